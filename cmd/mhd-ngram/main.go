@@ -0,0 +1,106 @@
+// Command mhd-ngram builds or downloads the reference n-gram model used for
+// novelty scoring and installs it in the workspace cache.
+//
+//	go run ./cmd/mhd-ngram build -name gutenberg-fiction books/*.txt
+//	go run ./cmd/mhd-ngram download https://example.org/reference.ngram.gz
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"book_dashboard/internal/novelty"
+	"book_dashboard/internal/workspace"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "build":
+		build(os.Args[2:])
+	case "download":
+		download(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mhd-ngram build [-o model.ngram.gz] [-name name] [-min-count n] <text files...>")
+	fmt.Fprintln(os.Stderr, "       mhd-ngram download [-o model.ngram.gz] <url>")
+	os.Exit(2)
+}
+
+// defaultModelPath is the workspace location the desktop analyzer reads.
+func defaultModelPath() string {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		log.Fatalf("workspace initialization failed: %v", err)
+	}
+	return workspace.NoveltyModelPath(root)
+}
+
+func build(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	out := fs.String("o", "", "output path (default: workspace cache/ngram/reference.ngram.gz)")
+	name := fs.String("name", "reference", "model name shown in reports")
+	minCount := fs.Int64("min-count", 2, "drop bigrams and trigrams seen fewer times")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+	texts := make([]string, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("read %s: %v", path, err)
+		}
+		texts = append(texts, string(raw))
+	}
+	model, err := novelty.Build(*name, texts, *minCount)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	path := *out
+	if path == "" {
+		path = defaultModelPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("create model dir: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("create model: %v", err)
+	}
+	if err := model.Save(f); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("close model: %v", err)
+	}
+	fmt.Printf("Wrote %s: %d tokens, baseline %.2f bits/token (sd %.2f)\n", path, model.Tokens, model.BaselineSurprisal, model.BaselineSD)
+}
+
+func download(args []string) {
+	fs := flag.NewFlagSet("download", flag.ExitOnError)
+	out := fs.String("o", "", "output path (default: workspace cache/ngram/reference.ngram.gz)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	path := *out
+	if path == "" {
+		path = defaultModelPath()
+	}
+	model, err := novelty.Download(context.Background(), fs.Arg(0), path)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Printf("Installed %s at %s: %d tokens, baseline %.2f bits/token\n", model.Name, path, model.Tokens, model.BaselineSurprisal)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"book_dashboard/internal/gates"
+	"book_dashboard/internal/workspace"
+)
+
+// thresholds are the gate limits; a negative limit is not checked.
+type thresholds struct {
+	MinMHD            int
+	MaxAISuspicion    int
+	MaxPAI            float64
+	MaxContradictions int
+	FailOnStaleSource bool
+	// Gates names the quality gates to check: "" for none, "project" for
+	// each project's own (or the defaults), or a JSON rules file.
+	Gates string
+}
+
+// gateResult is what a pipeline needs to accept or bounce one manuscript.
+// Failures lists the threshold flags that failed; quality gates report in
+// Gates, failed or not.
+type gateResult struct {
+	Path             string         `json:"path"`
+	BookTitle        string         `json:"book_title"`
+	MHDScore         int            `json:"mhd_score"`
+	AISuspicionScore int            `json:"ai_suspicion_score"`
+	PAIDoc           *float64       `json:"p_ai_doc"`
+	Contradictions   int            `json:"contradictions"`
+	SourceStatus     string         `json:"source_status"`
+	Passed           bool           `json:"passed"`
+	Failures         []string       `json:"failures"`
+	Gates            []gates.Result `json:"gates,omitempty"`
+}
+
+// savedReport is the slice of report.json the gate reads.
+type savedReport struct {
+	BookTitle      string `json:"book_title"`
+	MHDScore       int    `json:"mhd_score"`
+	Contradictions int    `json:"contradictions"`
+	Analysis       struct {
+		SlopReport struct {
+			AISuspicionScore int `json:"AISuspicionScore"`
+		} `json:"slop_report"`
+		AIReport struct {
+			PAIDoc *float64 `json:"p_ai_doc"`
+		} `json:"ai_report"`
+		Language *struct {
+			SpellingScore    int `json:"spellingScore"`
+			GrammarScore     int `json:"grammarScore"`
+			ReadabilityScore int `json:"readabilityScore"`
+		} `json:"language"`
+		QualityMetrics gates.Metrics `json:"quality_metrics"`
+	} `json:"analysis"`
+}
+
+// metrics returns the values the quality gates test. Reports written before
+// the gates existed lack quality_metrics, so those are rebuilt from the
+// scores they do carry; contradiction severities are then unknown.
+func (r savedReport) metrics() gates.Metrics {
+	if len(r.Analysis.QualityMetrics) > 0 {
+		return r.Analysis.QualityMetrics
+	}
+	m := gates.Metrics{
+		gates.MetricMHDScore:       float64(r.MHDScore),
+		gates.MetricAISuspicion:    float64(r.Analysis.SlopReport.AISuspicionScore),
+		gates.MetricContradictions: float64(r.Contradictions),
+	}
+	if r.Contradictions == 0 {
+		m[gates.MetricHighContradictions] = 0
+	}
+	if r.Analysis.AIReport.PAIDoc != nil {
+		m[gates.MetricPAIDoc] = *r.Analysis.AIReport.PAIDoc
+	}
+	if lang := r.Analysis.Language; lang != nil && (lang.GrammarScore > 0 || lang.SpellingScore > 0) {
+		m[gates.MetricGrammar] = float64(lang.GrammarScore)
+		m[gates.MetricSpelling] = float64(lang.SpellingScore)
+		m[gates.MetricReadability] = float64(lang.ReadabilityScore)
+	}
+	return m
+}
+
+// gateRules resolves the quality gates to check for the report at path.
+func (t thresholds) gateRules(path string) ([]gates.Rule, error) {
+	switch t.Gates {
+	case "":
+		return nil, nil
+	case "project":
+		settings, err := workspace.LoadProjectSettings(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		return settings.GateRules(), nil
+	}
+	return gates.LoadRules(t.Gates)
+}
+
+// evaluate reads a report.json, or the one inside a project directory, and
+// checks it against the limits.
+func evaluate(path string, limits thresholds) (gateResult, error) {
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		path = filepath.Join(path, "report.json")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return gateResult{}, fmt.Errorf("read report: %w", err)
+	}
+	var report savedReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return gateResult{}, fmt.Errorf("decode report: %w", err)
+	}
+	check, err := workspace.VerifyReportSource(path)
+	if err != nil {
+		return gateResult{}, err
+	}
+	result := gateResult{
+		Path:             path,
+		BookTitle:        report.BookTitle,
+		MHDScore:         report.MHDScore,
+		AISuspicionScore: report.Analysis.SlopReport.AISuspicionScore,
+		PAIDoc:           report.Analysis.AIReport.PAIDoc,
+		Contradictions:   report.Contradictions,
+		SourceStatus:     check.Status,
+	}
+	result.Failures = limits.check(result, check.Stale())
+	rules, err := limits.gateRules(path)
+	if err != nil {
+		return gateResult{}, err
+	}
+	if len(rules) > 0 {
+		result.Gates = gates.Evaluate(report.metrics(), rules)
+	}
+	result.Passed = len(result.Failures) == 0
+	for _, g := range result.Gates {
+		if !g.Passed {
+			result.Passed = false
+		}
+	}
+	return result, nil
+}
+
+func (t thresholds) check(r gateResult, stale bool) []string {
+	failures := []string{}
+	if t.MinMHD >= 0 && r.MHDScore < t.MinMHD {
+		failures = append(failures, fmt.Sprintf("MHD score %d is below %d", r.MHDScore, t.MinMHD))
+	}
+	if t.MaxAISuspicion >= 0 && r.AISuspicionScore > t.MaxAISuspicion {
+		failures = append(failures, fmt.Sprintf("AI suspicion score %d is above %d", r.AISuspicionScore, t.MaxAISuspicion))
+	}
+	if t.MaxPAI >= 0 && r.PAIDoc != nil && *r.PAIDoc > t.MaxPAI {
+		failures = append(failures, fmt.Sprintf("document AI probability %.2f is above %.2f", *r.PAIDoc, t.MaxPAI))
+	}
+	if t.MaxContradictions >= 0 && r.Contradictions > t.MaxContradictions {
+		failures = append(failures, fmt.Sprintf("%d contradictions is more than %d", r.Contradictions, t.MaxContradictions))
+	}
+	if t.FailOnStaleSource && stale {
+		failures = append(failures, fmt.Sprintf("report is stale: source file %s", r.SourceStatus))
+	}
+	return failures
+}
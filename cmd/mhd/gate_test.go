@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateAppliesThresholds(t *testing.T) {
+	dir := t.TempDir()
+	report := `{"book_title":"Salt","mhd_score":42,"contradictions":1,"analysis":{"slop_report":{"AISuspicionScore":71},"ai_report":{"p_ai_doc":0.3}}}`
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	relaxed := thresholds{MinMHD: -1, MaxAISuspicion: -1, MaxPAI: -1, MaxContradictions: -1}
+	result, err := evaluate(dir, relaxed)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if !result.Passed || result.MHDScore != 42 || result.AISuspicionScore != 71 || result.PAIDoc == nil {
+		t.Fatalf("disabled limits should pass and report the scores, got %+v", result)
+	}
+
+	strict := thresholds{MinMHD: 50, MaxAISuspicion: 60, MaxPAI: 0.5, MaxContradictions: -1}
+	result, err = evaluate(dir, strict)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if result.Passed || len(result.Failures) != 2 {
+		t.Fatalf("want the MHD and AI suspicion limits to fail, got %+v", result.Failures)
+	}
+	if !strings.Contains(result.Failures[0], "MHD score 42 is below 50") || !strings.Contains(result.Failures[1], "AI suspicion score 71 is above 60") {
+		t.Fatalf("unexpected failures: %v", result.Failures)
+	}
+}
+
+func TestEvaluateChecksProjectQualityGates(t *testing.T) {
+	dir := t.TempDir()
+	report := `{"book_title":"Salt","mhd_score":72,"contradictions":3,"analysis":{"ai_report":{"p_ai_doc":0.2},"quality_metrics":{"mhd_score":72,"p_ai_doc":0.2,"grammar_score":64,"contradictions":3,"contradictions_high":1}}}`
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	limits := thresholds{MinMHD: -1, MaxAISuspicion: -1, MaxPAI: -1, MaxContradictions: -1, Gates: "project"}
+
+	// No settings file: the default gates apply.
+	result, err := evaluate(dir, limits)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	failed := []string{}
+	for _, g := range result.Gates {
+		if !g.Passed {
+			failed = append(failed, g.Explanation)
+		}
+	}
+	if result.Passed || len(result.Gates) != 3 || len(failed) != 2 || len(result.Failures) != 0 {
+		t.Fatalf("want the HIGH contradiction and grammar gates to fail apart from the thresholds, got %+v", result)
+	}
+	if !strings.Contains(failed[1], "Grammar score is 64, which fails >= 70.") {
+		t.Fatalf("expected the failure explained, got %v", failed)
+	}
+
+	settings := `{"disabled_sections":[],"quality_gates":[{"id":"ai","metric":"p_ai_doc","op":"<","value":0.5}]}`
+	if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = evaluate(dir, limits)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if !result.Passed || len(result.Gates) != 1 || result.Gates[0].Label != "document AI probability < 0.50" {
+		t.Fatalf("want the project's own gate checked, got %+v", result)
+	}
+}
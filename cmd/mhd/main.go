@@ -1,6 +1,17 @@
+// Command mhd prepares the Manuscript Health workspace and, given saved
+// reports, gates them against score thresholds for batch pipelines.
+//
+//	go run ./cmd/mhd
+//	go run ./cmd/mhd -json -min-mhd 50 -max-ai-suspicion 60 ~/ManuscriptHealth/projects/*
+//	go run ./cmd/mhd -gates project ~/ManuscriptHealth/projects/*
+//
+// Exit status is 0 when every report passes, 1 when any report breaks a
+// threshold and 2 for usage errors or unreadable reports.
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,12 +20,82 @@ import (
 	"book_dashboard/internal/workspace"
 )
 
+const (
+	exitPass      = 0
+	exitThreshold = 1
+	exitError     = 2
+)
+
 func main() {
-	root, err := workspace.EnsureDefault()
-	if err != nil {
-		log.Fatalf("workspace initialization failed: %v", err)
+	asJSON := flag.Bool("json", false, "print one JSON object per report instead of text")
+	var limits thresholds
+	flag.IntVar(&limits.MinMHD, "min-mhd", -1, "fail when the MHD score is below this (negative disables)")
+	flag.IntVar(&limits.MaxAISuspicion, "max-ai-suspicion", -1, "fail when the AI suspicion score is above this (negative disables)")
+	flag.Float64Var(&limits.MaxPAI, "max-p-ai", -1, "fail when the document AI probability is above this, 0 to 1 (negative disables)")
+	flag.IntVar(&limits.MaxContradictions, "max-contradictions", -1, "fail when there are more contradictions than this (negative disables)")
+	flag.StringVar(&limits.Gates, "gates", "", `check quality gates: "project" for each project's own (default rules when unset) or a JSON rules file`)
+	flag.BoolVar(&limits.FailOnStaleSource, "fail-on-stale-source", false, "fail when the source changed or went missing since the report was produced")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: mhd [flags] [project dir | report.json]...")
+		fmt.Fprintln(flag.CommandLine.Output(), "With no reports, initializes the workspace.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		root, err := workspace.EnsureDefault()
+		if err != nil {
+			log.Fatalf("workspace initialization failed: %v", err)
+		}
+		fmt.Printf("Manuscript Health workspace ready at: %s\n", filepath.Clean(root))
+		fmt.Printf("Home: %s\n", os.Getenv("HOME"))
+		return
 	}
 
-	fmt.Printf("Manuscript Health workspace ready at: %s\n", filepath.Clean(root))
-	fmt.Printf("Home: %s\n", os.Getenv("HOME"))
+	status := exitPass
+	enc := json.NewEncoder(os.Stdout)
+	for _, path := range flag.Args() {
+		result, err := evaluate(path, limits)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = exitError
+			continue
+		}
+		if !result.Passed && status == exitPass {
+			status = exitThreshold
+		}
+		if *asJSON {
+			if err := enc.Encode(result); err != nil {
+				log.Fatalf("write result: %v", err)
+			}
+			continue
+		}
+		printResult(result)
+	}
+	os.Exit(status)
+}
+
+func printResult(r gateResult) {
+	verdict := "PASS"
+	if !r.Passed {
+		verdict = "FAIL"
+	}
+	pAI := "n/a"
+	if r.PAIDoc != nil {
+		pAI = fmt.Sprintf("%.2f", *r.PAIDoc)
+	}
+	fmt.Printf("%s %s: mhd=%d ai_suspicion=%d p_ai=%s contradictions=%d source=%s\n", verdict, r.BookTitle, r.MHDScore, r.AISuspicionScore, pAI, r.Contradictions, r.SourceStatus)
+	for _, f := range r.Failures {
+		fmt.Printf("  - %s\n", f)
+	}
+	for _, g := range r.Gates {
+		mark := "[x]"
+		switch {
+		case g.Skipped:
+			mark = "[-]"
+		case !g.Passed:
+			mark = "[ ]"
+		}
+		fmt.Printf("  %s %s: %s\n", mark, g.Label, g.Explanation)
+	}
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"book_dashboard/desktop/backend"
+)
+
+// Queue item states, in the order an item moves through them.
+const (
+	QueueQueued    = "queued"
+	QueueRunning   = "running"
+	QueueDone      = "done"
+	QueueFailed    = "failed"
+	QueueCancelled = "cancelled"
+)
+
+// maxQueueWorkers caps parallel queued analyses; each one drives Ollama and
+// LanguageTool, which serve a handful of requests at a time between them.
+const maxQueueWorkers = 4
+
+// QueueItem is one manuscript in the background analysis queue.
+type QueueItem struct {
+	ID      int    `json:"id"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	Percent int    `json:"percent"`
+	Stage   string `json:"stage"`
+	Detail  string `json:"detail"`
+	// ProjectID and RunID name the saved result once the item is done; open
+	// it with OpenProject.
+	ProjectID   string `json:"projectId,omitempty"`
+	RunID       string `json:"runId,omitempty"`
+	MHDScore    int    `json:"mhdScore"`
+	Error       string `json:"error,omitempty"`
+	QueuedAt    string `json:"queuedAt"`
+	StartedAt   string `json:"startedAt,omitempty"`
+	CompletedAt string `json:"completedAt,omitempty"`
+}
+
+// QueueStatus is the whole queue, oldest item first, with counts by state.
+type QueueStatus struct {
+	Workers   int         `json:"workers"`
+	Items     []QueueItem `json:"items"`
+	Queued    int         `json:"queued"`
+	Running   int         `json:"running"`
+	Done      int         `json:"done"`
+	Failed    int         `json:"failed"`
+	Cancelled int         `json:"cancelled"`
+}
+
+// queueRunner analyzes one queued file, reporting progress as it goes.
+type queueRunner func(path string, onProgress backend.ProgressFn) (backend.DashboardData, error)
+
+// analysisQueue works through queued files with up to workers analyses at a
+// time. Results are saved to their projects rather than shown, so a pile of
+// submissions can run unattended while the dashboard stays on what the user
+// is reading.
+type analysisQueue struct {
+	run      queueRunner
+	onChange func(QueueItem)
+
+	mu      sync.Mutex
+	items   []QueueItem
+	nextID  int
+	workers int
+	active  int
+}
+
+func newAnalysisQueue(run queueRunner, onChange func(QueueItem)) *analysisQueue {
+	return &analysisQueue{run: run, onChange: onChange, workers: queueWorkersFromEnv()}
+}
+
+// queueWorkersFromEnv reads MHD_QUEUE_WORKERS; the default of 1 analyzes
+// queued files one after another.
+func queueWorkersFromEnv() int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MHD_QUEUE_WORKERS")))
+	if err != nil {
+		return 1
+	}
+	return clampQueueWorkers(n)
+}
+
+func clampQueueWorkers(n int) int {
+	return min(max(n, 1), maxQueueWorkers)
+}
+
+// enqueue adds the files to the queue and starts workers for them. Blank
+// paths and files already waiting or running are skipped.
+func (q *analysisQueue) enqueue(paths []string) []QueueItem {
+	q.mu.Lock()
+	added := []QueueItem{}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" || q.pendingLocked(path) {
+			continue
+		}
+		q.nextID++
+		item := QueueItem{ID: q.nextID, Path: path, Status: QueueQueued, Detail: filepath.Base(path), QueuedAt: time.Now().Format(time.RFC3339)}
+		q.items = append(q.items, item)
+		added = append(added, item)
+	}
+	q.startWorkersLocked()
+	q.mu.Unlock()
+	for _, item := range added {
+		q.notify(item)
+	}
+	return added
+}
+
+func (q *analysisQueue) pendingLocked(path string) bool {
+	for _, item := range q.items {
+		if item.Path == path && (item.Status == QueueQueued || item.Status == QueueRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+// setWorkers changes how many files are analyzed at once; extra workers
+// start right away, and surplus ones stop after their current file.
+func (q *analysisQueue) setWorkers(n int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.workers = clampQueueWorkers(n)
+	q.startWorkersLocked()
+	return q.workers
+}
+
+func (q *analysisQueue) startWorkersLocked() {
+	for q.active < q.workers && q.nextQueuedLocked() >= 0 {
+		q.active++
+		go q.work()
+	}
+}
+
+func (q *analysisQueue) nextQueuedLocked() int {
+	for i, item := range q.items {
+		if item.Status == QueueQueued {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *analysisQueue) work() {
+	for {
+		q.mu.Lock()
+		i := q.nextQueuedLocked()
+		if i < 0 || q.active > q.workers {
+			q.active--
+			q.mu.Unlock()
+			return
+		}
+		q.items[i].Status = QueueRunning
+		q.items[i].StartedAt = time.Now().Format(time.RFC3339)
+		id, path := q.items[i].ID, q.items[i].Path
+		started := q.items[i]
+		q.mu.Unlock()
+		q.notify(started)
+
+		data, err := q.run(path, func(percent int, stage, detail string) {
+			q.updateItem(id, func(item *QueueItem) {
+				item.Percent, item.Stage, item.Detail = percent, stage, detail
+			})
+		})
+		q.updateItem(id, func(item *QueueItem) {
+			item.CompletedAt = time.Now().Format(time.RFC3339)
+			switch {
+			case err != nil:
+				item.Status = QueueFailed
+				item.Error = err.Error()
+			case data.RunStats.Status == "CANCELLED":
+				item.Status = QueueCancelled
+			default:
+				item.Status = QueueDone
+				item.Percent = 100
+			}
+			if data.ProjectLocation != "" {
+				item.ProjectID = filepath.Base(data.ProjectLocation)
+			}
+			item.RunID = data.RunStats.RunID
+			item.MHDScore = data.MHDScore
+		})
+	}
+}
+
+func (q *analysisQueue) updateItem(id int, fn func(*QueueItem)) {
+	q.mu.Lock()
+	var updated QueueItem
+	found := false
+	for i := range q.items {
+		if q.items[i].ID == id {
+			fn(&q.items[i])
+			updated, found = q.items[i], true
+			break
+		}
+	}
+	q.mu.Unlock()
+	if found {
+		q.notify(updated)
+	}
+}
+
+func (q *analysisQueue) notify(item QueueItem) {
+	if q.onChange != nil {
+		q.onChange(item)
+	}
+}
+
+// status returns a copy of the queue.
+func (q *analysisQueue) status() QueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := QueueStatus{Workers: q.workers, Items: append([]QueueItem{}, q.items...)}
+	for _, item := range q.items {
+		switch item.Status {
+		case QueueQueued:
+			out.Queued++
+		case QueueRunning:
+			out.Running++
+		case QueueDone:
+			out.Done++
+		case QueueFailed:
+			out.Failed++
+		case QueueCancelled:
+			out.Cancelled++
+		}
+	}
+	return out
+}
+
+// clearFinished drops done, failed and cancelled items.
+func (q *analysisQueue) clearFinished() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.items[:0]
+	for _, item := range q.items {
+		if item.Status == QueueQueued || item.Status == QueueRunning {
+			kept = append(kept, item)
+		}
+	}
+	q.items = kept
+}
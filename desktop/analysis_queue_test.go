@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"book_dashboard/desktop/backend"
+)
+
+// waitForQueue polls until no item is queued or running.
+func waitForQueue(t *testing.T, q *analysisQueue) QueueStatus {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		status := q.status()
+		if status.Queued == 0 && status.Running == 0 {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("queue did not drain: %+v", q.status())
+	return QueueStatus{}
+}
+
+func TestAnalysisQueueRunsUpToWorkersAtOnce(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	release := make(chan struct{})
+	run := func(path string, onProgress backend.ProgressFn) (backend.DashboardData, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		onProgress(50, "CHAPTER", path)
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if path == "bad.docx" {
+			return backend.DashboardData{}, errors.New("parse failed")
+		}
+		return backend.DashboardData{ProjectLocation: "/ws/projects/" + path, MHDScore: 80, RunStats: backend.RunStats{RunID: "run-" + path, Status: "DONE"}}, nil
+	}
+	var events []QueueItem
+	var eventsMu sync.Mutex
+	q := newAnalysisQueue(run, func(item QueueItem) {
+		eventsMu.Lock()
+		events = append(events, item)
+		eventsMu.Unlock()
+	})
+	q.setWorkers(2)
+
+	added := q.enqueue([]string{"a.docx", "bad.docx", " ", "c.docx", "a.docx"})
+	if len(added) != 3 {
+		t.Fatalf("expected blank and duplicate paths skipped, got %+v", added)
+	}
+	if again := q.enqueue([]string{"c.docx"}); len(again) != 0 {
+		t.Fatalf("expected a file already waiting to be skipped, got %+v", again)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	status := waitForQueue(t, q)
+
+	if peak != 2 {
+		t.Fatalf("expected two analyses at once, got %d", peak)
+	}
+	if status.Done != 2 || status.Failed != 1 || status.Items[1].Error != "parse failed" {
+		t.Fatalf("expected two done and one failed, got %+v", status)
+	}
+	if status.Items[0].ProjectID != "a.docx" || status.Items[0].RunID != "run-a.docx" || status.Items[0].Percent != 100 {
+		t.Fatalf("expected the saved project and run recorded, got %+v", status.Items[0])
+	}
+	eventsMu.Lock()
+	sawProgress := false
+	for _, e := range events {
+		if e.Status == QueueRunning && e.Percent == 50 && e.Stage == "CHAPTER" {
+			sawProgress = true
+		}
+	}
+	eventsMu.Unlock()
+	if !sawProgress {
+		t.Fatalf("expected per-item progress events, got %+v", events)
+	}
+
+	q.clearFinished()
+	if left := q.status(); len(left.Items) != 0 {
+		t.Fatalf("expected finished items cleared, got %+v", left)
+	}
+	if again := q.enqueue([]string{"a.docx"}); len(again) != 1 {
+		t.Fatalf("expected a finished file to be queued again, got %+v", again)
+	}
+	waitForQueue(t, q)
+}
+
+func TestEnqueueFilesAnalyzesInTheBackground(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:1/v2/check")
+
+	docxPath := filepath.Join(t.TempDir(), "Pile.docx")
+	if err := os.WriteFile(docxPath, buildDOCX(t), 0o644); err != nil {
+		t.Fatalf("write docx: %v", err)
+	}
+	app := NewApp()
+	before := app.GetDashboard()
+	if added := app.EnqueueFiles([]string{docxPath, filepath.Join(t.TempDir(), "missing.docx")}); len(added) != 2 {
+		t.Fatalf("expected both files queued, got %+v", added)
+	}
+	status := waitForQueue(t, app.queue)
+	if status.Done != 1 || status.Failed != 1 {
+		t.Fatalf("expected one analyzed and one failed, got %+v", status)
+	}
+	projects := app.ListProjects()
+	if len(projects) != 1 || projects[0].ID != status.Items[0].ProjectID {
+		t.Fatalf("expected the queued result saved to its project, got %+v vs %+v", projects, status.Items[0])
+	}
+	if after := app.GetDashboard(); after.RunStats.RunID != before.RunStats.RunID {
+		t.Fatalf("expected the dashboard left alone, got run %s", after.RunStats.RunID)
+	}
+}
@@ -13,23 +13,38 @@ import (
 	"book_dashboard/desktop/backend"
 	"book_dashboard/internal/ingest"
 	"book_dashboard/internal/timeline"
+	"book_dashboard/internal/workspace"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
 	ctx      context.Context
-	data     backend.DashboardData
+	events   backend.EventBus
+	state    *appState
 	services *serviceManager
 	logs     *logArchive
+	queue    *analysisQueue
+
+	// runCtx is cancelled at shutdown so in-flight analysis stops early.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
 }
 
+// defaultShutdownGrace is how long shutdown waits for an in-flight analysis
+// to wind down; MHD_SHUTDOWN_GRACE overrides it (e.g. "30s").
+const defaultShutdownGrace = 10 * time.Second
+
 func NewApp() *App {
-	return &App{data: backend.DashboardData{}, services: newServiceManager()}
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	a := &App{state: newAppState(), services: newServiceManager(), runCtx: runCtx, cancelRun: cancelRun}
+	a.queue = newAnalysisQueue(a.analyzeQueued, a.emitQueueProgress)
+	return a
 }
 
 func (a *App) startup(ctx context.Context) {
 	defer a.recoverFromPanic("startup")
 	a.ctx = ctx
+	a.events = newWailsEventBus(ctx)
 	if archive, err := newLogArchive(); err == nil {
 		a.logs = archive
 	} else {
@@ -40,21 +55,44 @@ func (a *App) startup(ctx context.Context) {
 			a.logs.appendServiceTrace(t)
 		}
 	})
-	runtime.EventsEmit(a.ctx, "analysis_progress", map[string]any{"percent": 0, "stage": "SETUP", "detail": "initializing"})
-	a.services.Start(a.ctx)
-	a.data = backend.InitialDashboard()
-	a.applySystemDiagnostics(&a.data)
+	a.emitProgress(0, "SETUP", "initializing")
+	a.services.Start(a.events)
+	initial := backend.InitialDashboard()
+	a.applySystemDiagnostics(&initial)
+	a.state.replace(initial, "")
 	a.persistDashboardSnapshot("startup")
 }
 
+// shutdown cancels any in-flight analysis and waits up to the grace period
+// for it to return, flushes logs and the dashboard snapshot, and only then
+// stops the managed Ollama and LanguageTool processes.
 func (a *App) shutdown(context.Context) {
+	a.cancelRun()
+	grace := shutdownGrace()
+	if a.state.waitForRun(grace) {
+		a.persistDashboardSnapshot("shutdown")
+	} else {
+		a.logs.appendLine("RISK", "SHUTDOWN", "Analysis still running after grace period", grace.String())
+	}
+	a.logs.flush()
 	a.services.Stop()
+	a.logs.close()
+}
+
+func shutdownGrace() time.Duration {
+	if raw := strings.TrimSpace(os.Getenv("MHD_SHUTDOWN_GRACE")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return defaultShutdownGrace
 }
 
 func (a *App) GetDashboard() backend.DashboardData {
 	defer a.recoverFromPanic("GetDashboard")
-	a.applySystemDiagnostics(&a.data)
-	return a.data
+	data := a.state.snapshot()
+	a.applySystemDiagnostics(&data)
+	return data
 }
 
 func (a *App) GetServiceDiagnostics() backend.SystemDiagnostics {
@@ -82,26 +120,44 @@ func (a *App) InstallMissingDependencies() backend.SystemDiagnostics {
 	}
 	sort.Strings(packages)
 	if len(packages) == 0 {
-		a.services.trace(a.ctx, "INFO", "Dependency install skipped", "No missing dependencies detected")
-		a.applySystemDiagnostics(&a.data)
+		a.services.trace(a.events, "INFO", "Dependency install skipped", "No missing dependencies detected")
 		return a.services.Snapshot()
 	}
 
 	for _, pkg := range packages {
-		a.services.trace(a.ctx, "ANALYSIS", "Installing dependency", pkg)
+		a.services.trace(a.events, "ANALYSIS", "Installing dependency", pkg)
 		if err := installWithBrew(pkg); err != nil {
-			a.services.trace(a.ctx, "RISK", "Dependency install failed", fmt.Sprintf("%s: %v", pkg, err))
+			a.services.trace(a.events, "RISK", "Dependency install failed", fmt.Sprintf("%s: %v", pkg, err))
 		} else {
-			a.services.trace(a.ctx, "INFO", "Dependency installed", pkg)
+			a.services.trace(a.events, "INFO", "Dependency installed", pkg)
 		}
 	}
 
-	if a.ctx != nil {
-		a.services.EnsureReady(a.ctx)
+	a.services.EnsureReady(a.events)
+	return a.services.Snapshot()
+}
+
+// StartServices is the inline fix for a service_down fallback: it starts
+// Ollama and LanguageTool if they are not answering.
+func (a *App) StartServices() backend.SystemDiagnostics {
+	defer a.recoverFromPanic("StartServices")
+	a.services.Retry(a.events)
+	return a.services.Snapshot()
+}
+
+// PullModel is the inline fix for a model_missing fallback.
+func (a *App) PullModel(model string) backend.SystemDiagnostics {
+	defer a.recoverFromPanic("PullModel")
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return a.services.Snapshot()
+	}
+	a.services.trace(a.events, "ANALYSIS", "Pulling Ollama model", model)
+	if err := pullModel(model); err != nil {
+		a.services.trace(a.events, "RISK", "Ollama model pull failed", err.Error())
 	} else {
-		a.services.EnsureReady(nil)
+		a.services.trace(a.events, "INFO", "Ollama model ready", model)
 	}
-	a.applySystemDiagnostics(&a.data)
 	return a.services.Snapshot()
 }
 
@@ -109,62 +165,285 @@ func (a *App) AnalyzeExcerpt(text string) backend.DashboardData {
 	defer a.recoverFromPanic("AnalyzeExcerpt")
 	trimmed := strings.TrimSpace(text)
 	if trimmed == "" {
-		data := a.GetDashboard()
-		data.Logs = append(data.Logs, backend.LogLine{
+		data := a.appendLogLine(backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
 			Stage:   "INGEST",
 			Message: "Analyze Excerpt ignored: empty text",
 			Detail:  "Paste text before running excerpt analysis.",
 		})
-		a.data = data
 		a.persistDashboardSnapshot("analyze_excerpt_empty")
 		return data
 	}
-	if a.ctx != nil {
-		a.services.EnsureReady(a.ctx)
-	} else {
-		a.services.EnsureReady(nil)
-	}
-	a.data = backend.BuildDashboard("Pasted Excerpt", "source.txt", []byte(trimmed), trimmed, a.emitProgress)
-	a.applySystemDiagnostics(&a.data)
+	a.services.EnsureReady(a.events)
+	unlock := a.state.lockRun()
+	defer unlock()
+	data := backend.BuildDashboardContext(a.withProgressEvents(a.runCtx), "Pasted Excerpt", "source.txt", []byte(trimmed), trimmed, nil)
+	a.applySystemDiagnostics(&data)
+	a.state.replace(data, trimmed)
+	a.recordResourceProfile(data.RunStats)
 	a.persistDashboardSnapshot("analyze_excerpt")
-	return a.data
+	return data
 }
 
 func (a *App) AnalyzeFile(path string) backend.DashboardData {
+	return a.AnalyzeFileWithSensitivity(path, "")
+}
+
+// AnalyzeFileWithSensitivity runs AnalyzeFile with an AI-detection preset
+// for this run only; an empty preset uses the project setting.
+func (a *App) AnalyzeFileWithSensitivity(path, preset string) backend.DashboardData {
+	return a.analyzeFile(path, func(ctx context.Context) context.Context {
+		return backend.WithAISensitivity(ctx, preset)
+	})
+}
+
+// AnalyzeFileWithProfile runs AnalyzeFile under an analysis profile
+// ("quick", "standard" or "deep"); an empty profile runs Standard.
+func (a *App) AnalyzeFileWithProfile(path, profile string) backend.DashboardData {
+	return a.analyzeFile(path, func(ctx context.Context) context.Context {
+		return backend.WithAnalysisProfile(ctx, profile)
+	})
+}
+
+// AnalyzeFileFresh runs AnalyzeFile without reusing cached genre, summary or
+// AI-window results, and replaces the cache with this run's.
+func (a *App) AnalyzeFileFresh(path string) backend.DashboardData {
+	return a.analyzeFile(path, backend.WithForceRefresh)
+}
+
+// AnalyzeInstallment analyzes the file as the next installment of a
+// serialized work, checking it against the earlier installments recorded
+// under the series name and then recording it for the ones after it.
+func (a *App) AnalyzeInstallment(path, series string) backend.DashboardData {
+	return a.analyzeFile(path, func(ctx context.Context) context.Context {
+		return backend.WithSeries(ctx, series)
+	})
+}
+
+// ListSeries returns the names of the series with recorded installments.
+func (a *App) ListSeries() []string {
+	defer a.recoverFromPanic("ListSeries")
+	names, err := backend.ListSeries()
+	if err != nil {
+		a.logProjectFailure("SERIES", "List series failed", err)
+		return []string{}
+	}
+	return names
+}
+
+// ListProjects returns the manuscripts analyzed before, most recently
+// analyzed first, for the project browser.
+func (a *App) ListProjects() []workspace.ProjectSummary {
+	defer a.recoverFromPanic("ListProjects")
+	projects, err := backend.ListProjects()
+	if err != nil {
+		a.logProjectFailure("PROJECT", "List projects failed", err)
+	}
+	if projects == nil {
+		projects = []workspace.ProjectSummary{}
+	}
+	return projects
+}
+
+// OpenProject restores the dashboard saved for a project without running
+// the analysis again. The manuscript text is read back from the project's
+// source file when it still matches the report, so findings and annotations
+// keep their excerpts; otherwise the dashboard opens without it and carries
+// the source integrity warning.
+func (a *App) OpenProject(id string) backend.DashboardData {
+	defer a.recoverFromPanic("OpenProject")
+	data, err := backend.OpenProject(id)
+	if err != nil {
+		a.logProjectFailure("PROJECT", "Open project failed", err)
+		return a.GetDashboard()
+	}
+	return a.showSavedDashboard(data, id, "Project reopened from saved report", "Project reopened")
+}
+
+// RescoreProject recomputes the AI-detection and MHD scores of a project's
+// latest run from its stored signals under the current AI sensitivity and
+// weighting, without analyzing the manuscript or calling any model again.
+func (a *App) RescoreProject(id string) backend.DashboardData {
+	defer a.recoverFromPanic("RescoreProject")
+	unlock := a.state.lockRun()
+	data, err := backend.RescoreProject(id)
+	unlock()
+	if err != nil {
+		a.logProjectFailure("SCORING", "Re-score failed", err)
+		return a.GetDashboard()
+	}
+	return a.showSavedDashboard(data, id, "Project re-scored from stored signals", "Project re-scored")
+}
+
+// showSavedDashboard makes a dashboard loaded from a project the current one.
+// The manuscript text is read back from the project's source file when it
+// still matches the report.
+func (a *App) showSavedDashboard(data backend.DashboardData, id, message, archived string) backend.DashboardData {
+	text := ""
+	detail := fmt.Sprintf("project=%s", id)
+	if data.SourceIntegrity != nil && data.SourceIntegrity.Status == workspace.SourceUnchanged {
+		if parsed, err := ingest.ParseFile(data.SourceIntegrity.SourcePath); err == nil {
+			text = parsed.Text
+		} else {
+			detail += " source_unreadable=" + err.Error()
+		}
+	} else if data.SourceIntegrity != nil {
+		detail += " source=" + data.SourceIntegrity.Status
+	}
+	unlock := a.state.lockRun()
+	defer unlock()
+	data.Logs = append(data.Logs, backend.LogLine{
+		Time:    time.Now().Format("15:04:05.000"),
+		Level:   "INFO",
+		Stage:   "PROJECT",
+		Message: message,
+		Detail:  detail,
+	})
+	a.applySystemDiagnostics(&data)
+	a.state.replace(data, text)
+	if a.logs != nil {
+		a.logs.appendLine("INFO", "PROJECT", archived, id)
+	}
+	return a.GetDashboard()
+}
+
+// ListProjectRuns returns the ids of a project's completed runs, oldest
+// first, for choosing two to compare.
+func (a *App) ListProjectRuns(projectID string) []string {
+	defer a.recoverFromPanic("ListProjectRuns")
+	runs, err := backend.ListProjectRuns(projectID)
+	if err != nil {
+		a.logProjectFailure("PROJECT", "List project runs failed", err)
+		return []string{}
+	}
+	return runs
+}
+
+// CompareRuns diffs two completed runs of a project, runA before and runB
+// after a revision. It returns an empty diff if either run cannot be read.
+func (a *App) CompareRuns(projectID, runA, runB string) backend.RunDiff {
+	defer a.recoverFromPanic("CompareRuns")
+	diff, err := backend.CompareRuns(projectID, runA, runB)
+	if err != nil {
+		a.logProjectFailure("PROJECT", "Compare runs failed", err)
+		return backend.RunDiff{ProjectID: projectID, RunA: runA, RunB: runB}
+	}
+	return diff
+}
+
+// EnqueueFiles adds manuscripts to the background analysis queue and
+// returns the items added. Queued files are analyzed one at a time, or up to
+// the SetQueueWorkers count in parallel, and saved to their projects without
+// replacing the dashboard; each change is emitted as a queue_progress event.
+func (a *App) EnqueueFiles(paths []string) []QueueItem {
+	defer a.recoverFromPanic("EnqueueFiles")
+	added := a.queue.enqueue(paths)
+	if a.logs != nil && len(added) > 0 {
+		a.logs.appendLine("INFO", "QUEUE", "Files queued for analysis", fmt.Sprintf("count=%d", len(added)))
+	}
+	return added
+}
+
+// GetQueueStatus returns every queued, running and finished item.
+func (a *App) GetQueueStatus() QueueStatus {
+	defer a.recoverFromPanic("GetQueueStatus")
+	return a.queue.status()
+}
+
+// SetQueueWorkers sets how many queued files are analyzed at once (1 to 4)
+// and returns the count in force.
+func (a *App) SetQueueWorkers(n int) int {
+	defer a.recoverFromPanic("SetQueueWorkers")
+	return a.queue.setWorkers(n)
+}
+
+// ClearFinishedQueueItems removes done, failed and cancelled items from the
+// queue.
+func (a *App) ClearFinishedQueueItems() QueueStatus {
+	defer a.recoverFromPanic("ClearFinishedQueueItems")
+	a.queue.clearFinished()
+	return a.queue.status()
+}
+
+// analyzeQueued analyzes one file for the background queue. The result is
+// saved to its project and the session log but leaves the dashboard alone.
+func (a *App) analyzeQueued(path string, onProgress backend.ProgressFn) (backend.DashboardData, error) {
+	if a.runCtx.Err() != nil {
+		return backend.DashboardData{}, fmt.Errorf("app is shutting down")
+	}
+	parsed, err := ingest.ParseFile(path)
+	if err != nil {
+		return backend.DashboardData{}, err
+	}
+	a.services.EnsureReady(a.events)
+	unlock := a.state.lockQueuedRun()
+	defer unlock()
+	data := backend.BuildDashboardContext(backend.WithPages(backend.WithHighlights(a.runCtx, parsed.Highlights), parsed.Pages), parsed.Title, filepath.Base(parsed.SourcePath), parsed.SourceBytes, parsed.Text, onProgress)
+	if a.logs != nil {
+		a.logs.appendDashboardLogs(data.Logs)
+	}
+	a.recordResourceProfile(data.RunStats)
+	return data, nil
+}
+
+func (a *App) emitQueueProgress(item QueueItem) {
+	if a.logs != nil && item.Status != QueueRunning {
+		a.logs.appendLine("INFO", "QUEUE", "Queue item "+item.Status, fmt.Sprintf("id=%d path=%s run=%s %s", item.ID, item.Path, item.RunID, item.Error))
+	}
+	if a.events == nil || a.runCtx.Err() != nil {
+		return
+	}
+	a.events.Emit(backend.EventQueueProgress, map[string]any{
+		"id":          item.ID,
+		"path":        item.Path,
+		"status":      item.Status,
+		"percent":     item.Percent,
+		"stage":       item.Stage,
+		"detail":      item.Detail,
+		"projectId":   item.ProjectID,
+		"runId":       item.RunID,
+		"mhdScore":    item.MHDScore,
+		"error":       item.Error,
+		"queuedAt":    item.QueuedAt,
+		"startedAt":   item.StartedAt,
+		"completedAt": item.CompletedAt,
+	})
+}
+
+// analyzeFile parses and analyzes the file; withRun adds the per-run
+// overrides to the run context.
+func (a *App) analyzeFile(path string, withRun func(context.Context) context.Context) backend.DashboardData {
 	defer a.recoverFromPanic("AnalyzeFile")
 	path = strings.TrimSpace(path)
 	if path == "" {
-		data := a.GetDashboard()
-		data.Logs = append(data.Logs, backend.LogLine{
+		data := a.appendLogLine(backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
 			Stage:   "INGEST",
 			Message: "Analyze File ignored: empty path",
 			Detail:  "Provide an absolute .docx or .pdf path or use Pick File.",
 		})
-		a.data = data
 		a.persistDashboardSnapshot("analyze_file_empty")
 		return data
 	}
 	if _, err := os.Stat(path); err != nil {
-		data := a.GetDashboard()
-		data.Logs = append(data.Logs, backend.LogLine{
+		data := a.appendLogLine(backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
 			Stage:   "INGEST",
 			Message: "Analyze File failed: path not found",
 			Detail:  path,
 		})
-		a.data = data
 		a.persistDashboardSnapshot("analyze_file_not_found")
 		return data
 	}
 
 	parsed, err := ingest.ParseFile(path)
 	if err != nil {
-		data := backend.BuildDashboard("Ingestion Failure", "", nil, backend.DefaultDemoText, a.emitProgress)
+		unlock := a.state.lockRun()
+		defer unlock()
+		data := backend.BuildDashboardContext(a.withProgressEvents(a.runCtx), "Ingestion Failure", "", nil, backend.DefaultDemoText, nil)
 		data.Logs = append(data.Logs, backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
@@ -172,34 +451,40 @@ func (a *App) AnalyzeFile(path string) backend.DashboardData {
 			Message: "file parse failed",
 			Detail:  err.Error(),
 		})
-		a.data = data
+		a.applySystemDiagnostics(&data)
+		a.state.replace(data, backend.DefaultDemoText)
+		a.recordResourceProfile(data.RunStats)
 		a.persistDashboardSnapshot("analyze_file_parse_failed")
-		return a.data
-	}
-	if a.ctx != nil {
-		a.services.EnsureReady(a.ctx)
-	} else {
-		a.services.EnsureReady(nil)
+		return data
 	}
+	a.services.EnsureReady(a.events)
 	a.emitProgress(10, "INGEST", "File parsed, starting analysis")
-	a.data = backend.BuildDashboard(parsed.Title, filepath.Base(parsed.SourcePath), parsed.SourceBytes, parsed.Text, a.emitProgress)
-	a.applySystemDiagnostics(&a.data)
+	unlock := a.state.lockRun()
+	defer unlock()
+	data := backend.BuildDashboardContext(a.withProgressEvents(backend.WithPages(backend.WithHighlights(withRun(a.runCtx), parsed.Highlights), parsed.Pages)), parsed.Title, filepath.Base(parsed.SourcePath), parsed.SourceBytes, parsed.Text, nil)
+	a.applySystemDiagnostics(&data)
+	a.state.replace(data, parsed.Text)
+	a.recordResourceProfile(data.RunStats)
 	a.persistDashboardSnapshot("analyze_file")
-	return a.data
+	return data
 }
 
 func (a *App) PickAndAnalyzeFile() backend.DashboardData {
+	return a.PickAndAnalyzeFileWithProfile("")
+}
+
+// PickAndAnalyzeFileWithProfile asks for a manuscript and analyzes it under
+// the analysis profile.
+func (a *App) PickAndAnalyzeFileWithProfile(profile string) backend.DashboardData {
 	defer a.recoverFromPanic("PickAndAnalyzeFile")
 	if a.ctx == nil {
-		data := a.GetDashboard()
-		data.Logs = append(data.Logs, backend.LogLine{
+		data := a.appendLogLine(backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
 			Stage:   "INGEST",
 			Message: "File picker unavailable",
 			Detail:  "UI context is not initialized.",
 		})
-		a.data = data
 		a.persistDashboardSnapshot("pick_file_unavailable")
 		return data
 	}
@@ -212,22 +497,29 @@ func (a *App) PickAndAnalyzeFile() backend.DashboardData {
 		},
 	})
 	if err != nil {
-		data := a.GetDashboard()
-		data.Logs = append(data.Logs, backend.LogLine{
+		data := a.appendLogLine(backend.LogLine{
 			Time:    time.Now().Format("15:04:05.000"),
 			Level:   "RISK",
 			Stage:   "INGEST",
 			Message: "file picker failed",
 			Detail:  err.Error(),
 		})
-		a.data = data
 		a.persistDashboardSnapshot("pick_file_error")
-		return a.data
+		return data
 	}
 	if strings.TrimSpace(selected) == "" {
 		return a.GetDashboard()
 	}
-	return a.AnalyzeFile(selected)
+	return a.AnalyzeFileWithProfile(selected, profile)
+}
+
+// LintExcerpt gives live feedback on a passage: echo words, filter words and
+// red-flag vocabulary. It runs offline in milliseconds and leaves the
+// dashboard, run lock and logs untouched, so it can be called as the author
+// types.
+func (a *App) LintExcerpt(text string) backend.ExcerptLint {
+	defer a.recoverFromPanic("LintExcerpt")
+	return backend.LintExcerpt(text)
 }
 
 func (a *App) ExtractTimelineMarkers(paragraph string) []string {
@@ -235,21 +527,337 @@ func (a *App) ExtractTimelineMarkers(paragraph string) []string {
 	return timeline.ExtractMarkers(paragraph)
 }
 
+func (a *App) AddAnnotation(in backend.Annotation) []backend.Annotation {
+	defer a.recoverFromPanic("AddAnnotation")
+	in.FindingID = backend.StableFindingID(a.state.snapshot(), in.FindingID)
+	if _, err := backend.AddAnnotation(a.state.projectLocation(), a.state.sourceText(), in); err != nil {
+		a.logAnnotationFailure("Add annotation failed", err)
+		return a.state.snapshot().Annotations
+	}
+	return a.ListAnnotations()
+}
+
+func (a *App) ListAnnotations() []backend.Annotation {
+	defer a.recoverFromPanic("ListAnnotations")
+	items, err := backend.ListAnnotations(a.state.projectLocation(), a.state.sourceText())
+	if err != nil {
+		a.logAnnotationFailure("List annotations failed", err)
+		return a.state.snapshot().Annotations
+	}
+	a.state.update(func(d *backend.DashboardData) { d.Annotations = items })
+	return items
+}
+
+func (a *App) DeleteAnnotation(id int64) []backend.Annotation {
+	defer a.recoverFromPanic("DeleteAnnotation")
+	if err := backend.DeleteAnnotation(a.state.projectLocation(), id); err != nil {
+		a.logAnnotationFailure("Delete annotation failed", err)
+		return a.state.snapshot().Annotations
+	}
+	return a.ListAnnotations()
+}
+
+func (a *App) GetReportSections() map[string]string {
+	defer a.recoverFromPanic("GetReportSections")
+	sections, err := backend.LoadProjectSections(a.state.projectLocation())
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Load report sections failed", err)
+		return a.state.snapshot().Sections
+	}
+	return sections
+}
+
+// SetReportSectionEnabled persists a section toggle for the loaded project;
+// it takes effect on the next analysis run.
+func (a *App) SetReportSectionEnabled(section string, enabled bool) map[string]string {
+	defer a.recoverFromPanic("SetReportSectionEnabled")
+	sections, err := backend.SetSectionEnabled(a.state.projectLocation(), section, enabled)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update report section failed", err)
+		return a.state.snapshot().Sections
+	}
+	return sections
+}
+
+// SetAISensitivity persists the AI-detection preset (conservative, balanced
+// or aggressive) for the loaded project; it takes effect on the next run.
+func (a *App) SetAISensitivity(preset string) string {
+	defer a.recoverFromPanic("SetAISensitivity")
+	saved, err := backend.SetAISensitivity(a.state.projectLocation(), preset)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update AI sensitivity failed", err)
+		return ""
+	}
+	return saved
+}
+
+// SetAnthology marks the loaded project as a story collection (or not); it
+// takes effect on the next run.
+func (a *App) SetAnthology(enabled bool) bool {
+	defer a.recoverFromPanic("SetAnthology")
+	saved, err := backend.SetAnthology(a.state.projectLocation(), enabled)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update anthology mode failed", err)
+		return false
+	}
+	return saved
+}
+
+// SetMaskProfanity chooses whether the loaded project's exports mask quoted
+// profanity; it applies to the next export.
+func (a *App) SetMaskProfanity(enabled bool) bool {
+	defer a.recoverFromPanic("SetMaskProfanity")
+	saved, err := backend.SetMaskProfanity(a.state.projectLocation(), enabled)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update profanity masking failed", err)
+		return false
+	}
+	return saved
+}
+
+// SetManuscriptType selects fiction, nonfiction or memoir for the loaded
+// project; it takes effect on the next run.
+func (a *App) SetManuscriptType(manuscriptType string) string {
+	defer a.recoverFromPanic("SetManuscriptType")
+	saved, err := backend.SetManuscriptType(a.state.projectLocation(), manuscriptType)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update manuscript type failed", err)
+		return ""
+	}
+	return saved
+}
+
+// SetNumberStyle selects the style guide (chicago, ap or oxford) that
+// numbers, units and dates are checked against; it takes effect on the next
+// run.
+func (a *App) SetNumberStyle(style string) string {
+	defer a.recoverFromPanic("SetNumberStyle")
+	saved, err := backend.SetNumberStyle(a.state.projectLocation(), style)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update number style guide failed", err)
+		return ""
+	}
+	return saved
+}
+
+func (a *App) GetVerifiedHuman() backend.VerifiedHuman {
+	defer a.recoverFromPanic("GetVerifiedHuman")
+	verified, err := backend.LoadVerifiedHuman(a.state.projectLocation())
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Load verified-human list failed", err)
+		return backend.VerifiedHuman{Chapters: []int{}, Passages: []string{}}
+	}
+	return verified
+}
+
+// SetVerifiedHuman replaces the chapters and passages the author has verified
+// as their own writing. AI detection still scores them but leaves them out of
+// the document estimate from the next run.
+func (a *App) SetVerifiedHuman(in backend.VerifiedHuman) backend.VerifiedHuman {
+	defer a.recoverFromPanic("SetVerifiedHuman")
+	verified, err := backend.SetVerifiedHuman(a.state.projectLocation(), in)
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "Update verified-human list failed", err)
+		return a.GetVerifiedHuman()
+	}
+	return verified
+}
+
+// ExplainFinding asks the local model to explain one finding from the current
+// dashboard in plain English, with a suggested fix.
+func (a *App) ExplainFinding(findingID string) backend.FindingExplanation {
+	defer a.recoverFromPanic("ExplainFinding")
+	explanation, err := backend.ExplainFinding(a.state.snapshot(), a.state.sourceText(), findingID)
+	if err != nil {
+		a.logProjectFailure("EXPLAIN", "Explain finding failed", err)
+		return backend.FindingExplanation{FindingID: findingID, Evidence: []string{}, Notes: []string{err.Error()}}
+	}
+	return explanation
+}
+
+// MarkFinding records whether a finding of the current dashboard is correct
+// or a false positive and returns per-signal precision across the workspace.
+func (a *App) MarkFinding(in backend.FindingFeedback) backend.FeedbackReport {
+	defer a.recoverFromPanic("MarkFinding")
+	report, err := backend.RecordFindingFeedback(a.state.snapshot(), in)
+	if err != nil {
+		a.logProjectFailure("FEEDBACK", "Finding feedback not recorded", err)
+		return backend.FeedbackReport{Signals: []backend.SignalPrecision{}, Thresholds: map[string]float64{}}
+	}
+	return report
+}
+
+// GetFeedbackReport returns per-signal precision and the re-fit thresholds
+// from the feedback given so far.
+func (a *App) GetFeedbackReport() backend.FeedbackReport {
+	defer a.recoverFromPanic("GetFeedbackReport")
+	root, err := workspace.EnsureDefault()
+	if err == nil {
+		var report backend.FeedbackReport
+		if report, err = backend.LoadFeedbackReport(root); err == nil {
+			return report
+		}
+	}
+	a.logProjectFailure("FEEDBACK", "Feedback report unavailable", err)
+	return backend.FeedbackReport{Signals: []backend.SignalPrecision{}, Thresholds: map[string]float64{}}
+}
+
+// SuggestRewrites returns labeled machine rewrites for a slop or AI finding.
+// passage is optional for AI windows and required for slop flags. Nothing is
+// applied to the manuscript.
+func (a *App) SuggestRewrites(findingID, passage string) backend.RewriteSuggestion {
+	defer a.recoverFromPanic("SuggestRewrites")
+	suggestion, err := backend.SuggestRewrites(a.state.snapshot(), a.state.sourceText(), findingID, passage)
+	if err != nil {
+		a.logProjectFailure("REWRITE", "Rewrite suggestion failed", err)
+		suggestion.Notes = append(suggestion.Notes, err.Error())
+	}
+	return suggestion
+}
+
+// SimulateChapterOrders is an experimental structure tool: it re-scores
+// single-chapter moves of the current manuscript and returns the best ones.
+func (a *App) SimulateChapterOrders() backend.ReorderReport {
+	defer a.recoverFromPanic("SimulateChapterOrders")
+	report, err := backend.SimulateChapterOrders(a.state.snapshot(), a.state.sourceText())
+	if err != nil {
+		a.logProjectFailure("STRUCTURE", "Chapter reorder simulation failed", err)
+		return backend.ReorderReport{Experimental: true, Chapters: []backend.ReorderChapter{}, Suggestions: []backend.ReorderSuggestion{}, Notes: []string{err.Error()}}
+	}
+	return report
+}
+
+// DownloadNoveltyModel installs the reference n-gram model used for novelty
+// scoring. An empty url falls back to MHD_NOVELTY_MODEL_URL.
+func (a *App) DownloadNoveltyModel(url string) backend.NoveltyModelInfo {
+	defer a.recoverFromPanic("DownloadNoveltyModel")
+	info, err := backend.DownloadNoveltyModel(url)
+	if err != nil {
+		a.logProjectFailure("NOVELTY", "Novelty model download failed", err)
+		return backend.NoveltyModelInfo{Notes: []string{err.Error()}}
+	}
+	return info
+}
+
+// ListLexicons describes the editable bad-word and intensifier lexicons of
+// the workspace.
+func (a *App) ListLexicons() []backend.LexiconInfo {
+	defer a.recoverFromPanic("ListLexicons")
+	lexicons, err := backend.ListLexicons()
+	if err != nil {
+		a.logProjectFailure("SETTINGS", "List lexicons failed", err)
+		return []backend.LexiconInfo{}
+	}
+	return lexicons
+}
+
+// AddLexiconEntry adds word to a lexicon (bad_words or intensifiers), for
+// one genre when genre is set. It applies from the next analysis.
+func (a *App) AddLexiconEntry(name, genre, word string) []backend.LexiconInfo {
+	defer a.recoverFromPanic("AddLexiconEntry")
+	if _, err := backend.AddLexiconEntry(name, genre, word); err != nil {
+		a.logProjectFailure("SETTINGS", "Add lexicon entry failed", err)
+	}
+	return a.ListLexicons()
+}
+
+// RemoveLexiconEntry drops word from a lexicon, for one genre when genre is
+// set. It applies from the next analysis.
+func (a *App) RemoveLexiconEntry(name, genre, word string) []backend.LexiconInfo {
+	defer a.recoverFromPanic("RemoveLexiconEntry")
+	if _, err := backend.RemoveLexiconEntry(name, genre, word); err != nil {
+		a.logProjectFailure("SETTINGS", "Remove lexicon entry failed", err)
+	}
+	return a.ListLexicons()
+}
+
+// RemoveManuscript deletes everything stored from the loaded manuscript:
+// source copies, reports, database, settings, cached embeddings, run
+// snapshots and the session logs that mention its runs. It resets the
+// dashboard and returns a report of what was deleted and whether each
+// deletion was confirmed. The frontend asks the author to confirm first.
+func (a *App) RemoveManuscript() backend.PurgeReport {
+	defer a.recoverFromPanic("RemoveManuscript")
+	unlock := a.state.lockRun()
+	defer unlock()
+	location := a.state.projectLocation()
+	report, err := backend.PurgeManuscript(location)
+	if err != nil {
+		report.Notes = append(report.Notes, err.Error())
+	}
+	if location != "" && a.logs != nil {
+		purged, err := a.logs.purgeProject(location)
+		report.Deleted = append(report.Deleted, purged...)
+		if err != nil {
+			report.Notes = append(report.Notes, "Log cleanup incomplete: "+err.Error())
+		}
+	}
+	report.Notes = append(report.Notes, "Files you saved yourself (exported plain reports and log packages) are not tracked and were not removed.")
+	report.Verify()
+	a.state.replace(backend.InitialDashboard(), "")
+	// The detail carries counts only; titles and paths would leave the
+	// manuscript's traces in the fresh session log.
+	a.logs.appendLine("INFO", "PRIVACY", "Manuscript data removed", fmt.Sprintf("files=%d bytes=%d verified=%t", report.FilesDeleted, report.BytesDeleted, report.Verified))
+	return report
+}
+
+func (a *App) logAnnotationFailure(message string, err error) {
+	a.logProjectFailure("ANNOTATIONS", message, err)
+}
+
+func (a *App) logProjectFailure(stage, message string, err error) {
+	line := backend.LogLine{
+		Time:    time.Now().Format("15:04:05.000"),
+		Level:   "RISK",
+		Stage:   stage,
+		Message: message,
+		Detail:  err.Error(),
+	}
+	a.appendLogLine(line)
+	if a.logs != nil {
+		a.logs.appendLine(line.Level, line.Stage, line.Message, line.Detail)
+	}
+}
+
+// appendLogLine records a log line on the shared dashboard and returns the
+// updated copy with fresh service diagnostics.
+func (a *App) appendLogLine(line backend.LogLine) backend.DashboardData {
+	data := a.state.update(func(d *backend.DashboardData) {
+		d.Logs = append(d.Logs, line)
+	})
+	a.applySystemDiagnostics(&data)
+	return data
+}
+
 func (a *App) emitProgress(percent int, stage, detail string) {
+	a.traceProgress(percent, stage, detail)
+	if a.events == nil || a.runCtx.Err() != nil {
+		return
+	}
+	backend.ProgressEvents(a.events)(percent, stage, detail)
+}
+
+// withProgressEvents sends an interactive run's structured progress, with
+// stage timings and the remaining-time estimate, to the frontend.
+func (a *App) withProgressEvents(ctx context.Context) context.Context {
+	return backend.WithProgressListener(ctx, a.emitProgressEvent)
+}
+
+func (a *App) emitProgressEvent(ev backend.ProgressEvent) {
+	a.traceProgress(ev.Percent, ev.Stage, ev.Detail)
+	if a.events == nil || a.runCtx.Err() != nil {
+		return
+	}
+	backend.ProgressEventListener(a.events)(ev)
+}
+
+func (a *App) traceProgress(percent int, stage, detail string) {
 	if os.Getenv("MHD_TRACE_PROGRESS") == "1" {
 		fmt.Printf("%s [PROGRESS] %3d%% [%s] %s\n", time.Now().Format("15:04:05.000"), percent, stage, detail)
 	}
 	if a.logs != nil {
 		a.logs.appendProgress(percent, stage, detail)
 	}
-	if a.ctx == nil {
-		return
-	}
-	runtime.EventsEmit(a.ctx, "analysis_progress", map[string]any{
-		"percent": percent,
-		"stage":   stage,
-		"detail":  detail,
-	})
 }
 
 func (a *App) applySystemDiagnostics(data *backend.DashboardData) {
@@ -263,9 +871,10 @@ func (a *App) persistDashboardSnapshot(trigger string) {
 	if a.logs == nil {
 		return
 	}
-	a.applySystemDiagnostics(&a.data)
-	a.logs.appendDashboardLogs(a.data.Logs)
-	path, err := a.logs.persistRunSnapshot(trigger, a.data)
+	data := a.state.snapshot()
+	a.applySystemDiagnostics(&data)
+	a.logs.appendDashboardLogs(data.Logs)
+	path, err := a.logs.persistRunSnapshot(trigger, data)
 	if err != nil {
 		fmt.Printf("%s [RISK] [LOGS] Failed to persist snapshot: %v\n", time.Now().Format("15:04:05.000"), err)
 		return
@@ -273,63 +882,218 @@ func (a *App) persistDashboardSnapshot(trigger string) {
 	a.logs.appendLine("INFO", "LOGS", "Run snapshot persisted", path)
 }
 
+func (a *App) recordResourceProfile(stats backend.RunStats) {
+	if a.logs == nil {
+		return
+	}
+	if err := a.logs.appendResourceProfile(stats); err != nil {
+		a.logs.appendLine("RISK", "LOGS", "Resource profile not recorded", err.Error())
+	}
+}
+
+// ListRunSnapshots lists previously persisted run snapshots, newest first.
+func (a *App) ListRunSnapshots() []RunSnapshotInfo {
+	defer a.recoverFromPanic("ListRunSnapshots")
+	items, err := a.logs.listRunSnapshots()
+	if err != nil {
+		a.logProjectFailure("LOGS", "List run snapshots failed", err)
+		return []RunSnapshotInfo{}
+	}
+	return items
+}
+
+// LoadRunSnapshot replaces the dashboard with a persisted snapshot so support
+// can inspect a previous run without re-running the analysis. The source text
+// is not part of the snapshot, so annotation edits and finding excerpts are
+// unavailable until the manuscript is analyzed again.
+func (a *App) LoadRunSnapshot(path string) backend.DashboardData {
+	defer a.recoverFromPanic("LoadRunSnapshot")
+	snap, err := a.logs.loadRunSnapshot(path)
+	if err != nil {
+		a.logProjectFailure("LOGS", "Load run snapshot failed", err)
+		return a.GetDashboard()
+	}
+	unlock := a.state.lockRun()
+	defer unlock()
+	data := snap.Dashboard
+	data.Logs = append(data.Logs, backend.LogLine{
+		Time:    time.Now().Format("15:04:05.000"),
+		Level:   "INFO",
+		Stage:   "LOGS",
+		Message: "Run snapshot loaded",
+		Detail:  fmt.Sprintf("captured_at=%s trigger=%s path=%s", snap.CapturedAt, snap.Trigger, path),
+	})
+	a.state.replace(data, "")
+	if a.logs != nil {
+		a.logs.appendLine("INFO", "LOGS", "Run snapshot loaded", path)
+	}
+	return a.GetDashboard()
+}
+
 func (a *App) ExportLogPackageDialog() {
 	defer a.recoverFromPanic("ExportLogPackageDialog")
 	if a.ctx == nil {
 		return
 	}
 	if a.logs == nil {
-		_, _ = runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-			Type:    runtime.ErrorDialog,
-			Title:   "Export Log Package",
-			Message: "Log archive is not initialized.",
-		})
+		backend.Notify(a.events, backend.NotificationError, "Export Log Package", "Log archive is not initialized.")
+		return
+	}
+	target, ok := a.saveDialog("Export Log Package", a.logs.RootDir(), "mhd-log-package-"+time.Now().Format("20060102-150405")+".zip", []runtime.FileFilter{
+		{DisplayName: "ZIP Archive", Pattern: "*.zip"},
+	})
+	if !ok {
+		return
+	}
+	if err := a.logs.exportZip(target); err != nil {
+		backend.Notify(a.events, backend.NotificationError, "Export Log Package", "Failed to export logs: "+err.Error())
+		return
+	}
+	a.logs.appendLine("INFO", "LOGS", "Log package exported", target)
+	backend.Notify(a.events, backend.NotificationInfo, "Export Log Package", "Log package created at:\n"+target)
+}
+
+// GetPlainReport returns the current dashboard as linear Markdown for screen
+// readers and plain-text review.
+func (a *App) GetPlainReport() string {
+	defer a.recoverFromPanic("GetPlainReport")
+	data := a.state.snapshot()
+	if backend.MaskProfanityEnabled(data.ProjectLocation) {
+		data = backend.MaskProfanity(data)
+	}
+	return backend.PlainReport(data)
+}
+
+// ExportPlainReportDialog saves the internal plain report.
+func (a *App) ExportPlainReportDialog() {
+	a.ExportReportDialog(backend.ExportProfileInternal)
+}
+
+// ExportReportDialog saves the plain report for an export profile:
+// "internal" for the whole report or "author" for the author-facing one.
+func (a *App) ExportReportDialog(profile string) {
+	defer a.recoverFromPanic("ExportReportDialog")
+	if a.ctx == nil {
+		return
+	}
+	profile, err := backend.ParseExportProfile(profile)
+	if err != nil {
+		backend.Notify(a.events, backend.NotificationError, "Export Plain Report", err.Error())
+		return
+	}
+	data := a.state.snapshot()
+	target, ok := a.saveDialog("Export Plain Report", data.ProjectLocation, "mhd-report-"+profile+"-"+time.Now().Format("20060102-150405")+".md", []runtime.FileFilter{
+		{DisplayName: "Markdown", Pattern: "*.md"},
+		{DisplayName: "Text", Pattern: "*.txt"},
+	})
+	if !ok {
+		return
+	}
+	if backend.MaskProfanityEnabled(data.ProjectLocation) {
+		data = backend.MaskProfanity(data)
+	}
+	if err := os.WriteFile(target, []byte(backend.PlainReportFor(data, profile)), 0o644); err != nil {
+		a.logProjectFailure("REPORT", "Plain report export failed", err)
+		backend.Notify(a.events, backend.NotificationError, "Export Plain Report", "Failed to export report: "+err.Error())
+		return
+	}
+	if a.logs != nil {
+		a.logs.appendLine("INFO", "REPORT", "Plain report exported ("+profile+")", target)
+	}
+	backend.Notify(a.events, backend.NotificationInfo, "Export Plain Report", "Report saved to:\n"+target)
+}
+
+// ExportAIReviewPacketDialog saves the current run's highest-scoring
+// AI-likelihood passages, with their signals and duplicate comparisons, as an
+// HTML page an editor can read or print to PDF.
+func (a *App) ExportAIReviewPacketDialog() {
+	defer a.recoverFromPanic("ExportAIReviewPacketDialog")
+	if a.ctx == nil {
+		return
+	}
+	data := a.state.snapshot()
+	target, ok := a.saveDialog("Export AI Review Packet", data.ProjectLocation, "mhd-ai-review-"+time.Now().Format("20060102-150405")+".html", []runtime.FileFilter{
+		{DisplayName: "HTML", Pattern: "*.html;*.htm"},
+	})
+	if !ok {
+		return
+	}
+	text := a.state.sourceText()
+	if backend.MaskProfanityEnabled(data.ProjectLocation) {
+		data, text = backend.MaskProfanity(data), backend.MaskProfanityText(text)
+	}
+	packet := backend.AIReviewPacket(data, text, backend.DefaultAIPacketWindows)
+	if err := os.WriteFile(target, []byte(packet), 0o644); err != nil {
+		a.logProjectFailure("REPORT", "AI review packet export failed", err)
+		backend.Notify(a.events, backend.NotificationError, "Export AI Review Packet", "Failed to export packet: "+err.Error())
+		return
+	}
+	if a.logs != nil {
+		a.logs.appendLine("INFO", "REPORT", "AI review packet exported", target)
+	}
+	backend.Notify(a.events, backend.NotificationInfo, "Export AI Review Packet", "Packet saved to:\n"+target+"\nOpen it in a browser and print to save a PDF.")
+}
+
+func (a *App) ExportStatsDialog() {
+	defer a.recoverFromPanic("ExportStatsDialog")
+	if a.ctx == nil {
+		return
+	}
+	data := a.state.snapshot()
+	target, ok := a.saveDialog("Export Statistics", data.ProjectLocation, "mhd-stats-"+time.Now().Format("20060102-150405")+".csv", []runtime.FileFilter{
+		{DisplayName: "CSV", Pattern: "*.csv"},
+	})
+	if !ok {
 		return
 	}
-	defaultDir := a.logs.RootDir()
+	if err := os.WriteFile(target, []byte(backend.StatsCSV(data.Stats)), 0o644); err != nil {
+		a.logProjectFailure("REPORT", "Statistics export failed", err)
+		backend.Notify(a.events, backend.NotificationError, "Export Statistics", "Failed to export statistics: "+err.Error())
+		return
+	}
+	if a.logs != nil {
+		a.logs.appendLine("INFO", "REPORT", "Statistics exported", target)
+	}
+	backend.Notify(a.events, backend.NotificationInfo, "Export Statistics", "Statistics saved to:\n"+target)
+}
+
+// saveDialog asks where to save an export, starting in ~/Downloads when it
+// exists and in dir otherwise. The target keeps an extension the filters
+// accept and gets the first filter's otherwise. ok is false when the user
+// cancelled or the dialog failed, which it reports under title.
+func (a *App) saveDialog(title, dir, defaultName string, filters []runtime.FileFilter) (target string, ok bool) {
 	if home, homeErr := os.UserHomeDir(); homeErr == nil {
 		downloads := filepath.Join(home, "Downloads")
 		if stat, statErr := os.Stat(downloads); statErr == nil && stat.IsDir() {
-			defaultDir = downloads
+			dir = downloads
 		}
 	}
 	target, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title:            "Export Log Package",
-		DefaultDirectory: defaultDir,
-		DefaultFilename:  "mhd-log-package-" + time.Now().Format("20060102-150405") + ".zip",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "ZIP Archive", Pattern: "*.zip"},
-		},
+		Title:            title,
+		DefaultDirectory: dir,
+		DefaultFilename:  defaultName,
+		Filters:          filters,
 	})
 	if err != nil {
-		_, _ = runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-			Type:    runtime.ErrorDialog,
-			Title:   "Export Log Package",
-			Message: "Could not open save dialog: " + err.Error(),
-		})
-		return
+		backend.Notify(a.events, backend.NotificationError, title, "Could not open save dialog: "+err.Error())
+		return "", false
 	}
 	target = strings.TrimSpace(target)
 	if target == "" {
-		return
+		return "", false
 	}
-	if !strings.HasSuffix(strings.ToLower(target), ".zip") {
-		target += ".zip"
+	ext := strings.ToLower(filepath.Ext(target))
+	for _, f := range filters {
+		for _, pattern := range strings.Split(f.Pattern, ";") {
+			if ext != "" && strings.TrimPrefix(pattern, "*") == ext {
+				return target, true
+			}
+		}
 	}
-	if err := a.logs.exportZip(target); err != nil {
-		_, _ = runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-			Type:    runtime.ErrorDialog,
-			Title:   "Export Log Package",
-			Message: "Failed to export logs: " + err.Error(),
-		})
-		return
+	if len(filters) > 0 {
+		target += strings.TrimPrefix(strings.Split(filters[0].Pattern, ";")[0], "*")
 	}
-	a.logs.appendLine("INFO", "LOGS", "Log package exported", target)
-	_, _ = runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    runtime.InfoDialog,
-		Title:   "Export Log Package",
-		Message: "Log package created at:\n" + target,
-	})
+	return target, true
 }
 
 func (a *App) Quit() {
@@ -351,7 +1115,7 @@ func (a *App) ReportClientError(source, message, detail string) {
 	if line.Message == ":" || line.Message == "" {
 		line.Message = "frontend runtime error"
 	}
-	a.data.Logs = append(a.data.Logs, line)
+	a.appendLogLine(line)
 	if a.logs != nil {
 		a.logs.appendLine(line.Level, line.Stage, line.Message, line.Detail)
 	}
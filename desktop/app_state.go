@@ -0,0 +1,152 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"book_dashboard/desktop/backend"
+)
+
+// appState guards the dashboard shared by bound methods, the startup
+// goroutine and background work. Readers get copies so callers can append to
+// logs without touching the stored slice.
+type appState struct {
+	mu   sync.RWMutex
+	data backend.DashboardData
+	text string
+
+	// runMu serializes interactive analysis runs, which hold it exclusively;
+	// background queue runs share it with each other. GetDashboard never
+	// waits on it.
+	runMu sync.RWMutex
+}
+
+func newAppState() *appState {
+	return &appState{data: backend.DashboardData{}}
+}
+
+func (s *appState) snapshot() backend.DashboardData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneDashboard(s.data)
+}
+
+func (s *appState) sourceText() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.text
+}
+
+func (s *appState) projectLocation() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.ProjectLocation
+}
+
+// replace stores the result of a completed run together with the text it was
+// computed from.
+func (s *appState) replace(data backend.DashboardData, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = cloneDashboard(data)
+	s.text = text
+}
+
+func (s *appState) update(fn func(*backend.DashboardData)) backend.DashboardData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.data)
+	return cloneDashboard(s.data)
+}
+
+func (s *appState) lockRun() func() {
+	s.runMu.Lock()
+	return s.runMu.Unlock
+}
+
+// lockQueuedRun lets background queue runs proceed side by side while
+// keeping them apart from interactive runs.
+func (s *appState) lockQueuedRun() func() {
+	s.runMu.RLock()
+	return s.runMu.RUnlock
+}
+
+// waitForRun reports whether the in-flight runs, if any, finished within
+// timeout.
+func (s *appState) waitForRun(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.runMu.Lock()
+		s.runMu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// cloneDashboard deep-copies d, so the stored dashboard and the copies
+// handed out never share a slice, map or pointer.
+func cloneDashboard(d backend.DashboardData) backend.DashboardData {
+	return deepCopy(reflect.ValueOf(d)).Interface().(backend.DashboardData)
+}
+
+// deepCopy copies v, following pointers, slices, maps and interfaces.
+// Unexported struct fields are copied as they are, which keeps values such as
+// time.Time whole.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopy(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopy(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"book_dashboard/desktop/backend"
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/gates"
+)
+
+// Run with -race: bound methods are invoked concurrently by the Wails runtime.
+func TestAppStateConcurrentDashboardAccess(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", "")
+	t.Setenv("MHD_DISABLE_SYSTEM_BIN_FALLBACK", "1")
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:9")
+	t.Setenv("AI_ENABLE_LANGUAGE_TOOL", "0")
+
+	app := NewApp()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := app.AnalyzeExcerpt(backend.DefaultDemoText)
+			if data.BookTitle != "Pasted Excerpt" {
+				t.Errorf("expected excerpt analysis, got %q", data.BookTitle)
+			}
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				data := app.GetDashboard()
+				data.Logs = append(data.Logs, backend.LogLine{Message: "local copy only"})
+				app.ListAnnotations()
+				app.GetReportSections()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 10; j++ {
+			app.ReportClientError("test", "concurrent error", "")
+		}
+	}()
+	wg.Wait()
+
+	final := app.GetDashboard()
+	if final.BookTitle != "Pasted Excerpt" {
+		t.Fatalf("expected final dashboard from excerpt run, got %q", final.BookTitle)
+	}
+	for _, line := range final.Logs {
+		if line.Message == "local copy only" {
+			t.Fatal("expected caller-side log appends not to leak into shared state")
+		}
+	}
+}
+
+func TestShutdownCancelsInFlightRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", "")
+	t.Setenv("MHD_DISABLE_SYSTEM_BIN_FALLBACK", "1")
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:9")
+	t.Setenv("AI_ENABLE_LANGUAGE_TOOL", "0")
+
+	app := NewApp()
+	app.cancelRun()
+	data := app.AnalyzeExcerpt(backend.DefaultDemoText)
+	if data.RunStats.Status != "CANCELLED" {
+		t.Fatalf("expected a cancelled run, got status %q", data.RunStats.Status)
+	}
+	raw, err := os.ReadFile(filepath.Join(data.ProjectLocation, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), `"analysis"`) {
+		t.Fatalf("a cancelled run must not overwrite report.json:\n%s", raw)
+	}
+}
+
+func TestWaitForRunHonorsGracePeriod(t *testing.T) {
+	state := newAppState()
+	unlock := state.lockRun()
+	if state.waitForRun(10 * time.Millisecond) {
+		t.Fatal("expected the wait to time out while a run holds the lock")
+	}
+	unlock()
+	if !state.waitForRun(time.Second) {
+		t.Fatal("expected the wait to succeed once the run finished")
+	}
+}
+
+func TestAppStateCopiesShareNothingWithTheStoredDashboard(t *testing.T) {
+	value, pDoc := 64.0, 0.4
+	stored := backend.DashboardData{
+		BookTitle:    "Salt",
+		Logs:         []backend.LogLine{{Message: "started"}},
+		Annotations:  []backend.Annotation{{ID: 1, Note: "check this"}},
+		QualityGates: []gates.Result{{ID: "grammar", Value: &value}},
+		Benchmarks:   []backend.Benchmark{{Metric: "mhd_score", Percentile: 40}},
+		AIReport: aidetect.Report{
+			PAIDoc:       &pDoc,
+			Windows:      []aidetect.WindowReport{{WindowID: "w1", PAI: 0.2}},
+			Intensifiers: []string{"very"},
+		},
+		Sections: map[string]string{"ai": "on"},
+	}
+	state := newAppState()
+	state.replace(stored, "")
+	want := cloneDashboard(stored)
+	if !reflect.DeepEqual(want, stored) {
+		t.Fatalf("expected the clone to equal the original, got %+v", want)
+	}
+
+	dup := state.snapshot()
+	dup.Logs[0].Message = "changed"
+	dup.Annotations[0].Note = "changed"
+	*dup.QualityGates[0].Value = 0
+	dup.Benchmarks[0].Percentile = 0
+	*dup.AIReport.PAIDoc = 1
+	dup.AIReport.Windows[0].PAI = 1
+	dup.AIReport.Intensifiers[0] = "changed"
+	dup.Sections["ai"] = "off"
+	stored.Annotations[0].Note = "changed by the caller"
+
+	if got := state.snapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("mutating a copy changed the stored dashboard: %+v", got)
+	}
+}
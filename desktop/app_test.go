@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"book_dashboard/desktop/backend"
 )
 
 func TestAnalyzeFilePersistsReport(t *testing.T) {
@@ -38,6 +40,67 @@ func TestAnalyzeFilePersistsReport(t *testing.T) {
 	}
 }
 
+func TestOpenProjectRestoresSavedDashboard(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:1/v2/check")
+
+	docxPath := filepath.Join(t.TempDir(), "Sample.docx")
+	if err := os.WriteFile(docxPath, buildDOCX(t), 0o644); err != nil {
+		t.Fatalf("write docx: %v", err)
+	}
+	analyzed := NewApp().AnalyzeFile(docxPath)
+
+	app := NewApp()
+	projects := app.ListProjects()
+	if len(projects) != 1 || projects[0].Root != analyzed.ProjectLocation || projects[0].WordCount != analyzed.WordCount {
+		t.Fatalf("expected the analyzed project listed, got %+v", projects)
+	}
+	data := app.OpenProject(projects[0].ID)
+	if data.WordCount != analyzed.WordCount || data.MHDScore != analyzed.MHDScore || data.ProjectLocation != analyzed.ProjectLocation {
+		t.Fatalf("expected the saved dashboard restored, got words=%d score=%d location=%s", data.WordCount, data.MHDScore, data.ProjectLocation)
+	}
+	if data.SourceIntegrity == nil || data.SourceIntegrity.Status != "unchanged" || app.state.sourceText() == "" {
+		t.Fatalf("expected the unchanged source read back, got %+v", data.SourceIntegrity)
+	}
+
+	before := app.GetDashboard()
+	if got := app.OpenProject("../" + projects[0].ID); got.WordCount != before.WordCount || len(got.Logs) != len(before.Logs)+1 {
+		t.Fatal("expected an invalid project id to leave the dashboard and log the failure")
+	}
+}
+
+func TestCompareRunsDiffsSavedRuns(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:1/v2/check")
+
+	docxPath := filepath.Join(t.TempDir(), "Sample.docx")
+	if err := os.WriteFile(docxPath, buildDOCX(t), 0o644); err != nil {
+		t.Fatalf("write docx: %v", err)
+	}
+	app := NewApp()
+	first := app.AnalyzeFile(docxPath)
+	second := app.AnalyzeFile(docxPath)
+	projects := app.ListProjects()
+	if len(projects) != 1 {
+		t.Fatalf("expected one project, got %+v", projects)
+	}
+	runs := app.ListProjectRuns(projects[0].ID)
+	if len(runs) != 2 || runs[0] != first.RunStats.RunID || runs[1] != second.RunStats.RunID {
+		t.Fatalf("expected both runs saved in order, got %v", runs)
+	}
+	diff := app.CompareRuns(projects[0].ID, runs[0], runs[1])
+	if len(diff.Scores) == 0 || diff.Scores[0].Name != "MHD score" || diff.Scores[0].Before != float64(first.MHDScore) || diff.Scores[0].Delta != float64(second.MHDScore-first.MHDScore) {
+		t.Fatalf("expected the MHD score compared, got %+v", diff.Scores)
+	}
+	if missing := app.CompareRuns(projects[0].ID, runs[0], "run-20990101-000000.000"); len(missing.Scores) != 0 {
+		t.Fatalf("expected an empty diff for a missing run, got %+v", missing)
+	}
+}
+
 func buildDOCX(t *testing.T) []byte {
 	t.Helper()
 	var b bytes.Buffer
@@ -55,3 +118,61 @@ func buildDOCX(t *testing.T) []byte {
 	}
 	return b.Bytes()
 }
+
+func TestRemoveManuscriptDeletesStoredDataAndVerifies(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:1/v2/check")
+
+	docxPath := filepath.Join(t.TempDir(), "Secret.docx")
+	if err := os.WriteFile(docxPath, buildDOCX(t), 0o644); err != nil {
+		t.Fatalf("write docx: %v", err)
+	}
+	logs, err := newLogArchive()
+	if err != nil {
+		t.Fatalf("new log archive: %v", err)
+	}
+	t.Cleanup(logs.close)
+	app := NewApp()
+	app.logs = logs
+	data := app.AnalyzeFile(docxPath)
+	if data.ProjectLocation == "" {
+		t.Fatal("expected a project")
+	}
+	snapshots, _ := logs.listRunSnapshots()
+	if len(snapshots) == 0 {
+		t.Fatal("expected the run to leave a snapshot")
+	}
+
+	report := app.RemoveManuscript()
+	if !report.Verified || report.FilesDeleted == 0 {
+		t.Fatalf("expected verified deletions, got %+v", report)
+	}
+	kinds := map[string]bool{}
+	for _, d := range report.Deleted {
+		kinds[d.Kind] = true
+	}
+	for _, kind := range []string{"source", "report", "run_snapshot", "session_log"} {
+		if !kinds[kind] {
+			t.Fatalf("expected %s in the purge report, got %+v", kind, report.Deleted)
+		}
+	}
+	if _, err := os.Stat(data.ProjectLocation); !os.IsNotExist(err) {
+		t.Fatalf("expected project dir gone, got %v", err)
+	}
+	if snapshots, _ := logs.listRunSnapshots(); len(snapshots) != 0 {
+		t.Fatalf("expected snapshots gone, got %+v", snapshots)
+	}
+	logs.flush()
+	sessions, _ := filepath.Glob(filepath.Join(logs.RootDir(), "session-*.log"))
+	for _, path := range sessions {
+		raw, _ := os.ReadFile(path)
+		if bytes.Contains(raw, []byte(data.RunStats.RunID)) || bytes.Contains(raw, []byte("Secret")) {
+			t.Fatalf("expected no trace of the run in %s:\n%s", path, raw)
+		}
+	}
+	if got := app.GetDashboard(); got.ProjectLocation != "" || got.BookTitle != backend.InitialDashboard().BookTitle {
+		t.Fatalf("expected dashboard reset, got %q at %q", got.BookTitle, got.ProjectLocation)
+	}
+}
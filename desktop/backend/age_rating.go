@@ -0,0 +1,364 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	RatingLanguage   = "language"
+	RatingSex        = "sex"
+	RatingViolence   = "violence"
+	RatingSubstances = "substances"
+	RatingSelfHarm   = "self_harm"
+	RatingSuicide    = "suicide"
+)
+
+// ratingDimensions is the display order of sub-ratings.
+var ratingDimensions = []string{RatingLanguage, RatingSex, RatingViolence, RatingSubstances, RatingSelfHarm, RatingSuicide}
+
+var ratingLabels = map[string]string{
+	RatingLanguage:   "Strong language",
+	RatingSex:        "Sexual content",
+	RatingViolence:   "Violence",
+	RatingSubstances: "Drug and alcohol use",
+	RatingSelfHarm:   "Self-harm",
+	RatingSuicide:    "Suicide",
+}
+
+// Lexicon entries are single words or phrases of up to three words matched
+// against adjacent words.
+var contentLexicons = map[string]map[string]struct{}{
+	RatingLanguage:   {"fuck": {}, "shit": {}, "damn": {}, "bitch": {}, "asshole": {}, "bastard": {}},
+	RatingSex:        {"sex": {}, "nude": {}, "naked": {}, "erotic": {}, "orgasm": {}, "penetration": {}},
+	RatingViolence:   {"blood": {}, "kill": {}, "murder": {}, "gun": {}, "knife": {}, "stab": {}, "violent": {}},
+	RatingSubstances: {"drunk": {}, "whiskey": {}, "vodka": {}, "beer": {}, "cocaine": {}, "heroin": {}, "joint": {}, "pills": {}, "overdose": {}, "alcohol": {}, "drugs": {}, "meth": {}, "marijuana": {}, "weed": {}},
+	RatingSelfHarm:   {"self harm": {}, "selfharm": {}, "cut myself": {}, "cut herself": {}, "cut himself": {}, "cutting herself": {}, "cutting himself": {}, "burned herself": {}, "burned himself": {}, "razor blade": {}},
+	RatingSuicide:    {"suicide": {}, "suicidal": {}, "kill myself": {}, "kill herself": {}, "kill himself": {}, "killed herself": {}, "killed himself": {}, "noose": {}, "end my life": {}},
+}
+
+// contentDensityScale converts hits-per-word into the 0-100 dimension score.
+var contentDensityScale = map[string]int{
+	RatingLanguage:   1000,
+	RatingSex:        1200,
+	RatingViolence:   900,
+	RatingSubstances: 900,
+	RatingSelfHarm:   1500,
+	RatingSuicide:    1500,
+}
+
+type AgeBand struct {
+	Label  string `json:"label"`
+	MinAge int    `json:"min_age"`
+}
+
+type RubricThreshold struct {
+	MinScore int    `json:"min_score"`
+	MinAge   int    `json:"min_age"`
+	Level    string `json:"level"`
+}
+
+// AgeRubric maps per-dimension scores onto age bands. Thresholds are matched
+// highest-first; the strictest dimension decides the overall band.
+type AgeRubric struct {
+	Standard   string                       `json:"standard"`
+	Bands      []AgeBand                    `json:"bands"`
+	Dimensions map[string][]RubricThreshold `json:"dimensions"`
+}
+
+type RatingEvidence struct {
+	Chapter int    `json:"chapter"`
+	Title   string `json:"title"`
+	Term    string `json:"term"`
+	Quote   string `json:"quote"`
+}
+
+type RatingDimension struct {
+	Dimension string           `json:"dimension"`
+	Score     int              `json:"score"`
+	Instances int              `json:"instances"`
+	Level     string           `json:"level"`
+	Provider  string           `json:"provider"`
+	MinAge    int              `json:"minAge"`
+	Rating    string           `json:"rating"`
+	Chapters  []int            `json:"chapters"`
+	Evidence  []RatingEvidence `json:"evidence"`
+}
+
+type AgeRating struct {
+	Standard   string            `json:"standard"`
+	Overall    string            `json:"overall"`
+	MinAge     int               `json:"minAge"`
+	DrivenBy   []string          `json:"drivenBy"`
+	Dimensions []RatingDimension `json:"dimensions"`
+}
+
+// DefaultAgeRubric follows the Common Sense Media style of rating each
+// content dimension on a "not present" to "a lot" scale before choosing an
+// overall age band.
+func DefaultAgeRubric() AgeRubric {
+	return AgeRubric{
+		Standard: "common-sense-style",
+		Bands: []AgeBand{
+			{Label: "All Ages", MinAge: 0},
+			{Label: "Teen 13+", MinAge: 13},
+			{Label: "Mature 16+", MinAge: 16},
+			{Label: "Adult 18+", MinAge: 18},
+		},
+		Dimensions: map[string][]RubricThreshold{
+			RatingLanguage: {
+				{MinScore: 10, MinAge: 18, Level: "A lot"},
+				{MinScore: 6, MinAge: 16, Level: "Some"},
+				{MinScore: 2, MinAge: 13, Level: "A little"},
+			},
+			RatingSex: {
+				{MinScore: 8, MinAge: 18, Level: "A lot"},
+				{MinScore: 4, MinAge: 16, Level: "Some"},
+				{MinScore: 1, MinAge: 13, Level: "A little"},
+			},
+			RatingViolence: {
+				{MinScore: 20, MinAge: 18, Level: "A lot"},
+				{MinScore: 8, MinAge: 16, Level: "Some"},
+				{MinScore: 4, MinAge: 13, Level: "A little"},
+			},
+			RatingSubstances: {
+				{MinScore: 15, MinAge: 18, Level: "A lot"},
+				{MinScore: 8, MinAge: 16, Level: "Some"},
+				{MinScore: 3, MinAge: 13, Level: "A little"},
+			},
+			RatingSelfHarm: {
+				{MinScore: 10, MinAge: 18, Level: "A lot"},
+				{MinScore: 4, MinAge: 16, Level: "Some"},
+				{MinScore: 1, MinAge: 13, Level: "A little"},
+			},
+			RatingSuicide: {
+				{MinScore: 10, MinAge: 18, Level: "A lot"},
+				{MinScore: 4, MinAge: 16, Level: "Some"},
+				{MinScore: 1, MinAge: 13, Level: "A little"},
+			},
+		},
+	}
+}
+
+// LoadAgeRubric reads configs/age_rubric.json from the workspace, falling
+// back to the default rubric when no override exists.
+func LoadAgeRubric(workspaceRoot string) (AgeRubric, error) {
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return DefaultAgeRubric(), nil
+	}
+	raw, err := os.ReadFile(filepath.Join(workspaceRoot, "configs", "age_rubric.json"))
+	if os.IsNotExist(err) {
+		return DefaultAgeRubric(), nil
+	}
+	if err != nil {
+		return DefaultAgeRubric(), fmt.Errorf("read age rubric: %w", err)
+	}
+	var rubric AgeRubric
+	if err := json.Unmarshal(raw, &rubric); err != nil {
+		return DefaultAgeRubric(), fmt.Errorf("decode age rubric: %w", err)
+	}
+	if len(rubric.Bands) == 0 || len(rubric.Dimensions) == 0 {
+		return DefaultAgeRubric(), fmt.Errorf("age rubric needs bands and dimensions")
+	}
+	if rubric.Standard == "" {
+		rubric.Standard = "custom"
+	}
+	return rubric, nil
+}
+
+type contentHit struct {
+	chapter chapter
+	term    string
+}
+
+// scanContent counts lexicon hits per dimension and keeps the hits so each
+// sub-rating can cite chapter evidence.
+func scanContent(chapters []chapter) (map[string]int, map[string][]contentHit, int) {
+	counts := map[string]int{}
+	hits := map[string][]contentHit{}
+	totalWords := 0
+	for _, ch := range chapters {
+		words := wordPattern.FindAllString(strings.ToLower(ch.text), -1)
+		totalWords += len(words)
+		for i, w := range words {
+			terms := []string{w}
+			if i+1 < len(words) {
+				terms = append(terms, w+" "+words[i+1])
+			}
+			if i+2 < len(words) {
+				terms = append(terms, w+" "+words[i+1]+" "+words[i+2])
+			}
+			for dim, lexicon := range contentLexicons {
+				for _, term := range terms {
+					if _, ok := lexicon[term]; ok {
+						counts[dim]++
+						hits[dim] = append(hits[dim], contentHit{chapter: ch, term: term})
+					}
+				}
+			}
+		}
+	}
+	return counts, hits, totalWords
+}
+
+// contentScore converts a hit count into a 0-100 density. Any hit scores at
+// least 1 so rare but serious mentions (suicide, self-harm) are not rounded away.
+func contentScore(dimension string, count, totalWords int) int {
+	if count == 0 {
+		return 0
+	}
+	return clamp100(max(1, count*contentDensityScale[dimension]/max(1, totalWords)))
+}
+
+// rateAge applies the rubric to the supplied dimension scores. Scores from a
+// model override heuristic densities upstream; evidence always comes from the
+// lexicon scan so every sub-rating can point at chapters.
+func rateAge(chapters []chapter, scores map[string]int, rubric AgeRubric) AgeRating {
+	_, hits, _ := scanContent(chapters)
+	rating := AgeRating{Standard: rubric.Standard, DrivenBy: []string{}}
+	for _, dim := range ratingDimensionsFor(rubric) {
+		d := RatingDimension{Dimension: dim, Score: scores[dim], Level: "Not present", Chapters: []int{}, Evidence: []RatingEvidence{}}
+		thresholds := append([]RubricThreshold(nil), rubric.Dimensions[dim]...)
+		sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].MinScore > thresholds[j].MinScore })
+		for _, th := range thresholds {
+			if d.Score >= th.MinScore {
+				d.MinAge = th.MinAge
+				d.Level = th.Level
+				break
+			}
+		}
+		d.Rating = bandForAge(rubric.Bands, d.MinAge)
+		d.Instances = len(hits[dim])
+		seenChapter := map[int]bool{}
+		for _, h := range hits[dim] {
+			if !seenChapter[h.chapter.index] {
+				seenChapter[h.chapter.index] = true
+				d.Chapters = append(d.Chapters, h.chapter.index)
+			}
+			if len(d.Evidence) < 3 && !evidenceHasChapter(d.Evidence, h.chapter.index) {
+				d.Evidence = append(d.Evidence, RatingEvidence{
+					Chapter: h.chapter.index,
+					Title:   h.chapter.title,
+					Term:    h.term,
+					Quote:   quoteContaining(h.chapter.text, h.term, 24),
+				})
+			}
+		}
+		if d.MinAge > rating.MinAge {
+			rating.MinAge = d.MinAge
+		}
+		rating.Dimensions = append(rating.Dimensions, d)
+	}
+	for _, d := range rating.Dimensions {
+		if d.MinAge == rating.MinAge && rating.MinAge > 0 {
+			rating.DrivenBy = append(rating.DrivenBy, d.Dimension)
+		}
+	}
+	rating.Overall = bandForAge(rubric.Bands, rating.MinAge)
+	return rating
+}
+
+// rateContent combines the language report's profanity/explicit/violence
+// scores with lexicon densities for the remaining dimensions. When the model
+// returned scores for those dimensions they replace the densities, so keyword
+// hits the model does not confirm (e.g. "pills" as medication) drop out.
+func rateContent(chapters []chapter, base LanguageReport, modelScores map[string]int, rubric AgeRubric) AgeRating {
+	counts, _, totalWords := scanContent(chapters)
+	scores := map[string]int{}
+	providers := map[string]string{}
+	for _, dim := range ratingDimensions {
+		scores[dim] = contentScore(dim, counts[dim], totalWords)
+		providers[dim] = "heuristic"
+	}
+	scores[RatingLanguage] = base.ProfanityScore
+	scores[RatingSex] = base.ExplicitScore
+	scores[RatingViolence] = base.ViolenceScore
+	for _, dim := range []string{RatingLanguage, RatingSex, RatingViolence} {
+		providers[dim] = base.SafetyProvider
+	}
+	for dim, score := range modelScores {
+		scores[dim] = score
+		providers[dim] = "Ollama"
+	}
+	rating := rateAge(chapters, scores, rubric)
+	for i := range rating.Dimensions {
+		rating.Dimensions[i].Provider = providers[rating.Dimensions[i].Dimension]
+	}
+	return rating
+}
+
+func ratingDimensionsFor(rubric AgeRubric) []string {
+	out := []string{}
+	for _, dim := range ratingDimensions {
+		if _, ok := rubric.Dimensions[dim]; ok {
+			out = append(out, dim)
+		}
+	}
+	return out
+}
+
+func bandForAge(bands []AgeBand, minAge int) string {
+	label := "Unknown"
+	best := -1
+	for _, b := range bands {
+		if b.MinAge <= minAge && b.MinAge > best {
+			best = b.MinAge
+			label = b.Label
+		}
+	}
+	return label
+}
+
+func evidenceHasChapter(items []RatingEvidence, chapter int) bool {
+	for _, e := range items {
+		if e.Chapter == chapter {
+			return true
+		}
+	}
+	return false
+}
+
+// contentWarnings lists every dimension the rating found present, strictest
+// first, with the chapters that triggered it.
+func contentWarnings(rating AgeRating) []string {
+	present := []RatingDimension{}
+	for _, d := range rating.Dimensions {
+		if d.MinAge > 0 {
+			present = append(present, d)
+		}
+	}
+	sort.SliceStable(present, func(i, j int) bool { return present[i].MinAge > present[j].MinAge })
+	out := make([]string, 0, len(present))
+	for _, d := range present {
+		label := ratingLabels[d.Dimension]
+		if label == "" {
+			label = d.Dimension
+		}
+		warning := fmt.Sprintf("%s (%s)", label, strings.ToLower(d.Level))
+		if len(d.Chapters) > 0 {
+			chapters := make([]string, 0, len(d.Chapters))
+			for _, idx := range d.Chapters {
+				chapters = append(chapters, strconv.Itoa(idx))
+			}
+			warning += ": chapters " + strings.Join(chapters, ", ")
+		}
+		out = append(out, warning)
+	}
+	return out
+}
+
+// quoteContaining returns the first sentence mentioning term, trimmed to n words.
+func quoteContaining(text, term string, n int) string {
+	for _, s := range splitSentences(text) {
+		words := " " + strings.Join(wordPattern.FindAllString(strings.ToLower(s), -1), " ") + " "
+		if strings.Contains(words, " "+term+" ") {
+			return firstWords(s, n)
+		}
+	}
+	return ""
+}
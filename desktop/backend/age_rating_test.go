@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRateAgeCitesChapterEvidence(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "Chapter 1", text: "They walked to the river and talked about school."},
+		{index: 2, title: "Chapter 2", text: "He pulled the knife. There was blood on the floor."},
+	}
+	rating := rateAge(chapters, map[string]int{RatingViolence: 9}, DefaultAgeRubric())
+	if rating.Overall != "Mature 16+" || rating.MinAge != 16 {
+		t.Fatalf("expected violence to drive Mature 16+, got %+v", rating)
+	}
+	if len(rating.DrivenBy) != 1 || rating.DrivenBy[0] != RatingViolence {
+		t.Fatalf("expected violence as the driving dimension, got %v", rating.DrivenBy)
+	}
+	for _, d := range rating.Dimensions {
+		if d.Dimension != RatingViolence {
+			if d.Level != "Not present" {
+				t.Fatalf("expected %s not present, got %+v", d.Dimension, d)
+			}
+			continue
+		}
+		if len(d.Evidence) == 0 || d.Evidence[0].Chapter != 2 || d.Evidence[0].Quote == "" {
+			t.Fatalf("expected chapter 2 evidence for violence, got %+v", d.Evidence)
+		}
+	}
+}
+
+func TestLoadAgeRubricOverride(t *testing.T) {
+	root := t.TempDir()
+	if rubric, err := LoadAgeRubric(root); err != nil || rubric.Standard != "common-sense-style" {
+		t.Fatalf("expected default rubric without override, got %+v err=%v", rubric, err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "configs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := `{"standard":"house","bands":[{"label":"Everyone","min_age":0},{"label":"Grown-ups","min_age":18}],"dimensions":{"language":[{"min_score":1,"min_age":18,"level":"Any"}]}}`
+	if err := os.WriteFile(filepath.Join(root, "configs", "age_rubric.json"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rubric, err := LoadAgeRubric(root)
+	if err != nil {
+		t.Fatalf("load rubric: %v", err)
+	}
+	rating := rateAge(nil, map[string]int{RatingLanguage: 2, RatingViolence: 50}, rubric)
+	if rating.Overall != "Grown-ups" || len(rating.Dimensions) != 1 {
+		t.Fatalf("expected override rubric applied to configured dimensions only, got %+v", rating)
+	}
+}
+
+func TestRateContentModelConfirmsSensitiveDimensions(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "Chapter 1", text: "She swallowed the pills with whiskey."},
+		{index: 2, title: "Chapter 2", text: "He wrote that he wanted to kill himself. The note spoke of suicide."},
+	}
+	base := LanguageReport{SafetyProvider: "heuristic"}
+
+	heuristic := rateContent(chapters, base, nil, DefaultAgeRubric())
+	byDim := map[string]RatingDimension{}
+	for _, d := range heuristic.Dimensions {
+		byDim[d.Dimension] = d
+	}
+	if d := byDim[RatingSuicide]; d.Instances != 2 || d.MinAge == 0 || d.Provider != "heuristic" {
+		t.Fatalf("expected heuristic suicide hits to rate, got %+v", d)
+	}
+	if d := byDim[RatingSubstances]; d.Instances != 2 || len(d.Chapters) != 1 || d.Chapters[0] != 1 {
+		t.Fatalf("expected substance hits in chapter 1, got %+v", d)
+	}
+	warnings := contentWarnings(heuristic)
+	if len(warnings) == 0 || warnings[0] == "" {
+		t.Fatalf("expected content warnings, got %v", warnings)
+	}
+
+	confirmed := rateContent(chapters, base, map[string]int{RatingSubstances: 0, RatingSuicide: 12, RatingSelfHarm: 0}, DefaultAgeRubric())
+	for _, d := range confirmed.Dimensions {
+		switch d.Dimension {
+		case RatingSubstances:
+			if d.MinAge != 0 || d.Provider != "Ollama" {
+				t.Fatalf("expected unconfirmed substance hits to drop out, got %+v", d)
+			}
+		case RatingSuicide:
+			if d.MinAge != 18 {
+				t.Fatalf("expected model suicide score to drive Adult 18+, got %+v", d)
+			}
+		}
+	}
+	if confirmed.Overall != "Adult 18+" {
+		t.Fatalf("expected overall Adult 18+, got %s", confirmed.Overall)
+	}
+}
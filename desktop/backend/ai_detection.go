@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/workspace"
 )
 
 type aiLogger struct {
@@ -24,18 +25,71 @@ func (l aiLogger) Log(level, stage, message, detail string) {
 	}
 }
 
+// minCalibrationGenreShare is how clearly one genre must lead before its
+// calibration profile replaces the neutral one.
+const minCalibrationGenreShare = 0.30
+
+// aiCalibration picks the aidetect calibration profile from the manuscript's
+// genre mix. AI_GENRE_CALIBRATION=0 keeps the neutral profile.
+func aiCalibration(genreScores []GenreScore) aidetect.Calibration {
+	if strings.TrimSpace(os.Getenv("AI_GENRE_CALIBRATION")) == "0" {
+		return aidetect.NeutralCalibration()
+	}
+	genre, share := topGenre(genreScores)
+	if share < minCalibrationGenreShare {
+		return aidetect.NeutralCalibration()
+	}
+	return aidetect.CalibrationForGenre(genre)
+}
+
+type aiSensitivityKey struct{}
+
+// WithAISensitivity selects the AI-detection preset for the run using ctx,
+// overriding the project setting. An empty preset leaves the setting in force.
+func WithAISensitivity(ctx context.Context, preset string) context.Context {
+	return context.WithValue(ctx, aiSensitivityKey{}, preset)
+}
+
+// resolveAISensitivity picks the run override, then the project setting, then
+// balanced, and reports which one won.
+func resolveAISensitivity(ctx context.Context, settings workspace.ProjectSettings) (string, string) {
+	if preset, _ := ctx.Value(aiSensitivityKey{}).(string); aidetect.IsSensitivity(preset) {
+		return aidetect.NormalizeSensitivity(preset), "run"
+	}
+	if aidetect.IsSensitivity(settings.AISensitivity) {
+		return aidetect.NormalizeSensitivity(settings.AISensitivity), "project"
+	}
+	return aidetect.SensitivityBalanced, "default"
+}
+
+// SetAISensitivity stores the project's AI-detection preset; it applies from
+// the next analysis run.
+func SetAISensitivity(projectLocation, preset string) (string, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return "", fmt.Errorf("no project loaded")
+	}
+	if !aidetect.IsSensitivity(preset) {
+		return "", fmt.Errorf("unknown AI sensitivity preset %q", preset)
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return "", err
+	}
+	settings.AISensitivity = aidetect.NormalizeSensitivity(preset)
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return "", err
+	}
+	return settings.AISensitivity, nil
+}
+
 type aiLanguageToolScorer struct {
 	endpoint string
 	client   *http.Client
 }
 
 func newAILanguageToolScorer() aidetect.LanguageToolScorer {
-	endpoint := strings.TrimSpace(os.Getenv("LANGUAGETOOL_URL"))
-	if endpoint == "" {
-		endpoint = "http://localhost:8010/v2/check"
-	}
 	return aiLanguageToolScorer{
-		endpoint: endpoint,
+		endpoint: languageToolEndpoint(),
 		client:   &http.Client{Timeout: 8 * time.Second},
 	}
 }
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/workspace"
+)
+
+func TestAICalibrationFollowsLeadingGenre(t *testing.T) {
+	romance := []GenreScore{{Genre: "Romance", Score: 0.45}, {Genre: "Literary", Score: 0.25}}
+	if got := aiCalibration(romance).Profile; got != "romance" {
+		t.Fatalf("expected romance profile, got %q", got)
+	}
+	mixed := []GenreScore{{Genre: "Romance", Score: 0.22}, {Genre: "Thriller", Score: 0.21}}
+	if got := aiCalibration(mixed).Profile; got != "neutral" {
+		t.Fatalf("expected neutral profile without a clear genre lead, got %q", got)
+	}
+	t.Setenv("AI_GENRE_CALIBRATION", "0")
+	if got := aiCalibration(romance).Profile; got != "neutral" {
+		t.Fatalf("expected calibration disabled by env, got %q", got)
+	}
+}
+
+func TestLocateAIWindowsMapsWordRangesToParagraphs(t *testing.T) {
+	text := "Chapter 1\nOne two three four.\nFive six seven.\n\nChapter 2\nEight nine ten."
+	report := aidetect.Report{Windows: []aidetect.WindowReport{
+		{WindowID: "w-000", StartWord: 3, EndWord: 8},
+		{WindowID: "w-001", StartWord: 7, EndWord: 14},
+		{WindowID: "w-002", StartWord: 0, EndWord: 2},
+	}}
+	if placed := locateAIWindows(&report, text, splitChapters(text)); placed != 2 {
+		t.Fatalf("expected two windows placed, got %d", placed)
+	}
+	if got := aiWindowName(report.Windows[0]); got != "Ch 1, paragraphs 1–2" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if got := aiWindowName(report.Windows[1]); got != "Ch 1, paragraph 2 – Ch 2, paragraph 1" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if report.Windows[2].Location != nil || aiWindowName(report.Windows[2]) != "Words 0 to 2" {
+		t.Fatalf("expected the heading-only window to stay unplaced, got %+v", report.Windows[2].Location)
+	}
+}
+
+func TestAISensitivityRunOverridesProjectSetting(t *testing.T) {
+	project := t.TempDir()
+	if _, err := SetAISensitivity(project, "extreme"); err == nil {
+		t.Fatal("expected an unknown preset to be rejected")
+	}
+	if saved, err := SetAISensitivity(project, "Conservative"); err != nil || saved != aidetect.SensitivityConservative {
+		t.Fatalf("expected conservative saved, got %q (%v)", saved, err)
+	}
+	settings, err := workspace.LoadProjectSettings(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preset, source := resolveAISensitivity(context.Background(), settings); preset != aidetect.SensitivityConservative || source != "project" {
+		t.Fatalf("expected the project preset, got %s from %s", preset, source)
+	}
+	ctx := WithAISensitivity(context.Background(), aidetect.SensitivityAggressive)
+	if preset, source := resolveAISensitivity(ctx, settings); preset != aidetect.SensitivityAggressive || source != "run" {
+		t.Fatalf("expected the run preset, got %s from %s", preset, source)
+	}
+	if preset, source := resolveAISensitivity(WithAISensitivity(context.Background(), ""), workspace.ProjectSettings{}); preset != aidetect.SensitivityBalanced || source != "default" {
+		t.Fatalf("expected balanced by default, got %s from %s", preset, source)
+	}
+}
+
+func TestRollUpAIChaptersScoresEachChapter(t *testing.T) {
+	text := "Chapter 1\nOne two three four.\nFive six seven.\n\nChapter 2\nEight nine ten."
+	report := aidetect.Report{Windows: []aidetect.WindowReport{
+		{WindowID: "w-000", StartWord: 0, EndWord: 8, PAI: 0.2, Confidence: 1},
+		{WindowID: "w-001", StartWord: 8, EndWord: 14, PAI: 0.9, Confidence: 1},
+	}}
+	if scored := rollUpAIChapters(&report, text, splitChapters(text)); scored != 2 {
+		t.Fatalf("expected both chapters scored, got %d (%+v)", scored, report.PAIPerChapter)
+	}
+	one, two := report.PAIPerChapter[0], report.PAIPerChapter[1]
+	if one.Title != "Chapter 1" || one.StartWord != 2 || one.EndWord != 9 || two.StartWord != 11 || two.EndWord != 14 {
+		t.Fatalf("unexpected chapter spans %+v %+v", one.ChapterSpan, two.ChapterSpan)
+	}
+	if *two.PAI != 0.9 || *one.PAI >= *two.PAI {
+		t.Fatalf("expected chapter 2 to score higher, got %v and %v", *one.PAI, *two.PAI)
+	}
+	data := plainReportFixture()
+	data.AIReport.PAIPerChapter = report.PAIPerChapter
+	if plain := PlainReport(data); !strings.Contains(plain, "### By chapter\n\n1. Chapter 2: 90 percent\n2. Chapter 1:") {
+		t.Fatalf("expected chapters ranked in the plain report:\n%s", plain)
+	}
+}
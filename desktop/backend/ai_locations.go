@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+)
+
+// chapterAnchorWords is how many opening words of a chapter must match to
+// find where it starts among the manuscript's words.
+const chapterAnchorWords = 8
+
+// paragraphSpan is one paragraph's range of aidetect word offsets.
+type paragraphSpan struct {
+	chapter   int
+	paragraph int
+	start     int
+	end       int
+}
+
+// paragraphOffsets places every chapter paragraph on the word axis aidetect
+// windows are measured in. Headings and front matter outside the chapters
+// occupy words but no span. A chapter whose opening cannot be found is left
+// out, so windows over it stay unplaced instead of being misattributed.
+func paragraphOffsets(text string, chapters []chapter) []paragraphSpan {
+	words := aidetect.Words(text)
+	spans := []paragraphSpan{}
+	cursor := 0
+	for _, ch := range chapters {
+		lines := nonEmptyLines(ch.text)
+		if len(lines) > 1 && chapterHeaderPattern.MatchString(lines[0]) {
+			// Inline-split chapters keep their heading line, which is not a
+			// paragraph; the anchor search below steps over its words.
+			lines = lines[1:]
+		}
+		paragraphWords := make([][]string, len(lines))
+		var chapterWords []string
+		for i, line := range lines {
+			paragraphWords[i] = aidetect.Words(line)
+			chapterWords = append(chapterWords, paragraphWords[i]...)
+		}
+		if len(chapterWords) == 0 {
+			continue
+		}
+		start := indexWords(words, cursor, chapterWords[:min(len(chapterWords), chapterAnchorWords)])
+		if start < 0 {
+			continue
+		}
+		pos := start
+		for i, pw := range paragraphWords {
+			spans = append(spans, paragraphSpan{chapter: ch.index, paragraph: i + 1, start: pos, end: pos + len(pw)})
+			pos += len(pw)
+		}
+		cursor = pos
+	}
+	return spans
+}
+
+// locateAIWindows sets Location on every window that overlaps a mapped
+// paragraph and returns how many were placed.
+func locateAIWindows(report *aidetect.Report, text string, chapters []chapter) int {
+	spans := paragraphOffsets(text, chapters)
+	placed := 0
+	for i := range report.Windows {
+		w := &report.Windows[i]
+		if w.Location = placeWordRange(spans, w.StartWord, w.EndWord); w.Location != nil {
+			placed++
+		}
+	}
+	return placed
+}
+
+// attributeAISentences credits flagged windows' scores to their sentences
+// and places each sentence like a window. It returns how many sentences were
+// attributed.
+func attributeAISentences(report *aidetect.Report, text string, chapters []chapter) int {
+	n := aidetect.AttributeSentences(report, text, deepSentenceMinPAI, maxAttributedSentences)
+	spans := paragraphOffsets(text, chapters)
+	for i := range report.Sentences {
+		s := &report.Sentences[i]
+		s.Location = placeWordRange(spans, s.StartWord, s.EndWord)
+	}
+	return n
+}
+
+// placeWordRange locates a word range among the mapped paragraphs, or
+// returns nil when it overlaps none.
+func placeWordRange(spans []paragraphSpan, start, end int) *aidetect.WindowLocation {
+	first := sort.Search(len(spans), func(j int) bool { return spans[j].end > start })
+	last := sort.Search(len(spans), func(j int) bool { return spans[j].start >= end }) - 1
+	if first >= len(spans) || last < first {
+		return nil
+	}
+	loc := &aidetect.WindowLocation{
+		StartChapter:   spans[first].chapter,
+		StartParagraph: spans[first].paragraph,
+		EndChapter:     spans[last].chapter,
+		EndParagraph:   spans[last].paragraph,
+	}
+	loc.Label = windowLocationLabel(*loc)
+	return loc
+}
+
+// rollUpAIChapters scores each chapter found on the word axis from the
+// windows over it and returns how many chapters got a score.
+func rollUpAIChapters(report *aidetect.Report, text string, chapters []chapter) int {
+	titles := map[int]string{}
+	for _, ch := range chapters {
+		titles[ch.index] = ch.title
+	}
+	spans := []aidetect.ChapterSpan{}
+	for _, p := range paragraphOffsets(text, chapters) {
+		if n := len(spans); n > 0 && spans[n-1].Chapter == p.chapter {
+			spans[n-1].EndWord = p.end
+			continue
+		}
+		spans = append(spans, aidetect.ChapterSpan{Chapter: p.chapter, Title: titles[p.chapter], StartWord: p.start, EndWord: p.end})
+	}
+	aidetect.AggregateChapters(report, spans)
+	scored := 0
+	for _, ch := range report.PAIPerChapter {
+		if ch.PAI != nil {
+			scored++
+		}
+	}
+	return scored
+}
+
+func windowLocationLabel(loc aidetect.WindowLocation) string {
+	var label string
+	switch {
+	case loc.StartChapter != loc.EndChapter:
+		label = fmt.Sprintf("Ch %d, paragraph %d – Ch %d, paragraph %d", loc.StartChapter, loc.StartParagraph, loc.EndChapter, loc.EndParagraph)
+	case loc.StartParagraph == loc.EndParagraph:
+		label = fmt.Sprintf("Ch %d, paragraph %d", loc.StartChapter, loc.StartParagraph)
+	default:
+		label = fmt.Sprintf("Ch %d, paragraphs %d–%d", loc.StartChapter, loc.StartParagraph, loc.EndParagraph)
+	}
+	if ref := pageRef(loc.StartPage, loc.EndPage); ref != "" {
+		label += ", " + ref
+	}
+	return label
+}
+
+// aiWindowName is how a window is referred to in reports: its chapter and
+// paragraph location when known, otherwise its word range.
+func aiWindowName(w aidetect.WindowReport) string {
+	if w.Location != nil && w.Location.Label != "" {
+		return w.Location.Label
+	}
+	return fmt.Sprintf("Words %d to %d", w.StartWord, w.EndWord)
+}
+
+func nonEmptyLines(text string) []string {
+	out := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if trim := strings.TrimSpace(line); trim != "" {
+			out = append(out, trim)
+		}
+	}
+	return out
+}
+
+// indexWords returns the first position at or after from where needle occurs
+// in words, or -1.
+func indexWords(words []string, from int, needle []string) int {
+	for i := from; i+len(needle) <= len(words); i++ {
+		match := true
+		for j, w := range needle {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,227 @@
+package backend
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+)
+
+// DefaultAIPacketWindows is how many windows an AI review packet shows when
+// the caller does not say; ten passages read in about ten minutes.
+const DefaultAIPacketWindows = 10
+
+// evidenceWindowRe finds the window an evidence summary compares against,
+// such as "near-duplication with w-012 (jaccard=0.41)".
+var evidenceWindowRe = regexp.MustCompile(`\bw-\d+\b`)
+
+// AIReviewPacket renders the run's highest-scoring AI-likelihood windows as a
+// self-contained HTML page for an editor who has not seen the dashboard: the
+// full text of each passage, what every signal scored, and a word-by-word
+// comparison with any passage it duplicates. The page has a print stylesheet,
+// one passage per sheet, so printing it to PDF from a browser gives the PDF
+// version. Windows overlapping a higher-scoring one already in the packet, and
+// verified-human windows, are skipped. text is the manuscript the run
+// analyzed; without it the packet still lists scores and evidence.
+func AIReviewPacket(data DashboardData, text string, top int) string {
+	if top <= 0 {
+		top = DefaultAIPacketWindows
+	}
+	title := strings.TrimSpace(data.BookTitle)
+	if title == "" {
+		title = "Untitled manuscript"
+	}
+	report := data.AIReport
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>AI review packet: %s</title>\n", html.EscapeString(title))
+	b.WriteString(aiPacketStyle)
+	b.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>AI review packet: %s</h1>\n", html.EscapeString(title))
+	writeAIPacketSummary(&b, data)
+
+	windows := aiPacketWindows(report, top)
+	if len(windows) == 0 {
+		b.WriteString("<p>No passages were scored for AI likelihood in this run.</p>\n</body>\n</html>\n")
+		return b.String()
+	}
+	if strings.TrimSpace(text) == "" {
+		b.WriteString("<p class=\"note\">The manuscript text was not available when this packet was made, so passages are listed by location without their text.</p>\n")
+	}
+	byID := map[string]aidetect.WindowReport{}
+	for _, w := range report.Windows {
+		byID[w.WindowID] = w
+	}
+	for i, w := range windows {
+		writeAIPacketWindow(&b, i+1, w, byID, report, text)
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// aiPacketWindows picks up to top scored windows, highest first, that are not
+// verified human and do not overlap one already picked.
+func aiPacketWindows(report aidetect.Report, top int) []aidetect.WindowReport {
+	windows := append(report.Windows[:0:0], report.Windows...)
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].PAI > windows[j].PAI })
+	out := []aidetect.WindowReport{}
+	for _, w := range windows {
+		if len(out) == top {
+			break
+		}
+		if w.Exempt {
+			continue
+		}
+		overlaps := false
+		for _, picked := range out {
+			if w.StartWord < picked.EndWord && picked.StartWord < w.EndWord {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func writeAIPacketSummary(b *strings.Builder, data DashboardData) {
+	report := data.AIReport
+	b.WriteString("<section class=\"summary\">\n<ul>\n")
+	if data.RunStats.RunID != "" {
+		fmt.Fprintf(b, "<li>Run: %s, completed %s</li>\n", html.EscapeString(data.RunStats.RunID), html.EscapeString(data.RunStats.CompletedAt))
+	}
+	if report.PAIDoc != nil {
+		fmt.Fprintf(b, "<li>Whole manuscript: %s</li>\n", percentInWords(*report.PAIDoc))
+	}
+	if report.AICoverageEst != nil {
+		fmt.Fprintf(b, "<li>Estimated share of text with AI-like signals: %s</li>\n", percentInWords(*report.AICoverageEst))
+	}
+	if report.Sensitivity != "" {
+		fmt.Fprintf(b, "<li>Sensitivity preset: %s; signal weighting: %s</li>\n", html.EscapeString(report.Sensitivity), html.EscapeString(report.Weighting.Name))
+	}
+	for _, flag := range report.Flags {
+		fmt.Fprintf(b, "<li>Flag: %s</li>\n", html.EscapeString(flag))
+	}
+	b.WriteString("</ul>\n")
+	b.WriteString("<p>Each passage below is a window the detector scored as AI-like, highest first. The signal table shows what drove the score; a high duplication score with a comparison underneath means the passage repeats another one nearly word for word. These are leads for a human reader, not proof of authorship.</p>\n")
+	b.WriteString("</section>\n")
+}
+
+func writeAIPacketWindow(b *strings.Builder, n int, w aidetect.WindowReport, byID map[string]aidetect.WindowReport, report aidetect.Report, text string) {
+	b.WriteString("<section class=\"window\">\n")
+	fmt.Fprintf(b, "<h2>%d. %s: %s</h2>\n", n, html.EscapeString(aiWindowName(w)), percentInWords(w.PAI))
+	fmt.Fprintf(b, "<p class=\"meta\">Window %s, words %d to %d, confidence %.0f%%</p>\n", html.EscapeString(w.WindowID), w.StartWord, w.EndWord, w.Confidence*100)
+	if excerpt := wordSpanExcerpt(text, w.StartWord, w.EndWord, w.EndWord-w.StartWord); excerpt != "" {
+		fmt.Fprintf(b, "<blockquote>%s</blockquote>\n", html.EscapeString(excerpt))
+	}
+
+	weights := report.Weighting.WithoutLM
+	if w.Signals.LMSmoothness.Score != nil {
+		weights = report.Weighting.WithLM
+	}
+	b.WriteString("<table>\n<tr><th>Signal</th><th>Score</th><th>Weight</th></tr>\n")
+	for _, s := range []struct {
+		name   string
+		score  *float64
+		weight float64
+	}{
+		{"Duplication", w.Signals.Duplication.Score, weights.Duplication},
+		{"Semantic duplication", w.Signals.SemanticDuplication.Score, weights.SemanticDuplication},
+		{"Language-model smoothness", w.Signals.LMSmoothness.Score, weights.LMSmoothness},
+		{"Style uniformity", w.Signals.StyleUniform.Score, weights.StyleUniform},
+		{"Polish and cliché", w.Signals.PolishCliche.Score, weights.PolishCliche},
+		{"LanguageTool cleanliness", w.Signals.LanguageTool.Score, weights.LanguageTool},
+	} {
+		score := "not measured"
+		if s.score != nil {
+			score = fmt.Sprintf("%.2f", *s.score)
+		}
+		fmt.Fprintf(b, "<tr><td>%s</td><td>%s</td><td>%.2f</td></tr>\n", s.name, score, s.weight)
+	}
+	b.WriteString("</table>\n")
+
+	if len(w.TopEvidence) > 0 {
+		b.WriteString("<ul class=\"evidence\">\n")
+		for _, e := range w.TopEvidence {
+			fmt.Fprintf(b, "<li>%s: %s</li>\n", html.EscapeString(e.Type), html.EscapeString(e.Summary))
+		}
+		b.WriteString("</ul>\n")
+	}
+	writeAIPacketPairs(b, w, byID, text)
+	b.WriteString("</section>\n")
+}
+
+// writeAIPacketPairs compares the window word by word with each window its
+// duplication evidence names, recaps included.
+func writeAIPacketPairs(b *strings.Builder, w aidetect.WindowReport, byID map[string]aidetect.WindowReport, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	seen := map[string]bool{}
+	evidence := append(append(append([]aidetect.Evidence{}, w.Signals.Duplication.Evidence...), w.Signals.SemanticDuplication.Evidence...), w.Signals.Duplication.Recaps...)
+	for _, e := range evidence {
+		id := evidenceWindowRe.FindString(e.Summary)
+		other, ok := byID[id]
+		if id == "" || id == w.WindowID || seen[id] || !ok {
+			continue
+		}
+		seen[id] = true
+		a := strings.Fields(wordSpanExcerpt(text, w.StartWord, w.EndWord, w.EndWord-w.StartWord))
+		c := strings.Fields(wordSpanExcerpt(text, other.StartWord, other.EndWord, other.EndWord-other.StartWord))
+		if len(a) == 0 || len(c) == 0 {
+			continue
+		}
+		ops := aidetect.DiffWords(a, c)
+		same := 0
+		for _, op := range ops {
+			if op.Op == aidetect.DiffIdentical {
+				same += len(op.Words)
+			}
+		}
+		fmt.Fprintf(b, "<h3>Compared with %s (%s)</h3>\n", html.EscapeString(aiWindowName(other)), html.EscapeString(other.WindowID))
+		fmt.Fprintf(b, "<p class=\"meta\">%d of %d words identical and in order. <del>Struck</del> words appear only in this passage, <ins>underlined</ins> words only in the other.</p>\n", same, len(a))
+		b.WriteString("<p class=\"diff\">")
+		for i, op := range ops {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			words := html.EscapeString(strings.Join(op.Words, " "))
+			other := html.EscapeString(strings.Join(op.OtherWords, " "))
+			switch op.Op {
+			case aidetect.DiffRemoved:
+				fmt.Fprintf(b, "<del>%s</del>", words)
+			case aidetect.DiffAdded:
+				fmt.Fprintf(b, "<ins>%s</ins>", other)
+			case aidetect.DiffParaphrased:
+				fmt.Fprintf(b, "<del>%s</del> <ins>%s</ins>", words, other)
+			default:
+				b.WriteString(words)
+			}
+		}
+		b.WriteString("</p>\n")
+	}
+}
+
+const aiPacketStyle = `<style>
+body { font-family: Georgia, serif; max-width: 46em; margin: 2em auto; padding: 0 1em; color: #222; line-height: 1.5; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.25em; margin-top: 2em; }
+h3 { font-size: 1em; }
+.meta, .note { color: #555; font-size: 0.9em; }
+blockquote { margin: 1em 0; padding: 0.5em 1em; border-left: 3px solid #999; background: #f6f6f6; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+del { color: #a00; }
+ins { color: #060; }
+@media print {
+  body { margin: 0; max-width: none; }
+  .window { page-break-before: always; }
+  blockquote { background: none; }
+}
+</style>
+`
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+)
+
+func TestAIReviewPacketShowsTopWindowsWithPairDiffs(t *testing.T) {
+	p := func(v float64) *float64 { return &v }
+	words := make([]string, 0, 40)
+	for i := 0; i < 20; i++ {
+		words = append(words, "alpha")
+	}
+	for i := 0; i < 20; i++ {
+		words = append(words, "beta")
+	}
+	text := strings.Join(words, " ")
+	report := aidetect.Report{
+		PAIDoc:      p(0.7),
+		Sensitivity: aidetect.SensitivityBalanced,
+		Weighting:   aidetect.WeightingForName(aidetect.WeightingBalanced),
+		Windows: []aidetect.WindowReport{
+			{WindowID: "w-000", StartWord: 0, EndWord: 20, PAI: 0.9, Signals: aidetect.WindowSignals{
+				Duplication: aidetect.DuplicationSignal{Score: p(0.8), Evidence: []aidetect.Evidence{{Type: "duplication", Summary: "near-duplication with w-002 (jaccard=0.60)"}}},
+			}},
+			{WindowID: "w-001", StartWord: 10, EndWord: 30, PAI: 0.8},
+			{WindowID: "w-002", StartWord: 20, EndWord: 40, PAI: 0.5},
+			{WindowID: "w-003", StartWord: 0, EndWord: 40, PAI: 0.95, Exempt: true},
+		},
+	}
+	packet := AIReviewPacket(DashboardData{BookTitle: "Pile <1>", AIReport: report}, text, 5)
+
+	if !strings.Contains(packet, "<title>AI review packet: Pile &lt;1&gt;</title>") {
+		t.Fatalf("expected an escaped title, got %s", packet)
+	}
+	if strings.Contains(packet, "(w-001)") || strings.Contains(packet, "Window w-001") || strings.Contains(packet, "Window w-003") {
+		t.Fatalf("expected overlapping and verified-human windows skipped, got %s", packet)
+	}
+	if strings.Index(packet, "Window w-000") > strings.Index(packet, "Window w-002") {
+		t.Fatalf("expected windows highest first")
+	}
+	if !strings.Contains(packet, "Compared with Words 20 to 40 (w-002)") || !strings.Contains(packet, "0 of 20 words identical") {
+		t.Fatalf("expected a comparison with the duplicate window, got %s", packet)
+	}
+	if !strings.Contains(packet, "<td>Duplication</td><td>0.80</td><td>0.50</td>") || !strings.Contains(packet, "<td>Language-model smoothness</td><td>not measured</td>") {
+		t.Fatalf("expected the signal breakdown, got %s", packet)
+	}
+
+	without := AIReviewPacket(DashboardData{AIReport: report}, "", 0)
+	if !strings.Contains(without, "without their text") || strings.Contains(without, "Compared with") {
+		t.Fatalf("expected no text or comparisons without the manuscript, got %s", without)
+	}
+}
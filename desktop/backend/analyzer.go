@@ -1,21 +1,37 @@
 package backend
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"book_dashboard/internal/aidetect"
 	"book_dashboard/internal/chunk"
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/resources"
+	"book_dashboard/internal/scheduler"
 	"book_dashboard/internal/slop"
 	"book_dashboard/internal/timeline"
 	"book_dashboard/internal/workspace"
 )
 
 func BuildDashboard(bookTitle, sourceName string, source []byte, text string, onProgress ProgressFn) DashboardData {
+	return BuildDashboardContext(context.Background(), bookTitle, sourceName, source, text, onProgress)
+}
+
+// BuildDashboardContext is BuildDashboard with a run context. Once ctx is
+// cancelled the run stops reporting progress, skips the remaining model-backed
+// stages (falling back to heuristics where a result is required) and returns
+// with status CANCELLED without overwriting the project's report.json.
+func BuildDashboardContext(ctx context.Context, bookTitle, sourceName string, source []byte, text string, onProgress ProgressFn) DashboardData {
 	started := time.Now()
 	runID := "run-" + started.Format("20060102-150405.000")
 	stats := RunStats{
@@ -26,8 +42,16 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 		StartedAt:  started.Format(time.RFC3339),
 	}
 
+	timer := newStageTimer(started)
+	words := len(strings.Fields(text))
+	track := newProgressTracker(ctx, onProgress, timer, started, words)
+	monitor := resources.Start(resourceSampleInterval())
+	limiters := newRunLimiters(monitor)
 	logs := []LogLine{}
+	var logMu sync.Mutex
 	addLog := func(level, stage, message, detail string) {
+		logMu.Lock()
+		defer logMu.Unlock()
 		if os.Getenv("MHD_TRACE_PROGRESS") == "1" {
 			fmt.Printf("%s [ANALYSIS] [%s] [%s] %s | %s\n", time.Now().Format("15:04:05.000"), level, stage, message, detail)
 		}
@@ -40,10 +64,21 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 		})
 	}
 
+	var cancelLogged sync.Once
+	cancelled := func(stage string) bool {
+		if ctx.Err() == nil {
+			return false
+		}
+		cancelLogged.Do(func() {
+			addLog("RISK", stage, "Run cancelled; skipping remaining model stages", ctx.Err().Error())
+		})
+		return true
+	}
+
 	addLog("INFO", "BOOT", "Run started", fmt.Sprintf("id=%s source=%s", runID, sourceName))
-	progress(onProgress, 2, "BOOT", "Run started")
+	track.progress(2, "BOOT", "Run started")
 	addLog("INFO", "WORKSPACE", "Workspace initialization started", "")
-	progress(onProgress, 6, "WORKSPACE", "Initializing workspace")
+	track.progress(6, "WORKSPACE", "Initializing workspace")
 
 	workspaceRoot, err := workspace.EnsureDefault()
 	if err != nil {
@@ -54,47 +89,213 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 
 	projectPath := ""
 	reportPath := ""
+	reportRevision := 0
+	projectDBPath := ""
+	projectID, projectSourceName, projectSourceSHA := "", "", ""
+	var prior *PriorAnalysis
+	settings := workspace.ProjectSettings{}
+	var duplicationIgnore []*regexp.Regexp
 	if workspaceRoot != "" {
+		if disk := workspace.CheckDiskSpace(workspaceRoot, len(source)); disk.Low() && disk.Sufficient() {
+			addLog("RISK", "PROJECT", "Low disk space", fmt.Sprintf("available_mb=%d required_mb=%d path=%s", disk.AvailableBytes>>20, disk.RequiredBytes>>20, workspaceRoot))
+		}
 		project, projectErr := workspace.CreateProjectWithSource(workspaceRoot, bookTitle, sourceName, source)
-		if projectErr != nil {
+		if errors.Is(projectErr, workspace.ErrInsufficientDisk) {
+			addLog("RISK", "PROJECT", "Not enough disk space; project files and report will not be written", projectErr.Error())
+		} else if projectErr != nil {
 			addLog("RISK", "PROJECT", "Project initialization failed", projectErr.Error())
 		} else {
 			projectPath = project.Root
 			reportPath = project.ReportPath
+			reportRevision = project.ReportRevision
+			projectDBPath = project.DBPath
+			projectID = project.ID
+			projectSourceName, projectSourceSHA = filepath.Base(project.SourcePath), project.SourceSHA256
 			addLog("ANALYSIS", "PROJECT", "Project created", project.Root)
+			if project.Prior != nil {
+				prior = &PriorAnalysis{Title: project.Prior.Title, SourceName: project.Prior.SourceName, LastAnalyzedAt: project.Prior.LastAnalyzedAt}
+				addLog("INFO", "PROJECT", "Manuscript analyzed before", fmt.Sprintf("title=%q source=%s last_analyzed_at=%s", prior.Title, prior.SourceName, prior.LastAnalyzedAt))
+			}
+			loaded, settingsErr := workspace.LoadProjectSettings(project.Root)
+			if settingsErr != nil {
+				addLog("RISK", "PROJECT", "Project settings unreadable; all sections enabled", settingsErr.Error())
+			} else {
+				settings = loaded
+			}
+			ignored, ignoreErr := workspace.LoadDuplicationIgnore(project.Root)
+			if ignoreErr != nil {
+				addLog("RISK", "PROJECT", "Duplication ignore list has unusable lines", ignoreErr.Error())
+			}
+			if len(ignored) > 0 {
+				duplicationIgnore = ignored
+				addLog("INFO", "PROJECT", "Duplication ignore list loaded", fmt.Sprintf("patterns=%d", len(ignored)))
+			}
+			if len(source) > 0 {
+				archived, archiveErr := workspace.ArchiveRunSource(project.Root, runID, sourceName, source, settings.SourceRetention())
+				if archiveErr != nil {
+					addLog("RISK", "PROJECT", "Run source archive failed", archiveErr.Error())
+				}
+				if archived != "" {
+					stats.SourceArchivePath = archived
+					addLog("ANALYSIS", "PROJECT", "Run source archived", fmt.Sprintf("path=%s keep=%d", archived, settings.SourceRetention()))
+				}
+			}
+		}
+	}
+	sections := sectionStatuses(settings)
+	profile := analysisProfileFromContext(ctx)
+	stats.Profile = profile
+	profileSections(profile, sections, settings)
+	addLog("INFO", "PROJECT", "Analysis profile selected", "profile="+profile)
+	inference := startInferenceTelemetry(func(m ModelInference) {
+		addLog("RISK", "RESOURCES", "Model running on CPU", inferenceFallbackWarning(m, profile))
+	})
+	defer inference.stop()
+	for _, name := range ReportSections {
+		if sections[name] == SectionStatusDisabled {
+			addLog("INFO", "PROJECT", "Section disabled by project settings", name)
 		}
 	}
-	progress(onProgress, 12, "PROJECT", "Project initialized")
+	var stageHistory map[string]float64
+	if projectDBPath != "" {
+		rates, historyErr := db.StageRates(projectDBPath, progressHistoryRuns)
+		if historyErr != nil {
+			addLog("RISK", "PROJECT", "Stage history unreadable; default progress weights used", historyErr.Error())
+		} else {
+			stageHistory = rates
+		}
+	}
+	skipStages := map[string]bool{"SENSITIVITY": sections[SectionSensitivity] != SectionStatusEnabled}
+	track.planStages(stageHistory, skipStages)
+	var modelDrift []ModelDrift
+	if workspaceRoot != "" && profile != ProfileQuick && !cancelled("PROJECT") {
+		modelDrift = checkModelDrift(workspaceRoot, []driftCheck{
+			{model: ollamaModel("OLLAMA_GENRE_MODEL", "OLLAMA_LANGUAGE_MODEL"), genre: true},
+			{model: ollamaModel("OLLAMA_LANGUAGE_MODEL"), safety: sections[SectionSafety] == SectionStatusEnabled},
+		}, addLog)
+	}
+	track.mark("PROJECT")
+	track.progress(progressPlanStart, "PROJECT", "Project initialized")
 
-	words := len(strings.Fields(text))
-	chapters := splitChapters(text)
+	cache := newStageCache(ctx, workspaceRoot, text)
+	if cache.refresh {
+		addLog("INFO", "PROJECT", "Stage cache bypassed; cached results will be recomputed", "")
+	}
+
+	anthology, anthologySource := resolveAnthology(ctx, settings)
+	manuscriptType, manuscriptTypeSource := resolveManuscriptType(ctx, settings)
+	addLog("INFO", "PROJECT", "Manuscript type selected", fmt.Sprintf("type=%s source=%s", manuscriptType, manuscriptTypeSource))
+	var chapters []chapter
+	var stories []story
+	storyBoundaries := ""
+	if anthology {
+		stories, storyBoundaries = detectStories(text)
+		chapters = storiesAsChapters(stories)
+		addLog("ANALYSIS", "CHAPTER", "Story boundaries detected", fmt.Sprintf("stories=%d detection=%s source=%s", len(stories), storyBoundaries, anthologySource))
+		if storyBoundaries == StoryBoundaryChapters {
+			addLog("RISK", "CHAPTER", "No story titles or bylines found; chapters analyzed as stories", "")
+		}
+	} else {
+		chapters = splitChapters(text)
+	}
 	stats.ChapterCount = len(chapters)
+	track.setChapters(len(chapters))
 	addLog("ANALYSIS", "CHAPTER", "Chapter scan completed", strconv.Itoa(len(chapters))+" chapters")
-	progress(onProgress, 18, "CHAPTER", fmt.Sprintf("%d chapters detected", len(chapters)))
+	track.stage("INGEST", 0.5, fmt.Sprintf("%d chapters detected", len(chapters)))
 
 	segments := chunk.SlidingWindow(text, 1500, 200)
 	stats.SegmentCount = len(segments)
 	addLog("ANALYSIS", "INGEST", "Chunking completed", strconv.Itoa(len(segments))+" segments")
-	progress(onProgress, 24, "INGEST", fmt.Sprintf("%d segments created", len(segments)))
+	track.mark("INGEST")
+	track.stage("INGEST", 1, fmt.Sprintf("%d segments created", len(segments)))
 
 	chapterMetrics := make([]ChapterMetric, 0, len(chapters))
 	genreClassifier := newGenreClassifier()
 	allGenreRaw := map[string]float64{}
 	genreReasoningLines := make([]string, 0, len(chapters))
 	providerHits := map[string]int{}
+	chapterCount := float64(len(chapters))
+	chapterWords := make([]int, len(chapters))
 	for idx, ch := range chapters {
-		chapterProgressStart := 24
-		chapterProgressEnd := 50
-		if len(chapters) > 0 {
-			chapterProgressStart = 24 + int(float64(idx)/float64(len(chapters))*26.0)
-			chapterProgressEnd = 24 + int(float64(idx+1)/float64(len(chapters))*26.0)
+		chapterWords[idx] = len(strings.Fields(ch.text))
+	}
+
+	// Genre calls fan out under the adaptive limiter and fill the first 90%
+	// of the stage; metrics are then assembled in chapter order.
+	genreDecisions := make([]genreDecision, len(chapters))
+	genreFingerprint := stageFingerprint(genreClassifier.model, genreOrder)
+	cachedGenre := map[string]genreDecision{}
+	if _, cacheErr := cache.load(CachedStageGenre, genreFingerprint, &cachedGenre); cacheErr != nil {
+		addLog("RISK", "CHAPTER", "Genre cache unreadable; chapters will be classified again", cacheErr.Error())
+	}
+	genreCacheHits := 0
+	if profile == ProfileQuick {
+		genreClassifier.stop("quick scan runs offline")
+	}
+	var classifiedMu sync.Mutex
+	classified := 0
+	track.stage("CHAPTER", 0, fmt.Sprintf("Classifying genre for %d chapters", len(chapters)))
+	stopHeartbeat := heartbeat(modelHeartbeatInterval, func(elapsed time.Duration) {
+		classifiedMu.Lock()
+		defer classifiedMu.Unlock()
+		track.stage("CHAPTER", float64(classified)/chapterCount*0.9, fmt.Sprintf("%d/%d chapters classified: waiting on genre model (%s, %d at a time)", classified, len(chapters), elapsed, limiters.genreModel.Limit()))
+	})
+	scheduler.Each(ctx, limiters.genreModel, chapterWords, func(_ context.Context, idx int) error {
+		if cancelled("CHAPTER") {
+			genreClassifier.stop("run cancelled")
+		}
+		decision, hit := cachedGenre[chapterKey(chapters[idx])]
+		if !hit {
+			decision = genreClassifier.classifyChapter(chapters[idx])
+		}
+		genreDecisions[idx] = decision
+		classifiedMu.Lock()
+		defer classifiedMu.Unlock()
+		classified++
+		if hit {
+			genreCacheHits++
+		}
+		label := fmt.Sprintf("Chapter %d/%d: genre classified (%d/%d done)", idx+1, len(chapters), classified, len(chapters))
+		if latency := genreClassifier.latency(); latency != "" {
+			label += " (" + latency + ")"
+		}
+		track.stageChapter("CHAPTER", float64(classified)/chapterCount*0.9, idx+1, label)
+		if decision.Provider == "heuristic" {
+			return errGenreHeuristic
+		}
+		return nil
+	})
+	stopHeartbeat()
+	if cancelled("CHAPTER") {
+		// Chapters the fan-out never reached still need a genre for the
+		// chapter metrics; the stopped classifier answers heuristically.
+		genreClassifier.stop("run cancelled")
+		for idx := range genreDecisions {
+			if genreDecisions[idx].Provider == "" {
+				genreDecisions[idx] = genreClassifier.classifyChapter(chapters[idx])
+			}
+		}
+	}
+	if genreCacheHits > 0 {
+		stats.CachedStages = append(stats.CachedStages, CachedStageGenre)
+		addLog("INFO", "CHAPTER", "Genre classification reused from cache", fmt.Sprintf("chapters=%d of %d", genreCacheHits, len(chapters)))
+	}
+	if genreCacheHits < len(chapters) {
+		// Heuristic fallbacks are not kept, so the model gets another try on
+		// the next run.
+		for idx, decision := range genreDecisions {
+			if decision.Provider != "heuristic" {
+				cachedGenre[chapterKey(chapters[idx])] = decision
+			}
 		}
-		chapterProgressMid := chapterProgressStart + (chapterProgressEnd-chapterProgressStart)/2
-		progress(onProgress, chapterProgressStart, "CHAPTER", fmt.Sprintf("Chapter %d/%d: classifying genre", idx+1, len(chapters)))
+		if cacheErr := cache.save(CachedStageGenre, genreFingerprint, cachedGenre); cacheErr != nil {
+			addLog("RISK", "CHAPTER", "Genre cache not saved", cacheErr.Error())
+		}
+	}
 
-		genreDecision := genreClassifier.classifyChapter(ch)
+	for idx, ch := range chapters {
+		genreDecision := genreDecisions[idx]
 		chGenres := genreDecision.Scores
-		progress(onProgress, chapterProgressMid, "CHAPTER", fmt.Sprintf("Chapter %d/%d: extracting timeline markers", idx+1, len(chapters)))
 		markCount := len(extractChapterMarkers(ch.text))
 		topName, topScore := topGenre(chGenres)
 		providerHits[genreDecision.Provider]++
@@ -105,50 +306,260 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 		chapterMetrics = append(chapterMetrics, ChapterMetric{
 			Index:          ch.index,
 			Title:          ch.title,
-			WordCount:      len(strings.Fields(ch.text)),
+			WordCount:      chapterWords[idx],
 			TimelineMarks:  markCount,
 			TopGenre:       topName,
 			TopGenreScore:  topScore,
 			GenreProvider:  genreDecision.Provider,
 			GenreReasoning: genreDecision.Reasoning,
 			GenreBreakdown: topNGenres(chGenres, 4),
+			ReadingLevel:   measureReadingLevel(ch.text),
 		})
-		addLog("ANALYSIS", "CHAPTER", fmt.Sprintf("Read chapter %d", ch.index), fmt.Sprintf("title=%s words=%d top_genre=%s provider=%s timeline_markers=%d", ch.title, len(strings.Fields(ch.text)), topName, genreDecision.Provider, markCount))
-		progress(onProgress, chapterProgressEnd, "CHAPTER", fmt.Sprintf("Chapter %d/%d: metrics complete", idx+1, len(chapters)))
+		addLog("ANALYSIS", "CHAPTER", fmt.Sprintf("Read chapter %d", ch.index), fmt.Sprintf("title=%s words=%d top_genre=%s provider=%s timeline_markers=%d", ch.title, chapterWords[idx], topName, genreDecision.Provider, markCount))
+		track.stageChapter("CHAPTER", 0.9+0.1*float64(idx+1)/chapterCount, idx+1, fmt.Sprintf("Chapter %d/%d: metrics complete", idx+1, len(chapters)))
+	}
+	if latency := genreClassifier.latency(); latency != "" {
+		addLog("INFO", "CHAPTER", "Genre model latency", latency)
+	}
+	track.mark("CHAPTER")
+	var characterDictionary []CharacterEntry
+	var chapterSummaries []ChapterSummary
+	var chapterSummaryByID map[int]ChapterSummary
+	// Deep Dive also asks the model which names are the same character.
+	coreferenceModel := ""
+	if profile == ProfileDeep {
+		coreferenceModel = ollamaModel("OLLAMA_COREFERENCE_MODEL", "OLLAMA_LANGUAGE_MODEL")
+	}
+	dictionaryFingerprint := stageFingerprint(chapterSplitFingerprint(chapters), "aliases", "pronoun-actions", "summary-guard", "pronoun-mentions", coreferenceModel)
+	var cachedDictionary struct {
+		Characters []CharacterEntry `json:"characters"`
+		Summaries  []ChapterSummary `json:"summaries"`
+	}
+	hit, cacheErr := cache.load(CachedStageDictionary, dictionaryFingerprint, &cachedDictionary)
+	if cacheErr != nil {
+		addLog("RISK", "DICTIONARY", "Dictionary cache unreadable; chapters will be summarized again", cacheErr.Error())
+	}
+	if hit {
+		characterDictionary, chapterSummaries = cachedDictionary.Characters, cachedDictionary.Summaries
+		chapterSummaryByID = make(map[int]ChapterSummary, len(chapterSummaries))
+		for _, cs := range chapterSummaries {
+			chapterSummaryByID[cs.Chapter] = cs
+		}
+		stats.CachedStages = append(stats.CachedStages, CachedStageDictionary)
+		addLog("INFO", "DICTIONARY", "Character dictionary and chapter summaries reused from cache", "")
+	} else {
+		characterDictionary, chapterSummaries, chapterSummaryByID = buildCharacterDictionary(chapters)
+		before := len(characterDictionary)
+		characterDictionary = mergeCharacterAliases(chapters, characterDictionary, nil)
+		if coreferenceModel != "" && len(characterDictionary) > 1 {
+			groups, err := coreferenceGroupsWithOllama(coreferenceModel, chapters, characterDictionary)
+			if err != nil {
+				addLog("RISK", "DICTIONARY", "Ollama coreference unavailable; aliases merged by name matching only", err.Error())
+			} else {
+				characterDictionary = mergeCharacterAliases(chapters, characterDictionary, groups)
+			}
+		}
+		guarded := []string{}
+		for _, cs := range chapterSummaries {
+			if cs.Fallback != "" {
+				guarded = append(guarded, fmt.Sprintf("ch %d %s", cs.Chapter, cs.Fallback))
+			}
+		}
+		if len(guarded) > 0 {
+			addLog("RISK", "DICTIONARY", "Chapter summaries failed the summary guard; opening prose used instead", strings.Join(guarded, ", "))
+		}
+		if merged := before - len(characterDictionary); merged > 0 {
+			addLog("INFO", "DICTIONARY", "Character aliases merged", fmt.Sprintf("names=%d characters=%d", before, len(characterDictionary)))
+		}
+		cachedDictionary.Characters, cachedDictionary.Summaries = characterDictionary, chapterSummaries
+		if cacheErr := cache.save(CachedStageDictionary, dictionaryFingerprint, cachedDictionary); cacheErr != nil {
+			addLog("RISK", "DICTIONARY", "Dictionary cache not saved", cacheErr.Error())
+		}
 	}
-	characterDictionary, chapterSummaries, chapterSummaryByID := buildCharacterDictionary(chapters)
 	addLog("ANALYSIS", "DICTIONARY", "Character dictionary built", fmt.Sprintf("characters=%d chapters=%d", len(characterDictionary), len(chapterSummaries)))
+	nameHygiene := analyzeNameHygiene(characterDictionary)
+	for _, flag := range nameHygiene.Flags {
+		addLog("RISK", "DICTIONARY", "Name hygiene: "+flag, "")
+	}
+	terminology := analyzeTerminology(chapters, text, characterDictionary)
+	addLog("ANALYSIS", "DICTIONARY", "Invented terminology checked", fmt.Sprintf("terms=%d inconsistent=%d", len(terminology.Terms), len(terminology.Issues)))
+	for _, flag := range terminology.Flags {
+		addLog("RISK", "DICTIONARY", "Terminology: "+flag, "")
+	}
+	voiceReport := analyzeDialogueVoices(chapters, characterDictionary)
+	addLog("ANALYSIS", "DICTIONARY", "Dialogue voices compared", fmt.Sprintf("attributed_lines=%d profiled=%d mean_between=%.2f mean_drift=%.2f", voiceReport.AttributedLines, len(voiceReport.Characters), voiceReport.MeanBetween, voiceReport.MeanDrift))
+	for _, flag := range voiceReport.Flags {
+		addLog("RISK", "DICTIONARY", flag, "")
+	}
+	track.mark("DICTIONARY")
+	track.stage("DICTIONARY", 1, fmt.Sprintf("%d chapter summaries built", len(chapterSummaries)))
 
 	genreScores := normalizeGenreScores(allGenreRaw)
 	if len(genreScores) == 0 {
 		genreScores = scoreGenresForText(text)
 	}
 	globalGenreProvider := dominantProvider(providerHits)
+	var genreFallback *FallbackReason
+	if providerHits["heuristic"] > 0 {
+		if genreFallback = genreClassifier.fallback(); genreFallback != nil {
+			addLog("RISK", "CHAPTER", "Genre heuristic fallback", fmt.Sprintf("reason=%s chapters=%d: %s", genreFallback.Code, providerHits["heuristic"], genreFallback.Detail))
+		}
+	}
 	globalGenreReasoning := strings.Join(genreReasoningLines, "\n")
 	if len(globalGenreReasoning) > 2400 {
 		globalGenreReasoning = globalGenreReasoning[:2400]
 	}
 
-	slopReport := slop.Analyze(text)
+	phraseGenre, phraseShare := topGenre(genreScores)
+	if phraseShare < minCalibrationGenreShare {
+		phraseGenre = ""
+	}
+	phraseBank, phraseBankErr := slop.LoadPhraseBank(phraseGenre, phraseBankDir(workspaceRoot))
+	if phraseBankErr != nil {
+		addLog("RISK", "SLOP", "Phrase bank pack unreadable", phraseBankErr.Error())
+	}
+	addLog("INFO", "SLOP", "Phrase bank selected", fmt.Sprintf("genre=%s trigrams=%d sources=%s", phraseBank.Genre, phraseBank.Size(), strings.Join(phraseBank.Sources, ",")))
+	slopThresholds := feedbackThresholds(workspaceRoot, settings, addLog)
+	badWords, badWordSources, badWordsErr := loadLexicon(workspaceRoot, LexiconBadWords, phraseGenre)
+	if badWordsErr != nil {
+		addLog("RISK", "SLOP", "Bad-word lexicon unreadable", badWordsErr.Error())
+	}
+	if badWords != nil {
+		addLog("INFO", "SLOP", "Custom bad-word lexicon loaded", fmt.Sprintf("entries=%d sources=%s", len(badWords), strings.Join(badWordSources, ",")))
+	}
+	slopReport := slop.AnalyzeWithOptions(text, slop.Options{PhraseBank: phraseBank, Exclusions: duplicationIgnore, Thresholds: slopThresholds, BadWords: badWords, Genre: phraseGenre})
+	if slopReport.ThresholdGenre != "" {
+		addLog("INFO", "SLOP", "Genre thresholds applied", fmt.Sprintf("genre=%s dramatic_density=%.3f", slopReport.ThresholdGenre, slopReport.DramaticDensity))
+	}
+	if slopReport.ExcludedWords > 0 {
+		addLog("INFO", "SLOP", "Ignore-list text left out of repetition signals", fmt.Sprintf("words=%d", slopReport.ExcludedWords))
+	}
 	stats.SlopFlagCount = len(slopReport.Flags)
 	addLog("ANALYSIS", "SLOP", "Statistical scan completed", fmt.Sprintf("flags=%d sd=%.2f", len(slopReport.Flags), slopReport.SentenceLengthSD))
 	for _, flag := range slopReport.Flags {
+		addLog("RISK", "SLOP", flag.Text, fmt.Sprintf("code=%s severity=%s confidence=%.2f", flag.Code, flag.Severity, flag.Confidence))
+	}
+	noveltyModel, noveltyPath, noveltyErr := loadNoveltyModel(workspaceRoot)
+	if noveltyErr != nil {
+		addLog("RISK", "SLOP", "Novelty model unreadable", noveltyErr.Error())
+	}
+	noveltyReport := analyzeNovelty(noveltyModel, noveltyPath, chapters, text)
+	if noveltyReport.Available {
+		addLog("ANALYSIS", "SLOP", "Novelty profiled", fmt.Sprintf("model=%s novelty=%d surprisal=%.2f baseline=%.2f chapters=%d", noveltyReport.Model, noveltyReport.Novelty, noveltyReport.Surprisal, noveltyReport.BaselineSurprisal, len(noveltyReport.Chapters)))
+	}
+	crutchWords := analyzeCrutchWords(chapters, noveltyModel, characterDictionary)
+	addLog("ANALYSIS", "SLOP", "Word and phrase frequencies compared", fmt.Sprintf("baseline=%q words=%d phrases=%d", crutchWords.Baseline, len(crutchWords.Words), len(crutchWords.Phrases)))
+	for _, t := range crutchWords.Words[:min(len(crutchWords.Words), 5)] {
+		addLog("INFO", "SLOP", "Crutch word", describeCrutchTerm(t))
+	}
+	bookends := analyzeChapterBookends(chapters)
+	addLog("ANALYSIS", "SLOP", "Chapter openings and endings clustered", fmt.Sprintf("chapters=%d opening_patterns=%d closing_patterns=%d", bookends.Chapters, len(bookends.Openings), len(bookends.Closings)))
+	for _, flag := range bookends.Flags {
 		addLog("RISK", "SLOP", flag, "")
 	}
-	progress(onProgress, 56, "SLOP", "Statistical language pass complete")
+	pacing := analyzePacing(chapters)
+	addLog("ANALYSIS", "SLOP", "Chapter pacing measured", fmt.Sprintf("chapters=%d stretches=%d", len(pacing.Chapters), len(pacing.Stretches)))
+	for _, flag := range pacing.Flags {
+		addLog("RISK", "SLOP", flag, "")
+	}
+	manuscriptStats := measureManuscriptStats(chapters)
+	addLog("ANALYSIS", "SLOP", "Sentence length distribution", describeDistribution(manuscriptStats.Sentences))
+	addLog("ANALYSIS", "SLOP", "Paragraph length distribution", describeDistribution(manuscriptStats.Paragraphs))
+	track.mark("SLOP")
+	track.stage("SLOP", 1, "Statistical language pass complete")
 
-	aiCfg := aidetect.DefaultConfig()
-	aiReport := aidetect.Analyze(
-		aidetect.Input{
-			DocumentID: runID,
-			Text:       text,
-			Language:   "en",
-		},
-		aiCfg,
-		newAILanguageToolScorer(),
-		nil,
-		aiLogger{add: addLog},
-	)
+	aiSensitivity, aiSensitivitySource := resolveAISensitivity(ctx, settings)
+	aiCfg := aidetect.ConfigForSensitivity(aiSensitivity)
+	aiCfg.Calibration = aiCalibration(genreScores)
+	aiCfg.LanguageToolLimiter = limiters.aiWindows
+	aiCfg = profileAIConfig(profile, aiCfg)
+	aiCfg.Exclusions = duplicationIgnore
+	intensifiers, intensifierSources, intensifiersErr := loadLexicon(workspaceRoot, LexiconIntensifiers, phraseGenre)
+	if intensifiersErr != nil {
+		addLog("RISK", "AI", "Intensifier lexicon unreadable", intensifiersErr.Error())
+	}
+	if intensifiers != nil {
+		aiCfg.Intensifiers = intensifiers
+		addLog("INFO", "AI", "Custom intensifier lexicon loaded", fmt.Sprintf("entries=%d sources=%s", len(intensifiers), strings.Join(intensifierSources, ",")))
+	}
+	aiEmbedModel := ""
+	if aiCfg.EnableSemanticDup {
+		aiEmbedModel = embedModel()
+		aiCfg.Embedder = aidetect.NewOllamaEmbedder(ollamaEmbedEndpoint(), aiEmbedModel)
+	}
+	var aiLM aidetect.LMSmoothnessScorer
+	aiLMModel := ""
+	if aiCfg.EnableLMSmoothness {
+		aiLMModel = lmSmoothnessModel()
+		aiLM = newAILMSmoothnessScorer(aiLMModel)
+	}
+	aiReport := aidetect.Report{Flags: []string{}, Windows: []aidetect.WindowReport{}, Errors: []aidetect.ErrorEntry{}, Traces: []aidetect.SpanTrace{}}
+	if sections[SectionAIDetection] == SectionStatusEnabled && !cancelled("AI") {
+		addLog("INFO", "AI", "Sensitivity preset selected", fmt.Sprintf("preset=%s source=%s bias=%.2f coverage_trigger=%.2f", aiCfg.Sensitivity, aiSensitivitySource, aiCfg.Bias, aiCfg.CoverageTrigger))
+		if _, err := aidetect.WeightingFromEnv(); err != nil {
+			addLog("RISK", "AI", "AI_WEIGHTING profile unusable; using balanced weighting", err.Error())
+		}
+		addLog("INFO", "AI", "Weighting profile selected", fmt.Sprintf("profile=%s", aiCfg.Weighting.Name))
+		if aiCfg.EnableSemanticDup {
+			addLog("INFO", "AI", "Semantic duplication enabled", fmt.Sprintf("model=%s threshold=%.2f", aiEmbedModel, aiCfg.SemanticDupThreshold))
+		}
+		if aiCfg.EnableLMSmoothness {
+			addLog("INFO", "AI", "LM smoothness enabled", fmt.Sprintf("model=%s samples_per_window=%d timeout_ms=%d", aiLMModel, lmSamplesPerWindow, aiCfg.LMSmoothnessTimeoutMs))
+		}
+		addLog("INFO", "AI", "Calibration profile selected", fmt.Sprintf("profile=%s style_weight=%.2f polish_weight=%.2f bias_offset=%.2f", aiCfg.Calibration.Profile, aiCfg.Calibration.StyleWeight, aiCfg.Calibration.PolishWeight, aiCfg.Calibration.BiasOffset))
+		exemptSpans, missingExempt := humanExemptSpans(text, chapters, verifiedHumanFromSettings(settings))
+		if len(exemptSpans) > 0 {
+			addLog("INFO", "AI", "Verified-human text excluded from aggregate", fmt.Sprintf("spans=%d chapters=%d passages=%d", len(exemptSpans), len(settings.VerifiedHumanChapters), len(settings.VerifiedHumanPassages)))
+		}
+		for _, missing := range missingExempt {
+			addLog("RISK", "AI", "Verified-human text not found in this draft", missing)
+		}
+		aiFingerprint := stageFingerprint(aiCfg, exemptSpans, aiEmbedModel, aiLMModel, patternStrings(duplicationIgnore), aiCfg.Intensifiers)
+		hit, cacheErr := cache.load(CachedStageAI, aiFingerprint, &aiReport)
+		if cacheErr != nil {
+			addLog("RISK", "AI", "AI window cache unreadable; windows will be scored again", cacheErr.Error())
+		}
+		if hit {
+			aiReport.DocumentID = runID
+			aiReport.Traces = []aidetect.SpanTrace{}
+			aiReport.Intensifiers = aiCfg.Intensifiers
+			stats.CachedStages = append(stats.CachedStages, CachedStageAI)
+			addLog("INFO", "AI", "AI windows reused from cache", fmt.Sprintf("windows=%d", len(aiReport.Windows)))
+		} else {
+			aiReport = aidetect.AnalyzeContext(
+				ctx,
+				aidetect.Input{
+					DocumentID:  runID,
+					Text:        text,
+					Language:    "en",
+					ExemptSpans: exemptSpans,
+				},
+				aiCfg,
+				newAILanguageToolScorer(),
+				aiLM,
+				aiLogger{add: addLog},
+			)
+			// A report with degraded signals is not kept, so the next run
+			// retries the services that failed.
+			if len(aiReport.Errors) == 0 && !cancelled("AI") {
+				if cacheErr := cache.save(CachedStageAI, aiFingerprint, aiReport); cacheErr != nil {
+					addLog("RISK", "AI", "AI window cache not saved", cacheErr.Error())
+				}
+			}
+		}
+		placed := locateAIWindows(&aiReport, text, chapters)
+		addLog("ANALYSIS", "AI", "Windows mapped to chapters", fmt.Sprintf("placed=%d windows=%d", placed, len(aiReport.Windows)))
+		scored := rollUpAIChapters(&aiReport, text, chapters)
+		addLog("ANALYSIS", "AI", "AI likelihood rolled up per chapter", fmt.Sprintf("scored=%d chapters=%d", scored, len(aiReport.PAIPerChapter)))
+		if profile == ProfileDeep {
+			attributed := attributeAISentences(&aiReport, text, chapters)
+			addLog("ANALYSIS", "AI", "AI likelihood attributed to sentences", fmt.Sprintf("sentences=%d min_window_p_ai=%.2f", attributed, deepSentenceMinPAI))
+		}
+		if pages := pagesFromContext(ctx); len(pages) > 0 {
+			paged := pageAIWindows(&aiReport, text, pages)
+			addLog("ANALYSIS", "AI", "Windows mapped to PDF pages", fmt.Sprintf("paged=%d windows=%d pages=%d", paged, len(aiReport.Windows), len(pages)))
+		}
+	}
 	for _, span := range aiReport.Traces {
 		addLog("ANALYSIS", "AI", "Trace span", fmt.Sprintf("%s duration_ms=%d status=%s", span.Name, span.DurationMs, span.Status))
 	}
@@ -189,9 +600,26 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 			addLog("RISK", "AI", "Additional AI signal errors suppressed", fmt.Sprintf("%d unique error groups omitted", len(order)-maxErrorLogs))
 		}
 	}
-	progress(onProgress, 62, "AI", "AI detection analysis complete")
+	track.mark("AI")
+	track.stage("AI", 1, "AI detection analysis complete")
 
-	contradictions := detectHeuristicContradictions(chapters)
+	customAttributes, customErr := LoadAttributeExtractors(workspaceRoot)
+	if customErr != nil {
+		addLog("RISK", "FORENSICS", "Custom contradiction attributes unreadable; built-in attributes only", customErr.Error())
+	} else if len(customAttributes) > 0 {
+		addLog("INFO", "FORENSICS", "Custom contradiction attributes loaded", fmt.Sprintf("attributes=%d", len(customAttributes)))
+	}
+	contradictions := []forensics.Contradiction{}
+	switch {
+	case !isFiction(manuscriptType):
+		addLog("INFO", "FORENSICS", "Character contradiction checks skipped for "+manuscriptType, "")
+	case anthology:
+		// Stories do not share characters, so facts are only compared
+		// within a story.
+		contradictions = storyContradictions(stories, customAttributes)
+	default:
+		contradictions = detectHeuristicContradictions(chapters, characterAliasIndex(characterDictionary), customAttributes)
+	}
 	healthIssues := buildHealthIssues(contradictions, chapterSummaryByID)
 	stats.ContradictionCount = len(healthIssues)
 	if len(healthIssues) > 0 {
@@ -199,7 +627,35 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 	} else {
 		addLog("INFO", "FORENSICS", "No contradictions detected by heuristic pass", "")
 	}
-	progress(onProgress, 68, "FORENSICS", "Consistency checks complete")
+	tenseDrifts, tenseSceneShifts := detectTenseDrift(chapters)
+	addLog("ANALYSIS", "FORENSICS", "Tense drift checked", fmt.Sprintf("drifts=%d scene_break_shifts=%d", len(tenseDrifts), tenseSceneShifts))
+	healthIssues = append(healthIssues, tenseDriftIssues(tenseDrifts, len(healthIssues))...)
+	pov := emptyPOVReport()
+	if isFiction(manuscriptType) {
+		var headHops []headHop
+		pov, headHops = analyzePOV(chapters)
+		povFindings := povIssues(pov, headHops, chapters, !anthology, len(healthIssues))
+		addLog("ANALYSIS", "FORENSICS", "Point of view checked", fmt.Sprintf("pov=%s issues=%d head_hopping_scenes=%d", pov.POV, len(povFindings), len(headHops)))
+		healthIssues = append(healthIssues, povFindings...)
+	}
+	if anthology {
+		addLog("INFO", "FORENSICS", "Chapter numbering and fragments not checked for a story collection", "")
+	} else {
+		numbering := checkChapterNumbering(text, chapters)
+		addLog("ANALYSIS", "FORENSICS", "Chapter numbering checked", fmt.Sprintf("issues=%d", len(numbering)))
+		for _, issue := range numbering {
+			addLog("RISK", "FORENSICS", "Chapter numbering: "+issue.problem, "")
+		}
+		healthIssues = append(healthIssues, chapterNumberingIssues(numbering, len(healthIssues))...)
+		fragments := checkFragments(text, chapters)
+		addLog("ANALYSIS", "FORENSICS", "Stitching fragments checked", fmt.Sprintf("issues=%d", len(fragments)))
+		for _, f := range fragments {
+			addLog("RISK", "FORENSICS", "Fragment: "+f.problem, "")
+		}
+		healthIssues = append(healthIssues, fragmentIssues(fragments, len(healthIssues))...)
+	}
+	track.mark("FORENSICS")
+	track.stage("FORENSICS", 1, "Consistency checks complete")
 
 	timelineEvents := buildTimeline(chapters, chapterSummaries)
 	stats.TimelineCount = len(timelineEvents)
@@ -209,22 +665,108 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 	} else {
 		addLog("ANALYSIS", "TIMELINE", "Timeline markers extracted", strconv.Itoa(len(timelineEvents)))
 	}
-	progress(onProgress, 76, "TIMELINE", "Timeline reconstruction complete")
+	track.mark("TIMELINE")
+	track.stage("TIMELINE", 1, "Timeline reconstruction complete")
 
-	beats, plotStructure := analyzePlotStructure(PlotInputs{
-		Chapters:         chapters,
-		ChapterSummaries: chapterSummaries,
-		ChapterMetrics:   chapterMetrics,
-		TimelineEvents:   timelineEvents,
-		GenreScores:      genreScores,
-		GenreProvider:    globalGenreProvider,
-		GenreReasoning:   globalGenreReasoning,
-	})
+	var seriesReport *SeriesReport
+	var seriesStore workspace.Series
+	var seriesEntry workspace.SeriesInstallment
+	if seriesName := seriesFromContext(ctx); seriesName != "" && workspaceRoot != "" {
+		loaded, seriesErr := workspace.LoadSeries(workspaceRoot, seriesName)
+		if seriesErr != nil {
+			addLog("RISK", "SERIES", "Series unreadable; installment analyzed standalone", seriesErr.Error())
+		} else {
+			seriesStore = loaded
+			seriesEntry = seriesInstallment(bookTitle, projectSourceName, projectSourceSHA, projectID, words, chapters, characterDictionary, customAttributes)
+			report, issues := compareWithSeries(seriesStore.Name, seriesStore.Before(projectSourceSHA), seriesEntry, chapterSummaryByID, len(healthIssues))
+			seriesReport = report
+			healthIssues = append(healthIssues, issues...)
+			addLog("ANALYSIS", "SERIES", "Installment compared with earlier installments", fmt.Sprintf("series=%q installment=%d prior=%d prior_words=%d returning=%d new=%d", report.Name, report.Installment, report.PriorInstallments, report.PriorWords, len(report.ReturningCharacters), len(report.NewCharacters)))
+			for _, issue := range issues {
+				addLog("RISK", "SERIES", "Series continuity break", issue.Description)
+			}
+			for _, note := range report.Notes {
+				addLog("RISK", "SERIES", "Series timeline", note)
+			}
+		}
+	}
+	if pages := pagesFromContext(ctx); len(pages) > 0 && len(healthIssues) > 0 {
+		paged := pageHealthIssues(healthIssues, text, chapters, pages)
+		addLog("ANALYSIS", "FORENSICS", "Consistency issues mapped to PDF pages", fmt.Sprintf("paged=%d issues=%d", paged, len(healthIssues)))
+	}
+
+	beats := []BeatResult{}
+	plotStructure := PlotStructureReport{Provider: SectionStatusDisabled, Reasoning: "Plot structure analysis disabled in project settings."}
+	if !isFiction(manuscriptType) {
+		plotStructure = PlotStructureReport{Provider: SectionStatusDisabled, Reasoning: fmt.Sprintf("Plot structure is not applied to %s; see the nonfiction checks.", manuscriptType)}
+	} else if anthology {
+		plotStructure = PlotStructureReport{Provider: SectionStatusDisabled, Reasoning: "Plot structure is not applied to a story collection; stories are compared in the anthology report."}
+	} else if sections[SectionPlotStructure] == SectionStatusEnabled && !cancelled("STRUCTURE") {
+		beats, plotStructure = analyzePlotStructure(PlotInputs{
+			Chapters:         chapters,
+			ChapterSummaries: chapterSummaries,
+			ChapterMetrics:   chapterMetrics,
+			TimelineEvents:   timelineEvents,
+			GenreScores:      genreScores,
+			GenreProvider:    globalGenreProvider,
+			GenreReasoning:   globalGenreReasoning,
+			Offline:          profile == ProfileQuick,
+		})
+	}
 	addLog("ANALYSIS", "STRUCTURE", "Plot structure evaluated", fmt.Sprintf("beats=%d selected=%s provider=%s", len(beats), plotStructure.SelectedStructure, plotStructure.Provider))
-	progress(onProgress, 84, "STRUCTURE", "Structural beat mapping complete")
+	track.mark("STRUCTURE")
+	track.stage("STRUCTURE", 1, "Structural beat mapping complete")
 
-	language := analyzeLanguage(chapters, text)
-	addLog("ANALYSIS", "LANGUAGE", "Language diagnostics completed", fmt.Sprintf("spelling=%d grammar=%d age=%s", language.SpellingScore, language.GrammarScore, language.AgeCategory))
+	rubric, rubricErr := LoadAgeRubric(workspaceRoot)
+	if rubricErr != nil {
+		addLog("RISK", "LANGUAGE", "Age rubric unreadable; default rubric applied", rubricErr.Error())
+	}
+	dialect, dialectSource := resolveDialect(settings.Dialect, text)
+	addLog("INFO", "LANGUAGE", "Dialect selected", fmt.Sprintf("dialect=%s source=%s", dialect, dialectSource))
+	languageMix := detectLanguageMix(chapters)
+	for _, flag := range languageMix.Flags {
+		addLog("RISK", "LANGUAGE", "Language mix: "+flag, "")
+	}
+	scoredChapters, scoredText := withoutForeignPassages(chapters, text, languageMix)
+	language := analyzeLanguage(scoredChapters, scoredText, sections[SectionSafety] == SectionStatusEnabled && !cancelled("LANGUAGE"), rubric, languageToolOptions{
+		dialect:         dialect,
+		dialogueGrammar: settings.DialogueGrammar,
+		offline:         profile == ProfileQuick,
+		limiter:         limiters.languageTool,
+		progress: func(fraction float64, detail string) {
+			track.stage("LANGUAGE", fraction, detail)
+		},
+	})
+	if languageMix.ExcludedWords > 0 {
+		language.Notes = append(language.Notes, fmt.Sprintf("Passages in another language left out of scoring: %d words", languageMix.ExcludedWords))
+	}
+	for _, drift := range readingLevelDrift(chapterMetrics, language.ReadingLevel) {
+		language.Notes = append(language.Notes, drift)
+		addLog("ANALYSIS", "LANGUAGE", "Reading level drift", drift)
+	}
+	addLog("ANALYSIS", "LANGUAGE", "Language diagnostics completed", fmt.Sprintf("spelling=%d grammar=%d readability=%d fk_grade=%.1f fog=%.1f smog=%.1f age=%s", language.SpellingScore, language.GrammarScore, language.ReadabilityScore, language.ReadingLevel.FleschKincaidGrade, language.ReadingLevel.GunningFog, language.ReadingLevel.SMOG, language.AgeCategory))
+	if len(language.AgeRating.DrivenBy) > 0 {
+		addLog("ANALYSIS", "LANGUAGE", "Age rating driven by content dimensions", fmt.Sprintf("rubric=%s dimensions=%s", language.AgeRating.Standard, strings.Join(language.AgeRating.DrivenBy, ",")))
+	}
+	attention := []string{}
+	for _, c := range language.Chapters {
+		if c.NeedsAttention {
+			attention = append(attention, fmt.Sprintf("%d(spelling=%d grammar=%d)", c.Chapter, c.SpellingScore, c.GrammarScore))
+		}
+	}
+	if len(attention) > 0 {
+		addLog("ANALYSIS", "LANGUAGE", "Chapters needing copyedit attention", strings.Join(attention, " "))
+	}
+	for _, c := range language.IssueBreakdown {
+		rules := make([]string, 0, len(c.TopRules))
+		for _, r := range c.TopRules {
+			rules = append(rules, fmt.Sprintf("%s=%d", r.RuleID, r.Count))
+		}
+		addLog("ANALYSIS", "LANGUAGE", "LanguageTool category", fmt.Sprintf("category=%s count=%d top_rules=%s", c.Category, c.Count, strings.Join(rules, ",")))
+	}
+	for _, warning := range language.ContentWarnings {
+		addLog("RISK", "LANGUAGE", "Content warning", warning)
+	}
 	if language.HeuristicFallback {
 		addLog("RISK", "LANGUAGE", "Heuristic fallback active", fmt.Sprintf("spelling_provider=%s safety_provider=%s", language.SpellingProvider, language.SafetyProvider))
 	}
@@ -233,38 +775,107 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 			addLog("RISK", "LANGUAGE", "Language dependency unavailable", note)
 		}
 	}
-	progress(onProgress, 94, "LANGUAGE", "Language quality analysis complete")
+	track.mark("LANGUAGE")
+	track.stage("LANGUAGE", 1, "Language quality analysis complete")
 
-	compTitles := []CompTitle{{Title: "The Silent Patient", Tier: "Blockbuster"}, {Title: "The Maidens", Tier: "Blockbuster"}, {Title: "Wrong Place Wrong Time", Tier: "Mid-list"}, {Title: "Rock Paper Scissors", Tier: "Mid-list"}, {Title: "Unknown", Tier: "Unknown"}}
+	draftMarkers := scanDraftMarkers(chapters, highlightsFromContext(ctx))
+	addLog("ANALYSIS", "LANGUAGE", "Draft marker scan completed", fmt.Sprintf("markers=%d highlights=%d", len(draftMarkers.Markers), len(highlightsFromContext(ctx))))
+	if !draftMarkers.Ready {
+		addLog("RISK", "LANGUAGE", fmt.Sprintf("Not ready: %d draft markers remain", len(draftMarkers.Markers)), draftMarkers.Markers[0].Location+": "+draftMarkers.Markers[0].Marker)
+	}
+	numberStyle := analyzeNumberStyle(chapters, settings.NumberStyle)
+	addLog("ANALYSIS", "LANGUAGE", "Numbers, units and dates checked", fmt.Sprintf("style_guide=%s issues=%d", numberStyle.StyleGuide, len(numberStyle.Issues)))
+	for _, flag := range numberStyle.Flags {
+		addLog("RISK", "LANGUAGE", "Number style: "+flag, "")
+	}
+	permissions := auditPermissions(chapters)
+	addForeignPassages(&permissions, languageMix)
+	addLog("ANALYSIS", "LANGUAGE", "Quoted lyrics, poems and epigraphs listed", fmt.Sprintf("items=%d words=%d", len(permissions.Items), permissions.TotalWords))
+	for _, flag := range permissions.Flags {
+		addLog("RISK", "LANGUAGE", "Permissions: "+flag, "")
+	}
 
-	aiPenalty := slopReport.AISuspicionScore / 5
-	if aiReport.PAIDoc != nil && aiReport.AICoverageEst != nil && aiReport.PAIMax != nil {
-		coverageExcess := math.Max(0, *aiReport.AICoverageEst-0.10)
-		aiPenalty = int(math.Round((*aiReport.PAIMax * 35.0) + (coverageExcess * 40.0)))
-		if *aiReport.PAIDoc >= 0.85 && (*aiReport.PAIMax >= 0.75 || *aiReport.AICoverageEst >= 0.25) {
-			aiPenalty += 8
+	var nonfiction *NonfictionReport
+	if !isFiction(manuscriptType) {
+		report := analyzeNonfiction(manuscriptType, text, chapters)
+		nonfiction = &report
+		addLog("ANALYSIS", "NONFICTION", "Nonfiction checks completed", fmt.Sprintf("type=%s repeated_claims=%d heading_issues=%d placeholders=%d", manuscriptType, len(report.RepeatedClaims), len(report.HeadingIssues), len(report.Placeholders)))
+		for _, flag := range report.Flags {
+			addLog("RISK", "NONFICTION", flag, "")
 		}
-		if containsString(aiReport.Flags, "ai_chunk_detected") {
-			aiPenalty += 10
+	}
+
+	var anthologyReport *AnthologyReport
+	if anthology {
+		anthologyReport = buildAnthologyReport(stories, storyBoundaries, chapterMetrics, storyAI(aiReport.Windows, len(stories)), language)
+		addLog("ANALYSIS", "ANTHOLOGY", "Stories compared", fmt.Sprintf("stories=%d authors=%d genres=%d median_p_ai=%.2f", len(anthologyReport.Stories), len(anthologyReport.Authors), len(anthologyReport.GenreMix), anthologyReport.MedianAI))
+		for _, flag := range anthologyReport.Flags {
+			addLog("RISK", "ANTHOLOGY", flag, "")
 		}
-		if containsString(aiReport.Flags, "widespread_ai_signal") {
-			aiPenalty += 15
+	}
+
+	sensitivity := SensitivityReport{Provider: SectionStatusDisabled, Disclaimer: sensitivityDisclaimer, Flags: []SensitivityFlag{}, Notes: []string{}}
+	if sections[SectionSensitivity] == SectionStatusEnabled && !cancelled("SENSITIVITY") {
+		track.stage("SENSITIVITY", 0, "Reviewing passages for sensitivity read")
+		sensitivity = analyzeSensitivity(chapters)
+		addLog("ANALYSIS", "SENSITIVITY", "Sensitivity read pass completed", fmt.Sprintf("flags=%d provider=%s", len(sensitivity.Flags), sensitivity.Provider))
+		for _, note := range sensitivity.Notes {
+			if strings.Contains(strings.ToLower(note), "unavailable") {
+				addLog("RISK", "SENSITIVITY", "Sensitivity model unavailable", note)
+			}
 		}
-		if aiPenalty > 70 {
-			aiPenalty = 70
+		track.mark("SENSITIVITY")
+	}
+
+	var annotations []Annotation
+	if projectDBPath != "" {
+		anchored, annotationErr := reanchorAnnotations(projectDBPath, text)
+		if annotationErr != nil {
+			addLog("RISK", "ANNOTATIONS", "Annotation re-anchoring failed", annotationErr.Error())
+		} else {
+			annotations = anchored
+			orphaned := 0
+			for _, a := range anchored {
+				if a.Orphaned {
+					orphaned++
+				}
+			}
+			addLog("INFO", "ANNOTATIONS", "Annotations loaded", fmt.Sprintf("count=%d orphaned=%d", len(anchored), orphaned))
 		}
 	}
-	mhdScore := 100 - (len(healthIssues) * 10) - (len(slopReport.Flags) * 6) - ((100 - language.GrammarScore) / 5) - ((100 - language.SpellingScore) / 5) - aiPenalty
-	if mhdScore < 0 {
-		mhdScore = 0
+
+	compTitles := []CompTitle{}
+	compCatalog := ""
+	var compFallback *FallbackReason
+	if sections[SectionCompTitles] == SectionStatusEnabled {
+		compTitles = defaultCompTitles
+		catalog, catalogPath, catalogErr := LoadCompCatalog(workspaceRoot)
+		compCatalog = catalogPath
+		if catalogErr != nil {
+			addLog("RISK", "COMP_TITLES", "Comp catalog unreadable; using default comp list", catalogErr.Error())
+		} else if profile == ProfileQuick {
+			addLog("INFO", "COMP_TITLES", "Comp ranking skipped in quick scan; using default comp list", "")
+		} else if len(catalog) > 0 && !cancelled("COMP_TITLES") {
+			ranked, provider, fallback := rankCompTitles(compSynopsis(chapterSummaries), catalog, embeddingCacheDir(workspaceRoot))
+			if fallback != nil {
+				addLog("RISK", "COMP_TITLES", "Comp similarity fallback", fmt.Sprintf("reason=%s: Ollama embeddings unavailable: %s", fallback.Code, fallback.Detail))
+			}
+			compFallback = fallback
+			compTitles = ranked
+			addLog("ANALYSIS", "COMP_TITLES", "Comp titles ranked against catalog", fmt.Sprintf("catalog=%s entries=%d provider=%s", catalogPath, len(catalog), provider))
+		}
 	}
-	addLog("INFO", "SCORING", "AI likelihood penalty applied", fmt.Sprintf("%d p_ai_doc=%.3f coverage=%.3f p_ai_max=%.3f flags=%d", aiPenalty, aiPtr(aiReport.PAIDoc), aiPtr(aiReport.AICoverageEst), aiPtr(aiReport.PAIMax), len(aiReport.Flags)))
-	addLog("INFO", "SCORING", "MHD score calculated", strconv.Itoa(mhdScore))
+
+	scoreBreakdown := computeMHDScore(healthIssues, slopReport, language, aiReport, draftMarkers, sections[SectionAIDetection] == SectionStatusEnabled)
+	mhdScore := scoreBreakdown.Score
+	addLog("INFO", "SCORING", "AI likelihood penalty applied", fmt.Sprintf("%d p_ai_doc=%.3f coverage=%.3f p_ai_max=%.3f flags=%d", scoreBreakdown.Points(ScoreComponentAI), aiPtr(aiReport.PAIDoc), aiPtr(aiReport.AICoverageEst), aiPtr(aiReport.PAIMax), len(aiReport.Flags)))
+	addLog("INFO", "SCORING", "MHD score calculated", describeScoreBreakdown(scoreBreakdown))
 
 	data := DashboardData{
 		BookTitle:           bookTitle,
 		WordCount:           words,
 		MHDScore:            mhdScore,
+		ScoreBreakdown:      scoreBreakdown,
 		Logs:                logs,
 		Contradictions:      contradictions,
 		HealthIssues:        healthIssues,
@@ -276,19 +887,90 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 		GenreScores:         genreScores,
 		GenreProvider:       globalGenreProvider,
 		GenreReasoning:      globalGenreReasoning,
+		GenreFallback:       genreFallback,
 		ChapterMetrics:      chapterMetrics,
 		ChapterSummaries:    chapterSummaries,
 		CharacterDictionary: characterDictionary,
 		ChapterCount:        len(chapters),
 		CompTitles:          compTitles,
+		CompTitlesFallback:  compFallback,
 		Language:            language,
+		Sensitivity:         sensitivity,
+		Novelty:             noveltyReport,
+		Voice:               voiceReport,
+		NameHygiene:         nameHygiene,
+		Terminology:         terminology,
+		Bookends:            bookends,
+		POV:                 pov,
+		Pacing:              pacing,
+		Stats:               manuscriptStats,
+		CrutchWords:         crutchWords,
 		ProjectLocation:     projectPath,
+		PriorAnalysis:       prior,
+		Series:              seriesReport,
+		Anthology:           anthologyReport,
+		ManuscriptType:      manuscriptType,
+		DraftMarkers:        draftMarkers,
+		NumberStyle:         numberStyle,
+		Permissions:         permissions,
+		LanguageMix:         languageMix,
+		Nonfiction:          nonfiction,
+		ModelDrift:          modelDrift,
+		Annotations:         annotations,
+		Sections:            sections,
 		RunStats:            stats,
 	}
+	anchorFindings(&data, text)
+	if detached := markDetachedAnnotations(&data); detached > 0 {
+		addLog("INFO", "ANNOTATIONS", "Notes on findings this run no longer raises", fmt.Sprintf("count=%d", detached))
+	}
 
+	stats.Resources = monitor.Stop()
+	logResourceProfile(addLog, stats.Resources)
+	stats.Concurrency = limiters.stats()
+	for _, c := range stats.Concurrency {
+		addLog("INFO", "RESOURCES", "Adaptive concurrency", describeConcurrency(c))
+	}
+	stats.Inference = inference.stop()
+	for _, m := range stats.Inference {
+		addLog("INFO", "RESOURCES", "Model inference", describeInference(m))
+	}
 	stats.CompletedAt = time.Now().Format(time.RFC3339)
 	stats.Status = "DONE"
+	if ctx.Err() != nil {
+		stats.Status = "CANCELLED"
+	}
 	data.RunStats = stats
+	applyQualityGates(&data, settings.GateRules(), addLog)
+
+	track.mark("SCORING")
+	if ctx.Err() != nil {
+		// A partial run must not replace the last complete report or skew
+		// stage timing history.
+		addLog("RISK", "REPORT", "Report not persisted; run cancelled", reportPath)
+		projectDBPath, reportPath = "", ""
+	}
+	if projectDBPath != "" {
+		durations := make([]db.StageDuration, 0, len(timer.timings))
+		for _, t := range timer.timings {
+			durations = append(durations, db.StageDuration{Stage: t.Stage, DurationMs: t.DurationMs})
+		}
+		if err := db.RecordStageTimings(projectDBPath, runID, words, durations); err != nil {
+			addLog("RISK", "REPORT", "Stage timings not recorded", err.Error())
+		}
+		if len(aiReport.Windows) > 0 {
+			if err := db.RecordWindowSignals(projectDBPath, runID, aiReport.Inputs()); err != nil {
+				addLog("RISK", "REPORT", "AI window signals not recorded; the run cannot be re-scored", err.Error())
+			}
+		}
+		if err := db.RecordRun(projectDBPath, projectRunRecord(data)); err != nil {
+			addLog("RISK", "REPORT", "Run not recorded in the project database", err.Error())
+		}
+		data.Benchmarks = libraryBenchmarks(workspaceRoot, projectID, data, addLog)
+	}
+	if workspaceRoot != "" && projectID != "" && anthologyReport == nil {
+		data.Drafts = draftProvenance(workspaceRoot, projectID, projectSourceName, chapters, aiReport, addLog)
+	}
 
 	if reportPath != "" {
 		report := workspace.Report{
@@ -296,38 +978,74 @@ func BuildDashboard(bookTitle, sourceName string, source []byte, text string, on
 			WordCount:      data.WordCount,
 			MHDScore:       data.MHDScore,
 			Contradictions: len(data.Contradictions),
-			SlopFlags:      data.SlopReport.Flags,
-			Analysis: map[string]any{
-				"chapter_count":        data.ChapterCount,
-				"run_stats":            data.RunStats,
-				"system":               data.System,
-				"health_issues":        data.HealthIssues,
-				"language":             data.Language,
-				"genre_scores":         data.GenreScores,
-				"genre_provider":       data.GenreProvider,
-				"genre_reasoning":      data.GenreReasoning,
-				"chapter_metrics":      data.ChapterMetrics,
-				"chapter_summaries":    data.ChapterSummaries,
-				"character_dictionary": data.CharacterDictionary,
-				"timeline":             data.Timeline,
-				"beats":                data.Beats,
-				"plot_structure":       data.PlotStructure,
-				"ai_report":            data.AIReport,
-				"slop_report":          data.SlopReport,
-				"comp_titles":          data.CompTitles,
-				"project_location":     data.ProjectLocation,
-			},
-		}
-		if err := workspace.SaveReport(reportPath, report); err != nil {
+			SlopFlags:      slop.FlagTexts(data.SlopReport.Flags),
+			SourceName:     projectSourceName,
+			SourceSHA256:   projectSourceSHA,
+			TextSHA256:     cache.textSHA256,
+			Provenance: collectProvenance(map[string]any{
+				"ollama_endpoint":       redactedURL(ollamaGenerateEndpoint()),
+				"language_model":        ollamaModel("OLLAMA_LANGUAGE_MODEL"),
+				"genre_model":           ollamaModel("OLLAMA_GENRE_MODEL", "OLLAMA_LANGUAGE_MODEL"),
+				"structure_model":       ollamaModel("OLLAMA_STRUCTURE_MODEL", "OLLAMA_GENRE_MODEL", "OLLAMA_LANGUAGE_MODEL"),
+				"sensitivity_model":     ollamaModel("OLLAMA_SENSITIVITY_MODEL", "OLLAMA_LANGUAGE_MODEL"),
+				"languagetool_endpoint": redactedURL(languageToolEndpoint()),
+				"analysis_profile":      profile,
+				"ai_detection":          aiCfg,
+				"ai_sensitivity":        aiCfg.Sensitivity,
+				"ai_weighting":          aiCfg.Weighting.Name,
+				"verified_human":        verifiedHumanFromSettings(settings),
+				"series":                seriesStore.Name,
+				"anthology":             anthology,
+				"manuscript_type":       manuscriptType,
+				"number_style":          numberStyle.StyleGuide,
+				"enabled_sections":      sortedSectionNames(sections),
+				"age_rubric":            rubric.Standard,
+				"source_retention":      settings.SourceRetention(),
+				"language_dialect":      data.Language.Dialect,
+				"dialogue_grammar":      data.Language.DialogueGrammar,
+				"comp_catalog":          compCatalog,
+				"phrase_bank":           phraseBank.Sources,
+				"bad_words":             lexiconProvenance(badWords, badWordSources),
+				"intensifiers":          lexiconProvenance(intensifiers, intensifierSources),
+				"slop_thresholds":       slopThresholds,
+				"slop_genre":            slopReport.ThresholdGenre,
+				"novelty_model":         noveltyReport.Model,
+				"embed_model":           embedModel(),
+				"lm_model":              aiLMModel,
+				"segment_tokens":        1500,
+				"segment_overlap":       200,
+			}, timer.timings),
+			Analysis: reportAnalysis(data),
+			Revision: reportRevision,
+		}
+		if saved, err := workspace.SaveReport(reportPath, report); err != nil {
 			addLog("RISK", "REPORT", "report persistence failed", err.Error())
 		} else {
-			addLog("INFO", "REPORT", "Report persisted", reportPath)
+			addLog("INFO", "REPORT", "Report persisted", fmt.Sprintf("path=%s revision=%d", reportPath, saved.Revision))
+			if saved.ConflictPath != "" {
+				addLog("RISK", "REPORT", "Report saved by another analyst during this run; theirs was kept", saved.ConflictPath)
+			}
+		}
+		if runPath, err := workspace.SaveRun(projectPath, runID, data); err != nil {
+			addLog("RISK", "REPORT", "Run not saved to project history", err.Error())
+		} else {
+			addLog("INFO", "REPORT", "Run saved to project history", runPath)
+		}
+		if seriesReport != nil {
+			// Recorded only with a persisted run, so a cancelled or failed
+			// installment never becomes context for the next one.
+			number := seriesStore.Record(seriesEntry)
+			if err := workspace.SaveSeries(workspaceRoot, seriesStore); err != nil {
+				addLog("RISK", "SERIES", "Installment not recorded in series", err.Error())
+			} else {
+				addLog("INFO", "SERIES", "Installment recorded in series", fmt.Sprintf("series=%q installment=%d path=%s", seriesStore.Name, number, workspace.SeriesPath(workspaceRoot, seriesStore.Name)))
+			}
 		}
 	}
 
 	addLog("INFO", "BOOT", "Run completed", stats.RunID)
 	data.Logs = logs
-	progress(onProgress, 100, "DONE", "Analysis complete")
+	track.progress(100, "DONE", "Analysis complete")
 	return data
 }
 
@@ -350,3 +1068,73 @@ func aiPtr(v *float64) float64 {
 	}
 	return *v
 }
+
+// reportAnalysis is the analysis section of report.json.
+func reportAnalysis(data DashboardData) map[string]any {
+	return map[string]any{
+		"chapter_count":        data.ChapterCount,
+		"run_stats":            data.RunStats,
+		"score_breakdown":      data.ScoreBreakdown,
+		"system":               data.System,
+		"health_issues":        data.HealthIssues,
+		"language":             data.Language,
+		"sensitivity":          data.Sensitivity,
+		"novelty":              data.Novelty,
+		"voice":                data.Voice,
+		"name_hygiene":         data.NameHygiene,
+		"terminology":          data.Terminology,
+		"bookends":             data.Bookends,
+		"pov":                  data.POV,
+		"pacing":               data.Pacing,
+		"stats":                data.Stats,
+		"crutch_words":         data.CrutchWords,
+		"genre_scores":         data.GenreScores,
+		"genre_provider":       data.GenreProvider,
+		"genre_reasoning":      data.GenreReasoning,
+		"genre_fallback":       data.GenreFallback,
+		"chapter_metrics":      data.ChapterMetrics,
+		"chapter_summaries":    data.ChapterSummaries,
+		"character_dictionary": data.CharacterDictionary,
+		"timeline":             data.Timeline,
+		"beats":                data.Beats,
+		"plot_structure":       data.PlotStructure,
+		"ai_report":            data.AIReport,
+		"slop_report":          data.SlopReport,
+		"comp_titles":          data.CompTitles,
+		"comp_titles_fallback": data.CompTitlesFallback,
+		"project_location":     data.ProjectLocation,
+		"series":               data.Series,
+		"anthology":            data.Anthology,
+		"manuscript_type":      data.ManuscriptType,
+		"draft_markers":        data.DraftMarkers,
+		"drafts":               data.Drafts,
+		"number_style":         data.NumberStyle,
+		"permissions":          data.Permissions,
+		"language_mix":         data.LanguageMix,
+		"nonfiction":           data.Nonfiction,
+		"model_drift":          data.ModelDrift,
+		"annotations":          data.Annotations,
+		"sections":             data.Sections,
+		"quality_metrics":      data.QualityMetrics,
+		"quality_gates":        data.QualityGates,
+		"benchmarks":           data.Benchmarks,
+	}
+}
+
+// phraseBankDir holds downloaded originality packs (<genre>.txt) that extend
+// the embedded phrase banks.
+func phraseBankDir(workspaceRoot string) string {
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return ""
+	}
+	return filepath.Join(workspaceRoot, "configs", "phrase_banks")
+}
+
+// patternStrings lists the source of each pattern, for fingerprints.
+func patternStrings(patterns []*regexp.Regexp) []string {
+	out := make([]string, 0, len(patterns))
+	for _, re := range patterns {
+		out = append(out, re.String())
+	}
+	return out
+}
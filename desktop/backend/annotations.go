@@ -0,0 +1,142 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/workspace"
+)
+
+// Annotation offsets count UTF-16 code units, like the editor selection and
+// LintExcerpt. The project database keeps byte offsets, which is what
+// re-anchoring searches the manuscript with.
+type Annotation struct {
+	ID        int64  `json:"id"`
+	FindingID string `json:"findingId"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Anchor    string `json:"anchor"`
+	Label     string `json:"label"`
+	Note      string `json:"note"`
+	Orphaned  bool   `json:"orphaned"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// AddAnnotation stores a note for the project. Offset annotations capture the
+// covered text so later runs can re-anchor them after edits.
+func AddAnnotation(projectLocation, text string, in Annotation) (Annotation, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return Annotation{}, fmt.Errorf("no project loaded")
+	}
+	if strings.TrimSpace(in.Label) == "" && strings.TrimSpace(in.Note) == "" {
+		return Annotation{}, fmt.Errorf("annotation needs a label or note")
+	}
+	record := db.Annotation{
+		FindingID: in.FindingID,
+		Label:     in.Label,
+		Note:      in.Note,
+	}
+	if strings.TrimSpace(in.FindingID) == "" {
+		start, startOK := byteOffset(text, in.Start)
+		end, endOK := byteOffset(text, in.End)
+		if !startOK || !endOK || end <= start {
+			return Annotation{}, fmt.Errorf("annotation range %d-%d is outside the manuscript", in.Start, in.End)
+		}
+		record.StartOffset = start
+		record.EndOffset = end
+		record.AnchorText = text[start:end]
+	}
+	saved, err := db.InsertAnnotation(workspace.ProjectDBPath(projectLocation), record)
+	if err != nil {
+		return Annotation{}, err
+	}
+	return annotationFromRecord(saved, text, nil), nil
+}
+
+// ListAnnotations returns the project's annotations with offsets into text.
+func ListAnnotations(projectLocation, text string) ([]Annotation, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return []Annotation{}, nil
+	}
+	records, err := db.ListAnnotations(workspace.ProjectDBPath(projectLocation))
+	if err != nil {
+		return nil, err
+	}
+	return annotationsFromRecords(records, text), nil
+}
+
+func DeleteAnnotation(projectLocation string, id int64) error {
+	if strings.TrimSpace(projectLocation) == "" {
+		return fmt.Errorf("no project loaded")
+	}
+	return db.DeleteAnnotation(workspace.ProjectDBPath(projectLocation), id)
+}
+
+func reanchorAnnotations(dbPath, text string) ([]Annotation, error) {
+	records, err := db.ReanchorAnnotations(dbPath, text)
+	if err != nil {
+		return nil, err
+	}
+	return annotationsFromRecords(records, text), nil
+}
+
+func annotationsFromRecords(records []db.Annotation, text string) []Annotation {
+	offsets := utf16Offsets(text)
+	out := make([]Annotation, 0, len(records))
+	for _, r := range records {
+		out = append(out, annotationFromRecord(r, text, offsets))
+	}
+	return out
+}
+
+// annotationFromRecord converts the stored byte offsets to UTF-16 offsets
+// into text; offsets is utf16Offsets(text), or nil to compute it.
+func annotationFromRecord(r db.Annotation, text string, offsets []int) Annotation {
+	if offsets == nil {
+		offsets = utf16Offsets(text)
+	}
+	return Annotation{
+		ID:        r.ID,
+		FindingID: r.FindingID,
+		Start:     utf16Offset(text, offsets, r.StartOffset),
+		End:       utf16Offset(text, offsets, r.EndOffset),
+		Anchor:    r.AnchorText,
+		Label:     r.Label,
+		Note:      r.Note,
+		Orphaned:  r.Orphaned,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// byteOffset converts a UTF-16 offset into text to a byte offset. It fails
+// past the end of text and inside a surrogate pair, where no byte offset
+// would keep the rune whole.
+func byteOffset(text string, pos int) (int, bool) {
+	if pos < 0 {
+		return 0, false
+	}
+	n := 0
+	for i, r := range text {
+		if n == pos {
+			return i, true
+		}
+		if n += utf16.RuneLen(r); n > pos {
+			return 0, false
+		}
+	}
+	return len(text), n == pos
+}
+
+// utf16Offset converts a stored byte offset to a UTF-16 one. An offset the
+// text cannot place (a record from an older draft) is returned as is.
+func utf16Offset(text string, offsets []int, b int) int {
+	if b < 0 || b > len(text) || (b < len(text) && !utf8.RuneStart(text[b])) {
+		return b
+	}
+	return offsets[b]
+}
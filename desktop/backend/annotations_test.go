@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"book_dashboard/internal/workspace"
+)
+
+func TestAnnotationOffsetsCountUTF16Units(t *testing.T) {
+	project := t.TempDir()
+	// "—" is one UTF-16 unit but three bytes; "😀" is two units and four bytes.
+	text := "Naïve — 😀 the lighthouse keeper waited."
+	start := len(utf16.Encode([]rune(text[:strings.Index(text, "lighthouse")])))
+	end := start + len("lighthouse")
+
+	saved, err := AddAnnotation(project, text, Annotation{Start: start, End: end, Label: "motif"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Anchor != "lighthouse" || saved.Start != start || saved.End != end {
+		t.Fatalf("expected the UTF-16 range to anchor on the word, got %+v", saved)
+	}
+
+	// Splitting the emoji's surrogate pair would store half a rune.
+	emoji := len(utf16.Encode([]rune("Naïve — ")))
+	if _, err := AddAnnotation(project, text, Annotation{Start: emoji + 1, End: end, Label: "bad"}); err == nil {
+		t.Fatal("expected a range inside a surrogate pair rejected")
+	}
+
+	edited := "Prologue ✨ added. " + text
+	anchored, err := reanchorAnnotations(workspace.ProjectDBPath(project), edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	editedUnits := utf16.Encode([]rune(edited))
+	if len(anchored) != 1 || string(utf16.Decode(editedUnits[anchored[0].Start:anchored[0].End])) != "lighthouse" {
+		t.Fatalf("expected the re-anchored range in UTF-16 units, got %+v", anchored)
+	}
+}
@@ -0,0 +1,340 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/workspace"
+)
+
+// How story boundaries were found, from most to least explicit.
+const (
+	StoryBoundaryBylines  = "bylines"
+	StoryBoundaryHeadings = "headings"
+	StoryBoundaryChapters = "chapters"
+)
+
+const (
+	// minStoryWords is the least prose a title heading must stand over to
+	// open a story; shorter runs are sections inside a story or front matter.
+	minStoryWords = 400
+	// maxStoryTitleWords bounds a line read as a story title.
+	maxStoryTitleWords = 8
+	// anthologyAIGap and anthologyLanguageGap are how far a story must sit
+	// from the collection median to be called out.
+	anthologyAIGap       = 0.25
+	anthologyLanguageGap = 15
+)
+
+var bylinePattern = regexp.MustCompile(`^(?i:by)\s+(\p{Lu}[\p{L}.'’-]*(?:\s+\p{Lu}[\p{L}.'’-]*){0,4})$`)
+var sectionHeadingPattern = regexp.MustCompile(`(?i)^((part|book|section)\s+\S+|prologue|epilogue|interlude|afterword|foreword|introduction|contents|acknowledg(e)?ments)$`)
+var romanNumeralPattern = regexp.MustCompile(`(?i)^[ivxlcdm]+\.?$`)
+
+type anthologyKey struct{}
+
+// WithAnthology overrides the project setting for the run: true analyzes the
+// manuscript as a collection of stories, false as one novel.
+func WithAnthology(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, anthologyKey{}, enabled)
+}
+
+// resolveAnthology picks the run override, then the project setting, and
+// reports which one won.
+func resolveAnthology(ctx context.Context, settings workspace.ProjectSettings) (bool, string) {
+	if enabled, ok := ctx.Value(anthologyKey{}).(bool); ok {
+		return enabled, "run"
+	}
+	if settings.Anthology {
+		return true, "project"
+	}
+	return false, "default"
+}
+
+// SetAnthology stores whether the project is a story collection; it applies
+// from the next analysis run.
+func SetAnthology(projectLocation string, enabled bool) (bool, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return false, fmt.Errorf("no project loaded")
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return false, err
+	}
+	settings.Anthology = enabled
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return false, err
+	}
+	return settings.Anthology, nil
+}
+
+// story is one piece of a collection. Its text leaves out the title and
+// byline lines.
+type story struct {
+	title  string
+	author string
+	text   string
+}
+
+// detectStories splits a collection into stories. Bylines ("by Jane Doe")
+// under a title are the strongest boundary; failing two of those, title-case
+// headings over at least minStoryWords of prose; failing that, the
+// manuscript's chapters. Text before the first boundary is front matter and
+// belongs to no story.
+func detectStories(text string) ([]story, string) {
+	lines := nonEmptyLines(text)
+	type boundary struct {
+		at     int // first line of the story's heading
+		body   int // first line of its prose
+		title  string
+		author string
+	}
+
+	var bylined []boundary
+	for i, line := range lines {
+		m := bylinePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		b := boundary{at: i, body: i + 1, author: m[1]}
+		if i > 0 && storyTitleLine(lines[i-1]) {
+			b.at, b.title = i-1, lines[i-1]
+		}
+		bylined = append(bylined, b)
+	}
+
+	boundaries, method := bylined, StoryBoundaryBylines
+	if len(bylined) < 2 {
+		boundaries, method = nil, StoryBoundaryHeadings
+		var candidates []int
+		for i, line := range lines {
+			if storyTitleLine(line) {
+				candidates = append(candidates, i)
+			}
+		}
+		stackStart := -1
+		for k, i := range candidates {
+			next := len(lines)
+			if k+1 < len(candidates) {
+				next = candidates[k+1]
+			}
+			prose := countWords(lines[i+1 : next])
+			if prose == 0 {
+				// Stacked headings (a collection title, a contents list)
+				// end with the story's own title.
+				if stackStart < 0 {
+					stackStart = i
+				}
+				continue
+			}
+			at := i
+			if stackStart >= 0 {
+				at, stackStart = stackStart, -1
+			}
+			// A heading over less than a story's worth of prose is a
+			// section inside the story before it, or front matter.
+			if prose >= minStoryWords {
+				boundaries = append(boundaries, boundary{at: at, body: i + 1, title: lines[i]})
+			}
+		}
+	}
+
+	if len(boundaries) < 2 {
+		chapters := splitChapters(text)
+		out := make([]story, 0, len(chapters))
+		for _, ch := range chapters {
+			out = append(out, story{title: ch.title, text: ch.text})
+		}
+		return out, StoryBoundaryChapters
+	}
+
+	out := make([]story, 0, len(boundaries))
+	for k, b := range boundaries {
+		end := len(lines)
+		if k+1 < len(boundaries) {
+			end = boundaries[k+1].at
+		}
+		title := b.title
+		if title == "" {
+			title = fmt.Sprintf("Story %d", k+1)
+		}
+		out = append(out, story{title: title, author: b.author, text: strings.Join(lines[b.body:end], "\n")})
+	}
+	return out, method
+}
+
+// storiesAsChapters lets every per-chapter stage (genre, language, AI
+// window placement, summaries) run per story.
+func storiesAsChapters(stories []story) []chapter {
+	out := make([]chapter, 0, len(stories))
+	for i, s := range stories {
+		out = append(out, chapter{index: i + 1, title: s.title, text: s.text})
+	}
+	return out
+}
+
+// storyTitleLine accepts a short heading in title case or capitals, with no
+// sentence punctuation, dialogue or bare numbering.
+func storyTitleLine(line string) bool {
+	words := strings.Fields(line)
+	if len(words) == 0 || len(words) > maxStoryTitleWords {
+		return false
+	}
+	if strings.ContainsAny(line[len(line)-1:], ".,;:!?\"”'’…") || strings.ContainsAny(line[:1], "\"“'‘") {
+		return false
+	}
+	if chapterHeaderPattern.MatchString(line) || sectionHeadingPattern.MatchString(line) || bylinePattern.MatchString(line) || sceneBreakPattern.MatchString(line) || romanNumeralPattern.MatchString(line) {
+		return false
+	}
+	letters := false
+	for i, w := range words {
+		r := []rune(w)
+		if !unicode.IsLetter(r[0]) {
+			if unicode.IsDigit(r[0]) {
+				continue
+			}
+			return false
+		}
+		letters = true
+		// Short function words stay lower case inside a title.
+		if !unicode.IsUpper(r[0]) && (i == 0 || len(r) > 3) {
+			return false
+		}
+	}
+	return letters
+}
+
+// storyContradictions runs the consistency check inside each story, placing
+// every contradiction on its story and naming the story's own chapters in
+// the description.
+func storyContradictions(stories []story, custom []AttributeExtractor) []forensics.Contradiction {
+	out := []forensics.Contradiction{}
+	for i, s := range stories {
+		for _, c := range detectHeuristicContradictions(splitChapters(s.text), nil, custom) {
+			c.Description = fmt.Sprintf("%s: %s", s.title, c.Description)
+			c.ChapterA, c.ChapterB = i+1, i+1
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func countWords(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		n += len(strings.Fields(line))
+	}
+	return n
+}
+
+// buildAnthologyReport puts each story's genre, AI likelihood and language
+// scores side by side and calls out the stories that stand apart from the
+// rest of the collection.
+func buildAnthologyReport(stories []story, method string, metrics []ChapterMetric, ai []StoryAI, language LanguageReport) *AnthologyReport {
+	report := &AnthologyReport{Detection: method, Stories: make([]StoryReport, 0, len(stories)), Authors: []string{}, GenreMix: []GenreCount{}, Flags: []string{}}
+	authors := map[string]bool{}
+	genres := map[string]int{}
+	for i, s := range stories {
+		sr := StoryReport{Index: i + 1, Title: s.title, Author: s.author, WordCount: len(strings.Fields(s.text))}
+		if s.author != "" && !authors[s.author] {
+			authors[s.author] = true
+			report.Authors = append(report.Authors, s.author)
+		}
+		if i < len(metrics) {
+			sr.TopGenre, sr.TopGenreScore, sr.GenreProvider = metrics[i].TopGenre, metrics[i].TopGenreScore, metrics[i].GenreProvider
+			if sr.TopGenre != "" {
+				genres[sr.TopGenre]++
+			}
+		}
+		if i < len(ai) {
+			sr.AI = ai[i]
+		}
+		for _, c := range language.Chapters {
+			if c.Chapter == i+1 {
+				sr.SpellingScore, sr.GrammarScore, sr.ReadabilityScore, sr.NeedsAttention = c.SpellingScore, c.GrammarScore, c.ReadabilityScore, c.NeedsAttention
+			}
+		}
+		report.Stories = append(report.Stories, sr)
+	}
+	for g, n := range genres {
+		report.GenreMix = append(report.GenreMix, GenreCount{Genre: g, Stories: n})
+	}
+	sort.Slice(report.GenreMix, func(i, j int) bool {
+		if report.GenreMix[i].Stories == report.GenreMix[j].Stories {
+			return report.GenreMix[i].Genre < report.GenreMix[j].Genre
+		}
+		return report.GenreMix[i].Stories > report.GenreMix[j].Stories
+	})
+
+	pai := []float64{}
+	grammar, spelling := []float64{}, []float64{}
+	for _, s := range report.Stories {
+		if s.AI.Windows > 0 {
+			pai = append(pai, s.AI.Mean)
+		}
+		if s.GrammarScore > 0 || s.SpellingScore > 0 {
+			grammar = append(grammar, float64(s.GrammarScore))
+			spelling = append(spelling, float64(s.SpellingScore))
+		}
+	}
+	report.MedianAI = median(pai)
+	medianGrammar, medianSpelling := median(grammar), median(spelling)
+	for _, s := range report.Stories {
+		if s.AI.Windows > 0 && len(pai) >= 3 && s.AI.Mean >= 0.5 && s.AI.Mean-report.MedianAI >= anthologyAIGap {
+			report.Flags = append(report.Flags, fmt.Sprintf("%q reads as more AI-like (%.2f) than the rest of the collection (median %.2f).", s.Title, s.AI.Mean, report.MedianAI))
+		}
+		if len(grammar) >= 3 && (s.GrammarScore > 0 || s.SpellingScore > 0) {
+			if medianGrammar-float64(s.GrammarScore) >= anthologyLanguageGap {
+				report.Flags = append(report.Flags, fmt.Sprintf("%q scores %d for grammar against a collection median of %.0f.", s.Title, s.GrammarScore, medianGrammar))
+			}
+			if medianSpelling-float64(s.SpellingScore) >= anthologyLanguageGap {
+				report.Flags = append(report.Flags, fmt.Sprintf("%q scores %d for spelling against a collection median of %.0f.", s.Title, s.SpellingScore, medianSpelling))
+			}
+		}
+	}
+	if len(report.GenreMix) > 1 {
+		mix := make([]string, 0, len(report.GenreMix))
+		for _, g := range report.GenreMix {
+			mix = append(mix, fmt.Sprintf("%s %d", g.Genre, g.Stories))
+		}
+		report.Flags = append(report.Flags, fmt.Sprintf("Stories span %d genres (%s).", len(report.GenreMix), strings.Join(mix, ", ")))
+	}
+	return report
+}
+
+// storyAI summarizes the AI windows overlapping each story; verified-human
+// windows are left out as they are from the document estimate.
+func storyAI(windows []aidetect.WindowReport, stories int) []StoryAI {
+	out := make([]StoryAI, stories)
+	for _, w := range windows {
+		if w.Location == nil || w.Exempt {
+			continue
+		}
+		for idx := w.Location.StartChapter; idx <= w.Location.EndChapter && idx <= stories; idx++ {
+			s := &out[idx-1]
+			s.Mean = (s.Mean*float64(s.Windows) + w.PAI) / float64(s.Windows+1)
+			s.Max = math.Max(s.Max, w.PAI)
+			s.Windows++
+		}
+	}
+	return out
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// storyProse returns n lines of plain narration, about 10 words each.
+func storyProse(name string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s walked along the shore for the %d time that week.", name, i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDetectStoriesByHeadingsSkipsFrontMatter(t *testing.T) {
+	text := strings.Join([]string{
+		"Harbor Tales",
+		"Contents",
+		"The Drowned Bell",
+		"Salt and Iron",
+		"The Drowned Bell",
+		storyProse("Mara", 50),
+		"Low Tide",
+		storyProse("Mara", 5),
+		"Salt and Iron",
+		storyProse("Tomas", 50),
+	}, "\n")
+
+	stories, method := detectStories(text)
+	if method != StoryBoundaryHeadings {
+		t.Fatalf("detection = %q, want headings", method)
+	}
+	if len(stories) != 2 || stories[0].title != "The Drowned Bell" || stories[1].title != "Salt and Iron" {
+		t.Fatalf("unexpected stories: %+v", stories)
+	}
+	if !strings.Contains(stories[0].text, "Low Tide") || strings.Contains(stories[0].text, "Tomas") {
+		t.Fatal("a short section heading should stay inside its story")
+	}
+}
+
+func TestDetectStoriesByBylinesRecordsAuthors(t *testing.T) {
+	text := strings.Join([]string{
+		"The Lamp", "by Ada Reyes", storyProse("Mara", 5),
+		"Night Ferry", "by Jon Okafor", storyProse("Tomas", 5),
+	}, "\n")
+	stories, method := detectStories(text)
+	if method != StoryBoundaryBylines || len(stories) != 2 {
+		t.Fatalf("want 2 bylined stories, got %d via %s", len(stories), method)
+	}
+	if stories[1].title != "Night Ferry" || stories[1].author != "Jon Okafor" || strings.Contains(stories[1].text, "by Jon") {
+		t.Fatalf("unexpected second story: %+v", stories[1])
+	}
+}
+
+func TestBuildAnthologyReportFlagsOutlierStory(t *testing.T) {
+	stories := []story{{title: "One"}, {title: "Two"}, {title: "Three"}}
+	metrics := []ChapterMetric{{TopGenre: "Literary"}, {TopGenre: "Literary"}, {TopGenre: "Sci-Fi"}}
+	ai := []StoryAI{{Windows: 2, Mean: 0.2}, {Windows: 2, Mean: 0.25}, {Windows: 1, Mean: 0.9}}
+	language := LanguageReport{Chapters: []ChapterLanguageScore{
+		{Chapter: 1, SpellingScore: 95, GrammarScore: 92},
+		{Chapter: 2, SpellingScore: 94, GrammarScore: 60},
+		{Chapter: 3, SpellingScore: 96, GrammarScore: 90},
+	}}
+
+	report := buildAnthologyReport(stories, StoryBoundaryHeadings, metrics, ai, language)
+	flags := strings.Join(report.Flags, "\n")
+	for _, want := range []string{`"Three" reads as more AI-like`, `"Two" scores 60 for grammar`, "Stories span 2 genres (Literary 2, Sci-Fi 1)"} {
+		if !strings.Contains(flags, want) {
+			t.Errorf("missing flag %q in:\n%s", want, flags)
+		}
+	}
+	if strings.Contains(flags, `"One"`) {
+		t.Errorf("story One is typical of the collection, got:\n%s", flags)
+	}
+}
@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/gates"
+	"book_dashboard/internal/workspace"
+)
+
+// minBenchmarkBooks is how many other analyzed books a percentile needs
+// before it says anything about the manuscript.
+const minBenchmarkBooks = 3
+
+// Benchmark places one of the run's scores among the other books analyzed
+// in the workspace.
+type Benchmark struct {
+	Metric string  `json:"metric"`
+	Label  string  `json:"label"`
+	Value  float64 `json:"value"`
+	// Percentile is the share of the other books scoring lower, 0 to 100.
+	Percentile float64 `json:"percentile"`
+	Books      int     `json:"books"`
+	Summary    string  `json:"summary"`
+}
+
+const (
+	benchmarkVerbatimCoverage = "verbatim_duplication_coverage"
+	benchmarkPhraseCoverage   = "repeated_phrase_coverage"
+	benchmarkWordCount        = "word_count"
+)
+
+var benchmarkLabels = map[string]string{
+	gates.MetricMHDScore:       "MHD score",
+	gates.MetricPAIDoc:         "Document AI probability",
+	gates.MetricAISuspicion:    "AI suspicion score",
+	gates.MetricGrammar:        "Grammar score",
+	gates.MetricSpelling:       "Spelling score",
+	gates.MetricReadability:    "Readability score",
+	gates.MetricContradictions: "Contradiction count",
+	benchmarkVerbatimCoverage:  "Repetition coverage",
+	benchmarkPhraseCoverage:    "Repeated phrase coverage",
+	benchmarkWordCount:         "Word count",
+}
+
+// benchmarkScores are the run's scores kept in the library: the measured
+// quality metrics, the repetition coverages and the length.
+func benchmarkScores(data DashboardData) map[string]float64 {
+	scores := map[string]float64{
+		benchmarkVerbatimCoverage: data.SlopReport.VerbatimDuplicationCoverage,
+		benchmarkPhraseCoverage:   data.SlopReport.RepeatedPhraseCoverage,
+		benchmarkWordCount:        float64(data.WordCount),
+	}
+	for metric, value := range data.QualityMetrics {
+		if _, ok := benchmarkLabels[metric]; ok {
+			scores[metric] = value
+		}
+	}
+	return scores
+}
+
+// libraryBenchmarks compares the run with the latest runs of the other
+// projects in the workspace library, then records the run there for the
+// projects analyzed after it. Metrics fewer than minBenchmarkBooks other
+// books measured are left out.
+func libraryBenchmarks(workspaceRoot, projectID string, data DashboardData, addLog func(level, stage, message, detail string)) []Benchmark {
+	libraryPath := workspace.LibraryDBPath(workspaceRoot)
+	scores := benchmarkScores(data)
+	found, err := db.LibraryBenchmarks(libraryPath, projectID, scores)
+	if err != nil {
+		addLog("RISK", "SCORING", "Library benchmarks unavailable", err.Error())
+	}
+	if err := db.RecordLibraryScores(libraryPath, projectID, data.RunStats.RunID, scores); err != nil {
+		addLog("RISK", "SCORING", "Run not recorded in the workspace library", err.Error())
+	}
+
+	out := []Benchmark{}
+	for _, b := range found {
+		if b.Books < minBenchmarkBooks {
+			continue
+		}
+		label := benchmarkLabels[b.Metric]
+		percentile := b.Percentile()
+		out = append(out, Benchmark{
+			Metric:     b.Metric,
+			Label:      label,
+			Value:      b.Value,
+			Percentile: percentile,
+			Books:      b.Books,
+			Summary:    fmt.Sprintf("%s is higher than %.0f%% of the %d other books you've analyzed.", label, percentile, b.Books),
+		})
+	}
+	if len(out) > 0 {
+		addLog("INFO", "SCORING", "Library benchmarks computed", fmt.Sprintf("metrics=%d library=%s", len(out), libraryPath))
+	}
+	return out
+}
+
+// projectWorkspaceRoot is the workspace holding projectRoot, when the
+// project sits in a workspace's projects directory.
+func projectWorkspaceRoot(projectRoot string) (string, bool) {
+	parent := filepath.Dir(filepath.Clean(projectRoot))
+	if filepath.Base(parent) != "projects" {
+		return "", false
+	}
+	return filepath.Dir(parent), true
+}
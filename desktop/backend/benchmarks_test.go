@@ -0,0 +1,54 @@
+package backend
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/gates"
+)
+
+func TestLibraryBenchmarksNeedEnoughOtherBooks(t *testing.T) {
+	root := t.TempDir()
+	discard := func(string, string, string, string) {}
+	run := func(project string, coverage float64, mhd int) []Benchmark {
+		data := DashboardData{MHDScore: mhd, WordCount: 80000}
+		data.SlopReport.VerbatimDuplicationCoverage = coverage
+		data.RunStats.RunID = "run-" + project
+		data.QualityMetrics = gates.Metrics{gates.MetricMHDScore: float64(mhd), gates.MetricHighContradictions: 0}
+		return libraryBenchmarks(root, project, data, discard)
+	}
+
+	run("a", 0.01, 60)
+	if got := run("b", 0.02, 70); len(got) != 0 {
+		t.Fatalf("expected no benchmarks with one other book, got %+v", got)
+	}
+	run("c", 0.03, 80)
+	got := run("d", 0.09, 75)
+	byMetric := map[string]Benchmark{}
+	for _, b := range got {
+		byMetric[b.Metric] = b
+	}
+	coverage, ok := byMetric[benchmarkVerbatimCoverage]
+	if !ok || coverage.Percentile != 100 || coverage.Books != 3 {
+		t.Fatalf("expected the highest coverage above every other book, got %+v", got)
+	}
+	if !strings.Contains(coverage.Summary, "Repetition coverage is higher than 100% of the 3 other books") {
+		t.Fatalf("unexpected summary %q", coverage.Summary)
+	}
+	if _, ok := byMetric[gates.MetricHighContradictions]; ok {
+		t.Fatalf("expected only benchmarked metrics kept, got %+v", got)
+	}
+	if byMetric[gates.MetricMHDScore].Percentile < 66 || byMetric[gates.MetricMHDScore].Percentile > 67 {
+		t.Fatalf("expected MHD 75 above two of three books, got %+v", byMetric[gates.MetricMHDScore])
+	}
+}
+
+func TestProjectWorkspaceRoot(t *testing.T) {
+	if root, ok := projectWorkspaceRoot(filepath.Join("home", "ManuscriptHealth", "projects", "abc")); !ok || root != filepath.Join("home", "ManuscriptHealth") {
+		t.Fatalf("expected the workspace of a project, got %q %v", root, ok)
+	}
+	if _, ok := projectWorkspaceRoot(t.TempDir()); ok {
+		t.Fatal("expected a directory outside a workspace rejected")
+	}
+}
@@ -0,0 +1,189 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// A bookend pattern is formulaic once it covers bookendFormulaShare of the
+	// chapters and at least minBookendChapters of them.
+	bookendFormulaShare = 0.4
+	minBookendChapters  = 3
+	// oneLineCloserWords is the longest final paragraph counted as a one-line
+	// stinger.
+	oneLineCloserWords = 12
+	maxBookendExamples = 3
+)
+
+// bookendRule names a recognizable way to open or close a chapter.
+type bookendRule struct {
+	pattern string
+	label   string
+	match   func(paragraph string, paragraphs int) bool
+}
+
+var (
+	weatherOpenPattern  = regexp.MustCompile(`(?i)\b(rain(ed|ing)?|snow(ed|ing)?|sun(light|rise|set)?|wind|storm|fog|mist|clouds?|thunder|drizzle|frost|sky|heat|dawn broke)\b`)
+	wakingOpenPattern   = regexp.MustCompile(`(?i)\b(woke|awoke|wakes|waking|alarm (clock )?(rang|went off|blared)|opened (his|her|my|their) eyes)\b`)
+	timeJumpOpenPattern = regexp.MustCompile(`(?i)^(the next (morning|day|night|week)|the following|(that|this) (morning|afternoon|evening|night)|by (morning|noon|nightfall|dawn|evening)|(\w+ )?(hours|days|weeks|months|years) (later|earlier|after)|later that)\b`)
+	sleepClosePattern   = regexp.MustCompile(`(?i)\b(fell asleep|drifted off|sleep (took|claimed)|darkness (took|swallowed|claimed)|closed (his|her|my|their) eyes|slept)\b`)
+	ominousClosePattern = regexp.MustCompile(`(?i)\b(little did|had no idea|would never be the same|nothing would (ever )?be the same|everything (had )?changed|only the beginning|that was when|if only (he|she|i|they) had known)\b`)
+	italicLinePattern   = regexp.MustCompile(`^[*_][^*_]+[*_][.!?]?$`)
+	bookendLeadPattern  = regexp.MustCompile(`^["“'‘]?([A-Za-z']+)\s+([A-Za-z']+)`)
+)
+
+var openingRules = []bookendRule{
+	{pattern: "weather", label: "Opens on weather", match: func(p string, _ int) bool { return weatherOpenPattern.MatchString(firstSentence(p)) }},
+	{pattern: "waking", label: "Opens with a character waking", match: func(p string, _ int) bool { return wakingOpenPattern.MatchString(firstSentence(p)) }},
+	{pattern: "time_jump", label: "Opens with a time jump", match: func(p string, _ int) bool { return timeJumpOpenPattern.MatchString(p) }},
+	{pattern: "dialogue", label: "Opens mid-dialogue", match: func(p string, _ int) bool { return dialogueOnlyPattern.MatchString(p) || strings.HasPrefix(p, "“") }},
+}
+
+var closingRules = []bookendRule{
+	{pattern: "italic_one_liner", label: "Ends on an italicized line", match: func(p string, _ int) bool { return italicLinePattern.MatchString(p) }},
+	{pattern: "one_line_stinger", label: "Ends on a one-line paragraph", match: func(p string, paragraphs int) bool {
+		return paragraphs >= 3 && len(strings.Fields(p)) <= oneLineCloserWords && len(splitSentences(p)) == 1
+	}},
+	{pattern: "question", label: "Ends on a question", match: func(p string, _ int) bool { return strings.HasSuffix(strings.TrimRight(p, `"”'’`), "?") }},
+	{pattern: "ominous", label: "Ends on foreshadowing", match: func(p string, _ int) bool { return ominousClosePattern.MatchString(p) }},
+	{pattern: "sleep", label: "Ends with a character falling asleep", match: func(p string, _ int) bool { return sleepClosePattern.MatchString(p) }},
+	{pattern: "dialogue", label: "Ends on dialogue", match: func(p string, _ int) bool { return strings.HasSuffix(p, `"`) || strings.HasSuffix(p, "”") }},
+}
+
+// BookendReport clusters how chapters open and close, so formulaic habits
+// (every chapter opens on weather, every chapter ends on a one-line stinger)
+// show up with counts and examples.
+type BookendReport struct {
+	Chapters int              `json:"chapters"`
+	Openings []BookendPattern `json:"openings"`
+	Closings []BookendPattern `json:"closings"`
+	Flags    []string         `json:"flags"`
+}
+
+type BookendPattern struct {
+	Pattern   string   `json:"pattern"`
+	Label     string   `json:"label"`
+	Count     int      `json:"count"`
+	Share     float64  `json:"share"`
+	Chapters  []int    `json:"chapters"`
+	Examples  []string `json:"examples"`
+	Formulaic bool     `json:"formulaic"`
+}
+
+func emptyBookendReport() BookendReport {
+	return BookendReport{Openings: []BookendPattern{}, Closings: []BookendPattern{}, Flags: []string{}}
+}
+
+// analyzeChapterBookends classifies each chapter's first and last paragraph
+// against the opening and closing rules, and clusters openings that start
+// with the same two words. Patterns seen in fewer than two chapters are
+// dropped.
+func analyzeChapterBookends(chapters []chapter) BookendReport {
+	report := emptyBookendReport()
+	openings := map[string]*BookendPattern{}
+	closings := map[string]*BookendPattern{}
+	add := func(into map[string]*BookendPattern, pattern, label string, index int, example string) {
+		p, ok := into[pattern]
+		if !ok {
+			p = &BookendPattern{Pattern: pattern, Label: label, Chapters: []int{}, Examples: []string{}}
+			into[pattern] = p
+		}
+		p.Count++
+		p.Chapters = append(p.Chapters, index)
+		if len(p.Examples) < maxBookendExamples {
+			p.Examples = append(p.Examples, fmt.Sprintf("Ch%d: %s", index, firstWords(example, 16)))
+		}
+	}
+
+	for _, ch := range chapters {
+		paragraphs := chapterParagraphs(ch.text)
+		if len(paragraphs) == 0 {
+			continue
+		}
+		report.Chapters++
+		first, last := paragraphs[0], paragraphs[len(paragraphs)-1]
+		for _, rule := range openingRules {
+			if rule.match(first, len(paragraphs)) {
+				add(openings, rule.pattern, rule.label, ch.index, first)
+			}
+		}
+		if m := bookendLeadPattern.FindStringSubmatch(first); m != nil {
+			lead := strings.ToLower(m[1] + " " + m[2])
+			add(openings, "lead:"+lead, fmt.Sprintf("Opens with %q", lead), ch.index, first)
+		}
+		for _, rule := range closingRules {
+			if rule.match(last, len(paragraphs)) {
+				add(closings, rule.pattern, rule.label, ch.index, last)
+			}
+		}
+	}
+
+	report.Openings = rankBookends(openings, report.Chapters)
+	report.Closings = rankBookends(closings, report.Chapters)
+	for _, group := range []struct {
+		kind     string
+		patterns []BookendPattern
+	}{{"openings", report.Openings}, {"endings", report.Closings}} {
+		for _, p := range group.patterns {
+			if p.Formulaic {
+				report.Flags = append(report.Flags, fmt.Sprintf("Formulaic chapter %s: %s in %d of %d chapters.", group.kind, strings.ToLower(p.Label[:1])+p.Label[1:], p.Count, report.Chapters))
+			}
+		}
+	}
+	return report
+}
+
+func rankBookends(patterns map[string]*BookendPattern, chapters int) []BookendPattern {
+	out := make([]BookendPattern, 0, len(patterns))
+	for _, p := range patterns {
+		if p.Count < 2 {
+			continue
+		}
+		p.Share = round2(float64(p.Count) / float64(chapters))
+		p.Formulaic = p.Count >= minBookendChapters && p.Share >= bookendFormulaShare
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Pattern < out[j].Pattern
+	})
+	return out
+}
+
+// chapterParagraphs returns the chapter's non-empty lines without its
+// heading; inline headings ("Chapter 3 The rain ...") are trimmed off the
+// first paragraph.
+func chapterParagraphs(text string) []string {
+	out := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	if len(out) == 0 {
+		return out
+	}
+	// A heading line ("Chapter 3: The Storm") has no closing punctuation.
+	if chapterHeaderPattern.MatchString(out[0]) && strings.TrimRight(out[0], ".!?\"”") == out[0] {
+		return out[1:]
+	}
+	if loc := chapterInlinePattern.FindStringIndex(out[0]); loc != nil && loc[0] == 0 {
+		out[0] = strings.TrimSpace(out[0][loc[1]:])
+		if out[0] == "" {
+			return out[1:]
+		}
+	}
+	return out
+}
+
+func firstSentence(paragraph string) string {
+	if sentences := splitSentences(paragraph); len(sentences) > 0 {
+		return sentences[0]
+	}
+	return paragraph
+}
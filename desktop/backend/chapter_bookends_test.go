@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeChapterBookendsFindsFormula(t *testing.T) {
+	openers := []string{
+		"Rain hammered the windows of the station.",
+		"Rain drummed on the tin roof all night.",
+		"Rain fell in sheets over the harbor.",
+		"Mara counted the boxes twice before lunch.",
+		"Rain again, thin and cold, over the fields.",
+	}
+	chapters := make([]chapter, 0, len(openers))
+	for i, opener := range openers {
+		text := strings.Join([]string{
+			fmt.Sprintf("Chapter %d", i+1),
+			opener + " She waited by the door.",
+			"They talked about the ledger for a long while and nobody mentioned the missing money.",
+			"*She was not alone.*",
+		}, "\n")
+		chapters = append(chapters, chapter{index: i + 1, title: fmt.Sprintf("Chapter %d", i+1), text: text})
+	}
+
+	report := analyzeChapterBookends(chapters)
+	if report.Chapters != 5 {
+		t.Fatalf("expected five chapters, got %+v", report)
+	}
+	patterns := map[string]BookendPattern{}
+	for _, p := range append(report.Openings, report.Closings...) {
+		patterns[p.Pattern] = p
+	}
+	if p := patterns["weather"]; p.Count != 4 || !p.Formulaic || len(p.Examples) != maxBookendExamples {
+		t.Fatalf("expected four weather openings flagged with examples, got %+v", p)
+	}
+	if p := patterns["italic_one_liner"]; p.Count != 5 || !p.Formulaic {
+		t.Fatalf("expected every chapter to end on an italic line, got %+v", p)
+	}
+	if _, ok := patterns["waking"]; ok {
+		t.Fatalf("no chapter opens with waking: %+v", report.Openings)
+	}
+	flags := strings.Join(report.Flags, " ")
+	if !strings.Contains(flags, "opens on weather in 4 of 5 chapters") || !strings.Contains(flags, "ends on an italicized line in 5 of 5") {
+		t.Fatalf("unexpected flags %v", report.Flags)
+	}
+}
+
+func TestChapterParagraphsDropsHeading(t *testing.T) {
+	if got := chapterParagraphs("Chapter 2 The storm broke at noon.\nShe ran."); got[0] != "The storm broke at noon." {
+		t.Fatalf("expected the inline heading trimmed, got %q", got)
+	}
+	if got := chapterParagraphs("Chapter Two\nShe ran."); len(got) != 1 || got[0] != "She ran." {
+		t.Fatalf("expected the heading line dropped, got %q", got)
+	}
+}
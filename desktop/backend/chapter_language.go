@@ -0,0 +1,45 @@
+package backend
+
+// A chapter needs copyediting attention when its spelling or grammar score is
+// below copyeditAttentionScore, or copyeditAttentionGap points below the
+// manuscript as a whole.
+const (
+	copyeditAttentionScore = 75
+	copyeditAttentionGap   = 10
+)
+
+func chapterLanguageScore(ch chapter, words, spellingIssues, grammarIssues, spellingScore, grammarScore int) ChapterLanguageScore {
+	return ChapterLanguageScore{
+		Chapter:          ch.index,
+		Title:            ch.title,
+		WordCount:        words,
+		SpellingScore:    spellingScore,
+		GrammarScore:     grammarScore,
+		ReadabilityScore: measureReadingLevel(ch.text).readabilityScore(),
+		SpellingIssues:   spellingIssues,
+		GrammarIssues:    grammarIssues,
+	}
+}
+
+// heuristicChapterScores keeps the per-chapter table populated when
+// LanguageTool is unavailable. Issue counts are not comparable with
+// LanguageTool's, so they are left at zero.
+func heuristicChapterScores(chapters []chapter) []ChapterLanguageScore {
+	out := make([]ChapterLanguageScore, 0, len(chapters))
+	for _, ch := range chapters {
+		h := heuristicLanguage(ch.text)
+		out = append(out, chapterLanguageScore(ch, len(wordPattern.FindAllString(ch.text, -1)), 0, 0, h.SpellingScore, h.GrammarScore))
+	}
+	return out
+}
+
+func markCopyeditAttention(scores []ChapterLanguageScore, spellingScore, grammarScore int) {
+	for i := range scores {
+		s := &scores[i]
+		if s.WordCount == 0 {
+			continue
+		}
+		s.NeedsAttention = s.SpellingScore < copyeditAttentionScore || s.GrammarScore < copyeditAttentionScore ||
+			spellingScore-s.SpellingScore >= copyeditAttentionGap || grammarScore-s.GrammarScore >= copyeditAttentionGap
+	}
+}
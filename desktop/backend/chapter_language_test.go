@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"book_dashboard/internal/scheduler"
+)
+
+func TestAnalyzeWithLanguageToolScoresEachChapter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.FormValue("text"), "Messy") {
+			_, _ = w.Write([]byte(`{"matches":[
+				{"offset":0,"rule":{"id":"MORFOLOGIK_RULE_EN_US","category":{"id":"TYPOS"}}},
+				{"offset":6,"rule":{"id":"HE_VERB_AGR","category":{"id":"GRAMMAR"}}},
+				{"offset":9,"rule":{"id":"HE_VERB_AGR","category":{"id":"GRAMMAR"}}}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"matches":[]}`))
+	}))
+	defer server.Close()
+	t.Setenv("LANGUAGETOOL_URL", server.URL)
+
+	clean := strings.Repeat("The rain kept falling. ", 25)
+	chapters := []chapter{
+		{index: 1, title: "Clean", text: clean},
+		{index: 2, title: "Messy", text: "Messy " + clean},
+	}
+	report, err := analyzeWithLanguageTool(chapters, languageToolOptions{dialect: DialectUS})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(report.Chapters) != 2 {
+		t.Fatalf("expected two chapter scores, got %+v", report.Chapters)
+	}
+	first, second := report.Chapters[0], report.Chapters[1]
+	if first.SpellingScore != 100 || first.GrammarScore != 100 || first.SpellingIssues != 0 {
+		t.Fatalf("expected clean chapter at 100, got %+v", first)
+	}
+	if second.SpellingIssues != 1 || second.GrammarIssues != 2 || second.GrammarScore >= first.GrammarScore {
+		t.Fatalf("expected messy chapter to carry its own issues, got %+v", second)
+	}
+	if report.GrammarScore <= second.GrammarScore {
+		t.Fatalf("expected manuscript grammar %d above the messy chapter's %d", report.GrammarScore, second.GrammarScore)
+	}
+
+	markCopyeditAttention(report.Chapters, report.SpellingScore, report.GrammarScore)
+	if report.Chapters[0].NeedsAttention || !report.Chapters[1].NeedsAttention {
+		t.Fatalf("expected only the messy chapter flagged, got %+v", report.Chapters)
+	}
+}
+
+func TestAnalyzeWithLanguageToolConcurrentKeepsChapterOrder(t *testing.T) {
+	t.Setenv("MHD_MAX_WORKERS", "4")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		text := r.FormValue("text")
+		if strings.Contains(text, "Broken") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Early chapters answer last, so completion order differs from
+		// chapter order once requests overlap.
+		if strings.HasPrefix(text, "Early") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if strings.Contains(text, "Messy") {
+			_, _ = w.Write([]byte(`{"matches":[{"offset":0,"rule":{"id":"MORFOLOGIK_RULE_EN_US","category":{"id":"TYPOS"}}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"matches":[]}`))
+	}))
+	defer server.Close()
+	t.Setenv("LANGUAGETOOL_URL", server.URL)
+
+	clean := strings.Repeat(" The rain kept falling.", 25)
+	chapters := []chapter{}
+	for i := 1; i <= 12; i++ {
+		text := "Late" + clean
+		if i <= 4 {
+			text = "Early" + clean
+		}
+		if i%3 == 0 {
+			text = "Messy " + text
+		}
+		chapters = append(chapters, chapter{index: i, title: strconv.Itoa(i), text: text})
+	}
+	limiter := scheduler.New("test", 4, nil)
+	report, err := analyzeWithLanguageTool(chapters, languageToolOptions{dialect: DialectUS, limiter: limiter})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	for i, c := range report.Chapters {
+		want := 0
+		if (i+1)%3 == 0 {
+			want = 1
+		}
+		if c.Chapter != i+1 || c.SpellingIssues != want {
+			t.Fatalf("chapter %d: expected %d spelling issues in order, got %+v", i+1, want, c)
+		}
+	}
+	if limiter.Stats().Calls != len(chapters) {
+		t.Fatalf("expected every chapter checked through the limiter, got %+v", limiter.Stats())
+	}
+
+	chapters[5].text = "Broken" + clean
+	if _, err := analyzeWithLanguageTool(chapters, languageToolOptions{dialect: DialectUS, limiter: scheduler.New("test", 4, nil)}); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected the failing chapter's error, got %v", err)
+	}
+}
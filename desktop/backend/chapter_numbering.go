@@ -0,0 +1,178 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	HealthIssueChapterNumbering = "chapter_numbering"
+
+	// maxChapterHeadingWords keeps prose that happens to start with
+	// "Chapter 3" out of the heading outline.
+	maxChapterHeadingWords = 12
+)
+
+// partHeadingPattern matches Part and Book headings, after which chapter
+// numbering may start again at 1.
+var partHeadingPattern = regexp.MustCompile(`(?i)^\s*(part|book)\s+([0-9ivxlcdm]+|one|two|three|four|five|six|seven|eight|nine|ten)\b`)
+
+// chapterHeading is one numbered chapter heading. Position is its place among
+// the manuscript's chapter headings and chapter the detected chapter it
+// opens, both 1-based; chapter is 0 when no detected chapter starts there.
+type chapterHeading struct {
+	line     string
+	number   int
+	position int
+	chapter  int
+}
+
+// numberingIssue is one break in the chapter sequence: a duplicate, a
+// heading out of order, or missing numbers before heading.
+type numberingIssue struct {
+	heading  chapterHeading
+	previous chapterHeading
+	severity string
+	problem  string
+}
+
+// checkChapterNumbering reads the numbered chapter headings in order and
+// reports duplicates, headings numbered below the one before, and gaps. A
+// Part or Book heading followed by Chapter 1 starts the count again.
+func checkChapterNumbering(text string, chapters []chapter) []numberingIssue {
+	headings := chapterHeadings(text, chapters)
+	if len(headings) < 2 {
+		return nil
+	}
+	out := []numberingIssue{}
+	seen := map[int]chapterHeading{}
+	var last chapterHeading
+	flushGaps := func() {
+		// Numbers never seen below the highest one are missing; each run is
+		// reported at the heading that follows it.
+		highest := 0
+		for n := range seen {
+			highest = max(highest, n)
+		}
+		for n := 1; n <= highest; n++ {
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			end := n
+			for end+1 <= highest {
+				if _, ok := seen[end+1]; ok {
+					break
+				}
+				end++
+			}
+			next := seen[end+1]
+			severity, problem := "MED", fmt.Sprintf("%s missing before %q, which is the %s chapter heading", chapterRange(n, end), next.line, ordinal(next.position))
+			if n == 1 {
+				severity, problem = "LOW", fmt.Sprintf("Numbering starts at %q; %s not in the manuscript", next.line, strings.ToLower(chapterRange(n, end)))
+			}
+			out = append(out, numberingIssue{heading: next, severity: severity, problem: problem})
+			n = end
+		}
+	}
+	for _, h := range headings {
+		if h.number == 0 {
+			// A Part heading: Chapter 1 next starts a new count.
+			last.number = -1
+			continue
+		}
+		if last.number == -1 && h.number == 1 {
+			flushGaps()
+			seen = map[int]chapterHeading{}
+		}
+		switch prev, dup := seen[h.number]; {
+		case dup:
+			out = append(out, numberingIssue{heading: h, previous: prev, severity: "HIGH", problem: fmt.Sprintf("%q appears twice: the %s and %s chapter headings", h.line, ordinal(prev.position), ordinal(h.position))})
+		case last.number > h.number:
+			out = append(out, numberingIssue{heading: h, previous: last, severity: "HIGH", problem: fmt.Sprintf("%q comes after %q; it is the %s chapter heading", h.line, last.line, ordinal(h.position))})
+			seen[h.number] = h
+		default:
+			seen[h.number] = h
+		}
+		last = h
+	}
+	flushGaps()
+	return out
+}
+
+// chapterHeadings lists the short heading lines that name a chapter number,
+// and the Part headings between them with number 0.
+func chapterHeadings(text string, chapters []chapter) []chapterHeading {
+	out := []chapterHeading{}
+	position, cursor := 0, 0
+	for _, line := range nonEmptyLines(text) {
+		if len(strings.Fields(line)) > maxChapterHeadingWords {
+			continue
+		}
+		if partHeadingPattern.MatchString(line) && !chapterHeaderPattern.MatchString(line) {
+			out = append(out, chapterHeading{line: line})
+			continue
+		}
+		m := chapterHeaderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, ok := chapterNumber(m[2])
+		if !ok {
+			continue
+		}
+		position++
+		h := chapterHeading{line: line, number: n, position: position}
+		for i := cursor; i < len(chapters); i++ {
+			if chapters[i].title == line || strings.HasPrefix(chapters[i].text, line) {
+				h.chapter, cursor = chapters[i].index, i+1
+				break
+			}
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+func chapterNumberingIssues(issues []numberingIssue, existing int) []HealthIssue {
+	out := make([]HealthIssue, 0, len(issues))
+	for i, issue := range issues {
+		chapterA := issue.previous.chapter
+		if issue.previous.line == "" {
+			chapterA = issue.heading.chapter
+		}
+		out = append(out, HealthIssue{
+			ID:          fmt.Sprintf("issue-%03d", existing+i+1),
+			Kind:        HealthIssueChapterNumbering,
+			Entity:      "Chapter numbering",
+			Severity:    issue.severity,
+			Description: issue.problem,
+			ChapterA:    chapterA,
+			ChapterB:    issue.heading.chapter,
+			ContextA:    issue.previous.line,
+			ContextB:    issue.heading.line,
+		})
+	}
+	return out
+}
+
+func chapterRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("Chapter %d is", from)
+	}
+	return fmt.Sprintf("Chapters %d–%d are", from, to)
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckChapterNumberingFindsGapsDuplicatesAndDisorder(t *testing.T) {
+	text := strings.Join([]string{
+		"Chapter 1", "She woke.",
+		"Chapter 2", "She ran.",
+		"Chapter 4", "She hid.",
+		"Chapter 4", "She hid again.",
+		"Chapter 6", "She fought.",
+		"Chapter 5", "She rested.",
+		"Chapter 3 was the one she never reread, because it still hurt to think about the harbor.",
+	}, "\n")
+	chapters := splitChapters(text)
+	issues := checkChapterNumbering(text, chapters)
+	problems := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		problems = append(problems, issue.problem)
+	}
+	want := []string{
+		`"Chapter 4" appears twice: the 3rd and 4th chapter headings`,
+		`"Chapter 5" comes after "Chapter 6"; it is the 6th chapter heading`,
+		`Chapter 3 is missing before "Chapter 4", which is the 3rd chapter heading`,
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected numbering issues:\n%s", strings.Join(problems, "\n"))
+	}
+	dup := issues[0]
+	if dup.severity != "HIGH" || dup.previous.chapter != 3 || dup.heading.chapter != 4 {
+		t.Fatalf("expected the duplicate placed on chapters 3 and 4, got %+v", dup)
+	}
+
+	health := chapterNumberingIssues(issues, 2)
+	if health[0].ID != "issue-003" || health[0].Kind != HealthIssueChapterNumbering || health[2].ChapterA != health[2].ChapterB {
+		t.Fatalf("unexpected health issues %+v", health)
+	}
+	data := plainReportFixture()
+	data.HealthIssues = health
+	if plain := PlainReport(data); !strings.Contains(plain, "1. HIGH severity: \"Chapter 4\" appears twice") {
+		t.Fatalf("expected numbering issues in the plain report:\n%s", plain)
+	}
+}
+
+func TestCheckChapterNumberingAllowsRestartsAfterParts(t *testing.T) {
+	text := "Part One\nChapter One\nA.\nChapter Two\nB.\nPart Two\nChapter 1\nC.\nChapter 2\nD."
+	if issues := checkChapterNumbering(text, splitChapters(text)); len(issues) != 0 {
+		t.Fatalf("expected a Part to restart the count, got %+v", issues)
+	}
+	late := "Chapter V\nA.\nChapter VI\nB."
+	issues := checkChapterNumbering(late, splitChapters(late))
+	if len(issues) != 1 || issues[0].severity != "LOW" || issues[0].problem != `Numbering starts at "Chapter V"; chapters 1–4 are not in the manuscript` {
+		t.Fatalf("expected one low-severity note for a late start, got %+v", issues)
+	}
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Chapter summaries feed the timeline, beats, comp-title and plot-structure
+// prompts and every report, so a bad one is caught where it is made instead
+// of being inherited downstream. The summaries are extractive, so there is
+// no model answer to re-prompt for; a summary that fails the guard is
+// replaced by the chapter's opening prose. Meta-text matters all the same:
+// manuscripts drafted with a chatbot often keep its asides ("As an AI
+// language model...", "Here is the revised chapter"), and those sentences
+// must not become a chapter's summary or events.
+const (
+	summaryMinWords      = 6
+	summaryMaxWords      = 48
+	summaryFallbackWords = 36
+)
+
+const (
+	SummaryFallbackMetaText = "meta_text"
+	SummaryFallbackTooShort = "too_short"
+	SummaryFallbackTooLong  = "too_long"
+)
+
+var summaryMetaPattern = regexp.MustCompile(`(?i)\b(?:as an ai\b|(?:ai|large) language model|here(?: is|'s) (?:a|an|the|your) (?:summary|revised|rewritten|chapter|story|draft|continuation)|i hope (?:this|that) helps|let me know if you(?:'d| would)? (?:like|want)|i(?: cannot|'m unable to|can't| am unable to) (?:help|assist|write|continue|provide)|certainly[!,] here)`)
+
+// summaryProblem names what is wrong with a summary, or returns "".
+func summaryProblem(summary string) string {
+	n := len(strings.Fields(summary))
+	switch {
+	case summaryMetaPattern.MatchString(summary):
+		return SummaryFallbackMetaText
+	case n < summaryMinWords:
+		return SummaryFallbackTooShort
+	case n > summaryMaxWords:
+		return SummaryFallbackTooLong
+	}
+	return ""
+}
+
+// guardChapterSummary returns summary, or when it fails the guard the
+// chapter's opening sentences without meta-text, with the reason it was
+// replaced. A chapter too short for a better summary keeps the one it has.
+func guardChapterSummary(text, summary string) (string, string) {
+	problem := summaryProblem(summary)
+	if problem == "" {
+		return summary, ""
+	}
+	opening := []string{}
+	words := 0
+	for _, s := range splitSentences(text) {
+		if words >= summaryMinWords {
+			break
+		}
+		if summaryMetaPattern.MatchString(s) {
+			continue
+		}
+		opening = append(opening, s)
+		words += len(strings.Fields(s))
+	}
+	fallback := firstWords(strings.Join(opening, " "), summaryFallbackWords)
+	if fallback == "" || (problem == SummaryFallbackTooShort && len(strings.Fields(fallback)) <= len(strings.Fields(summary))) {
+		return summary, ""
+	}
+	return fallback, problem
+}
@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuardChapterSummaryReplacesMetaText(t *testing.T) {
+	text := "Mara crossed the frozen river at dawn with the ledger under her coat. " +
+		"As an AI language model, I cannot continue this story without more context. " +
+		"The ferryman waited on the far bank and refused to meet her eyes."
+	events := deriveEvents(text)
+	for _, e := range events {
+		if strings.Contains(e, "language model") {
+			t.Fatalf("meta-text picked as an event: %q", e)
+		}
+	}
+	summary, fallback := guardChapterSummary(text, "As an AI language model, I cannot continue this story.")
+	if fallback != SummaryFallbackMetaText || !strings.HasPrefix(summary, "Mara crossed the frozen river") || strings.Contains(summary, "AI") {
+		t.Fatalf("expected the opening prose for meta-text, got %q (%s)", summary, fallback)
+	}
+
+	long := strings.Repeat("word ", summaryMaxWords+5)
+	if summary, fallback = guardChapterSummary(text, long); fallback != SummaryFallbackTooLong || len(strings.Fields(summary)) > summaryFallbackWords {
+		t.Fatalf("expected a bounded fallback for a long summary, got %q (%s)", summary, fallback)
+	}
+	if summary, fallback = guardChapterSummary(text, "Mara crosses the river and meets the ferryman."); fallback != "" {
+		t.Fatalf("expected a good summary kept, got %q (%s)", summary, fallback)
+	}
+	if summary, fallback = guardChapterSummary("She left.", "She left."); summary != "She left." || fallback != "" {
+		t.Fatalf("expected a tiny chapter to keep its summary, got %q (%s)", summary, fallback)
+	}
+}
@@ -0,0 +1,316 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxCoreferenceNames caps the characters sent to the coreference
+	// prompt; minor characters rarely carry aliases worth a model call.
+	maxCoreferenceNames = 40
+)
+
+const characterTitleAlternation = `(Mr|Mrs|Ms|Miss|Dr|Doctor|Prof|Professor|Captain|Detective|Inspector|Sergeant|Lord|Lady|Sir|Aunt|Uncle)`
+
+// characterTitles are honorifics and ranks. They are stripped from a
+// reference ("Dr. Cole") to find the name it stands for, and are never a
+// character on their own.
+var characterTitles = map[string]bool{}
+
+func init() {
+	for _, t := range strings.Split(strings.Trim(characterTitleAlternation, "()"), "|") {
+		characterTitles[t] = true
+	}
+}
+
+var titledReferencePattern = regexp.MustCompile(`\b` + characterTitleAlternation + `\.?[ \t]+([A-Z][a-z]{2,})(?:[ \t]+([A-Z][a-z]{2,}))?\b`)
+var capitalizedRunPattern = regexp.MustCompile(`\b[A-Z][a-z]{2,}(?:[ \t]+[A-Z][a-z]{2,})+\b`)
+var lowerWordPattern = regexp.MustCompile(`\b[a-z]{3,}\b`)
+var midSentenceNamePattern = regexp.MustCompile(`[a-z,;:][ \t]+([A-Z][a-z]{2,})\b`)
+
+// mergeCharacterAliases folds the dictionary entries that name the same
+// character into one. "Sarah" and "Cole" join "Sarah Cole" when the
+// manuscript uses the full name and neither part belongs to another full
+// name; titled references ("Dr. Cole") resolve through the name they carry.
+// A name part also used as an ordinary lowercase word ("will") is never
+// merged, and a full name's first part must appear mid-sentence somewhere,
+// so a capitalized sentence opening ("When Sarah") is not taken for one. groups are further sets of names known to corefer, such as
+// those from coreferenceGroupsWithOllama. Merged entries carry the names
+// they absorbed as Aliases and their mentions are counted again without
+// counting "Sarah Cole" as both a Sarah and a Cole.
+func mergeCharacterAliases(chapters []chapter, entries []CharacterEntry, groups [][]string) []CharacterEntry {
+	byName := map[string]int{}
+	kept := make([]CharacterEntry, 0, len(entries))
+	for _, e := range entries {
+		if characterTitles[e.Name] {
+			continue
+		}
+		byName[e.Name] = len(kept)
+		kept = append(kept, e)
+	}
+
+	lowerWords, midSentence := map[string]bool{}, map[string]bool{}
+	fullNames := map[string]int{}
+	titled := map[string]map[string]bool{}
+	for _, ch := range chapters {
+		for _, w := range lowerWordPattern.FindAllString(ch.text, -1) {
+			lowerWords[w] = true
+		}
+		for _, m := range midSentenceNamePattern.FindAllStringSubmatch(ch.text, -1) {
+			midSentence[m[1]] = true
+		}
+		for _, m := range titledReferencePattern.FindAllStringSubmatch(ch.text, -1) {
+			if m[3] != "" {
+				continue
+			}
+			if titled[m[2]] == nil {
+				titled[m[2]] = map[string]bool{}
+			}
+			titled[m[2]][m[0]] = true
+		}
+		for _, run := range capitalizedRunPattern.FindAllString(ch.text, -1) {
+			parts := strings.Fields(run)
+			for len(parts) > 0 && characterTitles[parts[0]] {
+				parts = parts[1:]
+			}
+			if len(parts) != 2 {
+				continue
+			}
+			fullNames[parts[0]+" "+parts[1]]++
+		}
+	}
+
+	// A name part shared by two full names ("Sarah Cole", "Mark Cole") is
+	// ambiguous and stays its own entry.
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(n string) string {
+		p, ok := parent[n]
+		if !ok || p == n {
+			return n
+		}
+		root := find(p)
+		parent[n] = root
+		return root
+	}
+	union := func(a, b string) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+	partOf := map[string][]string{}
+	for full := range fullNames {
+		parts := strings.Fields(full)
+		usable := midSentence[parts[0]]
+		for _, p := range parts {
+			if _, ok := byName[p]; !ok || lowerWords[strings.ToLower(p)] {
+				usable = false
+			}
+		}
+		if !usable {
+			delete(fullNames, full)
+			continue
+		}
+		for _, p := range parts {
+			partOf[p] = append(partOf[p], full)
+		}
+	}
+	for full := range fullNames {
+		for _, p := range strings.Fields(full) {
+			if len(partOf[p]) == 1 {
+				union(full, p)
+			}
+		}
+	}
+	for _, g := range groups {
+		known := []string{}
+		for _, n := range g {
+			n = strings.TrimSpace(n)
+			if _, ok := byName[n]; ok {
+				known = append(known, n)
+			} else if _, ok := fullNames[n]; ok {
+				known = append(known, n)
+			}
+		}
+		for _, n := range known[min(1, len(known)):] {
+			union(known[0], n)
+		}
+	}
+
+	members := map[string][]string{}
+	for _, e := range kept {
+		root := find(e.Name)
+		members[root] = append(members[root], e.Name)
+	}
+	for full := range fullNames {
+		if root := find(full); root != full || len(members[root]) > 0 {
+			members[root] = append(members[root], full)
+		}
+	}
+
+	out := make([]CharacterEntry, 0, len(members))
+	for _, names := range members {
+		if len(names) == 1 {
+			e := kept[byName[names[0]]]
+			e.Aliases = titledAliases(titled, names)
+			out = append(out, e)
+			continue
+		}
+		out = append(out, mergedCharacter(chapters, kept, byName, fullNames, titled, names))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := characterProminence(out[i]), characterProminence(out[j]); a != b {
+			return a > b
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// mergedCharacter builds one entry from the names of a coreference group,
+// named after its most used full name or else its most mentioned name.
+func mergedCharacter(chapters []chapter, kept []CharacterEntry, byName map[string]int, fullNames map[string]int, titled map[string]map[string]bool, names []string) CharacterEntry {
+	canonical, best := "", -1
+	for _, n := range names {
+		score := 0
+		if i, ok := byName[n]; ok {
+			score = kept[i].TotalMentions
+		} else {
+			score = 1<<30 + fullNames[n]
+		}
+		if score > best || (score == best && n < canonical) {
+			canonical, best = n, score
+		}
+	}
+
+	aliases := []string{}
+	for _, n := range names {
+		if n != canonical {
+			aliases = append(aliases, n)
+		}
+	}
+	aliases = append(aliases, titledAliases(titled, names)...)
+	sort.Strings(aliases)
+
+	// Longest names first, so "Sarah Cole" is one mention rather than a
+	// Sarah and a Cole.
+	alternation := append([]string{}, names...)
+	sort.Slice(alternation, func(i, j int) bool { return len(alternation[i]) > len(alternation[j]) })
+	for i := range alternation {
+		alternation[i] = regexp.QuoteMeta(alternation[i])
+	}
+	mentionPattern := regexp.MustCompile(`\b(` + strings.Join(alternation, "|") + `)\b`)
+
+	records := map[int]*CharacterChapterRecord{}
+	for _, n := range names {
+		i, ok := byName[n]
+		if !ok {
+			continue
+		}
+		for _, r := range kept[i].Chapters {
+			rec, ok := records[r.Chapter]
+			if !ok {
+				r.Actions = append([]string{}, r.Actions...)
+				records[r.Chapter] = &r
+				continue
+			}
+			rec.PronounMentions += r.PronounMentions
+			for _, a := range r.Actions {
+				if !containsString(rec.Actions, a) {
+					rec.Actions = append(rec.Actions, a)
+				}
+			}
+		}
+	}
+
+	entry := CharacterEntry{Name: canonical, Aliases: aliases}
+	for _, ch := range chapters {
+		rec, ok := records[ch.index]
+		if !ok {
+			continue
+		}
+		rec.Mentions = len(mentionPattern.FindAllStringIndex(ch.text, -1))
+		entry.TotalMentions += rec.Mentions
+		entry.PronounMentions += rec.PronounMentions
+		if len(entry.Chapters) == 0 || ch.index < entry.FirstSeenChapter {
+			entry.FirstSeenChapter = ch.index
+		}
+		if ch.index > entry.LastSeenChapter {
+			entry.LastSeenChapter = ch.index
+		}
+		entry.Chapters = append(entry.Chapters, *rec)
+	}
+	entry.Description = fmt.Sprintf("Appears in %d chapter(s), from Ch %d to Ch %d.", len(entry.Chapters), entry.FirstSeenChapter, entry.LastSeenChapter)
+	return entry
+}
+
+func titledAliases(titled map[string]map[string]bool, names []string) []string {
+	out := []string{}
+	for _, n := range names {
+		for ref := range titled[n] {
+			out = append(out, ref)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// characterAliasIndex maps every name and alias in the dictionary to the
+// entry's name, so per-name findings land on the canonical character.
+func characterAliasIndex(entries []CharacterEntry) map[string]string {
+	index := map[string]string{}
+	for _, e := range entries {
+		for _, a := range e.Aliases {
+			index[a] = e.Name
+		}
+	}
+	for _, e := range entries {
+		index[e.Name] = e.Name
+	}
+	return index
+}
+
+type coreferenceResult struct {
+	Groups [][]string `json:"groups"`
+}
+
+// coreferenceGroupsWithOllama asks the model which of the most mentioned
+// characters are the same person under different names ("Liz" and
+// "Elizabeth", "the Captain" and "Hale"), quoting a sentence for each.
+func coreferenceGroupsWithOllama(model string, chapters []chapter, entries []CharacterEntry) ([][]string, error) {
+	var b strings.Builder
+	b.WriteString("You are a copy editor building a character list. Some names below may refer to the same person ")
+	b.WriteString("(a nickname, a surname, a title with a surname). Group only names you are confident name the same character; ")
+	b.WriteString("leave everyone else out. Use the names exactly as listed. Return JSON only: {\"groups\":[[\"name\",\"name\"]]}.\n\nCHARACTERS:\n")
+	for _, e := range entries[:min(len(entries), maxCoreferenceNames)] {
+		fmt.Fprintf(&b, "- %s", e.Name)
+		if len(e.Aliases) > 0 {
+			fmt.Fprintf(&b, " (also %s)", strings.Join(e.Aliases, ", "))
+		}
+		if sample := characterSample(chapters, e.Name); sample != "" {
+			fmt.Fprintf(&b, ": %s", sample)
+		}
+		b.WriteString("\n")
+	}
+	var parsed coreferenceResult
+	if err := generateOllamaJSON(model, b.String(), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed.Groups, nil
+}
+
+// characterSample is the first sentence naming the character, shortened.
+func characterSample(chapters []chapter, name string) string {
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for _, ch := range chapters {
+		for _, s := range splitSentences(ch.text) {
+			if pattern.MatchString(s) {
+				return firstWords(s, 25)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeCharacterAliasesFoldsFullNamesAndTitles(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "Arrival", text: "Sarah Cole arrived at the clinic at dawn. Sarah said the storm was close. Sarah's eyes were blue, and Mark Pine waited by the door."},
+		{index: 2, title: "Rounds", text: "Dr. Cole checked the charts. Cole's eyes were green in the lamplight. Mark said nothing. When Sarah left, the ward went quiet."},
+	}
+	dictionary, _, _ := buildCharacterDictionary(chapters)
+	merged := mergeCharacterAliases(chapters, dictionary, nil)
+
+	byName := map[string]CharacterEntry{}
+	for _, c := range merged {
+		byName[c.Name] = c
+	}
+	sarah, ok := byName["Sarah Cole"]
+	if !ok {
+		t.Fatalf("expected Sarah, Cole and Dr. Cole merged into Sarah Cole, got %+v", merged)
+	}
+	if strings.Join(sarah.Aliases, ",") != "Cole,Dr. Cole,Sarah" {
+		t.Fatalf("expected the absorbed names as aliases, got %v", sarah.Aliases)
+	}
+	// Sarah Cole, Sarah, Sarah's, Dr. Cole, Cole's and Sarah: the full name counts once.
+	if sarah.TotalMentions != 6 || sarah.FirstSeenChapter != 1 || sarah.LastSeenChapter != 2 || len(sarah.Chapters) != 2 {
+		t.Fatalf("unexpected merged entry %+v", sarah)
+	}
+	for _, gone := range []string{"Sarah", "Cole", "Mark", "Pine"} {
+		if _, ok := byName[gone]; ok {
+			t.Fatalf("expected %s folded into a full name, got %+v", gone, merged)
+		}
+	}
+	if _, ok := byName["Mark Pine"]; !ok {
+		t.Fatalf("expected Mark and Pine merged too, got %+v", merged)
+	}
+	// "When Sarah" only ever opens a sentence, so it is not a full name.
+	if _, ok := byName["When Sarah"]; ok {
+		t.Fatalf("expected a capitalized sentence opening not treated as a full name, got %+v", merged)
+	}
+
+	contradictions := detectHeuristicContradictions(chapters, characterAliasIndex(merged), nil)
+	if len(contradictions) != 1 || contradictions[0].EntityName != "sarah cole" || contradictions[0].Attribute != "eyes" {
+		t.Fatalf("expected the eye colour change found on the canonical character, got %+v", contradictions)
+	}
+	if unresolved := detectHeuristicContradictions(chapters, nil, nil); len(unresolved) != 0 {
+		t.Fatalf("expected no contradiction without alias resolution, got %+v", unresolved)
+	}
+}
+
+func TestMergeCharacterAliasesKeepsSharedSurnamesApart(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "One", text: "Rain fell as Sarah Cole and Mark Cole crossed the yard. Sarah laughed. Mark said the gate was locked. Cole said nothing."},
+	}
+	dictionary, _, _ := buildCharacterDictionary(chapters)
+	merged := mergeCharacterAliases(chapters, dictionary, [][]string{{"Sarah", "Unknown"}})
+	names := map[string]bool{}
+	for _, c := range merged {
+		names[c.Name] = true
+	}
+	if !names["Sarah Cole"] || !names["Mark Cole"] || !names["Cole"] {
+		t.Fatalf("expected both full names merged and the shared surname left alone, got %+v", merged)
+	}
+}
@@ -34,12 +34,15 @@ func buildCharacterDictionary(chapters []chapter) ([]CharacterEntry, []ChapterSu
 
 	for _, ch := range chapters {
 		events := deriveEvents(ch.text)
-		summary := deriveSummary(ch.text, events)
-		cs := ChapterSummary{Chapter: ch.index, Title: ch.title, Summary: summary, Events: events}
+		summary, fallback := guardChapterSummary(ch.text, deriveSummary(ch.text, events))
+		cs := ChapterSummary{Chapter: ch.index, Title: ch.title, Summary: summary, Events: events, Fallback: fallback}
 		chapterSummaries = append(chapterSummaries, cs)
 		chapterByID[ch.index] = cs
 
 		names := namesInText(ch.text)
+		actors := chapterActors(ch.text, names)
+		mentions := nameMentionCounts(ch.text)
+		pronouns := estimatePronounMentions(ch.text, actors)
 		for _, name := range names {
 			item, ok := entries[name]
 			if !ok {
@@ -52,13 +55,16 @@ func buildCharacterDictionary(chapters []chapter) ([]CharacterEntry, []ChapterSu
 			if ch.index > item.entry.LastSeenChapter {
 				item.entry.LastSeenChapter = ch.index
 			}
-			item.entry.TotalMentions += strings.Count(ch.text, name)
+			item.entry.TotalMentions += mentions[name]
+			item.entry.PronounMentions += pronouns[name]
 			item.entry.Chapters = append(item.entry.Chapters, CharacterChapterRecord{
-				Chapter: ch.index,
-				Title:   ch.title,
-				Summary: summary,
-				Actions: deriveActions(name, ch.text),
-				Events:  events,
+				Chapter:         ch.index,
+				Title:           ch.title,
+				Mentions:        mentions[name],
+				PronounMentions: pronouns[name],
+				Summary:         summary,
+				Actions:         deriveActions(name, ch.text, actors),
+				Events:          events,
 			})
 		}
 	}
@@ -70,10 +76,10 @@ func buildCharacterDictionary(chapters []chapter) ([]CharacterEntry, []ChapterSu
 		out = append(out, v.entry)
 	}
 	sort.Slice(out, func(i, j int) bool {
-		if out[i].TotalMentions == out[j].TotalMentions {
-			return out[i].Name < out[j].Name
+		if a, b := characterProminence(out[i]), characterProminence(out[j]); a != b {
+			return a > b
 		}
-		return out[i].TotalMentions > out[j].TotalMentions
+		return out[i].Name < out[j].Name
 	})
 	sort.Slice(chapterSummaries, func(i, j int) bool { return chapterSummaries[i].Chapter < chapterSummaries[j].Chapter })
 	return out, chapterSummaries, chapterByID
@@ -86,6 +92,7 @@ func buildHealthIssues(contradictions []forensics.Contradiction, chapterByID map
 		b := chapterByID[c.ChapterB]
 		issues = append(issues, HealthIssue{
 			ID:            fmt.Sprintf("issue-%03d", i+1),
+			Kind:          HealthIssueContradiction,
 			Entity:        c.EntityName,
 			Severity:      c.Severity,
 			Description:   c.Description,
@@ -157,6 +164,9 @@ func deriveEvents(text string) []string {
 	}
 	candidates := make([]candidate, 0, len(sentences))
 	for _, s := range sentences {
+		if summaryMetaPattern.MatchString(s) {
+			continue
+		}
 		lower := strings.ToLower(s)
 		score := 0
 		if eventVerbPattern.MatchString(lower) {
@@ -209,29 +219,77 @@ func deriveEvents(text string) []string {
 	return out
 }
 
-func deriveActions(name, text string) []string {
-	sentences := splitSentences(text)
+// deriveActions picks the sentences that best show what name does in a
+// chapter. Besides sentences naming the character, it follows pronouns within
+// a paragraph: after a sentence whose only named character is name, later
+// sentences using he or she and naming no one else are theirs too, until
+// another of the actors is named or the pronoun changes gender. When the
+// chapter's chains mostly use one pronoun for name, chains using the other are
+// dropped. Pronoun sentences rank just below named ones of the same weight.
+func deriveActions(name, text string, actors map[string]bool) []string {
 	out := make([]string, 0, 3)
 	needle := strings.ToLower(name)
 	type candidate struct {
 		sentence string
 		score    int
+		pronoun  string
 	}
 	candidates := make([]candidate, 0, 8)
-	for _, s := range sentences {
-		lower := strings.ToLower(s)
-		if strings.Contains(lower, needle) {
+	pronounVotes := map[string]int{}
+	for _, paragraph := range chapterParagraphs(text) {
+		chained, pronoun := false, ""
+		for _, s := range splitSentences(paragraph) {
+			lower := strings.ToLower(s)
+			named := strings.Contains(lower, needle)
+			others := namesOtherThan(s, name, actors)
 			score := 1
+			switch {
+			case named:
+				chained, pronoun = !others, ""
+			case others:
+				chained = false
+				continue
+			default:
+				p := subjectPronoun(lower)
+				if p == "" {
+					continue
+				}
+				if !chained || (pronoun != "" && pronoun != p) {
+					chained = false
+					continue
+				}
+				if pronoun == "" {
+					pronounVotes[p]++
+				}
+				pronoun, score = p, 0
+			}
 			if eventVerbPattern.MatchString(lower) {
 				score += 3
 			}
 			if causalMarkerPattern.MatchString(lower) {
 				score++
 			}
-			candidates = append(candidates, candidate{sentence: strings.TrimSpace(s), score: score})
+			c := candidate{sentence: strings.TrimSpace(s), score: score}
+			if !named {
+				c.pronoun = pronoun
+			}
+			candidates = append(candidates, c)
 		}
 	}
-	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if he, she := pronounVotes["he"], pronounVotes["she"]; he != she {
+		gender := "he"
+		if she > he {
+			gender = "she"
+		}
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if c.pronoun == "" || c.pronoun == gender {
+				kept = append(kept, c)
+			}
+		}
+		candidates = kept
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
 	for _, c := range candidates {
 		if len(out) >= 3 {
 			break
@@ -244,6 +302,48 @@ func deriveActions(name, text string) []string {
 	return out
 }
 
+var subjectPronounPattern = regexp.MustCompile(`\b(he|she)\b`)
+
+// subjectPronoun is "he" or "she" when the sentence uses only that one of
+// them, and "" when it uses neither or both.
+func subjectPronoun(lower string) string {
+	found := ""
+	for _, p := range subjectPronounPattern.FindAllString(lower, -1) {
+		if found != "" && found != p {
+			return ""
+		}
+		found = p
+	}
+	return found
+}
+
+// namesOtherThan reports whether the sentence names one of the actors other
+// than name.
+func namesOtherThan(sentence, name string, actors map[string]bool) bool {
+	for _, n := range properNamePattern.FindAllString(sentence, -1) {
+		if n != name && actors[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// chapterActors are the candidate names a chapter also uses mid-sentence, so
+// capitalized sentence openers ("When", "Rain") do not count as characters.
+func chapterActors(text string, names []string) map[string]bool {
+	mid := map[string]bool{}
+	for _, m := range midSentenceNamePattern.FindAllStringSubmatch(text, -1) {
+		mid[m[1]] = true
+	}
+	actors := map[string]bool{}
+	for _, n := range names {
+		if mid[n] {
+			actors[n] = true
+		}
+	}
+	return actors
+}
+
 func splitSentences(text string) []string {
 	parts := sentenceExtractPattern.FindAllString(text, -1)
 	out := make([]string, 0, len(parts))
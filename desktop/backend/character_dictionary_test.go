@@ -1,6 +1,9 @@
 package backend
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestNamesInTextFiltersDialogueFillersButKeepsCharacterNames(t *testing.T) {
 	text := `
@@ -28,3 +31,25 @@ I met Dawn at the station.
 		}
 	}
 }
+
+func TestDeriveActionsFollowsPronounsWithinParagraph(t *testing.T) {
+	text := "Sarah reached the harbor at dusk. She confronted the harbormaster about the missing boat. Then she found the ledger.\n" +
+		"The tide turned and a bell rang over the water while the gulls circled.\n" +
+		"He refused to talk to anyone that night, and the docks emptied early.\n" +
+		"Sarah waited beside Mark and the lamps. She escaped through the back gate.\n" +
+		"Sarah laughed at the rain. She was soaked.\n" +
+		// Sarah is "she" in two chains, so a "he" chain is someone else.
+		"Sarah stood alone by the rail. He arrived late, soaked through."
+	names := namesInText(text)
+	actions := deriveActions("Sarah", text, chapterActors(text, names))
+
+	joined := strings.Join(actions, "|")
+	if !strings.Contains(joined, "She confronted the harbormaster") || !strings.Contains(joined, "Then she found the ledger") {
+		t.Fatalf("expected pronoun sentences after a named subject attributed, got %v", actions)
+	}
+	for _, stray := range []string{"He refused", "She escaped", "He arrived"} {
+		if strings.Contains(joined, stray) {
+			t.Fatalf("expected %q not attributed (new paragraph, second character or pronoun change), got %v", stray, actions)
+		}
+	}
+}
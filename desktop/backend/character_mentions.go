@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// pronounSampleParagraphs caps the paragraphs of a chapter read for pronoun
+// resolution. Longer chapters are sampled at even intervals and the counts
+// scaled up, so the estimate costs the same for every chapter.
+const pronounSampleParagraphs = 60
+
+var pronounTokenPattern = regexp.MustCompile(`\b(?:[Hh]e|[Hh]im|[Hh]is|[Hh]imself|[Ss]he|[Hh]er|[Hh]ers|[Hh]erself)\b`)
+
+// nameMentionCounts counts each capitalized word of text as a whole word, so
+// "Rose" is not found inside "Rosemary" and "Rose's" is a mention of Rose.
+func nameMentionCounts(text string) map[string]int {
+	counts := map[string]int{}
+	for _, n := range properNamePattern.FindAllString(text, -1) {
+		counts[n]++
+	}
+	return counts
+}
+
+// estimatePronounMentions estimates how many he/him/his and she/her/hers
+// references in text point at each of the actors. Within a paragraph, a
+// sentence naming exactly one actor makes them the subject; the pronouns of
+// one gender in the following sentences that name no one are theirs, until
+// another actor is named or the other gender's pronouns appear. In the
+// naming sentence only possessives and reflexives count ("Mara lifted her
+// hand", not "Mara handed him the key"). A surname right after a first name
+// ("Sarah Cole") is the same reference. It is an estimate: paragraphs are
+// sampled, and a pronoun for a character never named in the paragraph is
+// left unresolved.
+func estimatePronounMentions(text string, actors map[string]bool) map[string]int {
+	paragraphs := chapterParagraphs(text)
+	sampled := paragraphs
+	if len(paragraphs) > pronounSampleParagraphs {
+		sampled = make([]string, 0, pronounSampleParagraphs)
+		for i := 0; i < pronounSampleParagraphs; i++ {
+			sampled = append(sampled, paragraphs[i*len(paragraphs)/pronounSampleParagraphs])
+		}
+	}
+	counts := map[string]int{}
+	for _, paragraph := range sampled {
+		subject, gender := "", ""
+		for _, s := range splitSentences(paragraph) {
+			named := sentenceActors(s, actors)
+			if len(named) > 0 {
+				subject, gender = "", ""
+				if len(named) == 1 {
+					subject = named[0]
+				}
+			}
+			if subject == "" {
+				continue
+			}
+			he, she := 0, 0
+			for _, p := range pronounTokenPattern.FindAllString(s, -1) {
+				p = strings.ToLower(p)
+				if len(named) > 0 && (p == "he" || p == "him" || p == "she") {
+					continue
+				}
+				switch p {
+				case "he", "him", "his", "himself":
+					he++
+				default:
+					she++
+				}
+			}
+			switch {
+			case he > 0 && she > 0:
+				subject = ""
+			case he > 0 && gender != "she":
+				gender = "he"
+				counts[subject] += he
+			case she > 0 && gender != "he":
+				gender = "she"
+				counts[subject] += she
+			case he > 0 || she > 0:
+				subject = ""
+			}
+		}
+	}
+	if len(sampled) < len(paragraphs) {
+		scale := float64(len(paragraphs)) / float64(len(sampled))
+		for name, n := range counts {
+			counts[name] = int(math.Round(float64(n) * scale))
+		}
+	}
+	return counts
+}
+
+// sentenceActors lists the actors a sentence names, counting a run of names
+// ("Sarah Cole") once by its first name.
+func sentenceActors(sentence string, actors map[string]bool) []string {
+	out := []string{}
+	prevEnd := -1
+	for _, loc := range properNamePattern.FindAllStringIndex(sentence, -1) {
+		name := sentence[loc[0]:loc[1]]
+		run := prevEnd >= 0 && strings.TrimSpace(sentence[prevEnd:loc[0]]) == ""
+		prevEnd = -1
+		if !actors[name] || characterTitles[name] {
+			continue
+		}
+		prevEnd = loc[1]
+		if run || containsString(out, name) {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// characterProminence ranks characters by their named mentions plus the
+// estimated pronoun references to them.
+func characterProminence(e CharacterEntry) int {
+	return e.TotalMentions + e.PronounMentions
+}
@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCharacterMentionsUseWholeWordsAndPronouns(t *testing.T) {
+	text := strings.Join([]string{
+		"Rose walked into the garden where Rosemary grew along the wall, and Tom watched from the gate.",
+		"Later that morning Rose knelt by the roses. She pulled a weed and then she pulled another. Her hands were dirty.",
+		"Tom came down the path. He said the tea was ready. She did not answer him.",
+		"Rosemary, Rosemary, everywhere. Rose's basket filled slowly.",
+	}, "\n")
+	dictionary, _, _ := buildCharacterDictionary([]chapter{{index: 1, title: "Garden", text: text}})
+	byName := map[string]CharacterEntry{}
+	for _, c := range dictionary {
+		byName[c.Name] = c
+	}
+	rose, tom := byName["Rose"], byName["Tom"]
+	// Rose, Rose, Rose's: Rosemary is not a mention of Rose.
+	if rose.TotalMentions != 3 || rose.Chapters[0].Mentions != 3 {
+		t.Fatalf("expected 3 whole-word mentions of Rose, got %+v", rose)
+	}
+	// She, she, Her follow "Rose knelt"; He follows "Tom came", and the She
+	// after it ends the chain instead of being given to Tom.
+	if rose.PronounMentions != 3 || tom.PronounMentions != 1 {
+		t.Fatalf("expected Rose ~3 and Tom ~1 pronoun references, got %d and %d", rose.PronounMentions, tom.PronounMentions)
+	}
+	if dictionary[0].Name != "Rose" {
+		t.Fatalf("expected Rose ranked first, got %s", dictionary[0].Name)
+	}
+}
+
+func TestEstimatePronounMentionsSamplesLongChapters(t *testing.T) {
+	paragraphs := make([]string, 0, 3*pronounSampleParagraphs)
+	for i := 0; i < 3*pronounSampleParagraphs; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("Ada opened door %d. She stepped through it.", i))
+	}
+	counts := estimatePronounMentions(strings.Join(paragraphs, "\n"), map[string]bool{"Ada": true})
+	if counts["Ada"] != len(paragraphs) {
+		t.Fatalf("expected the sample scaled to %d references, got %d", len(paragraphs), counts["Ada"])
+	}
+}
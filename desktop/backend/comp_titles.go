@@ -0,0 +1,362 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxCompTitles       = 5
+	compSynopsisWords   = 400
+	defaultEmbedModel   = "nomic-embed-text"
+	compProviderLexical = "lexical"
+)
+
+// defaultCompTitles is shown when no catalog is configured.
+var defaultCompTitles = []CompTitle{
+	{Title: "The Silent Patient", Tier: "Blockbuster"},
+	{Title: "The Maidens", Tier: "Blockbuster"},
+	{Title: "Wrong Place Wrong Time", Tier: "Mid-list"},
+	{Title: "Rock Paper Scissors", Tier: "Mid-list"},
+	{Title: "Unknown", Tier: "Unknown"},
+}
+
+// CompCatalogEntry is one published title an editor wants the manuscript
+// compared against.
+type CompCatalogEntry struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Blurb  string `json:"blurb"`
+	Tier   string `json:"tier"`
+}
+
+// compCatalogPath returns MHD_COMP_CATALOG when set, otherwise the first of
+// configs/comp_catalog.json and configs/comp_catalog.csv that exists.
+func compCatalogPath(workspaceRoot string) string {
+	if path := strings.TrimSpace(os.Getenv("MHD_COMP_CATALOG")); path != "" {
+		return path
+	}
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return ""
+	}
+	for _, name := range []string{"comp_catalog.json", "comp_catalog.csv"} {
+		path := filepath.Join(workspaceRoot, "configs", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadCompCatalog reads the comp catalog, returning its path. No catalog is
+// not an error: the path is empty and the static comp list is used.
+func LoadCompCatalog(workspaceRoot string) ([]CompCatalogEntry, string, error) {
+	path := compCatalogPath(workspaceRoot)
+	if path == "" {
+		return nil, "", nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, path, fmt.Errorf("read comp catalog: %w", err)
+	}
+	var entries []CompCatalogEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = parseCompCatalogCSV(raw)
+	} else if err = json.Unmarshal(raw, &entries); err != nil {
+		err = fmt.Errorf("decode comp catalog: %w", err)
+	}
+	if err != nil {
+		return nil, path, err
+	}
+	out := make([]CompCatalogEntry, 0, len(entries))
+	for _, e := range entries {
+		e.Title, e.Blurb = strings.TrimSpace(e.Title), strings.TrimSpace(e.Blurb)
+		if e.Title != "" && e.Blurb != "" {
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return nil, path, fmt.Errorf("comp catalog has no entries with both title and blurb")
+	}
+	return out, path, nil
+}
+
+// parseCompCatalogCSV expects a header row naming title, blurb and tier
+// columns (author is optional), in any order.
+func parseCompCatalogCSV(raw []byte) ([]CompCatalogEntry, error) {
+	rows, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("decode comp catalog: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("comp catalog is empty")
+	}
+	cols := map[string]int{}
+	for i, name := range rows[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "blurb", "tier"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("comp catalog header is missing %q", required)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	out := make([]CompCatalogEntry, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		out = append(out, CompCatalogEntry{Title: field(row, "title"), Author: field(row, "author"), Blurb: field(row, "blurb"), Tier: field(row, "tier")})
+	}
+	return out, nil
+}
+
+// compSynopsis stands in for a synopsis: the chapter summaries in order.
+func compSynopsis(summaries []ChapterSummary) string {
+	parts := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		if summary := strings.TrimSpace(s.Summary); summary != "" {
+			parts = append(parts, summary)
+		}
+	}
+	return firstWords(strings.Join(parts, " "), compSynopsisWords)
+}
+
+// rankCompTitles scores every catalog blurb against the synopsis by cosine
+// similarity of Ollama embeddings, falling back to TF-IDF vectors when the
+// embedding model is unavailable. It returns the top matches, the provider
+// used and, after a fallback, why.
+func rankCompTitles(synopsis string, catalog []CompCatalogEntry, cacheDir string) ([]CompTitle, string, *FallbackReason) {
+	var fallback *FallbackReason
+	model := embedModel()
+	provider := "ollama:" + model
+	similarities, err := embeddingSimilarities(model, synopsis, catalog, cacheDir)
+	if err != nil {
+		fallback = newFallbackReason(ServiceOllama, model, err)
+		provider = compProviderLexical
+		similarities = lexicalSimilarities(synopsis, catalog)
+	}
+
+	order := make([]int, len(catalog))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return similarities[order[a]] > similarities[order[b]] })
+	out := make([]CompTitle, 0, maxCompTitles)
+	for _, i := range order[:min(maxCompTitles, len(order))] {
+		e := catalog[i]
+		out = append(out, CompTitle{
+			Title:      e.Title,
+			Author:     e.Author,
+			Tier:       e.Tier,
+			Similarity: math.Round(similarities[i]*1000) / 1000,
+			Provider:   provider,
+		})
+	}
+	return out, provider, fallback
+}
+
+func embeddingSimilarities(model, synopsis string, catalog []CompCatalogEntry, cacheDir string) ([]float64, error) {
+	cache := loadEmbeddingCache(cacheDir, model)
+	embed := func(text string) ([]float64, error) {
+		key := embeddingKey(text)
+		if v, ok := cache.vectors[key]; ok {
+			return v, nil
+		}
+		v, err := ollamaEmbedding(model, text)
+		if err != nil {
+			return nil, err
+		}
+		cache.vectors[key] = v
+		cache.dirty = true
+		return v, nil
+	}
+	query, err := embed(synopsis)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(catalog))
+	for i, e := range catalog {
+		v, err := embed(e.Blurb)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = cosine(query, v)
+	}
+	cache.save()
+	return out, nil
+}
+
+// embedModel is OLLAMA_EMBED_MODEL or nomic-embed-text; the chat models used
+// elsewhere do not produce useful embeddings.
+func embedModel() string {
+	if model := strings.TrimSpace(os.Getenv("OLLAMA_EMBED_MODEL")); model != "" {
+		return model
+	}
+	return defaultEmbedModel
+}
+
+func ollamaEmbedEndpoint() string {
+	return strings.TrimSuffix(ollamaGenerateEndpoint(), "/api/generate") + "/api/embeddings"
+}
+
+func ollamaEmbedding(model, text string) ([]float64, error) {
+	raw, _ := json.Marshal(map[string]any{"model": model, "prompt": text})
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(ollamaEmbedEndpoint(), "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, statusError(resp.StatusCode, body)
+	}
+	var out struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embedding) == 0 {
+		return nil, fmt.Errorf("empty embedding")
+	}
+	return out.Embedding, nil
+}
+
+// embeddingCache keeps blurb embeddings in cache/embeddings/<model>.json so a
+// catalog is only embedded once per model.
+type embeddingCache struct {
+	path    string
+	vectors map[string][]float64
+	dirty   bool
+}
+
+func embeddingCacheDir(workspaceRoot string) string {
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return ""
+	}
+	return filepath.Join(workspaceRoot, "cache", "embeddings")
+}
+
+func loadEmbeddingCache(dir, model string) *embeddingCache {
+	c := &embeddingCache{vectors: map[string][]float64{}}
+	if strings.TrimSpace(dir) == "" {
+		return c
+	}
+	c.path = filepath.Join(dir, sanitizeForCacheName(model)+".json")
+	if raw, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(raw, &c.vectors)
+	}
+	return c
+}
+
+// save is best effort; a missing cache only costs another embedding call.
+func (c *embeddingCache) save() {
+	if c.path == "" || !c.dirty {
+		return
+	}
+	if raw, err := json.Marshal(c.vectors); err == nil {
+		_ = os.WriteFile(c.path, raw, 0o644)
+	}
+}
+
+func embeddingKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func sanitizeForCacheName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// lexicalSimilarities compares TF-IDF vectors, with document frequencies taken
+// over the catalog plus the synopsis.
+func lexicalSimilarities(synopsis string, catalog []CompCatalogEntry) []float64 {
+	docs := make([]map[string]float64, 0, len(catalog)+1)
+	df := map[string]float64{}
+	for _, text := range append([]string{synopsis}, catalogBlurbs(catalog)...) {
+		tf := map[string]float64{}
+		for _, w := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+			if len(w) > 2 {
+				tf[w]++
+			}
+		}
+		for w := range tf {
+			df[w]++
+		}
+		docs = append(docs, tf)
+	}
+	n := float64(len(docs))
+	vector := func(tf map[string]float64) map[string]float64 {
+		v := make(map[string]float64, len(tf))
+		for w, count := range tf {
+			v[w] = count * math.Log(1+n/df[w])
+		}
+		return v
+	}
+	query := vector(docs[0])
+	out := make([]float64, len(catalog))
+	for i := range catalog {
+		out[i] = sparseCosine(query, vector(docs[i+1]))
+	}
+	return out
+}
+
+func catalogBlurbs(catalog []CompCatalogEntry) []string {
+	out := make([]string, len(catalog))
+	for i, e := range catalog {
+		out[i] = e.Blurb
+	}
+	return out
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	dot, na, nb := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func sparseCosine(a, b map[string]float64) float64 {
+	dot, na, nb := 0.0, 0.0, 0.0
+	for w, x := range a {
+		dot += x * b[w]
+		na += x * x
+	}
+	for _, y := range b {
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
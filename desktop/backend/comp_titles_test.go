@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCompCatalogCSV(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("MHD_COMP_CATALOG", "")
+	if catalog, path, err := LoadCompCatalog(root); err != nil || path != "" || catalog != nil {
+		t.Fatalf("expected no catalog, got %v %q %v", catalog, path, err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "configs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	csvText := "tier,title,blurb,author\nBlockbuster,Gone Girl,\"A wife vanishes, a husband lies.\",Gillian Flynn\nMid-list,No Blurb,,\n"
+	if err := os.WriteFile(filepath.Join(root, "configs", "comp_catalog.csv"), []byte(csvText), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	catalog, path, err := LoadCompCatalog(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !strings.HasSuffix(path, "comp_catalog.csv") || len(catalog) != 1 {
+		t.Fatalf("expected one usable entry from csv, got %q %+v", path, catalog)
+	}
+	if catalog[0].Author != "Gillian Flynn" || catalog[0].Tier != "Blockbuster" {
+		t.Fatalf("columns mapped wrong: %+v", catalog[0])
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "configs", "comp_catalog.csv"), []byte("title,blurb\nX,Y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadCompCatalog(root); err == nil {
+		t.Fatal("expected missing tier column to fail")
+	}
+}
+
+func TestRankCompTitlesFallsBackToLexical(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	catalog := []CompCatalogEntry{
+		{Title: "Dragon Court", Blurb: "A young mage and a dragon fight for the throne of a magic kingdom.", Tier: "Mid-list"},
+		{Title: "Harbor Lies", Blurb: "A detective investigates a murder at the harbor and a missing witness.", Tier: "Blockbuster"},
+	}
+	ranked, provider, fallback := rankCompTitles("The detective finds the murder witness missing from the harbor.", catalog, "")
+	if provider != compProviderLexical || fallback == nil || fallback.Code != FallbackServiceDown {
+		t.Fatalf("expected lexical fallback with a service_down reason, got %q %+v", provider, fallback)
+	}
+	if len(ranked) != 2 || ranked[0].Title != "Harbor Lies" || ranked[0].Similarity <= ranked[1].Similarity {
+		t.Fatalf("unexpected ranking %+v", ranked)
+	}
+}
+
+func TestRankCompTitlesUsesCachedEmbeddings(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		vector := []float64{0, 1}
+		if strings.Contains(req.Prompt, "harbor") {
+			vector = []float64{1, 0.1}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"embedding": vector})
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_URL", server.URL)
+	t.Setenv("OLLAMA_EMBED_MODEL", "")
+
+	cacheDir := t.TempDir()
+	catalog := []CompCatalogEntry{
+		{Title: "Dragon Court", Blurb: "Dragons and mages.", Tier: "Mid-list"},
+		{Title: "Harbor Lies", Blurb: "Murder at the harbor.", Tier: "Blockbuster"},
+	}
+	ranked, provider, _ := rankCompTitles("A body in the harbor.", catalog, cacheDir)
+	if provider != "ollama:"+defaultEmbedModel || ranked[0].Title != "Harbor Lies" {
+		t.Fatalf("unexpected embedding ranking %q %+v", provider, ranked)
+	}
+	if calls != 3 {
+		t.Fatalf("expected three embedding calls, got %d", calls)
+	}
+	if _, _, _ = rankCompTitles("A body in the harbor.", catalog, cacheDir); calls != 3 {
+		t.Fatalf("expected cached embeddings to be reused, got %d calls", calls)
+	}
+}
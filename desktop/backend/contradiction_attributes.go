@@ -0,0 +1,115 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AttributeExtractor reads one character attribute out of chapter text for
+// the contradiction checks. Users add their own (hair colour, job title, car)
+// in configs/contradiction_attributes.json:
+//
+//	{"attributes": [{"attribute": "hair", "severity": "MED",
+//	  "pattern": "(?P<name>[A-Z][a-z]+)'s (?P<value>red|blond|black) hair"}]}
+//
+// Pattern captures the character in its "name" group and the value in its
+// "value" group, or, without those names, in its first two groups.
+type AttributeExtractor struct {
+	Attribute string `json:"attribute"`
+	Pattern   string `json:"pattern"`
+	// Severity is HIGH, MED or LOW (the default) for a custom attribute;
+	// built-in attributes keep the contradiction check's own severities.
+	Severity string `json:"severity,omitempty"`
+
+	re *regexp.Regexp
+	// clean normalizes a captured value; false drops the statement.
+	clean func(string) (string, bool)
+}
+
+type attributeExtractorFile struct {
+	Attributes []AttributeExtractor `json:"attributes"`
+}
+
+var validSeverities = wordSet("HIGH", "MED", "LOW")
+
+// LoadAttributeExtractors reads the custom attributes in
+// configs/contradiction_attributes.json. No file means none; a file with an
+// invalid entry is rejected whole, so a typo cannot silently drop a check.
+func LoadAttributeExtractors(workspaceRoot string) ([]AttributeExtractor, error) {
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(filepath.Join(workspaceRoot, "configs", "contradiction_attributes.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read contradiction attributes: %w", err)
+	}
+	var file attributeExtractorFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode contradiction attributes: %w", err)
+	}
+	out := make([]AttributeExtractor, 0, len(file.Attributes))
+	for i, e := range file.Attributes {
+		e.Attribute = strings.TrimSpace(e.Attribute)
+		if e.Attribute == "" {
+			return nil, fmt.Errorf("contradiction attribute %d has no name", i+1)
+		}
+		e.Severity = strings.ToUpper(strings.TrimSpace(e.Severity))
+		if e.Severity == "" {
+			e.Severity = "LOW"
+		}
+		if !validSeverities[e.Severity] {
+			return nil, fmt.Errorf("contradiction attribute %q: severity %q is not HIGH, MED or LOW", e.Attribute, e.Severity)
+		}
+		re, compileErr := regexp.Compile(e.Pattern)
+		if compileErr != nil {
+			return nil, fmt.Errorf("contradiction attribute %q: %w", e.Attribute, compileErr)
+		}
+		e.re = re
+		if e.re.NumSubexp() < 2 {
+			return nil, fmt.Errorf("contradiction attribute %q: pattern needs groups for the name and the value", e.Attribute)
+		}
+		e.clean = trimmedValue
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// key is the attribute as the contradiction check files it.
+func (e AttributeExtractor) key() string {
+	return strings.ToLower(e.Attribute)
+}
+
+func (e AttributeExtractor) nameGroup() int {
+	if i := e.re.SubexpIndex("name"); i > 0 {
+		return i
+	}
+	return 1
+}
+
+func (e AttributeExtractor) valueGroup() int {
+	if i := e.re.SubexpIndex("value"); i > 0 {
+		return i
+	}
+	return 2
+}
+
+func (e AttributeExtractor) cleanValue(v string) (string, bool) {
+	if e.clean == nil {
+		return v, true
+	}
+	return e.clean(v)
+}
+
+// trimmedValue keeps a custom value as written; the contradiction check
+// already ignores case.
+func trimmedValue(v string) (string, bool) {
+	v = strings.Join(strings.Fields(v), " ")
+	return v, v != ""
+}
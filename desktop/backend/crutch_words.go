@@ -0,0 +1,278 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"book_dashboard/internal/novelty"
+)
+
+const (
+	// A word is a crutch when the manuscript uses it crutchMinRatio times as
+	// often as the baseline and at least crutchMinCount times.
+	crutchMinRatio = 3.0
+	crutchMinCount = 5
+	// crutchUnlistedRate is the baseline, per million words, of a word the
+	// baseline does not list.
+	crutchUnlistedRate = 10.0
+	// Phrases of crutchPhraseMinWords to crutchPhraseMaxWords words repeated
+	// at least crutchMinPhraseCount times, with two or more content words,
+	// are reported.
+	crutchPhraseMinWords   = 3
+	crutchPhraseMaxWords   = 5
+	crutchMinPhraseCount   = 3
+	crutchTopWords         = 15
+	crutchTopPhrases       = 10
+	embeddedCrutchBaseline = "embedded fiction word list"
+)
+
+// CrutchWordReport lists the content words the manuscript leans on far more
+// than a baseline of published fiction, and its repeated distinctive
+// phrases, each with the chapters it appears in. Rates are per 10,000 words.
+type CrutchWordReport struct {
+	// Baseline names the reference the word rates are compared with: the
+	// installed novelty model, or the embedded word list.
+	Baseline string       `json:"baseline"`
+	Words    []CrutchTerm `json:"words"`
+	Phrases  []CrutchTerm `json:"phrases"`
+}
+
+// CrutchTerm is one overused word or phrase. Phrases carry a baseline only
+// when the novelty model has their trigram.
+type CrutchTerm struct {
+	Term                   string             `json:"term"`
+	Count                  int                `json:"count"`
+	PerTenThousand         float64            `json:"perTenThousand"`
+	BaselinePerTenThousand float64            `json:"baselinePerTenThousand,omitempty"`
+	Ratio                  float64            `json:"ratio,omitempty"`
+	Chapters               []TermChapterCount `json:"chapters"`
+}
+
+type TermChapterCount struct {
+	Chapter int    `json:"chapter"`
+	Title   string `json:"title"`
+	Count   int    `json:"count"`
+}
+
+func emptyCrutchWordReport() CrutchWordReport {
+	return CrutchWordReport{Words: []CrutchTerm{}, Phrases: []CrutchTerm{}}
+}
+
+// analyzeCrutchWords compares each content word's rate with model's, or with
+// the embedded baseline when model is nil, and collects repeated phrases.
+// Function words, pronouns, character names and words mostly capitalized
+// mid-sentence (other proper nouns) are left out.
+func analyzeCrutchWords(chapters []chapter, model *novelty.Model, characters []CharacterEntry) CrutchWordReport {
+	report := emptyCrutchWordReport()
+	report.Baseline = embeddedCrutchBaseline
+	baselineRate := func(term string) float64 { return novelty.BaselineWordRates()[term] }
+	if model != nil {
+		report.Baseline = model.Name
+		baselineRate = model.Rate
+	}
+	names := map[string]bool{}
+	for _, c := range characters {
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			for _, w := range wordPattern.FindAllString(strings.ToLower(name), -1) {
+				names[w] = true
+			}
+		}
+	}
+
+	total := 0
+	counts, midSentence, capitalized := map[string]int{}, map[string]int{}, map[string]int{}
+	phrases := map[string]int{}
+	for _, ch := range chapters {
+		for _, sentence := range crutchSentences(ch.text) {
+			tokens := make([]string, len(sentence))
+			for i, w := range sentence {
+				lower := strings.ToLower(w)
+				tokens[i] = lower
+				total++
+				counts[lower]++
+				if i > 0 {
+					midSentence[lower]++
+					if unicode.IsUpper([]rune(w)[0]) {
+						capitalized[lower]++
+					}
+				}
+			}
+			forEachPhrase(tokens, func(p string) { phrases[p]++ })
+		}
+	}
+	if total == 0 {
+		return report
+	}
+	perTenThousand := func(n int) float64 { return round2(float64(n) * 1e4 / float64(total)) }
+
+	for w, n := range counts {
+		if n < crutchMinCount || len(w) < 3 || statsFunctionWords[w] || statsPronouns[w] || names[w] || capitalized[w]*2 > midSentence[w] {
+			continue
+		}
+		base := baselineRate(w)
+		if base == 0 {
+			base = crutchUnlistedRate
+		}
+		rate := float64(n) * 1e6 / float64(total)
+		if rate < base*crutchMinRatio {
+			continue
+		}
+		report.Words = append(report.Words, CrutchTerm{Term: w, Count: n, PerTenThousand: perTenThousand(n), BaselinePerTenThousand: round2(base / 100), Ratio: round1(rate / base)})
+	}
+	// Rank by the uses beyond what the baseline predicts, so a common word
+	// used a little too often can outrank a rare one used a few times.
+	excess := func(t CrutchTerm) float64 {
+		return float64(t.Count) - t.BaselinePerTenThousand*float64(total)/1e4
+	}
+	sort.Slice(report.Words, func(i, j int) bool {
+		if a, b := excess(report.Words[i]), excess(report.Words[j]); a != b {
+			return a > b
+		}
+		return report.Words[i].Term < report.Words[j].Term
+	})
+	report.Words = report.Words[:min(len(report.Words), crutchTopWords)]
+
+	report.Phrases = distinctivePhrases(phrases, names, model, total)
+	for i := range report.Phrases {
+		report.Phrases[i].PerTenThousand = perTenThousand(report.Phrases[i].Count)
+	}
+	locateCrutchTerms(chapters, report.Words, report.Phrases)
+	return report
+}
+
+// distinctivePhrases keeps the phrases repeated often enough that have two
+// content words, dropping those the model's reference text uses as often and
+// those that only occur inside a longer reported phrase.
+func distinctivePhrases(counts map[string]int, names map[string]bool, model *novelty.Model, total int) []CrutchTerm {
+	candidates := []CrutchTerm{}
+	for p, n := range counts {
+		if n < crutchMinPhraseCount {
+			continue
+		}
+		content := 0
+		for _, w := range strings.Fields(p) {
+			if !statsFunctionWords[w] && !statsPronouns[w] && !names[w] {
+				content++
+			}
+		}
+		if content < 2 {
+			continue
+		}
+		term := CrutchTerm{Term: p, Count: n}
+		if model != nil && strings.Count(p, " ") == crutchPhraseMinWords-1 {
+			if base := model.Rate(p); base > 0 {
+				rate := float64(n) * 1e6 / float64(total)
+				if rate < base*crutchMinRatio {
+					continue
+				}
+				term.BaselinePerTenThousand = round2(base / 100)
+				term.Ratio = round1(rate / base)
+			}
+		}
+		candidates = append(candidates, term)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if la, lb := len(strings.Fields(a.Term)), len(strings.Fields(b.Term)); la != lb {
+			return la > lb
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Term < b.Term
+	})
+	kept := []CrutchTerm{}
+	for _, c := range candidates {
+		inside := false
+		for _, k := range kept {
+			if k.Count >= c.Count && strings.Contains(" "+k.Term+" ", " "+c.Term+" ") {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			kept = append(kept, c)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Count > kept[j].Count })
+	return kept[:min(len(kept), crutchTopPhrases)]
+}
+
+// locateCrutchTerms fills in the chapters each word and phrase appears in.
+func locateCrutchTerms(chapters []chapter, words, phrases []CrutchTerm) {
+	wordIndex, phraseIndex := map[string]int{}, map[string]int{}
+	for i, t := range words {
+		wordIndex[t.Term] = i
+		words[i].Chapters = []TermChapterCount{}
+	}
+	for i, t := range phrases {
+		phraseIndex[t.Term] = i
+		phrases[i].Chapters = []TermChapterCount{}
+	}
+	for _, ch := range chapters {
+		wordCounts, phraseCounts := map[int]int{}, map[int]int{}
+		for _, sentence := range crutchSentences(ch.text) {
+			tokens := make([]string, len(sentence))
+			for i, w := range sentence {
+				tokens[i] = strings.ToLower(w)
+				if j, ok := wordIndex[tokens[i]]; ok {
+					wordCounts[j]++
+				}
+			}
+			forEachPhrase(tokens, func(p string) {
+				if j, ok := phraseIndex[p]; ok {
+					phraseCounts[j]++
+				}
+			})
+		}
+		for j, n := range wordCounts {
+			words[j].Chapters = append(words[j].Chapters, TermChapterCount{Chapter: ch.index, Title: ch.title, Count: n})
+		}
+		for j, n := range phraseCounts {
+			phrases[j].Chapters = append(phrases[j].Chapters, TermChapterCount{Chapter: ch.index, Title: ch.title, Count: n})
+		}
+	}
+}
+
+// crutchSentences splits a chapter into sentences of words, trimming the
+// quote apostrophes wordPattern keeps ('Come in' reads as come, in).
+func crutchSentences(text string) [][]string {
+	out := [][]string{}
+	for _, paragraph := range chapterParagraphs(text) {
+		for _, s := range splitSentences(paragraph) {
+			words := []string{}
+			for _, w := range wordPattern.FindAllString(s, -1) {
+				if w = strings.Trim(w, "'"); w != "" {
+					words = append(words, w)
+				}
+			}
+			if len(words) > 0 {
+				out = append(out, words)
+			}
+		}
+	}
+	return out
+}
+
+func forEachPhrase(tokens []string, fn func(string)) {
+	for n := crutchPhraseMinWords; n <= crutchPhraseMaxWords; n++ {
+		for i := 0; i+n <= len(tokens); i++ {
+			fn(strings.Join(tokens[i:i+n], " "))
+		}
+	}
+}
+
+// describeCrutchTerm is the one-line form the log and plain report use.
+func describeCrutchTerm(t CrutchTerm) string {
+	where := make([]string, 0, len(t.Chapters))
+	for _, c := range t.Chapters {
+		where = append(where, fmt.Sprintf("ch %d ×%d", c.Chapter, c.Count))
+	}
+	line := fmt.Sprintf("%q ×%d (%.1f per 10k words", t.Term, t.Count, t.PerTenThousand)
+	if t.Ratio > 0 {
+		line += fmt.Sprintf(", %.1f× the baseline", t.Ratio)
+	}
+	return line + "): " + strings.Join(where, ", ")
+}
@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeCrutchWordsFindsOverusedWordsAndPhrases(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "One", text: "Mara just smirked at the door. She just waited with a wry little smile. The rain fell on Harrow Street."},
+		{index: 2, title: "Two", text: "Then Mara smirked again, a wry little smile. It was just a game. He walked down Harrow Street and smirked."},
+		{index: 3, title: "Three", text: "They smirked. The car waited by the house. A wry little smile, she just left. Nobody smirked on Harrow Street."},
+	}
+	characters := []CharacterEntry{{Name: "Mara"}}
+	report := analyzeCrutchWords(chapters, nil, characters)
+	if report.Baseline != embeddedCrutchBaseline {
+		t.Fatalf("expected the embedded baseline, got %q", report.Baseline)
+	}
+	words := map[string]CrutchTerm{}
+	for _, w := range report.Words {
+		words[w.Term] = w
+	}
+	smirked, ok := words["smirked"]
+	if !ok || smirked.Count != 5 || smirked.Ratio < crutchMinRatio {
+		t.Fatalf("expected smirked reported as a crutch, got %+v", report.Words)
+	}
+	if got := smirked.Chapters; len(got) != 3 || got[0].Chapter != 1 || got[0].Count != 1 || got[2].Count != 2 {
+		t.Fatalf("unexpected smirked locations %+v", got)
+	}
+	for _, skip := range []string{"mara", "harrow", "street", "the", "she"} {
+		if _, ok := words[skip]; ok {
+			t.Fatalf("expected %q left out, got %+v", skip, report.Words)
+		}
+	}
+	if len(report.Phrases) == 0 || report.Phrases[0].Term != "a wry little smile" || report.Phrases[0].Count != 3 {
+		t.Fatalf("expected the repeated phrase reported whole, got %+v", report.Phrases)
+	}
+	for _, p := range report.Phrases {
+		if strings.Contains("a wry little smile", p.Term) && p.Term != "a wry little smile" {
+			t.Fatalf("expected sub-phrases folded into the longer phrase, got %+v", report.Phrases)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// English variants LanguageTool checks. Auto picks en-US or en-GB from the
+// manuscript's own spellings; the other variants must be set explicitly.
+const (
+	DialectAuto = "auto"
+	DialectUS   = "en-US"
+	DialectGB   = "en-GB"
+)
+
+var supportedDialects = map[string]string{
+	"en-us": DialectUS,
+	"en-gb": DialectGB,
+	"en-au": "en-AU",
+	"en-ca": "en-CA",
+	"en-nz": "en-NZ",
+	"en-za": "en-ZA",
+}
+
+// Paired US/UK spellings used to detect the dialect. Only words common in
+// fiction are listed so a few hits are enough to decide.
+var dialectPairs = map[string]string{
+	"color": "colour", "colors": "colours", "colored": "coloured",
+	"favorite": "favourite", "honor": "honour", "honored": "honoured",
+	"neighbor": "neighbour", "neighbors": "neighbours", "behavior": "behaviour",
+	"humor": "humour", "harbor": "harbour", "armor": "armour", "odor": "odour",
+	"gray": "grey", "center": "centre", "theater": "theatre",
+	"realize": "realise", "realized": "realised", "recognize": "recognise",
+	"recognized": "recognised", "apologize": "apologise", "organize": "organise",
+	"traveled": "travelled", "traveling": "travelling", "canceled": "cancelled",
+	"jewelry": "jewellery", "pajamas": "pyjamas", "plow": "plough",
+	"defense": "defence", "offense": "offence", "skeptical": "sceptical", "aluminum": "aluminium",
+}
+
+// dialectMinHits is how many more UK than US spellings are needed before
+// auto-detection switches away from en-US.
+const dialectMinHits = 3
+
+// detectDialect counts US and UK spellings from dialectPairs.
+func detectDialect(text string) (string, int, int) {
+	ukWords := make(map[string]struct{}, len(dialectPairs))
+	for _, uk := range dialectPairs {
+		ukWords[uk] = struct{}{}
+	}
+	us, uk := 0, 0
+	for _, w := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if _, ok := dialectPairs[w]; ok {
+			us++
+		} else if _, ok := ukWords[w]; ok {
+			uk++
+		}
+	}
+	if uk-us >= dialectMinHits {
+		return DialectGB, us, uk
+	}
+	return DialectUS, us, uk
+}
+
+// resolveDialect applies the project setting, falling back to detection for
+// "auto", empty or unrecognized values. The second value describes where the
+// dialect came from for logs and notes.
+func resolveDialect(setting, text string) (string, string) {
+	key := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(setting, "_", "-")))
+	if dialect, ok := supportedDialects[key]; ok {
+		return dialect, "project setting"
+	}
+	dialect, us, uk := detectDialect(text)
+	source := fmt.Sprintf("detected (us_spellings=%d uk_spellings=%d)", us, uk)
+	if key != "" && key != DialectAuto {
+		source = fmt.Sprintf("unsupported setting %q; %s", setting, source)
+	}
+	return dialect, source
+}
@@ -0,0 +1,27 @@
+package backend
+
+import "testing"
+
+func TestResolveDialect(t *testing.T) {
+	uk := "The grey harbour smelled of tar. She realised the colour had faded, and her neighbour travelled on."
+	if got, _ := resolveDialect("", uk); got != DialectGB {
+		t.Fatalf("expected UK spellings to select %s, got %s", DialectGB, got)
+	}
+	if got, _ := resolveDialect("auto", "The gray harbor. She realized the color had faded."); got != DialectUS {
+		t.Fatalf("expected US spellings to select %s, got %s", DialectUS, got)
+	}
+	if got, source := resolveDialect("en_au", uk); got != "en-AU" || source != "project setting" {
+		t.Fatalf("expected explicit setting to win, got %s (%s)", got, source)
+	}
+	if got, _ := resolveDialect("klingon", uk); got != DialectGB {
+		t.Fatalf("expected unsupported setting to fall back to detection, got %s", got)
+	}
+}
+
+func TestPer1kScoreIgnoresManuscriptLength(t *testing.T) {
+	short := per1kScore(per1k(20, 4000), grammarPointsPer1k)
+	long := per1kScore(per1k(600, 120000), grammarPointsPer1k)
+	if short != long || short != 93 {
+		t.Fatalf("expected equal density to score equally (93), got short=%d long=%d", short, long)
+	}
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// How grammar matches inside quoted dialogue count toward the grammar score.
+// Fragments and nonstandard grammar in speech are usually deliberate.
+const (
+	DialogueGrammarInclude    = "include"
+	DialogueGrammarDownweight = "downweight"
+	DialogueGrammarExclude    = "exclude"
+)
+
+// dialogueMatchWeight is what a grammar match inside dialogue counts for in
+// downweight mode.
+const dialogueMatchWeight = 0.25
+
+// normalizeDialogueGrammar maps the project setting onto a mode; anything
+// unrecognized keeps the default of counting dialogue like narration.
+func normalizeDialogueGrammar(setting string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(setting)); mode {
+	case DialogueGrammarDownweight, DialogueGrammarExclude:
+		return mode
+	}
+	return DialogueGrammarInclude
+}
+
+// dialogueWeight is the score weight of a non-spelling match under mode.
+func dialogueWeight(mode string) float64 {
+	switch mode {
+	case DialogueGrammarDownweight:
+		return dialogueMatchWeight
+	case DialogueGrammarExclude:
+		return 0
+	}
+	return 1
+}
+
+type textSpan struct {
+	start int
+	end   int
+}
+
+// dialogueSpans returns quoted-speech spans in UTF-16 code units, the unit
+// LanguageTool uses for match offsets. A quote left open at the end of a
+// paragraph is closed there, matching the convention for speech that runs
+// across paragraphs.
+func dialogueSpans(text string) []textSpan {
+	spans := []textSpan{}
+	pos, start := 0, -1
+	for _, r := range text {
+		switch {
+		case r == '"' && start < 0, r == '“':
+			start = pos
+		case (r == '"' || r == '”') && start >= 0:
+			spans = append(spans, textSpan{start: start, end: pos + 1})
+			start = -1
+		case r == '\n' && start >= 0:
+			spans = append(spans, textSpan{start: start, end: pos})
+			start = -1
+		}
+		pos += utf16.RuneLen(r)
+	}
+	if start >= 0 {
+		spans = append(spans, textSpan{start: start, end: pos})
+	}
+	return spans
+}
+
+func inDialogue(spans []textSpan, offset int) bool {
+	for _, s := range spans {
+		if offset >= s.start && offset < s.end {
+			return true
+		}
+	}
+	return false
+}
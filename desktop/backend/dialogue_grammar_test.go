@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDialogueSpansUseUTF16Offsets(t *testing.T) {
+	// "—" is one UTF-16 unit but three bytes; "😀" is two units.
+	text := "He paused—😀 “Ain't nobody coming,” she said.\n\"Open quote runs on\nNext."
+	spans := dialogueSpans(text)
+	if len(spans) != 2 {
+		t.Fatalf("expected two dialogue spans, got %+v", spans)
+	}
+	if spans[0].start != 13 || spans[0].end != 35 {
+		t.Fatalf("unexpected curly-quote span %+v", spans[0])
+	}
+	if inDialogue(spans, 0) || !inDialogue(spans, 14) || !inDialogue(spans, spans[1].start+1) {
+		t.Fatalf("offset classification wrong for spans %+v", spans)
+	}
+}
+
+func TestAnalyzeWithLanguageToolDialogueModes(t *testing.T) {
+	text := `"Ain't nobody going," he said. Him went home.` + strings.Repeat(" The rain kept falling.", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"matches":[
+			{"offset":1,"rule":{"id":"AINT_RULE","description":"Nonstandard","category":{"id":"GRAMMAR"}}},
+			{"offset":31,"rule":{"id":"PRP_VBD","description":"Pronoun case","category":{"id":"GRAMMAR"}}}
+		]}`))
+	}))
+	defer server.Close()
+	t.Setenv("LANGUAGETOOL_URL", server.URL)
+
+	scores := map[string]int{}
+	for _, mode := range []string{"", DialogueGrammarDownweight, DialogueGrammarExclude} {
+		report, err := analyzeWithLanguageTool([]chapter{{index: 1, title: "One", text: text}}, languageToolOptions{dialect: DialectUS, dialogueGrammar: mode})
+		if err != nil {
+			t.Fatalf("analyze %q: %v", mode, err)
+		}
+		if report.DialogueMatches != 1 {
+			t.Fatalf("mode %q: expected one dialogue match, got %d", mode, report.DialogueMatches)
+		}
+		scores[report.DialogueGrammar] = report.GrammarScore
+	}
+	if !(scores[DialogueGrammarInclude] < scores[DialogueGrammarDownweight] && scores[DialogueGrammarDownweight] < scores[DialogueGrammarExclude]) {
+		t.Fatalf("expected dialogue matches to weigh less in each mode, got %+v", scores)
+	}
+}
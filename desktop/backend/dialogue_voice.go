@@ -0,0 +1,294 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// minVoiceWords is how much attributed dialogue a character needs before
+	// their voice is profiled; each early/late half needs half of it.
+	minVoiceWords = 200
+	// A character drifts when their early and late dialogue are further apart
+	// than voiceDriftRatio times the average distance between characters, or
+	// when their late dialogue is closer to another character than to their
+	// own early lines.
+	voiceDriftRatio = 1.0
+	// Voices are indistinct when characters are, on average, no further apart
+	// than voiceSamenessRatio times a character is from their own later self.
+	voiceSamenessRatio = 1.15
+)
+
+var quotedLinePattern = regexp.MustCompile(`["“]([^"“”\n]{2,})["”]`)
+var tagAfterPattern = regexp.MustCompile(`^\s*,?\s*(?:([A-Z][a-z]{2,})\s+` + speechVerbAlternation + `|` + speechVerbAlternation + `\s+([A-Z][a-z]{2,}))\b`)
+var tagBeforePattern = regexp.MustCompile(`\b([A-Z][a-z]{2,})\s+` + speechVerbAlternation + `\s*[,:]\s*$`)
+
+// voiceFunctionWords are frequent words whose rates carry an author's or
+// character's style independent of topic (the basis of Burrows' Delta).
+var voiceFunctionWords = []string{
+	"the", "a", "and", "to", "of", "i", "you", "it", "that", "in", "is", "was", "we", "me", "my", "not", "what",
+	"but", "for", "on", "this", "be", "with", "have", "just", "so", "do", "no", "all", "there", "if", "your",
+	"can", "like", "well", "oh", "yes", "know", "now", "here", "why", "how", "at", "they", "he", "she",
+}
+
+// VoiceReport compares characters' attributed dialogue: how far each
+// character's late lines drift from their early ones, and whether distinct
+// characters sound distinct at all.
+type VoiceReport struct {
+	Characters      []CharacterVoice `json:"characters"`
+	MeanBetween     float64          `json:"meanBetween"`
+	MeanDrift       float64          `json:"meanDrift"`
+	Indistinct      bool             `json:"indistinct"`
+	AttributedLines int              `json:"attributedLines"`
+	Flags           []string         `json:"flags"`
+}
+
+type CharacterVoice struct {
+	Name             string  `json:"name"`
+	Lines            int     `json:"lines"`
+	Words            int     `json:"words"`
+	FirstChapter     int     `json:"firstChapter"`
+	LastChapter      int     `json:"lastChapter"`
+	Drift            float64 `json:"drift"`
+	Drifting         bool    `json:"drifting"`
+	DriftToward      string  `json:"driftToward"`
+	NearestCharacter string  `json:"nearestCharacter"`
+	NearestDistance  float64 `json:"nearestDistance"`
+}
+
+type attributedLine struct {
+	chapter int
+	text    string
+}
+
+func emptyVoiceReport() VoiceReport {
+	return VoiceReport{Characters: []CharacterVoice{}, Flags: []string{}}
+}
+
+// attributeDialogue collects quoted lines whose speech tag ("Mara said",
+// "said Mara", or "Mara said:" before the quote) names a known character.
+func attributeDialogue(chapters []chapter, characters []CharacterEntry) map[string][]attributedLine {
+	known := make(map[string]bool, len(characters))
+	for _, c := range characters {
+		known[c.Name] = true
+	}
+	out := map[string][]attributedLine{}
+	for _, ch := range chapters {
+		for _, loc := range quotedLinePattern.FindAllStringSubmatchIndex(ch.text, -1) {
+			line := ch.text[loc[2]:loc[3]]
+			after := ch.text[loc[1]:min(len(ch.text), loc[1]+60)]
+			speaker := ""
+			if m := tagAfterPattern.FindStringSubmatch(after); m != nil {
+				speaker = m[1]
+				if speaker == "" {
+					speaker = m[4]
+				}
+			} else if m := tagBeforePattern.FindStringSubmatch(ch.text[max(0, loc[0]-60):loc[0]]); m != nil {
+				speaker = m[1]
+			}
+			if known[speaker] {
+				out[speaker] = append(out[speaker], attributedLine{chapter: ch.index, text: line})
+			}
+		}
+	}
+	return out
+}
+
+// voiceFeatures profiles a dialogue corpus: function-word rates plus words per
+// line, word length, contractions, questions and exclamations.
+func voiceFeatures(lines []attributedLine) ([]float64, int) {
+	counts := map[string]int{}
+	words, letters, contractions, questions, exclaims := 0, 0, 0, 0, 0
+	for _, l := range lines {
+		for _, w := range wordPattern.FindAllString(strings.ToLower(l.text), -1) {
+			counts[w]++
+			words++
+			letters += len(w)
+			if strings.Contains(w, "'") {
+				contractions++
+			}
+		}
+		questions += strings.Count(l.text, "?")
+		exclaims += strings.Count(l.text, "!")
+	}
+	if words == 0 {
+		return nil, 0
+	}
+	n := float64(words)
+	out := make([]float64, 0, len(voiceFunctionWords)+5)
+	for _, w := range voiceFunctionWords {
+		out = append(out, float64(counts[w])/n)
+	}
+	out = append(out,
+		n/float64(len(lines)),
+		float64(letters)/n,
+		float64(contractions)/n,
+		float64(questions)/float64(len(lines)),
+		float64(exclaims)/float64(len(lines)),
+	)
+	return out, words
+}
+
+// splitByWords divides lines in order into halves of roughly equal words.
+func splitByWords(lines []attributedLine) ([]attributedLine, []attributedLine) {
+	total := 0
+	for _, l := range lines {
+		total += len(strings.Fields(l.text))
+	}
+	running := 0
+	for i, l := range lines {
+		running += len(strings.Fields(l.text))
+		if running*2 >= total {
+			return lines[:i+1], lines[i+1:]
+		}
+	}
+	return lines, nil
+}
+
+// voiceDelta is Burrows' Delta: the mean absolute difference of z-scored
+// features, with means and deviations taken over every profiled sample.
+func voiceDelta(a, b, mean, sd []float64) float64 {
+	total, used := 0.0, 0
+	for i := range a {
+		if sd[i] == 0 {
+			continue
+		}
+		total += math.Abs((a[i]-mean[i])/sd[i] - (b[i]-mean[i])/sd[i])
+		used++
+	}
+	if used == 0 {
+		return 0
+	}
+	return total / float64(used)
+}
+
+func analyzeDialogueVoices(chapters []chapter, characters []CharacterEntry) VoiceReport {
+	report := emptyVoiceReport()
+	type profile struct {
+		name               string
+		lines              []attributedLine
+		words              int
+		whole, early, late []float64
+	}
+	profiles := []profile{}
+	for name, lines := range attributeDialogue(chapters, characters) {
+		report.AttributedLines += len(lines)
+		whole, words := voiceFeatures(lines)
+		if words < minVoiceWords {
+			continue
+		}
+		earlyLines, lateLines := splitByWords(lines)
+		early, earlyWords := voiceFeatures(earlyLines)
+		late, lateWords := voiceFeatures(lateLines)
+		if earlyWords < minVoiceWords/2 || lateWords < minVoiceWords/2 {
+			continue
+		}
+		profiles = append(profiles, profile{name: name, lines: lines, words: words, whole: whole, early: early, late: late})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].words != profiles[j].words {
+			return profiles[i].words > profiles[j].words
+		}
+		return profiles[i].name < profiles[j].name
+	})
+	if len(profiles) < 2 {
+		return report
+	}
+
+	samples := make([][]float64, 0, len(profiles)*3)
+	for _, p := range profiles {
+		samples = append(samples, p.whole, p.early, p.late)
+	}
+	mean, sd := featureMoments(samples)
+
+	between, pairs := 0.0, 0
+	nearest := make([]float64, len(profiles))
+	nearestName := make([]string, len(profiles))
+	for i := range profiles {
+		nearest[i] = math.Inf(1)
+	}
+	for i := range profiles {
+		for j := i + 1; j < len(profiles); j++ {
+			d := voiceDelta(profiles[i].whole, profiles[j].whole, mean, sd)
+			between += d
+			pairs++
+			if d < nearest[i] {
+				nearest[i], nearestName[i] = d, profiles[j].name
+			}
+			if d < nearest[j] {
+				nearest[j], nearestName[j] = d, profiles[i].name
+			}
+		}
+	}
+	report.MeanBetween = round2(between / float64(pairs))
+
+	driftTotal := 0.0
+	for i, p := range profiles {
+		drift := voiceDelta(p.early, p.late, mean, sd)
+		driftTotal += drift
+		toward, towardDistance := "", drift
+		for j, other := range profiles {
+			if d := voiceDelta(p.late, other.whole, mean, sd); j != i && d < towardDistance {
+				toward, towardDistance = other.name, d
+			}
+		}
+		report.Characters = append(report.Characters, CharacterVoice{
+			Name:             p.name,
+			Lines:            len(p.lines),
+			Words:            p.words,
+			FirstChapter:     p.lines[0].chapter,
+			LastChapter:      p.lines[len(p.lines)-1].chapter,
+			Drift:            round2(drift),
+			Drifting:         toward != "" || drift > voiceDriftRatio*report.MeanBetween,
+			DriftToward:      toward,
+			NearestCharacter: nearestName[i],
+			NearestDistance:  round2(nearest[i]),
+		})
+	}
+	report.MeanDrift = round2(driftTotal / float64(len(profiles)))
+	report.Indistinct = report.MeanBetween <= voiceSamenessRatio*report.MeanDrift
+
+	if report.Indistinct {
+		report.Flags = append(report.Flags, fmt.Sprintf("Everyone sounds the same: characters' dialogue differs by %.2f on average, about as much as one character's early and late lines (%.2f).", report.MeanBetween, report.MeanDrift))
+	}
+	for _, c := range report.Characters {
+		switch {
+		case !c.Drifting || report.Indistinct:
+		case c.DriftToward != "":
+			report.Flags = append(report.Flags, fmt.Sprintf("Voice drift: %s's later dialogue (through chapter %d) sounds more like %s than like %s's early lines.", c.Name, c.LastChapter, c.DriftToward, c.Name))
+		default:
+			report.Flags = append(report.Flags, fmt.Sprintf("Voice drift: %s's later dialogue (through chapter %d) moves %.2f from their early lines, more than characters differ from each other (%.2f).", c.Name, c.LastChapter, c.Drift, report.MeanBetween))
+		}
+	}
+	return report
+}
+
+func featureMoments(samples [][]float64) ([]float64, []float64) {
+	dims := len(samples[0])
+	mean := make([]float64, dims)
+	sd := make([]float64, dims)
+	for _, s := range samples {
+		for i, v := range s {
+			mean[i] += v
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(samples))
+	}
+	for _, s := range samples {
+		for i, v := range s {
+			sd[i] += (v - mean[i]) * (v - mean[i])
+		}
+	}
+	for i := range sd {
+		sd[i] = math.Sqrt(sd[i] / float64(len(samples)))
+	}
+	return mean, sd
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	terseLine  = "Don't. Why'd you come? Who's asking? Get out!"
+	formalLine = "I must confess that the matter of the inheritance has been weighing upon me for the better part of the season, and I cannot in good conscience ignore it."
+	plainLine  = "We should go back to the house now and talk to them about it before it gets dark."
+)
+
+// voiceChapters builds chapters where each speaker's lines come from the
+// style function for the given chapter number.
+func voiceChapters(count int, style func(speaker string, chapter int) string) []chapter {
+	chapters := make([]chapter, 0, count)
+	for i := 1; i <= count; i++ {
+		var b strings.Builder
+		for j := 0; j < 6; j++ {
+			fmt.Fprintf(&b, "\"%s\" Mara said. The rain kept on. ", style("Mara", i))
+			fmt.Fprintf(&b, "Tomas said, \"%s\" He looked away.\n", style("Tomas", i))
+			fmt.Fprintf(&b, "\"%s\" said Ines.\n", style("Ines", i))
+		}
+		chapters = append(chapters, chapter{index: i, title: fmt.Sprintf("Chapter %d", i), text: b.String()})
+	}
+	return chapters
+}
+
+var voiceCast = []CharacterEntry{{Name: "Mara"}, {Name: "Tomas"}, {Name: "Ines"}}
+
+func TestAttributeDialogueTagForms(t *testing.T) {
+	lines := attributeDialogue(voiceChapters(1, func(string, int) string { return plainLine }), voiceCast)
+	for _, name := range []string{"Mara", "Tomas", "Ines"} {
+		if len(lines[name]) != 6 {
+			t.Fatalf("expected six lines for %s, got %d", name, len(lines[name]))
+		}
+	}
+}
+
+func TestAnalyzeDialogueVoicesFlagsSameness(t *testing.T) {
+	report := analyzeDialogueVoices(voiceChapters(6, func(string, int) string { return plainLine }), voiceCast)
+	if len(report.Characters) != 3 {
+		t.Fatalf("expected three profiled characters, got %+v", report)
+	}
+	if !report.Indistinct || !strings.Contains(strings.Join(report.Flags, " "), "Everyone sounds the same") {
+		t.Fatalf("expected identical voices to be flagged, got %+v", report)
+	}
+}
+
+func TestAnalyzeDialogueVoicesFlagsDrift(t *testing.T) {
+	styles := map[string]string{"Mara": terseLine, "Tomas": formalLine, "Ines": plainLine}
+	report := analyzeDialogueVoices(voiceChapters(6, func(speaker string, ch int) string {
+		// Mara starts talking like Tomas halfway through the book.
+		if speaker == "Mara" && ch > 3 {
+			return formalLine
+		}
+		return styles[speaker]
+	}), voiceCast)
+	if report.Indistinct {
+		t.Fatalf("distinct voices should not be flagged as the same: %+v", report)
+	}
+	for _, c := range report.Characters {
+		if c.Drifting != (c.Name == "Mara") {
+			t.Fatalf("expected only Mara to drift, got %+v", report.Characters)
+		}
+	}
+	if !strings.Contains(strings.Join(report.Flags, " "), "Voice drift: Mara") {
+		t.Fatalf("expected a drift flag for Mara, got %v", report.Flags)
+	}
+}
@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of draft marker.
+const (
+	DraftMarkerNote        = "note"
+	DraftMarkerBracket     = "bracket"
+	DraftMarkerHighlighted = "highlighted"
+)
+
+const (
+	// draftMarkerPenalty is taken off the MHD score per marker, up to
+	// maxDraftMarkerPenalty.
+	draftMarkerPenalty    = 2
+	maxDraftMarkerPenalty = 20
+	// maxHighlightMarkerRunes shortens long highlighted passages in Marker;
+	// the whole passage is still matched.
+	maxHighlightMarkerRunes = 60
+)
+
+var (
+	draftMarkerPattern    = regexp.MustCompile(`\b(TODO|FIXME|TBD|TK(TK)?)\b`)
+	bracketMarkerPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\[\s*(insert|add|fill in|placeholder|name|check|research|describe|something|more)\b[^\]\n]{0,40}\]`),
+		regexp.MustCompile(`\[[A-Z][A-Z ]{1,24}\]`),
+		regexp.MustCompile(`\{\{[^{}\n]{1,40}\}\}`),
+		regexp.MustCompile(`<<[^<>\n]{1,40}>>`),
+	}
+)
+
+type highlightsKey struct{}
+
+// WithHighlights passes the manuscript's highlighted passages (see
+// ingest.Parsed.Highlights) to the run, so the draft marker scan can list
+// them.
+func WithHighlights(ctx context.Context, highlights []string) context.Context {
+	return context.WithValue(ctx, highlightsKey{}, highlights)
+}
+
+func highlightsFromContext(ctx context.Context) []string {
+	highlights, _ := ctx.Value(highlightsKey{}).([]string)
+	return highlights
+}
+
+// scanDraftMarkers finds what an author left to finish: TK, TODO, FIXME and
+// TBD notes, bracketed placeholders such as "[insert name]" or "[CITY]",
+// and highlighted passages. Each is placed by chapter and paragraph, with
+// Offset counting runes into the chapter text.
+func scanDraftMarkers(chapters []chapter, highlights []string) DraftMarkerReport {
+	type hit struct {
+		start, end int
+		kind       string
+	}
+	pending := make([]string, 0, len(highlights))
+	for _, h := range highlights {
+		if h = strings.TrimSpace(h); h != "" {
+			pending = append(pending, h)
+		}
+	}
+	report := DraftMarkerReport{Markers: []DraftMarker{}}
+	for _, ch := range chapters {
+		lines := strings.Split(ch.text, "\n")
+		offset := 0
+		paragraph := 0
+		for i, raw := range lines {
+			lineStart := offset
+			offset += utf8.RuneCountInString(raw) + 1
+			line := strings.TrimSpace(raw)
+			if line == "" || (i == 0 && len(lines) > 1 && chapterHeaderPattern.MatchString(line)) {
+				continue
+			}
+			paragraph++
+			lineStart += utf8.RuneCountInString(raw[:strings.Index(raw, line)])
+
+			hits := []hit{}
+			for _, m := range draftMarkerPattern.FindAllStringIndex(line, -1) {
+				hits = append(hits, hit{m[0], m[1], DraftMarkerNote})
+			}
+			for _, p := range bracketMarkerPatterns {
+				for _, m := range p.FindAllStringIndex(line, -1) {
+					hits = append(hits, hit{m[0], m[1], DraftMarkerBracket})
+				}
+			}
+			// Highlights come in document order, so each is matched once,
+			// at its first place after the ones before it.
+			for len(pending) > 0 {
+				at := strings.Index(line, pending[0])
+				if at < 0 {
+					break
+				}
+				hits = append(hits, hit{at, at + len(pending[0]), DraftMarkerHighlighted})
+				pending = pending[1:]
+			}
+			sort.SliceStable(hits, func(a, b int) bool { return hits[a].start < hits[b].start })
+			lastEnd := -1
+			for _, h := range hits {
+				if h.start < lastEnd {
+					continue
+				}
+				lastEnd = h.end
+				marker := line[h.start:h.end]
+				if h.kind == DraftMarkerHighlighted {
+					marker = truncateRunes(marker, maxHighlightMarkerRunes)
+				}
+				report.Markers = append(report.Markers, DraftMarker{
+					Kind:      h.kind,
+					Marker:    marker,
+					Chapter:   ch.index,
+					Paragraph: paragraph,
+					Offset:    lineStart + utf8.RuneCountInString(line[:h.start]),
+					Location:  fmt.Sprintf("Ch %d, paragraph %d", ch.index, paragraph),
+					Context:   placeholderContext(line, h.start, h.end),
+				})
+			}
+		}
+	}
+	report.Ready = len(report.Markers) == 0
+	report.Penalty = min(len(report.Markers)*draftMarkerPenalty, maxDraftMarkerPenalty)
+	return report
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
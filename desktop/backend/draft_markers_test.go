@@ -0,0 +1,39 @@
+package backend
+
+import "testing"
+
+func TestScanDraftMarkers(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "Chapter 1", text: "Chapter 1\nMara met [insert name] at the dock. TK\nThe ferry left at dawn."},
+		{index: 2, title: "Chapter 2", text: "The letter came from [CITY] in the spring.\nShe paid Check the price for the room. TODO: fix the date."},
+	}
+	report := scanDraftMarkers(chapters, []string{"Check the price", "not in the text"})
+	want := []struct {
+		kind, marker               string
+		chapter, paragraph, offset int
+	}{
+		{DraftMarkerBracket, "[insert name]", 1, 1, 19},
+		{DraftMarkerNote, "TK", 1, 1, 46},
+		{DraftMarkerBracket, "[CITY]", 2, 1, 21},
+		{DraftMarkerHighlighted, "Check the price", 2, 2, 52},
+		{DraftMarkerNote, "TODO", 2, 2, 82},
+	}
+	if len(report.Markers) != len(want) {
+		t.Fatalf("want %d markers, got %+v", len(want), report.Markers)
+	}
+	for i, w := range want {
+		m := report.Markers[i]
+		if m.Kind != w.kind || m.Marker != w.marker || m.Chapter != w.chapter || m.Paragraph != w.paragraph || m.Offset != w.offset {
+			t.Fatalf("marker %d = %+v, want %+v", i, m, w)
+		}
+		if got := []rune(chapters[m.Chapter-1].text)[m.Offset:][:len([]rune(m.Marker))]; string(got) != m.Marker {
+			t.Fatalf("offset %d of chapter %d reads %q, not %q", m.Offset, m.Chapter, string(got), m.Marker)
+		}
+	}
+	if report.Ready || report.Penalty != 10 {
+		t.Fatalf("expected not ready with penalty 10, got ready=%t penalty=%d", report.Ready, report.Penalty)
+	}
+	if clean := scanDraftMarkers([]chapter{{index: 1, text: "All done."}}, nil); !clean.Ready || clean.Penalty != 0 {
+		t.Fatalf("expected a clean manuscript to be ready, got %+v", clean)
+	}
+}
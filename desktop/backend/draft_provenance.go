@@ -0,0 +1,206 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/ingest"
+	"book_dashboard/internal/workspace"
+)
+
+const (
+	// maxEarlierDrafts bounds how many earlier drafts a run re-reads.
+	maxEarlierDrafts = 5
+	// draftShingleWords is the word n-gram the chapter similarity compares.
+	draftShingleWords = 3
+	// draftReplacedBelow is the similarity under which a chapter counts as
+	// replaced wholesale rather than revised.
+	draftReplacedBelow = 0.3
+	// draftAISpike is the rise in a chapter's AI probability between two
+	// drafts that counts as a spike.
+	draftAISpike = 0.2
+)
+
+// DraftProvenanceReport follows each chapter through the earlier drafts of
+// the manuscript, the projects analyzed before under the same title, so an
+// editor can spot a chapter that was swapped out late and reads as AI-written
+// in its new form. Chapters are matched by number.
+type DraftProvenanceReport struct {
+	// Drafts are oldest first; the last one is this run.
+	Drafts   []DraftRef          `json:"drafts"`
+	Chapters []ChapterProvenance `json:"chapters"`
+	Flags    []string            `json:"flags"`
+}
+
+type DraftRef struct {
+	Draft      int    `json:"draft"`
+	ProjectID  string `json:"projectId"`
+	SourceName string `json:"sourceName"`
+	AnalyzedAt string `json:"analyzedAt"`
+}
+
+type ChapterProvenance struct {
+	Chapter  int                 `json:"chapter"`
+	Title    string              `json:"title"`
+	Timeline []ChapterDraftPoint `json:"timeline"`
+}
+
+// ChapterDraftPoint is a chapter in one draft. Similarity compares it with
+// the same chapter in the draft before and is nil for a chapter new in this
+// draft; PAI is nil when that draft's run did not score the chapter.
+type ChapterDraftPoint struct {
+	Draft      int      `json:"draft"`
+	Words      int      `json:"words"`
+	Similarity *float64 `json:"similarity"`
+	PAI        *float64 `json:"pAi"`
+	Replaced   bool     `json:"replaced"`
+	AISpike    bool     `json:"aiSpike"`
+}
+
+// draftSnapshot is one draft's chapters and their AI probabilities.
+type draftSnapshot struct {
+	ref      DraftRef
+	chapters []chapter
+	pai      map[int]*float64
+}
+
+// draftProvenance compares this run's chapters with the earlier drafts of
+// projectID. It is nil when there are none to compare with.
+func draftProvenance(workspaceRoot, projectID, sourceName string, chapters []chapter, report aidetect.Report, addLog func(level, stage, message, detail string)) *DraftProvenanceReport {
+	earlier, err := workspace.EarlierDrafts(workspaceRoot, projectID)
+	if err != nil {
+		addLog("RISK", "DRAFTS", "Earlier drafts unreadable; draft provenance skipped", err.Error())
+		return nil
+	}
+	if len(earlier) > maxEarlierDrafts {
+		earlier = earlier[len(earlier)-maxEarlierDrafts:]
+	}
+	drafts := make([]draftSnapshot, 0, len(earlier)+1)
+	for _, entry := range earlier {
+		snapshot, loadErr := loadDraftSnapshot(workspaceRoot, entry)
+		if loadErr != nil {
+			addLog("RISK", "DRAFTS", "Earlier draft skipped", fmt.Sprintf("project=%s: %v", entry.ID, loadErr))
+			continue
+		}
+		drafts = append(drafts, snapshot)
+	}
+	if len(drafts) == 0 {
+		return nil
+	}
+	drafts = append(drafts, draftSnapshot{
+		ref:      DraftRef{ProjectID: projectID, SourceName: sourceName},
+		chapters: chapters,
+		pai:      chapterPAIs(report),
+	})
+	provenance := compareDrafts(drafts)
+	addLog("ANALYSIS", "DRAFTS", "Draft provenance computed", fmt.Sprintf("drafts=%d flags=%d", len(provenance.Drafts), len(provenance.Flags)))
+	return provenance
+}
+
+// loadDraftSnapshot re-reads an earlier draft's source and the AI scores of
+// its latest saved run.
+func loadDraftSnapshot(workspaceRoot string, entry workspace.ProjectIndexEntry) (draftSnapshot, error) {
+	projectRoot, err := workspace.ProjectRoot(workspaceRoot, entry.ID)
+	if err != nil {
+		return draftSnapshot{}, err
+	}
+	parsed, err := ingest.ParseFile(filepath.Join(projectRoot, entry.SourceName))
+	if err != nil {
+		return draftSnapshot{}, err
+	}
+	snapshot := draftSnapshot{
+		ref:      DraftRef{ProjectID: entry.ID, SourceName: entry.SourceName, AnalyzedAt: entry.LastAnalyzedAt},
+		chapters: splitChapters(parsed.Text),
+	}
+	if runs, listErr := workspace.ListRuns(projectRoot); listErr == nil && len(runs) > 0 {
+		var run DashboardData
+		if workspace.LoadRun(projectRoot, runs[len(runs)-1], &run) == nil {
+			snapshot.pai = chapterPAIs(run.AIReport)
+		}
+	}
+	return snapshot, nil
+}
+
+func chapterPAIs(report aidetect.Report) map[int]*float64 {
+	out := make(map[int]*float64, len(report.PAIPerChapter))
+	for _, ch := range report.PAIPerChapter {
+		out[ch.Chapter] = ch.PAI
+	}
+	return out
+}
+
+// compareDrafts builds the timeline of every chapter in the last draft and
+// flags those replaced wholesale in the same revision their AI probability
+// spiked.
+func compareDrafts(drafts []draftSnapshot) *DraftProvenanceReport {
+	report := &DraftProvenanceReport{Drafts: []DraftRef{}, Chapters: []ChapterProvenance{}, Flags: []string{}}
+	byIndex := make([]map[int]chapter, len(drafts))
+	for i := range drafts {
+		drafts[i].ref.Draft = i + 1
+		report.Drafts = append(report.Drafts, drafts[i].ref)
+		byIndex[i] = map[int]chapter{}
+		for _, ch := range drafts[i].chapters {
+			byIndex[i][ch.index] = ch
+		}
+	}
+	for _, ch := range drafts[len(drafts)-1].chapters {
+		provenance := ChapterProvenance{Chapter: ch.index, Title: ch.title, Timeline: []ChapterDraftPoint{}}
+		var before *ChapterDraftPoint
+		var beforeText string
+		for i := range drafts {
+			current, ok := byIndex[i][ch.index]
+			if !ok {
+				before = nil
+				continue
+			}
+			point := ChapterDraftPoint{Draft: i + 1, Words: len(strings.Fields(current.text)), PAI: drafts[i].pai[ch.index]}
+			if before != nil {
+				similarity := shingleSimilarity(beforeText, current.text)
+				point.Similarity = &similarity
+				point.Replaced = similarity < draftReplacedBelow
+				point.AISpike = before.PAI != nil && point.PAI != nil && *point.PAI-*before.PAI >= draftAISpike
+				if point.Replaced && point.AISpike {
+					report.Flags = append(report.Flags, fmt.Sprintf("Chapter %d (%s) was replaced between drafts %d and %d (similarity %.2f) and its AI probability rose from %s to %s.",
+						ch.index, ch.title, before.Draft, point.Draft, similarity, percentInWords(*before.PAI), percentInWords(*point.PAI)))
+				}
+			}
+			provenance.Timeline = append(provenance.Timeline, point)
+			before, beforeText = &point, current.text
+		}
+		report.Chapters = append(report.Chapters, provenance)
+	}
+	return report
+}
+
+// shingleSimilarity is the Jaccard similarity of two texts' word n-grams, 1
+// for identical wording and 0 for none in common.
+func shingleSimilarity(a, b string) float64 {
+	sa, sb := wordShingles(a), wordShingles(b)
+	if len(sa) == 0 && len(sb) == 0 {
+		return 1
+	}
+	shared := 0
+	for s := range sa {
+		if sb[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(sa)+len(sb)-shared)
+}
+
+func wordShingles(text string) map[string]bool {
+	words := wordPattern.FindAllString(strings.ToLower(text), -1)
+	out := map[string]bool{}
+	if len(words) < draftShingleWords {
+		if len(words) > 0 {
+			out[strings.Join(words, " ")] = true
+		}
+		return out
+	}
+	for i := 0; i+draftShingleWords <= len(words); i++ {
+		out[strings.Join(words[i:i+draftShingleWords], " ")] = true
+	}
+	return out
+}
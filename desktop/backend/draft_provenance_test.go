@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareDraftsFlagsReplacedChapterWithAISpike(t *testing.T) {
+	p := func(v float64) *float64 { return &v }
+	kept := "Mara walked to the harbour at dawn and counted the boats twice before the fog came in."
+	drafts := []draftSnapshot{
+		{
+			chapters: []chapter{{index: 1, title: "Harbour", text: kept}, {index: 2, title: "Letter", text: "The letter was short and her father had signed it with only an initial."}},
+			pai:      map[int]*float64{1: p(0.10), 2: p(0.12)},
+		},
+		{
+			chapters: []chapter{{index: 1, title: "Harbour", text: kept + " She went home."}, {index: 2, title: "Letter", text: "In a tapestry of shimmering emotions, the missive unveiled a testament to enduring bonds."}},
+			pai:      map[int]*float64{1: p(0.11), 2: p(0.74)},
+		},
+	}
+	report := compareDrafts(drafts)
+	if len(report.Drafts) != 2 || report.Drafts[1].Draft != 2 || len(report.Chapters) != 2 {
+		t.Fatalf("unexpected report shape: %+v", report)
+	}
+	harbour := report.Chapters[0].Timeline
+	if len(harbour) != 2 || harbour[0].Similarity != nil || harbour[1].Replaced || *harbour[1].Similarity < 0.5 {
+		t.Fatalf("expected the revised chapter kept, got %+v", harbour)
+	}
+	letter := report.Chapters[1].Timeline[1]
+	if !letter.Replaced || !letter.AISpike {
+		t.Fatalf("expected the letter chapter replaced with an AI spike, got %+v", letter)
+	}
+	if len(report.Flags) != 1 || !strings.Contains(report.Flags[0], "Chapter 2 (Letter) was replaced between drafts 1 and 2") {
+		t.Fatalf("unexpected flags %q", report.Flags)
+	}
+	if got := PlainReport(DashboardData{Drafts: report}); !strings.Contains(got, "## Draft history") {
+		t.Fatalf("expected a draft history section:\n%s", got)
+	}
+
+	raw, err := json.Marshal(map[string]any{"analysis": reportAnalysis(DashboardData{Drafts: report})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := DashboardFromReport(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Drafts == nil || !reflect.DeepEqual(*loaded.Drafts, *report) {
+		t.Fatalf("expected the draft history to round-trip through report.json, got %+v", loaded.Drafts)
+	}
+}
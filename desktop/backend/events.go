@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event names carried on an EventBus. The desktop frontend subscribes to
+// analysis_progress, queue_progress and service_trace; notifications become
+// message dialogs. queue_progress carries one background queue item each
+// time its state or progress changes.
+const (
+	EventAnalysisProgress = "analysis_progress"
+	EventQueueProgress    = "queue_progress"
+	EventServiceTrace     = "service_trace"
+	EventNotification     = "notification"
+
+	NotificationInfo  = "info"
+	NotificationError = "error"
+)
+
+// EventBus receives everything a run reports while it works: progress,
+// service traces and user-facing notifications. The desktop app forwards
+// events to Wails; headless callers can write them out with JSONLEventBus.
+type EventBus interface {
+	Emit(name string, payload map[string]any)
+}
+
+// ProgressEvents adapts a bus to the ProgressFn that BuildDashboard reports
+// through. A nil bus yields a nil ProgressFn.
+func ProgressEvents(bus EventBus) ProgressFn {
+	if bus == nil {
+		return nil
+	}
+	return func(percent int, stage, detail string) {
+		bus.Emit(EventAnalysisProgress, map[string]any{"percent": percent, "stage": stage, "detail": detail})
+	}
+}
+
+// ProgressEventListener adapts a bus to a ProgressListener, emitting the
+// structured event as analysis_progress. The payload keeps percent, stage and
+// detail, so subscribers of ProgressEvents read it unchanged. A nil bus
+// yields a nil listener.
+func ProgressEventListener(bus EventBus) ProgressListener {
+	if bus == nil {
+		return nil
+	}
+	return func(ev ProgressEvent) {
+		bus.Emit(EventAnalysisProgress, ev.payload())
+	}
+}
+
+func (ev ProgressEvent) payload() map[string]any {
+	return map[string]any{
+		"percent":        ev.Percent,
+		"stage":          ev.Stage,
+		"detail":         ev.Detail,
+		"elapsedMs":      ev.ElapsedMs,
+		"stageElapsedMs": ev.StageElapsedMs,
+		"remainingMs":    ev.RemainingMs,
+		"chapter":        ev.Chapter,
+		"chapterCount":   ev.ChapterCount,
+		"words":          ev.Words,
+		"stages":         ev.Stages,
+	}
+}
+
+// Notify emits a notification; level is NotificationInfo or
+// NotificationError. A nil bus drops it.
+func Notify(bus EventBus, level, title, message string) {
+	if bus == nil {
+		return
+	}
+	bus.Emit(EventNotification, map[string]any{"level": level, "title": title, "message": message})
+}
+
+// JSONLEventBus writes one JSON object per event, with the event name under
+// "event" and the emit time under "time", for CLIs and log collectors.
+type JSONLEventBus struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONLEventBus(w io.Writer) *JSONLEventBus {
+	return &JSONLEventBus{enc: json.NewEncoder(w)}
+}
+
+func (b *JSONLEventBus) Emit(name string, payload map[string]any) {
+	line := make(map[string]any, len(payload)+2)
+	for k, v := range payload {
+		line[k] = v
+	}
+	line["event"] = name
+	line["time"] = time.Now().Format(time.RFC3339Nano)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Events are best effort; a closed writer must not fail the run.
+	_ = b.enc.Encode(line)
+}
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONLEventBusWritesOneObjectPerEvent(t *testing.T) {
+	var out bytes.Buffer
+	bus := NewJSONLEventBus(&out)
+	ProgressEvents(bus)(40, "SLOP", "scanning")
+	Notify(bus, NotificationError, "Export", "disk full")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two JSON lines, got %q", out.String())
+	}
+	var progressEvent, notice map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &progressEvent); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &notice); err != nil {
+		t.Fatal(err)
+	}
+	if progressEvent["event"] != EventAnalysisProgress || progressEvent["percent"] != float64(40) || progressEvent["stage"] != "SLOP" {
+		t.Fatalf("unexpected progress event %v", progressEvent)
+	}
+	if notice["event"] != EventNotification || notice["level"] != NotificationError || notice["time"] == "" {
+		t.Fatalf("unexpected notification %v", notice)
+	}
+}
+
+func TestProgressEventListenerKeepsPlainFields(t *testing.T) {
+	var out bytes.Buffer
+	ProgressEventListener(NewJSONLEventBus(&out))(ProgressEvent{Percent: 55, Stage: "AI", Detail: "windows", RemainingMs: 4200, Chapter: 2, Stages: []StageProgress{{Stage: "AI", Status: StageRunning, EstimatedMs: 5000}}})
+	var ev map[string]any
+	if err := json.Unmarshal(out.Bytes(), &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev["event"] != EventAnalysisProgress || ev["percent"] != float64(55) || ev["stage"] != "AI" || ev["remainingMs"] != float64(4200) || ev["chapter"] != float64(2) {
+		t.Fatalf("unexpected progress event %v", ev)
+	}
+	if stages, ok := ev["stages"].([]any); !ok || len(stages) != 1 {
+		t.Fatalf("expected the stage list, got %v", ev["stages"])
+	}
+}
+
+func TestNilBusIsSilent(t *testing.T) {
+	if ProgressEvents(nil) != nil || ProgressEventListener(nil) != nil {
+		t.Fatal("expected nil progress callbacks for a nil bus")
+	}
+	Notify(nil, NotificationInfo, "title", "message")
+}
@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+)
+
+// Export profiles choose who a report is written for. The internal export is
+// the whole report, reviewer notes included; the author-facing export leaves
+// out what stays with the agency: the AI-likelihood internals, reviewer notes,
+// library benchmarks, quality gates and model diagnostics.
+const (
+	ExportProfileInternal = "internal"
+	ExportProfileAuthor   = "author"
+)
+
+// ExportProfiles lists the export profiles in the order the UI offers them.
+var ExportProfiles = []string{ExportProfileInternal, ExportProfileAuthor}
+
+// ParseExportProfile resolves an export profile name; empty is the internal
+// profile.
+func ParseExportProfile(name string) (string, error) {
+	switch profile := strings.ToLower(strings.TrimSpace(name)); profile {
+	case "":
+		return ExportProfileInternal, nil
+	case ExportProfileInternal, ExportProfileAuthor:
+		return profile, nil
+	default:
+		return "", fmt.Errorf("unknown export profile %q (want %s)", name, strings.Join(ExportProfiles, " or "))
+	}
+}
+
+// RedactForAuthor returns data as the author-facing export shows it. The
+// dashboard's own copy is left untouched.
+func RedactForAuthor(data DashboardData) DashboardData {
+	out := data
+	out.AIReport = aidetect.Report{}
+	out.Annotations = nil
+	out.Benchmarks = nil
+	out.QualityMetrics = nil
+	out.QualityGates = nil
+	out.ModelDrift = nil
+	out.Drafts = nil
+	out.Logs = nil
+	out.System = SystemDiagnostics{}
+	out.ScoreBreakdown.Components = append([]ScorePenalty(nil), data.ScoreBreakdown.Components...)
+	for i, c := range out.ScoreBreakdown.Components {
+		if c.Component == ScoreComponentAI {
+			out.ScoreBreakdown.Components[i].Input, out.ScoreBreakdown.Components[i].Detail = 0, ""
+		}
+	}
+	if data.Anthology != nil {
+		anthology := *data.Anthology
+		anthology.MedianAI = 0
+		anthology.Stories = append([]StoryReport(nil), data.Anthology.Stories...)
+		for i := range anthology.Stories {
+			anthology.Stories[i].AI = StoryAI{}
+		}
+		anthology.Flags = nil
+		for _, flag := range data.Anthology.Flags {
+			if !strings.Contains(flag, "AI-like") {
+				anthology.Flags = append(anthology.Flags, flag)
+			}
+		}
+		out.Anthology = &anthology
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Fallback reason codes say why a stage fell back to its heuristics, so the UI
+// can offer the matching fix (start the service, pull the model) next to the
+// affected section instead of a generic warning.
+const (
+	FallbackServiceDown  = "service_down"
+	FallbackTimeout      = "timeout"
+	FallbackBadJSON      = "bad_json"
+	FallbackModelMissing = "model_missing"
+
+	ServiceOllama       = "ollama"
+	ServiceLanguageTool = "languagetool"
+)
+
+// FallbackReason is attached to a report section that was produced by the
+// heuristics because its service call failed. Model is empty for
+// LanguageTool.
+type FallbackReason struct {
+	Code    string `json:"code"`
+	Service string `json:"service"`
+	Model   string `json:"model,omitempty"`
+	Fix     string `json:"fix"`
+	Detail  string `json:"detail"`
+}
+
+// errNoJSON is returned when a model answered without a JSON object.
+var errNoJSON = errors.New("no JSON in model response")
+
+// httpStatusError is a non-2xx answer from a local service. It keeps the
+// body because Ollama only says a model is not pulled there.
+type httpStatusError struct {
+	status int
+	body   string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func statusError(status int, body []byte) error {
+	return &httpStatusError{status: status, body: string(body)}
+}
+
+// newFallbackReason classifies err from a call to service and says how to fix
+// it.
+func newFallbackReason(service, model string, err error) *FallbackReason {
+	reason := &FallbackReason{Code: classifyFallback(err), Service: service, Model: model, Detail: err.Error()}
+	name := "Ollama"
+	if service == ServiceLanguageTool {
+		name = "LanguageTool"
+	}
+	switch reason.Code {
+	case FallbackModelMissing:
+		reason.Fix = "Pull the model: ollama pull " + model
+	case FallbackTimeout:
+		reason.Fix = fmt.Sprintf("%s is slow to answer; re-run once it is idle or choose a smaller model.", name)
+	case FallbackBadJSON:
+		reason.Fix = fmt.Sprintf("%s answered with unreadable JSON; re-run, or choose another model than %s.", name, model)
+		if model == "" {
+			reason.Fix = fmt.Sprintf("%s answered with unreadable JSON; restart it and re-run.", name)
+		}
+	default:
+		reason.Fix = fmt.Sprintf("Start %s and re-run the analysis.", name)
+	}
+	return reason
+}
+
+func classifyFallback(err error) string {
+	var status *httpStatusError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &status):
+		if status.status == 404 && strings.Contains(strings.ToLower(status.body), "model") {
+			return FallbackModelMissing
+		}
+		return FallbackServiceDown
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FallbackTimeout
+	case errors.Is(err, errNoJSON), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return FallbackBadJSON
+	default:
+		return FallbackServiceDown
+	}
+}
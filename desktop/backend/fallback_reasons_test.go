@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPlotStructureFallbackReasons(t *testing.T) {
+	chapters := []chapter{{index: 1, title: "Chapter 1", text: "She left the harbor at dawn."}}
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"model missing", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"llama3.1:8b\" not found, try pulling it first"}`))
+		}, FallbackModelMissing},
+		{"server error", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, FallbackServiceDown},
+		{"prose answer", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"response":"The story follows a save the cat structure."}`))
+		}, FallbackBadJSON},
+		{"broken answer", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"response":`))
+		}, FallbackBadJSON},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			t.Setenv("OLLAMA_URL", server.URL)
+			_, report := analyzePlotStructure(PlotInputs{Chapters: chapters})
+			if report.Provider != "heuristic" || report.Fallback == nil || report.Fallback.Code != tc.want {
+				t.Fatalf("expected a %s fallback, got %s %+v", tc.want, report.Provider, report.Fallback)
+			}
+			if report.Fallback.Service != ServiceOllama || report.Fallback.Model == "" || report.Fallback.Fix == "" {
+				t.Fatalf("expected the service, model and fix named, got %+v", report.Fallback)
+			}
+		})
+	}
+
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	if _, report := analyzePlotStructure(PlotInputs{Chapters: chapters}); report.Fallback == nil || report.Fallback.Code != FallbackServiceDown {
+		t.Fatalf("expected service_down for a closed port, got %+v", report.Fallback)
+	}
+	if _, report := analyzePlotStructure(PlotInputs{Chapters: chapters, Offline: true}); report.Fallback != nil {
+		t.Fatalf("expected no fallback reason for an offline scan, got %+v", report.Fallback)
+	}
+}
+
+func TestClassifyFallbackTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	_, err := (&http.Client{Timeout: 20 * time.Millisecond}).Get(server.URL)
+	if err == nil {
+		t.Fatal("expected the request to time out")
+	}
+	if reason := newFallbackReason(ServiceLanguageTool, "", err); reason.Code != FallbackTimeout || reason.Fix == "" {
+		t.Fatalf("expected a timeout reason with a fix, got %+v", reason)
+	}
+}
@@ -0,0 +1,271 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/slop"
+	"book_dashboard/internal/workspace"
+)
+
+const (
+	// minFeedbackLabels is how many labels a signal needs, at least one of
+	// them a false positive, before its threshold is re-fit.
+	minFeedbackLabels = 5
+	// targetFeedbackPrecision is the share of a signal's findings readers
+	// should agree with once its threshold is re-fit.
+	targetFeedbackPrecision = 0.8
+)
+
+// Settings values for ProjectSettings.FeedbackThresholds.
+const (
+	FeedbackThresholdsRefit = "refit"
+	FeedbackThresholdsOff   = "off"
+)
+
+// FindingFeedback marks one finding of the loaded run correct or a false
+// positive. Finding IDs are those ExplainFinding takes; the label keeps the
+// finding's anchor ID so it names the same finding in later runs.
+type FindingFeedback struct {
+	FindingID string `json:"findingId"`
+	Correct   bool   `json:"correct"`
+	Note      string `json:"note"`
+}
+
+// SignalPrecision is how often readers agreed with one signal's findings.
+// Threshold is set when the labels moved the signal's threshold away from
+// DefaultThreshold.
+type SignalPrecision struct {
+	Signal           string   `json:"signal"`
+	Labels           int      `json:"labels"`
+	Correct          int      `json:"correct"`
+	Precision        float64  `json:"precision"`
+	Metric           string   `json:"metric,omitempty"`
+	DefaultThreshold *float64 `json:"defaultThreshold,omitempty"`
+	Threshold        *float64 `json:"threshold,omitempty"`
+}
+
+// FeedbackReport sums up the feedback given across the workspace. Thresholds
+// are the re-fit slop metric limits the next run applies.
+type FeedbackReport struct {
+	Signals    []SignalPrecision  `json:"signals"`
+	Thresholds map[string]float64 `json:"thresholds"`
+}
+
+// RecordFindingFeedback stores a verdict on a finding of the loaded run in
+// the workspace library, where labels from every project are pooled so the
+// thresholds follow the manuscripts this workspace sees, and returns the
+// updated report.
+func RecordFindingFeedback(data DashboardData, in FindingFeedback) (FeedbackReport, error) {
+	workspaceRoot, ok := projectWorkspaceRoot(data.ProjectLocation)
+	if strings.TrimSpace(data.ProjectLocation) == "" || !ok {
+		return FeedbackReport{}, fmt.Errorf("no project loaded")
+	}
+	label, err := feedbackLabel(data, strings.TrimSpace(in.FindingID))
+	if err != nil {
+		return FeedbackReport{}, err
+	}
+	label.ProjectID = filepath.Base(data.ProjectLocation)
+	label.RunID = data.RunStats.RunID
+	label.Correct = in.Correct
+	label.Note = in.Note
+	libraryPath := workspace.LibraryDBPath(workspaceRoot)
+	if err := db.RecordFeedback(libraryPath, label); err != nil {
+		return FeedbackReport{}, err
+	}
+	return LoadFeedbackReport(workspaceRoot)
+}
+
+// LoadFeedbackReport computes per-signal precision and re-fit thresholds
+// from every label in the workspace library.
+func LoadFeedbackReport(workspaceRoot string) (FeedbackReport, error) {
+	libraryPath := workspace.LibraryDBPath(workspaceRoot)
+	if _, err := os.Stat(libraryPath); os.IsNotExist(err) {
+		return buildFeedbackReport(nil), nil
+	}
+	labels, err := db.ListFeedback(libraryPath)
+	if err != nil {
+		return FeedbackReport{}, err
+	}
+	return buildFeedbackReport(labels), nil
+}
+
+// feedbackThresholds returns the slop thresholds re-fit from feedback for a
+// run, or nil when the project turned re-fitting off or no signal has enough
+// labels yet.
+func feedbackThresholds(workspaceRoot string, settings workspace.ProjectSettings, addLog func(level, stage, message, detail string)) map[string]float64 {
+	if strings.EqualFold(strings.TrimSpace(settings.FeedbackThresholds), FeedbackThresholdsOff) || strings.TrimSpace(workspaceRoot) == "" {
+		return nil
+	}
+	report, err := LoadFeedbackReport(workspaceRoot)
+	if err != nil {
+		addLog("RISK", "SLOP", "Reader feedback unreadable; default thresholds used", err.Error())
+		return nil
+	}
+	if len(report.Thresholds) == 0 {
+		return nil
+	}
+	moved := make([]string, 0, len(report.Thresholds))
+	for metric, t := range report.Thresholds {
+		moved = append(moved, fmt.Sprintf("%s=%.4g->%.4g", metric, slop.DefaultThresholds[metric], t))
+	}
+	sort.Strings(moved)
+	addLog("INFO", "SLOP", "Thresholds re-fit from reader feedback", strings.Join(moved, " "))
+	return report.Thresholds
+}
+
+// feedbackLabel names the signal behind a finding and, for slop flags, the
+// metric value and threshold that raised it.
+func feedbackLabel(data DashboardData, findingID string) (db.FeedbackLabel, error) {
+	label := db.FeedbackLabel{FindingID: StableFindingID(data, findingID)}
+	findingID = resolveFindingID(data, findingID)
+	switch {
+	case strings.HasPrefix(findingID, "w-"):
+		for _, w := range data.AIReport.Windows {
+			if w.WindowID == findingID {
+				label.Signal, label.Metric, label.Value = FindingAIWindow, "p_ai", w.PAI
+				return label, nil
+			}
+		}
+	case strings.HasPrefix(findingID, "issue-"):
+		for _, issue := range data.HealthIssues {
+			if issue.ID == findingID {
+				kind := issue.Kind
+				if kind == "" {
+					kind = HealthIssueContradiction
+				}
+				label.Signal = FindingHealthIssue + ":" + kind
+				return label, nil
+			}
+		}
+	case strings.HasPrefix(findingID, "slop-"):
+		if i, ok := findingIndex(findingID, "slop-", len(data.SlopReport.Flags)); ok {
+			flag := data.SlopReport.Flags[i]
+			label.Signal = FindingSlop + ":" + flag.Code
+			if len(flag.Evidence) > 0 {
+				e := flag.Evidence[0]
+				label.Metric, label.Value, label.Threshold = e.Metric, e.Value, e.Threshold
+			}
+			return label, nil
+		}
+	case strings.HasPrefix(findingID, "sensitivity-"):
+		if i, ok := findingIndex(findingID, "sensitivity-", len(data.Sensitivity.Flags)); ok {
+			label.Signal = FindingSensitivity + ":" + data.Sensitivity.Flags[i].Category
+			return label, nil
+		}
+	case strings.HasPrefix(findingID, "rating-"):
+		dim := strings.TrimPrefix(findingID, "rating-")
+		for _, d := range data.Language.AgeRating.Dimensions {
+			if d.Dimension == dim {
+				label.Signal = FindingContentRating + ":" + dim
+				return label, nil
+			}
+		}
+	}
+	return db.FeedbackLabel{}, fmt.Errorf("unknown finding %q", findingID)
+}
+
+func buildFeedbackReport(labels []db.FeedbackLabel) FeedbackReport {
+	report := FeedbackReport{Signals: []SignalPrecision{}, Thresholds: map[string]float64{}}
+	bySignal := map[string][]db.FeedbackLabel{}
+	for _, l := range labels {
+		bySignal[l.Signal] = append(bySignal[l.Signal], l)
+	}
+	for signal, group := range bySignal {
+		sp := SignalPrecision{Signal: signal, Labels: len(group)}
+		// A flag reports its strongest metric first, so one signal may carry
+		// labels for more than one metric; re-fit the one most labels name.
+		metricCounts := map[string]int{}
+		for _, l := range group {
+			if l.Correct {
+				sp.Correct++
+			}
+			metricCounts[l.Metric]++
+		}
+		sp.Precision = float64(sp.Correct) / float64(sp.Labels)
+		for m, n := range metricCounts {
+			if n > metricCounts[sp.Metric] || (n == metricCounts[sp.Metric] && m < sp.Metric) {
+				sp.Metric = m
+			}
+		}
+		if def, ok := slop.DefaultThresholds[sp.Metric]; ok {
+			sp.DefaultThreshold = &def
+			metricLabels := []db.FeedbackLabel{}
+			for _, l := range group {
+				if l.Metric == sp.Metric {
+					metricLabels = append(metricLabels, l)
+				}
+			}
+			if t, ok := fitThreshold(metricLabels, def, slop.IsFloorMetric(sp.Metric)); ok {
+				sp.Threshold = &t
+				report.Thresholds[sp.Metric] = t
+			}
+		}
+		report.Signals = append(report.Signals, sp)
+	}
+	sort.Slice(report.Signals, func(i, j int) bool {
+		if report.Signals[i].Precision != report.Signals[j].Precision {
+			return report.Signals[i].Precision < report.Signals[j].Precision
+		}
+		return report.Signals[i].Signal < report.Signals[j].Signal
+	})
+	return report
+}
+
+// fitThreshold moves a threshold just past the false positives readers
+// marked, to the loosest limit at which the labelled findings that would
+// still fire reach targetFeedbackPrecision, or else the most precise one.
+// Labels only exist for findings that fired, so a threshold can only be
+// tightened: past def for a ceiling, below it for a floor.
+func fitThreshold(labels []db.FeedbackLabel, def float64, floor bool) (float64, bool) {
+	wrong := 0
+	for _, l := range labels {
+		if !l.Correct {
+			wrong++
+		}
+	}
+	if len(labels) < minFeedbackLabels || wrong == 0 {
+		return 0, false
+	}
+	// Order labels from weakest to strongest evidence.
+	sorted := append([]db.FeedbackLabel{}, labels...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if floor {
+			return sorted[i].Value > sorted[j].Value
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	best, bestPrecision := -1, -1.0
+	for i := range sorted {
+		if i > 0 && sorted[i].Value == sorted[i-1].Value {
+			continue
+		}
+		correct := 0
+		for _, l := range sorted[i:] {
+			if l.Correct {
+				correct++
+			}
+		}
+		precision := float64(correct) / float64(len(sorted)-i)
+		if precision >= targetFeedbackPrecision {
+			best = i
+			break
+		}
+		if precision > bestPrecision {
+			best, bestPrecision = i, precision
+		}
+	}
+	if best <= 0 {
+		return 0, false
+	}
+	t := math.Round((sorted[best-1].Value+sorted[best].Value)/2*1e4) / 1e4
+	if (!floor && t <= def) || (floor && t >= def) {
+		return 0, false
+	}
+	return t, true
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/slop"
+	"book_dashboard/internal/workspace"
+)
+
+func TestRecordFindingFeedbackRefitsSlopThresholds(t *testing.T) {
+	root := t.TempDir()
+	projectRoot := filepath.Join(root, "projects", "p1")
+	if err := os.MkdirAll(projectRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := DashboardData{ProjectLocation: projectRoot}
+	// Five repeated-phrase flags from five runs: readers reject the two
+	// weakest and accept the rest.
+	coverages := []float64{0.11, 0.13, 0.16, 0.22, 0.30}
+	for i, coverage := range coverages {
+		data.RunStats.RunID = "run-" + string(rune('a'+i))
+		data.SlopReport.Flags = []slop.Flag{{Code: slop.FlagRepeatedPhrases, Evidence: []slop.EvidenceRef{{Metric: "RepeatedPhraseCoverage", Value: coverage, Threshold: 0.10}}}}
+		if _, err := RecordFindingFeedback(data, FindingFeedback{FindingID: "slop-1", Correct: coverage > 0.15}); err != nil {
+			t.Fatalf("record feedback: %v", err)
+		}
+	}
+	report, err := RecordFindingFeedback(data, FindingFeedback{FindingID: "slop-1", Correct: true, Note: "still right"})
+	if err != nil {
+		t.Fatalf("record feedback: %v", err)
+	}
+	if len(report.Signals) != 1 || report.Signals[0].Labels != 5 || report.Signals[0].Correct != 3 || report.Signals[0].Signal != "slop:repeated_phrases" {
+		t.Fatalf("expected re-marking a finding to replace its label, got %+v", report.Signals)
+	}
+	if got := report.Thresholds["RepeatedPhraseCoverage"]; got != 0.145 {
+		t.Fatalf("expected the threshold moved between the false positives and the first agreed finding, got %v", report.Thresholds)
+	}
+
+	var logged []string
+	addLog := func(level, stage, message, detail string) { logged = append(logged, message+" "+detail) }
+	if th := feedbackThresholds(root, workspace.ProjectSettings{}, addLog); th["RepeatedPhraseCoverage"] != 0.145 || len(logged) != 1 {
+		t.Fatalf("expected the re-fit threshold applied and logged, got %v %v", th, logged)
+	}
+	if th := feedbackThresholds(root, workspace.ProjectSettings{FeedbackThresholds: FeedbackThresholdsOff}, addLog); th != nil {
+		t.Fatalf("expected re-fitting off to keep the defaults, got %v", th)
+	}
+
+	if _, err := RecordFindingFeedback(data, FindingFeedback{FindingID: "slop-9"}); err == nil {
+		t.Fatal("expected an unknown finding rejected")
+	}
+}
+
+func TestFitThresholdForFloorMetric(t *testing.T) {
+	// Sentence-length SD flags below 4; readers reject the flags just under it.
+	labels := []db.FeedbackLabel{}
+	for _, v := range []float64{3.9, 3.7, 3.0, 2.5, 2.0, 1.5} {
+		labels = append(labels, db.FeedbackLabel{Metric: "SentenceLengthSD", Value: v, Correct: v < 3.5})
+	}
+	// Dropping the weakest flag already leaves four of five agreed, 80%.
+	got, ok := fitThreshold(labels, 4, true)
+	if !ok || got != 3.8 {
+		t.Fatalf("expected the floor lowered between 3.9 and 3.7, got %v %v", got, ok)
+	}
+	if _, ok := fitThreshold(labels[:4], 4, true); ok {
+		t.Fatal("expected too few labels left alone")
+	}
+	for i := range labels {
+		labels[i].Correct = true
+	}
+	if _, ok := fitThreshold(labels, 4, true); ok {
+		t.Fatal("expected no change without a false positive")
+	}
+}
@@ -0,0 +1,168 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Anchor IDs name a finding by a fingerprint of the text around it instead of
+// its position in the run, so notes, feedback and run comparisons can match
+// the same issue after edits shift its offsets or renumber the findings. They
+// read prefix@hash, with the prefix of the positional ID (issue@3f9a0c12de).
+// The positional IDs (issue-001, w-023) still work everywhere a finding ID is
+// taken.
+
+// anchorWindowWords is how many opening words of an AI window fingerprint it.
+const anchorWindowWords = 12
+
+// anchorFindings sets the anchor ID of every health issue, slop flag,
+// sensitivity flag and AI window of data. text is the analyzed text the AI
+// windows' word offsets point into.
+func anchorFindings(data *DashboardData, text string) {
+	seen := map[string]int{}
+	for i := range data.HealthIssues {
+		data.HealthIssues[i].AnchorID = uniqueAnchor(seen, healthIssueAnchor(data.HealthIssues[i]))
+	}
+	for i := range data.SlopReport.Flags {
+		data.SlopReport.Flags[i].AnchorID = uniqueAnchor(seen, slopFlagAnchor(data.SlopReport.Flags[i].Code, data.SlopReport.Flags[i].Text))
+	}
+	for i := range data.Sensitivity.Flags {
+		data.Sensitivity.Flags[i].AnchorID = uniqueAnchor(seen, sensitivityFlagAnchor(data.Sensitivity.Flags[i]))
+	}
+	words := strings.Fields(text)
+	for i, w := range data.AIReport.Windows {
+		if w.StartWord < 0 || w.StartWord >= len(words) {
+			continue
+		}
+		opening := words[w.StartWord:min(w.EndWord, w.StartWord+anchorWindowWords, len(words))]
+		data.AIReport.Windows[i].AnchorID = uniqueAnchor(seen, contentAnchor("w", strings.Join(opening, " ")))
+	}
+}
+
+// healthIssueAnchor fingerprints an issue by its kind, entity and quoted
+// passages; chapter numbers and descriptions, which carry counts, are left
+// out.
+func healthIssueAnchor(issue HealthIssue) string {
+	kind := issue.Kind
+	if kind == "" {
+		kind = HealthIssueContradiction
+	}
+	return contentAnchor("issue", kind, issue.Entity, issue.ContextA, issue.ContextB)
+}
+
+// slopFlagAnchor fingerprints a manuscript-wide slop flag by its code, or by
+// its label when an old report left the code unknown.
+func slopFlagAnchor(code, text string) string {
+	if code == "" {
+		code = slopFlagLabel(text)
+	}
+	return contentAnchor("slop", code)
+}
+
+func sensitivityFlagAnchor(f SensitivityFlag) string {
+	return contentAnchor("sensitivity", f.Category, f.Term, f.Quote)
+}
+
+// contentAnchor hashes parts by their words alone, so case, punctuation and
+// spacing changes keep the anchor.
+func contentAnchor(prefix string, parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(strings.Join(wordPattern.FindAllString(strings.ToLower(part), -1), " ")))
+		h.Write([]byte{0})
+	}
+	return prefix + "@" + hex.EncodeToString(h.Sum(nil))[:10]
+}
+
+// uniqueAnchor numbers repeats of an anchor within one run in report order:
+// the second identical finding is anchor~2.
+func uniqueAnchor(seen map[string]int, anchor string) string {
+	seen[anchor]++
+	if n := seen[anchor]; n > 1 {
+		return fmt.Sprintf("%s~%d", anchor, n)
+	}
+	return anchor
+}
+
+func isAnchorID(findingID string) bool {
+	return strings.Contains(findingID, "@")
+}
+
+// resolveFindingID maps an anchor ID to the positional ID of the matching
+// finding in data. Positional IDs are returned unchanged, and an anchor with
+// no match in data is returned as is so the caller reports it unknown.
+func resolveFindingID(data DashboardData, findingID string) string {
+	if !isAnchorID(findingID) {
+		return findingID
+	}
+	for _, issue := range data.HealthIssues {
+		if issue.AnchorID == findingID {
+			return issue.ID
+		}
+	}
+	for i, f := range data.SlopReport.Flags {
+		if f.AnchorID == findingID {
+			return fmt.Sprintf("slop-%d", i+1)
+		}
+	}
+	for i, f := range data.Sensitivity.Flags {
+		if f.AnchorID == findingID {
+			return fmt.Sprintf("sensitivity-%d", i+1)
+		}
+	}
+	for _, w := range data.AIReport.Windows {
+		if w.AnchorID == findingID {
+			return w.WindowID
+		}
+	}
+	return findingID
+}
+
+// StableFindingID returns the anchor ID of the finding findingID names in
+// data, for storing with notes and feedback, or findingID itself for
+// findings without an anchor, such as content ratings (rating-violence),
+// whose IDs are stable already.
+func StableFindingID(data DashboardData, findingID string) string {
+	findingID = strings.TrimSpace(findingID)
+	if isAnchorID(findingID) {
+		return findingID
+	}
+	switch {
+	case strings.HasPrefix(findingID, "w-"):
+		for _, w := range data.AIReport.Windows {
+			if w.WindowID == findingID && w.AnchorID != "" {
+				return w.AnchorID
+			}
+		}
+	case strings.HasPrefix(findingID, "issue-"):
+		for _, issue := range data.HealthIssues {
+			if issue.ID == findingID && issue.AnchorID != "" {
+				return issue.AnchorID
+			}
+		}
+	case strings.HasPrefix(findingID, "slop-"):
+		if i, ok := findingIndex(findingID, "slop-", len(data.SlopReport.Flags)); ok && data.SlopReport.Flags[i].AnchorID != "" {
+			return data.SlopReport.Flags[i].AnchorID
+		}
+	case strings.HasPrefix(findingID, "sensitivity-"):
+		if i, ok := findingIndex(findingID, "sensitivity-", len(data.Sensitivity.Flags)); ok && data.Sensitivity.Flags[i].AnchorID != "" {
+			return data.Sensitivity.Flags[i].AnchorID
+		}
+	}
+	return findingID
+}
+
+// markDetachedAnnotations marks the notes on an anchored finding that this
+// run no longer raises as orphaned, as text notes whose passage is gone are.
+func markDetachedAnnotations(data *DashboardData) int {
+	detached := 0
+	for i, a := range data.Annotations {
+		if isAnchorID(a.FindingID) && resolveFindingID(*data, a.FindingID) == a.FindingID {
+			data.Annotations[i].Orphaned = true
+			detached++
+		}
+	}
+	return detached
+}
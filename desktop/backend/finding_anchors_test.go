@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/slop"
+)
+
+func TestFindingAnchorsSurviveShiftedOffsets(t *testing.T) {
+	passage := "Mara pushed open the gate and the hinges screamed like a gull over the harbor wall at dawn."
+	draft := func(prefix string, chapterA int, issueNumber string) (DashboardData, string) {
+		text := prefix + passage + " The rest of the chapter follows."
+		start := len(strings.Fields(prefix))
+		data := DashboardData{
+			HealthIssues: []HealthIssue{
+				{ID: issueNumber, Kind: HealthIssueTenseDrift, Description: "Tense drift", ChapterA: chapterA, ChapterB: chapterA, ContextA: "Mara pushed open the gate.", ContextB: "The hinges scream."},
+			},
+			SlopReport:  slop.Report{Flags: []slop.Flag{{Code: slop.FlagMonotone, Text: "Monotone: 3.1"}}},
+			Sensitivity: SensitivityReport{Flags: []SensitivityFlag{{Chapter: chapterA, Category: SensitivityStereotype, Quote: "like all of them"}}},
+			AIReport:    aidetect.Report{Windows: []aidetect.WindowReport{{WindowID: "w-001", StartWord: start, EndWord: start + 18}}},
+		}
+		anchorFindings(&data, text)
+		return data, text
+	}
+	first, _ := draft("", 2, "issue-001")
+	// A later draft adds a paragraph before the passage, renumbers the
+	// chapters and reports the issue under another number.
+	second, text := draft("A new opening paragraph sets the scene first. ", 3, "issue-004")
+
+	if a, b := first.HealthIssues[0].AnchorID, second.HealthIssues[0].AnchorID; a == "" || a != b || !strings.HasPrefix(a, "issue@") {
+		t.Fatalf("expected the same issue anchor across drafts, got %q and %q", a, b)
+	}
+	if a, b := first.AIReport.Windows[0].AnchorID, second.AIReport.Windows[0].AnchorID; a == "" || a != b {
+		t.Fatalf("expected the shifted window to keep its anchor, got %q and %q", a, b)
+	}
+	if first.Sensitivity.Flags[0].AnchorID != second.Sensitivity.Flags[0].AnchorID || first.SlopReport.Flags[0].AnchorID != second.SlopReport.Flags[0].AnchorID {
+		t.Fatal("expected flag anchors unchanged across drafts")
+	}
+
+	anchor := first.HealthIssues[0].AnchorID
+	if got := resolveFindingID(second, anchor); got != "issue-004" {
+		t.Fatalf("expected the anchor resolved to the new issue number, got %q", got)
+	}
+	if got := StableFindingID(second, "issue-004"); got != anchor {
+		t.Fatalf("expected the positional ID mapped to its anchor, got %q", got)
+	}
+	if got := StableFindingID(second, "rating-violence"); got != "rating-violence" {
+		t.Fatalf("expected rating IDs kept, got %q", got)
+	}
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	explanation, err := ExplainFinding(second, text, anchor)
+	if err != nil || explanation.FindingID != anchor || explanation.Kind != FindingHealthIssue {
+		t.Fatalf("expected the anchored finding explained, got %+v (%v)", explanation, err)
+	}
+
+	second.Annotations = []Annotation{{FindingID: anchor}, {FindingID: "issue@0000000000"}, {FindingID: "issue-001"}}
+	if detached := markDetachedAnnotations(&second); detached != 1 || !second.Annotations[1].Orphaned || second.Annotations[0].Orphaned || second.Annotations[2].Orphaned {
+		t.Fatalf("expected only the note on a vanished finding orphaned, got %d %+v", detached, second.Annotations)
+	}
+}
+
+func TestFindingAnchorsNumberRepeats(t *testing.T) {
+	data := DashboardData{HealthIssues: []HealthIssue{
+		{ID: "issue-001", Kind: HealthIssueFragment, ContextA: "She said"},
+		{ID: "issue-002", Kind: HealthIssueFragment, ContextA: "she said."},
+	}}
+	anchorFindings(&data, "")
+	a, b := data.HealthIssues[0].AnchorID, data.HealthIssues[1].AnchorID
+	if b != a+"~2" {
+		t.Fatalf("expected the repeat numbered, got %q and %q", a, b)
+	}
+	if got := resolveFindingID(data, b); got != "issue-002" {
+		t.Fatalf("expected the repeat resolved to the second issue, got %q", got)
+	}
+}
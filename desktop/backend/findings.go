@@ -0,0 +1,299 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+)
+
+const (
+	FindingAIWindow      = "ai_window"
+	FindingHealthIssue   = "health_issue"
+	FindingSlop          = "slop"
+	FindingSensitivity   = "sensitivity"
+	FindingContentRating = "content_rating"
+)
+
+// FindingExplanation is the editor-facing answer to "what does this finding
+// mean and what should I do about it".
+type FindingExplanation struct {
+	FindingID    string   `json:"findingId"`
+	Kind         string   `json:"kind"`
+	Title        string   `json:"title"`
+	Evidence     []string `json:"evidence"`
+	Explanation  string   `json:"explanation"`
+	SuggestedFix string   `json:"suggestedFix"`
+	Provider     string   `json:"provider"`
+	Notes        []string `json:"notes"`
+}
+
+type explainLLMResult struct {
+	Explanation  string `json:"explanation"`
+	SuggestedFix string `json:"suggested_fix"`
+}
+
+// ExplainFinding composes the evidence behind a finding into a prompt for the
+// local model. When the model is unavailable a templated explanation is
+// returned so the binding never leaves the editor empty-handed.
+//
+// Finding IDs: AI windows use their window id (w-023), health issues their
+// issue id (issue-001), slop and sensitivity flags their 1-based position
+// (slop-2, sensitivity-3), and content ratings their dimension
+// (rating-violence). The anchor IDs of finding_anchors.go name the same
+// findings in a way that survives re-analysis.
+func ExplainFinding(data DashboardData, text, findingID string) (FindingExplanation, error) {
+	findingID = strings.TrimSpace(findingID)
+	out, err := resolveFinding(data, text, resolveFindingID(data, findingID))
+	if err != nil {
+		return FindingExplanation{}, err
+	}
+	out.FindingID = findingID
+
+	model := ollamaModel("OLLAMA_EXPLAIN_MODEL", "OLLAMA_LANGUAGE_MODEL")
+	var b strings.Builder
+	b.WriteString("You are an experienced manuscript editor. Explain the following automated finding to a writer in plain English, ")
+	b.WriteString("without jargon, in at most four sentences, then suggest one concrete fix. ")
+	b.WriteString("Return JSON only with keys: explanation, suggested_fix.\n\n")
+	fmt.Fprintf(&b, "FINDING: %s (%s)\n", out.Title, out.Kind)
+	b.WriteString("EVIDENCE:\n")
+	for _, line := range out.Evidence {
+		b.WriteString("- " + line + "\n")
+	}
+	var parsed explainLLMResult
+	if err := generateOllamaJSON(model, b.String(), &parsed); err != nil {
+		out.Notes = append(out.Notes, "Ollama explanation unavailable: "+err.Error())
+		return out, nil
+	}
+	if strings.TrimSpace(parsed.Explanation) == "" {
+		out.Notes = append(out.Notes, "Ollama returned an empty explanation; showing the built-in explanation.")
+		return out, nil
+	}
+	out.Explanation = strings.TrimSpace(parsed.Explanation)
+	if fix := strings.TrimSpace(parsed.SuggestedFix); fix != "" {
+		out.SuggestedFix = fix
+	}
+	out.Provider = "ollama:" + model
+	return out, nil
+}
+
+// resolveFinding gathers evidence for the finding and fills in the templated
+// explanation used when the model is unavailable.
+func resolveFinding(data DashboardData, text, findingID string) (FindingExplanation, error) {
+	out := FindingExplanation{FindingID: findingID, Evidence: []string{}, Provider: "heuristic", Notes: []string{}}
+	switch {
+	case strings.HasPrefix(findingID, "w-"):
+		for _, w := range data.AIReport.Windows {
+			if w.WindowID == findingID {
+				explainAIWindow(&out, w, text)
+				return out, nil
+			}
+		}
+	case strings.HasPrefix(findingID, "issue-"):
+		for _, issue := range data.HealthIssues {
+			if issue.ID == findingID && issue.Kind == HealthIssueTenseDrift {
+				out.Kind = FindingHealthIssue
+				out.Title = issue.Description
+				out.Evidence = append(out.Evidence,
+					fmt.Sprintf("Chapter %d, before: %s", issue.ChapterA, issue.ContextA),
+					fmt.Sprintf("Chapter %d, after: %s", issue.ChapterB, issue.ContextB),
+					"Severity: "+issue.Severity,
+				)
+				out.Explanation = fmt.Sprintf("The narration in chapter %d changes tense partway through a scene with no scene break, which reads as a slip rather than a choice.", issue.ChapterA)
+				out.SuggestedFix = "Put the drifted sentences back in the scene's tense, or add a scene break if the shift is intentional."
+				return out, nil
+			}
+			if issue.ID == findingID && issue.Kind == HealthIssueChapterNumbering {
+				out.Kind = FindingHealthIssue
+				out.Title = issue.Description
+				if issue.ContextA != "" {
+					out.Evidence = append(out.Evidence, fmt.Sprintf("Chapter %d heading: %s", issue.ChapterA, issue.ContextA))
+				}
+				out.Evidence = append(out.Evidence,
+					fmt.Sprintf("Chapter %d heading: %s", issue.ChapterB, issue.ContextB),
+					"Severity: "+issue.Severity,
+				)
+				out.Explanation = "The chapter headings do not count up one at a time in reading order, so a chapter may be missing, repeated or misplaced."
+				out.SuggestedFix = "Check that no chapter was dropped or pasted twice, then renumber the headings to match their order."
+				return out, nil
+			}
+			if issue.ID == findingID && issue.Kind == HealthIssuePOVDrift {
+				out.Kind = FindingHealthIssue
+				out.Title = issue.Description
+				if issue.ContextA != "" {
+					out.Evidence = append(out.Evidence, fmt.Sprintf("Ch%d: %s", issue.ChapterA, issue.ContextA))
+				}
+				if issue.ContextB != "" {
+					out.Evidence = append(out.Evidence, fmt.Sprintf("Ch%d: %s", issue.ChapterB, issue.ContextB))
+				}
+				out.Evidence = append(out.Evidence, "Severity: "+issue.Severity)
+				out.Explanation = fmt.Sprintf("The narration in chapter %d does not hold one point of view: it changes grammatical person from the rest of the book, or reports several characters' thoughts in quick succession.", issue.ChapterA)
+				out.SuggestedFix = "Keep each scene in one character's head and one grammatical person; move other characters' thoughts into what the viewpoint character can see or hear, or add a scene break."
+				return out, nil
+			}
+			if issue.ID == findingID && issue.Kind == HealthIssueFragment {
+				out.Kind = FindingHealthIssue
+				out.Title = issue.Description
+				out.Evidence = append(out.Evidence, fmt.Sprintf("Ch%d: %s", issue.ChapterA, issue.ContextA))
+				if issue.ContextB != "" {
+					out.Evidence = append(out.Evidence, fmt.Sprintf("Ch%d: %s", issue.ChapterB, issue.ContextB))
+				}
+				out.Evidence = append(out.Evidence, "Severity: "+issue.Severity)
+				out.Explanation = "Stub chapters, sentences cut at a chapter break and chapters that start alike and then diverge are typical leftovers of pasting drafts together."
+				out.SuggestedFix = "Compare the passages with your drafts, then delete the leftover or join the split text back together."
+				return out, nil
+			}
+			if issue.ID == findingID {
+				out.Kind = FindingHealthIssue
+				out.Title = issue.Description
+				out.Evidence = append(out.Evidence,
+					fmt.Sprintf("Chapter %d: %s", issue.ChapterA, issue.ContextA),
+					fmt.Sprintf("Chapter %d: %s", issue.ChapterB, issue.ContextB),
+					"Severity: "+issue.Severity,
+				)
+				out.Explanation = fmt.Sprintf("%s is described differently in chapter %d and chapter %d, so a reader may notice the inconsistency.", issue.Entity, issue.ChapterA, issue.ChapterB)
+				out.SuggestedFix = fmt.Sprintf("Decide which version of %s is canonical and update the other chapter, or add a line explaining the change.", issue.Entity)
+				return out, nil
+			}
+		}
+	case strings.HasPrefix(findingID, "slop-"):
+		if i, ok := findingIndex(findingID, "slop-", len(data.SlopReport.Flags)); ok {
+			flag := data.SlopReport.Flags[i]
+			out.Kind = FindingSlop
+			out.Title = flag.Text
+			out.Evidence = append(out.Evidence,
+				fmt.Sprintf("Mean sentence length: %.1f words (standard deviation %.1f)", data.SlopReport.MeanSentenceLength, data.SlopReport.SentenceLengthSD),
+				fmt.Sprintf("Filler word density: %.3f", data.SlopReport.BadWordDensity),
+			)
+			for _, e := range flag.Evidence {
+				out.Evidence = append(out.Evidence, fmt.Sprintf("%s: %.3f (threshold %.3f)", e.Metric, e.Value, e.Threshold))
+			}
+			if flag.Severity != "" {
+				out.Evidence = append(out.Evidence, fmt.Sprintf("Severity: %s, confidence %.0f%%", flag.Severity, flag.Confidence*100))
+			}
+			out.Explanation = "The statistical scan found prose patterns that often read as flat or formulaic: " + flag.Text + "."
+			out.SuggestedFix = "Vary sentence length and structure, and cut stock phrases in the affected passages."
+			return out, nil
+		}
+	case strings.HasPrefix(findingID, "sensitivity-"):
+		if i, ok := findingIndex(findingID, "sensitivity-", len(data.Sensitivity.Flags)); ok {
+			f := data.Sensitivity.Flags[i]
+			out.Kind = FindingSensitivity
+			out.Title = fmt.Sprintf("Sensitivity read: %s", f.Category)
+			out.Evidence = append(out.Evidence, fmt.Sprintf("Chapter %d (%s, %s): %q", f.Chapter, f.Title, f.Context, f.Quote))
+			out.Explanation = f.Rationale + " " + sensitivityDisclaimer
+			out.SuggestedFix = "Ask a sensitivity reader with relevant lived experience to review the passage in context."
+			return out, nil
+		}
+	case strings.HasPrefix(findingID, "rating-"):
+		dim := strings.TrimPrefix(findingID, "rating-")
+		for _, d := range data.Language.AgeRating.Dimensions {
+			if d.Dimension == dim {
+				explainRating(&out, d)
+				return out, nil
+			}
+		}
+	}
+	return FindingExplanation{}, fmt.Errorf("unknown finding %q", findingID)
+}
+
+func explainAIWindow(out *FindingExplanation, w aidetect.WindowReport, text string) {
+	out.Kind = FindingAIWindow
+	out.Title = fmt.Sprintf("AI-likelihood window %s (p_ai=%.2f)", w.WindowID, w.PAI)
+	if w.Location != nil {
+		out.Title = fmt.Sprintf("AI-likelihood passage %s (p_ai=%.2f)", w.Location.Label, w.PAI)
+	}
+	out.Evidence = append(out.Evidence, fmt.Sprintf("Words %d-%d, confidence %.2f", w.StartWord, w.EndWord, w.Confidence))
+	signals := []struct {
+		name  string
+		score *float64
+	}{
+		{"duplication", w.Signals.Duplication.Score},
+		{"style uniformity", w.Signals.StyleUniform.Score},
+		{"polish/cliche", w.Signals.PolishCliche.Score},
+		{"LM smoothness", w.Signals.LMSmoothness.Score},
+		{"LanguageTool", w.Signals.LanguageTool.Score},
+	}
+	strongest, strongestScore := "", -1.0
+	for _, s := range signals {
+		if s.score == nil {
+			continue
+		}
+		out.Evidence = append(out.Evidence, fmt.Sprintf("Signal %s: %.2f", s.name, *s.score))
+		if *s.score > strongestScore {
+			strongest, strongestScore = s.name, *s.score
+		}
+	}
+	for _, e := range w.TopEvidence {
+		line := e.Summary
+		if len(e.Spans) > 0 {
+			if excerpt := wordSpanExcerpt(text, e.Spans[0].Start, e.Spans[0].End, 40); excerpt != "" {
+				line += fmt.Sprintf(": %q", excerpt)
+			}
+		}
+		out.Evidence = append(out.Evidence, line)
+	}
+
+	explanation := fmt.Sprintf("This stretch of text scored %.0f%% on the AI-likelihood scale, driven mostly by %s.", w.PAI*100, strongest)
+	switch strongest {
+	case "duplication":
+		explanation += " Near-duplication compares overlapping 10-word phrases between windows; a Jaccard value is the share of those phrases two windows have in common (0.20 means about a fifth), and anything that high usually points at repeated or recycled passages."
+		out.SuggestedFix = "Find the matching passage named in the evidence and rewrite or cut one of the two versions."
+	case "style uniformity":
+		explanation += " Sentences here are unusually similar in length and shape, a rhythm typical of generated text."
+		out.SuggestedFix = "Mix short and long sentences and vary how they open."
+	case "polish/cliche":
+		explanation += " The passage leans on intensifiers and stock framing phrases."
+		out.SuggestedFix = "Replace stock phrases with concrete, specific detail."
+	default:
+		out.SuggestedFix = "Reread the passage aloud and revise anything that sounds generic."
+	}
+	out.Explanation = explanation
+}
+
+func explainRating(out *FindingExplanation, d RatingDimension) {
+	out.Kind = FindingContentRating
+	label := ratingLabels[d.Dimension]
+	if label == "" {
+		label = d.Dimension
+	}
+	out.Title = fmt.Sprintf("%s: %s (%s)", label, d.Level, d.Rating)
+	out.Evidence = append(out.Evidence, fmt.Sprintf("Score %d from %d instances (%s)", d.Score, d.Instances, d.Provider))
+	for _, e := range d.Evidence {
+		out.Evidence = append(out.Evidence, fmt.Sprintf("Chapter %d (%s): %q", e.Chapter, e.Title, e.Quote))
+	}
+	chapters := append([]int(nil), d.Chapters...)
+	sort.Ints(chapters)
+	out.Explanation = fmt.Sprintf("%s content was rated %q, which on its own places the book at %s.", label, strings.ToLower(d.Level), d.Rating)
+	out.SuggestedFix = "If the intended audience is younger, soften or remove the cited passages"
+	if len(chapters) > 0 {
+		parts := make([]string, 0, len(chapters))
+		for _, c := range chapters {
+			parts = append(parts, strconv.Itoa(c))
+		}
+		out.SuggestedFix += " in chapters " + strings.Join(parts, ", ")
+	}
+	out.SuggestedFix += "; otherwise keep them and carry the content warning."
+}
+
+func findingIndex(findingID, prefix string, n int) (int, bool) {
+	i, err := strconv.Atoi(strings.TrimPrefix(findingID, prefix))
+	if err != nil || i < 1 || i > n {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+// wordSpanExcerpt maps aidetect word offsets back onto whitespace-separated
+// words of the source text; offsets are approximate because the detector
+// strips punctuation first.
+func wordSpanExcerpt(text string, start, end, limit int) string {
+	words := strings.Fields(text)
+	if start < 0 || start >= len(words) {
+		return ""
+	}
+	end = min(end, len(words), start+limit)
+	return strings.Join(words[start:end], " ")
+}
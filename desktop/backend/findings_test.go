@@ -0,0 +1,49 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+)
+
+func TestExplainFindingFallsBackWithoutModel(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	dup := 0.82
+	style := 0.31
+	data := DashboardData{
+		AIReport: aidetect.Report{Windows: []aidetect.WindowReport{{
+			WindowID: "w-004",
+			PAI:      0.74,
+			Signals: aidetect.WindowSignals{
+				Duplication:  aidetect.DuplicationSignal{Score: &dup},
+				StyleUniform: aidetect.ScalarSignal{Score: &style},
+			},
+			TopEvidence: []aidetect.Evidence{{
+				Type:    "duplication",
+				Summary: "near-duplication with w-023 (jaccard=0.21)",
+				Spans:   []aidetect.EvidenceSpan{{Start: 1, End: 4}},
+			}},
+		}}},
+	}
+	out, err := ExplainFinding(data, "The tide came in slowly over grey sand.", "w-004")
+	if err != nil {
+		t.Fatalf("explain finding: %v", err)
+	}
+	if out.Kind != FindingAIWindow || out.Provider != "heuristic" {
+		t.Fatalf("expected heuristic AI window explanation, got %+v", out)
+	}
+	if !strings.Contains(out.Explanation, "Jaccard") || out.SuggestedFix == "" {
+		t.Fatalf("expected duplication explanation and fix, got %+v", out)
+	}
+	if !strings.Contains(strings.Join(out.Evidence, "\n"), `"tide came in"`) {
+		t.Fatalf("expected evidence excerpt from source text, got %v", out.Evidence)
+	}
+	if len(out.Notes) == 0 {
+		t.Fatal("expected note about unavailable model")
+	}
+
+	if _, err := ExplainFinding(data, "", "w-999"); err == nil {
+		t.Fatal("expected unknown finding error")
+	}
+}
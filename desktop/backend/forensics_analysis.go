@@ -13,60 +13,157 @@ var eyesPattern = regexp.MustCompile(`(?i)\b([A-Z][a-z]+)\b[^.\n]{0,45}\beyes\b[
 var agePattern = regexp.MustCompile(`(?i)\b([A-Z][a-z]+)\b[^.\n]{0,35}\b(?:age|aged)\b[^0-9\n]{0,10}([0-9]{1,3})\b`)
 var lifePattern = regexp.MustCompile(`(?i)\b([A-Z][a-z]+)\b[^.\n]{0,30}\b(dead|alive)\b`)
 
-func detectHeuristicContradictions(chapters []chapter) []forensics.Contradiction {
+// Location statements are case sensitive so that only a capitalized place
+// name ("Boston", "New York") is read as the place.
+const placeName = `([A-Z][a-z]+(?:[ -][A-Z][a-z]+)?)`
+
+var residencePattern = regexp.MustCompile(`\b([A-Z][a-z]+)\s+(?:still\s+|now\s+)?(?:lives|lived|has lived|had lived|is living|was living)\s+in\s+` + placeName)
+var homePattern = regexp.MustCompile(`\b([A-Z][a-z]+)(?:'s|’s)\s+(?:[a-z]+\s+)?(?:apartment|flat|house|home|condo|loft|cottage)\s+in\s+` + placeName)
+var movePattern = regexp.MustCompile(`\b([A-Z][a-z]+)\s+(?:had\s+)?(?:moved|relocated)\s+(?:back\s+)?to\s+` + placeName)
+var birthplacePattern = regexp.MustCompile(`\b([A-Z][a-z]+)\s+(?:was\s+)?born\s+in\s+` + placeName)
+
+// notPlaces are capitalized words that follow "in" without naming a place.
+var notPlaces = wordSet("January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December",
+	"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday", "Spring", "Summer", "Autumn", "Fall", "Winter")
+
+// builtinExtractors are the attributes every contradiction check tracks.
+// Within a chapter a later statement of an attribute replaces an earlier one.
+var builtinExtractors = []AttributeExtractor{
+	{Attribute: "eyes", re: eyesPattern, clean: lowerValue},
+	{Attribute: "age", re: agePattern},
+	{Attribute: "dead", re: lifePattern, clean: lifeValue},
+	{Attribute: "residence", re: residencePattern, clean: placeValue},
+	{Attribute: "residence", re: homePattern, clean: placeValue},
+	{Attribute: "residence", re: movePattern, clean: placeValue},
+	{Attribute: "birthplace", re: birthplacePattern, clean: placeValue},
+}
+
+// detectHeuristicContradictions compares the built-in attributes and the
+// custom ones across chapters. A custom attribute's contradictions carry its
+// configured severity.
+func detectHeuristicContradictions(chapters []chapter, aliases map[string]string, custom []AttributeExtractor) []forensics.Contradiction {
+	raw := forensics.DetectContradictions(chapterProfiles(chapters, aliases, custom))
+	out := filterContradictions(raw, chapterMoves(chapters, aliases))
+	for i := range out {
+		for _, e := range custom {
+			if out[i].Attribute == e.key() && e.Severity != "" {
+				out[i].Severity = e.Severity
+			}
+		}
+	}
+	return out
+}
+
+// chapterProfiles collects the statements each extractor finds about each
+// named character, built-in ones (eye colour, age, alive/dead, residence and
+// birthplace) first, one profile per character per chapter. Names found in
+// aliases are filed under the canonical character they map to. A move
+// ("Mara moved to Lisbon") sets the residence like any other statement;
+// filterContradictions forgives it.
+func chapterProfiles(chapters []chapter, aliases map[string]string, custom []AttributeExtractor) []forensics.ChapterProfile {
+	extractors := append(slices.Clip(builtinExtractors), custom...)
 	profiles := make([]forensics.ChapterProfile, 0, 256)
 	for _, ch := range chapters {
 		entityAttrs := map[string]map[string]string{}
-		for _, m := range eyesPattern.FindAllStringSubmatch(ch.text, -1) {
-			name := strings.TrimSpace(m[1])
-			if isIgnoredEntityName(name) {
-				continue
-			}
-			if entityAttrs[name] == nil {
-				entityAttrs[name] = map[string]string{}
+		for _, e := range extractors {
+			for _, m := range e.re.FindAllStringSubmatch(ch.text, -1) {
+				name, ok := resolveEntityName(m[e.nameGroup()], aliases)
+				if !ok {
+					continue
+				}
+				value, ok := e.cleanValue(m[e.valueGroup()])
+				if !ok {
+					continue
+				}
+				if entityAttrs[name] == nil {
+					entityAttrs[name] = map[string]string{}
+				}
+				entityAttrs[name][e.key()] = value
 			}
-			entityAttrs[name]["eyes"] = strings.ToLower(m[2])
 		}
-		for _, m := range agePattern.FindAllStringSubmatch(ch.text, -1) {
-			name := strings.TrimSpace(m[1])
-			if isIgnoredEntityName(name) {
-				continue
-			}
-			if entityAttrs[name] == nil {
-				entityAttrs[name] = map[string]string{}
-			}
-			entityAttrs[name]["age"] = m[2]
+		for name, attrs := range entityAttrs {
+			profiles = append(profiles, forensics.ChapterProfile{Chapter: ch.index, Name: name, Attributes: attrs})
 		}
-		for _, m := range lifePattern.FindAllStringSubmatch(ch.text, -1) {
-			name := strings.TrimSpace(m[1])
-			if isIgnoredEntityName(name) {
+	}
+	return profiles
+}
+
+// chapterMoves records, per lower-cased character name, the chapter of each
+// move and the place moved to.
+func chapterMoves(chapters []chapter, aliases map[string]string) map[string]map[string][]int {
+	moves := map[string]map[string][]int{}
+	for _, ch := range chapters {
+		for _, m := range movePattern.FindAllStringSubmatch(ch.text, -1) {
+			name, ok := resolveEntityName(m[1], aliases)
+			place, isPlace := placeValue(m[2])
+			if !ok || !isPlace {
 				continue
 			}
-			if entityAttrs[name] == nil {
-				entityAttrs[name] = map[string]string{}
+			key := strings.ToLower(name)
+			if moves[key] == nil {
+				moves[key] = map[string][]int{}
 			}
-			entityAttrs[name]["dead"] = strconv.FormatBool(strings.EqualFold(m[2], "dead"))
-		}
-		for name, attrs := range entityAttrs {
-			profiles = append(profiles, forensics.ChapterProfile{Chapter: ch.index, Name: name, Attributes: attrs})
+			moves[key][strings.ToLower(place)] = append(moves[key][strings.ToLower(place)], ch.index)
 		}
 	}
-	raw := forensics.DetectContradictions(profiles)
-	return filterContradictions(raw)
+	return moves
+}
+
+func resolveEntityName(name string, aliases map[string]string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if isIgnoredEntityName(name) {
+		return "", false
+	}
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+	return name, true
+}
+
+func lowerValue(v string) (string, bool) {
+	return strings.ToLower(v), true
+}
+
+// lifeValue records "dead" as true and "alive" as false.
+func lifeValue(v string) (string, bool) {
+	return strconv.FormatBool(strings.EqualFold(v, "dead")), true
 }
 
-func filterContradictions(raw []forensics.Contradiction) []forensics.Contradiction {
+// placeValue rejects a month, weekday or season read as a place ("born in
+// May").
+func placeValue(place string) (string, bool) {
+	if first, _, _ := strings.Cut(place, " "); notPlaces[first] {
+		return "", false
+	}
+	return place, true
+}
+
+// filterContradictions drops changes a story allows: an alive character
+// later dead, and a residence the character moved to in between.
+func filterContradictions(raw []forensics.Contradiction, moves map[string]map[string][]int) []forensics.Contradiction {
 	out := make([]forensics.Contradiction, 0, len(raw))
 	for _, c := range raw {
 		// Allow natural progression from alive(false dead) to dead(true dead).
 		if c.Attribute == "dead" && strings.EqualFold(c.ValueA, "false") && strings.EqualFold(c.ValueB, "true") && c.ChapterB > c.ChapterA {
 			continue
 		}
+		if c.Attribute == "residence" && movedBetween(moves[strings.ToLower(c.EntityName)][strings.ToLower(c.ValueB)], c.ChapterA, c.ChapterB) {
+			continue
+		}
 		out = append(out, c)
 	}
 	return out
 }
 
+func movedBetween(chapters []int, after, upTo int) bool {
+	for _, ch := range chapters {
+		if ch > after && ch <= upTo {
+			return true
+		}
+	}
+	return false
+}
+
 func isIgnoredEntityName(name string) bool {
 	if name == "" {
 		return true
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectHeuristicContradictionsTracksLocations(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "John lives in Boston with his sister. Mara was born in Lisbon in May."},
+		{index: 2, text: "Rain hammered John's apartment in Chicago all night."},
+		{index: 3, text: "Mara was born in Porto, her mother said."},
+	}
+	got := detectHeuristicContradictions(chapters, nil, nil)
+	byAttribute := map[string]int{}
+	for _, c := range got {
+		byAttribute[c.Attribute]++
+		switch c.Attribute {
+		case "residence":
+			if c.EntityName != "john" || c.ValueA != "Boston" || c.ValueB != "Chicago" || c.ChapterA != 1 || c.ChapterB != 2 || c.Severity != "MED" {
+				t.Fatalf("unexpected residence contradiction %+v", c)
+			}
+		case "birthplace":
+			if c.EntityName != "mara" || c.ValueA != "Lisbon" || c.ValueB != "Porto" || c.Severity != "MED" {
+				t.Fatalf("unexpected birthplace contradiction %+v", c)
+			}
+		}
+	}
+	if len(got) != 2 || byAttribute["residence"] != 1 || byAttribute["birthplace"] != 1 {
+		t.Fatalf("expected a residence and a birthplace contradiction, got %+v", got)
+	}
+}
+
+func TestDetectHeuristicContradictionsAllowsMoves(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "John lives in Boston with his sister."},
+		{index: 2, text: "That spring John moved to Chicago for the job."},
+		{index: 4, text: "Snow buried John's apartment in Chicago."},
+	}
+	if got := detectHeuristicContradictions(chapters, nil, nil); len(got) != 0 {
+		t.Fatalf("expected a move not reported as a contradiction, got %+v", got)
+	}
+	if _, ok := placeValue("May"); ok {
+		t.Fatal("expected a month not read as a place")
+	}
+}
+
+func TestCustomAttributeExtractorsFeedContradictions(t *testing.T) {
+	root := t.TempDir()
+	if custom, err := LoadAttributeExtractors(root); err != nil || custom != nil {
+		t.Fatalf("expected no custom attributes without a config, got %+v (%v)", custom, err)
+	}
+	configs := filepath.Join(root, "configs")
+	if err := os.MkdirAll(configs, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(configs, "contradiction_attributes.json")
+	config := `{"attributes": [
+		{"attribute": "Hair", "severity": "med", "pattern": "(?P<name>[A-Z][a-z]+)'s (?P<value>red|blond|black) hair"},
+		{"attribute": "vehicle", "pattern": "([A-Z][a-z]+) drove (?:a|an|his|her) ([a-z]+)"}
+	]}`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := LoadAttributeExtractors(root)
+	if err != nil || len(custom) != 2 {
+		t.Fatalf("expected two custom attributes, got %+v (%v)", custom, err)
+	}
+	chapters := []chapter{
+		{index: 1, text: "Mara's red hair caught the light. John drove a truck to the dock."},
+		{index: 2, text: "Mara's black hair was wet with rain. John drove his truck home."},
+		{index: 3, text: "John drove a sedan north."},
+	}
+	got := detectHeuristicContradictions(chapters, nil, custom)
+	byAttribute := map[string]string{}
+	for _, c := range got {
+		byAttribute[c.Attribute] = c.Severity
+	}
+	if len(got) != 2 || byAttribute["hair"] != "MED" || byAttribute["vehicle"] != "LOW" {
+		t.Fatalf("expected hair and vehicle contradictions with their severities, got %+v", got)
+	}
+
+	for _, bad := range []string{
+		`{"attributes": [{"attribute": "job", "pattern": "([A-Z][a-z]+) works"}]}`,
+		`{"attributes": [{"attribute": "job", "pattern": "([A-Z][a-z]+ works as (\\w+)", "severity": "LOW"}]}`,
+		`{"attributes": [{"attribute": "job", "pattern": "([A-Z][a-z]+) works as (\\w+)", "severity": "URGENT"}]}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAttributeExtractors(root); err == nil {
+			t.Fatalf("expected %s rejected", bad)
+		}
+	}
+}
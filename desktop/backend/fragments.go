@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	HealthIssueFragment = "fragment"
+
+	// minChapterRunes is the length below which a chapter reads as a pasted
+	// leftover, when the manuscript's median chapter is at least
+	// fragmentMedianRatio times as long.
+	minChapterRunes     = 1000
+	fragmentMedianRatio = 3
+	// minCutLineWords keeps short closing labels such as "The End" from
+	// counting as a sentence cut off at a chapter break.
+	minCutLineWords = 4
+	// openingWords is how much of two chapters must match before their
+	// openings count as duplicates.
+	openingWords = 30
+	// fragmentContextWords bounds the text quoted for each side of an issue.
+	fragmentContextWords = 25
+)
+
+// fragmentIssue is one stitching artifact: a stub chapter, a chapter cut off
+// mid-sentence, or two chapters opening alike and then diverging. chapterB
+// is 0 when only one chapter is involved.
+type fragmentIssue struct {
+	chapterA int
+	chapterB int
+	severity string
+	problem  string
+	contextA string
+	contextB string
+}
+
+// checkFragments looks for what manual stitching leaves behind. Only chapters
+// opened by a heading line are judged, so splits made by word count or at a
+// passing mention of "Chapter 3" are not mistaken for cuts.
+func checkFragments(text string, chapters []chapter) []fragmentIssue {
+	headed := map[int]string{}
+	for _, h := range chapterHeadings(text, chapters) {
+		if h.chapter > 0 {
+			headed[h.chapter] = h.line
+		}
+	}
+	if len(headed) < 2 {
+		return nil
+	}
+	bodies := make([]string, len(chapters))
+	lengths := make([]float64, 0, len(chapters))
+	for i, ch := range chapters {
+		bodies[i] = chapterBody(ch, headed[ch.index])
+		if headed[ch.index] != "" {
+			lengths = append(lengths, float64(utf8.RuneCountInString(bodies[i])))
+		}
+	}
+
+	out := []fragmentIssue{}
+	if median(lengths) >= minChapterRunes*fragmentMedianRatio {
+		for i, ch := range chapters {
+			n := utf8.RuneCountInString(bodies[i])
+			if headed[ch.index] == "" || n >= minChapterRunes {
+				continue
+			}
+			problem := fmt.Sprintf("Ch%d is only %d characters long; it may be a leftover pasted from another draft", ch.index, n)
+			if n == 0 {
+				problem = fmt.Sprintf("Ch%d has a heading but no text", ch.index)
+			}
+			out = append(out, fragmentIssue{chapterA: ch.index, severity: "MED", problem: problem, contextA: headed[ch.index], contextB: firstWords(bodies[i], fragmentContextWords)})
+		}
+	}
+
+	for i := 0; i+1 < len(chapters); i++ {
+		next := chapters[i+1]
+		if headed[next.index] == "" {
+			continue
+		}
+		lines := nonEmptyLines(bodies[i])
+		if len(lines) == 0 {
+			continue
+		}
+		last := lines[len(lines)-1]
+		r, _ := utf8.DecodeLastRuneInString(last)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(",;:-", r) {
+			continue
+		}
+		if len(strings.Fields(last)) < minCutLineWords {
+			continue
+		}
+		ending := lastWords(last, fragmentContextWords)
+		opening := firstWords(bodies[i+1], fragmentContextWords)
+		first, _ := utf8.DecodeRuneInString(opening)
+		if unicode.IsLower(first) {
+			out = append(out, fragmentIssue{chapterA: chapters[i].index, chapterB: next.index, severity: "HIGH", problem: fmt.Sprintf("Ch%d stops mid-sentence and Ch%d picks it up after the heading; the break may have been pasted into a sentence", chapters[i].index, next.index), contextA: ending, contextB: opening})
+			continue
+		}
+		out = append(out, fragmentIssue{chapterA: chapters[i].index, severity: "MED", problem: fmt.Sprintf("Ch%d ends mid-sentence", chapters[i].index), contextA: ending, contextB: headed[next.index]})
+	}
+
+	seen := map[string]int{}
+	for i, ch := range chapters {
+		words := foldedFields(bodies[i])
+		if headed[ch.index] == "" || len(words) < openingWords {
+			continue
+		}
+		key := strings.Join(words[:openingWords], " ")
+		j, dup := seen[key]
+		if !dup {
+			seen[key] = i
+			continue
+		}
+		earlier := foldedFields(bodies[j])
+		shared := 0
+		for shared < len(words) && shared < len(earlier) && words[shared] == earlier[shared] {
+			shared++
+		}
+		if shared == len(words) && shared == len(earlier) {
+			// Whole repeated chapters are the duplication signals' to report.
+			continue
+		}
+		out = append(out, fragmentIssue{
+			chapterA: chapters[j].index,
+			chapterB: ch.index,
+			severity: "HIGH",
+			problem:  fmt.Sprintf("Ch%d and Ch%d open with the same %d words, then go different ways; one may be an earlier draft left in", chapters[j].index, ch.index, shared),
+			contextA: wordsFrom(bodies[j], shared),
+			contextB: wordsFrom(bodies[i], shared),
+		})
+	}
+	return out
+}
+
+// chapterBody is the chapter's text without its heading line.
+func chapterBody(ch chapter, heading string) string {
+	text := strings.TrimSpace(ch.text)
+	if heading != "" && strings.HasPrefix(text, heading) {
+		text = strings.TrimSpace(text[len(heading):])
+	}
+	return text
+}
+
+// foldedFields splits s into lowercased words with surrounding punctuation
+// removed, so the same sentence compares equal however it is quoted.
+func foldedFields(s string) []string {
+	fields := strings.Fields(s)
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.ToLower(strings.TrimFunc(f, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// wordsFrom quotes s from its from-th folded word, a little before where two
+// chapters part ways.
+func wordsFrom(s string, from int) string {
+	fields := strings.Fields(s)
+	start := 0
+	for k, seen := 0, 0; k < len(fields); k++ {
+		if seen >= max(0, from-5) {
+			start = k
+			break
+		}
+		if len(foldedFields(fields[k])) > 0 {
+			seen++
+		}
+	}
+	quoted := firstWords(strings.Join(fields[start:], " "), fragmentContextWords)
+	if start > 0 {
+		quoted = "…" + quoted
+	}
+	return quoted
+}
+
+func lastWords(s string, n int) string {
+	words := strings.Fields(s)
+	if len(words) > n {
+		words = words[len(words)-n:]
+	}
+	return strings.Join(words, " ")
+}
+
+// fragmentIssues turns fragments into health issues numbered after the
+// existing issues.
+func fragmentIssues(fragments []fragmentIssue, existing int) []HealthIssue {
+	out := make([]HealthIssue, 0, len(fragments))
+	for i, f := range fragments {
+		chapterB := f.chapterB
+		if chapterB == 0 {
+			chapterB = f.chapterA
+		}
+		out = append(out, HealthIssue{
+			ID:          fmt.Sprintf("issue-%03d", existing+i+1),
+			Kind:        HealthIssueFragment,
+			Entity:      "Stitching",
+			Severity:    f.severity,
+			Description: f.problem,
+			ChapterA:    f.chapterA,
+			ChapterB:    chapterB,
+			ContextA:    f.contextA,
+			ContextB:    f.contextB,
+		})
+	}
+	return out
+}
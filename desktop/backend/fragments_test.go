@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckFragmentsFindsStitchingLeftovers(t *testing.T) {
+	prose := func(seed string) string {
+		lines := make([]string, 0, 40)
+		for i := 0; i < 40; i++ {
+			lines = append(lines, fmt.Sprintf("The %s road bent past marker %d and the wind kept its own counsel there.", seed, i))
+		}
+		return strings.Join(lines, " ")
+	}
+	opening := "Mara found the letter under the loose board in the kitchen, folded twice and sealed with wax the color of old blood, and she knew before she read a word who had written it."
+	text := strings.Join([]string{
+		"Chapter 1", prose("north"),
+		"Chapter 2", prose("east") + " She turned back toward the",
+		"Chapter 3", "harbor and did not look at him. " + prose("south"),
+		"Chapter 4", opening + " " + prose("west"),
+		"Chapter 5", "A gull cried. The boat was gone.",
+		"Chapter 6", opening + " She burned it unread. " + prose("river"),
+		"Chapter 7", prose("hill"),
+	}, "\n")
+	fragments := checkFragments(text, splitChapters(text))
+	problems := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		problems = append(problems, f.severity+" "+f.problem)
+	}
+	want := []string{
+		"MED Ch5 is only 32 characters long; it may be a leftover pasted from another draft",
+		"HIGH Ch2 stops mid-sentence and Ch3 picks it up after the heading; the break may have been pasted into a sentence",
+		"HIGH Ch4 and Ch6 open with the same 34 words, then go different ways; one may be an earlier draft left in",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected fragments:\n%s", strings.Join(problems, "\n"))
+	}
+	if !strings.HasSuffix(fragments[1].contextA, "counsel there. She turned back toward the") || !strings.HasPrefix(fragments[1].contextB, "harbor and") {
+		t.Fatalf("expected the cut sentence on both sides, got %+v", fragments[1])
+	}
+	if !strings.HasPrefix(fragments[2].contextB, "…word who had written it. She burned it unread.") {
+		t.Fatalf("expected the divergence quoted, got %q", fragments[2].contextB)
+	}
+
+	health := fragmentIssues(fragments, 4)
+	if health[0].ID != "issue-005" || health[0].Kind != HealthIssueFragment || health[0].ChapterB != 5 || health[2].ChapterA != 4 || health[2].ChapterB != 6 {
+		t.Fatalf("unexpected health issues %+v", health)
+	}
+}
+
+func TestCheckFragmentsLeavesUnheadedSplitsAlone(t *testing.T) {
+	text := strings.Repeat("She walked on and on without a pause or a full stop ", 1200)
+	if fragments := checkFragments(text, splitChapters(text)); len(fragments) != 0 {
+		t.Fatalf("expected word-count splits not to be judged, got %+v", fragments)
+	}
+	short := "Chapter 1\nShe left.\nChapter 2\nHe stayed.\nChapter 3\nThey wrote."
+	if fragments := checkFragments(short, splitChapters(short)); len(fragments) != 0 {
+		t.Fatalf("expected uniformly short chapters not to be flagged, got %+v", fragments)
+	}
+}
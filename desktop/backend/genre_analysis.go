@@ -3,12 +3,14 @@ package backend
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,9 +21,10 @@ var genreKeywords = map[string][]string{
 	"Fantasy":  {"magic", "kingdom", "sword", "dragon", "spell", "prophecy", "portal", "myth"},
 	"Sci-Fi":   {"ship", "quantum", "android", "orbit", "signal", "colony", "lab", "protocol", "variant"},
 	"Literary": {"memory", "silence", "family", "identity", "childhood", "grief", "dream", "voice"},
+	"Horror":   {"haunted", "corpse", "dread", "terror", "demon", "possessed", "monster", "ghost"},
 }
 
-var genreOrder = []string{"Thriller", "Mystery", "Romance", "Fantasy", "Sci-Fi", "Literary"}
+var genreOrder = []string{"Thriller", "Mystery", "Romance", "Fantasy", "Sci-Fi", "Literary", "Horror"}
 
 func scoreGenresForText(text string) []GenreScore {
 	lower := strings.ToLower(text)
@@ -42,23 +45,32 @@ type genreDecision struct {
 	Scores    []GenreScore
 }
 
+// maxGenreModelFailures consecutive failed chapters send the rest of the
+// run to the keyword heuristic.
+const maxGenreModelFailures = 3
+
+// errGenreHeuristic tells the genre limiter a chapter fell back to the
+// heuristic, which counts as a failed model call.
+var errGenreHeuristic = errors.New("genre model unavailable")
+
 type genreClassifier struct {
 	endpoint string
 	model    string
 	client   *http.Client
 
+	// mu guards the fields below; chapters are classified concurrently.
+	mu                  sync.Mutex
 	consecutiveFailures int
 	lastErr             string
+	// lastFailure is the last failed model call, for the fallback reason.
+	lastFailure error
+	// modelTime and modelCalls accumulate Ollama latency for progress detail.
+	modelTime  time.Duration
+	modelCalls int
 }
 
 func newGenreClassifier() *genreClassifier {
-	model := strings.TrimSpace(os.Getenv("OLLAMA_GENRE_MODEL"))
-	if model == "" {
-		model = strings.TrimSpace(os.Getenv("OLLAMA_LANGUAGE_MODEL"))
-	}
-	if model == "" {
-		model = "llama3.1:8b"
-	}
+	model := ollamaModel("OLLAMA_GENRE_MODEL", "OLLAMA_LANGUAGE_MODEL")
 	return &genreClassifier{
 		endpoint: ollamaGenerateEndpoint(),
 		model:    model,
@@ -66,30 +78,50 @@ func newGenreClassifier() *genreClassifier {
 	}
 }
 
+func (g *genreClassifier) useModel() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.consecutiveFailures < maxGenreModelFailures
+}
+
 func (g *genreClassifier) classifyChapter(ch chapter) genreDecision {
 	// Keep trying Ollama per chapter; only short-circuit after repeated hard failures.
-	if g.consecutiveFailures < 3 {
+	if g.useModel() {
 		sample := buildGenreSample(ch.text)
 		for attempt := 0; attempt < 3; attempt++ {
-			if llm, err := g.classifyWithOllama(sample); err == nil {
+			callStarted := time.Now()
+			llm, err := g.classifyWithOllama(sample)
+			g.mu.Lock()
+			g.modelTime += time.Since(callStarted)
+			g.modelCalls++
+			if err == nil {
 				g.consecutiveFailures = 0
+			} else {
+				g.lastErr = err.Error()
+				g.lastFailure = err
+			}
+			g.mu.Unlock()
+			if err == nil {
 				return genreDecision{
 					Provider:  "ollama:" + g.model,
 					Reasoning: llm.Reasoning,
 					Scores:    llm.Scores,
 				}
-			} else {
-				g.lastErr = err.Error()
 			}
 		}
+		g.mu.Lock()
 		g.consecutiveFailures++
+		g.mu.Unlock()
 	}
 
 	scores := scoreGenresForText(ch.text)
 	topName, topScore := topGenre(scores)
 	reason := fmt.Sprintf("Heuristic fallback using keyword frequencies across full chapter text (top genre=%s %.2f).", topName, topScore)
-	if g.lastErr != "" {
-		reason += " Ollama unavailable: " + g.lastErr
+	g.mu.Lock()
+	lastErr := g.lastErr
+	g.mu.Unlock()
+	if lastErr != "" {
+		reason += " Ollama unavailable: " + lastErr
 	}
 	return genreDecision{
 		Provider:  "heuristic",
@@ -98,8 +130,42 @@ func (g *genreClassifier) classifyChapter(ch chapter) genreDecision {
 	}
 }
 
+// stop sends the remaining chapters to the heuristic without calling the
+// model, as after repeated model failures.
+func (g *genreClassifier) stop(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.consecutiveFailures = maxGenreModelFailures
+	g.lastErr = reason
+}
+
+// fallback is why chapters fell back to the heuristic, or nil when no model
+// call failed; a stopped classifier is not a fallback.
+func (g *genreClassifier) fallback() *FallbackReason {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.lastFailure == nil {
+		return nil
+	}
+	return newFallbackReason(ServiceOllama, g.model, g.lastFailure)
+}
+
+// latency describes model time spent so far, or "" before the first call.
+func (g *genreClassifier) latency() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.modelCalls == 0 {
+		return ""
+	}
+	avg := g.modelTime / time.Duration(g.modelCalls)
+	return fmt.Sprintf("model time %s, %s per call", g.modelTime.Round(time.Second), avg.Round(100*time.Millisecond))
+}
+
 type ollamaGenreResponse struct {
 	Response string `json:"response"`
+	// EvalCount and EvalDuration (nanoseconds) time the generated tokens.
+	EvalCount    int   `json:"eval_count"`
+	EvalDuration int64 `json:"eval_duration"`
 }
 
 type genreLLMResult struct {
@@ -111,7 +177,7 @@ type genreLLMResult struct {
 func (g *genreClassifier) classifyWithOllama(sample string) (genreDecision, error) {
 	prompt := "You are a senior fiction editor. Classify manuscript excerpt genre mixture." +
 		" Return JSON only with keys: top_genre, reasoning, genre_scores." +
-		" genre_scores must include exactly these keys with 0-1 floats that sum to 1: Thriller, Mystery, Romance, Fantasy, Sci-Fi, Literary." +
+		" genre_scores must include exactly these keys with 0-1 floats that sum to 1: " + strings.Join(genreOrder, ", ") + "." +
 		" reasoning should be concise and cite observed signals.\n\nTEXT:\n" + sample
 
 	payload := map[string]any{
@@ -129,16 +195,17 @@ func (g *genreClassifier) classifyWithOllama(sample string) (genreDecision, erro
 	body, _ := io.ReadAll(resp.Body)
 	_ = resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return genreDecision{}, fmt.Errorf("status %d", resp.StatusCode)
+		return genreDecision{}, statusError(resp.StatusCode, body)
 	}
 
 	var out ollamaGenreResponse
 	if err := json.Unmarshal(body, &out); err != nil {
 		return genreDecision{}, err
 	}
+	noteInference(g.model, out.EvalCount, out.EvalDuration)
 	jsonText := extractJSONObject(out.Response)
 	if jsonText == "" {
-		return genreDecision{}, fmt.Errorf("no JSON in model response")
+		return genreDecision{}, errNoJSON
 	}
 
 	var parsed genreLLMResult
@@ -176,6 +243,17 @@ func fillMissingGenres(raw map[string]float64) map[string]float64 {
 	return out
 }
 
+// ollamaModel returns the first model named by the given env vars, falling
+// back to the default local model.
+func ollamaModel(envKeys ...string) string {
+	for _, key := range envKeys {
+		if model := strings.TrimSpace(os.Getenv(key)); model != "" {
+			return model
+		}
+	}
+	return "llama3.1:8b"
+}
+
 func ollamaGenerateEndpoint() string {
 	base := strings.TrimSpace(os.Getenv("OLLAMA_URL"))
 	if base == "" {
@@ -187,6 +265,39 @@ func ollamaGenerateEndpoint() string {
 	return strings.TrimSuffix(base, "/") + "/api/generate"
 }
 
+// generateOllamaJSON runs a deterministic JSON-format prompt against the local
+// model and decodes the first JSON object of the response into out.
+func generateOllamaJSON(model, prompt string, out any) error {
+	payload := map[string]any{
+		"model":   model,
+		"prompt":  prompt,
+		"stream":  false,
+		"format":  "json",
+		"options": map[string]any{"temperature": 0},
+	}
+	raw, _ := json.Marshal(payload)
+	client := &http.Client{Timeout: 120 * time.Second}
+	resp, err := client.Post(ollamaGenerateEndpoint(), "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(resp.StatusCode, body)
+	}
+	var generated ollamaResponse
+	if err := json.Unmarshal(body, &generated); err != nil {
+		return err
+	}
+	noteInference(model, generated.EvalCount, generated.EvalDuration)
+	jsonText := extractJSONObject(generated.Response)
+	if jsonText == "" {
+		return errNoJSON
+	}
+	return json.Unmarshal([]byte(jsonText), out)
+}
+
 func buildGenreSample(text string) string {
 	words := strings.Fields(text)
 	if len(words) == 0 {
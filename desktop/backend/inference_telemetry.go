@@ -0,0 +1,268 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Where /api/ps showed a model loaded when it served a request.
+const (
+	ProcessorGPU     = "gpu"
+	ProcessorCPU     = "cpu"
+	ProcessorMixed   = "mixed"
+	ProcessorUnknown = "unknown"
+)
+
+// placementRecheck is how long a model's placement is trusted before /api/ps
+// is asked again; Ollama only moves a model when it reloads it.
+const placementRecheck = 30 * time.Second
+
+// ModelInference is how Ollama served one model's generate requests during a
+// run.
+type ModelInference struct {
+	Model    string `json:"model"`
+	Requests int    `json:"requests"`
+	// GPURequests, CPURequests and MixedRequests count requests by where the
+	// model was loaded; a request whose placement could not be read counts
+	// in none of them.
+	GPURequests   int `json:"gpuRequests"`
+	CPURequests   int `json:"cpuRequests"`
+	MixedRequests int `json:"mixedRequests"`
+	// Processor sums the counts up as gpu, cpu, mixed or unknown.
+	Processor string `json:"processor"`
+	// VRAMShare is the share of the model held in GPU memory at the last
+	// check.
+	VRAMShare       float64 `json:"vramShare"`
+	EvalTokens      int     `json:"evalTokens"`
+	TokensPerSecond float64 `json:"tokensPerSecond"`
+	evalNanos       int64
+}
+
+// activeInference holds the recorders of the running analyses. Model calls are
+// made deep inside the stages, so recorders are reached through this rather
+// than threaded through every call. When queued runs overlap, each records
+// every call made while it runs: placement and throughput describe the Ollama
+// they shared.
+var activeInference sync.Map
+
+// inferenceTelemetry records the eval counts and timings Ollama returns with
+// each generate request, and where /api/ps shows the model loaded.
+type inferenceTelemetry struct {
+	psURL  string
+	client *http.Client
+	// onFallback is called once per model the first time it is found
+	// running on the CPU, in whole or in part.
+	onFallback func(ModelInference)
+
+	mu        sync.Mutex
+	models    map[string]*ModelInference
+	order     []string
+	placement map[string]modelPlacement
+	warned    map[string]bool
+}
+
+type modelPlacement struct {
+	processor string
+	share     float64
+	checked   time.Time
+}
+
+func newInferenceTelemetry(psURL string, onFallback func(ModelInference)) *inferenceTelemetry {
+	return &inferenceTelemetry{
+		psURL:      psURL,
+		client:     &http.Client{Timeout: 2 * time.Second},
+		onFallback: onFallback,
+		models:     map[string]*ModelInference{},
+		placement:  map[string]modelPlacement{},
+		warned:     map[string]bool{},
+	}
+}
+
+// startInferenceTelemetry makes a recorder the run's active one.
+func startInferenceTelemetry(onFallback func(ModelInference)) *inferenceTelemetry {
+	t := newInferenceTelemetry(ollamaPSEndpoint(), onFallback)
+	activeInference.Store(t, struct{}{})
+	return t
+}
+
+// stop detaches the recorder and returns one entry per model, in the order
+// they were first used.
+func (t *inferenceTelemetry) stop() []ModelInference {
+	activeInference.Delete(t)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]ModelInference, 0, len(t.order))
+	for _, name := range t.order {
+		out = append(out, *t.models[name])
+	}
+	return out
+}
+
+// noteInference records a generate response for the active runs, if any.
+func noteInference(model string, evalCount int, evalNanos int64) {
+	activeInference.Range(func(key, _ any) bool {
+		key.(*inferenceTelemetry).record(model, evalCount, evalNanos)
+		return true
+	})
+}
+
+func (t *inferenceTelemetry) record(model string, evalCount int, evalNanos int64) {
+	place := t.lookupPlacement(model)
+	t.mu.Lock()
+	m := t.models[model]
+	if m == nil {
+		m = &ModelInference{Model: model, Processor: ProcessorUnknown}
+		t.models[model] = m
+		t.order = append(t.order, model)
+	}
+	m.Requests++
+	switch place.processor {
+	case ProcessorGPU:
+		m.GPURequests++
+	case ProcessorCPU:
+		m.CPURequests++
+	case ProcessorMixed:
+		m.MixedRequests++
+	}
+	if place.processor != ProcessorUnknown {
+		m.VRAMShare = place.share
+	}
+	m.Processor = summarizeProcessor(*m)
+	if evalCount > 0 && evalNanos > 0 {
+		m.EvalTokens += evalCount
+		m.evalNanos += evalNanos
+		m.TokensPerSecond = float64(m.EvalTokens) / (float64(m.evalNanos) / float64(time.Second))
+	}
+	fallback := (place.processor == ProcessorCPU || place.processor == ProcessorMixed) && !t.warned[model]
+	if fallback {
+		t.warned[model] = true
+	}
+	snapshot := *m
+	t.mu.Unlock()
+	if fallback && t.onFallback != nil {
+		t.onFallback(snapshot)
+	}
+}
+
+// lookupPlacement returns where the model is loaded, asking /api/ps when the
+// last answer is stale.
+func (t *inferenceTelemetry) lookupPlacement(model string) modelPlacement {
+	t.mu.Lock()
+	cached, ok := t.placement[model]
+	t.mu.Unlock()
+	if ok && time.Since(cached.checked) < placementRecheck {
+		return cached
+	}
+	place := modelPlacement{processor: ProcessorUnknown, checked: time.Now()}
+	if loaded, err := t.loadedModels(); err == nil {
+		for _, l := range loaded {
+			if !sameModel(l.Name, model) && !sameModel(l.Model, model) {
+				continue
+			}
+			place.share = 0
+			if l.Size > 0 {
+				place.share = min(1, float64(l.SizeVRAM)/float64(l.Size))
+			}
+			switch {
+			case l.SizeVRAM <= 0:
+				place.processor = ProcessorCPU
+			case l.SizeVRAM >= l.Size:
+				place.processor = ProcessorGPU
+			default:
+				place.processor = ProcessorMixed
+			}
+			break
+		}
+	}
+	t.mu.Lock()
+	t.placement[model] = place
+	t.mu.Unlock()
+	return place
+}
+
+type loadedModel struct {
+	Name     string `json:"name"`
+	Model    string `json:"model"`
+	Size     int64  `json:"size"`
+	SizeVRAM int64  `json:"size_vram"`
+}
+
+func (t *inferenceTelemetry) loadedModels() ([]loadedModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.psURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var out struct {
+		Models []loadedModel `json:"models"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	return out.Models, nil
+}
+
+// sameModel compares model names, reading a name without a tag as :latest.
+func sameModel(a, b string) bool {
+	tagged := func(name string) string {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !strings.Contains(name, ":") {
+			name += ":latest"
+		}
+		return name
+	}
+	return tagged(a) != "" && tagged(a) == tagged(b)
+}
+
+func summarizeProcessor(m ModelInference) string {
+	switch {
+	case m.GPURequests+m.CPURequests+m.MixedRequests == 0:
+		return ProcessorUnknown
+	case m.CPURequests+m.MixedRequests == 0:
+		return ProcessorGPU
+	case m.GPURequests+m.MixedRequests == 0:
+		return ProcessorCPU
+	default:
+		return ProcessorMixed
+	}
+}
+
+func ollamaPSEndpoint() string {
+	return strings.TrimSuffix(ollamaGenerateEndpoint(), "/api/generate") + "/api/ps"
+}
+
+// describeInference is the log detail for one model's telemetry.
+func describeInference(m ModelInference) string {
+	return fmt.Sprintf("model=%s requests=%d processor=%s gpu=%d cpu=%d mixed=%d vram=%.0f%% eval_tokens=%d tokens_per_sec=%.1f",
+		m.Model, m.Requests, m.Processor, m.GPURequests, m.CPURequests, m.MixedRequests, m.VRAMShare*100, m.EvalTokens, m.TokensPerSecond)
+}
+
+// inferenceFallbackWarning explains what a model on the CPU means for the
+// run's profile.
+func inferenceFallbackWarning(m ModelInference, profile string) string {
+	where := "entirely on the CPU"
+	if m.CPURequests == 0 {
+		where = fmt.Sprintf("partly on the CPU (%.0f%% in GPU memory)", m.VRAMShare*100)
+	}
+	advice := "model stages will be slow, and Deep Dive runs impractically so; free GPU memory or choose a smaller model"
+	if profile == ProfileDeep {
+		advice = "this Deep Dive scores every AI window with the model and may take hours; free GPU memory, choose a smaller model or run Standard"
+	}
+	return fmt.Sprintf("%s is running %s: %s", m.Model, where, advice)
+}
@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// psServer answers /api/ps with the given loaded models and counts the calls.
+func psServer(t *testing.T, models []loadedModel, calls *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": models})
+	}))
+}
+
+func TestInferenceTelemetryWarnsOnceWhenModelRunsOnCPU(t *testing.T) {
+	calls := 0
+	server := psServer(t, []loadedModel{
+		{Name: "llama3.2:latest", Size: 4 << 30, SizeVRAM: 0},
+		{Name: "gemma3:4b", Size: 4 << 30, SizeVRAM: 4 << 30},
+	}, &calls)
+	defer server.Close()
+	var warned []ModelInference
+	telemetry := newInferenceTelemetry(server.URL, func(m ModelInference) { warned = append(warned, m) })
+
+	telemetry.record("llama3.2", 50, int64(5*time.Second))
+	telemetry.record("llama3.2", 50, int64(5*time.Second))
+	telemetry.record("gemma3:4b", 100, int64(time.Second))
+	got := telemetry.stop()
+
+	if calls != 2 {
+		t.Fatalf("expected one /api/ps lookup per model, got %d", calls)
+	}
+	if len(got) != 2 || got[0].Model != "llama3.2" || got[1].Model != "gemma3:4b" {
+		t.Fatalf("expected both models in first-use order, got %+v", got)
+	}
+	if got[0].Processor != ProcessorCPU || got[0].CPURequests != 2 || got[0].TokensPerSecond != 10 {
+		t.Fatalf("expected llama3.2 on the CPU at 10 tokens/s, got %+v", got[0])
+	}
+	if got[1].Processor != ProcessorGPU || got[1].VRAMShare != 1 || got[1].TokensPerSecond != 100 {
+		t.Fatalf("expected gemma3:4b on the GPU at 100 tokens/s, got %+v", got[1])
+	}
+	if len(warned) != 1 || warned[0].Model != "llama3.2" {
+		t.Fatalf("expected a single CPU fallback warning for llama3.2, got %+v", warned)
+	}
+	if msg := inferenceFallbackWarning(warned[0], ProfileDeep); !strings.Contains(msg, "entirely on the CPU") || !strings.Contains(msg, "Deep Dive") {
+		t.Fatalf("expected the deep warning to name the CPU and Deep Dive, got %q", msg)
+	}
+}
+
+func TestInferenceTelemetryReportsPartialOffload(t *testing.T) {
+	calls := 0
+	server := psServer(t, []loadedModel{{Name: "mistral:7b", Size: 8 << 30, SizeVRAM: 2 << 30}}, &calls)
+	defer server.Close()
+	var warned []ModelInference
+	telemetry := newInferenceTelemetry(server.URL, func(m ModelInference) { warned = append(warned, m) })
+	telemetry.record("mistral:7b", 0, 0)
+	got := telemetry.stop()
+	if got[0].Processor != ProcessorMixed || got[0].VRAMShare != 0.25 {
+		t.Fatalf("expected a quarter of mistral on the GPU, got %+v", got[0])
+	}
+	if len(warned) != 1 || !strings.Contains(inferenceFallbackWarning(warned[0], ProfileStandard), "25% in GPU memory") {
+		t.Fatalf("expected a partial offload warning, got %+v", warned)
+	}
+}
+
+func TestInferenceTelemetryWithoutPSCountsUnknown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	warned := false
+	telemetry := newInferenceTelemetry(server.URL, func(ModelInference) { warned = true })
+	activeInference.Store(telemetry, struct{}{})
+	noteInference("llama3.2", 10, int64(time.Second))
+	got := telemetry.stop()
+	if _, ok := activeInference.Load(telemetry); ok {
+		t.Fatal("expected stop to detach the recorder")
+	}
+	if warned || len(got) != 1 || got[0].Processor != ProcessorUnknown || got[0].Requests != 1 {
+		t.Fatalf("expected an unknown placement without a warning, got %+v", got)
+	}
+	noteInference("llama3.2", 10, int64(time.Second))
+	if len(telemetry.stop()) != 1 || telemetry.models["llama3.2"].Requests != 1 {
+		t.Fatal("expected requests after stop to go unrecorded")
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"book_dashboard/internal/aidetect"
 	"book_dashboard/internal/slop"
+	"book_dashboard/internal/workspace"
 )
 
 func InitialDashboard() DashboardData {
@@ -12,6 +13,7 @@ func InitialDashboard() DashboardData {
 		BookTitle:           "No Manuscript Loaded",
 		WordCount:           0,
 		MHDScore:            0,
+		ScoreBreakdown:      emptyScoreBreakdown(),
 		Logs:                []LogLine{{Time: time.Now().Format("15:04:05.000"), Level: "INFO", Stage: "BOOT", Message: "Ready", Detail: "Use Pick File or Analyze File to start."}},
 		Contradictions:      nil,
 		HealthIssues:        nil,
@@ -28,8 +30,23 @@ func InitialDashboard() DashboardData {
 		CharacterDictionary: nil,
 		ChapterCount:        0,
 		CompTitles:          nil,
-		Language:            LanguageReport{AgeCategory: "Unknown"},
+		Language:            LanguageReport{AgeCategory: "Unknown", IssueBreakdown: []LanguageToolCategory{}, Chapters: []ChapterLanguageScore{}},
+		Sensitivity:         SensitivityReport{Provider: SectionStatusDisabled, Disclaimer: sensitivityDisclaimer},
+		Novelty:             emptyNoveltyReport(),
+		Voice:               emptyVoiceReport(),
+		NameHygiene:         emptyNameHygieneReport(),
+		Terminology:         emptyTerminologyReport(),
+		NumberStyle:         emptyNumberStyleReport(NumberStyleChicago),
+		Permissions:         emptyPermissionsReport(),
+		LanguageMix:         emptyLanguageMixReport(),
+		Bookends:            emptyBookendReport(),
+		POV:                 emptyPOVReport(),
+		Pacing:              emptyPacingReport(),
+		Stats:               emptyManuscriptStats(),
+		CrutchWords:         emptyCrutchWordReport(),
 		ProjectLocation:     "",
+		Annotations:         nil,
+		Sections:            sectionStatuses(workspace.ProjectSettings{}),
 		RunStats: RunStats{
 			Status:     "IDLE",
 			RunID:      "",
@@ -2,15 +2,21 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
+
+	"book_dashboard/internal/scheduler"
 )
 
 var wordPattern = regexp.MustCompile(`[A-Za-z']+`)
@@ -20,26 +26,49 @@ var multiSpacePattern = regexp.MustCompile(`\s{2,}`)
 var vowelPattern = regexp.MustCompile(`[aeiouy]`)
 var hardClusterPattern = regexp.MustCompile(`[bcdfghjklmnpqrstvwxz]{6,}`)
 
-func analyzeLanguage(chapters []chapter, text string) LanguageReport {
+func analyzeLanguage(chapters []chapter, text string, includeSafety bool, rubric AgeRubric, ltOpts languageToolOptions) LanguageReport {
 	base := heuristicLanguage(text)
 	base.SpellingProvider = "heuristic"
 	base.SafetyProvider = "heuristic"
+	base.Dialect = ltOpts.dialect
+	base.DialogueGrammar = normalizeDialogueGrammar(ltOpts.dialogueGrammar)
 
-	ltReport, ltErr := analyzeWithLanguageTool(chapters)
-	if ltErr == nil {
+	if ltOpts.offline {
+		base.Chapters = heuristicChapterScores(chapters)
+		base.Notes = append(base.Notes, "Spelling & grammar provider: heuristic (offline quick scan)")
+	} else if ltReport, ltErr := analyzeWithLanguageTool(chapters, ltOpts); ltErr == nil {
 		base.SpellingScore = ltReport.SpellingScore
 		base.GrammarScore = ltReport.GrammarScore
-		base.ReadabilityScore = ltReport.ReadabilityScore
 		base.ProfanityScore = max(base.ProfanityScore, ltReport.ProfanityScore)
 		base.SpellingProvider = "LanguageTool"
+		base.IssueBreakdown = ltReport.IssueBreakdown
+		base.SpellingIssuesPer1k = ltReport.SpellingIssuesPer1k
+		base.GrammarIssuesPer1k = ltReport.GrammarIssuesPer1k
+		base.DialogueMatches = ltReport.DialogueMatches
+		base.Chapters = ltReport.Chapters
 		base.Notes = append(base.Notes, ltReport.Notes...)
 	} else {
+		base.Chapters = heuristicChapterScores(chapters)
 		base.Notes = append(base.Notes, "Spelling & grammar provider: heuristic fallback")
 		base.Notes = append(base.Notes, "LanguageTool unavailable: "+ltErr.Error())
+		base.SpellingFallback = newFallbackReason(ServiceLanguageTool, "", ltErr)
 	}
 
-	safety, safetyErr := analyzeSafetyWithOllama(chapters, text)
-	if safetyErr == nil {
+	markCopyeditAttention(base.Chapters, base.SpellingScore, base.GrammarScore)
+
+	var modelScores map[string]int
+	if !includeSafety {
+		base.AgeCategory = "Not Rated"
+		base.SafetyProvider = SectionStatusDisabled
+		base.ProfanityScore = 0
+		base.ExplicitScore = 0
+		base.ViolenceScore = 0
+		base.ProfanityInstances = 0
+		base.ExplicitInstances = 0
+		base.Notes = append(base.Notes, "Content safety analysis disabled in project settings.")
+	} else if ltOpts.offline {
+		base.Notes = append(base.Notes, "Content rated from the rubric only (offline quick scan).")
+	} else if safety, safetyErr := analyzeSafetyWithProgress(chapters, text, ltOpts); safetyErr == nil {
 		base.AgeCategory = safety.AgeCategory
 		base.ProfanityScore = safety.ProfanityScore
 		base.ExplicitScore = safety.ExplicitScore
@@ -47,13 +76,29 @@ func analyzeLanguage(chapters []chapter, text string) LanguageReport {
 		base.SafetyProvider = "Ollama"
 		base.ProfanityInstances = max(base.ProfanityInstances, safety.ProfanityInstances)
 		base.ExplicitInstances = max(base.ExplicitInstances, safety.ExplicitInstances)
+		modelScores = map[string]int{
+			RatingSubstances: safety.SubstanceScore,
+			RatingSelfHarm:   safety.SelfHarmScore,
+			RatingSuicide:    safety.SuicideScore,
+		}
 		if safety.SafetyRationale != "" {
 			base.Notes = append(base.Notes, "Ollama safety rationale: "+safety.SafetyRationale)
 		}
 	} else {
 		base.Notes = append(base.Notes, "Ollama safety unavailable: "+safetyErr.Error())
+		base.SafetyFallback = newFallbackReason(ServiceOllama, ollamaModel("OLLAMA_LANGUAGE_MODEL"), safetyErr)
 	}
-	base.HeuristicFallback = strings.EqualFold(base.SpellingProvider, "heuristic") || strings.EqualFold(base.SafetyProvider, "heuristic")
+	if includeSafety {
+		modelCategory := base.AgeCategory
+		base.AgeRating = rateContent(chapters, base, modelScores, rubric)
+		base.AgeCategory = base.AgeRating.Overall
+		base.ContentWarnings = contentWarnings(base.AgeRating)
+		if strings.EqualFold(base.SafetyProvider, "Ollama") && modelCategory != "" && modelCategory != base.AgeCategory {
+			base.Notes = append(base.Notes, fmt.Sprintf("Ollama suggested %s; rubric (%s) rates %s.", modelCategory, base.AgeRating.Standard, base.AgeCategory))
+		}
+	}
+	// An offline run chose the heuristics; it is not a fallback.
+	base.HeuristicFallback = !ltOpts.offline && (strings.EqualFold(base.SpellingProvider, "heuristic") || strings.EqualFold(base.SafetyProvider, "heuristic"))
 	if base.HeuristicFallback {
 		base.Notes = append([]string{"Warning: heuristic fallback active. Verify dependency startup logs."}, base.Notes...)
 	}
@@ -69,22 +114,18 @@ func heuristicLanguage(text string) LanguageReport {
 		return LanguageReport{SpellingScore: 0, GrammarScore: 0, ReadabilityScore: 0, AgeCategory: "Unknown"}
 	}
 
-	profanityWords := map[string]struct{}{"fuck": {}, "shit": {}, "damn": {}, "bitch": {}, "asshole": {}, "bastard": {}}
-	explicitWords := map[string]struct{}{"sex": {}, "nude": {}, "naked": {}, "erotic": {}, "orgasm": {}, "penetration": {}}
-	violenceWords := map[string]struct{}{"blood": {}, "kill": {}, "murder": {}, "gun": {}, "knife": {}, "stab": {}, "violent": {}}
-
 	profanityCount := 0
 	explicitCount := 0
 	violenceCount := 0
 	suspiciousSpelling := 0
 	for _, w := range words {
-		if _, ok := profanityWords[w]; ok {
+		if _, ok := contentLexicons[RatingLanguage][w]; ok {
 			profanityCount++
 		}
-		if _, ok := explicitWords[w]; ok {
+		if _, ok := contentLexicons[RatingSex][w]; ok {
 			explicitCount++
 		}
-		if _, ok := violenceWords[w]; ok {
+		if _, ok := contentLexicons[RatingViolence][w]; ok {
 			violenceCount++
 		}
 		if looksMisspelled(w) {
@@ -124,25 +165,15 @@ func heuristicLanguage(text string) LanguageReport {
 	if len(sentences) > 0 {
 		avgSentenceLen = float64(totalSentenceWords) / float64(max(1, len(sentences)))
 	}
-	readabilityPenalty := int(abs(avgSentenceLen-18.0) * 3.2)
-	readabilityScore := clamp100(100 - readabilityPenalty)
-
-	profanityScore := clamp100(profanityCount * 1000 / max(1, wordCount))
-	explicitScore := clamp100(explicitCount * 1200 / max(1, wordCount))
-	violenceScore := clamp100(violenceCount * 900 / max(1, wordCount))
+	level := measureReadingLevel(text)
 
-	ageCategory := "All Ages"
-	switch {
-	case explicitScore >= 8 || profanityScore >= 10:
-		ageCategory = "Adult 18+"
-	case explicitScore >= 4 || profanityScore >= 6 || violenceScore >= 8:
-		ageCategory = "Mature 16+"
-	case profanityScore >= 2 || violenceScore >= 4:
-		ageCategory = "Teen 13+"
-	}
+	profanityScore := contentScore(RatingLanguage, profanityCount, wordCount)
+	explicitScore := contentScore(RatingSex, explicitCount, wordCount)
+	violenceScore := contentScore(RatingViolence, violenceCount, wordCount)
 
 	notes := []string{
 		fmt.Sprintf("Average sentence length: %.1f words", avgSentenceLen),
+		fmt.Sprintf("Reading level: Flesch-Kincaid grade %.1f, Gunning Fog %.1f, SMOG %.1f", level.FleschKincaidGrade, level.GunningFog, level.SMOG),
 		fmt.Sprintf("Potential spelling anomalies: %d", suspiciousSpelling),
 		fmt.Sprintf("Grammar issue heuristics triggered: %d", grammarIssues),
 	}
@@ -150,95 +181,218 @@ func heuristicLanguage(text string) LanguageReport {
 	return LanguageReport{
 		SpellingScore:      spellingScore,
 		GrammarScore:       grammarScore,
-		ReadabilityScore:   readabilityScore,
-		AgeCategory:        ageCategory,
+		ReadabilityScore:   level.readabilityScore(),
+		ReadingLevel:       level,
 		ProfanityScore:     profanityScore,
 		ExplicitScore:      explicitScore,
 		ViolenceScore:      violenceScore,
 		ProfanityInstances: profanityCount,
 		ExplicitInstances:  explicitCount,
+		IssueBreakdown:     []LanguageToolCategory{},
+		Chapters:           []ChapterLanguageScore{},
 		Notes:              notes,
 	}
 }
 
 type languageToolResponse struct {
 	Matches []struct {
-		Rule struct {
-			Category struct {
+		Offset int `json:"offset"`
+		Rule   struct {
+			ID          string `json:"id"`
+			Description string `json:"description"`
+			Category    struct {
 				ID string `json:"id"`
 			} `json:"category"`
 		} `json:"rule"`
 	} `json:"matches"`
 }
 
-func analyzeWithLanguageTool(chapters []chapter) (LanguageReport, error) {
-	endpoint := os.Getenv("LANGUAGETOOL_URL")
-	if endpoint == "" {
-		endpoint = "http://localhost:8010/v2/check"
+// LanguageTool scores are normalized per 1,000 words: every issue per 1k words
+// costs spellingPointsPer1k (spelling) or grammarPointsPer1k (all other
+// categories) off 100, so a 3k-word story and a 120k-word novel with the same
+// error density get the same score. At the defaults a score of 50 means 50
+// spelling or about 33 grammar issues per 1k words.
+const (
+	spellingPointsPer1k = 1.0
+	grammarPointsPer1k  = 1.5
+)
+
+func per1k(issues float64, words int) float64 {
+	return issues * 1000 / float64(max(1, words))
+}
+
+func per1kScore(rate, pointsPer1k float64) int {
+	return clamp100(int(math.Round(100 - rate*pointsPer1k)))
+}
+
+// languageToolOptions carries the per-project LanguageTool settings.
+type languageToolOptions struct {
+	dialect         string
+	dialogueGrammar string
+	// offline scores spelling, grammar and content from heuristics and the
+	// rubric without calling LanguageTool or the safety model.
+	offline bool
+	// progress, when set, receives the fraction of the language stage done
+	// and a detail line: LanguageTool chapters cover the first half, the
+	// safety model the second.
+	progress func(fraction float64, detail string)
+	// limiter sizes concurrent LanguageTool requests; nil sends one at a
+	// time.
+	limiter *scheduler.Limiter
+}
+
+func (o languageToolOptions) report(fraction float64, detail string) {
+	if o.progress != nil {
+		o.progress(fraction, detail)
 	}
+}
+
+func analyzeWithLanguageTool(chapters []chapter, opts languageToolOptions) (LanguageReport, error) {
+	endpoint := languageToolEndpoint()
 	client := &http.Client{Timeout: 45 * time.Second}
 
-	grammarIssues := 0
-	spellingIssues := 0
-	styleIssues := 0
+	mode := normalizeDialogueGrammar(opts.dialogueGrammar)
+	tally := newLanguageToolTally()
 	totalWords := 0
-	for _, ch := range chapters {
-		totalWords += len(strings.Fields(ch.text))
-		vals := url.Values{}
-		vals.Set("language", "en-US")
-		vals.Set("text", ch.text)
-		req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(vals.Encode()))
+	otherIssues := 0.0
+	dialogueMatches := 0
+	chapterScores := make([]ChapterLanguageScore, 0, len(chapters))
+
+	// Requests run concurrently under the adaptive limiter; results are
+	// tallied in chapter order afterwards so the report does not depend on
+	// which request finished first.
+	limiter := opts.limiter
+	if limiter == nil {
+		limiter = scheduler.New("languagetool_chapters", 1, nil)
+	}
+	sizes := make([]int, len(chapters))
+	for i, ch := range chapters {
+		sizes[i] = len(strings.Fields(ch.text))
+	}
+	responses := make([]languageToolResponse, len(chapters))
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	var mu sync.Mutex
+	checked := 0
+	opts.report(0, fmt.Sprintf("LanguageTool: 0/%d chapters checked", len(chapters)))
+	errs := scheduler.Each(ctx, limiter, sizes, func(ctx context.Context, i int) error {
+		lt, err := checkLanguageTool(ctx, client, endpoint, opts.dialect, chapters[i].text)
 		if err != nil {
-			return LanguageReport{}, err
+			stop()
+			return err
 		}
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		resp, err := client.Do(req)
-		if err != nil {
-			return LanguageReport{}, err
-		}
-		body, _ := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return LanguageReport{}, fmt.Errorf("status %d", resp.StatusCode)
-		}
-		var lt languageToolResponse
-		if err := json.Unmarshal(body, &lt); err != nil {
+		responses[i] = lt
+		mu.Lock()
+		defer mu.Unlock()
+		checked++
+		opts.report(float64(checked)/float64(len(chapters))*0.5, fmt.Sprintf("LanguageTool: %d/%d chapters checked (%d at a time)", checked, len(chapters), limiter.Limit()))
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
 			return LanguageReport{}, err
 		}
+	}
+
+	for i, ch := range chapters {
+		chapterWords := sizes[i]
+		totalWords += chapterWords
+		lt := responses[i]
+		spans := dialogueSpans(ch.text)
+		chapterSpelling, chapterGrammar, chapterWeighted := 0, 0, 0.0
 		for _, m := range lt.Matches {
-			cat := strings.ToUpper(m.Rule.Category.ID)
-			switch {
-			case strings.Contains(cat, "TYPOS") || strings.Contains(cat, "SPELL"):
-				spellingIssues++
-			case strings.Contains(cat, "STYLE"):
-				styleIssues++
-			default:
-				grammarIssues++
+			weight := 1.0
+			if languageToolCategory(m.Rule.ID, m.Rule.Category.ID) != LTCategorySpelling && inDialogue(spans, m.Offset) {
+				dialogueMatches++
+				weight = dialogueWeight(mode)
+				if weight == 0 {
+					continue
+				}
+			}
+			if tally.add(m.Rule.ID, m.Rule.Description, m.Rule.Category.ID) == LTCategorySpelling {
+				chapterSpelling++
+			} else {
+				chapterGrammar++
+				chapterWeighted += weight
 			}
 		}
+		otherIssues += chapterWeighted
+		chapterScores = append(chapterScores, chapterLanguageScore(ch, chapterWords, chapterSpelling, chapterGrammar,
+			per1kScore(per1k(float64(chapterSpelling), chapterWords), spellingPointsPer1k),
+			per1kScore(per1k(chapterWeighted, chapterWords), grammarPointsPer1k)))
 	}
+	spellingIssues := float64(tally.count(LTCategorySpelling))
 
-	if totalWords == 0 {
-		totalWords = 1
-	}
-	spellingScore := clamp100(100 - (spellingIssues * 700 / totalWords))
-	grammarScore := clamp100(100 - ((grammarIssues + styleIssues) * 900 / totalWords))
-	readabilityScore := clamp100((spellingScore + grammarScore) / 2)
+	spellingRate := per1k(spellingIssues, totalWords)
+	grammarRate := per1k(otherIssues, totalWords)
+	spellingScore := per1kScore(spellingRate, spellingPointsPer1k)
+	grammarScore := per1kScore(grammarRate, grammarPointsPer1k)
 
+	breakdown := tally.breakdown()
+	counts := make([]string, 0, len(breakdown))
+	for _, c := range breakdown {
+		counts = append(counts, fmt.Sprintf("%s=%d", c.Category, c.Count))
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "none")
+	}
 	return LanguageReport{
-		SpellingScore:    spellingScore,
-		GrammarScore:     grammarScore,
-		ReadabilityScore: readabilityScore,
-		ProfanityScore:   0,
+		SpellingScore:       spellingScore,
+		GrammarScore:        grammarScore,
+		ProfanityScore:      0,
+		IssueBreakdown:      breakdown,
+		Chapters:            chapterScores,
+		SpellingIssuesPer1k: math.Round(spellingRate*10) / 10,
+		GrammarIssuesPer1k:  math.Round(grammarRate*10) / 10,
+		DialogueGrammar:     mode,
+		DialogueMatches:     dialogueMatches,
 		Notes: []string{
-			"Spelling & grammar provider: LanguageTool",
-			fmt.Sprintf("LanguageTool issues: grammar=%d spelling=%d style=%d", grammarIssues, spellingIssues, styleIssues),
+			"Spelling & grammar provider: LanguageTool (" + opts.dialect + ")",
+			"LanguageTool issues: " + strings.Join(counts, " "),
+			fmt.Sprintf("Issues per 1k words: spelling=%.1f grammar=%.1f", spellingRate, grammarRate),
+			fmt.Sprintf("Grammar matches inside dialogue: %d (%s)", dialogueMatches, mode),
 		},
 	}, nil
 }
 
+func checkLanguageTool(ctx context.Context, client *http.Client, endpoint, dialect, text string) (languageToolResponse, error) {
+	vals := url.Values{}
+	vals.Set("language", dialect)
+	vals.Set("text", text)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(vals.Encode()))
+	if err != nil {
+		return languageToolResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return languageToolResponse{}, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return languageToolResponse{}, statusError(resp.StatusCode, body)
+	}
+	var lt languageToolResponse
+	if err := json.Unmarshal(body, &lt); err != nil {
+		return languageToolResponse{}, err
+	}
+	return lt, nil
+}
+
+func languageToolEndpoint() string {
+	endpoint := strings.TrimSpace(os.Getenv("LANGUAGETOOL_URL"))
+	if endpoint == "" {
+		return "http://localhost:8010/v2/check"
+	}
+	return endpoint
+}
+
 type ollamaResponse struct {
 	Response string `json:"response"`
+	// EvalCount and EvalDuration (nanoseconds) time the generated tokens.
+	EvalCount    int   `json:"eval_count"`
+	EvalDuration int64 `json:"eval_duration"`
 }
 
 type safetyResult struct {
@@ -248,18 +402,30 @@ type safetyResult struct {
 	ViolenceScore      int    `json:"violence_score"`
 	ProfanityInstances int    `json:"profanity_instances"`
 	ExplicitInstances  int    `json:"explicit_instances"`
+	SubstanceScore     int    `json:"substance_score"`
+	SelfHarmScore      int    `json:"self_harm_score"`
+	SuicideScore       int    `json:"suicide_score"`
 	SafetyRationale    string `json:"safety_rationale"`
 }
 
+// analyzeSafetyWithProgress keeps emitting progress while the single safety
+// call is in flight; it is one request over a sample, so there is no
+// per-chapter step to report.
+func analyzeSafetyWithProgress(chapters []chapter, text string, opts languageToolOptions) (safetyResult, error) {
+	opts.report(0.5, "Safety classification: waiting on model")
+	stop := heartbeat(modelHeartbeatInterval, func(elapsed time.Duration) {
+		opts.report(0.5, fmt.Sprintf("Safety classification: waiting on model (%s)", elapsed))
+	})
+	defer stop()
+	return analyzeSafetyWithOllama(chapters, text)
+}
+
 func analyzeSafetyWithOllama(chapters []chapter, text string) (safetyResult, error) {
 	endpoint := ollamaGenerateEndpoint()
-	model := os.Getenv("OLLAMA_LANGUAGE_MODEL")
-	if model == "" {
-		model = "llama3.1:8b"
-	}
+	model := ollamaModel("OLLAMA_LANGUAGE_MODEL")
 
 	sample := buildSafetySample(chapters, text)
-	prompt := "You are a strict content classifier for book publishing. Return JSON only with keys: age_category, profanity_score, explicit_score, violence_score, profanity_instances, explicit_instances, safety_rationale. Scores are 0-100." + "\n\nTEXT:\n" + sample
+	prompt := "You are a strict content classifier for book publishing. Return JSON only with keys: age_category, profanity_score, explicit_score, violence_score, profanity_instances, explicit_instances, substance_score, self_harm_score, suicide_score, safety_rationale. Scores are 0-100. substance_score covers drug and alcohol use; self_harm_score and suicide_score cover depictions or references, not figures of speech." + "\n\nTEXT:\n" + sample
 	payload := map[string]any{
 		"model":  model,
 		"prompt": prompt,
@@ -278,12 +444,13 @@ func analyzeSafetyWithOllama(chapters []chapter, text string) (safetyResult, err
 	body, _ := io.ReadAll(resp.Body)
 	_ = resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return safetyResult{}, fmt.Errorf("status %d", resp.StatusCode)
+		return safetyResult{}, statusError(resp.StatusCode, body)
 	}
 	var out ollamaResponse
 	if err := json.Unmarshal(body, &out); err != nil {
 		return safetyResult{}, err
 	}
+	noteInference(model, out.EvalCount, out.EvalDuration)
 	jsonText := extractJSONObject(out.Response)
 	if jsonText == "" {
 		snippet := strings.TrimSpace(out.Response)
@@ -291,9 +458,9 @@ func analyzeSafetyWithOllama(chapters []chapter, text string) (safetyResult, err
 			snippet = snippet[:220] + "..."
 		}
 		if snippet == "" {
-			return safetyResult{}, fmt.Errorf("no JSON in model response (empty response)")
+			return safetyResult{}, fmt.Errorf("%w (empty response)", errNoJSON)
 		}
-		return safetyResult{}, fmt.Errorf("no JSON in model response: %q", snippet)
+		return safetyResult{}, fmt.Errorf("%w: %q", errNoJSON, snippet)
 	}
 	var sr safetyResult
 	if err := json.Unmarshal([]byte(jsonText), &sr); err != nil {
@@ -302,6 +469,9 @@ func analyzeSafetyWithOllama(chapters []chapter, text string) (safetyResult, err
 	sr.ProfanityScore = clamp100(sr.ProfanityScore)
 	sr.ExplicitScore = clamp100(sr.ExplicitScore)
 	sr.ViolenceScore = clamp100(sr.ViolenceScore)
+	sr.SubstanceScore = clamp100(sr.SubstanceScore)
+	sr.SelfHarmScore = clamp100(sr.SelfHarmScore)
+	sr.SuicideScore = clamp100(sr.SuicideScore)
 	if sr.AgeCategory == "" {
 		sr.AgeCategory = "Unknown"
 	}
@@ -0,0 +1,267 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PermissionForeignLanguage marks a passage left in another language, listed
+// with the quoted material so the translator and contracts team see both.
+const PermissionForeignLanguage = "foreign_language"
+
+const (
+	languageEnglish = "English"
+
+	// minForeignWords is the shortest passage or line of dialogue judged;
+	// shorter phrases ("Mon Dieu", "Sí") are ordinary English prose.
+	minForeignWords = 4
+	// A passage is in a language when at least foreignStopwordShare of its
+	// words, and at least minForeignStopwords, are that language's function
+	// words, more than twice as many as are English ones.
+	foreignStopwordShare = 0.2
+	minForeignStopwords  = 2
+	// A chapter with foreignChapterShare of its words in passages is reported
+	// as one chapter-long passage.
+	foreignChapterShare = 0.5
+)
+
+var mixWordPattern = regexp.MustCompile(`[\p{L}']+`)
+
+// languageStopwords are the function words that identify a Latin-script
+// language. Words shared between languages ("de", "la") count for each.
+var languageStopwords = map[string]map[string]bool{
+	languageEnglish: wordSet("the", "and", "of", "to", "in", "is", "was", "he", "she", "it", "that", "with", "for", "his", "her", "you", "not", "on", "as", "at", "but", "had", "they", "my", "be", "this", "have", "from", "were", "i", "what", "we", "are", "me", "there", "where", "do"),
+	"Spanish":       wordSet("el", "la", "los", "las", "de", "que", "y", "en", "un", "una", "es", "no", "por", "con", "para", "se", "su", "lo", "como", "pero", "más", "mi", "yo", "está", "del", "al", "dónde", "qué", "muy", "tu", "estoy"),
+	"French":        wordSet("le", "la", "les", "de", "des", "et", "est", "un", "une", "je", "il", "elle", "que", "qui", "ne", "pas", "pour", "dans", "sur", "au", "du", "avec", "mais", "nous", "vous", "ce", "c'est", "sont", "suis", "où", "tu", "moi"),
+	"German":        wordSet("der", "die", "das", "und", "ist", "nicht", "ich", "du", "ein", "eine", "zu", "mit", "sich", "auf", "für", "den", "dem", "des", "es", "sie", "wir", "aber", "auch", "wie", "nein", "ja", "bin", "wo", "was", "mein"),
+	"Italian":       wordSet("il", "lo", "la", "gli", "le", "di", "che", "e", "è", "un", "una", "non", "per", "con", "mi", "ti", "sono", "ma", "come", "questo", "della", "del", "dove", "io", "tu"),
+	"Portuguese":    wordSet("o", "a", "os", "as", "de", "que", "e", "em", "um", "uma", "não", "é", "do", "da", "com", "para", "por", "se", "eu", "você", "mas", "muito", "onde", "está"),
+	"Latin":         wordSet("et", "in", "est", "non", "ad", "cum", "sed", "ut", "qui", "quae", "quod", "sum", "esse", "deus", "nos", "vos", "per", "ex", "atque", "enim", "sunt", "mea", "tua"),
+}
+
+// nonLatinScripts name passages by script when their letters are mostly not
+// Latin.
+var nonLatinScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Hebrew", unicode.Hebrew},
+	{"Arabic", unicode.Arabic},
+	{"Chinese/Japanese", unicode.Han},
+	{"Japanese", unicode.Hiragana},
+	{"Japanese", unicode.Katakana},
+	{"Korean", unicode.Hangul},
+	{"Devanagari", unicode.Devanagari},
+}
+
+// LanguageMixReport lists the passages written in another language than the
+// English main text. Their words are left out of the spelling, grammar and
+// readability scores, which assume English.
+type LanguageMixReport struct {
+	Passages      []ForeignPassage `json:"passages"`
+	ExcludedWords int              `json:"excludedWords"`
+	Flags         []string         `json:"flags"`
+}
+
+// ForeignPassage is one foreign paragraph run, line of dialogue or chapter.
+type ForeignPassage struct {
+	Kind      string `json:"kind"`
+	Language  string `json:"language"`
+	Chapter   int    `json:"chapter"`
+	Paragraph int    `json:"paragraph"`
+	Location  string `json:"location"`
+	Words     int    `json:"words"`
+	Excerpt   string `json:"excerpt"`
+	// texts are the exact paragraphs or quotation, for removing them from
+	// the scored text.
+	texts []string
+}
+
+// Kinds of foreign passage.
+const (
+	ForeignPassageParagraphs = "paragraphs"
+	ForeignPassageDialogue   = "dialogue"
+	ForeignPassageChapter    = "chapter"
+)
+
+func emptyLanguageMixReport() LanguageMixReport {
+	return LanguageMixReport{Passages: []ForeignPassage{}, Flags: []string{}}
+}
+
+// passageLanguage names the language of a passage, or "" when it has too few
+// words or reads as English.
+func passageLanguage(text string) string {
+	words := mixWordPattern.FindAllString(strings.ToLower(text), -1)
+	if len(words) < minForeignWords {
+		return ""
+	}
+	letters, scripts := 0, map[string]int{}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range nonLatinScripts {
+			if unicode.Is(s.table, r) {
+				scripts[s.name]++
+				break
+			}
+		}
+	}
+	for _, s := range nonLatinScripts {
+		if n := scripts[s.name]; n*2 > letters {
+			return s.name
+		}
+	}
+	hits := map[string]int{}
+	for _, w := range words {
+		for lang, stopwords := range languageStopwords {
+			if stopwords[w] {
+				hits[lang]++
+			}
+		}
+	}
+	best := ""
+	for lang, n := range hits {
+		if lang == languageEnglish {
+			continue
+		}
+		if best == "" || n > hits[best] || (n == hits[best] && lang < best) {
+			best = lang
+		}
+	}
+	if best == "" || hits[best] < minForeignStopwords || hits[best] <= 2*hits[languageEnglish] || float64(hits[best]) < foreignStopwordShare*float64(len(words)) {
+		return ""
+	}
+	return best
+}
+
+// detectLanguageMix finds paragraphs and quoted dialogue in another language
+// than English, joining neighbouring foreign paragraphs in the same language
+// and collapsing a mostly foreign chapter into one passage.
+func detectLanguageMix(chapters []chapter) LanguageMixReport {
+	report := emptyLanguageMixReport()
+	for _, ch := range chapters {
+		paragraphs := chapterParagraphs(ch.text)
+		chapterWords, found := 0, []ForeignPassage{}
+		for i, p := range paragraphs {
+			words := len(strings.Fields(p))
+			chapterWords += words
+			if lang := passageLanguage(p); lang != "" {
+				if n := len(found); n > 0 && found[n-1].Kind == ForeignPassageParagraphs && found[n-1].Language == lang && found[n-1].Paragraph+len(found[n-1].texts) == i+1 {
+					found[n-1].Words += words
+					found[n-1].texts = append(found[n-1].texts, p)
+					continue
+				}
+				found = append(found, foreignPassage(ForeignPassageParagraphs, lang, ch.index, i, p, words))
+				continue
+			}
+			for _, quote := range narrationQuotePattern.FindAllString(p, -1) {
+				inner := strings.Trim(quote, `"“”`)
+				if lang := passageLanguage(inner); lang != "" {
+					found = append(found, foreignPassage(ForeignPassageDialogue, lang, ch.index, i, inner, len(strings.Fields(inner))))
+				}
+			}
+		}
+		foreignWords := 0
+		for _, f := range found {
+			foreignWords += f.Words
+		}
+		if chapterWords > 0 && float64(foreignWords) >= foreignChapterShare*float64(chapterWords) {
+			chapterPassage := foreignPassage(ForeignPassageChapter, dominantLanguage(found), ch.index, 0, strings.Join(paragraphs, " "), chapterWords)
+			chapterPassage.Location = fmt.Sprintf("Ch %d", ch.index)
+			chapterPassage.texts = paragraphs
+			found = []ForeignPassage{chapterPassage}
+		}
+		report.Passages = append(report.Passages, found...)
+	}
+	byLanguage := map[string]int{}
+	for _, p := range report.Passages {
+		report.ExcludedWords += p.Words
+		byLanguage[p.Language] += p.Words
+	}
+	if len(report.Passages) > 0 {
+		langs := make([]string, 0, len(byLanguage))
+		for lang, words := range byLanguage {
+			langs = append(langs, fmt.Sprintf("%s (%d words)", lang, words))
+		}
+		sort.Strings(langs)
+		report.Flags = append(report.Flags, fmt.Sprintf("%d passages are not in English: %s. They are left out of the spelling, grammar and readability scores.", len(report.Passages), strings.Join(langs, ", ")))
+	}
+	return report
+}
+
+func foreignPassage(kind, lang string, chapterIndex, i int, text string, words int) ForeignPassage {
+	return ForeignPassage{
+		Kind:      kind,
+		Language:  lang,
+		Chapter:   chapterIndex,
+		Paragraph: i + 1,
+		Location:  fmt.Sprintf("Ch %d, paragraph %d", chapterIndex, i+1),
+		Words:     words,
+		Excerpt:   truncateRunes(text, maxExcerptRunes),
+		texts:     []string{text},
+	}
+}
+
+// dominantLanguage is the language most of the passages' words are in.
+func dominantLanguage(passages []ForeignPassage) string {
+	words := map[string]int{}
+	best := ""
+	for _, p := range passages {
+		words[p.Language] += p.Words
+		if best == "" || words[p.Language] > words[best] {
+			best = p.Language
+		}
+	}
+	return best
+}
+
+// withoutForeignPassages returns the chapters and text with the report's
+// passages removed, for the English-only language scores.
+func withoutForeignPassages(chapters []chapter, text string, report LanguageMixReport) ([]chapter, string) {
+	if len(report.Passages) == 0 {
+		return chapters, text
+	}
+	byChapter := map[int][]ForeignPassage{}
+	for _, p := range report.Passages {
+		byChapter[p.Chapter] = append(byChapter[p.Chapter], p)
+	}
+	out := make([]chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		for _, p := range byChapter[ch.index] {
+			for _, t := range p.texts {
+				text = strings.Replace(text, t, "", 1)
+				ch.text = strings.Replace(ch.text, t, "", 1)
+			}
+		}
+		out = append(out, ch)
+	}
+	return out, text
+}
+
+// addForeignPassages lists the foreign passages on the permissions checklist,
+// where each needs a translation decision and, when quoted from a published
+// translation or original, the same clearance as other quotations.
+func addForeignPassages(report *PermissionsReport, mix LanguageMixReport) {
+	for _, p := range mix.Passages {
+		report.Items = append(report.Items, PermissionItem{
+			Kind:      PermissionForeignLanguage,
+			Excerpt:   p.Excerpt,
+			Words:     p.Words,
+			Lines:     max(1, len(p.texts)),
+			Chapter:   p.Chapter,
+			Paragraph: p.Paragraph,
+			Location:  p.Location,
+			Note:      fmt.Sprintf("%s %s left untranslated; confirm with the translator whether to keep, gloss or translate it, and clear it if quoted from a published work.", p.Language, p.Kind),
+		})
+	}
+	if n := len(mix.Passages); n > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d passages in another language (%d words) need a translation decision.", n, mix.ExcludedWords))
+	}
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPassageLanguage(t *testing.T) {
+	cases := map[string]string{
+		"¿Dónde está la biblioteca?":                   "Spanish",
+		"Je ne sais pas ce que tu veux dire.":          "French",
+		"Ich weiß nicht, was du meinst.":               "German",
+		"Я не знаю, что ты имеешь в виду.":             "Cyrillic",
+		"She walked to the door and opened it.":        "",
+		"Mon Dieu, she said.":                          "",
+		"La Belle Époque Hotel stood empty.":           "",
+		"Mara and Tomas ran for the boat in the rain.": "",
+		"Non est ad astra mollis e terris via.":        "Latin",
+	}
+	for text, want := range cases {
+		if got := passageLanguage(text); got != want {
+			t.Errorf("passageLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDetectLanguageMixExcludesForeignPassagesFromScoring(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "Chapter 1\nJe ne sais pas ce que tu veux dire. Il est tard et nous sommes fatigués.\nMara read the epigraph twice and closed the book. The porter was asleep at his desk, and the lamp beside him had burned down to a stub.\n“¿Dónde está la biblioteca?” she asked the porter, who only shrugged."},
+		{index: 2, text: "Tomas walked to the harbor. He was tired and his feet hurt."},
+	}
+	report := detectLanguageMix(chapters)
+	if len(report.Passages) != 2 {
+		t.Fatalf("expected an epigraph and a line of dialogue, got %+v", report.Passages)
+	}
+	epigraph, dialogue := report.Passages[0], report.Passages[1]
+	if epigraph.Kind != ForeignPassageParagraphs || epigraph.Language != "French" || epigraph.Location != "Ch 1, paragraph 1" {
+		t.Fatalf("unexpected epigraph %+v", epigraph)
+	}
+	if dialogue.Kind != ForeignPassageDialogue || dialogue.Language != "Spanish" || dialogue.Words != 4 || dialogue.Paragraph != 3 {
+		t.Fatalf("unexpected dialogue %+v", dialogue)
+	}
+	if report.ExcludedWords != epigraph.Words+4 || len(report.Flags) != 1 || !strings.Contains(report.Flags[0], "French") {
+		t.Fatalf("unexpected totals %d %v", report.ExcludedWords, report.Flags)
+	}
+
+	text := chapters[0].text + "\n" + chapters[1].text
+	scored, scoredText := withoutForeignPassages(chapters, text, report)
+	for _, s := range []string{scored[0].text, scoredText} {
+		if strings.Contains(s, "Je ne sais") || strings.Contains(s, "biblioteca") || !strings.Contains(s, "Mara read the epigraph") {
+			t.Fatalf("expected only the foreign passages removed, got %q", s)
+		}
+	}
+	if scored[1].text != chapters[1].text {
+		t.Fatalf("expected the English chapter untouched, got %q", scored[1].text)
+	}
+
+	permissions := emptyPermissionsReport()
+	addForeignPassages(&permissions, report)
+	if len(permissions.Items) != 2 || permissions.Items[0].Kind != PermissionForeignLanguage || !strings.Contains(permissions.Items[1].Note, "Spanish dialogue left untranslated") || len(permissions.Flags) != 1 {
+		t.Fatalf("expected the passages on the permissions checklist, got %+v", permissions)
+	}
+}
+
+func TestDetectLanguageMixCollapsesForeignChapter(t *testing.T) {
+	chapters := []chapter{{index: 3, text: "Il pleuvait sur la ville et nous étions seuls.\nElle est partie avant le jour, sans un mot pour moi.\nMara woke."}}
+	report := detectLanguageMix(chapters)
+	if len(report.Passages) != 1 || report.Passages[0].Kind != ForeignPassageChapter || report.Passages[0].Location != "Ch 3" || report.Passages[0].Language != "French" {
+		t.Fatalf("expected one chapter-long French passage, got %+v", report.Passages)
+	}
+	scored, _ := withoutForeignPassages(chapters, chapters[0].text, report)
+	if strings.TrimSpace(scored[0].text) != "" {
+		t.Fatalf("expected the chapter left out of scoring, got %q", scored[0].text)
+	}
+}
@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"sort"
+	"strings"
+)
+
+// Editorial categories for LanguageTool matches. LanguageTool's own category
+// ids are too coarse (most agreement and punctuation rules sit under GRAMMAR
+// or MISC), so rule ids are checked first.
+const (
+	LTCategorySpelling      = "spelling"
+	LTCategoryPunctuation   = "punctuation"
+	LTCategoryAgreement     = "agreement"
+	LTCategoryConfusedWords = "confused_words"
+	LTCategoryTypography    = "typography"
+	LTCategoryStyle         = "style"
+	LTCategoryGrammar       = "grammar"
+)
+
+var ltCategoryLabels = map[string]string{
+	LTCategorySpelling:      "Spelling",
+	LTCategoryPunctuation:   "Punctuation",
+	LTCategoryAgreement:     "Agreement",
+	LTCategoryConfusedWords: "Confused words",
+	LTCategoryTypography:    "Typography",
+	LTCategoryStyle:         "Style",
+	LTCategoryGrammar:       "Other grammar",
+}
+
+// ltCategoryOrder fixes the display order of the breakdown.
+var ltCategoryOrder = []string{
+	LTCategorySpelling,
+	LTCategoryPunctuation,
+	LTCategoryAgreement,
+	LTCategoryConfusedWords,
+	LTCategoryTypography,
+	LTCategoryStyle,
+	LTCategoryGrammar,
+}
+
+const maxTopLanguageToolRules = 3
+
+// Exact rule ids whose LanguageTool category does not match the editorial one.
+var ltRuleCategories = map[string]string{
+	"EN_A_VS_AN":                         LTCategoryAgreement,
+	"DT_JJ_NO_NOUN":                      LTCategoryAgreement,
+	"THIS_NNS":                           LTCategoryAgreement,
+	"EN_QUOTES":                          LTCategoryTypography,
+	"WHITESPACE_RULE":                    LTCategoryTypography,
+	"UPPERCASE_SENTENCE_START":           LTCategoryTypography,
+	"DASH_RULE":                          LTCategoryTypography,
+	"ELLIPSIS":                           LTCategoryTypography,
+	"COMMA_PARENTHESIS_WHITESPACE":       LTCategoryPunctuation,
+	"DOUBLE_PUNCTUATION":                 LTCategoryPunctuation,
+	"EN_UNPAIRED_BRACKETS":               LTCategoryPunctuation,
+	"UNLIKELY_OPENING_PUNCTUATION":       LTCategoryPunctuation,
+	"ENGLISH_WORD_REPEAT_RULE":           LTCategoryStyle,
+	"ENGLISH_WORD_REPEAT_BEGINNING_RULE": LTCategoryStyle,
+}
+
+// Rule id fragments, checked in order after the exact ids.
+var ltRuleFragments = []struct {
+	fragment string
+	category string
+}{
+	{"MORFOLOGIK", LTCategorySpelling},
+	{"HUNSPELL", LTCategorySpelling},
+	{"SPELL", LTCategorySpelling},
+	{"AGREEMENT", LTCategoryAgreement},
+	{"_AGR", LTCategoryAgreement},
+	{"NON3PRS", LTCategoryAgreement},
+	{"CONFUSION", LTCategoryConfusedWords},
+	{"_VS_", LTCategoryConfusedWords},
+	{"QUOTE", LTCategoryTypography},
+	{"APOS", LTCategoryTypography},
+	{"WHITESPACE", LTCategoryTypography},
+	{"COMMA", LTCategoryPunctuation},
+	{"PUNCT", LTCategoryPunctuation},
+	{"REPEAT", LTCategoryStyle},
+}
+
+// LanguageTool category ids, the last resort.
+var ltCategoryIDs = map[string]string{
+	"TYPOS":               LTCategorySpelling,
+	"PUNCTUATION":         LTCategoryPunctuation,
+	"CONFUSED_WORDS":      LTCategoryConfusedWords,
+	"TYPOGRAPHY":          LTCategoryTypography,
+	"CASING":              LTCategoryTypography,
+	"STYLE":               LTCategoryStyle,
+	"REDUNDANCY":          LTCategoryStyle,
+	"PLAIN_ENGLISH":       LTCategoryStyle,
+	"WIKIPEDIA":           LTCategoryStyle,
+	"NONSTANDARD_PHRASES": LTCategoryStyle,
+	"REPETITIONS_STYLE":   LTCategoryStyle,
+}
+
+// languageToolCategory maps a LanguageTool match to an editorial category.
+func languageToolCategory(ruleID, categoryID string) string {
+	rule := strings.ToUpper(strings.TrimSpace(ruleID))
+	if category, ok := ltRuleCategories[rule]; ok {
+		return category
+	}
+	for _, f := range ltRuleFragments {
+		if strings.Contains(rule, f.fragment) {
+			return f.category
+		}
+	}
+	if category, ok := ltCategoryIDs[strings.ToUpper(strings.TrimSpace(categoryID))]; ok {
+		return category
+	}
+	return LTCategoryGrammar
+}
+
+// languageToolTally accumulates matches per category and rule across chapters.
+type languageToolTally struct {
+	counts map[string]int
+	rules  map[string]map[string]*LanguageToolRule
+}
+
+func newLanguageToolTally() *languageToolTally {
+	return &languageToolTally{counts: map[string]int{}, rules: map[string]map[string]*LanguageToolRule{}}
+}
+
+func (t *languageToolTally) add(ruleID, description, categoryID string) string {
+	category := languageToolCategory(ruleID, categoryID)
+	t.counts[category]++
+	if t.rules[category] == nil {
+		t.rules[category] = map[string]*LanguageToolRule{}
+	}
+	key := strings.TrimSpace(ruleID)
+	if key == "" {
+		key = strings.ToUpper(strings.TrimSpace(categoryID))
+	}
+	rule := t.rules[category][key]
+	if rule == nil {
+		rule = &LanguageToolRule{RuleID: key, Description: strings.TrimSpace(description)}
+		t.rules[category][key] = rule
+	}
+	rule.Count++
+	return category
+}
+
+func (t *languageToolTally) count(category string) int {
+	return t.counts[category]
+}
+
+// breakdown lists categories with at least one match in display order, each
+// with its most frequently triggered rules.
+func (t *languageToolTally) breakdown() []LanguageToolCategory {
+	out := []LanguageToolCategory{}
+	for _, category := range ltCategoryOrder {
+		if t.counts[category] == 0 {
+			continue
+		}
+		rules := make([]LanguageToolRule, 0, len(t.rules[category]))
+		for _, rule := range t.rules[category] {
+			rules = append(rules, *rule)
+		}
+		sort.Slice(rules, func(i, j int) bool {
+			if rules[i].Count != rules[j].Count {
+				return rules[i].Count > rules[j].Count
+			}
+			return rules[i].RuleID < rules[j].RuleID
+		})
+		if len(rules) > maxTopLanguageToolRules {
+			rules = rules[:maxTopLanguageToolRules]
+		}
+		out = append(out, LanguageToolCategory{
+			Category: category,
+			Label:    ltCategoryLabels[category],
+			Count:    t.counts[category],
+			TopRules: rules,
+		})
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLanguageToolCategoryMapping(t *testing.T) {
+	cases := []struct {
+		ruleID, categoryID, want string
+	}{
+		{"MORFOLOGIK_RULE_EN_US", "TYPOS", LTCategorySpelling},
+		{"HE_VERB_AGR", "GRAMMAR", LTCategoryAgreement},
+		{"EN_A_VS_AN", "MISC", LTCategoryAgreement},
+		{"THERE_THEIR_CONFUSION", "GRAMMAR", LTCategoryConfusedWords},
+		{"YOUR_YOU_RE", "CONFUSED_WORDS", LTCategoryConfusedWords},
+		{"EN_QUOTES", "PUNCTUATION", LTCategoryTypography},
+		{"COMMA_PARENTHESIS_WHITESPACE", "TYPOGRAPHY", LTCategoryPunctuation},
+		{"PASSIVE_VOICE", "STYLE", LTCategoryStyle},
+		{"SOME_NEW_RULE", "GRAMMAR", LTCategoryGrammar},
+	}
+	for _, c := range cases {
+		if got := languageToolCategory(c.ruleID, c.categoryID); got != c.want {
+			t.Fatalf("%s/%s: expected %s, got %s", c.ruleID, c.categoryID, c.want, got)
+		}
+	}
+}
+
+func TestAnalyzeWithLanguageToolBreakdown(t *testing.T) {
+	gotLanguage := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLanguage = r.FormValue("language")
+		_, _ = w.Write([]byte(`{"matches":[
+			{"rule":{"id":"MORFOLOGIK_RULE_EN_US","description":"Possible spelling mistake","category":{"id":"TYPOS"}}},
+			{"rule":{"id":"MORFOLOGIK_RULE_EN_US","description":"Possible spelling mistake","category":{"id":"TYPOS"}}},
+			{"rule":{"id":"HE_VERB_AGR","description":"Agreement error","category":{"id":"GRAMMAR"}}},
+			{"rule":{"id":"EN_QUOTES","description":"Smart quotes","category":{"id":"PUNCTUATION"}}}
+		]}`))
+	}))
+	defer server.Close()
+	t.Setenv("LANGUAGETOOL_URL", server.URL)
+
+	report, err := analyzeWithLanguageTool([]chapter{{index: 1, title: "One", text: "He go to the shop and buyed bred."}}, languageToolOptions{dialect: DialectGB})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if gotLanguage != DialectGB {
+		t.Fatalf("expected LanguageTool variant %s, got %q", DialectGB, gotLanguage)
+	}
+	if len(report.IssueBreakdown) != 3 {
+		t.Fatalf("expected spelling, agreement and typography categories, got %+v", report.IssueBreakdown)
+	}
+	spelling := report.IssueBreakdown[0]
+	if spelling.Category != LTCategorySpelling || spelling.Count != 2 || len(spelling.TopRules) != 1 || spelling.TopRules[0].Count != 2 {
+		t.Fatalf("unexpected spelling breakdown %+v", spelling)
+	}
+	if report.IssueBreakdown[1].Category != LTCategoryAgreement || report.IssueBreakdown[2].Category != LTCategoryTypography {
+		t.Fatalf("expected display order spelling, agreement, typography, got %+v", report.IssueBreakdown)
+	}
+}
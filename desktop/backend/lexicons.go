@@ -0,0 +1,296 @@
+package backend
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/slop"
+	"book_dashboard/internal/workspace"
+)
+
+// Editable lexicons. Each is the built-in list adjusted by the workspace
+// files configs/lexicons/<name>.txt and, for the manuscript's genre,
+// configs/lexicons/<name>.<genre>.txt: one entry per line adds a word,
+// "-word" drops a built-in one and # starts a comment. The files are read at
+// the start of every analysis, so edits apply to the next run without a
+// restart.
+const (
+	// LexiconBadWords is the slop red-flag vocabulary.
+	LexiconBadWords = "bad_words"
+	// LexiconIntensifiers is the AI polish signal's intensifier lexicon.
+	LexiconIntensifiers = "intensifiers"
+)
+
+// LexiconInfo describes one lexicon file: its additions and removals
+// against the built-in list, and how many entries the result has.
+type LexiconInfo struct {
+	Name    string   `json:"name"`
+	Genre   string   `json:"genre,omitempty"`
+	Path    string   `json:"path"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Entries int      `json:"entries"`
+}
+
+type lexiconEdits struct {
+	added   []string
+	removed []string
+}
+
+func lexiconDefaults(name string) ([]string, error) {
+	switch name {
+	case LexiconBadWords:
+		return slop.DefaultBadWords(), nil
+	case LexiconIntensifiers:
+		return aidetect.DefaultIntensifiers(), nil
+	}
+	return nil, fmt.Errorf("unknown lexicon %q; use %s or %s", name, LexiconBadWords, LexiconIntensifiers)
+}
+
+func lexiconDir(workspaceRoot string) string {
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return ""
+	}
+	return filepath.Join(workspaceRoot, "configs", "lexicons")
+}
+
+func lexiconPath(workspaceRoot, name, genre string) string {
+	if key := slop.GenreKey(genre); key != "" {
+		name += "." + key
+	}
+	return filepath.Join(lexiconDir(workspaceRoot), name+".txt")
+}
+
+// readLexiconEdits reads a lexicon file; a missing file has no edits.
+func readLexiconEdits(path string) (lexiconEdits, bool, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lexiconEdits{}, false, nil
+	}
+	if err != nil {
+		return lexiconEdits{}, false, fmt.Errorf("read lexicon %s: %w", path, err)
+	}
+	var edits lexiconEdits
+	scanner := bufio.NewScanner(strings.NewReader(string(raw)))
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if word, ok := strings.CutPrefix(line, "-"); ok {
+			edits.removed = append(edits.removed, strings.TrimSpace(word))
+			continue
+		}
+		edits.added = append(edits.added, line)
+	}
+	return edits, true, nil
+}
+
+func writeLexiconEdits(path, name string, edits lexiconEdits) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s lexicon. One entry per line adds a word; -word drops a built-in one.\n", name)
+	for _, w := range edits.added {
+		b.WriteString(w + "\n")
+	}
+	for _, w := range edits.removed {
+		b.WriteString("-" + w + "\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write lexicon %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write lexicon %s: %w", path, err)
+	}
+	return nil
+}
+
+// lexiconProvenance records which files a loaded lexicon came from and a
+// fingerprint of its words, rather than the words themselves. A nil lexicon
+// is the built-in list.
+func lexiconProvenance(words, sources []string) map[string]any {
+	if words == nil {
+		return map[string]any{"source": "built-in"}
+	}
+	return map[string]any{"source": sources, "entries": len(words), "sha256": stageFingerprint(words)}
+}
+
+// loadLexicon returns the lexicon name for genre with the workspace edits
+// applied, and the files it read. It returns nil, so the built-in list is
+// used as is, when no file exists.
+func loadLexicon(workspaceRoot, name, genre string) ([]string, []string, error) {
+	defaults, err := lexiconDefaults(name)
+	if err != nil || strings.TrimSpace(workspaceRoot) == "" {
+		return nil, nil, err
+	}
+	paths := []string{lexiconPath(workspaceRoot, name, "")}
+	if slop.GenreKey(genre) != "" {
+		paths = append(paths, lexiconPath(workspaceRoot, name, genre))
+	}
+	set := map[string]bool{}
+	for _, w := range defaults {
+		set[w] = true
+	}
+	var sources []string
+	var errs []error
+	for _, path := range paths {
+		edits, found, readErr := readLexiconEdits(path)
+		if readErr != nil {
+			errs = append(errs, readErr)
+			continue
+		}
+		if !found {
+			continue
+		}
+		sources = append(sources, path)
+		for _, w := range edits.added {
+			set[w] = true
+		}
+		for _, w := range edits.removed {
+			delete(set, w)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	entries := make([]string, 0, len(set))
+	for w := range set {
+		entries = append(entries, w)
+	}
+	sort.Strings(entries)
+	return entries, sources, errors.Join(errs...)
+}
+
+// ListLexicons describes the lexicon files of the default workspace.
+func ListLexicons() ([]LexiconInfo, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return nil, err
+	}
+	return listLexicons(root)
+}
+
+// listLexicons describes the general file of every lexicon, whether or not
+// it exists yet, and each genre file that does.
+func listLexicons(workspaceRoot string) ([]LexiconInfo, error) {
+	out := []LexiconInfo{}
+	for _, name := range []string{LexiconBadWords, LexiconIntensifiers} {
+		info, err := lexiconInfo(workspaceRoot, name, "")
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, info)
+		genreFiles, _ := filepath.Glob(filepath.Join(lexiconDir(workspaceRoot), name+".*.txt"))
+		sort.Strings(genreFiles)
+		for _, path := range genreFiles {
+			genre := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), name+"."), ".txt")
+			info, err := lexiconInfo(workspaceRoot, name, genre)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, info)
+		}
+	}
+	return out, nil
+}
+
+func lexiconInfo(workspaceRoot, name, genre string) (LexiconInfo, error) {
+	defaults, err := lexiconDefaults(name)
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	path := lexiconPath(workspaceRoot, name, genre)
+	edits, _, err := readLexiconEdits(path)
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	entries, _, err := loadLexicon(workspaceRoot, name, genre)
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	if entries == nil {
+		entries = defaults
+	}
+	info := LexiconInfo{Name: name, Genre: slop.GenreKey(genre), Path: path, Added: edits.added, Removed: edits.removed, Entries: len(entries)}
+	if info.Added == nil {
+		info.Added = []string{}
+	}
+	if info.Removed == nil {
+		info.Removed = []string{}
+	}
+	return info, nil
+}
+
+// AddLexiconEntry adds word to lexicon name in the default workspace, for
+// genre only when genre is set.
+func AddLexiconEntry(name, genre, word string) (LexiconInfo, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	return editLexicon(root, name, genre, word, true)
+}
+
+// RemoveLexiconEntry drops word from lexicon name in the default workspace,
+// for genre only when genre is set.
+func RemoveLexiconEntry(name, genre, word string) (LexiconInfo, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	return editLexicon(root, name, genre, word, false)
+}
+
+// editLexicon adds or removes word. Adding takes back an earlier removal and
+// lists a word the built-in list lacks; removing takes back an earlier
+// addition and lists a built-in word as removed. A genre file always lists
+// the edit, since the general file may have changed the word.
+func editLexicon(workspaceRoot, name, genre, word string, add bool) (LexiconInfo, error) {
+	defaults, err := lexiconDefaults(name)
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" || strings.ContainsAny(word, "\n#") || strings.HasPrefix(word, "-") {
+		return LexiconInfo{}, fmt.Errorf("lexicon entry %q is empty or not a word", word)
+	}
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return LexiconInfo{}, fmt.Errorf("no workspace to keep the %s lexicon in", name)
+	}
+	path := lexiconPath(workspaceRoot, name, genre)
+	edits, _, err := readLexiconEdits(path)
+	if err != nil {
+		return LexiconInfo{}, err
+	}
+	builtIn := false
+	for _, w := range defaults {
+		builtIn = builtIn || w == word
+	}
+	edits.added = withoutWord(edits.added, word)
+	edits.removed = withoutWord(edits.removed, word)
+	switch {
+	case add && (!builtIn || genre != ""):
+		edits.added = append(edits.added, word)
+	case !add && (builtIn || genre != ""):
+		edits.removed = append(edits.removed, word)
+	}
+	if err := writeLexiconEdits(path, name, edits); err != nil {
+		return LexiconInfo{}, err
+	}
+	return lexiconInfo(workspaceRoot, name, genre)
+}
+
+func withoutWord(words []string, word string) []string {
+	out := words[:0]
+	for _, w := range words {
+		if w != word {
+			out = append(out, w)
+		}
+	}
+	return out
+}
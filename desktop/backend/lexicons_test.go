@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLexiconEditsLayerGeneralAndGenreFiles(t *testing.T) {
+	root := t.TempDir()
+	if entries, sources, err := loadLexicon(root, LexiconBadWords, "Thriller"); err != nil || entries != nil || sources != nil {
+		t.Fatalf("expected the built-in list without files, got %v %v %v", entries, sources, err)
+	}
+	if _, err := editLexicon(root, LexiconBadWords, "", "gunmetal", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := editLexicon(root, LexiconBadWords, "", "delve", false); err != nil {
+		t.Fatal(err)
+	}
+	info, err := editLexicon(root, LexiconBadWords, "Thriller", "Gunmetal", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Genre != "thriller" || !slices.Equal(info.Removed, []string{"gunmetal"}) || !strings.HasSuffix(info.Path, "bad_words.thriller.txt") {
+		t.Fatalf("unexpected genre lexicon %+v", info)
+	}
+
+	general, _, err := loadLexicon(root, LexiconBadWords, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(general, "gunmetal") || slices.Contains(general, "delve") || !slices.Contains(general, "tapestry") {
+		t.Fatalf("unexpected general lexicon %v", general)
+	}
+	thriller, sources, err := loadLexicon(root, LexiconBadWords, "thriller")
+	if err != nil || len(sources) != 2 {
+		t.Fatalf("expected both files read, got %v %v", sources, err)
+	}
+	if slices.Contains(thriller, "gunmetal") || slices.Contains(thriller, "delve") {
+		t.Fatalf("expected the genre file to drop gunmetal, got %v", thriller)
+	}
+
+	// Adding back a removed built-in word takes the removal back.
+	info, err = editLexicon(root, LexiconBadWords, "", "delve", true)
+	if err != nil || len(info.Removed) != 0 || !slices.Equal(info.Added, []string{"gunmetal"}) {
+		t.Fatalf("unexpected general lexicon %+v %v", info, err)
+	}
+	// Hand edits are picked up on the next load.
+	if err := os.WriteFile(info.Path, []byte("# mine\nGUNMETAL\n-tapestry\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	general, _, _ = loadLexicon(root, LexiconBadWords, "")
+	if slices.Contains(general, "tapestry") || !slices.Contains(general, "delve") {
+		t.Fatalf("expected the hand-edited file applied, got %v", general)
+	}
+
+	lexicons, err := listLexicons(root)
+	if err != nil || len(lexicons) != 3 || lexicons[1].Genre != "thriller" || lexicons[2].Name != LexiconIntensifiers {
+		t.Fatalf("unexpected lexicons %+v %v", lexicons, err)
+	}
+	if _, err := editLexicon(root, "clichés", "", "word", true); err == nil {
+		t.Fatal("expected an unknown lexicon rejected")
+	}
+	if _, err := editLexicon(root, LexiconIntensifiers, "", "  ", true); err == nil {
+		t.Fatal("expected an empty entry rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"book_dashboard/internal/slop"
+)
+
+// Kinds of excerpt lint issue.
+const (
+	LintEcho    = "echo"
+	LintFilter  = "filter"
+	LintRedFlag = "red_flag"
+)
+
+const (
+	// echoDistance is how many words apart two uses of a word still echo.
+	echoDistance = 30
+	// maxLintWords keeps a pasted chapter inside the latency budget; the
+	// rest is not checked.
+	maxLintWords = 10000
+)
+
+var (
+	lintWordPattern = regexp.MustCompile(`\p{L}[\p{L}'’]*`)
+	// filterWordPattern finds perception verbs that filter a scene through a
+	// character ("she saw", "he could hear") instead of showing it.
+	filterWordPattern = regexp.MustCompile(`(?i)\b(?:I|he|she|they|we|you)\s+(?:could\s+|can\s+)?(saw|see|sees|watched|heard|hear|hears|felt|feel|feels|noticed|notices|realized|realised|realizes|wondered|wonders|knew|decided|seemed|seems)\b`)
+	echoStopwords     = wordSet("this", "that", "these", "those", "with", "from", "into", "have", "been", "were", "which", "their", "there", "they", "them", "what", "when", "where", "while", "would", "could", "should", "about", "also", "than", "then", "more", "most", "some", "such", "very", "just", "only", "even", "much", "many", "over", "because", "said", "your", "will", "here", "back", "down", "before", "after", "still", "again", "other", "like", "didn't", "don't", "it's", "that's", "she'd", "he'd", "won't", "can't", "through", "each", "once", "does", "being", "upon")
+)
+
+// ExcerptLint is the live-feedback result for a pasted or typed excerpt. It
+// runs no services and touches no project state. Offsets count UTF-16 code
+// units, the way the frontend indexes strings.
+type ExcerptLint struct {
+	WordCount int             `json:"wordCount"`
+	Truncated bool            `json:"truncated"`
+	Issues    []LintIssue     `json:"issues"`
+	Counts    map[string]int  `json:"counts"`
+	Slop      slop.LintReport `json:"slop"`
+	Flags     []string        `json:"flags"`
+	ElapsedMs int64           `json:"elapsedMs"`
+}
+
+type LintIssue struct {
+	Kind    string `json:"kind"`
+	Word    string `json:"word"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Message string `json:"message"`
+}
+
+// LintExcerpt runs the fast offline checks: echo words, filter words and
+// the slop lint subset.
+func LintExcerpt(text string) ExcerptLint {
+	started := time.Now()
+	out := ExcerptLint{Issues: []LintIssue{}, Counts: map[string]int{}, Flags: []string{}}
+	locs := lintWordPattern.FindAllStringIndex(text, -1)
+	if len(locs) > maxLintWords {
+		text = text[:locs[maxLintWords][0]]
+		locs = locs[:maxLintWords]
+		out.Truncated = true
+	}
+	out.WordCount = len(locs)
+	offsets := utf16Offsets(text)
+
+	last := map[string]int{}
+	for i, loc := range locs {
+		word := text[loc[0]:loc[1]]
+		first, _ := utf8.DecodeRuneInString(word)
+		key := strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+		// Capitalized words are mostly names, which repeat by necessity.
+		if utf8.RuneCountInString(word) < 4 || unicode.IsUpper(first) || echoStopwords[key] {
+			continue
+		}
+		if prev, ok := last[key]; ok && i-prev <= echoDistance {
+			out.Issues = append(out.Issues, LintIssue{
+				Kind:    LintEcho,
+				Word:    word,
+				Start:   offsets[loc[0]],
+				End:     offsets[loc[1]],
+				Message: fmt.Sprintf("%q is used again %d words later.", key, i-prev),
+			})
+		}
+		last[key] = i
+	}
+	for _, m := range filterWordPattern.FindAllStringSubmatchIndex(text, -1) {
+		out.Issues = append(out.Issues, LintIssue{
+			Kind:    LintFilter,
+			Word:    text[m[2]:m[3]],
+			Start:   offsets[m[0]],
+			End:     offsets[m[1]],
+			Message: fmt.Sprintf("Filter word %q: consider showing what is perceived.", strings.ToLower(text[m[2]:m[3]])),
+		})
+	}
+	out.Slop = slop.Lint(text)
+	for i, hit := range out.Slop.RedFlagWords {
+		hit.Start, hit.End = offsets[hit.Start], offsets[hit.End]
+		out.Slop.RedFlagWords[i] = hit
+		out.Issues = append(out.Issues, LintIssue{
+			Kind:    LintRedFlag,
+			Word:    hit.Word,
+			Start:   hit.Start,
+			End:     hit.End,
+			Message: fmt.Sprintf("%q is common in machine-generated prose.", hit.Word),
+		})
+	}
+	sort.SliceStable(out.Issues, func(i, j int) bool { return out.Issues[i].Start < out.Issues[j].Start })
+	for _, issue := range out.Issues {
+		out.Counts[issue.Kind]++
+	}
+	out.Flags = append(out.Flags, slop.FlagTexts(out.Slop.Flags)...)
+	if out.Truncated {
+		out.Flags = append(out.Flags, fmt.Sprintf("Only the first %d words were checked.", maxLintWords))
+	}
+	out.ElapsedMs = time.Since(started).Milliseconds()
+	return out
+}
+
+// utf16Offsets maps every byte offset of text that starts a rune, and
+// len(text), to its UTF-16 offset.
+func utf16Offsets(text string) []int {
+	out := make([]int, len(text)+1)
+	n := 0
+	for i, r := range text {
+		out[i] = n
+		n += utf16.RuneLen(r)
+	}
+	out[len(text)] = n
+	return out
+}
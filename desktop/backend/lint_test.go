@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestLintExcerptFindsEchoFilterAndRedFlagWords(t *testing.T) {
+	text := "Mara—tired—opened the door. She felt the cold. The door creaked as Mara stepped through the tapestry of shadows."
+	lint := LintExcerpt(text)
+	if lint.Counts[LintEcho] != 1 || lint.Counts[LintFilter] != 1 || lint.Counts[LintRedFlag] != 1 {
+		t.Fatalf("unexpected counts %v: %+v", lint.Counts, lint.Issues)
+	}
+	units := utf16.Encode([]rune(text))
+	for _, issue := range lint.Issues {
+		got := string(utf16.Decode(units[issue.Start:issue.End]))
+		if !strings.Contains(got, issue.Word) {
+			t.Fatalf("offsets of %+v select %q", issue, got)
+		}
+	}
+	if echo := lint.Issues[1]; echo.Kind != LintEcho || echo.Word != "door" {
+		t.Fatalf("want the second door flagged as an echo, got %+v", lint.Issues)
+	}
+	if lint.Issues[0].Kind != LintFilter || lint.Issues[0].Word != "felt" {
+		t.Fatalf("want issues in text order, got %+v", lint.Issues)
+	}
+}
+
+var longLintText = strings.Repeat("The harbor lights trembled while she watched the boats come home. He heard nothing at all. ", 1500)
+
+func TestLintExcerptTruncatesLongText(t *testing.T) {
+	lint := LintExcerpt(longLintText)
+	if !lint.Truncated || lint.WordCount != maxLintWords {
+		t.Fatalf("want the excerpt cut at %d words, got %d (truncated=%t)", maxLintWords, lint.WordCount, lint.Truncated)
+	}
+}
+
+// BenchmarkLintExcerptLongText tracks the editor's lint budget (under 200ms
+// per call); timing belongs here rather than in a test that flakes on slow
+// or race-instrumented runs.
+func BenchmarkLintExcerptLongText(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		LintExcerpt(longLintText)
+	}
+}
@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// lmSamplesPerWindow is how many next-word predictions are sampled per
+	// AI window; each is one model call.
+	lmSamplesPerWindow = 8
+	// lmMinSamples is how many predictions a window needs before a timeout
+	// still yields a score.
+	lmMinSamples = 4
+	// lmContextWords is how much preceding text the model sees for each
+	// prediction, and lmMinContextWords the least it needs.
+	lmContextWords    = 64
+	lmMinContextWords = 8
+	lmTopLogprobs     = 20
+	// Mean log-probabilities from lmSurprisedMean (human-like surprise) to
+	// lmFluentMean (machine-like predictability) map onto fluency, and a
+	// variance of lmBurstyVariance or more counts as fully bursty.
+	lmSurprisedMean  = -6.0
+	lmFluentMean     = -1.5
+	lmBurstyVariance = 12.0
+)
+
+// aiLMSmoothnessScorer estimates how predictable a window is to a local
+// Ollama model. Ollama reports log-probabilities only for generated tokens,
+// so the scorer samples positions through the window, asks for one token
+// after the preceding words, and reads the log-probability of the word the
+// author actually wrote from the top candidates. Low perplexity (a high mean)
+// and low burstiness (a low variance) both read as machine-like.
+type aiLMSmoothnessScorer struct {
+	endpoint string
+	model    string
+	client   *http.Client
+}
+
+func newAILMSmoothnessScorer(model string) aiLMSmoothnessScorer {
+	return aiLMSmoothnessScorer{
+		endpoint: ollamaGenerateEndpoint(),
+		model:    model,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func lmSmoothnessModel() string {
+	return ollamaModel("OLLAMA_LM_MODEL", "OLLAMA_LANGUAGE_MODEL")
+}
+
+type ollamaLogprob struct {
+	Token       string          `json:"token"`
+	Logprob     float64         `json:"logprob"`
+	TopLogprobs []ollamaLogprob `json:"top_logprobs"`
+}
+
+func (s aiLMSmoothnessScorer) ScoreWindow(ctx context.Context, text string) (float64, error) {
+	words := strings.Fields(text)
+	positions := lmSamplePositions(len(words), lmSamplesPerWindow)
+	if len(positions) == 0 {
+		return 0, nil
+	}
+	logprobs := make([]float64, 0, len(positions))
+	for _, pos := range positions {
+		lp, err := s.nextWordLogprob(ctx, words[max(0, pos-lmContextWords):pos], words[pos])
+		if err != nil {
+			// A slow model that ran out of time on a well-sampled window
+			// still has enough to score.
+			if ctx.Err() != nil && len(logprobs) >= lmMinSamples {
+				break
+			}
+			return 0, err
+		}
+		logprobs = append(logprobs, lp)
+	}
+	mean, variance := logprobStats(logprobs)
+	return lmSmoothness(mean, variance), nil
+}
+
+// nextWordLogprob asks the model for one token after the preceding words and
+// returns the log-probability of next among its top candidates.
+func (s aiLMSmoothnessScorer) nextWordLogprob(ctx context.Context, preceding []string, next string) (float64, error) {
+	payload := map[string]any{
+		"model":        s.model,
+		"prompt":       strings.Join(preceding, " "),
+		"raw":          true,
+		"stream":       false,
+		"logprobs":     true,
+		"top_logprobs": lmTopLogprobs,
+		"options":      map[string]any{"temperature": 0, "num_predict": 1},
+	}
+	raw, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var out struct {
+		Logprobs     []ollamaLogprob `json:"logprobs"`
+		EvalCount    int             `json:"eval_count"`
+		EvalDuration int64           `json:"eval_duration"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return 0, err
+	}
+	noteInference(s.model, out.EvalCount, out.EvalDuration)
+	if len(out.Logprobs) == 0 {
+		return 0, fmt.Errorf("model returned no logprobs; Ollama 0.12.11 or later is needed")
+	}
+	return candidateLogprob(out.Logprobs[0], next), nil
+}
+
+// candidateLogprob finds next among the generated token and its top
+// candidates. Tokens are often word pieces, so the longest candidate the word
+// starts with wins, which credits the word with its first piece's
+// probability. A word missing from the candidates is scored just below the
+// least likely of them.
+func candidateLogprob(generated ollamaLogprob, next string) float64 {
+	next = strings.ToLower(next)
+	candidates := append([]ollamaLogprob{generated}, generated.TopLogprobs...)
+	best, bestLen := 0.0, 0
+	lowest := 0.0
+	for _, c := range candidates {
+		lowest = math.Min(lowest, c.Logprob)
+		token := strings.ToLower(strings.TrimSpace(c.Token))
+		if token == "" || !strings.HasPrefix(next, token) {
+			continue
+		}
+		if len(token) > bestLen || (len(token) == bestLen && c.Logprob > best) {
+			best, bestLen = c.Logprob, len(token)
+		}
+	}
+	if bestLen > 0 {
+		return best
+	}
+	return lowest - 1
+}
+
+// lmSamplePositions spreads up to n word positions evenly over a window,
+// leaving each at least lmMinContextWords of context.
+func lmSamplePositions(words, n int) []int {
+	span := words - lmMinContextWords
+	if span <= 0 || n <= 0 {
+		return nil
+	}
+	n = min(n, span)
+	out := make([]int, n)
+	for i := range out {
+		out[i] = lmMinContextWords + i*span/n
+	}
+	return out
+}
+
+func logprobStats(values []float64) (mean, variance float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, variance / float64(len(values))
+}
+
+// lmSmoothness combines fluency (how predictable the words are) with
+// evenness (how little that predictability varies) into a 0..1 signal.
+func lmSmoothness(mean, variance float64) float64 {
+	fluency := clampAIDetect((mean - lmSurprisedMean) / (lmFluentMean - lmSurprisedMean))
+	evenness := clampAIDetect(1 - variance/lmBurstyVariance)
+	return clampAIDetect(0.6*fluency + 0.4*evenness)
+}
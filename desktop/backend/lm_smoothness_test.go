@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// lmServer answers every request with the given top candidates and records
+// the prompts it was sent.
+func lmServer(t *testing.T, top []ollamaLogprob, prompts *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt      string         `json:"prompt"`
+			Raw         bool           `json:"raw"`
+			Logprobs    bool           `json:"logprobs"`
+			TopLogprobs int            `json:"top_logprobs"`
+			Options     map[string]any `json:"options"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Raw || !req.Logprobs || req.TopLogprobs != lmTopLogprobs || req.Options["num_predict"] != float64(1) {
+			t.Errorf("unexpected request %+v", req)
+		}
+		*prompts = append(*prompts, req.Prompt)
+		if top == nil {
+			_ = json.NewEncoder(w).Encode(map[string]any{"response": "x"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"logprobs": []ollamaLogprob{{Token: top[0].Token, Logprob: top[0].Logprob, TopLogprobs: top}}})
+	}))
+}
+
+func TestLMSmoothnessScoresPredictableTextHigher(t *testing.T) {
+	text := strings.TrimSpace(strings.Repeat("the cat sat on the mat ", 5))
+	var prompts []string
+	fluent := lmServer(t, []ollamaLogprob{{Token: " the", Logprob: -0.5}, {Token: " cat", Logprob: -1}, {Token: " sat", Logprob: -1}, {Token: " on", Logprob: -1}, {Token: " m", Logprob: -1.5}}, &prompts)
+	defer fluent.Close()
+	scorer := aiLMSmoothnessScorer{endpoint: fluent.URL, model: "test", client: fluent.Client()}
+	high, err := scorer.ScoreWindow(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != lmSamplesPerWindow || len(strings.Fields(prompts[0])) != lmMinContextWords {
+		t.Fatalf("expected %d samples after %d words of context, got %q", lmSamplesPerWindow, lmMinContextWords, prompts)
+	}
+
+	surprised := lmServer(t, []ollamaLogprob{{Token: " dog", Logprob: -0.2}, {Token: " bird", Logprob: -8}}, &prompts)
+	defer surprised.Close()
+	scorer.endpoint, scorer.client = surprised.URL, surprised.Client()
+	low, err := scorer.ScoreWindow(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if high < 0.8 || low > 0.5 {
+		t.Fatalf("expected predictable text to score high and surprising text low, got %.2f and %.2f", high, low)
+	}
+}
+
+func TestLMSmoothnessNeedsLogprobs(t *testing.T) {
+	var prompts []string
+	server := lmServer(t, nil, &prompts)
+	defer server.Close()
+	scorer := aiLMSmoothnessScorer{endpoint: server.URL, model: "test", client: server.Client()}
+	if _, err := scorer.ScoreWindow(context.Background(), strings.Repeat("word ", 20)); err == nil || !strings.Contains(err.Error(), "no logprobs") {
+		t.Fatalf("expected an error from a server without logprobs, got %v", err)
+	}
+	if score, err := scorer.ScoreWindow(context.Background(), "too short"); err != nil || score != 0 {
+		t.Fatalf("expected a window too short to sample to score 0, got %v %v", score, err)
+	}
+}
+
+func TestCandidateLogprobPrefersLongestPiece(t *testing.T) {
+	generated := ollamaLogprob{Token: " t", Logprob: -0.1, TopLogprobs: []ollamaLogprob{{Token: " t", Logprob: -0.1}, {Token: " the", Logprob: -0.7}, {Token: " a", Logprob: -4}}}
+	if got := candidateLogprob(generated, "the"); got != -0.7 {
+		t.Fatalf("expected the whole-word candidate, got %v", got)
+	}
+	if got := candidateLogprob(generated, "dragon"); got != -5 {
+		t.Fatalf("expected a missing word just below the least likely candidate, got %v", got)
+	}
+}
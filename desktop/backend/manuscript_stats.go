@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Word-class proxies: closed word lists and suffixes stand in for a
+// part-of-speech tagger. They are rough, but comparable between chapters and
+// books.
+var (
+	statsPronouns      = wordSet("i", "me", "my", "mine", "myself", "you", "your", "yours", "yourself", "he", "him", "his", "himself", "she", "her", "hers", "herself", "it", "its", "itself", "we", "us", "our", "ours", "ourselves", "they", "them", "their", "theirs", "themselves")
+	statsFunctionWords = wordSet("the", "a", "an", "and", "but", "or", "nor", "so", "yet", "for", "of", "to", "in", "on", "at", "by", "with", "from", "into", "onto", "over", "under", "about", "after", "before", "through", "between", "against", "as", "than", "that", "which", "who", "if", "because", "while", "when", "although", "though", "until", "is", "was", "are", "were", "be", "been", "being", "not")
+	// statsNotAdverbs end in -ly without being adverbs.
+	statsNotAdverbs = wordSet("only", "family", "reply", "supply", "apply", "fly", "july", "ally", "belly", "bully", "holy", "ugly", "silly", "lily", "jelly", "rely", "early", "lonely", "lovely", "friendly", "likely", "daily", "elderly", "curly", "chilly", "hilly", "jolly", "wily", "oily", "sly", "ply", "italy", "assembly", "anomaly", "monopoly")
+)
+
+// ManuscriptStats holds the raw distributions behind the scores, for
+// histograms and for power users to export and analyze themselves. Lengths
+// are in words, in reading order within each chapter.
+type ManuscriptStats struct {
+	Chapters   []ChapterStats      `json:"chapters"`
+	Sentences  DistributionSummary `json:"sentences"`
+	Paragraphs DistributionSummary `json:"paragraphs"`
+	// ChapterWords summarizes the chapter lengths.
+	ChapterWords DistributionSummary `json:"chapterWords"`
+}
+
+// ChapterStats is one chapter's distributions and word-class proxies. The
+// proxies are per 100 words: -ly adverbs, pronouns, -ing words and function
+// words (articles, prepositions, conjunctions and forms of "be").
+type ChapterStats struct {
+	Chapter             int     `json:"chapter"`
+	Title               string  `json:"title"`
+	Words               int     `json:"words"`
+	SentenceLengths     []int   `json:"sentenceLengths"`
+	ParagraphLengths    []int   `json:"paragraphLengths"`
+	DialogueRatio       float64 `json:"dialogueRatio"`
+	AdverbsPer100       float64 `json:"adverbsPer100"`
+	PronounsPer100      float64 `json:"pronounsPer100"`
+	IngWordsPer100      float64 `json:"ingWordsPer100"`
+	FunctionWordsPer100 float64 `json:"functionWordsPer100"`
+}
+
+// DistributionSummary describes one distribution; StdDev is the population
+// standard deviation.
+type DistributionSummary struct {
+	Count  int     `json:"count"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stdDev"`
+}
+
+func emptyManuscriptStats() ManuscriptStats {
+	return ManuscriptStats{Chapters: []ChapterStats{}}
+}
+
+// measureManuscriptStats collects the sentence and paragraph lengths, dialogue
+// ratio and word-class proxies of every chapter.
+func measureManuscriptStats(chapters []chapter) ManuscriptStats {
+	stats := emptyManuscriptStats()
+	var sentences, paragraphs, chapterWords []int
+	for _, ch := range chapters {
+		cs := ChapterStats{Chapter: ch.index, Title: ch.title, SentenceLengths: []int{}, ParagraphLengths: []int{}}
+		dialogueWords, adverbs, pronouns, ings, functionWords := 0, 0, 0, 0, 0
+		for _, paragraph := range chapterParagraphs(ch.text) {
+			all := strings.Fields(paragraph)
+			cs.Words += len(all)
+			cs.ParagraphLengths = append(cs.ParagraphLengths, len(all))
+			dialogueWords += len(all) - len(strings.Fields(narrationOnly(paragraph)))
+			for _, s := range splitSentences(paragraph) {
+				if n := len(strings.Fields(s)); n > 0 {
+					cs.SentenceLengths = append(cs.SentenceLengths, n)
+				}
+			}
+			for _, w := range wordPattern.FindAllString(strings.ToLower(paragraph), -1) {
+				switch {
+				case statsPronouns[w]:
+					pronouns++
+				case statsFunctionWords[w]:
+					functionWords++
+				case len(w) > 4 && strings.HasSuffix(w, "ly") && !statsNotAdverbs[w]:
+					adverbs++
+				case len(w) > 4 && strings.HasSuffix(w, "ing"):
+					ings++
+				}
+			}
+		}
+		if cs.Words == 0 {
+			continue
+		}
+		per100 := func(n int) float64 { return round2(float64(n) * 100 / float64(cs.Words)) }
+		cs.DialogueRatio = round2(float64(dialogueWords) / float64(cs.Words))
+		cs.AdverbsPer100 = per100(adverbs)
+		cs.PronounsPer100 = per100(pronouns)
+		cs.IngWordsPer100 = per100(ings)
+		cs.FunctionWordsPer100 = per100(functionWords)
+		stats.Chapters = append(stats.Chapters, cs)
+		sentences = append(sentences, cs.SentenceLengths...)
+		paragraphs = append(paragraphs, cs.ParagraphLengths...)
+		chapterWords = append(chapterWords, cs.Words)
+	}
+	stats.Sentences = summarizeDistribution(sentences)
+	stats.Paragraphs = summarizeDistribution(paragraphs)
+	stats.ChapterWords = summarizeDistribution(chapterWords)
+	return stats
+}
+
+func summarizeDistribution(values []int) DistributionSummary {
+	if len(values) == 0 {
+		return DistributionSummary{}
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		floats[i] = float64(v)
+	}
+	mean, sd := meanAndDeviation(floats)
+	median := float64(sorted[len(sorted)/2])
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[len(sorted)/2-1]+sorted[len(sorted)/2]) / 2
+	}
+	return DistributionSummary{
+		Count:  len(values),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   round2(mean),
+		Median: median,
+		StdDev: round2(sd),
+	}
+}
+
+// StatsCSV writes the raw distributions as one CSV table in long form: a row
+// per value with its chapter, the series it belongs to and its position in
+// the chapter (0 for per-chapter figures).
+func StatsCSV(stats ManuscriptStats) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write([]string{"chapter", "title", "series", "index", "value"})
+	row := func(cs ChapterStats, series string, index int, value string) {
+		_ = w.Write([]string{strconv.Itoa(cs.Chapter), cs.Title, series, strconv.Itoa(index), value})
+	}
+	for _, cs := range stats.Chapters {
+		for i, n := range cs.SentenceLengths {
+			row(cs, "sentence_words", i+1, strconv.Itoa(n))
+		}
+		for i, n := range cs.ParagraphLengths {
+			row(cs, "paragraph_words", i+1, strconv.Itoa(n))
+		}
+		row(cs, "chapter_words", 0, strconv.Itoa(cs.Words))
+		for _, f := range []struct {
+			series string
+			value  float64
+		}{
+			{"dialogue_ratio", cs.DialogueRatio},
+			{"adverbs_per_100", cs.AdverbsPer100},
+			{"pronouns_per_100", cs.PronounsPer100},
+			{"ing_words_per_100", cs.IngWordsPer100},
+			{"function_words_per_100", cs.FunctionWordsPer100},
+		} {
+			row(cs, f.series, 0, strconv.FormatFloat(f.value, 'f', -1, 64))
+		}
+	}
+	w.Flush()
+	return b.String()
+}
+
+// describeDistribution is the one-line summary the log and plain report use.
+func describeDistribution(d DistributionSummary) string {
+	if d.Count == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("n=%d mean=%.1f median=%s sd=%.1f range=%d-%d", d.Count, d.Mean, strconv.FormatFloat(d.Median, 'f', -1, 64), d.StdDev, d.Min, d.Max)
+}
@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasureManuscriptStatsKeepsRawDistributions(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, title: "One", text: "She walked slowly home. It was raining.\n\n\"Come in,\" he said quietly."},
+		{index: 2, title: "Two", text: "The house stood at the end of the lane and nothing moved in it."},
+		{index: 3, title: "Empty", text: ""},
+	}
+	stats := measureManuscriptStats(chapters)
+	if len(stats.Chapters) != 2 {
+		t.Fatalf("expected the empty chapter skipped, got %+v", stats.Chapters)
+	}
+	one := stats.Chapters[0]
+	if got := one.SentenceLengths; len(got) != 3 || got[0] != 4 || got[1] != 3 || got[2] != 5 {
+		t.Fatalf("unexpected sentence lengths %v", got)
+	}
+	if got := one.ParagraphLengths; len(got) != 2 || got[0] != 7 || got[1] != 5 {
+		t.Fatalf("unexpected paragraph lengths %v", got)
+	}
+	if one.Words != 12 || one.DialogueRatio == 0 || one.AdverbsPer100 == 0 || one.PronounsPer100 == 0 || one.IngWordsPer100 == 0 {
+		t.Fatalf("expected dialogue and word-class proxies measured, got %+v", one)
+	}
+	if two := stats.Chapters[1]; two.DialogueRatio != 0 || two.AdverbsPer100 != 0 || two.FunctionWordsPer100 == 0 {
+		t.Fatalf("unexpected proxies for the narration chapter %+v", two)
+	}
+	want := DistributionSummary{Count: 4, Min: 3, Max: 14, Mean: 6.5, Median: 4.5, StdDev: 4.39}
+	if stats.Sentences != want {
+		t.Fatalf("unexpected sentence summary %+v, want %+v", stats.Sentences, want)
+	}
+	if stats.ChapterWords.Count != 2 || stats.ChapterWords.Median != 13 {
+		t.Fatalf("unexpected chapter summary %+v", stats.ChapterWords)
+	}
+}
+
+func TestStatsCSVWritesOneRowPerValue(t *testing.T) {
+	stats := measureManuscriptStats([]chapter{{index: 4, title: "Four, Again", text: "One two three. Four five."}})
+	lines := strings.Split(strings.TrimSpace(StatsCSV(stats)), "\n")
+	if lines[0] != "chapter,title,series,index,value" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	// 2 sentences, 1 paragraph, the chapter length and 5 per-chapter figures.
+	if len(lines) != 1+2+1+1+5 {
+		t.Fatalf("unexpected rows %q", lines)
+	}
+	if lines[1] != `4,"Four, Again",sentence_words,1,3` || lines[4] != `4,"Four, Again",chapter_words,0,5` {
+		t.Fatalf("unexpected rows %q", lines)
+	}
+}
@@ -0,0 +1,264 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"book_dashboard/internal/workspace"
+)
+
+const (
+	// genreDriftShare is how far a genre's share of a canary may move before
+	// the change counts as material.
+	genreDriftShare = 0.2
+	// safetyDriftPoints is the same for the 0-100 safety scores.
+	safetyDriftPoints = 15
+)
+
+// canarySample is a fixed excerpt sent to a model to see whether it still
+// answers the way it did.
+type canarySample struct {
+	id   string
+	text string
+}
+
+// genreCanaries each lean clearly toward one genre, so a model update that
+// reshuffles the mixture shows up as a moved share or a new top genre.
+var genreCanaries = []canarySample{
+	{id: "thriller", text: "The detonator blinked on the dashboard, counting down from ninety. Reyes kept both hands on the wheel and one eye on the mirror, where the black sedan had closed to three car lengths. Somewhere in the city a handler was waiting for her call, and somewhere behind her a man with a rifle was waiting for her to make it. She took the off-ramp at sixty, felt the tires slide, and counted. Eighty seconds. The bridge was two miles away, the river below it deep enough to swallow the car and whatever the bomb left of it."},
+	{id: "romance", text: "Ellie had sworn she would not look at him during the toast, and she looked at him anyway. Daniel stood by the window with his sleeves rolled up, laughing at something her sister had said, and the old ache came back as if the last six years had never happened. When he finally turned and caught her watching, he did not look away. He crossed the room slowly, the way he used to, and asked whether she still hated dancing. She said she did. He held out his hand, and she took it."},
+	{id: "fantasy", text: "The dragon-priests of Vael had not sung the binding hymn in three hundred years, and Maren knew only half of it. She knelt in the ash of the old temple with the runestone cold against her palms while the sky above the mountains split with violet fire. Her mentor's staff lay broken beside her. The wards were failing; she could feel them unravel like rotten thread. If the Hollow King crossed the river before dawn, every village in the valley would belong to him, and the elves would not come again."},
+}
+
+// safetyCanaries sit at both ends of the content scale.
+var safetyCanaries = []canarySample{
+	{id: "gentle", text: "Grandma Rose baked lemon bars every Sunday, and every Sunday Theo and his little sister argued over the corner pieces. This week they agreed to share. They sat on the porch swing with powdered sugar on their noses, watching the neighbor's dog chase butterflies across the yard until the sun went down and the fireflies came out."},
+	{id: "graphic", text: "Kessler broke the man's fingers one at a time while the others watched. Blood pooled on the concrete under the chair. \"Where is the money, you worthless piece of shit?\" he screamed, then drove the knife into the man's thigh and twisted it. The prisoner vomited. Kessler lit a cigarette, took a long swallow of whiskey from the bottle and promised that the next cut would be to the throat."},
+}
+
+// canaryReference is what a model answered to the canaries, and which build
+// of it answered.
+type canaryReference struct {
+	Model      string                  `json:"model"`
+	Digest     string                  `json:"digest"`
+	RecordedAt string                  `json:"recorded_at"`
+	Genre      map[string][]GenreScore `json:"genre,omitempty"`
+	Safety     map[string]safetyResult `json:"safety,omitempty"`
+}
+
+// driftCheck names a model and which of its classifications the run relies on.
+type driftCheck struct {
+	model  string
+	genre  bool
+	safety bool
+}
+
+// checkModelDrift compares each model's current build against the canary
+// answers recorded for it. A model with no recorded answers, or whose
+// recorded build is still installed, costs one /api/tags call; only a new
+// build sends the canaries again. The new answers then become the reference.
+func checkModelDrift(workspaceRoot string, checks []driftCheck, addLog func(level, stage, message, detail string)) []ModelDrift {
+	merged := []driftCheck{}
+	index := map[string]int{}
+	for _, c := range checks {
+		if i, ok := index[c.model]; ok {
+			merged[i].genre = merged[i].genre || c.genre
+			merged[i].safety = merged[i].safety || c.safety
+			continue
+		}
+		index[c.model] = len(merged)
+		merged = append(merged, c)
+	}
+
+	digests, err := ollamaModelDigests()
+	if err != nil {
+		addLog("INFO", "MODELS", "Model drift check skipped; installed models unknown", err.Error())
+		return nil
+	}
+	out := []ModelDrift{}
+	for _, c := range merged {
+		digest := ""
+		for name, d := range digests {
+			if sameModel(name, c.model) {
+				digest = d
+				break
+			}
+		}
+		if digest == "" {
+			addLog("INFO", "MODELS", "Model drift check skipped; model not installed", c.model)
+			continue
+		}
+		var ref canaryReference
+		found, err := workspace.LoadCanary(workspaceRoot, c.model, &ref)
+		if err != nil {
+			addLog("RISK", "MODELS", "Canary reference unreadable; recording a new one", err.Error())
+			found = false
+		}
+		if found && ref.Digest == digest && (!c.genre || len(ref.Genre) > 0) && (!c.safety || len(ref.Safety) > 0) {
+			continue
+		}
+		current, err := runCanaries(c, digest)
+		if err != nil {
+			addLog("RISK", "MODELS", "Canary prompts failed; model drift not checked", fmt.Sprintf("model=%s err=%v", c.model, err))
+			continue
+		}
+		if found && ref.Digest != digest {
+			drift := compareCanaries(ref, current)
+			out = append(out, drift)
+			if drift.Material {
+				addLog("RISK", "MODELS", "Model answers drifted after an update", drift.Warning+" "+strings.Join(drift.Changes, "; "))
+			} else {
+				addLog("INFO", "MODELS", "Model updated; canary answers unchanged", fmt.Sprintf("model=%s digest=%s", c.model, shortDigest(digest)))
+			}
+		} else if !found {
+			addLog("INFO", "MODELS", "Canary reference recorded", fmt.Sprintf("model=%s digest=%s", c.model, shortDigest(digest)))
+		}
+		if err := workspace.SaveCanary(workspaceRoot, c.model, current); err != nil {
+			addLog("RISK", "MODELS", "Canary reference not saved", err.Error())
+		}
+	}
+	return out
+}
+
+func runCanaries(c driftCheck, digest string) (canaryReference, error) {
+	ref := canaryReference{Model: c.model, Digest: digest, RecordedAt: time.Now().Format(time.RFC3339)}
+	if c.genre {
+		classifier := newGenreClassifier()
+		classifier.model = c.model
+		ref.Genre = map[string][]GenreScore{}
+		for _, s := range genreCanaries {
+			decision, err := classifier.classifyWithOllama(s.text)
+			if err != nil {
+				return canaryReference{}, fmt.Errorf("genre canary %s: %w", s.id, err)
+			}
+			ref.Genre[s.id] = decision.Scores
+		}
+	}
+	if c.safety {
+		ref.Safety = map[string]safetyResult{}
+		for _, s := range safetyCanaries {
+			result, err := analyzeSafetyWithOllama(nil, s.text)
+			if err != nil {
+				return canaryReference{}, fmt.Errorf("safety canary %s: %w", s.id, err)
+			}
+			ref.Safety[s.id] = result
+		}
+	}
+	return ref, nil
+}
+
+// compareCanaries lists the answers that moved materially between the
+// reference and the current build.
+func compareCanaries(ref, current canaryReference) ModelDrift {
+	drift := ModelDrift{
+		Model:               current.Model,
+		PreviousDigest:      ref.Digest,
+		Digest:              current.Digest,
+		ReferenceRecordedAt: ref.RecordedAt,
+		Changes:             []string{},
+	}
+	for _, s := range genreCanaries {
+		before, ok := ref.Genre[s.id]
+		after, ok2 := current.Genre[s.id]
+		if !ok || !ok2 {
+			continue
+		}
+		beforeTop, _ := topGenre(before)
+		afterTop, _ := topGenre(after)
+		if beforeTop != afterTop {
+			drift.Changes = append(drift.Changes, fmt.Sprintf("%s canary: top genre %s -> %s", s.id, beforeTop, afterTop))
+			continue
+		}
+		shares := map[string]float64{}
+		for _, g := range before {
+			shares[g.Genre] -= g.Score
+		}
+		for _, g := range after {
+			shares[g.Genre] += g.Score
+		}
+		for _, genre := range genreOrder {
+			if delta := shares[genre]; math.Abs(delta) > genreDriftShare {
+				drift.Changes = append(drift.Changes, fmt.Sprintf("%s canary: %s share %+.2f", s.id, genre, delta))
+			}
+		}
+	}
+	for _, s := range safetyCanaries {
+		before, ok := ref.Safety[s.id]
+		after, ok2 := current.Safety[s.id]
+		if !ok || !ok2 {
+			continue
+		}
+		if !strings.EqualFold(before.AgeCategory, after.AgeCategory) {
+			drift.Changes = append(drift.Changes, fmt.Sprintf("%s canary: age category %s -> %s", s.id, before.AgeCategory, after.AgeCategory))
+		}
+		for _, score := range []struct {
+			name          string
+			before, after int
+		}{
+			{"profanity", before.ProfanityScore, after.ProfanityScore},
+			{"explicit", before.ExplicitScore, after.ExplicitScore},
+			{"violence", before.ViolenceScore, after.ViolenceScore},
+			{"substance", before.SubstanceScore, after.SubstanceScore},
+			{"self-harm", before.SelfHarmScore, after.SelfHarmScore},
+			{"suicide", before.SuicideScore, after.SuicideScore},
+		} {
+			if delta := score.after - score.before; delta > safetyDriftPoints || delta < -safetyDriftPoints {
+				drift.Changes = append(drift.Changes, fmt.Sprintf("%s canary: %s score %d -> %d", s.id, score.name, score.before, score.after))
+			}
+		}
+	}
+	drift.Material = len(drift.Changes) > 0
+	if drift.Material {
+		drift.Warning = fmt.Sprintf("%s was updated (%s -> %s) and now classifies the fixed canary excerpts differently; genre and content-safety results may shift between runs even where the manuscript did not change.", current.Model, shortDigest(ref.Digest), shortDigest(current.Digest))
+	}
+	return drift
+}
+
+// ollamaModelDigests maps each installed model to the digest of its build,
+// from /api/tags.
+func ollamaModelDigests() (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	endpoint := strings.TrimSuffix(ollamaGenerateEndpoint(), "/api/generate") + "/api/tags"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var out struct {
+		Models []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, m := range out.Models {
+		digests[m.Name] = m.Digest
+	}
+	return digests, nil
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// driftServer is an Ollama with one model whose digest and answers the test
+// can change between runs.
+type driftServer struct {
+	digest   string
+	thriller float64
+	violence int
+	calls    int
+}
+
+func (d *driftServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]string{{"name": "canary:latest", "digest": d.digest}}})
+			return
+		}
+		d.calls++
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		answer := map[string]any{"top_genre": "Thriller", "reasoning": "pace", "genre_scores": map[string]float64{"Thriller": d.thriller, "Mystery": 1 - d.thriller}}
+		if strings.Contains(req.Prompt, "content classifier") {
+			answer = map[string]any{"age_category": "Adult", "violence_score": d.violence}
+		}
+		raw, _ := json.Marshal(answer)
+		_ = json.NewEncoder(w).Encode(map[string]any{"response": string(raw)})
+	}))
+}
+
+func TestCheckModelDriftWarnsAfterUpdateChangesAnswers(t *testing.T) {
+	d := &driftServer{digest: "sha256:aaaaaaaaaaaaaaaa", thriller: 0.8, violence: 70}
+	server := d.start(t)
+	defer server.Close()
+	t.Setenv("OLLAMA_URL", server.URL)
+	root := t.TempDir()
+	var logs []string
+	addLog := func(level, stage, message, detail string) { logs = append(logs, level+" "+message) }
+	checks := []driftCheck{{model: "canary", genre: true}, {model: "canary", safety: true}}
+
+	if drift := checkModelDrift(root, checks, addLog); len(drift) != 0 {
+		t.Fatalf("expected the first run to record a reference only, got %+v", drift)
+	}
+	canaries := len(genreCanaries) + len(safetyCanaries)
+	if d.calls != canaries {
+		t.Fatalf("expected %d canary prompts, got %d", canaries, d.calls)
+	}
+
+	checkModelDrift(root, checks, addLog)
+	if d.calls != canaries {
+		t.Fatalf("expected no prompts while the build is unchanged, got %d", d.calls-canaries)
+	}
+
+	d.digest = "sha256:bbbbbbbbbbbbbbbb"
+	if drift := checkModelDrift(root, checks, addLog); len(drift) != 1 || drift[0].Material {
+		t.Fatalf("expected an update with the same answers to be immaterial, got %+v", drift)
+	}
+
+	d.digest, d.thriller, d.violence = "sha256:cccccccccccccccc", 0.4, 30
+	drift := checkModelDrift(root, checks, addLog)
+	if len(drift) != 1 || !drift[0].Material || drift[0].PreviousDigest != "sha256:bbbbbbbbbbbbbbbb" {
+		t.Fatalf("expected material drift from the previous build, got %+v", drift)
+	}
+	changes := strings.Join(drift[0].Changes, "; ")
+	for _, want := range []string{"top genre Thriller -> Mystery", "violence score 70 -> 30"} {
+		if !strings.Contains(changes, want) {
+			t.Fatalf("expected %q among the changes, got %s", want, changes)
+		}
+	}
+	if !strings.Contains(strings.Join(logs, "\n"), "RISK Model answers drifted after an update") {
+		t.Fatalf("expected a RISK log for the drift, got %v", logs)
+	}
+}
+
+func TestCheckModelDriftSkipsWithoutOllama(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:1")
+	var logs []string
+	drift := checkModelDrift(t.TempDir(), []driftCheck{{model: "canary", genre: true}}, func(level, stage, message, detail string) { logs = append(logs, message) })
+	if drift != nil || len(logs) != 1 || !strings.Contains(logs[0], "skipped") {
+		t.Fatalf("expected the check skipped with one log line, got %+v %v", drift, logs)
+	}
+}
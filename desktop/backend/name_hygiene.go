@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	NameIssueSimilar   = "similar_names"
+	NameIssueHomophone = "near_homophones"
+	NameIssueOneOff    = "one_off"
+
+	// maxOneOffNames caps how many single-mention names are listed; past that
+	// the count matters more than the list.
+	maxOneOffNames = 12
+)
+
+// NameHygieneReport lists character names likely to confuse readers.
+type NameHygieneReport struct {
+	Issues []NameHygieneIssue `json:"issues"`
+	Flags  []string           `json:"flags"`
+}
+
+type NameHygieneIssue struct {
+	Kind       string   `json:"kind"`
+	Names      []string `json:"names"`
+	Detail     string   `json:"detail"`
+	Suggestion string   `json:"suggestion"`
+}
+
+func emptyNameHygieneReport() NameHygieneReport {
+	return NameHygieneReport{Issues: []NameHygieneIssue{}, Flags: []string{}}
+}
+
+// analyzeNameHygiene checks every pair of recurring characters for names that
+// look alike (same first letter, similar length, one or two letters apart) or
+// sound alike, and lists named characters mentioned only once.
+func analyzeNameHygiene(characters []CharacterEntry) NameHygieneReport {
+	report := emptyNameHygieneReport()
+	recurring := make([]CharacterEntry, 0, len(characters))
+	oneOffs := []string{}
+	for _, c := range characters {
+		if c.TotalMentions <= 1 {
+			oneOffs = append(oneOffs, c.Name)
+			continue
+		}
+		recurring = append(recurring, c)
+	}
+
+	similar, homophones := 0, 0
+	for i := range recurring {
+		for j := i + 1; j < len(recurring); j++ {
+			a, b := recurring[i], recurring[j]
+			// Characters are sorted by mentions, so b is the minor character.
+			switch {
+			case looksSimilar(a.Name, b.Name):
+				similar++
+				report.Issues = append(report.Issues, NameHygieneIssue{
+					Kind:       NameIssueSimilar,
+					Names:      []string{a.Name, b.Name},
+					Detail:     fmt.Sprintf("%s and %s share a first letter and nearly the same spelling; readers skim names by shape.", a.Name, b.Name),
+					Suggestion: fmt.Sprintf("Rename %s (%d mentions) to start with a different letter.", b.Name, b.TotalMentions),
+				})
+			case soundsAlike(a.Name, b.Name):
+				homophones++
+				report.Issues = append(report.Issues, NameHygieneIssue{
+					Kind:       NameIssueHomophone,
+					Names:      []string{a.Name, b.Name},
+					Detail:     fmt.Sprintf("%s and %s sound alike when read aloud or in audiobook.", a.Name, b.Name),
+					Suggestion: fmt.Sprintf("Give %s (%d mentions) a name with a different opening sound.", b.Name, b.TotalMentions),
+				})
+			}
+		}
+	}
+
+	sort.Strings(oneOffs)
+	if len(oneOffs) > 0 {
+		listed := oneOffs[:min(len(oneOffs), maxOneOffNames)]
+		detail := fmt.Sprintf("%d named character(s) appear only once: %s", len(oneOffs), strings.Join(listed, ", "))
+		if len(oneOffs) > len(listed) {
+			detail += fmt.Sprintf(" and %d more", len(oneOffs)-len(listed))
+		}
+		report.Issues = append(report.Issues, NameHygieneIssue{
+			Kind:       NameIssueOneOff,
+			Names:      listed,
+			Detail:     detail + ".",
+			Suggestion: "Describe walk-on characters by role (the clerk, the driver) unless the name pays off later; each name asks the reader to remember it.",
+		})
+	}
+
+	if similar > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d pair(s) of look-alike character names", similar))
+	}
+	if homophones > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d pair(s) of sound-alike character names", homophones))
+	}
+	if len(oneOffs) >= 5 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d one-off named characters", len(oneOffs)))
+	}
+	return report
+}
+
+// looksSimilar matches names with the same first letter, lengths within one
+// and at most two edits apart (Marta/Marla, Jon/Jan).
+func looksSimilar(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if a == b || a[0] != b[0] {
+		return false
+	}
+	if d := len(a) - len(b); d > 1 || d < -1 {
+		return false
+	}
+	limit := 2
+	if min(len(a), len(b)) <= 4 {
+		limit = 1
+	}
+	return editDistance(a, b) <= limit
+}
+
+// soundsAlike compares phonetic keys of names of similar length, so Ben and
+// Bonnie do not match on their shared consonants.
+func soundsAlike(a, b string) bool {
+	if d := len(a) - len(b); d > 2 || d < -2 {
+		return false
+	}
+	return phoneticKey(a) == phoneticKey(b)
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// phoneticKey folds spellings that sound the same (Catherine/Kathryn,
+// Philip/Filip, Sean/Shaun) onto one key: common digraphs and soft/hard
+// letters are normalized, vowels after the first letter and repeated
+// consonants are dropped.
+func phoneticKey(name string) string {
+	s := strings.ToLower(name)
+	for _, r := range []struct{ from, to string }{
+		{"ph", "f"}, {"th", "t"}, {"ck", "k"}, {"sh", "s"}, {"ch", "k"}, {"gh", "g"},
+		{"qu", "k"}, {"q", "k"}, {"x", "ks"}, {"z", "s"}, {"wh", "w"},
+	} {
+		s = strings.ReplaceAll(s, r.from, r.to)
+	}
+	// A trailing h is silent (Sarah/Sara).
+	s = strings.TrimSuffix(s, "h")
+	var b strings.Builder
+	var last rune
+	for i, r := range s {
+		switch {
+		case r == 'c' && i+1 < len(s) && strings.ContainsRune("eiy", rune(s[i+1])):
+			r = 's'
+		case r == 'c':
+			r = 'k'
+		case r == 'y':
+			r = 'i'
+		}
+		if strings.ContainsRune("aeiou", r) {
+			if i == 0 {
+				b.WriteRune('a')
+			}
+			last = r
+			continue
+		}
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeNameHygieneFlagsConfusableNames(t *testing.T) {
+	report := analyzeNameHygiene([]CharacterEntry{
+		{Name: "Marta", TotalMentions: 90},
+		{Name: "Catherine", TotalMentions: 60},
+		{Name: "Ben", TotalMentions: 40},
+		{Name: "Bonnie", TotalMentions: 30},
+		{Name: "Kathryn", TotalMentions: 20},
+		{Name: "Marla", TotalMentions: 12},
+		{Name: "Oswin", TotalMentions: 1},
+	})
+	kinds := map[string][]string{}
+	for _, issue := range report.Issues {
+		kinds[issue.Kind] = append(kinds[issue.Kind], strings.Join(issue.Names, "/"))
+	}
+	if got := kinds[NameIssueSimilar]; len(got) != 1 || got[0] != "Marta/Marla" {
+		t.Fatalf("expected Marta/Marla as look-alikes, got %v", got)
+	}
+	if got := kinds[NameIssueHomophone]; len(got) != 1 || got[0] != "Catherine/Kathryn" {
+		t.Fatalf("expected Catherine/Kathryn as sound-alikes, got %v", got)
+	}
+	if got := kinds[NameIssueOneOff]; len(got) != 1 || got[0] != "Oswin" {
+		t.Fatalf("expected Oswin listed as a one-off, got %v", got)
+	}
+	for _, issue := range report.Issues {
+		if issue.Kind == NameIssueSimilar && !strings.Contains(issue.Suggestion, "Rename Marla") {
+			t.Fatalf("expected the minor character to be renamed, got %q", issue.Suggestion)
+		}
+	}
+	if len(report.Flags) != 2 {
+		t.Fatalf("expected look-alike and sound-alike flags only, got %v", report.Flags)
+	}
+}
+
+func TestPhoneticKeyFoldsSpellings(t *testing.T) {
+	for _, pair := range [][2]string{{"Philip", "Filip"}, {"Sarah", "Sara"}, {"Catherine", "Kathryn"}} {
+		if phoneticKey(pair[0]) != phoneticKey(pair[1]) {
+			t.Fatalf("expected %s and %s to share a key: %q vs %q", pair[0], pair[1], phoneticKey(pair[0]), phoneticKey(pair[1]))
+		}
+	}
+	if phoneticKey("Owen") == phoneticKey("Ian") {
+		t.Fatal("Owen and Ian should not share a key")
+	}
+}
@@ -0,0 +1,440 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"book_dashboard/internal/workspace"
+)
+
+// Manuscript types. Fiction runs every stage; nonfiction and memoir skip the
+// fiction-only ones (plot beats, character contradictions) and add the
+// nonfiction checks.
+const (
+	ManuscriptFiction    = "fiction"
+	ManuscriptNonfiction = "nonfiction"
+	ManuscriptMemoir     = "memoir"
+)
+
+// Kinds of pre-submission placeholder.
+const (
+	PlaceholderCitation       = "citation"
+	PlaceholderMarker         = "marker"
+	PlaceholderCrossReference = "cross_reference"
+)
+
+const (
+	// minClaimWords is the fewest content words a sentence needs before a
+	// repeat of it counts as a restated claim.
+	minClaimWords = 6
+	// maxNonfictionItems caps each list in the nonfiction report.
+	maxNonfictionItems = 25
+)
+
+var (
+	citationPlaceholderPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\[\s*(citation needed|citation|cite|add citation|ref|reference|source|sources needed|source needed|footnote|fn|tk|\?+)\s*\]`),
+		regexp.MustCompile(`(?i)\(\s*(citation|cite|source|ref)( needed)?\s*\)`),
+		regexp.MustCompile(`(?i)\(\s*(author|name),?\s*(year|date)\s*\)`),
+		regexp.MustCompile(`\(\s*[A-Z][\p{L}-]+( et al\.)?,?\s+(XXXX|19XX|20XX|n\.d\.\?|\?{2,})\s*\)`),
+		regexp.MustCompile(`\bpp?\.\s*(XX+|\?{2,})`),
+	}
+	crossReferencePattern = regexp.MustCompile(`(?i)\bsee\s+(?:also\s+)?(chapter|section|part|figure|table|page)\s+([\p{L}\d.?#]+)`)
+	sectionNumberPattern  = regexp.MustCompile(`^(\d+(?:\.\d+)+)\.?\s+\S`)
+	markdownHeadingPrefix = regexp.MustCompile(`^(#{1,6})\s+\S`)
+	claimWordPattern      = regexp.MustCompile(`[\p{L}']+`)
+	chapterWordNumbers    = map[string]int{"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19, "twenty": 20}
+)
+
+// claimStopwords are frequent words that carry no claim of their own.
+var claimStopwords = wordSet("this", "that", "these", "those", "with", "from", "into", "have", "has", "been", "were", "which", "their", "there", "they", "them", "what", "when", "where", "while", "would", "could", "should", "about", "also", "than", "then", "more", "most", "some", "such", "very", "just", "only", "even", "much", "many", "over", "because")
+
+type manuscriptTypeKey struct{}
+
+// NormalizeManuscriptType maps a type name onto a known type; anything else
+// is fiction.
+func NormalizeManuscriptType(name string) string {
+	switch key := strings.ToLower(strings.TrimSpace(name)); key {
+	case ManuscriptNonfiction, ManuscriptMemoir:
+		return key
+	case "non-fiction":
+		return ManuscriptNonfiction
+	default:
+		return ManuscriptFiction
+	}
+}
+
+// IsManuscriptType reports whether name is one of the manuscript types.
+func IsManuscriptType(name string) bool {
+	key := strings.ToLower(strings.TrimSpace(name))
+	return key == ManuscriptFiction || key == ManuscriptNonfiction || key == ManuscriptMemoir || key == "non-fiction"
+}
+
+func isFiction(manuscriptType string) bool {
+	return manuscriptType == ManuscriptFiction
+}
+
+// WithManuscriptType selects the manuscript type for the run using ctx,
+// overriding the project setting. An empty type leaves the setting in force.
+func WithManuscriptType(ctx context.Context, manuscriptType string) context.Context {
+	return context.WithValue(ctx, manuscriptTypeKey{}, manuscriptType)
+}
+
+// resolveManuscriptType picks the run override, then the project setting,
+// then fiction, and reports which one won.
+func resolveManuscriptType(ctx context.Context, settings workspace.ProjectSettings) (string, string) {
+	if name, _ := ctx.Value(manuscriptTypeKey{}).(string); IsManuscriptType(name) {
+		return NormalizeManuscriptType(name), "run"
+	}
+	if IsManuscriptType(settings.ManuscriptType) {
+		return NormalizeManuscriptType(settings.ManuscriptType), "project"
+	}
+	return ManuscriptFiction, "default"
+}
+
+// SetManuscriptType stores the project's manuscript type; it applies from the
+// next analysis run.
+func SetManuscriptType(projectLocation, manuscriptType string) (string, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return "", fmt.Errorf("no project loaded")
+	}
+	if !IsManuscriptType(manuscriptType) {
+		return "", fmt.Errorf("unknown manuscript type %q", manuscriptType)
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return "", err
+	}
+	settings.ManuscriptType = NormalizeManuscriptType(manuscriptType)
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return "", err
+	}
+	return settings.ManuscriptType, nil
+}
+
+// analyzeNonfiction runs the checks that matter for nonfiction and memoir:
+// claims restated across the book, a heading outline that skips levels or
+// numbers, and placeholders left in the text.
+func analyzeNonfiction(manuscriptType, text string, chapters []chapter) NonfictionReport {
+	report := NonfictionReport{
+		ManuscriptType: manuscriptType,
+		RepeatedClaims: findRepeatedClaims(chapters),
+		HeadingIssues:  checkHeadingHierarchy(text),
+		Placeholders:   findPlaceholders(text, chapters),
+		Flags:          []string{},
+	}
+	if n := len(report.RepeatedClaims); n > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d claims are restated in more than one place.", n))
+	}
+	if n := len(report.HeadingIssues); n > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d headings break the outline.", n))
+	}
+	if n := len(report.Placeholders); n > 0 {
+		kinds := map[string]int{}
+		for _, p := range report.Placeholders {
+			kinds[p.Kind]++
+		}
+		parts := []string{}
+		for _, k := range []struct{ kind, label string }{
+			{PlaceholderCitation, "citation placeholders"},
+			{PlaceholderMarker, "TODO/TK markers"},
+			{PlaceholderCrossReference, "unresolved cross-references"},
+		} {
+			if kinds[k.kind] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", kinds[k.kind], k.label))
+			}
+		}
+		report.Flags = append(report.Flags, fmt.Sprintf("%d pre-submission blockers remain: %s.", n, strings.Join(parts, ", ")))
+	}
+	return report
+}
+
+// findRepeatedClaims groups sentences that make the same statement: the
+// same set of content words, whatever the word order or filler words.
+func findRepeatedClaims(chapters []chapter) []RepeatedClaim {
+	type occurrence struct {
+		sentence string
+		chapter  int
+	}
+	groups := map[string][]occurrence{}
+	order := []string{}
+	for _, ch := range chapters {
+		for _, sentence := range splitSentences(ch.text) {
+			key := claimKey(sentence)
+			if key == "" {
+				continue
+			}
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], occurrence{sentence: sentence, chapter: ch.index})
+		}
+	}
+	out := []RepeatedClaim{}
+	for _, key := range order {
+		occ := groups[key]
+		if len(occ) < 2 {
+			continue
+		}
+		claim := RepeatedClaim{Text: firstWords(occ[0].sentence, 30), Count: len(occ), Chapters: []int{}}
+		seen := map[int]bool{}
+		for _, o := range occ {
+			if !seen[o.chapter] {
+				seen[o.chapter] = true
+				claim.Chapters = append(claim.Chapters, o.chapter)
+			}
+		}
+		out = append(out, claim)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	if len(out) > maxNonfictionItems {
+		out = out[:maxNonfictionItems]
+	}
+	return out
+}
+
+func claimKey(sentence string) string {
+	words := map[string]bool{}
+	for _, w := range claimWordPattern.FindAllString(strings.ToLower(sentence), -1) {
+		w = strings.Trim(w, "'")
+		if len(w) < 4 || claimStopwords[w] {
+			continue
+		}
+		words[strings.TrimSuffix(w, "s")] = true
+	}
+	if len(words) < minClaimWords {
+		return ""
+	}
+	keys := make([]string, 0, len(words))
+	for w := range words {
+		keys = append(keys, w)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, " ")
+}
+
+// checkHeadingHierarchy walks the heading outline (chapter headings, dotted
+// section numbers such as 2.1.3, and Markdown # headings) and reports
+// chapters numbered out of sequence, sections that skip a level and
+// sections whose number does not follow the one before.
+func checkHeadingHierarchy(text string) []HeadingIssue {
+	out := []HeadingIssue{}
+	add := func(heading, problem string) {
+		if len(out) < maxNonfictionItems {
+			out = append(out, HeadingIssue{Heading: heading, Problem: problem})
+		}
+	}
+	lastChapter := 0
+	var lastSection []int
+	lastMarkdown := 0
+	for _, line := range nonEmptyLines(text) {
+		if m := chapterHeaderPattern.FindStringSubmatch(line); m != nil {
+			if n, ok := chapterNumber(m[2]); ok {
+				if lastChapter > 0 && n != lastChapter+1 {
+					add(line, fmt.Sprintf("follows chapter %d", lastChapter))
+				}
+				lastChapter = n
+			}
+			lastSection = nil
+			continue
+		}
+		if m := markdownHeadingPrefix.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			if level > lastMarkdown+1 {
+				add(line, fmt.Sprintf("level %d heading under a level %d heading", level, lastMarkdown))
+			}
+			lastMarkdown = level
+			continue
+		}
+		m := sectionNumberPattern.FindStringSubmatch(line)
+		if m == nil || len(strings.Fields(line)) > 12 || strings.HasSuffix(line, ".") {
+			continue
+		}
+		number := parseSectionNumber(m[1])
+		switch {
+		case lastChapter > 0 && number[0] != lastChapter:
+			add(line, fmt.Sprintf("numbered for chapter %d but sits in chapter %d", number[0], lastChapter))
+		case len(number) > max(len(lastSection), 1)+1:
+			// A chapter heading is level 1, so its first section is N.1.
+			add(line, fmt.Sprintf("skips from level %d to level %d", max(len(lastSection), 1), len(number)))
+		case len(lastSection) == 0 && number[len(number)-1] != 1:
+			add(line, "is not the first section of its chapter")
+		case len(lastSection) > 0 && !sectionFollows(lastSection, number):
+			add(line, fmt.Sprintf("does not follow %s", formatSectionNumber(lastSection)))
+		}
+		lastSection = number
+	}
+	return out
+}
+
+func chapterNumber(token string) (int, bool) {
+	token = strings.ToLower(token)
+	if n, err := strconv.Atoi(token); err == nil {
+		return n, true
+	}
+	if n, ok := chapterWordNumbers[token]; ok {
+		return n, true
+	}
+	values := map[rune]int{'i': 1, 'v': 5, 'x': 10, 'l': 50, 'c': 100, 'd': 500, 'm': 1000}
+	total, prev := 0, 0
+	for i := len(token) - 1; i >= 0; i-- {
+		v, ok := values[rune(token[i])]
+		if !ok {
+			return 0, false
+		}
+		if v < prev {
+			total -= v
+		} else {
+			total += v
+			prev = v
+		}
+	}
+	return total, total > 0
+}
+
+func parseSectionNumber(s string) []int {
+	parts := strings.Split(s, ".")
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, _ := strconv.Atoi(p)
+		out = append(out, n)
+	}
+	return out
+}
+
+// sectionFollows reports whether next is a valid successor of prev: the
+// first child (prev + ".1"), the next sibling, the next sibling of an
+// ancestor, or the first section of the next chapter when the chapters have
+// no headings of their own.
+func sectionFollows(prev, next []int) bool {
+	if next[0] == prev[0]+1 {
+		for _, n := range next[1:] {
+			if n != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if len(next) == len(prev)+1 {
+		return equalInts(next[:len(prev)], prev) && next[len(next)-1] == 1
+	}
+	if len(next) > len(prev) {
+		return false
+	}
+	k := len(next) - 1
+	return equalInts(next[:k], prev[:k]) && next[k] == prev[k]+1
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatSectionNumber(n []int) string {
+	parts := make([]string, len(n))
+	for i, v := range n {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ".")
+}
+
+// findPlaceholders lists what must be resolved before submission: citation
+// placeholders, TODO and TK markers, and cross-references to a chapter or
+// section that does not exist or was never filled in. Each is placed by
+// chapter and paragraph, counted as paragraphOffsets counts them.
+func findPlaceholders(text string, chapters []chapter) []Placeholder {
+	sections := map[string]bool{}
+	for _, line := range nonEmptyLines(text) {
+		if m := sectionNumberPattern.FindStringSubmatch(line); m != nil {
+			sections[m[1]] = true
+		}
+	}
+	type hit struct {
+		start, end int
+		kind       string
+	}
+	out := []Placeholder{}
+	for _, ch := range chapters {
+		lines := nonEmptyLines(ch.text)
+		if len(lines) > 1 && chapterHeaderPattern.MatchString(lines[0]) {
+			lines = lines[1:]
+		}
+		for i, line := range lines {
+			hits := []hit{}
+			for _, p := range citationPlaceholderPatterns {
+				for _, m := range p.FindAllStringIndex(line, -1) {
+					hits = append(hits, hit{m[0], m[1], PlaceholderCitation})
+				}
+			}
+			for _, m := range draftMarkerPattern.FindAllStringIndex(line, -1) {
+				hits = append(hits, hit{m[0], m[1], PlaceholderMarker})
+			}
+			for _, m := range crossReferencePattern.FindAllStringSubmatchIndex(line, -1) {
+				target := strings.TrimRight(line[m[4]:m[5]], ".")
+				if !crossReferenceResolved(strings.ToLower(line[m[2]:m[3]]), target, len(chapters), sections) {
+					hits = append(hits, hit{m[0], m[4] + len(target), PlaceholderCrossReference})
+				}
+			}
+			sort.SliceStable(hits, func(a, b int) bool { return hits[a].start < hits[b].start })
+			lastEnd := -1
+			for _, h := range hits {
+				if h.start < lastEnd {
+					continue
+				}
+				lastEnd = h.end
+				out = append(out, Placeholder{
+					Kind:      h.kind,
+					Chapter:   ch.index,
+					Paragraph: i + 1,
+					Location:  fmt.Sprintf("Ch %d, paragraph %d", ch.index, i+1),
+					Marker:    line[h.start:h.end],
+					Context:   placeholderContext(line, h.start, h.end),
+				})
+			}
+		}
+	}
+	return out
+}
+
+// crossReferenceResolved reports whether a "see Chapter 4"-style reference
+// points somewhere real. Chapters must be in range and numbered sections
+// must exist when the manuscript numbers its sections; anything else is only
+// unresolved when its target is a placeholder such as X, ?? or ##.
+func crossReferenceResolved(kind, target string, chapters int, sections map[string]bool) bool {
+	switch kind {
+	case "chapter":
+		n, ok := chapterNumber(target)
+		return ok && n >= 1 && n <= chapters
+	case "section":
+		if len(sections) > 0 && strings.Contains(target, ".") {
+			return sections[target]
+		}
+	}
+	return strings.Trim(strings.ToUpper(target), "X?#0") != "" && !strings.EqualFold(target, "TK")
+}
+
+// placeholderContext is the paragraph around a match, trimmed to a readable
+// length on rune boundaries.
+func placeholderContext(line string, start, end int) string {
+	from := max(0, start-120)
+	to := min(len(line), end+60)
+	for from < start && !utf8.RuneStart(line[from]) {
+		from++
+	}
+	for to < len(line) && !utf8.RuneStart(line[to]) {
+		to--
+	}
+	return strings.Join(strings.Fields(line[from:to]), " ")
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/workspace"
+)
+
+func TestFindRepeatedClaimsIgnoresWordOrder(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "Regular sleep improves memory consolidation among adult learners studying languages. The weather was fine."},
+		{index: 2, text: "Among adult learners studying languages, regular sleep improves memory consolidation. Something else entirely."},
+		{index: 3, text: "Short claim here. Short claim here."},
+	}
+	claims := findRepeatedClaims(chapters)
+	if len(claims) != 1 {
+		t.Fatalf("want 1 repeated claim, got %+v", claims)
+	}
+	if claims[0].Count != 2 || len(claims[0].Chapters) != 2 || claims[0].Chapters[1] != 2 {
+		t.Fatalf("unexpected claim: %+v", claims[0])
+	}
+}
+
+func TestCheckHeadingHierarchy(t *testing.T) {
+	text := strings.Join([]string{
+		"Chapter 1",
+		"1.1 Background",
+		"1.2 Method",
+		"1.2.1 Sampling",
+		"1.3 Results",
+		"Chapter 2",
+		"2.1.1 Too Deep",
+		"Chapter 4",
+		"4.2 Late Start",
+	}, "\n")
+	issues := checkHeadingHierarchy(text)
+	want := map[string]string{
+		"2.1.1 Too Deep": "skips from level 1 to level 3",
+		"Chapter 4":      "follows chapter 2",
+		"4.2 Late Start": "is not the first section of its chapter",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("want %d issues, got %+v", len(want), issues)
+	}
+	for _, issue := range issues {
+		if want[issue.Heading] != issue.Problem {
+			t.Fatalf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestFindPlaceholders(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "Rates doubled after 1990 [citation needed].\nAs Smith argues (Smith, 20XX), the trend held on p. XX of the survey. TODO check the figures."},
+		{index: 2, text: "1.1 Sources\nSee Chapter 2 for the method and see Chapter 7 for the results, and see section 1.4 and see Figure X.\nSee also Table 3 and section 1.1 above."},
+	}
+	found := findPlaceholders(chapters[0].text+"\n"+chapters[1].text, chapters)
+	want := []struct{ kind, marker, location string }{
+		{PlaceholderCitation, "[citation needed]", "Ch 1, paragraph 1"},
+		{PlaceholderCitation, "(Smith, 20XX)", "Ch 1, paragraph 2"},
+		{PlaceholderCitation, "p. XX", "Ch 1, paragraph 2"},
+		{PlaceholderMarker, "TODO", "Ch 1, paragraph 2"},
+		{PlaceholderCrossReference, "see Chapter 7", "Ch 2, paragraph 2"},
+		{PlaceholderCrossReference, "see section 1.4", "Ch 2, paragraph 2"},
+		{PlaceholderCrossReference, "see Figure X", "Ch 2, paragraph 2"},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("want %d placeholders, got %+v", len(want), found)
+	}
+	for i, w := range want {
+		if found[i].Kind != w.kind || found[i].Marker != w.marker || found[i].Location != w.location {
+			t.Fatalf("placeholder %d = %+v, want %+v", i, found[i], w)
+		}
+	}
+	if !strings.HasPrefix(found[0].Context, "Rates doubled") {
+		t.Fatalf("unexpected context %q", found[0].Context)
+	}
+}
+
+func TestResolveManuscriptTypePrefersRunOverride(t *testing.T) {
+	settings := workspace.ProjectSettings{ManuscriptType: "memoir"}
+	if got, source := resolveManuscriptType(context.Background(), settings); got != ManuscriptMemoir || source != "project" {
+		t.Fatalf("got %s from %s, want memoir from project", got, source)
+	}
+	ctx := WithManuscriptType(context.Background(), "Non-Fiction")
+	if got, source := resolveManuscriptType(ctx, settings); got != ManuscriptNonfiction || source != "run" {
+		t.Fatalf("got %s from %s, want nonfiction from run", got, source)
+	}
+	if got, _ := resolveManuscriptType(WithManuscriptType(context.Background(), ""), workspace.ProjectSettings{}); got != ManuscriptFiction {
+		t.Fatalf("got %s, want fiction by default", got)
+	}
+}
@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"book_dashboard/internal/novelty"
+	"book_dashboard/internal/workspace"
+)
+
+// noveltyMinChapterTokens keeps very short chapters (epigraphs, interludes)
+// out of the per-chapter profile, where a few rare words swing the score.
+const noveltyMinChapterTokens = 200
+
+// NoveltyReport scores how surprising the prose is to a reference n-gram model
+// of published fiction. Without an installed model it is unavailable.
+type NoveltyReport struct {
+	Available         bool             `json:"available"`
+	Model             string           `json:"model"`
+	ModelPath         string           `json:"modelPath"`
+	BaselineSurprisal float64          `json:"baselineSurprisal"`
+	Surprisal         float64          `json:"surprisal"`
+	Novelty           int              `json:"novelty"`
+	UnseenTrigramRate float64          `json:"unseenTrigramRate"`
+	Chapters          []ChapterNovelty `json:"chapters"`
+	Notes             []string         `json:"notes"`
+}
+
+type ChapterNovelty struct {
+	Chapter           int     `json:"chapter"`
+	Title             string  `json:"title"`
+	Tokens            int     `json:"tokens"`
+	Surprisal         float64 `json:"surprisal"`
+	ZScore            float64 `json:"zScore"`
+	Novelty           int     `json:"novelty"`
+	UnseenTrigramRate float64 `json:"unseenTrigramRate"`
+}
+
+// NoveltyModelInfo describes an installed reference model.
+type NoveltyModelInfo struct {
+	Name              string   `json:"name"`
+	Path              string   `json:"path"`
+	Tokens            int64    `json:"tokens"`
+	BaselineSurprisal float64  `json:"baselineSurprisal"`
+	Notes             []string `json:"notes"`
+}
+
+// noveltyModelPath returns MHD_NOVELTY_MODEL when set, otherwise the workspace
+// cache location.
+func noveltyModelPath(workspaceRoot string) string {
+	if path := strings.TrimSpace(os.Getenv("MHD_NOVELTY_MODEL")); path != "" {
+		return path
+	}
+	if strings.TrimSpace(workspaceRoot) == "" {
+		return ""
+	}
+	return workspace.NoveltyModelPath(workspaceRoot)
+}
+
+// loadNoveltyModel returns a nil model without error when none is installed.
+func loadNoveltyModel(workspaceRoot string) (*novelty.Model, string, error) {
+	path := noveltyModelPath(workspaceRoot)
+	if path == "" {
+		return nil, "", nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil
+	}
+	model, err := novelty.Load(path)
+	return model, path, err
+}
+
+func emptyNoveltyReport() NoveltyReport {
+	return NoveltyReport{Chapters: []ChapterNovelty{}, Notes: []string{}}
+}
+
+func analyzeNovelty(model *novelty.Model, modelPath string, chapters []chapter, text string) NoveltyReport {
+	report := emptyNoveltyReport()
+	report.ModelPath = modelPath
+	if model == nil {
+		report.Notes = append(report.Notes, "No reference n-gram model installed; download one or build it with mhd-ngram.")
+		return report
+	}
+	whole := model.Score(text)
+	report.Available = true
+	report.Model = model.Name
+	report.BaselineSurprisal = model.BaselineSurprisal
+	report.Surprisal = whole.Surprisal
+	report.Novelty = whole.Novelty
+	report.UnseenTrigramRate = whole.UnseenTrigramRate
+	for _, ch := range chapters {
+		p := model.Score(ch.text)
+		if p.Tokens < noveltyMinChapterTokens {
+			continue
+		}
+		report.Chapters = append(report.Chapters, ChapterNovelty{
+			Chapter:           ch.index,
+			Title:             ch.title,
+			Tokens:            p.Tokens,
+			Surprisal:         p.Surprisal,
+			ZScore:            p.ZScore,
+			Novelty:           p.Novelty,
+			UnseenTrigramRate: p.UnseenTrigramRate,
+		})
+	}
+	return report
+}
+
+// DownloadNoveltyModel installs the reference model from url, or from
+// MHD_NOVELTY_MODEL_URL when url is empty.
+func DownloadNoveltyModel(url string) (NoveltyModelInfo, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		url = strings.TrimSpace(os.Getenv("MHD_NOVELTY_MODEL_URL"))
+	}
+	if url == "" {
+		return NoveltyModelInfo{}, fmt.Errorf("no novelty model URL given and MHD_NOVELTY_MODEL_URL is not set")
+	}
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return NoveltyModelInfo{}, err
+	}
+	path := noveltyModelPath(root)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	model, err := novelty.Download(ctx, url, path)
+	if err != nil {
+		return NoveltyModelInfo{}, err
+	}
+	return NoveltyModelInfo{Name: model.Name, Path: path, Tokens: model.Tokens, BaselineSurprisal: model.BaselineSurprisal, Notes: []string{}}, nil
+}
@@ -0,0 +1,60 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/novelty"
+)
+
+func TestAnalyzeNoveltyWithInstalledModel(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("MHD_NOVELTY_MODEL", "")
+	model, path, err := loadNoveltyModel(root)
+	if err != nil || model != nil {
+		t.Fatalf("expected no model installed, got %v %v", model, err)
+	}
+	if report := analyzeNovelty(model, path, nil, "text"); report.Available || len(report.Notes) == 0 {
+		t.Fatalf("expected unavailable report with a note, got %+v", report)
+	}
+
+	reference := strings.Repeat("she walked to the door and looked back at the quiet house. ", 1200)
+	built, err := novelty.Build("test-reference", []string{reference}, 1)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	modelPath := filepath.Join(root, "reference.ngram.gz")
+	f, err := os.Create(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := built.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	t.Setenv("MHD_NOVELTY_MODEL", modelPath)
+
+	model, path, err = loadNoveltyModel(root)
+	if err != nil || model == nil || path != modelPath {
+		t.Fatalf("load installed model: %v %q", err, path)
+	}
+	familiar := strings.Repeat("she walked to the door and looked back at the quiet house. ", 30)
+	strange := strings.Repeat("copper herons debated the tidal arithmetic of forgotten lanterns. ", 30)
+	chapters := []chapter{
+		{index: 1, title: "Familiar", text: familiar},
+		{index: 2, title: "Strange", text: strange},
+		{index: 3, title: "Epigraph", text: "A short epigraph."},
+	}
+	report := analyzeNovelty(model, path, chapters, familiar+strange)
+	if !report.Available || report.Model != "test-reference" {
+		t.Fatalf("expected available report, got %+v", report)
+	}
+	if len(report.Chapters) != 2 {
+		t.Fatalf("expected short chapters skipped, got %+v", report.Chapters)
+	}
+	if report.Chapters[0].Novelty >= report.Chapters[1].Novelty {
+		t.Fatalf("expected the unfamiliar chapter to be more novel: %+v", report.Chapters)
+	}
+}
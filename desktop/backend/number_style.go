@@ -0,0 +1,333 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"book_dashboard/internal/workspace"
+)
+
+// Style guides for numbers, units and dates. Chicago and Oxford spell out
+// whole numbers to one hundred, AP only to nine; Oxford writes dates day
+// first.
+const (
+	NumberStyleChicago = "chicago"
+	NumberStyleAP      = "ap"
+	NumberStyleOxford  = "oxford"
+)
+
+// Kinds of number style issue.
+const (
+	NumberIssueNumeral       = "numeral"
+	NumberIssueSpelled       = "spelled"
+	NumberIssueSentenceStart = "sentence_start"
+	NumberIssueUnitSystem    = "unit_system"
+	NumberIssueDateFormat    = "date_format"
+)
+
+// Date formats.
+const (
+	DateMonthDayYear = "month_day_year"
+	DateDayMonthYear = "day_month_year"
+	DateNumeric      = "numeric"
+	DateISO          = "iso"
+)
+
+const maxNumberStyleIssues = 60
+
+type numberStyleGuide struct {
+	spellOutMax int
+	dateFormat  string
+	dateExample string
+}
+
+var numberStyleGuides = map[string]numberStyleGuide{
+	NumberStyleChicago: {spellOutMax: 100, dateFormat: DateMonthDayYear, dateExample: "March 5, 2024"},
+	NumberStyleAP:      {spellOutMax: 9, dateFormat: DateMonthDayYear, dateExample: "March 5, 2024"},
+	NumberStyleOxford:  {spellOutMax: 100, dateFormat: DateDayMonthYear, dateExample: "5 March 2024"},
+}
+
+const monthAlternation = `(?:January|February|March|April|May|June|July|August|September|October|November|December)`
+
+var (
+	numeralPattern    = regexp.MustCompile(`\b\d+\b`)
+	spelledTens       = `(?:twenty|thirty|forty|fifty|sixty|seventy|eighty|ninety)`
+	spelledPattern    = regexp.MustCompile(`(?i)\b(?:` + spelledTens + `(?:-(?:one|two|three|four|five|six|seven|eight|nine))?|ten|eleven|twelve|thirteen|fourteen|fifteen|sixteen|seventeen|eighteen|nineteen)\b`)
+	unitQuantity      = `(?:\d[\d,.]*|a|one|two|three|four|five|six|seven|eight|nine|ten|twenty|thirty|forty|fifty|hundred|thousand)[\s-]*`
+	metricUnitPattern = regexp.MustCompile(`(?i)\b` + unitQuantity + `(?:km|kilomet(?:er|re)s?|met(?:er|re)s?|cm|centimet(?:er|re)s?|mm|millimet(?:er|re)s?|kg|kilos?|kilograms?|grams?|lit(?:er|re)s?|millilit(?:er|re)s?|ml|degrees celsius|°C|celsius|hectares?)\b`)
+	imperialPattern   = regexp.MustCompile(`(?i)\b` + unitQuantity + `(?:miles?|mph|feet|ft|inch(?:es)?|yards?|lbs?|ounces?|oz|gallons?|pints?|degrees fahrenheit|°F|fahrenheit|acres?)\b`)
+	datePatterns      = map[string]*regexp.Regexp{
+		DateMonthDayYear: regexp.MustCompile(`\b` + monthAlternation + `\s+\d{1,2}(?:st|nd|rd|th)?,?\s+\d{4}\b`),
+		DateDayMonthYear: regexp.MustCompile(`\b\d{1,2}(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthAlternation + `,?\s+\d{4}\b`),
+		DateNumeric:      regexp.MustCompile(`\b\d{1,2}[/.]\d{1,2}[/.]\d{2,4}\b`),
+		DateISO:          regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`),
+	}
+	// numeralLabels precede numbers that are identifiers, not quantities.
+	numeralLabels = regexp.MustCompile(`(?i)(?:chapter|page|p\.|pp\.|room|no\.|number|level|floor|route|flight|platform|version|apartment|apt\.|suite|gate|bus|track|line|verse|act|scene|part|section|figure|table|volume|vol\.|#|\$|£|€)\s*$`)
+	monthBefore   = regexp.MustCompile(monthAlternation + `\s+$`)
+	monthAfter    = regexp.MustCompile(`^(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthAlternation)
+	quantityAfter = regexp.MustCompile(`(?i)^\s*(?:%|percent|per cent|a\.m\.|p\.m\.|am\b|pm\b|o'clock|` + `km|kg|cm|mm|ml|mph|ft|lbs?|oz|°)`)
+)
+
+// NumberStyleReport checks numbers, units and dates against a style guide.
+type NumberStyleReport struct {
+	StyleGuide string             `json:"styleGuide"`
+	Issues     []NumberStyleIssue `json:"issues"`
+	// Counts has the number of issues of each kind, including those past
+	// the cap on Issues.
+	Counts map[string]int `json:"counts"`
+	Flags  []string       `json:"flags"`
+}
+
+type NumberStyleIssue struct {
+	Kind       string `json:"kind"`
+	Text       string `json:"text"`
+	Suggestion string `json:"suggestion"`
+	Chapter    int    `json:"chapter"`
+	Paragraph  int    `json:"paragraph"`
+	Location   string `json:"location"`
+	Context    string `json:"context"`
+}
+
+func emptyNumberStyleReport(style string) NumberStyleReport {
+	return NumberStyleReport{StyleGuide: style, Issues: []NumberStyleIssue{}, Counts: map[string]int{}, Flags: []string{}}
+}
+
+// NormalizeNumberStyle maps a style guide name onto a known guide; anything
+// else is Chicago.
+func NormalizeNumberStyle(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := numberStyleGuides[key]; ok {
+		return key
+	}
+	return NumberStyleChicago
+}
+
+// IsNumberStyle reports whether name is one of the style guides.
+func IsNumberStyle(name string) bool {
+	_, ok := numberStyleGuides[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// SetNumberStyle stores the project's style guide for numbers, units and
+// dates; it applies from the next analysis run.
+func SetNumberStyle(projectLocation, style string) (string, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return "", fmt.Errorf("no project loaded")
+	}
+	if !IsNumberStyle(style) {
+		return "", fmt.Errorf("unknown number style guide %q", style)
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return "", err
+	}
+	settings.NumberStyle = NormalizeNumberStyle(style)
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return "", err
+	}
+	return settings.NumberStyle, nil
+}
+
+// numberHit is one finding within a paragraph, in byte offsets of the line.
+type numberHit struct {
+	start, end       int
+	kind, suggestion string
+}
+
+// analyzeNumberStyle finds numerals the guide would spell out (and, under
+// AP, spelled-out numbers it would write as numerals), sentences opening
+// with a numeral, measurements in the less used of metric and imperial, and
+// dates not written in the guide's format.
+func analyzeNumberStyle(chapters []chapter, style string) NumberStyleReport {
+	style = NormalizeNumberStyle(style)
+	guide := numberStyleGuides[style]
+	report := emptyNumberStyleReport(style)
+
+	type unitHit struct {
+		issue  NumberStyleIssue
+		metric bool
+	}
+	units := []unitHit{}
+	metricCount, imperialCount := 0, 0
+	add := func(issue NumberStyleIssue) {
+		report.Counts[issue.Kind]++
+		if len(report.Issues) < maxNumberStyleIssues {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	for _, ch := range chapters {
+		lines := strings.Split(ch.text, "\n")
+		paragraph := 0
+		for i, raw := range lines {
+			line := strings.TrimSpace(raw)
+			if line == "" || (i == 0 && len(lines) > 1 && chapterHeaderPattern.MatchString(line)) {
+				continue
+			}
+			paragraph++
+			issue := func(h numberHit) NumberStyleIssue {
+				return NumberStyleIssue{
+					Kind:       h.kind,
+					Text:       line[h.start:h.end],
+					Suggestion: h.suggestion,
+					Chapter:    ch.index,
+					Paragraph:  paragraph,
+					Location:   fmt.Sprintf("Ch %d, paragraph %d", ch.index, paragraph),
+					Context:    placeholderContext(line, h.start, h.end),
+				}
+			}
+			hits := numberStyleHits(line, guide, style)
+			for _, h := range hits {
+				add(issue(h))
+			}
+			for _, m := range metricUnitPattern.FindAllStringIndex(line, -1) {
+				metricCount++
+				units = append(units, unitHit{issue(numberHit{start: m[0], end: m[1], kind: NumberIssueUnitSystem}), true})
+			}
+			for _, m := range imperialPattern.FindAllStringIndex(line, -1) {
+				imperialCount++
+				units = append(units, unitHit{issue(numberHit{start: m[0], end: m[1], kind: NumberIssueUnitSystem}), false})
+			}
+		}
+	}
+
+	if metricCount > 0 && imperialCount > 0 {
+		// The less used system is the odd one out; on a tie, whichever
+		// appears first sets the convention.
+		metricPreferred := metricCount > imperialCount || (metricCount == imperialCount && units[0].metric)
+		preferred, other := "metric", "imperial"
+		if !metricPreferred {
+			preferred, other = other, preferred
+		}
+		for _, u := range units {
+			if u.metric != metricPreferred {
+				u.issue.Suggestion = fmt.Sprintf("The manuscript mostly uses %s units; this measurement is %s.", preferred, other)
+				add(u.issue)
+			}
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Chapter != b.Chapter {
+			return a.Chapter < b.Chapter
+		}
+		return a.Paragraph < b.Paragraph
+	})
+	report.Flags = numberStyleFlags(report.Counts, style, guide)
+	return report
+}
+
+// numberStyleHits checks one paragraph for numeral, spelling, sentence
+// start and date format issues.
+func numberStyleHits(line string, guide numberStyleGuide, style string) []numberHit {
+	hits := []numberHit{}
+	inDate := [][]int{}
+	for format, p := range datePatterns {
+		for _, m := range p.FindAllStringIndex(line, -1) {
+			inDate = append(inDate, m)
+			if format != guide.dateFormat {
+				hits = append(hits, numberHit{m[0], m[1], NumberIssueDateFormat, fmt.Sprintf("Write dates as %s under %s style.", guide.dateExample, numberStyleLabel(style))})
+			}
+		}
+	}
+	covered := func(start, end int) bool {
+		for _, d := range inDate {
+			if start < d[1] && d[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, m := range numeralPattern.FindAllStringIndex(line, -1) {
+		if covered(m[0], m[1]) || partOfFigure(line, m[0], m[1]) {
+			continue
+		}
+		if sentenceInitial(line, m[0]) {
+			if m[0] == 0 && m[1] < len(line) && (line[m[1]] == '.' || line[m[1]] == ')') {
+				// A numbered list item.
+				continue
+			}
+			hits = append(hits, numberHit{m[0], m[1], NumberIssueSentenceStart, "Spell out a number that opens a sentence, or recast the sentence."})
+			continue
+		}
+		value, err := strconv.Atoi(line[m[0]:m[1]])
+		if err != nil || value > guide.spellOutMax || numeralLabels.MatchString(line[:m[0]]) || quantityAfter.MatchString(line[m[1]:]) {
+			continue
+		}
+		hits = append(hits, numberHit{m[0], m[1], NumberIssueNumeral, fmt.Sprintf("Spell out numbers up to %s under %s style.", spelledLimit(guide.spellOutMax), numberStyleLabel(style))})
+	}
+
+	if guide.spellOutMax < 10 {
+		for _, m := range spelledPattern.FindAllStringIndex(line, -1) {
+			if sentenceInitial(line, m[0]) {
+				continue
+			}
+			hits = append(hits, numberHit{m[0], m[1], NumberIssueSpelled, fmt.Sprintf("Use numerals from 10 up under %s style.", numberStyleLabel(style))})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+	return hits
+}
+
+// partOfFigure reports numerals inside decimals, times, ranges, scores and
+// other compound figures, or joined to a month in a partial date.
+func partOfFigure(line string, start, end int) bool {
+	if prev, size := utf8.DecodeLastRuneInString(line[:start]); strings.ContainsRune(".,:/-–", prev) {
+		if before, _ := utf8.DecodeLastRuneInString(line[:start-size]); unicode.IsDigit(before) {
+			return true
+		}
+	}
+	if next, size := utf8.DecodeRuneInString(line[end:]); strings.ContainsRune(".,:/-–", next) {
+		if after, _ := utf8.DecodeRuneInString(line[end+size:]); unicode.IsDigit(after) {
+			return true
+		}
+	}
+	return monthBefore.MatchString(line[:start]) || monthAfter.MatchString(line[end:])
+}
+
+func spelledLimit(n int) string {
+	if n == 100 {
+		return "one hundred"
+	}
+	return "nine"
+}
+
+func numberStyleLabel(style string) string {
+	switch style {
+	case NumberStyleAP:
+		return "AP"
+	case NumberStyleOxford:
+		return "Oxford"
+	default:
+		return "Chicago"
+	}
+}
+
+func numberStyleFlags(counts map[string]int, style string, guide numberStyleGuide) []string {
+	flags := []string{}
+	label := numberStyleLabel(style)
+	if n := counts[NumberIssueNumeral]; n > 0 {
+		flags = append(flags, fmt.Sprintf("%d numerals up to %d that %s style spells out.", n, guide.spellOutMax, label))
+	}
+	if n := counts[NumberIssueSpelled]; n > 0 {
+		flags = append(flags, fmt.Sprintf("%d spelled-out numbers of 10 or more that %s style writes as numerals.", n, label))
+	}
+	if n := counts[NumberIssueSentenceStart]; n > 0 {
+		flags = append(flags, fmt.Sprintf("%d sentences open with a numeral.", n))
+	}
+	if n := counts[NumberIssueUnitSystem]; n > 0 {
+		flags = append(flags, fmt.Sprintf("%d measurements break from the manuscript's main unit system.", n))
+	}
+	if n := counts[NumberIssueDateFormat]; n > 0 {
+		flags = append(flags, fmt.Sprintf("%d dates are not in %s format (%s).", n, label, guide.dateExample))
+	}
+	return flags
+}
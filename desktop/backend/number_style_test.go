@@ -0,0 +1,51 @@
+package backend
+
+import "testing"
+
+func TestAnalyzeNumberStyleAgainstGuides(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "Chapter 1\nShe walked 5 miles to the dock on March 5, 2024, and waited 20 minutes in room 12.\n1. Pack the bags.\n12 men came at 3:15 and left at 60% strength."},
+		{index: 2, text: "The road ran 8 miles, then another 3 miles, and then 4 km uphill. By 5 April 2024 it was done, fifteen days late."},
+	}
+	chicago := analyzeNumberStyle(chapters, "")
+	if chicago.StyleGuide != NumberStyleChicago {
+		t.Fatalf("want chicago by default, got %q", chicago.StyleGuide)
+	}
+	want := map[string]int{
+		NumberIssueNumeral:       4, // 5 miles, 20 minutes, 8 miles, 3 miles; "4 km" is abbreviated
+		NumberIssueSentenceStart: 1, // 12 men
+		NumberIssueUnitSystem:    1, // 4 km among miles
+		NumberIssueDateFormat:    1, // 5 April 2024
+	}
+	for kind, n := range want {
+		if chicago.Counts[kind] != n {
+			t.Fatalf("chicago %s: want %d, got %d (%+v)", kind, n, chicago.Counts[kind], chicago.Issues)
+		}
+	}
+	if chicago.Counts[NumberIssueSpelled] != 0 || len(chicago.Flags) != 4 {
+		t.Fatalf("unexpected chicago report %+v", chicago)
+	}
+	for _, issue := range chicago.Issues {
+		if issue.Kind == NumberIssueUnitSystem && (issue.Text != "4 km" || issue.Location != "Ch 2, paragraph 1") {
+			t.Fatalf("unexpected unit issue %+v", issue)
+		}
+	}
+
+	ap := analyzeNumberStyle(chapters, "AP")
+	if ap.Counts[NumberIssueNumeral] != 3 || ap.Counts[NumberIssueSpelled] != 1 {
+		t.Fatalf("ap: want 3 numerals and 1 spelled number, got %+v", ap.Counts)
+	}
+	oxford := analyzeNumberStyle(chapters, NumberStyleOxford)
+	if oxford.Counts[NumberIssueDateFormat] != 1 || !hasIssueText(oxford.Issues, "March 5, 2024") {
+		t.Fatalf("oxford: want the month-first date flagged, got %+v", oxford.Issues)
+	}
+}
+
+func hasIssueText(issues []NumberStyleIssue, text string) bool {
+	for _, issue := range issues {
+		if issue.Text == text {
+			return true
+		}
+	}
+	return false
+}
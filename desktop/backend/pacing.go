@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// Pacing stretches are only marked in books with minPacingChapters
+	// chapters; fewer leave nothing to compare a chapter with.
+	minPacingChapters = 4
+	// A chapter's tension is pacingStretchTension or more below (sag) or
+	// above (unrelenting) the book's average, in standard deviations.
+	pacingStretchTension = 0.5
+	// A sag is pacingSagChapters slow chapters in a row; unrelenting
+	// intensity is pacingIntenseChapters tense chapters in a row.
+	pacingSagChapters     = 2
+	pacingIntenseChapters = 4
+
+	PacingSag         = "sag"
+	PacingUnrelenting = "unrelenting"
+)
+
+var actionVerbs = wordSet(
+	"ran", "run", "runs", "running", "sprinted", "sprint", "dashed", "raced", "bolted", "fled", "chased", "lunged", "leapt", "leaped", "jumped",
+	"grabbed", "grab", "seized", "snatched", "yanked", "shoved", "pushed", "pulled", "dragged", "threw", "hurled", "tossed",
+	"hit", "struck", "punched", "kicked", "slapped", "slammed", "smashed", "crashed", "shattered", "broke", "tore", "ripped",
+	"fired", "shot", "stabbed", "slashed", "swung", "ducked", "dodged", "rolled", "dove", "dived", "scrambled", "stumbled", "fell",
+	"screamed", "shouted", "yelled", "gasped", "spun", "whirled", "twisted", "burst", "exploded", "charged", "attacked", "fought",
+	"escaped", "caught", "climbed", "crawled", "hurried", "rushed", "jerked", "snapped", "pounded", "banged",
+)
+
+// PacingReport estimates each chapter's tension from three proxies and marks
+// stretches that sag or never let up. Tension is relative to the book: 0 is
+// its average chapter, and each unit is one standard deviation.
+type PacingReport struct {
+	Chapters  []ChapterPacing `json:"chapters"`
+	Stretches []PacingStretch `json:"stretches"`
+	Flags     []string        `json:"flags"`
+}
+
+// ChapterPacing holds a chapter's tension proxies: the share of its words
+// spoken in dialogue, the variation of its sentence lengths (standard
+// deviation over mean) and its action verbs per 100 words.
+type ChapterPacing struct {
+	Chapter           int     `json:"chapter"`
+	Title             string  `json:"title"`
+	Words             int     `json:"words"`
+	DialogueShare     float64 `json:"dialogueShare"`
+	SentenceVariation float64 `json:"sentenceVariation"`
+	ActionVerbsPer100 float64 `json:"actionVerbsPer100"`
+	Tension           float64 `json:"tension"`
+}
+
+// PacingStretch is a run of chapters that sag or keep up unrelenting
+// intensity.
+type PacingStretch struct {
+	Kind         string  `json:"kind"`
+	StartChapter int     `json:"startChapter"`
+	EndChapter   int     `json:"endChapter"`
+	Tension      float64 `json:"tension"`
+}
+
+func emptyPacingReport() PacingReport {
+	return PacingReport{Chapters: []ChapterPacing{}, Stretches: []PacingStretch{}, Flags: []string{}}
+}
+
+// analyzePacing measures the tension proxies per chapter, combines them into
+// a tension score relative to the book and marks sags and unrelenting
+// stretches.
+func analyzePacing(chapters []chapter) PacingReport {
+	report := emptyPacingReport()
+	for _, ch := range chapters {
+		words, dialogueWords, actions := 0, 0, 0
+		lengths := []float64{}
+		for _, paragraph := range chapterParagraphs(ch.text) {
+			all := strings.Fields(paragraph)
+			words += len(all)
+			dialogueWords += len(all) - len(strings.Fields(narrationOnly(paragraph)))
+			for _, w := range wordPattern.FindAllString(strings.ToLower(paragraph), -1) {
+				if actionVerbs[w] {
+					actions++
+				}
+			}
+			for _, s := range splitSentences(paragraph) {
+				lengths = append(lengths, float64(len(strings.Fields(s))))
+			}
+		}
+		if words == 0 {
+			continue
+		}
+		mean, sd := meanAndDeviation(lengths)
+		variation := 0.0
+		if mean > 0 {
+			variation = sd / mean
+		}
+		report.Chapters = append(report.Chapters, ChapterPacing{
+			Chapter:           ch.index,
+			Title:             ch.title,
+			Words:             words,
+			DialogueShare:     round2(float64(dialogueWords) / float64(words)),
+			SentenceVariation: round2(variation),
+			ActionVerbsPer100: round2(float64(actions) * 100 / float64(words)),
+		})
+	}
+	scorePacingTension(report.Chapters)
+	if len(report.Chapters) < minPacingChapters {
+		return report
+	}
+	report.Stretches = append(pacingStretches(report.Chapters, PacingSag, pacingSagChapters), pacingStretches(report.Chapters, PacingUnrelenting, pacingIntenseChapters)...)
+	for _, s := range report.Stretches {
+		what := "pacing sags"
+		if s.Kind == PacingUnrelenting {
+			what = "intensity never lets up"
+		}
+		report.Flags = append(report.Flags, fmt.Sprintf("Chapters %d–%d: %s (tension %+.1f against the book's average).", s.StartChapter, s.EndChapter, what, s.Tension))
+	}
+	return report
+}
+
+// scorePacingTension sets each chapter's tension to the mean of its proxies'
+// standard scores across the book.
+func scorePacingTension(chapters []ChapterPacing) {
+	proxies := []func(ChapterPacing) float64{
+		func(c ChapterPacing) float64 { return c.DialogueShare },
+		func(c ChapterPacing) float64 { return c.SentenceVariation },
+		func(c ChapterPacing) float64 { return c.ActionVerbsPer100 },
+	}
+	tension := make([]float64, len(chapters))
+	for _, proxy := range proxies {
+		values := make([]float64, len(chapters))
+		for i, c := range chapters {
+			values[i] = proxy(c)
+		}
+		mean, sd := meanAndDeviation(values)
+		if sd == 0 {
+			continue
+		}
+		for i, v := range values {
+			tension[i] += (v - mean) / sd / float64(len(proxies))
+		}
+	}
+	for i := range chapters {
+		chapters[i].Tension = round2(tension[i])
+	}
+}
+
+// pacingStretches finds runs of at least minRun consecutive chapters whose
+// tension sits past pacingStretchTension on kind's side of the average.
+func pacingStretches(chapters []ChapterPacing, kind string, minRun int) []PacingStretch {
+	out := []PacingStretch{}
+	past := func(c ChapterPacing) bool {
+		if kind == PacingSag {
+			return c.Tension <= -pacingStretchTension
+		}
+		return c.Tension >= pacingStretchTension
+	}
+	for i := 0; i < len(chapters); {
+		if !past(chapters[i]) {
+			i++
+			continue
+		}
+		j, sum := i, 0.0
+		for j < len(chapters) && past(chapters[j]) {
+			sum += chapters[j].Tension
+			j++
+		}
+		if j-i >= minRun {
+			out = append(out, PacingStretch{Kind: kind, StartChapter: chapters[i].Chapter, EndChapter: chapters[j-1].Chapter, Tension: round2(sum / float64(j-i))})
+		}
+		i = j
+	}
+	return out
+}
+
+func meanAndDeviation(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePacingMarksSagAndUnrelentingStretches(t *testing.T) {
+	tense := "\"Run!\" she screamed. He grabbed the rifle and fired twice, then lunged for the door as the window shattered behind them and glass rained across the floor. They ran."
+	slow := "The house stood at the end of the lane where it had always stood. The garden was quiet in the afternoon light. The kettle sat on the stove. The clock ticked on the wall."
+	texts := []string{slow, tense, tense, tense, tense, slow, slow, tense}
+	chapters := []chapter{}
+	for i, text := range texts {
+		chapters = append(chapters, chapter{index: i + 1, title: "Part", text: text})
+	}
+	report := analyzePacing(chapters)
+	if len(report.Chapters) != len(texts) {
+		t.Fatalf("expected every chapter measured, got %+v", report.Chapters)
+	}
+	if c := report.Chapters[1]; c.DialogueShare == 0 || c.ActionVerbsPer100 == 0 || c.Tension <= 0 {
+		t.Fatalf("expected the action chapter to read as tense, got %+v", c)
+	}
+	want := map[string][2]int{PacingUnrelenting: {2, 5}, PacingSag: {6, 7}}
+	if len(report.Stretches) != len(want) {
+		t.Fatalf("unexpected stretches %+v", report.Stretches)
+	}
+	for _, s := range report.Stretches {
+		if span := want[s.Kind]; span != [2]int{s.StartChapter, s.EndChapter} {
+			t.Fatalf("unexpected %s stretch %+v", s.Kind, s)
+		}
+	}
+	if !strings.Contains(strings.Join(report.Flags, "\n"), "Chapters 6–7: pacing sags") {
+		t.Fatalf("unexpected flags %q", report.Flags)
+	}
+	if short := analyzePacing(chapters[:3]); len(short.Stretches) != 0 || len(short.Chapters) != 3 {
+		t.Fatalf("expected no stretches marked in a three-chapter book, got %+v", short)
+	}
+}
@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/ingest"
+)
+
+// pageAnchorWords is how many opening words of an issue's context must match
+// to find the passage in its chapter.
+const pageAnchorWords = 8
+
+type pagesKey struct{}
+
+// WithPages passes a PDF source's page spans (see ingest.Parsed.Pages) to the
+// run, so findings can cite the page they are on.
+func WithPages(ctx context.Context, pages []ingest.PageSpan) context.Context {
+	return context.WithValue(ctx, pagesKey{}, pages)
+}
+
+func pagesFromContext(ctx context.Context) []ingest.PageSpan {
+	pages, _ := ctx.Value(pagesKey{}).([]ingest.PageSpan)
+	return pages
+}
+
+// pageRef cites pages the way editors do: "p. 214", or "pp. 214–216" for a
+// range. It is empty when the page is unknown.
+func pageRef(start, end int) string {
+	switch {
+	case start <= 0:
+		return ""
+	case end <= start:
+		return fmt.Sprintf("p. %d", start)
+	default:
+		return fmt.Sprintf("pp. %d–%d", start, end)
+	}
+}
+
+// pageAIWindows sets the pages of every placed AI window and attributed
+// sentence and adds them to its location label. It returns how many windows
+// got a page.
+func pageAIWindows(report *aidetect.Report, text string, pages []ingest.PageSpan) int {
+	offsets := aidetect.WordOffsets(text)
+	place := func(loc *aidetect.WindowLocation, start, end int) bool {
+		if loc == nil || start < 0 || start >= len(offsets) || end <= start {
+			return false
+		}
+		loc.StartPage = ingest.PageAt(pages, offsets[start])
+		loc.EndPage = ingest.PageAt(pages, offsets[min(end, len(offsets))-1])
+		loc.Label = windowLocationLabel(*loc)
+		return loc.StartPage > 0
+	}
+	paged := 0
+	for i := range report.Windows {
+		w := &report.Windows[i]
+		if place(w.Location, w.StartWord, w.EndWord) {
+			paged++
+		}
+	}
+	for i := range report.Sentences {
+		s := &report.Sentences[i]
+		place(s.Location, s.StartWord, s.EndWord)
+	}
+	return paged
+}
+
+// pageHealthIssues sets PageA and PageB on every issue: the page of its
+// context when that passage is found in its chapter, otherwise the page its
+// chapter starts on. A series issue's ContextA is an earlier installment, so
+// only its PageB is set. It returns how many issues got a page.
+func pageHealthIssues(issues []HealthIssue, text string, chapters []chapter, pages []ingest.PageSpan) int {
+	ranges := chapterRanges(text, chapters)
+	pageOf := func(chapterIndex int, context string) int {
+		r, ok := ranges[chapterIndex]
+		if !ok {
+			return 0
+		}
+		if at := contextOffset(text[r[0]:r[1]], context); at >= 0 {
+			return ingest.PageAt(pages, r[0]+at)
+		}
+		return ingest.PageAt(pages, r[0])
+	}
+	paged := 0
+	for i := range issues {
+		issue := &issues[i]
+		if issue.Kind != HealthIssueSeriesContinuity {
+			issue.PageA = pageOf(issue.ChapterA, issue.ContextA)
+		}
+		issue.PageB = pageOf(issue.ChapterB, issue.ContextB)
+		if issue.PageA > 0 || issue.PageB > 0 {
+			paged++
+		}
+	}
+	return paged
+}
+
+// chapterRanges finds each chapter's byte range in the manuscript text by its
+// first line. A chapter that cannot be found is left out.
+func chapterRanges(text string, chapters []chapter) map[int][2]int {
+	type start struct{ chapter, at int }
+	starts := []start{}
+	cursor := 0
+	for _, ch := range chapters {
+		lines := nonEmptyLines(ch.text)
+		if len(lines) == 0 {
+			continue
+		}
+		at := strings.Index(text[cursor:], lines[0])
+		if at < 0 {
+			continue
+		}
+		cursor += at
+		starts = append(starts, start{chapter: ch.index, at: cursor})
+		cursor += len(lines[0])
+	}
+	out := make(map[int][2]int, len(starts))
+	for i, s := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1].at
+		}
+		out[s.chapter] = [2]int{s.at, end}
+	}
+	return out
+}
+
+// contextOffset finds where an issue's context starts in text, matching its
+// opening words across any whitespace, or returns -1.
+func contextOffset(text, context string) int {
+	words := strings.Fields(strings.TrimRight(context, ".…"))
+	if len(words) == 0 {
+		return -1
+	}
+	if len(words) > pageAnchorWords {
+		words = words[:pageAnchorWords]
+	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	loc := regexp.MustCompile(strings.Join(words, `\s+`)).FindStringIndex(text)
+	if loc == nil {
+		return -1
+	}
+	return loc[0]
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/ingest"
+)
+
+func TestPageAIWindowsCitesPages(t *testing.T) {
+	text := "Chapter 1\nThe ferry left at dawn.\nMara watched it go.\nChapter 2\nThe letter came in spring."
+	pages := []ingest.PageSpan{
+		{Page: 12, Start: 0, End: strings.Index(text, "Mara")},
+		{Page: 13, Start: strings.Index(text, "Mara"), End: len(text)},
+	}
+	report := aidetect.Report{Windows: []aidetect.WindowReport{
+		{WindowID: "w-001", StartWord: 2, EndWord: 10},
+		{WindowID: "w-002", StartWord: 12, EndWord: 17},
+	}}
+	locateAIWindows(&report, text, splitChapters(text))
+	if paged := pageAIWindows(&report, text, pages); paged != 2 {
+		t.Fatalf("expected both windows paged, got %d", paged)
+	}
+	if got := aiWindowName(report.Windows[0]); got != "Ch 1, paragraphs 1–2, pp. 12–13" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if got := aiWindowName(report.Windows[1]); got != "Ch 2, paragraph 1, p. 13" {
+		t.Fatalf("unexpected label %q", got)
+	}
+}
+
+func TestPageHealthIssuesPlacesContextsAndChapters(t *testing.T) {
+	text := "Chapter 1\nHer eyes were green as the sea.\nChapter 2\nThe rain came.\nHer eyes were brown,\nlike the mud."
+	pages := []ingest.PageSpan{
+		{Page: 1, Start: 0, End: strings.Index(text, "The rain")},
+		{Page: 2, Start: strings.Index(text, "The rain"), End: strings.Index(text, "Her eyes were brown")},
+		{Page: 3, Start: strings.Index(text, "Her eyes were brown"), End: len(text)},
+	}
+	issues := []HealthIssue{
+		{Kind: HealthIssueTenseDrift, ChapterA: 2, ChapterB: 2, ContextA: "Her eyes were brown, like the mud.", ContextB: "Not in the text"},
+		{Kind: HealthIssueSeriesContinuity, ChapterA: 1, ChapterB: 1, ContextA: "Installment 1: Tides"},
+	}
+	if paged := pageHealthIssues(issues, text, splitChapters(text), pages); paged != 2 {
+		t.Fatalf("expected both issues paged, got %d", paged)
+	}
+	if issues[0].PageA != 3 || issues[0].PageB != 2 {
+		t.Fatalf("expected the context on p. 3 and the chapter start on p. 2, got %+v", issues[0])
+	}
+	if issues[1].PageA != 0 || issues[1].PageB != 1 {
+		t.Fatalf("expected only this installment's page on a series issue, got %+v", issues[1])
+	}
+	if got := issuePageSuffix(issues[0]); got != " (pp. 3 and 2)" {
+		t.Fatalf("unexpected page suffix %q", got)
+	}
+}
@@ -0,0 +1,284 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of quoted material that may need permission.
+const (
+	PermissionEpigraph  = "epigraph"
+	PermissionLyrics    = "lyrics"
+	PermissionPoem      = "poem"
+	PermissionQuotation = "quotation"
+)
+
+const (
+	// maxQuotedBlockLines and maxQuotedBlockWords bound the text above an
+	// attribution line that is read as one quotation.
+	maxQuotedBlockLines = 6
+	maxQuotedBlockWords = 150
+	// maxAttributionWords keeps ordinary dash-led dialogue from being read
+	// as an attribution.
+	maxAttributionWords = 12
+	// verseLineWords is the longest line read as a line of verse.
+	verseLineWords = 10
+	// publicDomainYear is the last publication year in the US public domain.
+	publicDomainYear = 1930
+	maxExcerptRunes  = 80
+)
+
+var (
+	permissionAttributionLine = regexp.MustCompile(`^[—–~-]+\s*(\p{Lu}.*)$`)
+	inlineAttributedQuote     = regexp.MustCompile(`^[“"](.{8,600})[”"]\s*[—–]+\s*(\p{Lu}[^—–]{1,120})$`)
+	sungQuoteBefore           = regexp.MustCompile(`(?i)\b(?:sang|sings|singing|sung|crooned|belted|hummed)\b[^"“\n]{0,30}[“"]([^"”\n]{8,300})[”"]`)
+	sungQuoteAfter            = regexp.MustCompile(`(?i)[“"]([^"”\n]{8,300})[”"],?\s+(?:\p{Lu}\p{L}+|he|she|they|I|we)\s+(?:sang|crooned|belted|hummed)\b`)
+	songCue                   = regexp.MustCompile(`(?i)\b(?:sang|sing|sings|singing|song|songs|lyrics?|chorus|radio|jukebox|ballad|anthem|hymn|album|band)\b`)
+	poemCue                   = regexp.MustCompile(`(?i)\b(?:poem|poems|poet|poetry|recited|recite|reciting|verse|verses|stanza|sonnet)\b`)
+	quotedTitle               = regexp.MustCompile(`[“"]([^"”\n]{2,60})[”"]`)
+	attributionYear           = regexp.MustCompile(`\b(1[0-9]{3}|20[0-9]{2})\b`)
+)
+
+// publicDomainAuthors died long enough ago that their original works are out
+// of copyright; a modern translation or edition may still need permission.
+var publicDomainAuthors = []string{
+	"shakespeare", "milton", "blake", "keats", "shelley", "byron", "wordsworth", "coleridge", "tennyson",
+	"browning", "dickinson", "whitman", "poe", "donne", "marvell", "chaucer", "homer", "virgil", "dante",
+	"ovid", "horace", "rumi", "burns", "longfellow", "austen", "dickens", "thoreau", "emerson", "aurelius",
+	"seneca", "plato", "aristotle", "confucius", "lao tzu", "sun tzu", "psalm", "proverbs", "ecclesiastes",
+	"genesis", "isaiah", "matthew", "john ", "corinthians", "bible",
+}
+
+// PermissionsReport lists quoted lyrics, poems, epigraphs and attributed
+// quotations, which commonly need permission from the rights holder.
+type PermissionsReport struct {
+	Items      []PermissionItem `json:"items"`
+	TotalWords int              `json:"totalWords"`
+	Flags      []string         `json:"flags"`
+}
+
+type PermissionItem struct {
+	Kind string `json:"kind"`
+	// Excerpt is the start of the quoted text.
+	Excerpt     string `json:"excerpt"`
+	Attribution string `json:"attribution"`
+	Words       int    `json:"words"`
+	Lines       int    `json:"lines"`
+	Chapter     int    `json:"chapter"`
+	Paragraph   int    `json:"paragraph"`
+	Location    string `json:"location"`
+	// PublicDomain is set when the attribution names an author or a year
+	// old enough to be out of copyright.
+	PublicDomain bool   `json:"publicDomain"`
+	Note         string `json:"note"`
+}
+
+func emptyPermissionsReport() PermissionsReport {
+	return PermissionsReport{Items: []PermissionItem{}, Flags: []string{}}
+}
+
+// auditPermissions finds quoted material by layout: a short block or quote
+// closed by a "— Author, Work" line, verse lines introduced by a song or
+// poem cue, and words quoted as sung. Length is estimated in words and
+// lines so the contracts team can judge what to clear.
+func auditPermissions(chapters []chapter) PermissionsReport {
+	report := emptyPermissionsReport()
+	for _, ch := range chapters {
+		paragraphs := chapterParagraphs(ch.text)
+		// next is the first paragraph not already part of an item.
+		next := 0
+		for i := 0; i < len(paragraphs); i++ {
+			if m := inlineAttributedQuote.FindStringSubmatch(paragraphs[i]); m != nil {
+				report.Items = append(report.Items, permissionItem(ch.index, i, []string{m[1]}, strings.TrimSpace(m[2]), ""))
+				next = i + 1
+				continue
+			}
+			if attribution, ok := attributionLine(paragraphs[i]); ok {
+				if start := quotedBlockStart(paragraphs, next, i); start < i {
+					report.Items = append(report.Items, permissionItem(ch.index, start, paragraphs[start:i], attribution, ""))
+				}
+				next = i + 1
+				continue
+			}
+			if i > 0 && i >= next {
+				cue := paragraphs[i-1]
+				if end := verseEnd(paragraphs, i); end-i >= 2 && (songCue.MatchString(cue) || poemCue.MatchString(cue)) {
+					// Verse closed by an attribution is left to that line.
+					if _, attributed := attributionLine(paragraphs[min(end, len(paragraphs)-1)]); end == len(paragraphs) || !attributed {
+						report.Items = append(report.Items, permissionItem(ch.index, i, paragraphs[i:end], "", cue))
+						next = end
+						i = end - 1
+						continue
+					}
+				}
+			}
+			for _, p := range []*regexp.Regexp{sungQuoteBefore, sungQuoteAfter} {
+				for _, m := range p.FindAllStringSubmatch(paragraphs[i], -1) {
+					item := permissionItem(ch.index, i, []string{m[1]}, "", paragraphs[i])
+					item.Kind = PermissionLyrics
+					item.Note = permissionNote(item)
+					report.Items = append(report.Items, item)
+				}
+			}
+		}
+	}
+	for _, item := range report.Items {
+		report.TotalWords += item.Words
+	}
+	if n := len(report.Items); n > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d quoted passages (%d words) may need permission to reprint.", n, report.TotalWords))
+	}
+	return report
+}
+
+// attributionLine reads a "— Author, Work" line. Dash-led dialogue is ruled
+// out by its length, its questions and exclamations, and its speech verbs.
+func attributionLine(line string) (string, bool) {
+	m := permissionAttributionLine.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	attribution := strings.TrimSpace(m[1])
+	if len(strings.Fields(attribution)) > maxAttributionWords || strings.ContainsAny(attribution, "?!") || speechVerbPattern.MatchString(attribution) {
+		return "", false
+	}
+	return attribution, true
+}
+
+// quotedBlockStart walks back from the attribution line at end to the start
+// of the quotation above it, never before from. A long paragraph is quoted
+// prose on its own; short lines are verse, and the walk stops at a line that
+// closes a sentence, since only a stanza's last line usually does, or at
+// one that introduces the quotation with a colon. It
+// returns end when the block is empty or reads as dialogue.
+func quotedBlockStart(paragraphs []string, from, end int) int {
+	if end == from || isDashLed(paragraphs[end-1]) {
+		return end
+	}
+	if !isVerseLine(paragraphs[end-1]) {
+		if len(strings.Fields(paragraphs[end-1])) > maxQuotedBlockWords {
+			return end
+		}
+		return end - 1
+	}
+	start := end - 1
+	for start > from && end-start < maxQuotedBlockLines && isVerseLine(paragraphs[start-1]) && !isDashLed(paragraphs[start-1]) && !closesSentence(paragraphs[start-1]) && !strings.HasSuffix(paragraphs[start-1], ":") {
+		start--
+	}
+	return start
+}
+
+// verseEnd returns the line after a run of verse lines starting at i. A line
+// that closes a sentence ends the run; it belongs to the verse only when the
+// line before it runs on with a comma or semicolon.
+func verseEnd(paragraphs []string, i int) int {
+	j := i
+	for j < len(paragraphs) && j-i < maxQuotedBlockLines && isVerseLine(paragraphs[j]) && !isDashLed(paragraphs[j]) {
+		if closesSentence(paragraphs[j]) {
+			if j > i && (strings.HasSuffix(paragraphs[j-1], ",") || strings.HasSuffix(paragraphs[j-1], ";")) {
+				return j + 1
+			}
+			return j
+		}
+		j++
+	}
+	return j
+}
+
+func isVerseLine(line string) bool {
+	return len(strings.Fields(line)) <= verseLineWords
+}
+
+func isDashLed(line string) bool {
+	return strings.HasPrefix(line, "—") || strings.HasPrefix(line, "–") || strings.HasPrefix(line, "-")
+}
+
+func closesSentence(line string) bool {
+	line = strings.TrimRight(line, `"”’)`)
+	return strings.HasSuffix(line, ".") || strings.HasSuffix(line, "?") || strings.HasSuffix(line, "!")
+}
+
+// permissionItem describes the quoted lines starting at paragraph index i.
+// cue is the surrounding text that introduced unattributed verse.
+func permissionItem(chapterIndex, i int, lines []string, attribution, cue string) PermissionItem {
+	text := strings.Join(lines, " / ")
+	words := 0
+	for _, l := range lines {
+		words += len(strings.Fields(l))
+	}
+	if attribution == "" {
+		if m := quotedTitle.FindStringSubmatch(cue); m != nil && !strings.Contains(text, m[1]) {
+			attribution = m[1]
+		}
+	}
+	item := PermissionItem{
+		Excerpt:      truncateRunes(strings.Trim(text, `"“”`), maxExcerptRunes),
+		Attribution:  attribution,
+		Words:        words,
+		Lines:        len(lines),
+		Chapter:      chapterIndex,
+		Paragraph:    i + 1,
+		Location:     fmt.Sprintf("Ch %d, paragraph %d", chapterIndex, i+1),
+		PublicDomain: likelyPublicDomain(attribution),
+	}
+	switch {
+	case songCue.MatchString(attribution) || songCue.MatchString(cue):
+		item.Kind = PermissionLyrics
+	case i == 0 && attribution != "":
+		item.Kind = PermissionEpigraph
+	case poemCue.MatchString(cue) || len(lines) >= 2 && allShortLines(lines):
+		item.Kind = PermissionPoem
+	default:
+		item.Kind = PermissionQuotation
+	}
+	item.Note = permissionNote(item)
+	return item
+}
+
+func allShortLines(lines []string) bool {
+	for _, l := range lines {
+		if len(strings.Fields(l)) > verseLineWords {
+			return false
+		}
+	}
+	return true
+}
+
+// likelyPublicDomain reports an attribution naming a long-dead author or
+// scripture, or a publication year before the US public domain cutoff.
+func likelyPublicDomain(attribution string) bool {
+	if attribution == "" {
+		return false
+	}
+	lower := strings.ToLower(attribution) + " "
+	for _, name := range publicDomainAuthors {
+		if strings.Contains(lower, name) {
+			return true
+		}
+	}
+	for _, m := range attributionYear.FindAllString(attribution, -1) {
+		if year, err := strconv.Atoi(m); err == nil && year <= publicDomainYear {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionNote(item PermissionItem) string {
+	if item.PublicDomain {
+		return "Likely public domain; confirm the edition or translation quoted."
+	}
+	switch item.Kind {
+	case PermissionLyrics:
+		return "Song lyrics usually need permission even for a line or two."
+	case PermissionPoem:
+		return "Poetry usually needs permission beyond a line or two."
+	default:
+		if item.Attribution == "" {
+			return "Identify the source; quotations beyond fair use need permission."
+		}
+		return "Quotations beyond fair use need permission; check the length against the publisher's policy."
+	}
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuditPermissionsFindsQuotedMaterial(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "Chapter 1\nThe woods are lovely, dark and deep,\nBut I have promises to keep.\n— Robert Frost, “Stopping by Woods on a Snowy Evening”\nMara woke before dawn."},
+		{index: 2, text: "The radio in the kitchen was playing their song.\nHold me closer, tiny dancer\nCount the headlights on the highway\nShe turned it off.\nLater he sang “we are the champions, my friends” all the way home."},
+		{index: 3, text: "Dad always said the same thing.\n“To be, or not to be, that is the question.” — William Shakespeare, Hamlet\n— Stop that, said Mara. Nobody wants to hear you recite the whole play again tonight, honestly."},
+	}
+	report := auditPermissions(chapters)
+	if len(report.Items) != 4 {
+		t.Fatalf("want 4 items, got %+v", report.Items)
+	}
+	epigraph := report.Items[0]
+	if epigraph.Kind != PermissionEpigraph || epigraph.Lines != 2 || epigraph.Words != 13 || epigraph.Location != "Ch 1, paragraph 1" || !strings.HasPrefix(epigraph.Attribution, "Robert Frost") {
+		t.Fatalf("unexpected epigraph %+v", epigraph)
+	}
+	lyrics := report.Items[1]
+	if lyrics.Kind != PermissionLyrics || lyrics.Lines != 2 || lyrics.Paragraph != 2 || lyrics.Attribution != "" {
+		t.Fatalf("unexpected lyrics %+v", lyrics)
+	}
+	if sung := report.Items[2]; sung.Kind != PermissionLyrics || sung.Excerpt != "we are the champions, my friends" {
+		t.Fatalf("unexpected sung quote %+v", sung)
+	}
+	quote := report.Items[3]
+	if quote.Kind != PermissionQuotation || !quote.PublicDomain || !strings.Contains(quote.Note, "public domain") {
+		t.Fatalf("unexpected quotation %+v", quote)
+	}
+	if report.TotalWords != 13+11+6+10 || len(report.Flags) != 1 {
+		t.Fatalf("unexpected totals %d %v", report.TotalWords, report.Flags)
+	}
+}
+
+func TestAuditPermissionsIgnoresDashDialogue(t *testing.T) {
+	chapters := []chapter{{index: 1, text: "— Where are you going? asked Mara.\n— Home, said Tom.\n— Now?\nThey walked on in silence."}}
+	if report := auditPermissions(chapters); len(report.Items) != 0 {
+		t.Fatalf("want no items for dash dialogue, got %+v", report.Items)
+	}
+}
+
+func TestPlainReportListsPermissions(t *testing.T) {
+	data := plainReportFixture()
+	data.Permissions = auditPermissions([]chapter{{index: 1, text: "Verse she loved:\nShall I compare thee\nTo a summer's day\n— Shakespeare, Sonnet 18\nShe smiled."}})
+	report := PlainReport(data)
+	if !strings.Contains(report, "## Permissions") || !strings.Contains(report, "- Ch 1, paragraph 2, poem (Shakespeare, Sonnet 18; 8 words, 2 lines)") {
+		t.Fatalf("permissions missing from report:\n%s", report)
+	}
+}
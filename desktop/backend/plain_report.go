@@ -0,0 +1,960 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/gates"
+	"book_dashboard/internal/slop"
+	"book_dashboard/internal/timeline"
+	"book_dashboard/internal/workspace"
+)
+
+// maxPlainReportWindows caps how many AI-likelihood windows the plain report
+// lists; the rest are summarized by count.
+const maxPlainReportWindows = 5
+
+// PlainReport renders the dashboard as linear Markdown for screen readers and
+// plain-text review. Everything the dashboard conveys with colour or heatmaps
+// is spelled out in words, and headings follow a single outline so the report
+// can be navigated heading by heading. It is the internal export; see
+// PlainReportFor.
+func PlainReport(data DashboardData) string {
+	return PlainReportFor(data, ExportProfileInternal)
+}
+
+// PlainReportFor renders the plain report for an export profile: the
+// author-facing profile renders RedactForAuthor's copy without the AI
+// likelihood section, and the internal profile adds the reviewer notes.
+func PlainReportFor(data DashboardData, profile string) string {
+	internal := profile != ExportProfileAuthor
+	if !internal {
+		data = RedactForAuthor(data)
+	}
+	var b strings.Builder
+	title := strings.TrimSpace(data.BookTitle)
+	if title == "" {
+		title = "Untitled manuscript"
+	}
+	fmt.Fprintf(&b, "# Manuscript Health Report: %s\n\n", title)
+	writeOverview(&b, data)
+	writeScoreBreakdown(&b, data)
+	writeQualityGates(&b, data)
+	writeBenchmarks(&b, data)
+	writeAnthology(&b, data)
+	writeDraftMarkers(&b, data)
+	writeNonfiction(&b, data)
+	writeLanguage(&b, data)
+	writeNumberStyle(&b, data)
+	writePermissions(&b, data)
+	writeHealthIssues(&b, data)
+	if internal {
+		writeAIDetection(&b, data)
+	}
+	writeDraftProvenance(&b, data)
+	writeProseStatistics(&b, data)
+	writeCrutchWords(&b, data)
+	writeGenre(&b, data)
+	writeStructure(&b, data)
+	writePacing(&b, data)
+	writeManuscriptStats(&b, data)
+	writeTimeline(&b, data)
+	writeSeries(&b, data)
+	writeChapters(&b, data)
+	writeVoices(&b, data)
+	writeNameHygiene(&b, data)
+	writeTerminology(&b, data)
+	writeBookends(&b, data)
+	writeSensitivity(&b, data)
+	writeCompTitles(&b, data)
+	if internal {
+		writeReviewerNotes(&b, data)
+	}
+	return b.String()
+}
+
+// writeScoreBreakdown lists what each component took off the score; reports
+// saved before the breakdown existed have none.
+func writeScoreBreakdown(b *strings.Builder, data DashboardData) {
+	if len(data.ScoreBreakdown.Components) == 0 {
+		return
+	}
+	b.WriteString("## Score breakdown\n\n")
+	fmt.Fprintf(b, "The score starts at %d and each component takes points off.\n\n", data.ScoreBreakdown.Base)
+	for _, c := range data.ScoreBreakdown.Components {
+		if c.Points == 0 {
+			continue
+		}
+		line := fmt.Sprintf("- %s: -%d", c.Label, c.Points)
+		if c.Detail != "" {
+			line += " (" + c.Detail + ")"
+		}
+		b.WriteString(line + "\n")
+	}
+	if data.ScoreBreakdown.Deducted > data.ScoreBreakdown.Base {
+		fmt.Fprintf(b, "- %d points in all; the score stops at 0\n", data.ScoreBreakdown.Deducted)
+	}
+	b.WriteString("\n")
+}
+
+// writeQualityGates is the submission checklist: each gate with its verdict,
+// the measured value and the threshold it was held to.
+func writeQualityGates(b *strings.Builder, data DashboardData) {
+	if len(data.QualityGates) == 0 {
+		return
+	}
+	b.WriteString("## Submission checklist\n\n")
+	for _, g := range data.QualityGates {
+		verdict, measured := "PASS", "not measured"
+		switch {
+		case g.Skipped:
+			verdict = "SKIPPED"
+		case !g.Passed:
+			verdict = "FAIL"
+		}
+		if g.Value != nil {
+			measured = "measured " + gates.FormatValue(g.Metric, *g.Value)
+		}
+		fmt.Fprintf(b, "- %s: %s (%s; threshold %s %s)\n", verdict, g.Label, measured, g.Op, gates.FormatValue(g.Metric, g.Threshold))
+	}
+	if gates.Passed(data.QualityGates) {
+		b.WriteString("\nEvery gate passed.\n\n")
+	} else {
+		b.WriteString("\nAt least one gate failed.\n\n")
+	}
+}
+
+// writeBenchmarks places the run's scores among the other books in the
+// workspace library.
+func writeBenchmarks(b *strings.Builder, data DashboardData) {
+	if len(data.Benchmarks) == 0 {
+		return
+	}
+	b.WriteString("## Benchmarks\n\n")
+	for _, bm := range data.Benchmarks {
+		value := gates.FormatValue(bm.Metric, bm.Value)
+		if bm.Metric == benchmarkVerbatimCoverage || bm.Metric == benchmarkPhraseCoverage {
+			value = percentInWords(bm.Value)
+		}
+		fmt.Fprintf(b, "- %s: %s, higher than %.0f percent of the %d other books analyzed\n", bm.Label, value, bm.Percentile, bm.Books)
+	}
+	b.WriteString("\n")
+}
+
+// scoreInWords describes a 0-100 score so its meaning does not depend on the
+// colour the dashboard would give it.
+func scoreInWords(score int) string {
+	band := "needs attention"
+	switch {
+	case score >= 85:
+		band = "good"
+	case score >= 70:
+		band = "fair"
+	}
+	return fmt.Sprintf("%d out of 100 (%s)", score, band)
+}
+
+func percentInWords(p float64) string {
+	return fmt.Sprintf("%.0f percent", p*100)
+}
+
+func sectionDisabled(b *strings.Builder, data DashboardData, section string) bool {
+	if data.Sections[section] != SectionStatusDisabled {
+		return false
+	}
+	b.WriteString("Disabled in project settings.\n\n")
+	return true
+}
+
+func writeOverview(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Overview\n\n")
+	fmt.Fprintf(b, "- Manuscript health score: %s\n", scoreInWords(data.MHDScore))
+	fmt.Fprintf(b, "- Words: %d\n", data.WordCount)
+	fmt.Fprintf(b, "- Chapters: %d\n", data.ChapterCount)
+	fmt.Fprintf(b, "- Consistency issues: %d\n", len(data.HealthIssues))
+	if data.RunStats.SourceName != "" {
+		fmt.Fprintf(b, "- Source file: %s\n", data.RunStats.SourceName)
+	}
+	if data.RunStats.CompletedAt != "" {
+		fmt.Fprintf(b, "- Analyzed: %s (run %s)\n", data.RunStats.CompletedAt, data.RunStats.RunID)
+	}
+	if data.RunStats.Profile != "" {
+		fmt.Fprintf(b, "- Analysis profile: %s\n", data.RunStats.Profile)
+	}
+	if data.PriorAnalysis != nil {
+		fmt.Fprintf(b, "- Analyzed before as %q on %s\n", data.PriorAnalysis.Title, data.PriorAnalysis.LastAnalyzedAt)
+	}
+	if data.SourceIntegrity != nil && data.SourceIntegrity.Warning != "" {
+		fmt.Fprintf(b, "- Warning: %s\n", data.SourceIntegrity.Warning)
+	}
+	for _, drift := range data.ModelDrift {
+		if drift.Material {
+			fmt.Fprintf(b, "- Warning: %s\n", drift.Warning)
+		}
+	}
+	b.WriteString("\n")
+}
+
+func writeAnthology(b *strings.Builder, data DashboardData) {
+	if data.Anthology == nil {
+		return
+	}
+	a := data.Anthology
+	b.WriteString("## Stories\n\n")
+	fmt.Fprintf(b, "- Stories: %d, found by %s\n", len(a.Stories), a.Detection)
+	if len(a.Authors) > 1 {
+		fmt.Fprintf(b, "- Authors: %s\n", strings.Join(a.Authors, ", "))
+	}
+	for _, flag := range a.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	b.WriteString("\n")
+	for _, s := range a.Stories {
+		fmt.Fprintf(b, "### %d. %s\n\n", s.Index, s.Title)
+		if s.Author != "" {
+			fmt.Fprintf(b, "- Author: %s\n", s.Author)
+		}
+		fmt.Fprintf(b, "- Words: %d\n", s.WordCount)
+		if s.TopGenre != "" {
+			fmt.Fprintf(b, "- Leading genre: %s, %s\n", s.TopGenre, percentInWords(s.TopGenreScore))
+		}
+		if s.AI.Windows > 0 {
+			fmt.Fprintf(b, "- AI likelihood: %s on average, highest %s\n", percentInWords(s.AI.Mean), percentInWords(s.AI.Max))
+		}
+		if s.GrammarScore > 0 || s.SpellingScore > 0 {
+			fmt.Fprintf(b, "- Spelling: %s\n- Grammar: %s\n", scoreInWords(s.SpellingScore), scoreInWords(s.GrammarScore))
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeDraftMarkers(b *strings.Builder, data DashboardData) {
+	if len(data.DraftMarkers.Markers) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## Not ready\n\n%d draft markers remain in the manuscript.\n\n", len(data.DraftMarkers.Markers))
+	for _, m := range data.DraftMarkers.Markers {
+		fmt.Fprintf(b, "- %s, %s %q: %s\n", m.Location, m.Kind, m.Marker, m.Context)
+	}
+	b.WriteString("\n")
+}
+
+func writeNumberStyle(b *strings.Builder, data DashboardData) {
+	if len(data.NumberStyle.Issues) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## Numbers, units and dates\n\nChecked against %s style.\n\n", numberStyleLabel(data.NumberStyle.StyleGuide))
+	for _, flag := range data.NumberStyle.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, issue := range data.NumberStyle.Issues {
+		fmt.Fprintf(b, "- %s, %q: %s\n", issue.Location, issue.Text, issue.Suggestion)
+	}
+	b.WriteString("\n")
+}
+
+// writePermissions lists quoted material for the contracts team, with enough
+// detail to request permission without opening the manuscript.
+func writePermissions(b *strings.Builder, data DashboardData) {
+	if len(data.Permissions.Items) == 0 {
+		return
+	}
+	b.WriteString("## Permissions\n\nQuoted material that may need permission from the rights holder.\n\n")
+	for _, flag := range data.Permissions.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, item := range data.Permissions.Items {
+		source := item.Attribution
+		if source == "" {
+			source = "source not given"
+		}
+		fmt.Fprintf(b, "- %s, %s (%s; %d words, %d lines) %q: %s\n", item.Location, item.Kind, source, item.Words, item.Lines, item.Excerpt, item.Note)
+	}
+	b.WriteString("\n")
+}
+
+func writeNonfiction(b *strings.Builder, data DashboardData) {
+	if data.Nonfiction == nil {
+		return
+	}
+	n := data.Nonfiction
+	fmt.Fprintf(b, "## Nonfiction checks\n\nManuscript type: %s.\n\n", n.ManuscriptType)
+	if len(n.RepeatedClaims)+len(n.HeadingIssues)+len(n.Placeholders) == 0 {
+		b.WriteString("No restated claims, outline problems or placeholders were found.\n\n")
+		return
+	}
+	if len(n.RepeatedClaims) > 0 {
+		b.WriteString("### Restated claims\n\n")
+		for _, c := range n.RepeatedClaims {
+			chapters := make([]string, 0, len(c.Chapters))
+			for _, ch := range c.Chapters {
+				chapters = append(chapters, strconv.Itoa(ch))
+			}
+			fmt.Fprintf(b, "- %q, %d times (chapters %s)\n", c.Text, c.Count, strings.Join(chapters, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(n.HeadingIssues) > 0 {
+		b.WriteString("### Headings\n\n")
+		for _, h := range n.HeadingIssues {
+			fmt.Fprintf(b, "- %s: %s\n", h.Heading, h.Problem)
+		}
+		b.WriteString("\n")
+	}
+	if len(n.Placeholders) > 0 {
+		b.WriteString("### Pre-submission blockers\n\n")
+		for _, p := range n.Placeholders {
+			fmt.Fprintf(b, "- %s, %s %q: %s\n", p.Location, strings.ReplaceAll(p.Kind, "_", " "), p.Marker, p.Context)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeLanguage(b *strings.Builder, data DashboardData) {
+	lang := data.Language
+	b.WriteString("## Language\n\n")
+	fmt.Fprintf(b, "- Spelling: %s\n", scoreInWords(lang.SpellingScore))
+	fmt.Fprintf(b, "- Grammar: %s\n", scoreInWords(lang.GrammarScore))
+	fmt.Fprintf(b, "- Readability: %s\n", scoreInWords(lang.ReadabilityScore))
+	if level := lang.ReadingLevel; level.Words > 0 {
+		fmt.Fprintf(b, "- Reading level: Flesch-Kincaid grade %.1f, Gunning Fog %.1f, SMOG %.1f\n", level.FleschKincaidGrade, level.GunningFog, level.SMOG)
+	}
+	if lang.SpellingProvider != "" {
+		fmt.Fprintf(b, "- Checked by: %s", lang.SpellingProvider)
+		if lang.Dialect != "" {
+			fmt.Fprintf(b, " (%s)", lang.Dialect)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "- Age rating: %s\n", lang.AgeCategory)
+	if len(lang.ContentWarnings) > 0 {
+		fmt.Fprintf(b, "- Content warnings: %s\n", strings.Join(lang.ContentWarnings, ", "))
+	}
+	b.WriteString("\n")
+
+	if len(lang.IssueBreakdown) > 0 {
+		b.WriteString("### Issues by category\n\n")
+		for _, c := range lang.IssueBreakdown {
+			fmt.Fprintf(b, "- %s: %d\n", c.Label, c.Count)
+		}
+		b.WriteString("\n")
+	}
+	attention := []string{}
+	for _, c := range lang.Chapters {
+		if c.NeedsAttention {
+			attention = append(attention, fmt.Sprintf("- Chapter %d, %s: spelling %d, grammar %d\n", c.Chapter, c.Title, c.SpellingScore, c.GrammarScore))
+		}
+	}
+	if len(attention) > 0 {
+		b.WriteString("### Chapters needing copyediting\n\n")
+		b.WriteString(strings.Join(attention, ""))
+		b.WriteString("\n")
+	}
+}
+
+// issuePageSuffix cites the PDF pages of an issue, " (p. 214)" or
+// " (pp. 40 and 214)", or is empty when it has none.
+func issuePageSuffix(issue HealthIssue) string {
+	switch {
+	case issue.PageA > 0 && issue.PageB > 0 && issue.PageA != issue.PageB:
+		return fmt.Sprintf(" (pp. %d and %d)", issue.PageA, issue.PageB)
+	case issue.PageA > 0:
+		return " (" + pageRef(issue.PageA, 0) + ")"
+	case issue.PageB > 0:
+		return " (" + pageRef(issue.PageB, 0) + ")"
+	}
+	return ""
+}
+
+func writeHealthIssues(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Consistency issues\n\n")
+	if len(data.HealthIssues) == 0 {
+		b.WriteString("No consistency issues found.\n\n")
+		return
+	}
+	for i, issue := range data.HealthIssues {
+		desc := issue.Description + issuePageSuffix(issue)
+		if issue.Kind == HealthIssueTenseDrift {
+			fmt.Fprintf(b, "%d. %s severity: %s\n   - Before: %s\n   - After: %s\n", i+1, issue.Severity, desc, issue.ContextA, issue.ContextB)
+			continue
+		}
+		if issue.Kind == HealthIssuePOVDrift {
+			fmt.Fprintf(b, "%d. %s severity: %s\n", i+1, issue.Severity, desc)
+			if issue.ContextA != "" {
+				fmt.Fprintf(b, "   - %s\n", issue.ContextA)
+			}
+			if issue.ContextB != "" {
+				fmt.Fprintf(b, "   - %s\n", issue.ContextB)
+			}
+			continue
+		}
+		if issue.Kind == HealthIssueFragment {
+			fmt.Fprintf(b, "%d. %s severity: %s\n   - %s\n", i+1, issue.Severity, desc, issue.ContextA)
+			continue
+		}
+		if issue.Kind == HealthIssueChapterNumbering {
+			fmt.Fprintf(b, "%d. %s severity: %s\n", i+1, issue.Severity, desc)
+			continue
+		}
+		if issue.Kind == HealthIssueSeriesContinuity {
+			fmt.Fprintf(b, "%d. %s severity: %s\n   - Earlier: %s\n", i+1, issue.Severity, desc, issue.ContextA)
+			continue
+		}
+		fmt.Fprintf(b, "%d. %s severity: %s (chapters %d and %d)\n", i+1, issue.Severity, desc, issue.ChapterA, issue.ChapterB)
+	}
+	b.WriteString("\n")
+}
+
+func writeAIDetection(b *strings.Builder, data DashboardData) {
+	b.WriteString("## AI likelihood\n\n")
+	if sectionDisabled(b, data, SectionAIDetection) {
+		return
+	}
+	report := data.AIReport
+	if report.PAIDoc == nil {
+		b.WriteString("No AI-likelihood estimate for this run.\n\n")
+		return
+	}
+	fmt.Fprintf(b, "- Whole manuscript: %s\n", percentInWords(*report.PAIDoc))
+	if report.AICoverageEst != nil {
+		fmt.Fprintf(b, "- Estimated share of text with AI-like signals: %s\n", percentInWords(*report.AICoverageEst))
+	}
+	if report.Sensitivity != "" {
+		fmt.Fprintf(b, "- Sensitivity preset: %s\n", report.Sensitivity)
+	}
+	if report.Weighting.Name != "" {
+		fmt.Fprintf(b, "- Signal weighting: %s\n", report.Weighting.Name)
+	}
+	if report.ExemptWindows > 0 {
+		fmt.Fprintf(b, "- Passages verified as human and left out of the estimate: %d\n", report.ExemptWindows)
+	}
+	for _, flag := range report.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	b.WriteString("\n")
+
+	writeAIChapters(b, report)
+
+	windows := append(report.Windows[:0:0], report.Windows...)
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].PAI > windows[j].PAI })
+	if len(windows) == 0 {
+		return
+	}
+	b.WriteString("### Highest-scoring passages\n\n")
+	for i, w := range windows {
+		if i == maxPlainReportWindows {
+			fmt.Fprintf(b, "%d more passages not listed.\n", len(windows)-maxPlainReportWindows)
+			break
+		}
+		if w.Exempt {
+			fmt.Fprintf(b, "%d. %s: %s (verified human, not counted)\n", i+1, aiWindowName(w), percentInWords(w.PAI))
+			continue
+		}
+		fmt.Fprintf(b, "%d. %s: %s\n", i+1, aiWindowName(w), percentInWords(w.PAI))
+	}
+	b.WriteString("\n")
+	writeAISentences(b, report)
+}
+
+// writeAISentences lists the sentences a Deep Dive run credited with the
+// most AI likelihood.
+func writeAISentences(b *strings.Builder, report aidetect.Report) {
+	if len(report.Sentences) == 0 {
+		return
+	}
+	b.WriteString("### Sentences carrying the signal\n\n")
+	for i, s := range report.Sentences {
+		if i == maxPlainReportWindows {
+			fmt.Fprintf(b, "%d more sentences not listed.\n", len(report.Sentences)-maxPlainReportWindows)
+			break
+		}
+		where := fmt.Sprintf("Words %d to %d", s.StartWord, s.EndWord)
+		if s.Location != nil {
+			where = s.Location.Label
+		}
+		fmt.Fprintf(b, "%d. %s: %q, %s (%s)\n", i+1, where, truncateRunes(s.Text, 160), percentInWords(s.PAI), strings.Join(s.Signals, ", "))
+	}
+	b.WriteString("\n")
+}
+
+// writeAIChapters lists scored chapters from most to least AI-like.
+func writeAIChapters(b *strings.Builder, report aidetect.Report) {
+	chapters := make([]aidetect.ChapterPAI, 0, len(report.PAIPerChapter))
+	for _, ch := range report.PAIPerChapter {
+		if ch.PAI != nil {
+			chapters = append(chapters, ch)
+		}
+	}
+	if len(chapters) == 0 {
+		return
+	}
+	sort.SliceStable(chapters, func(i, j int) bool { return *chapters[i].PAI > *chapters[j].PAI })
+	b.WriteString("### By chapter\n\n")
+	for i, ch := range chapters {
+		// Titles are the chapter's heading line, which names the chapter.
+		name := ch.Title
+		if name == "" {
+			name = fmt.Sprintf("Chapter %d", ch.Chapter)
+		}
+		fmt.Fprintf(b, "%d. %s: %s\n", i+1, name, percentInWords(*ch.PAI))
+	}
+	b.WriteString("\n")
+}
+
+func writeProseStatistics(b *strings.Builder, data DashboardData) {
+	prose := data.SlopReport
+	b.WriteString("## Prose statistics\n\n")
+	fmt.Fprintf(b, "- Average sentence length: %.1f words\n", prose.MeanSentenceLength)
+	fmt.Fprintf(b, "- Sentence length variation: %.1f words\n", prose.SentenceLengthSD)
+	for _, flag := range prose.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag.Text)
+	}
+	if n := data.Novelty; n.Available {
+		fmt.Fprintf(b, "- Novelty against %s: %d out of 100, where 50 is as predictable as the reference fiction\n", n.Model, n.Novelty)
+		for _, ch := range n.Chapters {
+			fmt.Fprintf(b, "  - Chapter %d, %s: novelty %d\n", ch.Chapter, ch.Title, ch.Novelty)
+		}
+	}
+	b.WriteString("\n")
+}
+
+func writeCrutchWords(b *strings.Builder, data DashboardData) {
+	report := data.CrutchWords
+	if len(report.Words) == 0 && len(report.Phrases) == 0 {
+		return
+	}
+	b.WriteString("## Crutch words\n\n")
+	fmt.Fprintf(b, "Baseline: %s.\n\n", report.Baseline)
+	for _, t := range report.Words {
+		fmt.Fprintf(b, "- %s\n", describeCrutchTerm(t))
+	}
+	for _, t := range report.Phrases {
+		fmt.Fprintf(b, "- Phrase %s\n", describeCrutchTerm(t))
+	}
+	b.WriteString("\n")
+}
+
+func writeGenre(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Genre\n\n")
+	if len(data.GenreScores) == 0 {
+		b.WriteString("No genre estimate for this run.\n\n")
+		return
+	}
+	scores := append(data.GenreScores[:0:0], data.GenreScores...)
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	for _, g := range topNGenres(scores, 3) {
+		fmt.Fprintf(b, "- %s: %s\n", g.Genre, percentInWords(g.Score))
+	}
+	b.WriteString("\n")
+}
+
+func writeStructure(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Plot structure\n\n")
+	if data.Anthology != nil || data.Nonfiction != nil {
+		fmt.Fprintf(b, "%s\n\n", data.PlotStructure.Reasoning)
+		return
+	}
+	if sectionDisabled(b, data, SectionPlotStructure) {
+		return
+	}
+	if data.PlotStructure.SelectedStructure != "" {
+		fmt.Fprintf(b, "Closest structure: %s.\n\n", data.PlotStructure.SelectedStructure)
+	}
+	if len(data.Beats) == 0 {
+		return
+	}
+	b.WriteString("### Beats\n\n")
+	for _, beat := range data.Beats {
+		found := "not found"
+		if beat.IsBeat {
+			found = fmt.Sprintf("chapters %d to %d", beat.StartChapter, beat.EndChapter)
+		}
+		fmt.Fprintf(b, "- %s: %s\n", beat.Name, found)
+	}
+	b.WriteString("\n")
+}
+
+func writeTimeline(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Timeline\n\n")
+	if len(data.Timeline) == 0 {
+		b.WriteString("No time markers found.\n\n")
+		return
+	}
+	for i, e := range data.Timeline {
+		fmt.Fprintf(b, "%d. %s: %s\n", i+1, e.TimeMarker, e.Event)
+	}
+	b.WriteString("\n")
+}
+
+func writeSeries(b *strings.Builder, data DashboardData) {
+	if data.Series == nil {
+		return
+	}
+	s := data.Series
+	fmt.Fprintf(b, "## Series: %s\n\n", s.Name)
+	fmt.Fprintf(b, "- Installment: %d\n", s.Installment)
+	if s.PriorInstallments == 0 {
+		b.WriteString("- First recorded installment; later installments are checked against it.\n\n")
+		return
+	}
+	fmt.Fprintf(b, "- Compared with: %d earlier installments (%d words)\n", s.PriorInstallments, s.PriorWords)
+	if len(s.ReturningCharacters) > 0 {
+		names := make([]string, 0, len(s.ReturningCharacters))
+		for _, c := range s.ReturningCharacters {
+			names = append(names, fmt.Sprintf("%s (since installment %d)", c.Name, c.FirstInstallment))
+		}
+		fmt.Fprintf(b, "- Returning characters: %s\n", strings.Join(names, ", "))
+	}
+	if len(s.NewCharacters) > 0 {
+		fmt.Fprintf(b, "- New characters: %s\n", strings.Join(s.NewCharacters, ", "))
+	}
+	for _, note := range s.Notes {
+		fmt.Fprintf(b, "- Note: %s\n", note)
+	}
+	b.WriteString("\n")
+}
+
+func writeChapters(b *strings.Builder, data DashboardData) {
+	b.WriteString("## Chapters\n\n")
+	summaries := map[int]string{}
+	for _, s := range data.ChapterSummaries {
+		summaries[s.Chapter] = s.Summary
+	}
+	for _, ch := range data.ChapterMetrics {
+		fmt.Fprintf(b, "### Chapter %d: %s\n\n", ch.Index, ch.Title)
+		fmt.Fprintf(b, "- Words: %d\n", ch.WordCount)
+		if ch.TopGenre != "" {
+			fmt.Fprintf(b, "- Leading genre: %s, %s\n", ch.TopGenre, percentInWords(ch.TopGenreScore))
+		}
+		if level := ch.ReadingLevel; level.Words > 0 {
+			fmt.Fprintf(b, "- Reading level: grade %.1f (Gunning Fog %.1f, SMOG %.1f)\n", level.FleschKincaidGrade, level.GunningFog, level.SMOG)
+		}
+		if summary := strings.TrimSpace(summaries[ch.Index]); summary != "" {
+			fmt.Fprintf(b, "- Summary: %s\n", summary)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeVoices(b *strings.Builder, data DashboardData) {
+	if len(data.Voice.Characters) == 0 {
+		return
+	}
+	b.WriteString("## Dialogue voices\n\n")
+	for _, flag := range data.Voice.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, c := range data.Voice.Characters {
+		fmt.Fprintf(b, "- %s: %d lines, drift %.2f, closest to %s (%.2f)\n", c.Name, c.Lines, c.Drift, c.NearestCharacter, c.NearestDistance)
+	}
+	b.WriteString("\n")
+}
+
+func writeNameHygiene(b *strings.Builder, data DashboardData) {
+	if len(data.NameHygiene.Issues) == 0 {
+		return
+	}
+	b.WriteString("## Name hygiene\n\n")
+	for _, issue := range data.NameHygiene.Issues {
+		fmt.Fprintf(b, "- %s Suggestion: %s\n", issue.Detail, issue.Suggestion)
+	}
+	b.WriteString("\n")
+}
+
+func writeTerminology(b *strings.Builder, data DashboardData) {
+	if len(data.Terminology.Issues) == 0 {
+		return
+	}
+	b.WriteString("## Terminology\n\n")
+	for _, issue := range data.Terminology.Issues {
+		fmt.Fprintf(b, "- %s\n", issue.Suggestion)
+	}
+	b.WriteString("\n")
+}
+
+func writeBookends(b *strings.Builder, data DashboardData) {
+	if len(data.Bookends.Openings) == 0 && len(data.Bookends.Closings) == 0 {
+		return
+	}
+	b.WriteString("## Chapter openings and endings\n\n")
+	for _, flag := range data.Bookends.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, p := range append(append([]BookendPattern{}, data.Bookends.Openings...), data.Bookends.Closings...) {
+		fmt.Fprintf(b, "- %s: %d chapters (%.0f%%), e.g. %s\n", p.Label, p.Count, p.Share*100, strings.Join(p.Examples, " / "))
+	}
+	b.WriteString("\n")
+}
+
+func writePacing(b *strings.Builder, data DashboardData) {
+	if len(data.Pacing.Chapters) == 0 {
+		return
+	}
+	b.WriteString("## Pacing\n\n")
+	for _, flag := range data.Pacing.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, c := range data.Pacing.Chapters {
+		fmt.Fprintf(b, "- Chapter %d, %s: tension %+.1f; %s dialogue, sentence-length variation %.2f, %.1f action verbs per 100 words\n", c.Chapter, c.Title, c.Tension, percentInWords(c.DialogueShare), c.SentenceVariation, c.ActionVerbsPer100)
+	}
+	b.WriteString("\n")
+}
+
+func writeManuscriptStats(b *strings.Builder, data DashboardData) {
+	if len(data.Stats.Chapters) == 0 {
+		return
+	}
+	b.WriteString("## Statistics\n\n")
+	fmt.Fprintf(b, "- Sentence words: %s\n", describeDistribution(data.Stats.Sentences))
+	fmt.Fprintf(b, "- Paragraph words: %s\n", describeDistribution(data.Stats.Paragraphs))
+	fmt.Fprintf(b, "- Chapter words: %s\n", describeDistribution(data.Stats.ChapterWords))
+	for _, c := range data.Stats.Chapters {
+		fmt.Fprintf(b, "- Chapter %d, %s: %s dialogue; per 100 words %.1f -ly adverbs, %.1f pronouns, %.1f -ing words, %.1f function words\n", c.Chapter, c.Title, percentInWords(c.DialogueRatio), c.AdverbsPer100, c.PronounsPer100, c.IngWordsPer100, c.FunctionWordsPer100)
+	}
+	b.WriteString("\n")
+}
+
+func writeSensitivity(b *strings.Builder, data DashboardData) {
+	if data.Sections[SectionSensitivity] != SectionStatusEnabled {
+		return
+	}
+	b.WriteString("## Sensitivity read\n\n")
+	b.WriteString(data.Sensitivity.Disclaimer + "\n\n")
+	if len(data.Sensitivity.Flags) == 0 {
+		b.WriteString("No passages flagged.\n\n")
+		return
+	}
+	for i, f := range data.Sensitivity.Flags {
+		fmt.Fprintf(b, "%d. Chapter %d, %s, in %s: %q. %s\n", i+1, f.Chapter, f.Category, f.Context, f.Quote, f.Rationale)
+	}
+	b.WriteString("\n")
+}
+
+func writeCompTitles(b *strings.Builder, data DashboardData) {
+	if len(data.CompTitles) == 0 {
+		return
+	}
+	b.WriteString("## Comparable titles\n\n")
+	for _, c := range data.CompTitles {
+		title := c.Title
+		if c.Author != "" {
+			title += " by " + c.Author
+		}
+		if c.Similarity > 0 {
+			fmt.Fprintf(b, "- %s (%s), similarity %.0f percent\n", title, c.Tier, c.Similarity*100)
+			continue
+		}
+		fmt.Fprintf(b, "- %s (%s)\n", title, c.Tier)
+	}
+	b.WriteString("\n")
+}
+
+// reportFile mirrors report.json: top-level summary fields plus the analysis
+// payload keyed by snake_case section names.
+type reportFile struct {
+	BookTitle string `json:"book_title"`
+	WordCount int    `json:"word_count"`
+	MHDScore  int    `json:"mhd_score"`
+	Analysis  struct {
+		ChapterCount        int                    `json:"chapter_count"`
+		RunStats            RunStats               `json:"run_stats"`
+		ScoreBreakdown      ScoreBreakdown         `json:"score_breakdown"`
+		System              SystemDiagnostics      `json:"system"`
+		HealthIssues        []HealthIssue          `json:"health_issues"`
+		Language            LanguageReport         `json:"language"`
+		Sensitivity         SensitivityReport      `json:"sensitivity"`
+		Novelty             NoveltyReport          `json:"novelty"`
+		Voice               VoiceReport            `json:"voice"`
+		NameHygiene         NameHygieneReport      `json:"name_hygiene"`
+		Terminology         TerminologyReport      `json:"terminology"`
+		Bookends            BookendReport          `json:"bookends"`
+		POV                 POVReport              `json:"pov"`
+		Pacing              PacingReport           `json:"pacing"`
+		Stats               ManuscriptStats        `json:"stats"`
+		CrutchWords         CrutchWordReport       `json:"crutch_words"`
+		GenreScores         []GenreScore           `json:"genre_scores"`
+		GenreProvider       string                 `json:"genre_provider"`
+		GenreReasoning      string                 `json:"genre_reasoning"`
+		GenreFallback       *FallbackReason        `json:"genre_fallback"`
+		ChapterMetrics      []ChapterMetric        `json:"chapter_metrics"`
+		ChapterSummaries    []ChapterSummary       `json:"chapter_summaries"`
+		CharacterDictionary []CharacterEntry       `json:"character_dictionary"`
+		Beats               []BeatResult           `json:"beats"`
+		PlotStructure       PlotStructureReport    `json:"plot_structure"`
+		CompTitles          []CompTitle            `json:"comp_titles"`
+		CompTitlesFallback  *FallbackReason        `json:"comp_titles_fallback"`
+		ProjectLocation     string                 `json:"project_location"`
+		Sections            map[string]string      `json:"sections"`
+		Series              *SeriesReport          `json:"series"`
+		Anthology           *AnthologyReport       `json:"anthology"`
+		ManuscriptType      string                 `json:"manuscript_type"`
+		DraftMarkers        DraftMarkerReport      `json:"draft_markers"`
+		Drafts              *DraftProvenanceReport `json:"drafts"`
+		NumberStyle         NumberStyleReport      `json:"number_style"`
+		Permissions         PermissionsReport      `json:"permissions"`
+		LanguageMix         LanguageMixReport      `json:"language_mix"`
+		Nonfiction          *NonfictionReport      `json:"nonfiction"`
+		ModelDrift          []ModelDrift           `json:"model_drift"`
+		Timeline            []timeline.Event       `json:"timeline"`
+		Annotations         []Annotation           `json:"annotations"`
+		QualityMetrics      gates.Metrics          `json:"quality_metrics"`
+		QualityGates        []gates.Result         `json:"quality_gates"`
+		Benchmarks          []Benchmark            `json:"benchmarks"`
+		AIReport            aidetect.Report        `json:"ai_report"`
+		SlopReport          slop.Report            `json:"slop_report"`
+	} `json:"analysis"`
+}
+
+// DashboardFromReport rebuilds the parts of DashboardData that report.json
+// persists, so a saved project can be rendered without re-running analysis.
+func DashboardFromReport(raw []byte) (DashboardData, error) {
+	var rf reportFile
+	if err := json.Unmarshal(raw, &rf); err != nil {
+		return DashboardData{}, fmt.Errorf("decode report: %w", err)
+	}
+	data := DashboardData{
+		BookTitle:           rf.BookTitle,
+		WordCount:           rf.WordCount,
+		MHDScore:            rf.MHDScore,
+		ChapterCount:        rf.Analysis.ChapterCount,
+		RunStats:            rf.Analysis.RunStats,
+		ScoreBreakdown:      rf.Analysis.ScoreBreakdown,
+		System:              rf.Analysis.System,
+		HealthIssues:        rf.Analysis.HealthIssues,
+		Language:            rf.Analysis.Language,
+		Sensitivity:         rf.Analysis.Sensitivity,
+		Novelty:             rf.Analysis.Novelty,
+		Voice:               rf.Analysis.Voice,
+		NameHygiene:         rf.Analysis.NameHygiene,
+		Terminology:         rf.Analysis.Terminology,
+		Bookends:            rf.Analysis.Bookends,
+		POV:                 rf.Analysis.POV,
+		Pacing:              rf.Analysis.Pacing,
+		Stats:               rf.Analysis.Stats,
+		CrutchWords:         rf.Analysis.CrutchWords,
+		GenreScores:         rf.Analysis.GenreScores,
+		GenreProvider:       rf.Analysis.GenreProvider,
+		GenreReasoning:      rf.Analysis.GenreReasoning,
+		GenreFallback:       rf.Analysis.GenreFallback,
+		ChapterMetrics:      rf.Analysis.ChapterMetrics,
+		ChapterSummaries:    rf.Analysis.ChapterSummaries,
+		CharacterDictionary: rf.Analysis.CharacterDictionary,
+		Beats:               rf.Analysis.Beats,
+		PlotStructure:       rf.Analysis.PlotStructure,
+		CompTitles:          rf.Analysis.CompTitles,
+		CompTitlesFallback:  rf.Analysis.CompTitlesFallback,
+		ProjectLocation:     rf.Analysis.ProjectLocation,
+		Sections:            rf.Analysis.Sections,
+		Series:              rf.Analysis.Series,
+		Anthology:           rf.Analysis.Anthology,
+		ManuscriptType:      rf.Analysis.ManuscriptType,
+		DraftMarkers:        rf.Analysis.DraftMarkers,
+		Drafts:              rf.Analysis.Drafts,
+		NumberStyle:         rf.Analysis.NumberStyle,
+		Permissions:         rf.Analysis.Permissions,
+		LanguageMix:         rf.Analysis.LanguageMix,
+		Nonfiction:          rf.Analysis.Nonfiction,
+		ModelDrift:          rf.Analysis.ModelDrift,
+		Timeline:            rf.Analysis.Timeline,
+		Annotations:         rf.Analysis.Annotations,
+		QualityMetrics:      rf.Analysis.QualityMetrics,
+		QualityGates:        rf.Analysis.QualityGates,
+		Benchmarks:          rf.Analysis.Benchmarks,
+		AIReport:            rf.Analysis.AIReport,
+		SlopReport:          rf.Analysis.SlopReport,
+	}
+	return data, nil
+}
+
+// OpenProjectReport loads a saved report.json, or the report.json inside a
+// project directory, and checks the project's source file against the
+// checksum the report recorded so findings from an earlier draft are flagged
+// rather than attributed to whatever is on disk now.
+func OpenProjectReport(path string) (DashboardData, error) {
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		path = filepath.Join(path, "report.json")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return DashboardData{}, fmt.Errorf("read report: %w", err)
+	}
+	data, err := DashboardFromReport(raw)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	check, err := workspace.VerifyReportSource(path)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	data.SourceIntegrity = sourceIntegrity(check)
+	return data, nil
+}
+
+func sourceIntegrity(check workspace.SourceCheck) *SourceIntegrity {
+	integrity := &SourceIntegrity{
+		Status:         check.Status,
+		SourcePath:     check.SourcePath,
+		RecordedSHA256: check.RecordedSHA256,
+		CurrentSHA256:  check.CurrentSHA256,
+	}
+	switch check.Status {
+	case workspace.SourceChanged:
+		integrity.Warning = fmt.Sprintf("%s changed since this report was produced; findings describe the earlier draft.", filepath.Base(check.SourcePath))
+	case workspace.SourceMissing:
+		integrity.Warning = fmt.Sprintf("%s is no longer in the project; findings cannot be checked against it.", filepath.Base(check.SourcePath))
+	}
+	return integrity
+}
+
+// writeDraftProvenance lists the chapters that changed most across drafts;
+// chapters revised in place are left to the dashboard's timeline.
+func writeDraftProvenance(b *strings.Builder, data DashboardData) {
+	if data.Drafts == nil {
+		return
+	}
+	d := data.Drafts
+	b.WriteString("## Draft history\n\n")
+	fmt.Fprintf(b, "- Drafts compared: %d\n", len(d.Drafts))
+	for _, flag := range d.Flags {
+		fmt.Fprintf(b, "- Flag: %s\n", flag)
+	}
+	for _, ch := range d.Chapters {
+		for _, p := range ch.Timeline {
+			if p.Replaced && !p.AISpike {
+				fmt.Fprintf(b, "- Chapter %d, %s: replaced in draft %d (similarity %.2f)\n", ch.Chapter, ch.Title, p.Draft, *p.Similarity)
+			}
+		}
+	}
+	b.WriteString("\n")
+}
+
+func writeReviewerNotes(b *strings.Builder, data DashboardData) {
+	if len(data.Annotations) == 0 {
+		return
+	}
+	b.WriteString("## Reviewer notes\n\n")
+	for _, a := range data.Annotations {
+		label := strings.TrimSpace(a.Label)
+		if label == "" {
+			label = "Note"
+		}
+		fmt.Fprintf(b, "- %s: %s", label, strings.TrimSpace(a.Note))
+		switch {
+		case a.FindingID != "":
+			fmt.Fprintf(b, " (finding %s)", a.FindingID)
+		case a.Anchor != "":
+			fmt.Fprintf(b, " (on %q)", a.Anchor)
+		}
+		if a.Orphaned {
+			b.WriteString(", no longer found in the text")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
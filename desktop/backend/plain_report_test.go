@@ -0,0 +1,171 @@
+package backend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/gates"
+	"book_dashboard/internal/timeline"
+)
+
+func plainReportFixture() DashboardData {
+	p := 0.42
+	return DashboardData{
+		BookTitle:    "The Long Road",
+		WordCount:    1200,
+		MHDScore:     68,
+		ChapterCount: 1,
+		HealthIssues: []HealthIssue{{ID: "issue-001", Severity: "High", Description: "Mara's eye colour changes", ChapterA: 1, ChapterB: 3}},
+		AIReport:     aidetect.Report{PAIDoc: &p, Windows: []aidetect.WindowReport{{WindowID: "w-001", StartWord: 0, EndWord: 300, PAI: 0.81}}},
+		Timeline:     []timeline.Event{{TimeMarker: "next day", Event: "Mara leaves."}},
+		Language: LanguageReport{
+			SpellingScore: 91, GrammarScore: 72, ReadabilityScore: 81, AgeCategory: "Teen 13+",
+			Chapters: []ChapterLanguageScore{{Chapter: 1, Title: "Start", SpellingScore: 90, GrammarScore: 60, NeedsAttention: true}},
+		},
+		ChapterMetrics: []ChapterMetric{{Index: 1, Title: "Start", WordCount: 1200, TopGenre: "Fantasy", TopGenreScore: 0.6}},
+		Sections:       map[string]string{SectionAIDetection: SectionStatusEnabled, SectionPlotStructure: SectionStatusDisabled},
+	}
+}
+
+func TestPlainReportSpellsOutScores(t *testing.T) {
+	report := PlainReport(plainReportFixture())
+	for _, want := range []string{
+		"# Manuscript Health Report: The Long Road",
+		"- Manuscript health score: 68 out of 100 (needs attention)",
+		"- Grammar: 72 out of 100 (fair)",
+		"1. High severity: Mara's eye colour changes (chapters 1 and 3)",
+		"- Whole manuscript: 42 percent",
+		"1. Words 0 to 300: 81 percent",
+		"- Chapter 1, Start: spelling 90, grammar 60",
+		"## Plot structure\n\nDisabled in project settings.",
+		"### Chapter 1: Start",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("plain report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestReportAnalysisReadsBackEveryKey(t *testing.T) {
+	value := 0.91
+	data := plainReportFixture()
+	data.System = SystemDiagnostics{Overall: "ready", Ollama: ServiceStatus{Name: "ollama", Ready: true}}
+	data.GenreProvider = "ollama"
+	data.GenreReasoning = "Ch1 (ollama): quest and magic"
+	data.Annotations = []Annotation{{ID: 1, Start: 4, End: 9, Anchor: "Mara", Label: "Editor", Note: "Check the name."}}
+	data.QualityMetrics = map[string]float64{"grammar_score": 72}
+	data.QualityGates = []gates.Result{{ID: "grammar", Metric: "grammar_score", Op: ">=", Threshold: 80, Value: &value, Passed: true}}
+	data.Benchmarks = []Benchmark{{Metric: "grammar_score", Label: "Grammar", Value: 72, Percentile: 40, Books: 12}}
+	data.Drafts = &DraftProvenanceReport{Drafts: []DraftRef{{Draft: 1, ProjectID: "p1"}}}
+
+	written := reportAnalysis(data)
+	raw, err := json.Marshal(map[string]any{"analysis": written})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := DashboardFromReport(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := reportAnalysis(loaded)
+	for key, want := range written {
+		wantJSON, _ := json.Marshal(want)
+		gotJSON, _ := json.Marshal(read[key])
+		if string(gotJSON) != string(wantJSON) {
+			t.Fatalf("report.json key %q did not round-trip:\nwrote %s\nread  %s", key, wantJSON, gotJSON)
+		}
+	}
+}
+
+func TestPlainReportRendersSubmissionChecklist(t *testing.T) {
+	data := plainReportFixture()
+	data.QualityGates = gates.Evaluate(gates.Metrics{gates.MetricHighContradictions: 1, gates.MetricGrammar: 72}, gates.DefaultRules())
+	report := PlainReport(data)
+	for _, want := range []string{
+		"## Submission checklist",
+		"- FAIL: No HIGH contradictions (measured 1; threshold == 0)",
+		"- SKIPPED: Document AI probability below 0.5 (not measured; threshold < 0.50)",
+		"- PASS: Grammar score at least 70 (measured 72; threshold >= 70)",
+		"At least one gate failed.",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("plain report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestPlainReportRendersBenchmarks(t *testing.T) {
+	data := plainReportFixture()
+	data.Benchmarks = []Benchmark{
+		{Metric: gates.MetricGrammar, Label: "Grammar score", Value: 72, Percentile: 40, Books: 12},
+		{Metric: benchmarkVerbatimCoverage, Label: "Repetition coverage", Value: 0.08, Percentile: 92, Books: 14},
+	}
+	report := PlainReport(data)
+	for _, want := range []string{
+		"## Benchmarks",
+		"- Grammar score: 72, higher than 40 percent of the 12 other books analyzed",
+		"- Repetition coverage: 8 percent, higher than 92 percent of the 14 other books analyzed",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("plain report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestPlainReportForAuthorHidesInternals(t *testing.T) {
+	data := plainReportFixture()
+	data.Annotations = []Annotation{{FindingID: "issue-001", Label: "Editor", Note: "Ask the author about chapter 3."}}
+	data.Benchmarks = []Benchmark{{}}
+
+	internal := PlainReportFor(data, ExportProfileInternal)
+	for _, want := range []string{"## AI likelihood", "## Reviewer notes", "- Editor: Ask the author about chapter 3. (finding issue-001)"} {
+		if !strings.Contains(internal, want) {
+			t.Fatalf("internal report missing %q:\n%s", want, internal)
+		}
+	}
+	author := PlainReportFor(data, ExportProfileAuthor)
+	for _, hidden := range []string{"AI likelihood", "Reviewer notes", "Ask the author"} {
+		if strings.Contains(author, hidden) {
+			t.Fatalf("author report shows %q:\n%s", hidden, author)
+		}
+	}
+	if !strings.Contains(author, "1. High severity: Mara's eye colour changes") {
+		t.Fatalf("author report dropped the consistency issues:\n%s", author)
+	}
+	if len(data.Annotations) != 1 || data.AIReport.PAIDoc == nil || len(data.Benchmarks) != 1 {
+		t.Fatal("expected the redaction to leave the dashboard's data untouched")
+	}
+	if _, err := ParseExportProfile("editor"); err == nil {
+		t.Fatal("expected an unknown export profile rejected")
+	}
+}
+
+func TestDashboardFromReportRoundTrip(t *testing.T) {
+	data := plainReportFixture()
+	raw, err := json.Marshal(map[string]any{
+		"book_title": data.BookTitle,
+		"word_count": data.WordCount,
+		"mhd_score":  data.MHDScore,
+		"analysis": map[string]any{
+			"chapter_count":   data.ChapterCount,
+			"health_issues":   data.HealthIssues,
+			"language":        data.Language,
+			"chapter_metrics": data.ChapterMetrics,
+			"timeline":        data.Timeline,
+			"ai_report":       data.AIReport,
+			"sections":        data.Sections,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := DashboardFromReport(raw)
+	if err != nil {
+		t.Fatalf("load report: %v", err)
+	}
+	if got, want := PlainReport(loaded), PlainReport(data); got != want {
+		t.Fatalf("expected identical plain report after round trip:\n%s\n---\n%s", got, want)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -44,6 +43,8 @@ type PlotInputs struct {
 	GenreScores      []GenreScore
 	GenreProvider    string
 	GenreReasoning   string
+	// Offline keeps to the heuristic beats without asking the model.
+	Offline bool
 }
 
 func analyzePlotStructure(in PlotInputs) ([]BeatResult, PlotStructureReport) {
@@ -62,18 +63,13 @@ func analyzePlotStructure(in PlotInputs) ([]BeatResult, PlotStructureReport) {
 	if len(in.Chapters) == 0 {
 		return fallbackBeats, fallback
 	}
-
-	model := strings.TrimSpace(os.Getenv("OLLAMA_STRUCTURE_MODEL"))
-	if model == "" {
-		model = strings.TrimSpace(os.Getenv("OLLAMA_GENRE_MODEL"))
-	}
-	if model == "" {
-		model = strings.TrimSpace(os.Getenv("OLLAMA_LANGUAGE_MODEL"))
-	}
-	if model == "" {
-		model = "llama3.1:8b"
+	if in.Offline {
+		fallback.Reasoning = "Heuristic beats from chapter-position windows; the quick scan does not consult the model."
+		return fallbackBeats, fallback
 	}
 
+	model := ollamaModel("OLLAMA_STRUCTURE_MODEL", "OLLAMA_GENRE_MODEL", "OLLAMA_LANGUAGE_MODEL")
+
 	client := &http.Client{Timeout: 120 * time.Second}
 	prompt := buildPlotPrompt(in)
 	payload := map[string]any{
@@ -87,29 +83,35 @@ func analyzePlotStructure(in PlotInputs) ([]BeatResult, PlotStructureReport) {
 	resp, err := client.Post(ollamaGenerateEndpoint(), "application/json", bytes.NewReader(raw))
 	if err != nil {
 		fallback.Reasoning += " Ollama unavailable: " + err.Error()
+		fallback.Fallback = newFallbackReason(ServiceOllama, model, err)
 		return fallbackBeats, fallback
 	}
 	body, _ := io.ReadAll(resp.Body)
 	_ = resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		fallback.Reasoning += fmt.Sprintf(" Ollama status=%d.", resp.StatusCode)
+		fallback.Fallback = newFallbackReason(ServiceOllama, model, statusError(resp.StatusCode, body))
 		return fallbackBeats, fallback
 	}
 
 	var out ollamaGenreResponse
 	if err := json.Unmarshal(body, &out); err != nil {
 		fallback.Reasoning += " Ollama decode failed: " + err.Error()
+		fallback.Fallback = newFallbackReason(ServiceOllama, model, err)
 		return fallbackBeats, fallback
 	}
+	noteInference(model, out.EvalCount, out.EvalDuration)
 	jsonText := extractJSONObject(out.Response)
 	if jsonText == "" {
 		fallback.Reasoning += " No JSON in response."
+		fallback.Fallback = newFallbackReason(ServiceOllama, model, errNoJSON)
 		return fallbackBeats, fallback
 	}
 
 	var parsed plotLLMResult
 	if err := json.Unmarshal([]byte(jsonText), &parsed); err != nil {
 		fallback.Reasoning += " JSON parse failed: " + err.Error()
+		fallback.Fallback = newFallbackReason(ServiceOllama, model, err)
 		return fallbackBeats, fallback
 	}
 
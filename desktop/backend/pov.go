@@ -0,0 +1,265 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	HealthIssuePOVDrift = "pov_drift"
+
+	povFirst   = "first"
+	povThird   = "third"
+	povMixed   = "mixed"
+	povUnknown = "unknown"
+
+	// A chapter's point of view is judged once its narration has
+	// minPOVPronouns personal pronouns. It is first person when at least
+	// povFirstShare of them are first person, third person when at most
+	// povThirdShare are, and mixed in between.
+	minPOVPronouns = 12
+	povFirstShare  = 0.3
+	povThirdShare  = 0.05
+	// A minority point of view used in more than povAlternatingShare of the
+	// judged chapters is read as a deliberate alternating structure, and its
+	// chapters are reported at LOW severity.
+	povAlternatingShare = 0.25
+	// A thought attributed to a different character within headHopWindow
+	// sentences of the last one is a hop; a scene with headHopSwitches hops is
+	// head-hopping.
+	headHopWindow   = 4
+	headHopSwitches = 2
+)
+
+var firstPersonPronouns = wordSet("i", "me", "my", "mine", "myself", "we", "us", "our", "ours", "ourselves")
+var thirdPersonPronouns = wordSet("he", "him", "his", "himself", "she", "her", "hers", "herself", "they", "them", "their", "theirs", "themselves")
+var thoughtSubjectPattern = regexp.MustCompile(`\b([A-Z][a-z]+|I)\s+(?:(?:had|could|would|suddenly|silently|briefly)\s+)?(thought|wondered|realized|realised|knew|wished|hoped|feared|worried|remembered|suspected|decided|felt)\b`)
+
+// POVReport is the narration's point of view per chapter and the book's
+// dominant one.
+type POVReport struct {
+	POV      string       `json:"pov"`
+	Chapters []ChapterPOV `json:"chapters"`
+}
+
+// ChapterPOV counts a chapter's first- and third-person narration pronouns
+// (dialogue excluded) and the characters whose thoughts the narration
+// reports. HeadHops counts rapid switches between those characters within a
+// scene.
+type ChapterPOV struct {
+	Chapter         int      `json:"chapter"`
+	Title           string   `json:"title"`
+	POV             string   `json:"pov"`
+	FirstPerson     int      `json:"firstPerson"`
+	ThirdPerson     int      `json:"thirdPerson"`
+	ThoughtSubjects []string `json:"thoughtSubjects"`
+	HeadHops        int      `json:"headHops"`
+}
+
+// headHop is a scene whose narration switches between characters' thoughts.
+type headHop struct {
+	chapter  int
+	scene    int
+	subjects []string
+	hops     int
+	before   string
+	after    string
+}
+
+func emptyPOVReport() POVReport {
+	return POVReport{POV: povUnknown, Chapters: []ChapterPOV{}}
+}
+
+// analyzePOV classifies each chapter's point of view and finds the scenes
+// that hop between characters' heads.
+func analyzePOV(chapters []chapter) (POVReport, []headHop) {
+	report := emptyPOVReport()
+	hops := []headHop{}
+	counts := map[string]int{}
+	for _, ch := range chapters {
+		cp := ChapterPOV{Chapter: ch.index, Title: ch.title, ThoughtSubjects: []string{}}
+		seen := map[string]bool{}
+		for sceneIdx, scene := range chapterScenes(ch.text) {
+			sentences := []string{}
+			for _, p := range scene {
+				sentences = append(sentences, splitSentences(narrationOnly(p))...)
+			}
+			for _, s := range sentences {
+				first, third := personCounts(s)
+				cp.FirstPerson += first
+				cp.ThirdPerson += third
+			}
+			hop := sceneHeadHops(sentences)
+			for _, subject := range hop.subjects {
+				if !seen[subject] {
+					seen[subject] = true
+					cp.ThoughtSubjects = append(cp.ThoughtSubjects, subject)
+				}
+			}
+			if hop.hops >= headHopSwitches {
+				hop.chapter, hop.scene = ch.index, sceneIdx+1
+				cp.HeadHops += hop.hops
+				hops = append(hops, hop)
+			}
+		}
+		cp.POV = classifyPOV(cp.FirstPerson, cp.ThirdPerson)
+		if cp.POV == povFirst || cp.POV == povThird {
+			counts[cp.POV]++
+		}
+		report.Chapters = append(report.Chapters, cp)
+	}
+	switch {
+	case counts[povFirst] > counts[povThird]:
+		report.POV = povFirst
+	case counts[povThird] > 0:
+		report.POV = povThird
+	}
+	return report, hops
+}
+
+func classifyPOV(first, third int) string {
+	total := first + third
+	if total < minPOVPronouns {
+		return povUnknown
+	}
+	share := float64(first) / float64(total)
+	switch {
+	case share >= povFirstShare:
+		return povFirst
+	case share <= povThirdShare:
+		return povThird
+	}
+	return povMixed
+}
+
+func personCounts(sentence string) (first, third int) {
+	for _, w := range tenseTokenPattern.FindAllString(sentence, -1) {
+		switch lw := strings.ToLower(w); {
+		case firstPersonPronouns[lw]:
+			// "I" is always the pronoun; "US" in capitals is likelier a name.
+			if w != "US" {
+				first++
+			}
+		case thirdPersonPronouns[lw]:
+			third++
+		}
+	}
+	return first, third
+}
+
+// sceneHeadHops follows whose thoughts each sentence reports. Only named
+// thinkers (and "I") count, since a bare "he" or "she" could be either.
+func sceneHeadHops(sentences []string) headHop {
+	hop := headHop{subjects: []string{}}
+	last, lastAt := "", -headHopWindow-1
+	seen := map[string]bool{}
+	for i, s := range sentences {
+		m := thoughtSubjectPattern.FindStringSubmatch(s)
+		if m == nil || (m[1] != "I" && capitalizedNonSubjects[m[1]]) {
+			continue
+		}
+		subject := m[1]
+		if !seen[subject] {
+			seen[subject] = true
+			hop.subjects = append(hop.subjects, subject)
+		}
+		if last != "" && subject != last && i-lastAt <= headHopWindow {
+			if hop.hops == 0 {
+				hop.before, hop.after = sentences[lastAt], s
+			}
+			hop.hops++
+		}
+		last, lastAt = subject, i
+	}
+	return hop
+}
+
+// povIssues reports chapters narrated in a different person from the book,
+// chapters that mix persons, and head-hopping scenes, numbered after the
+// existing issues. compareChapters is false for story collections, whose
+// stories may each pick their own point of view.
+func povIssues(report POVReport, hops []headHop, chapters []chapter, compareChapters bool, existing int) []HealthIssue {
+	out := []HealthIssue{}
+	add := func(issue HealthIssue) {
+		issue.ID = fmt.Sprintf("issue-%03d", existing+len(out)+1)
+		issue.Kind = HealthIssuePOVDrift
+		out = append(out, issue)
+	}
+	if compareChapters && (report.POV == povFirst || report.POV == povThird) {
+		judged, minority := 0, 0
+		for _, cp := range report.Chapters {
+			if cp.POV != povUnknown {
+				judged++
+			}
+			if cp.POV != povUnknown && cp.POV != povMixed && cp.POV != report.POV {
+				minority++
+			}
+		}
+		alternating := judged > 0 && float64(minority)/float64(judged) > povAlternatingShare
+		texts := map[int]string{}
+		for _, ch := range chapters {
+			texts[ch.index] = ch.text
+		}
+		for _, cp := range report.Chapters {
+			if cp.POV == povUnknown || cp.POV == report.POV {
+				continue
+			}
+			issue := HealthIssue{
+				Entity:   "Point of view",
+				Severity: "MED",
+				ChapterA: cp.Chapter,
+				ChapterB: cp.Chapter,
+				ContextA: firstWords(povEvidence(texts[cp.Chapter], otherPOV(report.POV)), 40),
+			}
+			if cp.POV == povMixed {
+				issue.Description = fmt.Sprintf("Ch%d mixes first- and third-person narration in a %s-person book (%d first-person, %d third-person pronouns)", cp.Chapter, report.POV, cp.FirstPerson, cp.ThirdPerson)
+			} else {
+				issue.Description = fmt.Sprintf("Ch%d is narrated in the %s person; the book is mostly %s person", cp.Chapter, cp.POV, report.POV)
+				if alternating {
+					issue.Severity = "LOW"
+					issue.Description += ", so the switch may be a deliberate alternating structure"
+				}
+			}
+			add(issue)
+		}
+	}
+	for _, h := range hops {
+		names := append([]string{}, h.subjects...)
+		sort.Strings(names)
+		add(HealthIssue{
+			Entity:      "Point of view",
+			Severity:    "MED",
+			Description: fmt.Sprintf("Point of view hops between %s %d times within scene %d of Ch%d", strings.Join(names, ", "), h.hops, h.scene, h.chapter),
+			ChapterA:    h.chapter,
+			ChapterB:    h.chapter,
+			ContextA:    firstWords(h.before, 40),
+			ContextB:    firstWords(h.after, 40),
+		})
+	}
+	return out
+}
+
+func otherPOV(pov string) string {
+	if pov == povFirst {
+		return povThird
+	}
+	return povFirst
+}
+
+// povEvidence is the chapter's first narration sentence written in pov, the
+// person the chapter slipped into: for first person, one with a first-person
+// pronoun; for third person, one with a third-person pronoun and none in the
+// first person.
+func povEvidence(text, pov string) string {
+	for _, p := range chapterParagraphs(text) {
+		for _, s := range splitSentences(narrationOnly(p)) {
+			first, third := personCounts(s)
+			if (pov == povFirst && first > 0) || (pov == povThird && third > 0 && first == 0) {
+				return s
+			}
+		}
+	}
+	return ""
+}
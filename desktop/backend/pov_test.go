@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	thirdPersonChapter = "Mara walked to the harbor. She was tired and her feet hurt. The gulls circled over her head. Tomas waited for her by his boat. He lifted the rope and handed it to her. She took it from him. They pushed off together. Their oars dipped. He smiled at her. She looked away from him."
+	firstPersonChapter = "I walked to the harbor. My feet hurt and I was tired. The gulls circled over my head. Tomas waited for me by his boat. He lifted the rope and handed it to me. I took it from him. We pushed off together. Our oars dipped. He smiled at me. I looked away."
+)
+
+func TestAnalyzePOVFlagsChapterInOtherPerson(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: thirdPersonChapter},
+		{index: 2, text: thirdPersonChapter},
+		{index: 3, text: thirdPersonChapter},
+		{index: 4, text: thirdPersonChapter},
+		{index: 5, text: firstPersonChapter},
+	}
+	report, hops := analyzePOV(chapters)
+	if report.POV != povThird || len(hops) != 0 {
+		t.Fatalf("expected a third-person book with no head-hopping, got %+v %+v", report, hops)
+	}
+	if got := report.Chapters[4]; got.POV != povFirst || got.FirstPerson == 0 {
+		t.Fatalf("expected chapter 5 in the first person, got %+v", got)
+	}
+
+	issues := povIssues(report, hops, chapters, true, 1)
+	if len(issues) != 1 {
+		t.Fatalf("expected one POV issue, got %+v", issues)
+	}
+	issue := issues[0]
+	if issue.ID != "issue-002" || issue.Kind != HealthIssuePOVDrift || issue.Severity != "MED" || issue.ChapterA != 5 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if !strings.HasPrefix(issue.ContextA, "I walked to the harbor") {
+		t.Fatalf("expected the first first-person sentence as evidence, got %q", issue.ContextA)
+	}
+	if got := povIssues(report, hops, chapters, false, 0); len(got) != 0 {
+		t.Fatalf("expected story collections not compared across chapters, got %+v", got)
+	}
+}
+
+func TestAnalyzePOVDetectsHeadHopping(t *testing.T) {
+	scene := thirdPersonChapter + "\nMara thought the sea looked calm. Tomas wondered whether she was angry. Mara felt the wind change. Tomas knew a storm was coming."
+	report, hops := analyzePOV([]chapter{{index: 2, text: scene + "\n* * *\n" + thirdPersonChapter}})
+	if len(hops) != 1 {
+		t.Fatalf("expected one head-hopping scene, got %+v", hops)
+	}
+	h := hops[0]
+	if h.chapter != 2 || h.scene != 1 || h.hops != 3 {
+		t.Fatalf("unexpected hop %+v", h)
+	}
+	if !strings.HasPrefix(h.before, "Mara thought") || !strings.HasPrefix(h.after, "Tomas wondered") {
+		t.Fatalf("unexpected evidence %+v", h)
+	}
+	if got := report.Chapters[0]; got.HeadHops != 3 || strings.Join(got.ThoughtSubjects, ",") != "Mara,Tomas" {
+		t.Fatalf("unexpected chapter POV %+v", got)
+	}
+	issues := povIssues(report, hops, nil, true, 0)
+	if len(issues) != 1 || !strings.Contains(issues[0].Description, "hops between Mara, Tomas 3 times within scene 1 of Ch2") {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+}
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/timeline"
+	"book_dashboard/internal/workspace"
+)
+
+// Profanity masking is an output option for publishers that need clean
+// reports: the exports quote profanity and explicit terms with their inner
+// letters dashed out (f--k), while every count, score and location stays as
+// analyzed. Masking keeps a word's length, so word and character offsets into
+// a masked text still line up.
+
+// maskedWords matches the profanity and sexual-content lexicons of the age
+// rating, with common inflections, and any word built on the two strongest
+// (motherfucker, bullshit).
+var maskedWords = func() *regexp.Regexp {
+	terms := []string{}
+	for _, dim := range []string{RatingLanguage, RatingSex} {
+		for term := range contentLexicons[dim] {
+			terms = append(terms, regexp.QuoteMeta(term))
+		}
+	}
+	sort.Strings(terms)
+	return regexp.MustCompile(`(?i)\b(?:[a-z]*(?:fuck|shit)[a-z]*|(?:` + strings.Join(terms, "|") + `)(?:s|es|ed|ing|er|ers|y)?)\b`)
+}()
+
+// MaskProfanityEnabled reports whether the project's exports mask profanity.
+func MaskProfanityEnabled(projectLocation string) bool {
+	if strings.TrimSpace(projectLocation) == "" {
+		return false
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	return err == nil && settings.MaskProfanity
+}
+
+// SetMaskProfanity stores whether the project's exports mask profanity; it
+// applies to the next export, without a re-run.
+func SetMaskProfanity(projectLocation string, enabled bool) (bool, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return false, fmt.Errorf("no project loaded")
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return false, err
+	}
+	settings.MaskProfanity = enabled
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return false, err
+	}
+	return settings.MaskProfanity, nil
+}
+
+// MaskProfanityText dashes out the inner letters of every masked word in s,
+// keeping its first and last letter and its length.
+func MaskProfanityText(s string) string {
+	return maskedWords.ReplaceAllStringFunc(s, func(w string) string {
+		if len(w) < 3 {
+			return w
+		}
+		return w[:1] + strings.Repeat("-", len(w)-2) + w[len(w)-1:]
+	})
+}
+
+// MaskProfanity returns data with profanity masked in every quoted passage,
+// evidence term and excerpt. The dashboard's own copy is left untouched.
+func MaskProfanity(data DashboardData) DashboardData {
+	out := data
+	mask := MaskProfanityText
+	maskAll := func(in []string) []string {
+		if in == nil {
+			return nil
+		}
+		masked := make([]string, len(in))
+		for i, s := range in {
+			masked[i] = mask(s)
+		}
+		return masked
+	}
+
+	out.HealthIssues = append([]HealthIssue(nil), data.HealthIssues...)
+	for i := range out.HealthIssues {
+		issue := &out.HealthIssues[i]
+		issue.Description, issue.ContextA, issue.ContextB = mask(issue.Description), mask(issue.ContextA), mask(issue.ContextB)
+	}
+	out.Timeline = append([]timeline.Event(nil), data.Timeline...)
+	for i := range out.Timeline {
+		out.Timeline[i].Event = mask(out.Timeline[i].Event)
+	}
+	out.ChapterSummaries = append([]ChapterSummary(nil), data.ChapterSummaries...)
+	for i := range out.ChapterSummaries {
+		out.ChapterSummaries[i].Summary = mask(out.ChapterSummaries[i].Summary)
+		out.ChapterSummaries[i].Events = maskAll(out.ChapterSummaries[i].Events)
+	}
+	out.CharacterDictionary = append([]CharacterEntry(nil), data.CharacterDictionary...)
+	for i := range out.CharacterDictionary {
+		out.CharacterDictionary[i].Description = mask(out.CharacterDictionary[i].Description)
+	}
+
+	out.Language.AgeRating.Dimensions = append([]RatingDimension(nil), data.Language.AgeRating.Dimensions...)
+	for i := range out.Language.AgeRating.Dimensions {
+		dim := &out.Language.AgeRating.Dimensions[i]
+		dim.Evidence = append([]RatingEvidence(nil), dim.Evidence...)
+		for j := range dim.Evidence {
+			dim.Evidence[j].Term, dim.Evidence[j].Quote = mask(dim.Evidence[j].Term), mask(dim.Evidence[j].Quote)
+		}
+	}
+	out.Sensitivity.Flags = append([]SensitivityFlag(nil), data.Sensitivity.Flags...)
+	for i := range out.Sensitivity.Flags {
+		f := &out.Sensitivity.Flags[i]
+		f.Term, f.Quote, f.Context = mask(f.Term), mask(f.Quote), mask(f.Context)
+	}
+
+	out.Bookends.Openings = maskBookendExamples(data.Bookends.Openings)
+	out.Bookends.Closings = maskBookendExamples(data.Bookends.Closings)
+	out.CrutchWords.Words = maskCrutchTerms(data.CrutchWords.Words)
+	out.CrutchWords.Phrases = maskCrutchTerms(data.CrutchWords.Phrases)
+	out.DraftMarkers.Markers = append([]DraftMarker(nil), data.DraftMarkers.Markers...)
+	for i := range out.DraftMarkers.Markers {
+		out.DraftMarkers.Markers[i].Context = mask(out.DraftMarkers.Markers[i].Context)
+	}
+	out.NumberStyle.Issues = append([]NumberStyleIssue(nil), data.NumberStyle.Issues...)
+	for i := range out.NumberStyle.Issues {
+		out.NumberStyle.Issues[i].Context = mask(out.NumberStyle.Issues[i].Context)
+	}
+	out.Permissions.Items = append([]PermissionItem(nil), data.Permissions.Items...)
+	for i := range out.Permissions.Items {
+		out.Permissions.Items[i].Excerpt = mask(out.Permissions.Items[i].Excerpt)
+	}
+	out.LanguageMix.Passages = append([]ForeignPassage(nil), data.LanguageMix.Passages...)
+	for i := range out.LanguageMix.Passages {
+		out.LanguageMix.Passages[i].Excerpt = mask(out.LanguageMix.Passages[i].Excerpt)
+	}
+	if data.Nonfiction != nil {
+		nonfiction := *data.Nonfiction
+		nonfiction.RepeatedClaims = append([]RepeatedClaim(nil), data.Nonfiction.RepeatedClaims...)
+		for i := range nonfiction.RepeatedClaims {
+			nonfiction.RepeatedClaims[i].Text = mask(nonfiction.RepeatedClaims[i].Text)
+		}
+		nonfiction.Placeholders = append([]Placeholder(nil), data.Nonfiction.Placeholders...)
+		for i := range nonfiction.Placeholders {
+			nonfiction.Placeholders[i].Context = mask(nonfiction.Placeholders[i].Context)
+		}
+		out.Nonfiction = &nonfiction
+	}
+	// Masking keeps the length, so the anchor still matches its offsets.
+	out.Annotations = append([]Annotation(nil), data.Annotations...)
+	for i := range out.Annotations {
+		out.Annotations[i].Anchor = mask(out.Annotations[i].Anchor)
+	}
+	out.AIReport.Sentences = append([]aidetect.SentenceAttribution(nil), data.AIReport.Sentences...)
+	for i := range out.AIReport.Sentences {
+		out.AIReport.Sentences[i].Text = mask(out.AIReport.Sentences[i].Text)
+	}
+	return out
+}
+
+func maskBookendExamples(patterns []BookendPattern) []BookendPattern {
+	out := append([]BookendPattern(nil), patterns...)
+	for i := range out {
+		examples := make([]string, len(out[i].Examples))
+		for j, e := range out[i].Examples {
+			examples[j] = MaskProfanityText(e)
+		}
+		out[i].Examples = examples
+	}
+	return out
+}
+
+func maskCrutchTerms(terms []CrutchTerm) []CrutchTerm {
+	out := append([]CrutchTerm(nil), terms...)
+	for i := range out {
+		out[i].Term = MaskProfanityText(out[i].Term)
+	}
+	return out
+}
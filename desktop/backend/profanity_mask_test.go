@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskProfanityTextKeepsLengthAndCleanWords(t *testing.T) {
+	in := "What the fuck, Sam? That's bullshit. Damned if I know. Fuckers. Sussex is naked."
+	got := MaskProfanityText(in)
+	want := "What the f--k, Sam? That's b------t. D----d if I know. F-----s. Sussex is n---d."
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if len(got) != len(in) {
+		t.Fatalf("masking changed the length: %d -> %d", len(in), len(got))
+	}
+}
+
+func TestMaskProfanityCopiesDataAndKeepsCounts(t *testing.T) {
+	data := DashboardData{
+		HealthIssues: []HealthIssue{{ID: "issue-001", ContextA: "He said shit twice.", ChapterA: 3}},
+		Language: LanguageReport{ProfanityInstances: 4, AgeRating: AgeRating{Dimensions: []RatingDimension{{
+			Dimension: RatingLanguage, Instances: 4, Chapters: []int{3},
+			Evidence: []RatingEvidence{{Chapter: 3, Term: "fuck", Quote: "Fuck this, she said."}},
+		}}}},
+		Sensitivity: SensitivityReport{Flags: []SensitivityFlag{{Chapter: 2, Quote: "the bitch is back"}}},
+		Bookends:    BookendReport{Openings: []BookendPattern{{Count: 2, Examples: []string{"Damn the rain."}}}},
+		Nonfiction:  &NonfictionReport{Placeholders: []Placeholder{{Kind: "citation", Location: "Chapter 1", Marker: "[CITE]", Context: "Fuck the data [CITE]."}}},
+		Annotations: []Annotation{{Label: "Editor", Note: "Tone down.", Anchor: "that shit"}},
+	}
+	masked := MaskProfanity(data)
+
+	if got := masked.HealthIssues[0].ContextA; got != "He said s--t twice." {
+		t.Fatalf("health issue passage not masked: %q", got)
+	}
+	evidence := masked.Language.AgeRating.Dimensions[0].Evidence[0]
+	if evidence.Term != "f--k" || evidence.Quote != "F--k this, she said." || evidence.Chapter != 3 {
+		t.Fatalf("rating evidence not masked in place: %+v", evidence)
+	}
+	if masked.Language.ProfanityInstances != 4 || masked.Language.AgeRating.Dimensions[0].Instances != 4 || masked.HealthIssues[0].ChapterA != 3 {
+		t.Fatalf("masking changed counts or locations: %+v", masked.Language)
+	}
+	if got := masked.Sensitivity.Flags[0].Quote; got != "the b---h is back" {
+		t.Fatalf("sensitivity quote not masked: %q", got)
+	}
+	if got := masked.Bookends.Openings[0].Examples[0]; got != "D--n the rain." {
+		t.Fatalf("bookend example not masked: %q", got)
+	}
+	if got := masked.Nonfiction.Placeholders[0].Context; got != "F--k the data [CITE]." {
+		t.Fatalf("nonfiction placeholder context not masked: %q", got)
+	}
+	if got := masked.Annotations[0].Anchor; got != "that s--t" {
+		t.Fatalf("annotation anchor not masked: %q", got)
+	}
+	if data.Nonfiction.Placeholders[0].Context != "Fuck the data [CITE]." || data.Annotations[0].Anchor != "that shit" {
+		t.Fatal("masking changed the dashboard's nonfiction or annotations")
+	}
+	if data.HealthIssues[0].ContextA != "He said shit twice." || data.Language.AgeRating.Dimensions[0].Evidence[0].Term != "fuck" || data.Bookends.Openings[0].Examples[0] != "Damn the rain." {
+		t.Fatal("masking changed the dashboard's own copy")
+	}
+	if report := PlainReport(masked); strings.Contains(strings.ToLower(report), "fuck") || strings.Contains(report, "shit") || !strings.Contains(report, "F--k the data") || !strings.Contains(report, `"that s--t"`) {
+		t.Fatalf("plain report still quotes profanity:\n%s", report)
+	}
+}
+
+func TestSetMaskProfanityPersists(t *testing.T) {
+	root := t.TempDir()
+	if MaskProfanityEnabled(root) {
+		t.Fatal("expected masking off by default")
+	}
+	if _, err := SetMaskProfanity(root, true); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if !MaskProfanityEnabled(root) {
+		t.Fatal("expected masking on after SetMaskProfanity")
+	}
+	if _, err := SetMaskProfanity("", true); err == nil {
+		t.Fatal("expected an error without a project")
+	}
+}
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/workspace"
+)
+
+// Analysis profiles trade run time for depth. Quick Scan stays offline and
+// uses heuristics only; Standard follows the project settings; Deep Dive
+// runs every model pass, including the opt-in ones, with embeddings and
+// per-sentence AI attribution.
+const (
+	ProfileQuick    = "quick"
+	ProfileStandard = "standard"
+	ProfileDeep     = "deep"
+)
+
+var AnalysisProfiles = []string{ProfileQuick, ProfileStandard, ProfileDeep}
+
+const (
+	// deepSentenceMinPAI is the window likelihood from which Deep Dive
+	// attributes the window's score to its sentences.
+	deepSentenceMinPAI = 0.5
+	// maxAttributedSentences caps the sentences kept in the AI report.
+	maxAttributedSentences = 200
+)
+
+type analysisProfileKey struct{}
+
+// NormalizeAnalysisProfile maps a profile name or its display name ("Quick
+// Scan", "deep-dive") to a profile; unknown names are standard.
+func NormalizeAnalysisProfile(name string) string {
+	switch profileKey(name) {
+	case "quick", "quickscan":
+		return ProfileQuick
+	case "deep", "deepdive":
+		return ProfileDeep
+	default:
+		return ProfileStandard
+	}
+}
+
+// IsAnalysisProfile reports whether name is one of the analysis profiles.
+func IsAnalysisProfile(name string) bool {
+	switch profileKey(name) {
+	case "quick", "quickscan", "standard", "deep", "deepdive":
+		return true
+	}
+	return false
+}
+
+func profileKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == ' ' || r == '-' || r == '_' }), "")
+}
+
+// WithAnalysisProfile selects the analysis profile for the run using ctx. An
+// empty or unknown profile runs Standard.
+func WithAnalysisProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, analysisProfileKey{}, profile)
+}
+
+func analysisProfileFromContext(ctx context.Context) string {
+	profile, _ := ctx.Value(analysisProfileKey{}).(string)
+	return NormalizeAnalysisProfile(profile)
+}
+
+// profileSections adjusts the project's section statuses for the profile:
+// Quick Scan drops the model-only sections and Deep Dive opts in to every
+// section the project has not switched off.
+func profileSections(profile string, sections map[string]string, settings workspace.ProjectSettings) {
+	switch profile {
+	case ProfileQuick:
+		sections[SectionSensitivity] = SectionStatusDisabled
+	case ProfileDeep:
+		for s := range optInSections {
+			if settings.SectionEnabled(s) {
+				sections[s] = SectionStatusEnabled
+			}
+		}
+	}
+}
+
+// profileAIConfig turns the AI-detection services on or off for the profile.
+func profileAIConfig(profile string, cfg aidetect.Config) aidetect.Config {
+	switch profile {
+	case ProfileQuick:
+		cfg.EnableLanguageTool = false
+		cfg.EnableLMSmoothness = false
+		cfg.EnableSemanticDup = false
+	case ProfileDeep:
+		cfg.EnableLMSmoothness = true
+		cfg.EnableSemanticDup = true
+	}
+	return cfg
+}
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/workspace"
+)
+
+func TestNormalizeAnalysisProfile(t *testing.T) {
+	for name, want := range map[string]string{
+		"quick":      ProfileQuick,
+		"Quick Scan": ProfileQuick,
+		"deep-dive":  ProfileDeep,
+		"DEEP":       ProfileDeep,
+		"standard":   ProfileStandard,
+		"":           ProfileStandard,
+		"thorough":   ProfileStandard,
+	} {
+		if got := NormalizeAnalysisProfile(name); got != want {
+			t.Errorf("NormalizeAnalysisProfile(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if IsAnalysisProfile("") || IsAnalysisProfile("thorough") || !IsAnalysisProfile("Deep Dive") {
+		t.Fatal("expected only the named profiles to be recognized")
+	}
+	if got := analysisProfileFromContext(context.Background()); got != ProfileStandard {
+		t.Fatalf("expected standard without an override, got %q", got)
+	}
+}
+
+func TestProfileSectionsAndAIConfig(t *testing.T) {
+	settings := workspace.ProjectSettings{}
+	deep := sectionStatuses(settings)
+	profileSections(ProfileDeep, deep, settings)
+	if deep[SectionSensitivity] != SectionStatusEnabled {
+		t.Fatalf("expected deep dive to opt in to the sensitivity read, got %v", deep)
+	}
+	settings.DisabledSections = []string{SectionSensitivity}
+	disabled := sectionStatuses(settings)
+	profileSections(ProfileDeep, disabled, settings)
+	if disabled[SectionSensitivity] != SectionStatusDisabled {
+		t.Fatal("expected deep dive to keep a section the project switched off")
+	}
+	settings = workspace.ProjectSettings{EnabledSections: []string{SectionSensitivity}}
+	quick := sectionStatuses(settings)
+	profileSections(ProfileQuick, quick, settings)
+	if quick[SectionSensitivity] != SectionStatusDisabled || quick[SectionAIDetection] != SectionStatusEnabled {
+		t.Fatalf("expected quick scan to drop only the model-only sections, got %v", quick)
+	}
+
+	cfg := aidetect.DefaultConfig()
+	cfg.EnableLanguageTool = true
+	if q := profileAIConfig(ProfileQuick, cfg); q.EnableLanguageTool || q.EnableSemanticDup || q.EnableLMSmoothness {
+		t.Fatalf("expected quick scan to turn off AI services, got %+v", q)
+	}
+	if d := profileAIConfig(ProfileDeep, cfg); !d.EnableSemanticDup || !d.EnableLMSmoothness || !d.EnableLanguageTool {
+		t.Fatalf("expected deep dive to add embeddings and LM smoothness, got %+v", d)
+	}
+}
+
+func TestQuickScanRunsOffline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:9")
+
+	ctx := WithAnalysisProfile(context.Background(), "Quick Scan")
+	data := BuildDashboardContext(ctx, "Quick", "source.txt", []byte(DefaultDemoText), DefaultDemoText, nil)
+	if data.RunStats.Profile != ProfileQuick {
+		t.Fatalf("expected the profile in RunStats, got %q", data.RunStats.Profile)
+	}
+	if data.Language.SpellingProvider != "heuristic" || data.Language.HeuristicFallback {
+		t.Fatalf("expected offline language scoring without a fallback warning, got %s fallback=%t", data.Language.SpellingProvider, data.Language.HeuristicFallback)
+	}
+	for _, note := range data.Language.Notes {
+		if strings.Contains(note, "unavailable") {
+			t.Fatalf("expected no service calls, got note %q", note)
+		}
+	}
+	if data.PlotStructure.Provider != "heuristic" {
+		t.Fatalf("expected heuristic plot structure, got %q", data.PlotStructure.Provider)
+	}
+	for _, ch := range data.ChapterMetrics {
+		if ch.GenreProvider != "heuristic" {
+			t.Fatalf("expected heuristic genres, got %q for chapter %d", ch.GenreProvider, ch.Index)
+		}
+	}
+	if !strings.Contains(PlainReport(data), "- Analysis profile: quick\n") {
+		t.Fatal("expected the profile in the plain report")
+	}
+}
@@ -1,7 +1,73 @@
 package backend
 
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
 type ProgressFn func(percent int, stage, detail string)
 
+// ProgressStages lists every stage a run reports, in run order. The names are
+// stable: the frontend and headless callers key pipeline views on them.
+// SETUP is reported by the desktop app while it starts services, before any
+// run.
+var ProgressStages = []string{"SETUP", "BOOT", "WORKSPACE", "PROJECT", "INGEST", "CHAPTER", "DICTIONARY", "SLOP", "AI", "FORENSICS", "TIMELINE", "STRUCTURE", "LANGUAGE", "SENSITIVITY", "SCORING", "DONE"}
+
+// Pipeline stage states in ProgressEvent.Stages.
+const (
+	StagePending = "pending"
+	StageRunning = "running"
+	StageDone    = "done"
+	StageSkipped = "skipped"
+)
+
+// StageProgress is one timed stage of a run. PROJECT covers everything
+// before ingest (boot, workspace and project setup). EstimatedMs is the
+// expected duration from the manuscript's word count, or -1 when no pace is
+// known yet.
+type StageProgress struct {
+	Stage       string `json:"stage"`
+	Status      string `json:"status"`
+	ElapsedMs   int64  `json:"elapsedMs"`
+	EstimatedMs int64  `json:"estimatedMs"`
+}
+
+// ProgressEvent is the structured form of a progress report. Percent, Stage
+// and Detail match ProgressFn; Stage is one of ProgressStages. Chapter is the
+// 1-based chapter the report is about, or 0. RemainingMs estimates the rest of
+// the run from the word count and the project's stage history, falling back
+// to this run's own pace; it is -1 until either is known.
+type ProgressEvent struct {
+	Percent        int             `json:"percent"`
+	Stage          string          `json:"stage"`
+	Detail         string          `json:"detail"`
+	ElapsedMs      int64           `json:"elapsedMs"`
+	StageElapsedMs int64           `json:"stageElapsedMs"`
+	RemainingMs    int64           `json:"remainingMs"`
+	Chapter        int             `json:"chapter"`
+	ChapterCount   int             `json:"chapterCount"`
+	Words          int             `json:"words"`
+	Stages         []StageProgress `json:"stages"`
+}
+
+// ProgressListener receives structured progress events.
+type ProgressListener func(ProgressEvent)
+
+type progressListenerKey struct{}
+
+// WithProgressListener sends the run's structured progress events to fn, in
+// addition to any ProgressFn passed to BuildDashboardContext.
+func WithProgressListener(ctx context.Context, fn ProgressListener) context.Context {
+	return context.WithValue(ctx, progressListenerKey{}, fn)
+}
+
+func progressListenerFromContext(ctx context.Context) ProgressListener {
+	fn, _ := ctx.Value(progressListenerKey{}).(ProgressListener)
+	return fn
+}
+
 func progress(on ProgressFn, percent int, stage, detail string) {
 	if on == nil {
 		return
@@ -14,3 +80,301 @@ func progress(on ProgressFn, percent int, stage, detail string) {
 	}
 	on(percent, stage, detail)
 }
+
+// Stages covered by the progress plan, in run order. BOOT, WORKSPACE and
+// PROJECT run before project history is available and keep fixed percents up
+// to progressPlanStart.
+var plannedStages = []string{"INGEST", "CHAPTER", "DICTIONARY", "SLOP", "AI", "FORENSICS", "TIMELINE", "STRUCTURE", "LANGUAGE", "SENSITIVITY", "SCORING"}
+
+// defaultStageWeights reproduce the previous fixed percents and are used for
+// stages with no recorded history.
+var defaultStageWeights = map[string]float64{
+	"INGEST": 12, "CHAPTER": 26, "DICTIONARY": 2, "SLOP": 4, "AI": 6, "FORENSICS": 6,
+	"TIMELINE": 8, "STRUCTURE": 8, "LANGUAGE": 10, "SENSITIVITY": 2, "SCORING": 4,
+}
+
+const (
+	progressPlanStart = 12
+	// progressHistoryRuns is how many recent runs feed the stage weights.
+	progressHistoryRuns = 5
+)
+
+// progressPlan spreads progressPlanStart..100 over the stages of this run in
+// proportion to their weights, so a stage that dominates runtime also
+// dominates the bar. It keeps the history it was built from, which also
+// drives the tracker's time estimates.
+type progressPlan struct {
+	start   map[string]float64
+	span    map[string]float64
+	skip    map[string]bool
+	history map[string]float64
+	// pace converts default weights to ms per 1k words; 0 without history.
+	pace float64
+}
+
+// newProgressPlan weights stages by historical ms per 1k words when available.
+// Stages missing from history take their default weight, rescaled to the
+// history's units using the stages both sources know.
+func newProgressPlan(history map[string]float64, skip map[string]bool) progressPlan {
+	stages := []string{}
+	for _, stage := range plannedStages {
+		if !skip[stage] {
+			stages = append(stages, stage)
+		}
+	}
+	histSum, defSum := 0.0, 0.0
+	for _, stage := range stages {
+		if rate, ok := history[stage]; ok && rate > 0 {
+			histSum += rate
+			defSum += defaultStageWeights[stage]
+		}
+	}
+	pace := 0.0
+	if histSum > 0 && defSum > 0 {
+		pace = histSum / defSum
+	}
+	scale := 1.0
+	if pace > 0 {
+		scale = pace
+	}
+	weights := map[string]float64{}
+	total := 0.0
+	for _, stage := range stages {
+		w := defaultStageWeights[stage] * scale
+		if rate, ok := history[stage]; ok && rate > 0 {
+			w = rate
+		}
+		weights[stage] = w
+		total += w
+	}
+	if skip == nil {
+		skip = map[string]bool{}
+	}
+	plan := progressPlan{start: map[string]float64{}, span: map[string]float64{}, skip: skip, history: history, pace: pace}
+	at := float64(progressPlanStart)
+	for _, stage := range stages {
+		span := 0.0
+		if total > 0 {
+			span = weights[stage] / total * (100 - progressPlanStart)
+		}
+		plan.start[stage] = at
+		plan.span[stage] = span
+		at += span
+	}
+	return plan
+}
+
+// at returns the percent for a stage that is fraction (0..1) complete.
+func (p progressPlan) at(stage string, fraction float64) int {
+	start, ok := p.start[stage]
+	if !ok {
+		return progressPlanStart
+	}
+	fraction = math.Max(0, math.Min(1, fraction))
+	return int(math.Round(start + p.span[stage]*fraction))
+}
+
+func (p progressPlan) end(stage string) int {
+	return p.at(stage, 1)
+}
+
+// pipelineStages are the stages progressTracker times, matching the stages
+// the run's stageTimer records.
+var pipelineStages = append([]string{"PROJECT"}, plannedStages...)
+
+// progressTracker is the run's one source of progress: it places each report
+// on the bar with the progress plan, times each pipeline stage as the run
+// marks it done and estimates what is left. Reports after ctx is cancelled
+// are dropped. Stages that fan out report from several goroutines, so every
+// method locks.
+type progressTracker struct {
+	ctx      context.Context
+	legacy   ProgressFn
+	listener ProgressListener
+	timer    *stageTimer
+	words    int
+
+	mu           sync.Mutex
+	started      time.Time
+	stageStarted time.Time
+	done         map[string]int64
+	plan         progressPlan
+	chapterCount int
+}
+
+func newProgressTracker(ctx context.Context, onProgress ProgressFn, timer *stageTimer, started time.Time, words int) *progressTracker {
+	return &progressTracker{
+		ctx:          ctx,
+		legacy:       onProgress,
+		listener:     progressListenerFromContext(ctx),
+		timer:        timer,
+		words:        words,
+		started:      started,
+		stageStarted: started,
+		done:         map[string]int64{},
+		plan:         newProgressPlan(nil, nil),
+	}
+}
+
+// planStages weights the bar by the project's stage history (ms per 1k
+// words) and leaves out the stages this run skips, once the project is known.
+func (t *progressTracker) planStages(history map[string]float64, skip map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.plan = newProgressPlan(history, skip)
+}
+
+func (t *progressTracker) setChapters(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.chapterCount = n
+}
+
+// mark closes a pipeline stage, for the stage timer as well as for
+// progress events.
+func (t *progressTracker) mark(stage string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.timer.mark(stage)
+	t.done[stage] = now.Sub(t.stageStarted).Milliseconds()
+	t.stageStarted = now
+}
+
+// progress reports a fixed percent, for the stages before the plan and DONE.
+func (t *progressTracker) progress(percent int, stage, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reportLocked(percent, stage, 0, detail)
+}
+
+// stage reports a planned stage that is fraction (0..1) complete.
+func (t *progressTracker) stage(stage string, fraction float64, detail string) {
+	t.stageChapter(stage, fraction, 0, detail)
+}
+
+// stageChapter is stage for a report about one (1-based) chapter.
+func (t *progressTracker) stageChapter(stage string, fraction float64, chapter int, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reportLocked(t.plan.at(stage, fraction), stage, chapter, detail)
+}
+
+func (t *progressTracker) reportLocked(percent int, stage string, chapter int, detail string) {
+	if t.ctx.Err() != nil || (t.legacy == nil && t.listener == nil) {
+		return
+	}
+	percent = min(max(percent, 0), 100)
+	progress(t.legacy, percent, stage, detail)
+	if t.listener != nil {
+		t.listener(t.eventLocked(percent, stage, chapter, detail))
+	}
+}
+
+func (t *progressTracker) eventLocked(percent int, stage string, chapter int, detail string) ProgressEvent {
+	now := time.Now()
+	ev := ProgressEvent{
+		Percent:        percent,
+		Stage:          stage,
+		Detail:         detail,
+		ElapsedMs:      now.Sub(t.started).Milliseconds(),
+		StageElapsedMs: now.Sub(t.stageStarted).Milliseconds(),
+		RemainingMs:    -1,
+		Chapter:        chapter,
+		ChapterCount:   t.chapterCount,
+		Words:          t.words,
+		Stages:         make([]StageProgress, 0, len(pipelineStages)),
+	}
+	scale := t.paceLocked()
+	remaining := int64(0)
+	running := stage != "DONE"
+	for _, name := range pipelineStages {
+		sp := StageProgress{Stage: name, Status: StagePending, EstimatedMs: -1}
+		if est, ok := t.estimateLocked(name, scale); ok {
+			sp.EstimatedMs = est
+		}
+		switch elapsed, done := t.done[name]; {
+		case done:
+			sp.Status, sp.ElapsedMs = StageDone, elapsed
+		case t.plan.skip[name]:
+			sp.Status = StageSkipped
+		case running:
+			sp.Status, sp.ElapsedMs = StageRunning, ev.StageElapsedMs
+			running = false
+		}
+		if sp.Status == StageRunning || sp.Status == StagePending {
+			if sp.EstimatedMs < 0 {
+				remaining = -1
+			} else if remaining >= 0 && sp.EstimatedMs > sp.ElapsedMs {
+				remaining += sp.EstimatedMs - sp.ElapsedMs
+			}
+		}
+		ev.Stages = append(ev.Stages, sp)
+	}
+	if stage == "DONE" {
+		remaining = 0
+	}
+	ev.RemainingMs = remaining
+	return ev
+}
+
+// paceLocked converts default stage weights to ms per 1k words: the plan's
+// pace from project history, else the pace of the stages this run has
+// finished. It returns 0 when neither is available.
+func (t *progressTracker) paceLocked() float64 {
+	if t.plan.pace > 0 || t.words <= 0 {
+		return t.plan.pace
+	}
+	rateSum, weightSum := 0.0, 0.0
+	for _, stage := range plannedStages {
+		if ms, ok := t.done[stage]; ok {
+			rateSum += float64(ms) * 1000 / float64(t.words)
+			weightSum += defaultStageWeights[stage]
+		}
+	}
+	if weightSum == 0 || rateSum == 0 {
+		return 0
+	}
+	return rateSum / weightSum
+}
+
+// estimateLocked is a stage's expected duration for this manuscript.
+func (t *progressTracker) estimateLocked(stage string, scale float64) (int64, bool) {
+	rate := t.plan.history[stage]
+	if rate <= 0 {
+		rate = defaultStageWeights[stage] * scale
+	}
+	if rate <= 0 {
+		return 0, false
+	}
+	return int64(math.Round(rate * float64(t.words) / 1000)), true
+}
+
+// modelHeartbeatInterval is how often a stage waiting on a local model
+// re-emits progress, so a slow model does not look like a hung app.
+const modelHeartbeatInterval = 5 * time.Second
+
+// heartbeat calls tick with the elapsed time every interval until stop is
+// called. stop waits for an in-flight tick, so no tick follows it.
+func heartbeat(interval time.Duration, tick func(elapsed time.Duration)) (stop func()) {
+	started := time.Now()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				tick(now.Sub(started).Round(time.Second))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
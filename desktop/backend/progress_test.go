@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressPlanDefaultsMatchFixedPercents(t *testing.T) {
+	plan := newProgressPlan(nil, map[string]bool{})
+	if got := plan.end("INGEST"); got != 24 {
+		t.Fatalf("expected INGEST to end at 24 without history, got %d", got)
+	}
+	if got := plan.end("SCORING"); got != 100 {
+		t.Fatalf("expected last stage to end at 100, got %d", got)
+	}
+	prev := progressPlanStart
+	for _, stage := range plannedStages {
+		if plan.end(stage) < prev {
+			t.Fatalf("progress went backwards at %s: %d < %d", stage, plan.end(stage), prev)
+		}
+		prev = plan.end(stage)
+	}
+}
+
+func TestProgressPlanWeightsByHistory(t *testing.T) {
+	// AI took most of the runtime historically; only some stages are known.
+	history := map[string]float64{"AI": 6000, "CHAPTER": 500, "LANGUAGE": 500}
+	plan := newProgressPlan(history, map[string]bool{"SENSITIVITY": true})
+	aiSpan := plan.end("AI") - plan.at("AI", 0)
+	chapterSpan := plan.end("CHAPTER") - plan.at("CHAPTER", 0)
+	if aiSpan < 30 || chapterSpan*5 > aiSpan {
+		t.Fatalf("expected AI to dominate the bar, got ai=%d chapter=%d", aiSpan, chapterSpan)
+	}
+	if plan.at("CHAPTER", 0.5) <= plan.at("CHAPTER", 0) {
+		t.Fatalf("expected sub-progress inside the chapter stage")
+	}
+	if _, ok := plan.start["SENSITIVITY"]; ok {
+		t.Fatalf("expected skipped stage to be left out of the plan")
+	}
+	if got := plan.end("SCORING"); got != 100 {
+		t.Fatalf("expected plan to end at 100, got %d", got)
+	}
+}
+
+func TestProgressTrackerReportsStagesAndRemainingTime(t *testing.T) {
+	var events []ProgressEvent
+	legacy := 0
+	ctx := WithProgressListener(context.Background(), func(ev ProgressEvent) { events = append(events, ev) })
+	track := newProgressTracker(ctx, func(int, string, string) { legacy++ }, newStageTimer(time.Now()), time.Now(), 2000)
+	history := map[string]float64{}
+	for _, stage := range pipelineStages {
+		history[stage] = 1000
+	}
+	track.planStages(history, map[string]bool{"SENSITIVITY": true})
+	track.setChapters(5)
+	track.mark("PROJECT")
+	track.mark("INGEST")
+	track.stageChapter("CHAPTER", 0.3, 3, "Chapter 3/5: genre classified")
+
+	if len(events) != 1 || legacy != 1 {
+		t.Fatalf("expected one structured and one plain report, got %d and %d", len(events), legacy)
+	}
+	ev := events[0]
+	if ev.Stage != "CHAPTER" || ev.Chapter != 3 || ev.ChapterCount != 5 || ev.Words != 2000 {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	status := map[string]string{}
+	for _, sp := range ev.Stages {
+		status[sp.Stage] = sp.Status
+	}
+	if status["PROJECT"] != StageDone || status["INGEST"] != StageDone || status["CHAPTER"] != StageRunning || status["AI"] != StagePending || status["SENSITIVITY"] != StageSkipped {
+		t.Fatalf("unexpected stage states %v", status)
+	}
+	// Nine stages left at 1000 ms per 1k words over 2000 words.
+	if ev.RemainingMs > 18000 || ev.RemainingMs < 17000 {
+		t.Fatalf("expected about 18s remaining, got %dms", ev.RemainingMs)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	quiet := newProgressTracker(cancelled, nil, newStageTimer(time.Now()), time.Now(), 2000)
+	quiet.progress(50, "AI", "")
+	if len(events) != 1 {
+		t.Fatalf("expected no events after cancel, got %d", len(events))
+	}
+}
+
+func TestProgressTrackerEstimatesFromRunPaceWithoutHistory(t *testing.T) {
+	var last ProgressEvent
+	ctx := WithProgressListener(context.Background(), func(ev ProgressEvent) { last = ev })
+	track := newProgressTracker(ctx, nil, newStageTimer(time.Now()), time.Now(), 2000)
+	track.progress(12, "PROJECT", "Project initialized")
+	if last.RemainingMs != -1 {
+		t.Fatalf("expected an unknown estimate before any stage finished, got %d", last.RemainingMs)
+	}
+	// INGEST took 600 ms per 1k words against a default weight of 12, so
+	// CHAPTER (weight 26) should take 1300 ms per 1k words.
+	track.done["PROJECT"], track.done["INGEST"] = 10, 1200
+	track.stage("CHAPTER", 0, "")
+	for _, sp := range last.Stages {
+		if sp.Stage == "CHAPTER" && sp.EstimatedMs != 2600 {
+			t.Fatalf("expected CHAPTER estimated at 2600ms, got %d", sp.EstimatedMs)
+		}
+	}
+	if last.RemainingMs <= 2600 {
+		t.Fatalf("expected the remaining stages estimated, got %dms", last.RemainingMs)
+	}
+}
+
+func TestHeartbeatTicksUntilStopped(t *testing.T) {
+	var mu sync.Mutex
+	ticks := []time.Duration{}
+	stop := heartbeat(5*time.Millisecond, func(elapsed time.Duration) {
+		mu.Lock()
+		ticks = append(ticks, elapsed)
+		mu.Unlock()
+	})
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	mu.Lock()
+	seen := len(ticks)
+	mu.Unlock()
+	if seen == 0 {
+		t.Fatalf("expected heartbeat ticks while waiting")
+	}
+	time.Sleep(15 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ticks) != seen {
+		t.Fatalf("expected no ticks after stop, got %d more", len(ticks)-seen)
+	}
+}
@@ -0,0 +1,29 @@
+package backend
+
+import (
+	"book_dashboard/internal/workspace"
+)
+
+// ListProjects returns the analyzed projects in the default workspace, most
+// recently analyzed first.
+func ListProjects() ([]workspace.ProjectSummary, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return nil, err
+	}
+	return workspace.ListProjects(root)
+}
+
+// OpenProject rebuilds the dashboard saved for the project with id in the
+// default workspace; see OpenProjectReport.
+func OpenProject(id string) (DashboardData, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return DashboardData{}, err
+	}
+	projectRoot, err := workspace.ProjectRoot(root, id)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	return OpenProjectReport(projectRoot)
+}
@@ -0,0 +1,176 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/workspace"
+)
+
+// PurgedEmbeddings is the PurgedFile kind for manuscript embeddings dropped
+// from the comp-title embedding cache.
+const PurgedEmbeddings = "embedding_cache"
+
+// PurgedLibraryScores is the PurgedFile kind for a project's scores dropped
+// from the workspace library used for benchmarks.
+const PurgedLibraryScores = "library_scores"
+
+// PurgedFeedbackLabels is the PurgedFile kind for verdicts on a project's
+// findings dropped from the workspace library.
+const PurgedFeedbackLabels = "feedback_labels"
+
+// PurgeReport is the verification report for "Remove my manuscript": what
+// was deleted, whether each deletion was confirmed, and what is out of reach.
+type PurgeReport struct {
+	ProjectLocation string                 `json:"projectLocation"`
+	Deleted         []workspace.PurgedFile `json:"deleted"`
+	FilesDeleted    int                    `json:"filesDeleted"`
+	BytesDeleted    int64                  `json:"bytesDeleted"`
+	// Verified is true when every deleted file was confirmed gone.
+	Verified  bool     `json:"verified"`
+	Remaining []string `json:"remaining"`
+	Notes     []string `json:"notes"`
+}
+
+// PurgeManuscript deletes a project's stored data: its directory (source
+// copies, report, database, settings), its project index entry, the stage
+// cache for its text, its scores and feedback labels in the workspace
+// library and any embeddings of its text in the comp-title cache. Run logs and snapshots live
+// with the desktop app, which adds them before calling Verify.
+func PurgeManuscript(projectLocation string) (PurgeReport, error) {
+	report := PurgeReport{ProjectLocation: projectLocation, Deleted: []workspace.PurgedFile{}, Remaining: []string{}, Notes: []string{}}
+	if strings.TrimSpace(projectLocation) == "" {
+		return report, fmt.Errorf("no project loaded")
+	}
+	workspaceRoot := filepath.Dir(filepath.Dir(projectLocation))
+	// The stage cache is keyed by the text hash recorded in report.json,
+	// which goes with the project directory.
+	textSHA256 := ""
+	if raw, err := os.ReadFile(filepath.Join(projectLocation, "report.json")); err == nil {
+		var saved workspace.Report
+		if json.Unmarshal(raw, &saved) == nil {
+			textSHA256 = saved.TextSHA256
+		}
+	}
+	purged, err := workspace.PurgeProject(workspaceRoot, projectLocation)
+	report.Deleted = append(report.Deleted, purged...)
+	if err != nil {
+		return report, fmt.Errorf("purge project: %w", err)
+	}
+	if textSHA256 != "" {
+		staged, err := workspace.PurgeStageCache(workspaceRoot, textSHA256)
+		report.Deleted = append(report.Deleted, staged...)
+		if err != nil {
+			return report, fmt.Errorf("purge stage cache: %w", err)
+		}
+	}
+	libraryPath := workspace.LibraryDBPath(workspaceRoot)
+	if _, err := os.Stat(libraryPath); err == nil {
+		removed, err := db.DeleteLibraryScores(libraryPath, filepath.Base(projectLocation))
+		if err != nil {
+			return report, fmt.Errorf("purge library scores: %w", err)
+		}
+		if removed > 0 {
+			report.Deleted = append(report.Deleted, workspace.PurgedFile{Path: libraryPath, Kind: PurgedLibraryScores, Entries: removed})
+		}
+		labels, err := db.DeleteProjectFeedback(libraryPath, filepath.Base(projectLocation))
+		if err != nil {
+			return report, fmt.Errorf("purge feedback labels: %w", err)
+		}
+		if labels > 0 {
+			report.Deleted = append(report.Deleted, workspace.PurgedFile{Path: libraryPath, Kind: PurgedFeedbackLabels, Entries: labels})
+		}
+	}
+	cached, err := pruneEmbeddingCaches(workspaceRoot)
+	report.Deleted = append(report.Deleted, cached...)
+	if err != nil {
+		return report, fmt.Errorf("prune embedding cache: %w", err)
+	}
+	return report, nil
+}
+
+// pruneEmbeddingCaches keeps only catalog blurb embeddings. Synopsis
+// embeddings are keyed by a hash of manuscript text, so they cannot be told
+// apart per project; every one is dropped, costing one embedding call for
+// the next run of each other manuscript.
+func pruneEmbeddingCaches(workspaceRoot string) ([]workspace.PurgedFile, error) {
+	dir := embeddingCacheDir(workspaceRoot)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	catalog, _, _ := LoadCompCatalog(workspaceRoot)
+	blurbs := map[string]bool{}
+	for _, e := range catalog {
+		blurbs[embeddingKey(e.Blurb)] = true
+	}
+	out := []workspace.PurgedFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return out, err
+		}
+		vectors := map[string][]float64{}
+		if err := json.Unmarshal(raw, &vectors); err != nil {
+			// An unreadable cache may still hold manuscript embeddings.
+			if err := os.Remove(path); err != nil {
+				return out, err
+			}
+			out = append(out, workspace.PurgedFile{Path: path, Kind: PurgedEmbeddings, Bytes: int64(len(raw))})
+			continue
+		}
+		removed := 0
+		for key := range vectors {
+			if !blurbs[key] {
+				delete(vectors, key)
+				removed++
+			}
+		}
+		if removed == 0 {
+			continue
+		}
+		kept, err := json.Marshal(vectors)
+		if err != nil {
+			return out, err
+		}
+		if err := os.WriteFile(path, kept, 0o644); err != nil {
+			return out, err
+		}
+		out = append(out, workspace.PurgedFile{Path: path, Kind: PurgedEmbeddings, Bytes: int64(len(raw) - len(kept)), Entries: removed})
+	}
+	return out, nil
+}
+
+// Verify totals the report and confirms each deleted file is gone. Files
+// that were only pruned of entries are expected to remain.
+func (r *PurgeReport) Verify() {
+	r.FilesDeleted, r.BytesDeleted = 0, 0
+	r.Remaining = []string{}
+	for _, d := range r.Deleted {
+		r.BytesDeleted += d.Bytes
+		if d.Entries > 0 {
+			continue
+		}
+		r.FilesDeleted++
+		if _, err := os.Lstat(d.Path); !os.IsNotExist(err) {
+			r.Remaining = append(r.Remaining, d.Path)
+		}
+	}
+	if r.ProjectLocation != "" {
+		if _, err := os.Lstat(r.ProjectLocation); !os.IsNotExist(err) {
+			r.Remaining = append(r.Remaining, r.ProjectLocation)
+		}
+	}
+	r.Verified = len(r.Remaining) == 0
+}
@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/workspace"
+)
+
+func TestPurgeManuscriptPrunesSynopsisEmbeddings(t *testing.T) {
+	root, err := workspace.EnsureAt(filepath.Join(t.TempDir(), workspace.BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	catalog := []CompCatalogEntry{{Title: "Known Book", Blurb: "A catalog blurb."}}
+	raw, _ := json.Marshal(catalog)
+	if err := os.WriteFile(filepath.Join(root, "configs", "comp_catalog.json"), raw, 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	cachePath := filepath.Join(embeddingCacheDir(root), "nomic-embed-text.json")
+	vectors := map[string][]float64{
+		embeddingKey("A catalog blurb."):       {1, 0},
+		embeddingKey("The secret synopsis..."): {0, 1},
+	}
+	raw, _ = json.Marshal(vectors)
+	if err := os.WriteFile(cachePath, raw, 0o644); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+	project, err := workspace.CreateProjectWithSource(root, "Secret", "secret.docx", []byte("secret text"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	libraryPath := workspace.LibraryDBPath(root)
+	if err := db.RecordLibraryScores(libraryPath, project.ID, "run-1", map[string]float64{"mhd_score": 70, "word_count": 3}); err != nil {
+		t.Fatalf("record library scores: %v", err)
+	}
+
+	report, err := PurgeManuscript(project.Root)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	report.Verify()
+	if !report.Verified || report.FilesDeleted < 2 {
+		t.Fatalf("expected verified file deletions, got %+v", report)
+	}
+	pruned, unlisted := false, false
+	for _, d := range report.Deleted {
+		if d.Kind == PurgedEmbeddings && d.Entries == 1 {
+			pruned = true
+		}
+		if d.Kind == PurgedLibraryScores && d.Entries == 2 {
+			unlisted = true
+		}
+	}
+	if !unlisted {
+		t.Fatalf("expected the project's two library scores removed, got %+v", report.Deleted)
+	}
+	if !pruned {
+		t.Fatalf("expected one synopsis embedding pruned, got %+v", report.Deleted)
+	}
+	left := loadEmbeddingCache(embeddingCacheDir(root), "nomic-embed-text")
+	if len(left.vectors) != 1 || left.vectors[embeddingKey("A catalog blurb.")] == nil {
+		t.Fatalf("expected only the catalog embedding kept, got %v", left.vectors)
+	}
+
+	if _, err := PurgeManuscript(""); err == nil {
+		t.Fatal("expected an error with no project loaded")
+	}
+}
+
+func TestPurgeReportVerifyFlagsSurvivors(t *testing.T) {
+	survivor := filepath.Join(t.TempDir(), "still-here.txt")
+	if err := os.WriteFile(survivor, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write survivor: %v", err)
+	}
+	report := PurgeReport{Deleted: []workspace.PurgedFile{
+		{Path: survivor, Kind: workspace.PurgedSource, Bytes: 1},
+		{Path: filepath.Join(t.TempDir(), "gone.txt"), Kind: workspace.PurgedReport, Bytes: 4},
+		{Path: survivor, Kind: workspace.PurgedIndexEntry, Entries: 1},
+	}}
+	report.Verify()
+	if report.Verified || len(report.Remaining) != 1 || report.FilesDeleted != 2 || report.BytesDeleted != 5 {
+		t.Fatalf("expected one surviving file flagged, got %+v", report)
+	}
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"book_dashboard/internal/gates"
+)
+
+// qualityMetrics collects the run's values the quality gates can test. The
+// AI probability is left out when AI detection did not run, and the language
+// scores when no language check produced any.
+func qualityMetrics(data DashboardData) gates.Metrics {
+	m := gates.Metrics{
+		gates.MetricMHDScore:           float64(data.MHDScore),
+		gates.MetricAISuspicion:        float64(data.SlopReport.AISuspicionScore),
+		gates.MetricContradictions:     float64(len(data.Contradictions)),
+		gates.MetricHighContradictions: 0,
+		gates.MetricMedContradictions:  0,
+		gates.MetricLowContradictions:  0,
+	}
+	for _, c := range data.Contradictions {
+		switch strings.ToUpper(strings.TrimSpace(c.Severity)) {
+		case "HIGH":
+			m[gates.MetricHighContradictions]++
+		case "MED", "MEDIUM":
+			m[gates.MetricMedContradictions]++
+		case "LOW":
+			m[gates.MetricLowContradictions]++
+		}
+	}
+	if data.AIReport.PAIDoc != nil {
+		m[gates.MetricPAIDoc] = *data.AIReport.PAIDoc
+	}
+	if lang := data.Language; lang.GrammarScore > 0 || lang.SpellingScore > 0 {
+		m[gates.MetricGrammar] = float64(lang.GrammarScore)
+		m[gates.MetricSpelling] = float64(lang.SpellingScore)
+		m[gates.MetricReadability] = float64(lang.ReadabilityScore)
+	}
+	return m
+}
+
+// applyQualityGates evaluates the submission checklist for a finished run and
+// logs each gate that failed.
+func applyQualityGates(data *DashboardData, rules []gates.Rule, addLog func(level, stage, message, detail string)) {
+	data.QualityMetrics = qualityMetrics(*data)
+	data.QualityGates = gates.Evaluate(data.QualityMetrics, rules)
+	failed := 0
+	for _, r := range data.QualityGates {
+		if !r.Passed {
+			failed++
+			addLog("RISK", "SCORING", "Quality gate failed: "+r.Label, r.Explanation)
+		}
+	}
+	addLog("INFO", "SCORING", "Quality gates evaluated", fmt.Sprintf("gates=%d failed=%d", len(data.QualityGates), failed))
+}
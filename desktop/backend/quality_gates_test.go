@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"testing"
+
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/gates"
+)
+
+func TestApplyQualityGatesBuildsChecklist(t *testing.T) {
+	pAI := 0.62
+	data := DashboardData{
+		MHDScore:       80,
+		Contradictions: []forensics.Contradiction{{EntityName: "sarah", Severity: "HIGH"}, {EntityName: "mark", Severity: "low"}},
+		Language:       LanguageReport{GrammarScore: 91, SpellingScore: 88},
+	}
+	data.AIReport.PAIDoc = &pAI
+	var logged []string
+	applyQualityGates(&data, gates.DefaultRules(), func(level, stage, message, detail string) {
+		logged = append(logged, level+" "+message)
+	})
+
+	if data.QualityMetrics[gates.MetricHighContradictions] != 1 || data.QualityMetrics[gates.MetricLowContradictions] != 1 {
+		t.Fatalf("expected contradictions counted by severity, got %v", data.QualityMetrics)
+	}
+	if len(data.QualityGates) != 3 || data.QualityGates[0].Passed || data.QualityGates[1].Passed || !data.QualityGates[2].Passed {
+		t.Fatalf("expected the contradiction and AI gates to fail and grammar to pass, got %+v", data.QualityGates)
+	}
+	if len(logged) != 3 || logged[0] != "RISK Quality gate failed: No HIGH contradictions" {
+		t.Fatalf("expected each failed gate logged, got %v", logged)
+	}
+
+	data.AIReport.PAIDoc = nil
+	data.Language = LanguageReport{}
+	applyQualityGates(&data, gates.DefaultRules(), func(string, string, string, string) {})
+	if !data.QualityGates[1].Skipped || !data.QualityGates[2].Skipped {
+		t.Fatalf("expected unmeasured AI and grammar gates skipped, got %+v", data.QualityGates)
+	}
+}
@@ -0,0 +1,128 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// readingGradeDrift is how many grade levels a chapter's Flesch-Kincaid grade
+// may sit from the manuscript's before the language notes call it out.
+const readingGradeDrift = 2.5
+
+// ReadingLevel is a passage's standard readability formulas with the counts
+// they are computed from. Syllables are estimated from vowel groups; SMOG
+// assumes at least 30 sentences and reads high on shorter passages.
+type ReadingLevel struct {
+	Sentences          int     `json:"sentences"`
+	Words              int     `json:"words"`
+	Syllables          int     `json:"syllables"`
+	Polysyllables      int     `json:"polysyllables"`
+	FleschReadingEase  float64 `json:"fleschReadingEase"`
+	FleschKincaidGrade float64 `json:"fleschKincaidGrade"`
+	GunningFog         float64 `json:"gunningFog"`
+	SMOG               float64 `json:"smog"`
+}
+
+// measureReadingLevel counts the sentences, words and syllables of text,
+// skipping chapter headings, and scores them.
+func measureReadingLevel(text string) ReadingLevel {
+	var level ReadingLevel
+	for _, paragraph := range nonEmptyLines(text) {
+		if chapterHeaderPattern.MatchString(paragraph) && strings.TrimRight(paragraph, ".!?\"”") == paragraph {
+			continue
+		}
+		for _, sentence := range splitSentences(paragraph) {
+			words := wordPattern.FindAllString(sentence, -1)
+			if len(words) == 0 {
+				continue
+			}
+			level.Sentences++
+			for _, w := range words {
+				n := syllableCount(w)
+				level.Words++
+				level.Syllables += n
+				if n >= 3 {
+					level.Polysyllables++
+				}
+			}
+		}
+	}
+	return level.scored()
+}
+
+func (l ReadingLevel) scored() ReadingLevel {
+	if l.Words == 0 || l.Sentences == 0 {
+		return ReadingLevel{}
+	}
+	wordsPerSentence := float64(l.Words) / float64(l.Sentences)
+	syllablesPerWord := float64(l.Syllables) / float64(l.Words)
+	l.FleschReadingEase = round1(206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord)
+	l.FleschKincaidGrade = round1(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+	l.GunningFog = round1(0.4 * (wordsPerSentence + 100*float64(l.Polysyllables)/float64(l.Words)))
+	l.SMOG = round1(1.043*math.Sqrt(float64(l.Polysyllables)*30/float64(l.Sentences)) + 3.1291)
+	return l
+}
+
+// readabilityScore is the 0-100 readability score: the Flesch reading ease,
+// where higher reads more easily.
+func (l ReadingLevel) readabilityScore() int {
+	if l.Words == 0 {
+		return 0
+	}
+	return clamp100(int(math.Round(l.FleschReadingEase)))
+}
+
+// syllableCount estimates a word's syllables from its vowel groups, dropping
+// a silent final e and the unvoiced -ed of "walked".
+func syllableCount(word string) int {
+	w := strings.ToLower(strings.Trim(word, "'"))
+	if len(w) <= 3 {
+		return 1
+	}
+	count, inVowels := 0, false
+	for _, r := range w {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !inVowels {
+			count++
+		}
+		inVowels = vowel
+	}
+	switch {
+	case strings.HasSuffix(w, "e") && !strings.HasSuffix(w, "le") && !strings.HasSuffix(w, "ee"):
+		count--
+	case strings.HasSuffix(w, "ed") && !strings.HasSuffix(w, "ted") && !strings.HasSuffix(w, "ded"):
+		count--
+	case strings.HasSuffix(w, "es") && !strings.HasSuffix(w, "ses") && !strings.HasSuffix(w, "ces") && !strings.HasSuffix(w, "ges") && !strings.HasSuffix(w, "zes"):
+		count--
+	}
+	return max(1, count)
+}
+
+// readingLevelDrift describes the chapters whose Flesch-Kincaid grade sits
+// readingGradeDrift or more grades from the manuscript's.
+func readingLevelDrift(metrics []ChapterMetric, book ReadingLevel) []string {
+	out := []string{}
+	if book.Words == 0 {
+		return out
+	}
+	for _, m := range metrics {
+		if m.ReadingLevel.Words == 0 {
+			continue
+		}
+		gap := m.ReadingLevel.FleschKincaidGrade - book.FleschKincaidGrade
+		if math.Abs(gap) < readingGradeDrift {
+			continue
+		}
+		direction := "above"
+		if gap < 0 {
+			direction = "below"
+		}
+		out = append(out, fmt.Sprintf("Chapter %d (%s) reads at grade %.1f, %.1f grades %s the manuscript's %.1f.", m.Index, m.Title, m.ReadingLevel.FleschKincaidGrade, math.Abs(gap), direction, book.FleschKincaidGrade))
+	}
+	return out
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,30 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasureReadingLevel(t *testing.T) {
+	for word, want := range map[string]int{"cat": 1, "walked": 1, "table": 2, "needed": 2, "beautiful": 3, "readability": 5, "home": 1} {
+		if got := syllableCount(word); got != want {
+			t.Fatalf("syllableCount(%q) = %d, want %d", word, got, want)
+		}
+	}
+	easy := measureReadingLevel("Chapter 1\nThe cat sat on the mat. The dog ran to the door. We went home.")
+	hard := measureReadingLevel("Chapter 2\nInstitutional accountability necessitates comprehensive administrative documentation, particularly regarding organizational responsibilities and interdepartmental communication.")
+	if easy.Sentences != 3 || easy.Words != 15 {
+		t.Fatalf("expected the heading skipped and 3 sentences of 15 words, got %+v", easy)
+	}
+	if easy.FleschKincaidGrade >= hard.FleschKincaidGrade || easy.GunningFog >= hard.GunningFog || easy.SMOG >= hard.SMOG {
+		t.Fatalf("expected the plain passage to read easier: easy %+v hard %+v", easy, hard)
+	}
+	if easy.readabilityScore() <= hard.readabilityScore() {
+		t.Fatalf("expected a higher readability score for the plain passage: %d vs %d", easy.readabilityScore(), hard.readabilityScore())
+	}
+	book := measureReadingLevel("The cat sat on the mat. The dog ran to the door. We went home.\n" + "Institutional accountability necessitates comprehensive administrative documentation, particularly regarding organizational responsibilities and interdepartmental communication.")
+	drift := readingLevelDrift([]ChapterMetric{{Index: 1, Title: "Start", ReadingLevel: easy}, {Index: 2, Title: "Memo", ReadingLevel: hard}}, book)
+	if len(drift) != 2 || !strings.Contains(drift[1], "Chapter 2 (Memo) reads at grade") || !strings.Contains(drift[1], "above") {
+		t.Fatalf("unexpected drift notes %q", drift)
+	}
+}
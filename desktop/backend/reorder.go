@@ -0,0 +1,142 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"book_dashboard/internal/structure"
+)
+
+const maxReorderSuggestions = 3
+
+var storyYearPattern = regexp.MustCompile(`\b(1[5-9]\d\d|20\d\d)\b`)
+var flashbackCuePattern = regexp.MustCompile(`(?i)\b(years (earlier|before|ago)|back then|flashback|(i|he|she|they) remembered|long ago)\b`)
+
+// ReorderReport is an experimental developmental-editing aid: it simulates
+// single-chapter moves and re-scores structure fit. Suggestions are prompts
+// for discussion, not fixes.
+type ReorderReport struct {
+	Experimental bool                `json:"experimental"`
+	Current      ReorderScore        `json:"current"`
+	Chapters     []ReorderChapter    `json:"chapters"`
+	Suggestions  []ReorderSuggestion `json:"suggestions"`
+	Notes        []string            `json:"notes"`
+}
+
+type ReorderScore struct {
+	Total      float64 `json:"total"`
+	BeatFit    float64 `json:"beatFit"`
+	Chronology float64 `json:"chronology"`
+	Pacing     float64 `json:"pacing"`
+}
+
+// ReorderChapter records what the simulation inferred about a chapter, so an
+// editor can correct a wrong year or flashback call before trusting a result.
+type ReorderChapter struct {
+	Chapter   int    `json:"chapter"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+	Flashback bool   `json:"flashback"`
+	Beat      string `json:"beat"`
+}
+
+type ReorderSuggestion struct {
+	Chapter   int          `json:"chapter"`
+	From      int          `json:"from"`
+	To        int          `json:"to"`
+	Order     []int        `json:"order"`
+	Score     ReorderScore `json:"score"`
+	Gain      float64      `json:"gain"`
+	Rationale []string     `json:"rationale"`
+}
+
+// SimulateChapterOrders scores the manuscript's chapter order against the
+// beat windows, story chronology and flashback placement, and returns the
+// single-chapter moves that would improve it most.
+func SimulateChapterOrders(data DashboardData, text string) (ReorderReport, error) {
+	chapters := splitChapters(text)
+	if strings.TrimSpace(text) == "" || len(chapters) < 3 {
+		return ReorderReport{}, fmt.Errorf("chapter reordering needs an analyzed manuscript with at least three chapters")
+	}
+	facts := reorderFacts(chapters, data.Beats)
+	current, suggestions := structure.SimulateReorders(facts, structure.SaveTheCatWindows, maxReorderSuggestions)
+
+	report := ReorderReport{
+		Experimental: true,
+		Current:      reorderScore(current),
+		Chapters:     make([]ReorderChapter, 0, len(facts)),
+		Suggestions:  make([]ReorderSuggestion, 0, len(suggestions)),
+		Notes:        []string{"Experimental: scores use inferred story years, flashback cues and beat positions; review the chapter facts before acting on a suggestion."},
+	}
+	for _, f := range facts {
+		report.Chapters = append(report.Chapters, ReorderChapter{Chapter: f.Index, Title: f.Title, Year: f.Year, Flashback: f.Flashback, Beat: f.Beat})
+	}
+	for _, s := range suggestions {
+		report.Suggestions = append(report.Suggestions, ReorderSuggestion{
+			Chapter:   s.Move.Chapter,
+			From:      s.Move.From,
+			To:        s.Move.To,
+			Order:     s.Order,
+			Score:     reorderScore(s.Score),
+			Gain:      s.Gain,
+			Rationale: s.Rationale,
+		})
+	}
+	if data.PlotStructure.Provider == "heuristic" {
+		report.Notes = append(report.Notes, "Beats were placed by chapter position rather than by the model, so only chronology and flashback pacing can suggest moves.")
+	}
+	if len(report.Suggestions) == 0 {
+		report.Notes = append(report.Notes, "No single-chapter move improves structure fit enough to suggest.")
+	}
+	return report, nil
+}
+
+func reorderFacts(chapters []chapter, beats []BeatResult) []structure.ChapterFacts {
+	beatByChapter := map[int]string{}
+	for _, b := range beats {
+		if b.IsBeat && b.StartChapter > 0 {
+			if _, taken := beatByChapter[b.StartChapter]; !taken {
+				beatByChapter[b.StartChapter] = b.Name
+			}
+		}
+	}
+	facts := make([]structure.ChapterFacts, 0, len(chapters))
+	for _, ch := range chapters {
+		// Only cued chapters count as flashbacks; an uncued jump back in
+		// years is left to the chronology score.
+		flashback := len(flashbackCuePattern.FindAllString(ch.text, 2)) >= 2
+		facts = append(facts, structure.ChapterFacts{Index: ch.index, Title: ch.title, Year: storyYear(ch.text), Flashback: flashback, Beat: beatByChapter[ch.index]})
+	}
+	return facts
+}
+
+// storyYear is the most frequently mentioned year in a chapter, earliest on
+// ties, or 0 when none is mentioned.
+func storyYear(text string) int {
+	counts := map[int]int{}
+	for _, m := range storyYearPattern.FindAllString(text, -1) {
+		year, _ := strconv.Atoi(m)
+		counts[year]++
+	}
+	years := make([]int, 0, len(counts))
+	for year := range counts {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	best := 0
+	for _, year := range years {
+		if best == 0 || counts[year] > counts[best] {
+			best = year
+		}
+	}
+	return best
+}
+
+func reorderScore(s structure.OrderScore) ReorderScore {
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	return ReorderScore{Total: round(s.Total), BeatFit: round(s.BeatFit), Chronology: round(s.Chronology), Pacing: round(s.Pacing)}
+}
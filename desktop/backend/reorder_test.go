@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulateChapterOrdersInfersFacts(t *testing.T) {
+	text := strings.Join([]string{
+		"Chapter 1", "Years earlier, back then, she remembered the orchard. " + strings.Repeat("Apples fell. ", 20),
+		"Chapter 2", "In 2001 the ferry docked. " + strings.Repeat("Gulls cried. ", 20),
+		"Chapter 3", "By 2002 the town had changed. In 2002 nobody left. " + strings.Repeat("Rain fell. ", 20),
+		"Chapter 4", "The winter of 2003 was long. " + strings.Repeat("Snow fell. ", 20),
+	}, "\n")
+	data := DashboardData{
+		Beats:         []BeatResult{{Name: "Midpoint", StartChapter: 3, EndChapter: 3, IsBeat: true}},
+		PlotStructure: PlotStructureReport{Provider: "ollama:test"},
+	}
+	report, err := SimulateChapterOrders(data, text)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if !report.Experimental || len(report.Chapters) != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	first, third := report.Chapters[0], report.Chapters[2]
+	if !first.Flashback || third.Year != 2002 || third.Beat != "Midpoint" {
+		t.Fatalf("facts inferred wrong: %+v", report.Chapters)
+	}
+	if len(report.Suggestions) == 0 || report.Suggestions[0].Chapter != 1 {
+		t.Fatalf("expected the opening flashback to be moved, got %+v", report.Suggestions)
+	}
+
+	if _, err := SimulateChapterOrders(data, ""); err == nil {
+		t.Fatal("expected an error without manuscript text")
+	}
+}
@@ -0,0 +1,144 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/workspace"
+)
+
+// RescoreProject weighs the latest run of the project with id in the default
+// workspace again under the project's current settings; see RescoreRun.
+func RescoreProject(id string) (DashboardData, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return DashboardData{}, err
+	}
+	projectRoot, err := workspace.ProjectRoot(root, id)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	return RescoreRun(projectRoot)
+}
+
+// RescoreRun recomputes the AI-detection and MHD scores of the project's
+// latest run from the window signals it stored, under the AI sensitivity and
+// weighting in force now. Nothing is extracted again and no model or
+// LanguageTool is called, so a changed preset applies in seconds. The result
+// is saved as a new run and becomes the project's report.
+func RescoreRun(projectRoot string) (DashboardData, error) {
+	runs, err := workspace.ListRuns(projectRoot)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	if len(runs) == 0 {
+		return DashboardData{}, fmt.Errorf("project has no saved runs to re-score")
+	}
+	from := runs[len(runs)-1]
+	var data DashboardData
+	if err := workspace.LoadRun(projectRoot, from, &data); err != nil {
+		return DashboardData{}, err
+	}
+	dbPath := workspace.ProjectDBPath(projectRoot)
+	aiEnabled := data.Sections[SectionAIDetection] == SectionStatusEnabled
+	var inputs []aidetect.WindowInputs
+	if aiEnabled {
+		inputs, err = db.LoadWindowSignals(dbPath, from)
+		if err != nil {
+			return DashboardData{}, err
+		}
+		if len(inputs) == 0 && len(data.AIReport.Windows) > 0 {
+			return DashboardData{}, fmt.Errorf("run %s stored no AI window signals; analyze the manuscript again to re-score it", from)
+		}
+	}
+	settings, err := workspace.LoadProjectSettings(projectRoot)
+	if err != nil {
+		return DashboardData{}, err
+	}
+
+	started := time.Now()
+	runID := "run-" + started.Format("20060102-150405.000")
+	logLine := func(level, stage, message, detail string) {
+		data.Logs = append(data.Logs, LogLine{Time: time.Now().Format("15:04:05.000"), Level: level, Stage: stage, Message: message, Detail: detail})
+	}
+	sensitivity, source := resolveAISensitivity(context.Background(), settings)
+	cfg := aidetect.ConfigForSensitivity(sensitivity)
+	cfg.Calibration = aiCalibration(data.GenreScores)
+	cfg = profileAIConfig(data.RunStats.Profile, cfg)
+	if len(inputs) > 0 {
+		before := aiPtr(data.AIReport.PAIDoc)
+		aidetect.Rescore(&data.AIReport, inputs, cfg)
+		logLine("INFO", "SCORING", "AI likelihood re-scored from stored signals", fmt.Sprintf("from=%s preset=%s source=%s weighting=%s windows=%d p_ai_doc=%.3f->%.3f", from, cfg.Sensitivity, source, cfg.Weighting.Name, len(inputs), before, aiPtr(data.AIReport.PAIDoc)))
+	}
+	previous := data.MHDScore
+	data.ScoreBreakdown = computeMHDScore(data.HealthIssues, data.SlopReport, data.Language, data.AIReport, data.DraftMarkers, aiEnabled)
+	data.MHDScore = data.ScoreBreakdown.Score
+	logLine("INFO", "SCORING", "MHD score re-scored", fmt.Sprintf("%d -> %d ai_penalty=%d", previous, data.MHDScore, data.ScoreBreakdown.Points(ScoreComponentAI)))
+	applyQualityGates(&data, settings.GateRules(), logLine)
+
+	data.RunStats.RunID = runID
+	data.RunStats.LastAction = "Re-score"
+	data.RunStats.StartedAt = started.Format(time.RFC3339)
+	data.RunStats.CompletedAt = time.Now().Format(time.RFC3339)
+	data.RunStats.RescoredFrom = from
+	if len(inputs) > 0 {
+		if err := db.RecordWindowSignals(dbPath, runID, inputs); err != nil {
+			logLine("RISK", "REPORT", "AI window signals not recorded; the run cannot be re-scored", err.Error())
+		}
+	}
+	if err := db.RecordRun(dbPath, projectRunRecord(data)); err != nil {
+		logLine("RISK", "REPORT", "Run not recorded in the project database", err.Error())
+	}
+	if workspaceRoot, ok := projectWorkspaceRoot(projectRoot); ok {
+		data.Benchmarks = libraryBenchmarks(workspaceRoot, filepath.Base(projectRoot), data, logLine)
+	}
+
+	reportPath := filepath.Join(projectRoot, "report.json")
+	if err := updateRescoredReport(reportPath, data, cfg); err != nil {
+		return DashboardData{}, err
+	}
+	check, err := workspace.VerifyReportSource(reportPath)
+	if err != nil {
+		return DashboardData{}, err
+	}
+	data.SourceIntegrity = sourceIntegrity(check)
+	logLine("INFO", "REPORT", "Re-scored run saved", runID+" mhd="+strconv.Itoa(data.MHDScore))
+	if _, err := workspace.SaveRun(projectRoot, runID, data); err != nil {
+		return DashboardData{}, err
+	}
+	return data, nil
+}
+
+// updateRescoredReport rewrites the scores and analysis of report.json for a
+// re-scored run, keeping the provenance of the run that measured the signals
+// apart from the AI-detection settings that now weigh them.
+func updateRescoredReport(reportPath string, data DashboardData, cfg aidetect.Config) error {
+	raw, err := os.ReadFile(reportPath)
+	if err != nil {
+		return fmt.Errorf("read report: %w", err)
+	}
+	var report workspace.Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return fmt.Errorf("decode report: %w", err)
+	}
+	report.MHDScore = data.MHDScore
+	report.Analysis = reportAnalysis(data)
+	if report.Provenance != nil {
+		if report.Provenance.Config == nil {
+			report.Provenance.Config = map[string]any{}
+		}
+		report.Provenance.Config["ai_detection"] = cfg
+		report.Provenance.Config["ai_sensitivity"] = cfg.Sensitivity
+		report.Provenance.Config["ai_weighting"] = cfg.Weighting.Name
+		report.Provenance.Config["rescored_from"] = data.RunStats.RescoredFrom
+	}
+	_, err = workspace.SaveReport(reportPath, report)
+	return err
+}
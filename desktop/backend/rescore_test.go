@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/db"
+	"book_dashboard/internal/workspace"
+)
+
+func TestRescoreRunReweighsStoredSignals(t *testing.T) {
+	t.Setenv("AI_GENRE_CALIBRATION", "0")
+	projectRoot := t.TempDir()
+	paragraph := strings.TrimSpace(strings.Repeat("the sterile corridor hummed with certainty and fear ", 48))
+	text := strings.Join([]string{paragraph, paragraph, strings.Repeat("Rain fell on a quiet harbor where boats rocked. ", 120)}, "\n\n")
+	cfg := aidetect.ConfigForSensitivity(aidetect.SensitivityBalanced)
+	cfg.EnableLanguageTool = false
+	cfg.EnableLMSmoothness = false
+	cfg.EnableSemanticDup = false
+	report := aidetect.Analyze(aidetect.Input{DocumentID: "rescore", Text: text, Language: "en"}, cfg, nil, nil, nil)
+
+	data := DashboardData{
+		BookTitle: "Rescore",
+		AIReport:  report,
+		Language:  LanguageReport{GrammarScore: 100, SpellingScore: 100},
+		Sections:  map[string]string{SectionAIDetection: SectionStatusEnabled},
+		RunStats:  RunStats{RunID: "run-20260101-000000.000", Profile: ProfileQuick},
+	}
+	data.MHDScore = computeMHDScore(nil, data.SlopReport, data.Language, report, DraftMarkerReport{}, true).Score
+	if _, err := workspace.SaveRun(projectRoot, data.RunStats.RunID, data); err != nil {
+		t.Fatalf("save run: %v", err)
+	}
+	dbPath := workspace.ProjectDBPath(projectRoot)
+	if err := db.RecordWindowSignals(dbPath, data.RunStats.RunID, report.Inputs()); err != nil {
+		t.Fatalf("record signals: %v", err)
+	}
+	reportPath := filepath.Join(projectRoot, "report.json")
+	if _, err := workspace.SaveReport(reportPath, workspace.Report{BookTitle: "Rescore", MHDScore: data.MHDScore, Provenance: &workspace.Provenance{Config: map[string]any{"ai_sensitivity": "balanced"}}}); err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	if err := workspace.SaveProjectSettings(projectRoot, workspace.ProjectSettings{AISensitivity: aidetect.SensitivityConservative}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	rescored, err := RescoreRun(projectRoot)
+	if err != nil {
+		t.Fatalf("rescore: %v", err)
+	}
+	if rescored.RunStats.RescoredFrom != data.RunStats.RunID || rescored.AIReport.Sensitivity != aidetect.SensitivityConservative {
+		t.Fatalf("expected a conservative re-score of the first run, got %+v sensitivity=%s", rescored.RunStats, rescored.AIReport.Sensitivity)
+	}
+	if *rescored.AIReport.PAIDoc >= *report.PAIDoc {
+		t.Fatalf("expected the conservative preset to lower p_ai_doc from %.3f, got %.3f", *report.PAIDoc, *rescored.AIReport.PAIDoc)
+	}
+	want := computeMHDScore(nil, data.SlopReport, data.Language, rescored.AIReport, DraftMarkerReport{}, true).Score
+	if rescored.MHDScore != want {
+		t.Fatalf("expected the MHD score recomputed to %d, got %d", want, rescored.MHDScore)
+	}
+
+	runs, err := workspace.ListRuns(projectRoot)
+	if err != nil || len(runs) != 2 || runs[1] != rescored.RunStats.RunID {
+		t.Fatalf("expected the re-score saved as a second run, got %v %v", runs, err)
+	}
+	signals, err := db.LoadWindowSignals(dbPath, rescored.RunStats.RunID)
+	if err != nil || len(signals) != len(report.Windows) {
+		t.Fatalf("expected the signals carried to the new run, got %d %v", len(signals), err)
+	}
+	recorded, err := db.ListRuns(dbPath, 1)
+	if err != nil || len(recorded) != 1 || recorded[0].RunID != rescored.RunStats.RunID || recorded[0].MHDScore != rescored.MHDScore {
+		t.Fatalf("expected the re-scored run recorded in the project database, got %+v %v", recorded, err)
+	}
+	raw, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var saved workspace.Report
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if saved.MHDScore != rescored.MHDScore || saved.Provenance.Config["ai_sensitivity"] != aidetect.SensitivityConservative || saved.Provenance.Config["rescored_from"] != data.RunStats.RunID {
+		t.Fatalf("expected report.json updated, got mhd=%d config=%v", saved.MHDScore, saved.Provenance.Config)
+	}
+}
+
+func TestRescoreRunNeedsStoredSignals(t *testing.T) {
+	projectRoot := t.TempDir()
+	data := DashboardData{
+		AIReport: aidetect.Report{Windows: []aidetect.WindowReport{{WindowID: "w1"}}},
+		Sections: map[string]string{SectionAIDetection: SectionStatusEnabled},
+	}
+	if _, err := workspace.SaveRun(projectRoot, "run-20260101-000000.000", data); err != nil {
+		t.Fatalf("save run: %v", err)
+	}
+	if _, err := RescoreRun(projectRoot); err == nil || !strings.Contains(err.Error(), "analyze the manuscript again") {
+		t.Fatalf("expected a run without stored signals refused, got %v", err)
+	}
+}
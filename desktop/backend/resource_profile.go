@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"book_dashboard/internal/resources"
+)
+
+// resourceSampleInterval is resources.DefaultInterval unless
+// MHD_RESOURCE_SAMPLE_INTERVAL names another positive duration.
+func resourceSampleInterval() time.Duration {
+	if raw := strings.TrimSpace(os.Getenv("MHD_RESOURCE_SAMPLE_INTERVAL")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+	}
+	return resources.DefaultInterval
+}
+
+// logResourceProfile records the run's peaks, and a RISK line when the
+// machine was under sustained CPU or memory pressure.
+func logResourceProfile(addLog func(level, stage, message, detail string), p resources.Profile) {
+	detail := fmt.Sprintf("samples=%d app_rss=%.0fMB heap=%.0fMB ollama_rss=%.0fMB", p.Samples, p.PeakProcessRSSMB, p.PeakHeapMB, p.PeakOllamaRSSMB)
+	if p.Known {
+		detail += fmt.Sprintf(" app_cpu=%.0f%% system_cpu=%.0f%% (mean %.0f%%) system_mem=%.0f%% of %.0fMB", p.MaxProcessCPU, p.MaxSystemCPU, p.MeanSystemCPU, p.MaxSystemMemPercent, p.SystemMemTotalMB)
+	}
+	addLog("INFO", "RESOURCES", "Resource profile captured", detail)
+	if p.Pressure == resources.PressureHigh {
+		addLog("RISK", "RESOURCES", "Machine under heavy load during run", fmt.Sprintf("mean system CPU %.0f%%, peak memory %.0f%%; close other apps or use a smaller Ollama model", p.MeanSystemCPU, p.MaxSystemMemPercent))
+	}
+}
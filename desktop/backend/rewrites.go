@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+const rewriteSuggestionLabel = "Machine suggestion: review before use. Suggestions are never applied to the manuscript automatically."
+
+const (
+	maxRewriteSuggestions = 2
+	maxRewriteWords       = 120
+)
+
+// RewriteSuggestion pairs the flagged passage with model-generated
+// alternatives. It is display-only; nothing writes it back to the source.
+type RewriteSuggestion struct {
+	FindingID   string   `json:"findingId"`
+	Label       string   `json:"label"`
+	Original    string   `json:"original"`
+	Suggestions []string `json:"suggestions"`
+	Provider    string   `json:"provider"`
+	Notes       []string `json:"notes"`
+}
+
+type rewriteLLMResult struct {
+	Suggestions []string `json:"suggestions"`
+}
+
+// SuggestRewrites asks the local model for up to two meaning-preserving
+// rewrites of the passage behind a slop or AI finding. AI windows supply their
+// own evidence span; slop flags are document-level, so the caller passes the
+// passage the editor selected. A non-empty passage always takes precedence.
+func SuggestRewrites(data DashboardData, text, findingID, passage string) (RewriteSuggestion, error) {
+	requested := strings.TrimSpace(findingID)
+	findingID = resolveFindingID(data, requested)
+	out := RewriteSuggestion{FindingID: requested, Label: rewriteSuggestionLabel, Suggestions: []string{}, Notes: []string{}}
+	if !strings.HasPrefix(findingID, "w-") && !strings.HasPrefix(findingID, "slop-") {
+		return out, fmt.Errorf("rewrites are only offered for slop and AI findings, got %q", findingID)
+	}
+	finding, err := resolveFinding(data, text, findingID)
+	if err != nil {
+		return out, err
+	}
+	out.Original = strings.TrimSpace(passage)
+	if out.Original == "" && finding.Kind == FindingAIWindow {
+		out.Original = aiWindowPassage(data, text, findingID)
+	}
+	if out.Original == "" {
+		return out, fmt.Errorf("finding %s has no text span; select a passage to rewrite", findingID)
+	}
+	out.Original = firstWords(out.Original, maxRewriteWords)
+
+	model := ollamaModel("OLLAMA_REWRITE_MODEL", "OLLAMA_LANGUAGE_MODEL")
+	prompt := "You are a careful line editor. Rewrite the passage to address the issue while preserving its meaning, point of view, tense and facts. " +
+		fmt.Sprintf("Offer %d distinct alternatives. Return JSON only: {\"suggestions\":[\"...\"]}.\n\n", maxRewriteSuggestions) +
+		"ISSUE: " + finding.Title + "\n\nPASSAGE:\n" + out.Original
+	var parsed rewriteLLMResult
+	if err := generateOllamaJSON(model, prompt, &parsed); err != nil {
+		out.Provider = "unavailable"
+		out.Notes = append(out.Notes, "Ollama rewrite unavailable: "+err.Error())
+		return out, nil
+	}
+	out.Provider = "ollama:" + model
+	for _, s := range parsed.Suggestions {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.EqualFold(s, out.Original) {
+			continue
+		}
+		out.Suggestions = append(out.Suggestions, s)
+		if len(out.Suggestions) == maxRewriteSuggestions {
+			break
+		}
+	}
+	if len(out.Suggestions) == 0 {
+		out.Notes = append(out.Notes, "The model returned no usable rewrite.")
+	}
+	return out, nil
+}
+
+// aiWindowPassage prefers the first evidence span and falls back to the
+// opening of the window.
+func aiWindowPassage(data DashboardData, text, windowID string) string {
+	for _, w := range data.AIReport.Windows {
+		if w.WindowID != windowID {
+			continue
+		}
+		for _, e := range w.TopEvidence {
+			for _, span := range e.Spans {
+				if excerpt := wordSpanExcerpt(text, span.Start, span.End, maxRewriteWords); excerpt != "" {
+					return excerpt
+				}
+			}
+		}
+		return wordSpanExcerpt(text, w.StartWord, w.EndWord, maxRewriteWords)
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"testing"
+
+	"book_dashboard/internal/slop"
+)
+
+func TestSuggestRewritesRequiresSpanAndLabelsOutput(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	data := DashboardData{}
+	data.SlopReport.Flags = []slop.Flag{{Code: slop.FlagMonotone, Text: "Monotone sentence rhythm"}}
+
+	if _, err := SuggestRewrites(data, "", "slop-1", ""); err == nil {
+		t.Fatal("expected slop finding without a passage to be rejected")
+	}
+	if _, err := SuggestRewrites(data, "", "issue-001", "text"); err == nil {
+		t.Fatal("expected non-slop, non-AI finding to be rejected")
+	}
+	out, err := SuggestRewrites(data, "", "slop-1", "It was very, very dark and very cold.")
+	if err != nil {
+		t.Fatalf("suggest rewrites: %v", err)
+	}
+	if out.Label == "" || out.Original == "" || len(out.Suggestions) != 0 || out.Provider != "unavailable" {
+		t.Fatalf("expected labeled passage without suggestions when model is down, got %+v", out)
+	}
+}
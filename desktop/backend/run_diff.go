@@ -0,0 +1,229 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/slop"
+	"book_dashboard/internal/workspace"
+)
+
+// Which way a score must move to count as an improvement.
+const (
+	BetterHigher = "higher"
+	BetterLower  = "lower"
+)
+
+// RunDiff compares two saved runs of a project, A before and B after a
+// revision, so an author can see whether manuscript health improved.
+type RunDiff struct {
+	ProjectID string       `json:"projectId"`
+	RunA      string       `json:"runA"`
+	RunB      string       `json:"runB"`
+	Scores    []ScoreDelta `json:"scores"`
+	// NewContradictions are in B only and ResolvedContradictions in A only.
+	// Contradictions match on entity, attribute and values, not chapters, so
+	// one that moved with a chapter is neither.
+	NewContradictions      []forensics.Contradiction `json:"newContradictions"`
+	ResolvedContradictions []forensics.Contradiction `json:"resolvedContradictions"`
+	NewSlopFlags           []string                  `json:"newSlopFlags"`
+	ResolvedSlopFlags      []string                  `json:"resolvedSlopFlags"`
+	// ChangedSlopFlags were raised by both runs with different figures.
+	ChangedSlopFlags []SlopFlagChange `json:"changedSlopFlags"`
+	// NewIssues are health issues in B only and ResolvedIssues in A only.
+	// Issues match on their anchor IDs, so one whose chapter moved or whose
+	// issue number changed is neither.
+	NewIssues      []HealthIssue `json:"newIssues"`
+	ResolvedIssues []HealthIssue `json:"resolvedIssues"`
+}
+
+// ScoreDelta is one figure in both runs. Better is empty for figures, such as
+// word count, that are neither good nor bad; Improved is then always false.
+type ScoreDelta struct {
+	Name     string  `json:"name"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+	Delta    float64 `json:"delta"`
+	Better   string  `json:"better"`
+	Improved bool    `json:"improved"`
+}
+
+// SlopFlagChange is a slop flag both runs raised, worded differently.
+type SlopFlagChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// ListProjectRuns returns the ids of the saved runs of the project with id in
+// the default workspace, oldest first.
+func ListProjectRuns(id string) ([]string, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return nil, err
+	}
+	projectRoot, err := workspace.ProjectRoot(root, id)
+	if err != nil {
+		return nil, err
+	}
+	return workspace.ListRuns(projectRoot)
+}
+
+// CompareRuns diffs two saved runs of the project with id in the default
+// workspace.
+func CompareRuns(id, runA, runB string) (RunDiff, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return RunDiff{}, err
+	}
+	projectRoot, err := workspace.ProjectRoot(root, id)
+	if err != nil {
+		return RunDiff{}, err
+	}
+	var a, b DashboardData
+	if err := workspace.LoadRun(projectRoot, runA, &a); err != nil {
+		return RunDiff{}, err
+	}
+	if err := workspace.LoadRun(projectRoot, runB, &b); err != nil {
+		return RunDiff{}, err
+	}
+	diff := compareRuns(a, b)
+	diff.ProjectID, diff.RunA, diff.RunB = id, runA, runB
+	return diff, nil
+}
+
+func compareRuns(a, b DashboardData) RunDiff {
+	diff := RunDiff{
+		NewContradictions:      []forensics.Contradiction{},
+		ResolvedContradictions: []forensics.Contradiction{},
+		NewSlopFlags:           []string{},
+		ResolvedSlopFlags:      []string{},
+		ChangedSlopFlags:       []SlopFlagChange{},
+		NewIssues:              []HealthIssue{},
+		ResolvedIssues:         []HealthIssue{},
+	}
+	score := func(name string, before, after float64, better string) {
+		d := ScoreDelta{Name: name, Before: before, After: after, Delta: after - before, Better: better}
+		d.Improved = (better == BetterHigher && d.Delta > 0) || (better == BetterLower && d.Delta < 0)
+		diff.Scores = append(diff.Scores, d)
+	}
+	score("MHD score", float64(a.MHDScore), float64(b.MHDScore), BetterHigher)
+	score("Word count", float64(a.WordCount), float64(b.WordCount), "")
+	score("Chapters", float64(a.ChapterCount), float64(b.ChapterCount), "")
+	score("Spelling", float64(a.Language.SpellingScore), float64(b.Language.SpellingScore), BetterHigher)
+	score("Grammar", float64(a.Language.GrammarScore), float64(b.Language.GrammarScore), BetterHigher)
+	score("Readability", float64(a.Language.ReadabilityScore), float64(b.Language.ReadabilityScore), BetterHigher)
+	score("Slop AI suspicion", float64(a.SlopReport.AISuspicionScore), float64(b.SlopReport.AISuspicionScore), BetterLower)
+	if a.AIReport.PAIDoc != nil && b.AIReport.PAIDoc != nil {
+		score("AI probability", *a.AIReport.PAIDoc*100, *b.AIReport.PAIDoc*100, BetterLower)
+	}
+	score("Contradictions", float64(len(a.Contradictions)), float64(len(b.Contradictions)), BetterLower)
+	score("Health issues", float64(len(a.HealthIssues)), float64(len(b.HealthIssues)), BetterLower)
+	score("Slop flags", float64(len(a.SlopReport.Flags)), float64(len(b.SlopReport.Flags)), BetterLower)
+
+	before := map[string]int{}
+	for _, c := range a.Contradictions {
+		before[contradictionKey(c)]++
+	}
+	for _, c := range b.Contradictions {
+		key := contradictionKey(c)
+		if before[key] > 0 {
+			before[key]--
+			continue
+		}
+		diff.NewContradictions = append(diff.NewContradictions, c)
+	}
+	after := map[string]int{}
+	for _, c := range b.Contradictions {
+		after[contradictionKey(c)]++
+	}
+	for _, c := range a.Contradictions {
+		key := contradictionKey(c)
+		if after[key] > 0 {
+			after[key]--
+			continue
+		}
+		diff.ResolvedContradictions = append(diff.ResolvedContradictions, c)
+	}
+
+	flagsA := map[string]string{}
+	for _, f := range a.SlopReport.Flags {
+		flagsA[slopFlagKey(f)] = f.Text
+	}
+	flagsB := map[string]string{}
+	for _, f := range b.SlopReport.Flags {
+		key := slopFlagKey(f)
+		flagsB[key] = f.Text
+		was, ok := flagsA[key]
+		switch {
+		case !ok:
+			diff.NewSlopFlags = append(diff.NewSlopFlags, f.Text)
+		case was != f.Text:
+			diff.ChangedSlopFlags = append(diff.ChangedSlopFlags, SlopFlagChange{Before: was, After: f.Text})
+		}
+	}
+	for _, f := range a.SlopReport.Flags {
+		if _, ok := flagsB[slopFlagKey(f)]; !ok {
+			diff.ResolvedSlopFlags = append(diff.ResolvedSlopFlags, f.Text)
+		}
+	}
+
+	anchorsA, anchorsB := issueAnchorIDs(a.HealthIssues), issueAnchorIDs(b.HealthIssues)
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, id := range anchorsA {
+		inA[id] = true
+	}
+	for _, id := range anchorsB {
+		inB[id] = true
+	}
+	for i, issue := range b.HealthIssues {
+		if !inA[anchorsB[i]] {
+			diff.NewIssues = append(diff.NewIssues, issue)
+		}
+	}
+	for i, issue := range a.HealthIssues {
+		if !inB[anchorsA[i]] {
+			diff.ResolvedIssues = append(diff.ResolvedIssues, issue)
+		}
+	}
+	return diff
+}
+
+// issueAnchorIDs returns the anchor ID of each issue, fingerprinting the
+// issues of runs saved before anchors were recorded.
+func issueAnchorIDs(issues []HealthIssue) []string {
+	out := make([]string, len(issues))
+	seen := map[string]int{}
+	for i, issue := range issues {
+		out[i] = issue.AnchorID
+		if out[i] == "" {
+			out[i] = uniqueAnchor(seen, healthIssueAnchor(issue))
+		}
+	}
+	return out
+}
+
+// contradictionKey identifies a contradiction across revisions: the same
+// entity and attribute with the same pair of values, in either order.
+func contradictionKey(c forensics.Contradiction) string {
+	values := []string{strings.ToLower(strings.TrimSpace(c.ValueA)), strings.ToLower(strings.TrimSpace(c.ValueB))}
+	sort.Strings(values)
+	return fmt.Sprintf("%s|%s|%s|%s", strings.ToLower(strings.TrimSpace(c.EntityName)), strings.ToLower(c.Attribute), values[0], values[1])
+}
+
+// slopFlagKey identifies a slop flag across runs by its code, or by its
+// label when an old report left the flag's code unknown.
+func slopFlagKey(f slop.Flag) string {
+	if f.Code != "" {
+		return f.Code
+	}
+	return slopFlagLabel(f.Text)
+}
+
+// slopFlagLabel is the part of a slop flag before its figures, such as
+// "Low Originality" in "Low Originality: 4.2% of trigrams ...".
+func slopFlagLabel(flag string) string {
+	label, _, _ := strings.Cut(flag, ":")
+	return strings.TrimSpace(label)
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"testing"
+
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/slop"
+)
+
+func TestCompareRunsReportsScoreDeltasAndChangedFindings(t *testing.T) {
+	before := DashboardData{
+		MHDScore:  62,
+		WordCount: 80000,
+		Contradictions: []forensics.Contradiction{
+			{EntityName: "Mara", Attribute: "eye_color", ValueA: "green", ValueB: "brown", ChapterA: 2, ChapterB: 9},
+			{EntityName: "Tom", Attribute: "age", ValueA: "30", ValueB: "35", ChapterA: 1, ChapterB: 4},
+		},
+		SlopReport: slop.Report{AISuspicionScore: 40, Flags: []slop.Flag{
+			{Code: slop.FlagMonotone, Text: "Monotone: sentence-length variability is unusually low"},
+			{Code: slop.FlagLowOriginality, Text: "Low Originality: 6.0% of trigrams are stock thriller phrasing"},
+		}},
+	}
+	after := DashboardData{
+		MHDScore:  70,
+		WordCount: 81000,
+		Contradictions: []forensics.Contradiction{
+			// The same contradiction, moved with a chapter and reported the
+			// other way round.
+			{EntityName: "mara", Attribute: "eye_color", ValueA: "Brown", ValueB: "green", ChapterA: 3, ChapterB: 10},
+			{EntityName: "Jon", Attribute: "hair", ValueA: "red", ValueB: "black", ChapterA: 5, ChapterB: 6},
+		},
+		SlopReport: slop.Report{AISuspicionScore: 25, Flags: []slop.Flag{
+			{Code: slop.FlagLowOriginality, Text: "Low Originality: 4.5% of trigrams are stock thriller phrasing"},
+			{Code: slop.FlagRedFlagVocabulary, Text: "High red-flag vocabulary density"},
+		}},
+	}
+	diff := compareRuns(before, after)
+
+	scores := map[string]ScoreDelta{}
+	for _, s := range diff.Scores {
+		scores[s.Name] = s
+	}
+	if s := scores["MHD score"]; s.Delta != 8 || !s.Improved {
+		t.Fatalf("expected the MHD score up 8 and improved, got %+v", s)
+	}
+	if s := scores["Slop AI suspicion"]; s.Delta != -15 || !s.Improved {
+		t.Fatalf("expected lower AI suspicion to count as improved, got %+v", s)
+	}
+	if s := scores["Word count"]; s.Delta != 1000 || s.Improved || s.Better != "" {
+		t.Fatalf("expected word count to be neutral, got %+v", s)
+	}
+	if _, ok := scores["AI probability"]; ok {
+		t.Fatal("expected AI probability left out when neither run scored it")
+	}
+	if len(diff.NewContradictions) != 1 || diff.NewContradictions[0].EntityName != "Jon" {
+		t.Fatalf("expected Jon's hair as the new contradiction, got %+v", diff.NewContradictions)
+	}
+	if len(diff.ResolvedContradictions) != 1 || diff.ResolvedContradictions[0].EntityName != "Tom" {
+		t.Fatalf("expected Tom's age resolved, got %+v", diff.ResolvedContradictions)
+	}
+	if len(diff.NewSlopFlags) != 1 || diff.NewSlopFlags[0] != "High red-flag vocabulary density" {
+		t.Fatalf("expected the vocabulary flag as new, got %v", diff.NewSlopFlags)
+	}
+	if len(diff.ResolvedSlopFlags) != 1 || slopFlagLabel(diff.ResolvedSlopFlags[0]) != "Monotone" {
+		t.Fatalf("expected the monotone flag resolved, got %v", diff.ResolvedSlopFlags)
+	}
+	if len(diff.ChangedSlopFlags) != 1 || diff.ChangedSlopFlags[0].After != after.SlopReport.Flags[0].Text {
+		t.Fatalf("expected the originality flag changed, got %+v", diff.ChangedSlopFlags)
+	}
+}
+
+func TestCompareRunsMatchesIssuesByAnchor(t *testing.T) {
+	drift := HealthIssue{ID: "issue-001", Kind: HealthIssueTenseDrift, ChapterA: 2, ChapterB: 2, ContextA: "She ran.", ContextB: "She runs."}
+	stub := HealthIssue{ID: "issue-002", Kind: HealthIssueFragment, ChapterA: 7, ContextA: "TODO: finish"}
+	before := DashboardData{HealthIssues: []HealthIssue{drift, stub}}
+
+	// The revision fixed the stub, moved the drift a chapter on and raised
+	// a new issue ahead of it, so the issue numbers no longer line up.
+	moved := drift
+	moved.ID, moved.ChapterA, moved.ChapterB = "issue-002", 3, 3
+	pov := HealthIssue{ID: "issue-001", Kind: HealthIssuePOVDrift, ChapterA: 1, ContextA: "I knew he lied."}
+	after := DashboardData{HealthIssues: []HealthIssue{pov, moved}}
+	anchorFindings(&after, "")
+
+	diff := compareRuns(before, after)
+	if len(diff.NewIssues) != 1 || diff.NewIssues[0].Kind != HealthIssuePOVDrift {
+		t.Fatalf("expected the POV drift as the only new issue, got %+v", diff.NewIssues)
+	}
+	if len(diff.ResolvedIssues) != 1 || diff.ResolvedIssues[0].Kind != HealthIssueFragment {
+		t.Fatalf("expected the stub resolved, got %+v", diff.ResolvedIssues)
+	}
+}
@@ -0,0 +1,61 @@
+package backend
+
+import "book_dashboard/internal/db"
+
+// projectRunRecord flattens a finished run's dashboard into the rows the
+// project database keeps for querying across runs.
+func projectRunRecord(data DashboardData) db.RunRecord {
+	run := db.RunRecord{
+		RunID:          data.RunStats.RunID,
+		Title:          data.BookTitle,
+		SourceName:     data.RunStats.SourceName,
+		Status:         data.RunStats.Status,
+		Profile:        data.RunStats.Profile,
+		WordCount:      data.WordCount,
+		ChapterCount:   data.ChapterCount,
+		MHDScore:       data.MHDScore,
+		PAIDoc:         data.AIReport.PAIDoc,
+		StartedAt:      data.RunStats.StartedAt,
+		CompletedAt:    data.RunStats.CompletedAt,
+		Timeline:       data.Timeline,
+		AIWindows:      data.AIReport.Windows,
+		Contradictions: data.Contradictions,
+	}
+	for _, c := range data.ChapterMetrics {
+		run.Chapters = append(run.Chapters, db.ChapterRecord{
+			Index:         c.Index,
+			Title:         c.Title,
+			WordCount:     c.WordCount,
+			TimelineMarks: c.TimelineMarks,
+			TopGenre:      c.TopGenre,
+			TopGenreScore: c.TopGenreScore,
+		})
+	}
+	for _, c := range data.CharacterDictionary {
+		run.Characters = append(run.Characters, db.CharacterRecord{
+			Name:             c.Name,
+			FirstSeenChapter: c.FirstSeenChapter,
+			LastSeenChapter:  c.LastSeenChapter,
+			TotalMentions:    c.TotalMentions,
+		})
+	}
+	lang := data.Language
+	run.Language = append(run.Language, db.LanguageScore{
+		Spelling:    lang.SpellingScore,
+		Grammar:     lang.GrammarScore,
+		Readability: lang.ReadabilityScore,
+		AgeCategory: lang.AgeCategory,
+		Profanity:   lang.ProfanityScore,
+		Explicit:    lang.ExplicitScore,
+		Violence:    lang.ViolenceScore,
+	})
+	for _, c := range lang.Chapters {
+		run.Language = append(run.Language, db.LanguageScore{
+			Chapter:     c.Chapter,
+			Spelling:    c.SpellingScore,
+			Grammar:     c.GrammarScore,
+			Readability: c.ReadabilityScore,
+		})
+	}
+	return run
+}
@@ -0,0 +1,48 @@
+package backend
+
+import (
+	"fmt"
+
+	"book_dashboard/internal/resources"
+	"book_dashboard/internal/scheduler"
+)
+
+// Ceilings for the run's adaptive limiters. Each starts at one call in flight
+// and grows toward its ceiling only while latency and system load stay low.
+// Ollama serves up to four requests in parallel by default; LanguageTool's
+// server runs checks on a thread pool of about the same size.
+const (
+	maxAIWindowWorkers     = 4
+	maxGenreModelWorkers   = 4
+	maxLanguageToolWorkers = 4
+)
+
+// runLimiters size a run's concurrent calls to local services.
+type runLimiters struct {
+	aiWindows    *scheduler.Limiter
+	genreModel   *scheduler.Limiter
+	languageTool *scheduler.Limiter
+}
+
+func newRunLimiters(monitor *resources.Monitor) runLimiters {
+	return runLimiters{
+		aiWindows:    scheduler.New("ai_windows", maxAIWindowWorkers, monitor.Pressure),
+		genreModel:   scheduler.New("genre_model", maxGenreModelWorkers, monitor.Pressure),
+		languageTool: scheduler.New("languagetool_chapters", maxLanguageToolWorkers, monitor.Pressure),
+	}
+}
+
+// stats lists limiters that made calls this run.
+func (r runLimiters) stats() []scheduler.Stats {
+	out := []scheduler.Stats{}
+	for _, l := range []*scheduler.Limiter{r.aiWindows, r.genreModel, r.languageTool} {
+		if s := l.Stats(); s.Calls > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func describeConcurrency(s scheduler.Stats) string {
+	return fmt.Sprintf("%s: calls=%d peak=%d/%d final=%d backoffs=%d errors=%d mean_ms_per_unit=%.1f", s.Name, s.Calls, s.PeakWorkers, s.MaxWorkers, s.FinalWorkers, s.Backoffs, s.Errors, s.MeanLatencyMs)
+}
@@ -0,0 +1,150 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/slop"
+)
+
+// Score components, in the order the breakdown lists them.
+const (
+	ScoreComponentHealthIssues = "health_issues"
+	ScoreComponentSlopFlags    = "slop_flags"
+	ScoreComponentGrammar      = "grammar"
+	ScoreComponentSpelling     = "spelling"
+	ScoreComponentAI           = "ai_likelihood"
+	ScoreComponentDraftMarkers = "draft_markers"
+)
+
+const (
+	mhdScoreBase         = 100
+	healthIssuePenalty   = 10
+	slopFlagPenalty      = 6
+	languagePenaltyShare = 5
+	maxAIPenalty         = 70
+)
+
+// ScoreBreakdown explains the MHD score: it starts at Base and each
+// component takes off its Points. The score stops at 0, so Deducted can be
+// more than Base.
+type ScoreBreakdown struct {
+	Base       int            `json:"base"`
+	Score      int            `json:"score"`
+	Deducted   int            `json:"deducted"`
+	Components []ScorePenalty `json:"components"`
+}
+
+// ScorePenalty is one component of the score. Input is what it measured
+// (issues, flags, points below 100 or markers) and Weight the points one unit
+// of it costs; the AI-likelihood penalty is a formula of several signals, so
+// it has no weight and its Detail spells the terms out.
+type ScorePenalty struct {
+	Component string  `json:"component"`
+	Label     string  `json:"label"`
+	Input     float64 `json:"input"`
+	Weight    float64 `json:"weight,omitempty"`
+	Points    int     `json:"points"`
+	Detail    string  `json:"detail"`
+}
+
+func emptyScoreBreakdown() ScoreBreakdown {
+	return ScoreBreakdown{Base: mhdScoreBase, Components: []ScorePenalty{}}
+}
+
+// Points returns what component took off the score.
+func (b ScoreBreakdown) Points(component string) int {
+	for _, c := range b.Components {
+		if c.Component == component {
+			return c.Points
+		}
+	}
+	return 0
+}
+
+// computeMHDScore folds the health issues, slop flags, language scores, AI
+// likelihood and draft markers into the MHD score, component by component.
+func computeMHDScore(healthIssues []HealthIssue, slopReport slop.Report, language LanguageReport, aiReport aidetect.Report, draftMarkers DraftMarkerReport, aiEnabled bool) ScoreBreakdown {
+	b := emptyScoreBreakdown()
+	add := func(p ScorePenalty) {
+		b.Components = append(b.Components, p)
+		b.Deducted += p.Points
+	}
+	add(ScorePenalty{
+		Component: ScoreComponentHealthIssues, Label: "Consistency issues", Input: float64(len(healthIssues)), Weight: healthIssuePenalty,
+		Points: len(healthIssues) * healthIssuePenalty, Detail: fmt.Sprintf("%d issue(s) × %d", len(healthIssues), healthIssuePenalty),
+	})
+	add(ScorePenalty{
+		Component: ScoreComponentSlopFlags, Label: "Slop flags", Input: float64(len(slopReport.Flags)), Weight: slopFlagPenalty,
+		Points: len(slopReport.Flags) * slopFlagPenalty, Detail: fmt.Sprintf("%d flag(s) × %d", len(slopReport.Flags), slopFlagPenalty),
+	})
+	for _, l := range []struct {
+		component, label string
+		score            int
+	}{
+		{ScoreComponentGrammar, "Grammar", language.GrammarScore},
+		{ScoreComponentSpelling, "Spelling", language.SpellingScore},
+	} {
+		below := 100 - l.score
+		add(ScorePenalty{
+			Component: l.component, Label: l.label, Input: float64(below), Weight: 1.0 / languagePenaltyShare,
+			Points: below / languagePenaltyShare, Detail: fmt.Sprintf("score %d: %d point(s) below 100 ÷ %d", l.score, below, languagePenaltyShare),
+		})
+	}
+	add(aiScorePenalty(slopReport, aiReport, aiEnabled))
+	add(ScorePenalty{
+		Component: ScoreComponentDraftMarkers, Label: "Draft markers", Input: float64(len(draftMarkers.Markers)), Weight: draftMarkerPenalty,
+		Points: draftMarkers.Penalty, Detail: fmt.Sprintf("%d point(s) per TK/TODO/placeholder, at most %d", draftMarkerPenalty, maxDraftMarkerPenalty),
+	})
+	b.Score = max(b.Base-b.Deducted, 0)
+	return b
+}
+
+// aiScorePenalty is the AI-likelihood component: the window scores when the
+// AI stage produced them, or else a fifth of the slop AI-suspicion score when
+// AI detection is enabled.
+func aiScorePenalty(slopReport slop.Report, aiReport aidetect.Report, aiEnabled bool) ScorePenalty {
+	p := ScorePenalty{Component: ScoreComponentAI, Label: "AI likelihood", Detail: "AI detection disabled"}
+	if aiEnabled {
+		p.Input = float64(slopReport.AISuspicionScore)
+		p.Points = slopReport.AISuspicionScore / 5
+		p.Detail = fmt.Sprintf("slop AI-suspicion score %d ÷ 5", slopReport.AISuspicionScore)
+	}
+	if aiReport.PAIDoc == nil || aiReport.AICoverageEst == nil || aiReport.PAIMax == nil {
+		return p
+	}
+	coverageExcess := math.Max(0, *aiReport.AICoverageEst-0.10)
+	points := int(math.Round((*aiReport.PAIMax * 35.0) + (coverageExcess * 40.0)))
+	terms := []string{fmt.Sprintf("peak window %.2f × 35 + coverage above 10%% %.2f × 40", *aiReport.PAIMax, coverageExcess)}
+	if *aiReport.PAIDoc >= 0.85 && (*aiReport.PAIMax >= 0.75 || *aiReport.AICoverageEst >= 0.25) {
+		points += 8
+		terms = append(terms, fmt.Sprintf("+8 document likelihood %.2f", *aiReport.PAIDoc))
+	}
+	if containsString(aiReport.Flags, "ai_chunk_detected") {
+		points += 10
+		terms = append(terms, "+10 AI chunk detected")
+	}
+	if containsString(aiReport.Flags, "widespread_ai_signal") {
+		points += 15
+		terms = append(terms, "+15 widespread AI signal")
+	}
+	if points > maxAIPenalty {
+		points = maxAIPenalty
+		terms = append(terms, fmt.Sprintf("capped at %d", maxAIPenalty))
+	}
+	p.Input, p.Points, p.Detail = *aiReport.PAIDoc, points, strings.Join(terms, ", ")
+	return p
+}
+
+// describeScoreBreakdown is the one-line form the log uses.
+func describeScoreBreakdown(b ScoreBreakdown) string {
+	parts := []string{fmt.Sprintf("%d", b.Base)}
+	for _, c := range b.Components {
+		if c.Points > 0 {
+			parts = append(parts, fmt.Sprintf("- %d %s", c.Points, c.Component))
+		}
+	}
+	return strings.Join(parts, " ") + fmt.Sprintf(" = %d", b.Score)
+}
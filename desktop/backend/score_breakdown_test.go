@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/slop"
+)
+
+func TestComputeMHDScoreBreaksDownEveryPenalty(t *testing.T) {
+	pDoc, coverage, pMax := 0.9, 0.35, 0.8
+	ai := aidetect.Report{PAIDoc: &pDoc, AICoverageEst: &coverage, PAIMax: &pMax, Flags: []string{"ai_chunk_detected"}}
+	issues := []HealthIssue{{ID: "issue-001"}, {ID: "issue-002"}}
+	slopReport := slop.Report{Flags: []slop.Flag{{Code: slop.FlagMonotone}}}
+	language := LanguageReport{GrammarScore: 88, SpellingScore: 95}
+	drafts := DraftMarkerReport{Markers: []DraftMarker{{Kind: "tk"}, {Kind: "todo"}}, Penalty: 4}
+
+	b := computeMHDScore(issues, slopReport, language, ai, drafts, true)
+	// AI: round(0.8*35 + 0.25*40) = 38, +8 document, +10 chunk = 56.
+	want := map[string]int{
+		ScoreComponentHealthIssues: 20,
+		ScoreComponentSlopFlags:    6,
+		ScoreComponentGrammar:      2,
+		ScoreComponentSpelling:     1,
+		ScoreComponentAI:           56,
+		ScoreComponentDraftMarkers: 4,
+	}
+	total := 0
+	for component, points := range want {
+		if got := b.Points(component); got != points {
+			t.Fatalf("%s: expected %d points, got %d (%+v)", component, points, got, b.Components)
+		}
+		total += points
+	}
+	if b.Base != 100 || b.Deducted != total || b.Score != 100-total || len(b.Components) != len(want) {
+		t.Fatalf("unexpected totals %+v", b)
+	}
+	for _, c := range b.Components {
+		if c.Component == ScoreComponentAI && !strings.Contains(c.Detail, "+10 AI chunk detected") {
+			t.Fatalf("expected the AI terms spelled out, got %q", c.Detail)
+		}
+	}
+
+	report := PlainReport(DashboardData{MHDScore: b.Score, ScoreBreakdown: b})
+	if !strings.Contains(report, "## Score breakdown") || !strings.Contains(report, "- Consistency issues: -20 (2 issue(s) × 10)") {
+		t.Fatalf("expected the breakdown in the plain report:\n%s", report)
+	}
+	author := RedactForAuthor(DashboardData{ScoreBreakdown: b})
+	for _, c := range author.ScoreBreakdown.Components {
+		if c.Component == ScoreComponentAI && (c.Detail != "" || c.Points != 56) {
+			t.Fatalf("expected the author copy to keep the AI points without their terms, got %+v", c)
+		}
+	}
+	if b.Points(ScoreComponentAI) != 56 || !strings.Contains(b.Components[4].Detail, "peak window") {
+		t.Fatal("redaction changed the original breakdown")
+	}
+
+	floored := computeMHDScore(make([]HealthIssue, 12), slopReport, language, ai, drafts, true)
+	if floored.Score != 0 || floored.Deducted <= 100 {
+		t.Fatalf("expected the score to stop at 0, got %+v", floored)
+	}
+}
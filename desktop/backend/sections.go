@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"book_dashboard/internal/workspace"
+)
+
+const (
+	SectionAIDetection   = "ai_detection"
+	SectionSafety        = "safety"
+	SectionCompTitles    = "comp_titles"
+	SectionPlotStructure = "plot_structure"
+	SectionSensitivity   = "sensitivity_read"
+
+	SectionStatusEnabled  = "enabled"
+	SectionStatusDisabled = "disabled"
+)
+
+// ReportSections lists the analysis sections a project can switch off.
+var ReportSections = []string{SectionAIDetection, SectionSafety, SectionCompTitles, SectionPlotStructure, SectionSensitivity}
+
+// optInSections are off until a project enables them.
+var optInSections = map[string]bool{SectionSensitivity: true}
+
+func IsReportSection(name string) bool {
+	for _, s := range ReportSections {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sectionStatuses(settings workspace.ProjectSettings) map[string]string {
+	out := make(map[string]string, len(ReportSections))
+	for _, s := range ReportSections {
+		out[s] = SectionStatusEnabled
+		if (optInSections[s] && !settings.SectionOptedIn(s)) || !settings.SectionEnabled(s) {
+			out[s] = SectionStatusDisabled
+		}
+	}
+	return out
+}
+
+func LoadProjectSections(projectLocation string) (map[string]string, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return sectionStatuses(workspace.ProjectSettings{}), nil
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return nil, err
+	}
+	return sectionStatuses(settings), nil
+}
+
+// SetSectionEnabled toggles one report section for the project; the change
+// applies to the next analysis run.
+func SetSectionEnabled(projectLocation, section string, enabled bool) (map[string]string, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return nil, fmt.Errorf("no project loaded")
+	}
+	section = strings.TrimSpace(strings.ToLower(section))
+	if !IsReportSection(section) {
+		return nil, fmt.Errorf("unknown report section %q", section)
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return nil, err
+	}
+	settings.DisabledSections = withoutSection(settings.DisabledSections, section)
+	settings.EnabledSections = withoutSection(settings.EnabledSections, section)
+	switch {
+	case !enabled:
+		settings.DisabledSections = append(settings.DisabledSections, section)
+	case optInSections[section]:
+		settings.EnabledSections = append(settings.EnabledSections, section)
+	}
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return nil, err
+	}
+	return sectionStatuses(settings), nil
+}
+
+func withoutSection(list []string, section string) []string {
+	out := make([]string, 0, len(list)+1)
+	for _, s := range list {
+		if !strings.EqualFold(s, section) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
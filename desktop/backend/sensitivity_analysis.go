@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+const sensitivityDisclaimer = "For human review: these passages may warrant a sensitivity read. Flags are prompts for a reader's judgement, not findings."
+
+const (
+	SensitivityOutdatedTerm = "outdated_term"
+	SensitivityStereotype   = "stereotype"
+	SensitivityDepiction    = "identity_depiction"
+)
+
+const maxSensitivityCandidates = 40
+
+// Loaded or outdated terms that usually merit a second look regardless of
+// context. Phrases are matched against adjacent words.
+var sensitivityTerms = map[string]struct{}{
+	"gypsy": {}, "gypsies": {}, "oriental": {}, "savage": {}, "savages": {}, "crippled": {}, "cripple": {},
+	"retarded": {}, "retard": {}, "midget": {}, "spastic": {}, "mulatto": {}, "half breed": {}, "eskimo": {},
+	"redskin": {}, "illegals": {}, "lunatic": {}, "tranny": {}, "wheelchair bound": {},
+}
+
+var identityTerms = map[string]struct{}{
+	"asian": {}, "african": {}, "arab": {}, "muslim": {}, "jewish": {}, "mexican": {}, "chinese": {},
+	"indian": {}, "native": {}, "immigrant": {}, "immigrants": {}, "gay": {}, "lesbian": {}, "transgender": {},
+	"disabled": {}, "blind": {}, "deaf": {}, "wheelchair": {}, "autistic": {}, "refugee": {}, "refugees": {},
+}
+
+// Generalizing phrases that turn an identity mention into a possible stereotype.
+var stereotypeMarkers = []string{"typical", "always", "all of them", "those people", "their kind", "like all", "exotic", "inscrutable", "primitive", "you people"}
+
+type sensitivityCandidate struct {
+	chapter  chapter
+	category string
+	term     string
+	sentence string
+	context  string
+}
+
+type sensitivityLLMResult struct {
+	Flags []struct {
+		Index     int    `json:"index"`
+		Flag      bool   `json:"flag"`
+		Category  string `json:"category"`
+		Rationale string `json:"rationale"`
+	} `json:"flags"`
+}
+
+// analyzeSensitivity collects candidate sentences lexically and asks the model
+// which of them deserve a sensitivity read. Without the model only outdated
+// terms and stereotype patterns are reported; bare identity mentions are too
+// noisy to flag on their own.
+func analyzeSensitivity(chapters []chapter) SensitivityReport {
+	report := SensitivityReport{Provider: "heuristic", Disclaimer: sensitivityDisclaimer, Flags: []SensitivityFlag{}, Notes: []string{}}
+	candidates := sensitivityCandidates(chapters)
+	if len(candidates) == 0 {
+		return report
+	}
+	if len(candidates) > maxSensitivityCandidates {
+		report.Notes = append(report.Notes, fmt.Sprintf("Reviewed the first %d of %d candidate passages.", maxSensitivityCandidates, len(candidates)))
+		candidates = candidates[:maxSensitivityCandidates]
+	}
+
+	model := ollamaModel("OLLAMA_SENSITIVITY_MODEL", "OLLAMA_LANGUAGE_MODEL")
+	parsed, err := reviewSensitivityWithOllama(model, candidates)
+	if err != nil {
+		report.Notes = append(report.Notes, "Ollama sensitivity review unavailable: "+err.Error())
+		report.Fallback = newFallbackReason(ServiceOllama, model, err)
+		for _, c := range candidates {
+			if c.category == SensitivityDepiction {
+				continue
+			}
+			report.Flags = append(report.Flags, c.flag("heuristic", heuristicSensitivityRationale(c)))
+		}
+		return report
+	}
+
+	report.Provider = "ollama:" + model
+	seen := map[int]bool{}
+	for _, f := range parsed.Flags {
+		if !f.Flag || f.Index < 0 || f.Index >= len(candidates) || seen[f.Index] {
+			continue
+		}
+		seen[f.Index] = true
+		c := candidates[f.Index]
+		if category := strings.TrimSpace(f.Category); category != "" {
+			c.category = category
+		}
+		rationale := strings.TrimSpace(f.Rationale)
+		if rationale == "" {
+			rationale = heuristicSensitivityRationale(c)
+		}
+		report.Flags = append(report.Flags, c.flag(report.Provider, rationale))
+	}
+	return report
+}
+
+func (c sensitivityCandidate) flag(provider, rationale string) SensitivityFlag {
+	return SensitivityFlag{
+		Chapter:   c.chapter.index,
+		Title:     c.chapter.title,
+		Category:  c.category,
+		Term:      c.term,
+		Quote:     firstWords(c.sentence, 40),
+		Context:   c.context,
+		Rationale: rationale,
+		Provider:  provider,
+	}
+}
+
+func sensitivityCandidates(chapters []chapter) []sensitivityCandidate {
+	out := []sensitivityCandidate{}
+	for _, ch := range chapters {
+		for _, sentence := range splitSentences(ch.text) {
+			words := wordPattern.FindAllString(strings.ToLower(sentence), -1)
+			category, term := classifySensitivitySentence(words)
+			if category == "" {
+				continue
+			}
+			out = append(out, sensitivityCandidate{
+				chapter:  ch,
+				category: category,
+				term:     term,
+				sentence: sentence,
+				context:  speechContext(sentence, term),
+			})
+		}
+	}
+	return out
+}
+
+func classifySensitivitySentence(words []string) (string, string) {
+	identity := ""
+	for i, w := range words {
+		if _, ok := sensitivityTerms[w]; ok {
+			return SensitivityOutdatedTerm, w
+		}
+		if i+1 < len(words) {
+			if _, ok := sensitivityTerms[w+" "+words[i+1]]; ok {
+				return SensitivityOutdatedTerm, w + " " + words[i+1]
+			}
+		}
+		if _, ok := identityTerms[w]; ok && identity == "" {
+			identity = w
+		}
+	}
+	if identity == "" {
+		return "", ""
+	}
+	joined := " " + strings.Join(words, " ") + " "
+	for _, marker := range stereotypeMarkers {
+		if strings.Contains(joined, " "+marker+" ") {
+			return SensitivityStereotype, identity
+		}
+	}
+	return SensitivityDepiction, identity
+}
+
+// speechContext reports whether term falls inside quoted speech. A slur in a
+// character's mouth reads differently from the same word in narration.
+func speechContext(sentence, term string) string {
+	lower := strings.ToLower(sentence)
+	idx := strings.Index(lower, strings.Fields(term)[0])
+	if idx < 0 {
+		return "narration"
+	}
+	open := false
+	for _, r := range lower[:idx] {
+		switch r {
+		case '"':
+			open = !open
+		case '“':
+			open = true
+		case '”':
+			open = false
+		}
+	}
+	if open {
+		return "dialogue"
+	}
+	return "narration"
+}
+
+func heuristicSensitivityRationale(c sensitivityCandidate) string {
+	switch c.category {
+	case SensitivityOutdatedTerm:
+		return fmt.Sprintf("Outdated or loaded term %q used in %s.", c.term, c.context)
+	case SensitivityStereotype:
+		return fmt.Sprintf("Generalizing language attached to identity term %q.", c.term)
+	}
+	return fmt.Sprintf("Depiction involving identity term %q.", c.term)
+}
+
+func reviewSensitivityWithOllama(model string, candidates []sensitivityCandidate) (sensitivityLLMResult, error) {
+	var b strings.Builder
+	b.WriteString("You are assisting a sensitivity reader. For each numbered passage decide whether it may need human sensitivity review ")
+	b.WriteString("(stereotyped descriptions, slurs in narration versus dialogue, depictions of marginalized identities). ")
+	b.WriteString("Do not flag neutral mentions. Return JSON only: {\"flags\":[{\"index\":0,\"flag\":true,\"category\":\"...\",\"rationale\":\"...\"}]}. ")
+	b.WriteString("category is one of outdated_term, stereotype, identity_depiction.\n\nPASSAGES:\n")
+	for i, c := range candidates {
+		fmt.Fprintf(&b, "[%d] (Ch %d, %s) %s\n", i, c.chapter.index, c.context, firstWords(c.sentence, 60))
+	}
+	var parsed sensitivityLLMResult
+	if err := generateOllamaJSON(model, b.String(), &parsed); err != nil {
+		return sensitivityLLMResult{}, err
+	}
+	return parsed, nil
+}
@@ -0,0 +1,27 @@
+package backend
+
+import "testing"
+
+func TestAnalyzeSensitivityHeuristicFallback(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	chapters := []chapter{
+		{index: 1, title: "Chapter 1", text: "The gypsy camp sat by the river. \"Those savages will rob us,\" Aunt May said."},
+		{index: 2, title: "Chapter 2", text: "Her neighbour was deaf. The immigrants were always loud, like all of them."},
+	}
+	report := analyzeSensitivity(chapters)
+	if report.Provider != "heuristic" || report.Disclaimer == "" {
+		t.Fatalf("expected labeled heuristic report, got %+v", report)
+	}
+	if report.Fallback == nil || report.Fallback.Code != FallbackServiceDown {
+		t.Fatalf("expected a service_down fallback reason, got %+v", report.Fallback)
+	}
+	if len(report.Flags) != 3 {
+		t.Fatalf("expected outdated terms and stereotype flagged without bare identity mention, got %+v", report.Flags)
+	}
+	if report.Flags[0].Context != "narration" || report.Flags[1].Context != "dialogue" {
+		t.Fatalf("expected narration then dialogue context, got %q and %q", report.Flags[0].Context, report.Flags[1].Context)
+	}
+	if f := report.Flags[2]; f.Category != SensitivityStereotype || f.Chapter != 2 || f.Quote == "" {
+		t.Fatalf("expected chapter 2 stereotype flag with quote, got %+v", f)
+	}
+}
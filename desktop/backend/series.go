@@ -0,0 +1,229 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"book_dashboard/internal/workspace"
+)
+
+const HealthIssueSeriesContinuity = "series_continuity"
+
+var yearMarkerPattern = regexp.MustCompile(`^\d{4}$`)
+
+type seriesKey struct{}
+
+// WithSeries analyzes the run as the next installment of the named series:
+// it is checked against what earlier installments established and then
+// recorded for the ones after it. An empty name analyzes it standalone.
+func WithSeries(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, seriesKey{}, strings.TrimSpace(name))
+}
+
+func seriesFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(seriesKey{}).(string)
+	return name
+}
+
+// ListSeries returns the names of the series recorded in the default
+// workspace.
+func ListSeries() ([]string, error) {
+	root, err := workspace.EnsureDefault()
+	if err != nil {
+		return nil, err
+	}
+	return workspace.ListSeries(root)
+}
+
+// seriesContext is the accumulated view of the installments before this one.
+type seriesContext struct {
+	characters map[string]*SeriesCharacterHistory
+	// attributes holds the latest stated value per lower-cased name and
+	// attribute, with where it was stated.
+	attributes map[string]seriesFact
+	timeline   []SeriesTimelineEvent
+	words      int
+}
+
+type seriesFact struct {
+	value       string
+	installment int
+	chapter     int
+	title       string
+}
+
+func accumulateSeries(prior []workspace.SeriesInstallment) seriesContext {
+	acc := seriesContext{characters: map[string]*SeriesCharacterHistory{}, attributes: map[string]seriesFact{}, timeline: []SeriesTimelineEvent{}}
+	for _, inst := range prior {
+		acc.words += inst.WordCount
+		for _, c := range inst.Characters {
+			h, ok := acc.characters[c.Name]
+			if !ok {
+				h = &SeriesCharacterHistory{Name: c.Name, FirstInstallment: inst.Number}
+				acc.characters[c.Name] = h
+			}
+			h.LastInstallment = inst.Number
+			h.PriorMentions += c.Mentions
+		}
+		for _, a := range inst.Attributes {
+			acc.attributes[strings.ToLower(a.Name)+"|"+a.Attribute] = seriesFact{value: a.Value, installment: inst.Number, chapter: a.Chapter, title: inst.Title}
+		}
+		for _, e := range inst.Timeline {
+			acc.timeline = append(acc.timeline, SeriesTimelineEvent{Installment: inst.Number, Chapter: e.Chapter, TimeMarker: e.TimeMarker, Event: e.Event})
+		}
+	}
+	return acc
+}
+
+// seriesInstallment captures the continuity facts this run contributes to
+// the series.
+func seriesInstallment(title, sourceName, sourceSHA, projectID string, words int, chapters []chapter, dictionary []CharacterEntry, custom []AttributeExtractor) workspace.SeriesInstallment {
+	inst := workspace.SeriesInstallment{
+		Title:        title,
+		SourceName:   sourceName,
+		SourceSHA256: sourceSHA,
+		ProjectID:    projectID,
+		AnalyzedAt:   time.Now().Format(time.RFC3339),
+		WordCount:    words,
+		ChapterCount: len(chapters),
+		Characters:   make([]workspace.SeriesCharacter, 0, len(dictionary)),
+		Attributes:   []workspace.SeriesAttribute{},
+		Timeline:     []workspace.SeriesEvent{},
+	}
+	for _, c := range dictionary {
+		inst.Characters = append(inst.Characters, workspace.SeriesCharacter{Name: c.Name, Mentions: c.TotalMentions})
+	}
+	for _, p := range chapterProfiles(chapters, characterAliasIndex(dictionary), custom) {
+		keys := make([]string, 0, len(p.Attributes))
+		for k := range p.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			inst.Attributes = append(inst.Attributes, workspace.SeriesAttribute{Name: p.Name, Attribute: k, Value: p.Attributes[k], Chapter: p.Chapter})
+		}
+	}
+	sort.SliceStable(inst.Attributes, func(i, j int) bool { return inst.Attributes[i].Chapter < inst.Attributes[j].Chapter })
+	for _, ch := range chapters {
+		for _, m := range extractChapterMarkers(ch.text) {
+			inst.Timeline = append(inst.Timeline, workspace.SeriesEvent{Chapter: ch.index, TimeMarker: m, Event: fmt.Sprintf("Ch %d %s: %s", ch.index, ch.title, firstWords(ch.text, 16))})
+		}
+	}
+	return inst
+}
+
+// compareWithSeries checks the installment against the series so far. Eye
+// colour must hold, ages may only grow and the dead stay dead; those breaks
+// become health issues numbered after the existing ones. A timeline that
+// starts before the series left off is only noted, since it is often a
+// deliberate flashback.
+func compareWithSeries(name string, prior []workspace.SeriesInstallment, current workspace.SeriesInstallment, chapterByID map[int]ChapterSummary, existing int) (*SeriesReport, []HealthIssue) {
+	acc := accumulateSeries(prior)
+	report := &SeriesReport{
+		Name:                name,
+		Installment:         len(prior) + 1,
+		PriorInstallments:   len(prior),
+		PriorWords:          acc.words,
+		NewCharacters:       []string{},
+		ReturningCharacters: []SeriesCharacterHistory{},
+		Timeline:            acc.timeline,
+		Notes:               []string{},
+	}
+	if n := len(prior); n > 0 {
+		report.Installment = prior[n-1].Number + 1
+	}
+	for _, c := range current.Characters {
+		if h, ok := acc.characters[c.Name]; ok {
+			returning := *h
+			returning.Mentions = c.Mentions
+			report.ReturningCharacters = append(report.ReturningCharacters, returning)
+			continue
+		}
+		if len(prior) > 0 {
+			report.NewCharacters = append(report.NewCharacters, c.Name)
+		}
+	}
+
+	issues := []HealthIssue{}
+	reported := map[string]bool{}
+	for _, a := range current.Attributes {
+		key := strings.ToLower(a.Name) + "|" + a.Attribute
+		prev, ok := acc.attributes[key]
+		if !ok || reported[key] || !seriesFactBroken(a.Attribute, prev.value, a.Value) {
+			continue
+		}
+		reported[key] = true
+		issues = append(issues, HealthIssue{
+			ID:            fmt.Sprintf("issue-%03d", existing+len(issues)+1),
+			Kind:          HealthIssueSeriesContinuity,
+			Entity:        a.Name,
+			Severity:      seriesSeverity(a.Attribute),
+			Description:   fmt.Sprintf("%s changed for %s: %q in installment %d Ch%d but %q in Ch%d of this installment", a.Attribute, a.Name, prev.value, prev.installment, prev.chapter, a.Value, a.Chapter),
+			ChapterA:      prev.chapter,
+			ChapterB:      a.Chapter,
+			ContextA:      fmt.Sprintf("Installment %d: %s", prev.installment, prev.title),
+			ContextB:      chapterByID[a.Chapter].Summary,
+			DictionaryRef: a.Name,
+		})
+	}
+
+	if last, ok := latestYear(acc.timeline); ok {
+		for _, e := range current.Timeline {
+			if year, isYear := markerYear(e.TimeMarker); isYear && year < last {
+				report.Notes = append(report.Notes, fmt.Sprintf("Ch %d is set in %d, before %d where the series left off; check it is meant as a flashback.", e.Chapter, year, last))
+				break
+			}
+		}
+	}
+	return report, issues
+}
+
+func seriesFactBroken(attribute, before, now string) bool {
+	switch attribute {
+	case "age":
+		a, errA := strconv.Atoi(before)
+		b, errB := strconv.Atoi(now)
+		return errA == nil && errB == nil && b < a
+	case "dead":
+		return before == "true" && now == "false"
+	case "residence":
+		// Characters move between installments.
+		return false
+	default:
+		return !strings.EqualFold(before, now)
+	}
+}
+
+func seriesSeverity(attribute string) string {
+	switch attribute {
+	case "dead":
+		return "HIGH"
+	case "age", "eyes", "birthplace":
+		return "MED"
+	default:
+		return "LOW"
+	}
+}
+
+func latestYear(events []SeriesTimelineEvent) (int, bool) {
+	latest, found := 0, false
+	for _, e := range events {
+		if year, ok := markerYear(e.TimeMarker); ok && year > latest {
+			latest, found = year, true
+		}
+	}
+	return latest, found
+}
+
+func markerYear(marker string) (int, bool) {
+	if !yearMarkerPattern.MatchString(marker) {
+		return 0, false
+	}
+	year, err := strconv.Atoi(marker)
+	return year, err == nil
+}
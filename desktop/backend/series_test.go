@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/internal/workspace"
+)
+
+func TestCompareWithSeriesChecksContinuityAgainstEarlierInstallments(t *testing.T) {
+	first := "Chapter 1\nIn 2001 Mara came home to the harbor. Mara's eyes were green in the lamplight. Mara, aged 30, mended the nets with Elias.\nChapter 2\nElias watched Mara sail out alone before the storm."
+	second := "Chapter 1\nIn 1999 Mara was still in the city, long before the harbor. Tomas found Mara at the station. Mara's eyes were blue and tired.\nChapter 2\nMara, aged 31, told Tomas about Elias and the boats."
+
+	installment := func(text string) ([]chapter, map[int]ChapterSummary, []CharacterEntry) {
+		chapters := splitChapters(text)
+		dictionary, _, byID := buildCharacterDictionary(chapters)
+		return chapters, byID, dictionary
+	}
+	chapters1, byID1, dict1 := installment(first)
+	one := seriesInstallment("Part One", "part-one.docx", "sha-1", "p1", len(strings.Fields(first)), chapters1, dict1, nil)
+	one.Number = 1
+	if report, issues := compareWithSeries("Harbor Lights", nil, one, byID1, 0); report.Installment != 1 || len(issues) != 0 || len(report.NewCharacters) != 0 {
+		t.Fatalf("first installment has nothing to compare with: %+v issues=%v", report, issues)
+	}
+
+	chapters2, byID2, dict2 := installment(second)
+	two := seriesInstallment("Part Two", "part-two.docx", "sha-2", "p2", len(strings.Fields(second)), chapters2, dict2, nil)
+	report, issues := compareWithSeries("Harbor Lights", []workspace.SeriesInstallment{one}, two, byID2, 2)
+
+	if report.Installment != 2 || report.PriorInstallments != 1 || report.PriorWords != one.WordCount {
+		t.Fatalf("unexpected series position: %+v", report)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("want only the eye colour break (age 30 to 31 is time passing), got %+v", issues)
+	}
+	if issue := issues[0]; issue.ID != "issue-003" || issue.Kind != HealthIssueSeriesContinuity || issue.Entity != "Mara" || !strings.Contains(issue.Description, `"green" in installment 1`) {
+		t.Fatalf("unexpected continuity issue: %+v", issue)
+	}
+	if !containsString(report.NewCharacters, "Tomas") || containsString(report.NewCharacters, "Elias") {
+		t.Fatalf("Tomas is new and Elias returning, got new=%v", report.NewCharacters)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "1999, before 2001") {
+		t.Fatalf("want a flashback note for 1999, got %v", report.Notes)
+	}
+}
@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"book_dashboard/internal/workspace"
+)
+
+// Stages whose results are cached per manuscript text.
+const (
+	CachedStageGenre      = "genre"
+	CachedStageDictionary = "dictionary"
+	CachedStageAI         = "ai"
+)
+
+type forceRefreshKey struct{}
+
+// WithForceRefresh makes the run recompute every cached stage and overwrite
+// what was cached, as after a model upgrade the fingerprints cannot see.
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+func forceRefreshFromContext(ctx context.Context) bool {
+	refresh, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return refresh
+}
+
+// stageCache reuses genre decisions, chapter summaries and AI windows across
+// runs of unchanged text. Without a workspace it neither loads nor saves.
+type stageCache struct {
+	workspaceRoot string
+	textSHA256    string
+	refresh       bool
+}
+
+func newStageCache(ctx context.Context, workspaceRoot, text string) stageCache {
+	return stageCache{workspaceRoot: workspaceRoot, textSHA256: workspace.TextSHA256(text), refresh: forceRefreshFromContext(ctx)}
+}
+
+func (c stageCache) load(stage, fingerprint string, v any) (bool, error) {
+	if c.refresh || strings.TrimSpace(c.workspaceRoot) == "" {
+		return false, nil
+	}
+	return workspace.LoadStage(c.workspaceRoot, c.textSHA256, stage, fingerprint, v)
+}
+
+func (c stageCache) save(stage, fingerprint string, v any) error {
+	if strings.TrimSpace(c.workspaceRoot) == "" {
+		return nil
+	}
+	return workspace.SaveStage(c.workspaceRoot, c.textSHA256, stage, fingerprint, v)
+}
+
+// stageFingerprint hashes the inputs a stage depends on besides the text.
+func stageFingerprint(parts ...any) string {
+	raw, err := json.Marshal(parts)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// chapterSplitFingerprint changes whenever the chapter (or story) split
+// does, since per-chapter results are stored in chapter order.
+func chapterSplitFingerprint(chapters []chapter) string {
+	parts := make([]any, 0, len(chapters))
+	for _, ch := range chapters {
+		parts = append(parts, []any{ch.index, ch.title, len(ch.text)})
+	}
+	return stageFingerprint(parts...)
+}
+
+// chapterKey identifies a chapter's text in the genre cache, so a chapter
+// keeps its decision when the split around it moves.
+func chapterKey(ch chapter) string {
+	return workspace.TextSHA256(ch.text)
+}
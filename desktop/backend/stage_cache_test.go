@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"book_dashboard/internal/workspace"
+)
+
+func TestStageCacheForceRefreshSkipsLoadButSaves(t *testing.T) {
+	root := t.TempDir()
+	text := "Chapter 1\nThe lighthouse keeper counted the ships."
+	cache := newStageCache(context.Background(), root, text)
+	if err := cache.save(CachedStageGenre, "fp", map[string]string{"a": "old"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	fresh := newStageCache(WithForceRefresh(context.Background()), root, text)
+	got := map[string]string{}
+	if hit, err := fresh.load(CachedStageGenre, "fp", &got); err != nil || hit {
+		t.Fatalf("force refresh should miss, got hit=%t err=%v", hit, err)
+	}
+	if err := fresh.save(CachedStageGenre, "fp", map[string]string{"a": "new"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if hit, _ := cache.load(CachedStageGenre, "fp", &got); !hit || got["a"] != "new" {
+		t.Fatalf("expected the refreshed value cached, got %v", got)
+	}
+
+	none := newStageCache(context.Background(), "", text)
+	if err := none.save(CachedStageGenre, "fp", got); err != nil {
+		t.Fatalf("save without workspace: %v", err)
+	}
+	if hit, _ := none.load(CachedStageGenre, "fp", &got); hit {
+		t.Fatal("a run without a workspace should not hit the cache")
+	}
+}
+
+func TestPurgeManuscriptRemovesStageCache(t *testing.T) {
+	root, err := workspace.EnsureAt(filepath.Join(t.TempDir(), workspace.BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	project, err := workspace.CreateProjectWithSource(root, "Secret", "secret.docx", []byte("secret text"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	key := workspace.TextSHA256("secret text")
+	if _, err := workspace.SaveReport(project.ReportPath, workspace.Report{BookTitle: "Secret", TextSHA256: key}); err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	if err := workspace.SaveStage(root, key, CachedStageAI, "fp", []int{1}); err != nil {
+		t.Fatalf("save stage: %v", err)
+	}
+
+	report, err := PurgeManuscript(project.Root)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	report.Verify()
+	if !report.Verified {
+		t.Fatalf("expected a verified purge, got %+v", report)
+	}
+	if _, err := os.Stat(workspace.StageCacheDir(root, key)); !os.IsNotExist(err) {
+		t.Fatalf("expected the stage cache removed, got %v", err)
+	}
+}
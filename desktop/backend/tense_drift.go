@@ -0,0 +1,236 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	HealthIssueContradiction = "contradiction"
+	HealthIssueTenseDrift    = "tense_drift"
+
+	tensePast    = "past"
+	tensePresent = "present"
+
+	// A scene's tense is established when tenseEstablishAgree of its first
+	// tenseEstablishWindow tagged sentences agree; scenes with fewer than
+	// minTenseSentences tagged sentences are not judged.
+	tenseEstablishWindow = 5
+	tenseEstablishAgree  = 4
+	minTenseSentences    = 6
+	// tenseDriftRun consecutive narration sentences in the other tense count as
+	// drift; shorter runs are usually general truths or unmarked thoughts.
+	tenseDriftRun = 3
+)
+
+var sceneBreakPattern = regexp.MustCompile(`^[\s*#~•·-]*[*#~•·-][\s*#~•·-]*$`)
+var narrationQuotePattern = regexp.MustCompile(`["“][^"“”]*["”]`)
+var auxiliaryTensePattern = regexp.MustCompile(`(?i)\b(was|were|is|are|am)(?:n't|n’t)?\b`)
+var tenseTokenPattern = regexp.MustCompile(`[A-Za-z]+`)
+
+var pastIrregularVerbs = wordSet("had", "did", "said", "went", "came", "saw", "took", "made", "knew", "thought", "felt", "found", "gave", "told", "left", "got", "ran", "sat", "stood", "held", "heard", "kept", "began", "brought", "wrote", "spoke", "fell", "turned", "could", "would", "lay", "rose", "drew", "threw", "caught", "meant", "paid", "led", "read", "slept", "woke", "shook", "swore", "tore", "wore", "grew", "flew", "knelt", "lost", "sent", "spent", "built", "broke", "chose", "drove", "ate", "drank", "sang", "swam", "won", "hung", "struck", "bit", "hid", "lit", "put", "set", "cut", "let", "hit", "shut")
+var presentIrregularVerbs = wordSet("has", "does", "says", "goes", "can", "will")
+var presentBaseVerbs = wordSet("have", "do", "go", "say", "see", "know", "think", "feel", "want", "look", "walk", "run", "take", "make", "get", "come", "hear", "watch", "turn", "stand", "sit", "wait", "tell", "need", "try", "hold", "keep", "open", "close")
+var capitalizedNonSubjects = wordSet("The", "A", "An", "This", "That", "These", "Those", "His", "Her", "Their", "My", "Our", "Its", "Your", "When", "Then", "But", "And", "After", "Before", "As", "If", "Once", "While", "Now", "So", "Still", "Outside", "Inside", "Somewhere", "There", "Here", "In", "On", "At", "By")
+var nonVerbEndings = wordSet("always", "perhaps", "sometimes", "towards", "besides", "nevertheless", "afterwards", "this", "his", "hers", "its", "ours", "yours", "theirs", "was", "is", "as", "us", "yes", "less", "unless", "across", "various", "thus", "plus", "need", "feed", "seed", "bed", "red", "shed", "speed", "indeed", "led", "wed", "bleed", "breed", "proceed")
+
+func wordSet(words ...string) map[string]bool {
+	out := make(map[string]bool, len(words))
+	for _, w := range words {
+		out[w] = true
+	}
+	return out
+}
+
+// tenseDrift is a run of narration that slips out of the tense its scene
+// established.
+type tenseDrift struct {
+	chapter int
+	scene   int
+	from    string
+	to      string
+	before  string
+	after   string
+	count   int
+}
+
+// sentenceTense tags a narration sentence as past or present by counting
+// auxiliaries and the verbs that follow a subject; dialogue is ignored. It
+// returns "" when the sentence carries no tense signal or both equally.
+func sentenceTense(sentence string) string {
+	narration := narrationOnly(sentence)
+	past, present := 0, 0
+	for _, m := range auxiliaryTensePattern.FindAllString(narration, -1) {
+		switch m = strings.ToLower(m); {
+		case strings.HasPrefix(m, "was"), strings.HasPrefix(m, "were"):
+			past++
+		default:
+			present++
+		}
+	}
+	tokens := tenseTokenPattern.FindAllString(narration, -1)
+	for i := 0; i+1 < len(tokens); i++ {
+		subject, v := tokens[i], tokens[i+1]
+		if strings.HasSuffix(v, "ly") && i+2 < len(tokens) {
+			v = tokens[i+2]
+		}
+		switch {
+		case pastIrregularVerbs[v] || (strings.HasSuffix(v, "ed") && len(v) > 3 && !nonVerbEndings[v]):
+			if isTenseSubject(subject) {
+				past++
+			}
+		case presentIrregularVerbs[v]:
+			if isTenseSubject(subject) {
+				present++
+			}
+		case strings.HasSuffix(v, "s") && !strings.HasSuffix(v, "ss") && len(v) > 3 && !nonVerbEndings[v]:
+			if isThirdPersonSubject(subject) {
+				present++
+			}
+		case presentBaseVerbs[v]:
+			if isTenseSubject(subject) && !isThirdPersonSubject(subject) {
+				present++
+			}
+		}
+	}
+	switch {
+	case past > present:
+		return tensePast
+	case present > past:
+		return tensePresent
+	}
+	return ""
+}
+
+func isThirdPersonSubject(w string) bool {
+	switch w {
+	case "he", "He", "she", "She", "it", "It":
+		return true
+	}
+	return w[0] >= 'A' && w[0] <= 'Z' && w != "I" && !capitalizedNonSubjects[w]
+}
+
+func isTenseSubject(w string) bool {
+	switch w {
+	case "I", "we", "We", "they", "They", "you", "You":
+		return true
+	}
+	return isThirdPersonSubject(w)
+}
+
+// narrationOnly blanks out dialogue in a paragraph, including a quote left
+// open to run on into the next paragraph.
+func narrationOnly(paragraph string) string {
+	narration := narrationQuotePattern.ReplaceAllString(paragraph, " ")
+	if i := strings.IndexAny(narration, `"“`); i >= 0 {
+		narration = narration[:i]
+	}
+	return narration
+}
+
+// chapterScenes splits a chapter's paragraphs at scene-break lines ("***",
+// "#", "~ ~ ~").
+func chapterScenes(text string) [][]string {
+	scenes := [][]string{{}}
+	for _, p := range chapterParagraphs(text) {
+		if sceneBreakPattern.MatchString(p) {
+			if len(scenes[len(scenes)-1]) > 0 {
+				scenes = append(scenes, []string{})
+			}
+			continue
+		}
+		scenes[len(scenes)-1] = append(scenes[len(scenes)-1], p)
+	}
+	return scenes
+}
+
+// detectTenseDrift checks each scene separately, so a scene written in a
+// different tense from the one before it (an intentional shift at a scene
+// break) is counted in sceneShifts rather than flagged. Within a scene, the
+// opening sentences establish the tense and the first run of narration in
+// the other tense is reported.
+func detectTenseDrift(chapters []chapter) (drifts []tenseDrift, sceneShifts int) {
+	for _, ch := range chapters {
+		previous := ""
+		for sceneIdx, scene := range chapterScenes(ch.text) {
+			type tagged struct{ text, tense string }
+			sentences := []tagged{}
+			for _, p := range scene {
+				for _, s := range splitSentences(narrationOnly(p)) {
+					if tense := sentenceTense(s); tense != "" {
+						sentences = append(sentences, tagged{text: s, tense: tense})
+					}
+				}
+			}
+			if len(sentences) < minTenseSentences {
+				continue
+			}
+			counts := map[string]int{}
+			for _, s := range sentences[:tenseEstablishWindow] {
+				counts[s.tense]++
+			}
+			base := tensePast
+			if counts[tensePresent] > counts[tensePast] {
+				base = tensePresent
+			}
+			if counts[base] < tenseEstablishAgree {
+				continue
+			}
+			if previous != "" && previous != base {
+				sceneShifts++
+			}
+			previous = base
+
+			lastBase, run := "", 0
+			for i, s := range sentences {
+				if s.tense == base {
+					lastBase, run = s.text, 0
+					continue
+				}
+				run++
+				if run < tenseDriftRun {
+					continue
+				}
+				start := i - run + 1
+				drifted := 0
+				for _, rest := range sentences[start:] {
+					if rest.tense != base {
+						drifted++
+					}
+				}
+				drifts = append(drifts, tenseDrift{
+					chapter: ch.index,
+					scene:   sceneIdx + 1,
+					from:    base,
+					to:      sentences[start].tense,
+					before:  lastBase,
+					after:   sentences[start].text,
+					count:   drifted,
+				})
+				break
+			}
+		}
+	}
+	return drifts, sceneShifts
+}
+
+// tenseDriftIssues turns drifts into MED health issues numbered after the
+// existing issues.
+func tenseDriftIssues(drifts []tenseDrift, existing int) []HealthIssue {
+	out := make([]HealthIssue, 0, len(drifts))
+	for i, d := range drifts {
+		out = append(out, HealthIssue{
+			ID:          fmt.Sprintf("issue-%03d", existing+i+1),
+			Kind:        HealthIssueTenseDrift,
+			Entity:      "Narration tense",
+			Severity:    "MED",
+			Description: fmt.Sprintf("Tense drifts from %s to %s within scene %d of Ch%d (%d %s-tense sentences)", d.from, d.to, d.scene, d.chapter, d.count, d.to),
+			ChapterA:    d.chapter,
+			ChapterB:    d.chapter,
+			ContextA:    firstWords(d.before, 40),
+			ContextB:    firstWords(d.after, 40),
+		})
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	pastScene    = "Mara walked to the harbor. She was tired. The gulls circled overhead and she watched them. Tomas waited by the boat. He lifted the rope. She stepped aboard."
+	presentScene = "Mara walks to the harbor. She is tired. She watches the gulls. Tomas waits by the boat. He lifts the rope. She steps aboard."
+)
+
+func TestSentenceTense(t *testing.T) {
+	cases := map[string]string{
+		"She walked to the door and opened it.":        tensePast,
+		"She walks to the door and opens it.":          tensePresent,
+		"I am not sure what they want.":                tensePresent,
+		`"I am here," she said.`:                       tensePast,
+		"The doors of the old church.":                 "",
+		"He always checks the locks before he sleeps.": tensePresent,
+	}
+	for sentence, want := range cases {
+		if got := sentenceTense(sentence); got != want {
+			t.Errorf("sentenceTense(%q) = %q, want %q", sentence, got, want)
+		}
+	}
+}
+
+func TestDetectTenseDriftWithinScene(t *testing.T) {
+	drifted := pastScene + "\nThen she turns around. She sees the lighthouse. Tomas laughs at her.\nShe shrugged."
+	drifts, shifts := detectTenseDrift([]chapter{{index: 4, text: drifted}})
+	if len(drifts) != 1 || shifts != 0 {
+		t.Fatalf("expected one drift, got %+v (shifts %d)", drifts, shifts)
+	}
+	d := drifts[0]
+	if d.chapter != 4 || d.from != tensePast || d.to != tensePresent || d.count != 3 {
+		t.Fatalf("unexpected drift %+v", d)
+	}
+	if !strings.HasPrefix(d.after, "Then she turns around") || !strings.HasPrefix(d.before, "She stepped aboard") {
+		t.Fatalf("unexpected evidence %+v", d)
+	}
+
+	issues := tenseDriftIssues(drifts, 2)
+	if issues[0].ID != "issue-003" || issues[0].Severity != "MED" || issues[0].Kind != HealthIssueTenseDrift {
+		t.Fatalf("unexpected issue %+v", issues[0])
+	}
+}
+
+func TestDetectTenseDriftAllowsShiftAtSceneBreak(t *testing.T) {
+	text := pastScene + "\n* * *\n" + presentScene
+	drifts, shifts := detectTenseDrift([]chapter{{index: 1, text: text}})
+	if len(drifts) != 0 || shifts != 1 {
+		t.Fatalf("expected a scene-break shift and no drift, got %+v (shifts %d)", drifts, shifts)
+	}
+}
@@ -0,0 +1,305 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minTermMentions is how often a capitalized term must recur to be
+	// tracked; minCoinageMentions is the same for lower-case hyphenated
+	// words, which are more often ordinary compounds.
+	minTermMentions    = 2
+	minCoinageMentions = 3
+	maxTrackedTerms    = 40
+	maxTermIssues      = 25
+)
+
+var (
+	capitalizedTermPattern = regexp.MustCompile(`\b\p{Lu}\p{L}+(?:-\p{L}+)*(?:[ \t]+(?:of|the)[ \t]+\p{Lu}\p{L}+(?:-\p{L}+)*|[ \t]+\p{Lu}\p{L}+(?:-\p{L}+)*)*`)
+	coinagePattern         = regexp.MustCompile(`\b\p{Ll}+(?:-\p{Ll}+)+\b`)
+	termPartSplit          = regexp.MustCompile(`[\s-]+`)
+	possessiveNamePattern  = regexp.MustCompile(`\b(?P<name>\p{Lu}\p{L}+)(?:'s|’s)\b`)
+	speakerBeforePattern   = regexp.MustCompile(`\b(?P<name>\p{Lu}\p{L}+)\s+` + speechVerbAlternation + `\b`)
+	speakerAfterPattern    = regexp.MustCompile(`\b` + speechVerbAlternation + `\s+(?P<name>\p{Lu}\p{L}+)\b`)
+	titledNamePattern      = regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Dr|Prof)\.?\s+(?P<name>\p{Lu}\p{L}+)\b`)
+)
+
+// commonCapitalized are capitalized mid-sentence for grammar, not because
+// the author coined them.
+var commonCapitalized = wordSet("monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+	"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december",
+	"mr", "mrs", "ms", "dr", "god", "english", "chapter", "ok", "okay")
+
+// TerminologyReport tracks the manuscript's invented terms (capitalized
+// names of things, orders and places, and hyphenated coinages) and the ones
+// spelled or capitalized more than one way.
+type TerminologyReport struct {
+	Terms  []TermEntry `json:"terms"`
+	Issues []TermIssue `json:"issues"`
+	Flags  []string    `json:"flags"`
+}
+
+type TermEntry struct {
+	Term         string `json:"term"`
+	Mentions     int    `json:"mentions"`
+	FirstChapter int    `json:"firstChapter"`
+}
+
+// TermIssue is one term written several ways. Preferred is the most used
+// form; Variants lists every form, most used first.
+type TermIssue struct {
+	Preferred  string        `json:"preferred"`
+	Variants   []TermVariant `json:"variants"`
+	Suggestion string        `json:"suggestion"`
+}
+
+type TermVariant struct {
+	Form     string `json:"form"`
+	Count    int    `json:"count"`
+	Chapters []int  `json:"chapters"`
+}
+
+func emptyTerminologyReport() TerminologyReport {
+	return TerminologyReport{Terms: []TermEntry{}, Issues: []TermIssue{}, Flags: []string{}}
+}
+
+// termOccurrences tallies each surface form of one term.
+type termOccurrences struct {
+	forms map[string]*TermVariant
+	total int
+	first int
+}
+
+// analyzeTerminology collects candidate terms from mid-sentence capitalized
+// phrases and hyphenated coinages, then searches for every spelling of each:
+// other capitalization, and hyphenated, closed or (for capitalized terms)
+// open compounds. Capitals at the start of a sentence say nothing about the
+// term, so those occurrences are not counted. Single words the entity
+// dictionary shows as characters (possessives, speech tags, titles) are left
+// to the name hygiene check.
+func analyzeTerminology(chapters []chapter, text string, dictionary []CharacterEntry) TerminologyReport {
+	report := emptyTerminologyReport()
+	characters := characterNames(dictionary, text)
+	candidates := map[string]string{}
+	counts := map[string]int{}
+	for _, ch := range chapters {
+		for _, m := range capitalizedTermPattern.FindAllStringIndex(ch.text, -1) {
+			term := trimTermCandidate(ch.text[m[0]:m[1]], sentenceInitial(ch.text, m[0]))
+			if term == "" {
+				continue
+			}
+			key := termKey(term)
+			counts[key]++
+			if _, ok := candidates[key]; !ok {
+				candidates[key] = term
+			}
+		}
+		for _, w := range coinagePattern.FindAllString(ch.text, -1) {
+			key := termKey(w)
+			counts[key]++
+			if _, ok := candidates[key]; !ok {
+				candidates[key] = w
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(candidates))
+	for key, term := range candidates {
+		capitalized := unicode.IsUpper([]rune(term)[0])
+		if capitalized && counts[key] < minTermMentions || !capitalized && counts[key] < minCoinageMentions {
+			continue
+		}
+		if capitalized && !strings.ContainsAny(term, " -") && characters[term] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		term := candidates[key]
+		occ := findTermForms(chapters, term)
+		if occ.total == 0 || ordinaryWord(term, occ) {
+			continue
+		}
+		report.Terms = append(report.Terms, TermEntry{Term: term, Mentions: occ.total, FirstChapter: occ.first})
+		if issue, ok := termIssue(term, occ); ok {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	sort.SliceStable(report.Terms, func(i, j int) bool { return report.Terms[i].Mentions > report.Terms[j].Mentions })
+	if len(report.Terms) > maxTrackedTerms {
+		report.Terms = report.Terms[:maxTrackedTerms]
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return len(report.Issues[i].Variants) > len(report.Issues[j].Variants)
+	})
+	if len(report.Issues) > maxTermIssues {
+		report.Issues = report.Issues[:maxTermIssues]
+	}
+	if n := len(report.Issues); n > 0 {
+		report.Flags = append(report.Flags, fmt.Sprintf("%d invented terms are spelled or capitalized inconsistently.", n))
+	}
+	return report
+}
+
+// characterNames picks the dictionary entries used as people: possessives,
+// speech tags and titles, the evidence hasStrongNameEvidence looks for, found
+// in one pass over the text rather than one per name.
+func characterNames(dictionary []CharacterEntry, text string) map[string]bool {
+	evidence := map[string]bool{}
+	for _, p := range []*regexp.Regexp{possessiveNamePattern, speakerBeforePattern, speakerAfterPattern, titledNamePattern} {
+		name := p.SubexpIndex("name")
+		for _, m := range p.FindAllStringSubmatch(text, -1) {
+			evidence[m[name]] = true
+		}
+	}
+	out := map[string]bool{}
+	for _, c := range dictionary {
+		if evidence[c.Name] {
+			out[c.Name] = true
+		}
+	}
+	return out
+}
+
+// trimTermCandidate drops a leading article, and at the start of a sentence
+// the first word, whose capital proves nothing.
+func trimTermCandidate(phrase string, initial bool) string {
+	words := strings.Fields(phrase)
+	if initial {
+		words = words[1:]
+	}
+	for len(words) > 0 {
+		switch strings.ToLower(words[0]) {
+		case "of", "the", "and", "a", "an":
+			words = words[1:]
+			continue
+		}
+		break
+	}
+	if len(words) == 0 || !unicode.IsUpper([]rune(words[0])[0]) {
+		return ""
+	}
+	if phrase := strings.Join(words, " "); phrase == strings.ToUpper(phrase) {
+		// All-caps runs are headings or emphasis.
+		return ""
+	}
+	if len(words) == 1 && (utf8.RuneCountInString(words[0]) < 3 || isIgnoredEntityName(words[0]) || commonCapitalized[strings.ToLower(words[0])]) {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// sentenceInitial reports whether the word at offset opens a sentence, line
+// or quotation.
+func sentenceInitial(text string, offset int) bool {
+	before := strings.TrimRight(text[:offset], " \t\"'“‘”’(")
+	if before == "" || strings.HasSuffix(before, "\n") {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return strings.ContainsRune(".!?:…\n", r)
+}
+
+// termKey is the same for every spelling of a term: case, hyphens and
+// spaces are ignored.
+func termKey(term string) string {
+	return strings.ToLower(termPartSplit.ReplaceAllString(term, ""))
+}
+
+// findTermForms finds every mid-sentence spelling of term. Capitalized
+// terms may be hyphenated, closed or open; lower-case coinages only
+// hyphenated or closed, since an open "well known" is often correct usage.
+func findTermForms(chapters []chapter, term string) termOccurrences {
+	parts := termPartSplit.Split(term, -1)
+	quoted := make([]string, len(parts))
+	for i, p := range parts {
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	joiner := `[\s-]?`
+	if !unicode.IsUpper([]rune(term)[0]) {
+		joiner = `-?`
+	}
+	pattern := regexp.MustCompile(`(?i)\b` + strings.Join(quoted, joiner) + `\b`)
+	occ := termOccurrences{forms: map[string]*TermVariant{}}
+	for _, ch := range chapters {
+		for _, m := range pattern.FindAllStringIndex(ch.text, -1) {
+			form := strings.Join(strings.Fields(ch.text[m[0]:m[1]]), " ")
+			if sentenceInitial(ch.text, m[0]) || (utf8.RuneCountInString(form) > 1 && form == strings.ToUpper(form)) {
+				// Sentence capitals and all-caps emphasis are not spellings.
+				continue
+			}
+			v, ok := occ.forms[form]
+			if !ok {
+				v = &TermVariant{Form: form, Chapters: []int{}}
+				occ.forms[form] = v
+			}
+			v.Count++
+			if n := len(v.Chapters); n == 0 || v.Chapters[n-1] != ch.index {
+				v.Chapters = append(v.Chapters, ch.index)
+			}
+			if occ.total == 0 {
+				occ.first = ch.index
+			}
+			occ.total++
+		}
+	}
+	return occ
+}
+
+// ordinaryWord reports a single capitalized word that is mostly written in
+// lower case: an ordinary word that sometimes opens a name ("Will", "will")
+// rather than a coinage.
+func ordinaryWord(term string, occ termOccurrences) bool {
+	if strings.ContainsAny(term, " -") || !unicode.IsUpper([]rune(term)[0]) {
+		return false
+	}
+	lower := 0
+	for _, v := range occ.forms {
+		if v.Form == strings.ToLower(v.Form) {
+			lower += v.Count
+		}
+	}
+	return lower*2 > occ.total-lower
+}
+
+// termIssue reports a term used in more than one form.
+func termIssue(term string, occ termOccurrences) (TermIssue, bool) {
+	if len(occ.forms) < 2 {
+		return TermIssue{}, false
+	}
+	variants := make([]TermVariant, 0, len(occ.forms))
+	for _, v := range occ.forms {
+		variants = append(variants, *v)
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		if variants[i].Count == variants[j].Count {
+			return variants[i].Form < variants[j].Form
+		}
+		return variants[i].Count > variants[j].Count
+	})
+	preferred := variants[0].Form
+	others := make([]string, 0, len(variants)-1)
+	for _, v := range variants[1:] {
+		others = append(others, fmt.Sprintf("%q (%d, Ch %s)", v.Form, v.Count, joinInts(v.Chapters)))
+	}
+	return TermIssue{
+		Preferred:  preferred,
+		Variants:   variants,
+		Suggestion: fmt.Sprintf("Use %q throughout; also written %s.", preferred, strings.Join(others, ", ")),
+	}, true
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
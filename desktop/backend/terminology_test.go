@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeTerminologyFlagsInconsistentForms(t *testing.T) {
+	chapters := []chapter{
+		{index: 1, text: "She swore to the Order of Ash at dawn. The sky-ship rose over the walls, and every sky-ship after it. Will said nothing."},
+		{index: 2, text: "Later she left the order of Ash for good. The sky-ship burned. Nobody will forget the Order of Ash. We will see, said Will."},
+		{index: 3, text: "A skyship came at dusk. She will not return, and they will not ask."},
+	}
+	text := chapters[0].text + "\n" + chapters[1].text + "\n" + chapters[2].text
+	report := analyzeTerminology(chapters, text, []CharacterEntry{{Name: "Will"}})
+
+	issues := map[string]TermIssue{}
+	for _, issue := range report.Issues {
+		issues[issue.Preferred] = issue
+	}
+	order, ok := issues["Order of Ash"]
+	if !ok || len(order.Variants) != 2 || order.Variants[1].Form != "order of Ash" || order.Variants[1].Chapters[0] != 2 {
+		t.Fatalf("want Order of Ash flagged against order of Ash, got %+v", report.Issues)
+	}
+	ship, ok := issues["sky-ship"]
+	if !ok || ship.Variants[1].Form != "skyship" || !strings.Contains(ship.Suggestion, `"skyship" (1, Ch 3)`) {
+		t.Fatalf("want sky-ship flagged against skyship, got %+v", report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if strings.EqualFold(issue.Preferred, "will") {
+			t.Fatalf("a character name that is also a common word should not be flagged: %+v", issue)
+		}
+	}
+	if len(report.Issues) != 2 || len(report.Flags) != 1 {
+		t.Fatalf("want 2 issues and a flag, got %+v", report)
+	}
+}
@@ -3,6 +3,9 @@ package backend
 import (
 	"book_dashboard/internal/aidetect"
 	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/gates"
+	"book_dashboard/internal/resources"
+	"book_dashboard/internal/scheduler"
 	"book_dashboard/internal/slop"
 	"book_dashboard/internal/timeline"
 )
@@ -11,6 +14,7 @@ type DashboardData struct {
 	BookTitle           string                    `json:"bookTitle"`
 	WordCount           int                       `json:"wordCount"`
 	MHDScore            int                       `json:"mhdScore"`
+	ScoreBreakdown      ScoreBreakdown            `json:"scoreBreakdown"`
 	Logs                []LogLine                 `json:"logs"`
 	Contradictions      []forensics.Contradiction `json:"contradictions"`
 	HealthIssues        []HealthIssue             `json:"healthIssues"`
@@ -22,17 +26,198 @@ type DashboardData struct {
 	GenreScores         []GenreScore              `json:"genreScores"`
 	GenreProvider       string                    `json:"genreProvider"`
 	GenreReasoning      string                    `json:"genreReasoning"`
+	GenreFallback       *FallbackReason           `json:"genreFallback"`
 	ChapterMetrics      []ChapterMetric           `json:"chapterMetrics"`
 	ChapterSummaries    []ChapterSummary          `json:"chapterSummaries"`
 	CharacterDictionary []CharacterEntry          `json:"characterDictionary"`
 	ChapterCount        int                       `json:"chapterCount"`
 	CompTitles          []CompTitle               `json:"compTitles"`
+	CompTitlesFallback  *FallbackReason           `json:"compTitlesFallback"`
 	Language            LanguageReport            `json:"language"`
+	Sensitivity         SensitivityReport         `json:"sensitivity"`
+	Novelty             NoveltyReport             `json:"novelty"`
+	Voice               VoiceReport               `json:"voice"`
+	NameHygiene         NameHygieneReport         `json:"nameHygiene"`
+	Terminology         TerminologyReport         `json:"terminology"`
+	Bookends            BookendReport             `json:"bookends"`
+	POV                 POVReport                 `json:"pov"`
+	Pacing              PacingReport              `json:"pacing"`
+	Stats               ManuscriptStats           `json:"stats"`
+	CrutchWords         CrutchWordReport          `json:"crutchWords"`
 	ProjectLocation     string                    `json:"projectLocation"`
+	PriorAnalysis       *PriorAnalysis            `json:"priorAnalysis"`
+	SourceIntegrity     *SourceIntegrity          `json:"sourceIntegrity"`
+	ModelDrift          []ModelDrift              `json:"modelDrift"`
+	Series              *SeriesReport             `json:"series"`
+	Anthology           *AnthologyReport          `json:"anthology"`
+	Drafts              *DraftProvenanceReport    `json:"drafts"`
+	ManuscriptType      string                    `json:"manuscriptType"`
+	DraftMarkers        DraftMarkerReport         `json:"draftMarkers"`
+	NumberStyle         NumberStyleReport         `json:"numberStyle"`
+	Permissions         PermissionsReport         `json:"permissions"`
+	LanguageMix         LanguageMixReport         `json:"languageMix"`
+	Nonfiction          *NonfictionReport         `json:"nonfiction"`
+	Annotations         []Annotation              `json:"annotations"`
+	Sections            map[string]string         `json:"sections"`
+	QualityMetrics      gates.Metrics             `json:"qualityMetrics"`
+	QualityGates        []gates.Result            `json:"qualityGates"`
+	Benchmarks          []Benchmark               `json:"benchmarks"`
 	RunStats            RunStats                  `json:"runStats"`
 	System              SystemDiagnostics         `json:"system"`
 }
 
+// PriorAnalysis is set when the same manuscript content was analyzed before,
+// so the UI can say "this manuscript was analyzed before as X".
+type PriorAnalysis struct {
+	Title          string `json:"title"`
+	SourceName     string `json:"sourceName"`
+	LastAnalyzedAt string `json:"lastAnalyzedAt"`
+}
+
+// SourceIntegrity is set when a saved report is reopened and says whether the
+// project's source file is still the one its findings were produced from.
+type SourceIntegrity struct {
+	Status         string `json:"status"`
+	SourcePath     string `json:"sourcePath"`
+	RecordedSHA256 string `json:"recordedSha256"`
+	CurrentSHA256  string `json:"currentSha256"`
+	Warning        string `json:"warning"`
+}
+
+// ModelDrift is set for each model whose installed build changed since its
+// answers to the canary excerpts were recorded. Material drift means score
+// changes since earlier runs may come from the model, not the manuscript.
+type ModelDrift struct {
+	Model               string   `json:"model"`
+	PreviousDigest      string   `json:"previousDigest"`
+	Digest              string   `json:"digest"`
+	ReferenceRecordedAt string   `json:"referenceRecordedAt"`
+	Material            bool     `json:"material"`
+	Changes             []string `json:"changes"`
+	Warning             string   `json:"warning"`
+}
+
+// SeriesReport is set when the run was analyzed as an installment of a
+// serialized work and compares it with the installments before it.
+type SeriesReport struct {
+	Name                string                   `json:"name"`
+	Installment         int                      `json:"installment"`
+	PriorInstallments   int                      `json:"priorInstallments"`
+	PriorWords          int                      `json:"priorWords"`
+	NewCharacters       []string                 `json:"newCharacters"`
+	ReturningCharacters []SeriesCharacterHistory `json:"returningCharacters"`
+	Timeline            []SeriesTimelineEvent    `json:"timeline"`
+	Notes               []string                 `json:"notes"`
+}
+
+// AnthologyReport is set when the manuscript was analyzed as a story
+// collection. Each story is one "chapter" of the run, so ChapterMetrics and
+// the per-chapter language scores are per story too.
+type AnthologyReport struct {
+	// Detection says how boundaries were found: bylines, headings or
+	// chapters.
+	Detection string        `json:"detection"`
+	Stories   []StoryReport `json:"stories"`
+	Authors   []string      `json:"authors"`
+	GenreMix  []GenreCount  `json:"genreMix"`
+	MedianAI  float64       `json:"medianAi"`
+	Flags     []string      `json:"flags"`
+}
+
+type StoryReport struct {
+	Index            int     `json:"index"`
+	Title            string  `json:"title"`
+	Author           string  `json:"author"`
+	WordCount        int     `json:"wordCount"`
+	TopGenre         string  `json:"topGenre"`
+	TopGenreScore    float64 `json:"topGenreScore"`
+	GenreProvider    string  `json:"genreProvider"`
+	AI               StoryAI `json:"ai"`
+	SpellingScore    int     `json:"spellingScore"`
+	GrammarScore     int     `json:"grammarScore"`
+	ReadabilityScore int     `json:"readabilityScore"`
+	NeedsAttention   bool    `json:"needsAttention"`
+}
+
+// StoryAI summarizes the AI-likelihood windows that overlap a story.
+type StoryAI struct {
+	Windows int     `json:"windows"`
+	Mean    float64 `json:"mean"`
+	Max     float64 `json:"max"`
+}
+
+type GenreCount struct {
+	Genre   string `json:"genre"`
+	Stories int    `json:"stories"`
+}
+
+// NonfictionReport holds the checks run for nonfiction and memoir
+// manuscripts in place of plot beats and character contradictions.
+type NonfictionReport struct {
+	ManuscriptType string          `json:"manuscriptType"`
+	RepeatedClaims []RepeatedClaim `json:"repeatedClaims"`
+	HeadingIssues  []HeadingIssue  `json:"headingIssues"`
+	Placeholders   []Placeholder   `json:"placeholders"`
+	Flags          []string        `json:"flags"`
+}
+
+// RepeatedClaim is a statement made more than once, in any word order.
+type RepeatedClaim struct {
+	Text     string `json:"text"`
+	Count    int    `json:"count"`
+	Chapters []int  `json:"chapters"`
+}
+
+type HeadingIssue struct {
+	Heading string `json:"heading"`
+	Problem string `json:"problem"`
+}
+
+// DraftMarkerReport is the "not ready" checklist: every TK, TODO, bracketed
+// placeholder or highlighted passage still in the manuscript.
+type DraftMarkerReport struct {
+	Ready   bool          `json:"ready"`
+	Markers []DraftMarker `json:"markers"`
+	// Penalty is what the markers took off the MHD score.
+	Penalty int `json:"penalty"`
+}
+
+type DraftMarker struct {
+	Kind      string `json:"kind"`
+	Marker    string `json:"marker"`
+	Chapter   int    `json:"chapter"`
+	Paragraph int    `json:"paragraph"`
+	Offset    int    `json:"offset"`
+	Location  string `json:"location"`
+	Context   string `json:"context"`
+}
+
+// Placeholder is unfinished drafting that blocks submission: a citation
+// placeholder, a TODO or TK marker, or an unresolved cross-reference.
+type Placeholder struct {
+	Kind      string `json:"kind"`
+	Chapter   int    `json:"chapter"`
+	Paragraph int    `json:"paragraph"`
+	Location  string `json:"location"`
+	Marker    string `json:"marker"`
+	Context   string `json:"context"`
+}
+
+type SeriesCharacterHistory struct {
+	Name             string `json:"name"`
+	FirstInstallment int    `json:"firstInstallment"`
+	LastInstallment  int    `json:"lastInstallment"`
+	PriorMentions    int    `json:"priorMentions"`
+	Mentions         int    `json:"mentions"`
+}
+
+type SeriesTimelineEvent struct {
+	Installment int    `json:"installment"`
+	Chapter     int    `json:"chapter"`
+	TimeMarker  string `json:"timeMarker"`
+	Event       string `json:"event"`
+}
+
 type LogLine struct {
 	Time    string `json:"time"`
 	Level   string `json:"level"`
@@ -59,6 +244,9 @@ type PlotStructureReport struct {
 	SelectedStructure string                     `json:"selectedStructure"`
 	Probabilities     []PlotStructureProbability `json:"probabilities"`
 	Reasoning         string                     `json:"reasoning"`
+	// Fallback is set when the model call failed and the beats are the
+	// heuristic's.
+	Fallback *FallbackReason `json:"fallback,omitempty"`
 }
 
 type GenreScore struct {
@@ -76,11 +264,15 @@ type ChapterMetric struct {
 	GenreProvider  string       `json:"genreProvider"`
 	GenreReasoning string       `json:"genreReasoning"`
 	GenreBreakdown []GenreScore `json:"genreBreakdown"`
+	ReadingLevel   ReadingLevel `json:"readingLevel"`
 }
 
 type CompTitle struct {
-	Title string `json:"title"`
-	Tier  string `json:"tier"`
+	Title      string  `json:"title"`
+	Author     string  `json:"author,omitempty"`
+	Tier       string  `json:"tier"`
+	Similarity float64 `json:"similarity,omitempty"`
+	Provider   string  `json:"provider,omitempty"`
 }
 
 type ChapterSummary struct {
@@ -88,27 +280,46 @@ type ChapterSummary struct {
 	Title   string   `json:"title"`
 	Summary string   `json:"summary"`
 	Events  []string `json:"events"`
+	// Fallback says why the derived summary failed the summary guard and
+	// was replaced by the chapter's opening (meta_text, too_short,
+	// too_long); see guardChapterSummary.
+	Fallback string `json:"fallback,omitempty"`
 }
 
 type CharacterChapterRecord struct {
-	Chapter int      `json:"chapter"`
-	Title   string   `json:"title"`
-	Summary string   `json:"summary"`
-	Actions []string `json:"actions"`
-	Events  []string `json:"events"`
+	Chapter int    `json:"chapter"`
+	Title   string `json:"title"`
+	// Mentions counts the chapter's uses of the name as a whole word;
+	// PronounMentions estimates its pronoun references, see
+	// estimatePronounMentions.
+	Mentions        int      `json:"mentions"`
+	PronounMentions int      `json:"pronounMentions"`
+	Summary         string   `json:"summary"`
+	Actions         []string `json:"actions"`
+	Events          []string `json:"events"`
 }
 
 type CharacterEntry struct {
-	Name             string                   `json:"name"`
-	Description      string                   `json:"description"`
-	FirstSeenChapter int                      `json:"firstSeenChapter"`
-	LastSeenChapter  int                      `json:"lastSeenChapter"`
-	TotalMentions    int                      `json:"totalMentions"`
-	Chapters         []CharacterChapterRecord `json:"chapters"`
+	Name string `json:"name"`
+	// Aliases are the other names and titled references ("Dr. Cole") merged
+	// into this character.
+	Aliases          []string `json:"aliases,omitempty"`
+	Description      string   `json:"description"`
+	FirstSeenChapter int      `json:"firstSeenChapter"`
+	LastSeenChapter  int      `json:"lastSeenChapter"`
+	TotalMentions    int      `json:"totalMentions"`
+	// PronounMentions is the estimated number of pronoun references; the
+	// dictionary is ranked by TotalMentions plus PronounMentions.
+	PronounMentions int                      `json:"pronounMentions"`
+	Chapters        []CharacterChapterRecord `json:"chapters"`
 }
 
 type HealthIssue struct {
-	ID            string `json:"id"`
+	ID string `json:"id"`
+	// AnchorID names the issue by its passages, stable across runs; see
+	// anchorFindings.
+	AnchorID      string `json:"anchorId,omitempty"`
+	Kind          string `json:"kind"`
 	Entity        string `json:"entity"`
 	Severity      string `json:"severity"`
 	Description   string `json:"description"`
@@ -117,22 +328,96 @@ type HealthIssue struct {
 	ContextA      string `json:"contextA"`
 	ContextB      string `json:"contextB"`
 	DictionaryRef string `json:"dictionaryRef"`
+	// PageA and PageB are the PDF pages of ContextA and ContextB; they are 0
+	// for other sources or when the passage could not be placed.
+	PageA int `json:"pageA,omitempty"`
+	PageB int `json:"pageB,omitempty"`
 }
 
 type LanguageReport struct {
-	SpellingScore      int      `json:"spellingScore"`
-	GrammarScore       int      `json:"grammarScore"`
-	ReadabilityScore   int      `json:"readabilityScore"`
-	AgeCategory        string   `json:"ageCategory"`
-	SpellingProvider   string   `json:"spellingProvider"`
-	SafetyProvider     string   `json:"safetyProvider"`
-	HeuristicFallback  bool     `json:"heuristicFallback"`
-	ProfanityScore     int      `json:"profanityScore"`
-	ExplicitScore      int      `json:"explicitScore"`
-	ViolenceScore      int      `json:"violenceScore"`
-	ProfanityInstances int      `json:"profanityInstances"`
-	ExplicitInstances  int      `json:"explicitInstances"`
-	Notes              []string `json:"notes"`
+	SpellingScore    int `json:"spellingScore"`
+	GrammarScore     int `json:"grammarScore"`
+	ReadabilityScore int `json:"readabilityScore"`
+	// ReadingLevel is the whole manuscript's; ReadabilityScore is its Flesch
+	// reading ease.
+	ReadingLevel      ReadingLevel `json:"readingLevel"`
+	AgeCategory       string       `json:"ageCategory"`
+	AgeRating         AgeRating    `json:"ageRating"`
+	ContentWarnings   []string     `json:"contentWarnings"`
+	SpellingProvider  string       `json:"spellingProvider"`
+	SafetyProvider    string       `json:"safetyProvider"`
+	HeuristicFallback bool         `json:"heuristicFallback"`
+	// SpellingFallback and SafetyFallback say why LanguageTool or the
+	// safety model was not used.
+	SpellingFallback   *FallbackReason `json:"spellingFallback,omitempty"`
+	SafetyFallback     *FallbackReason `json:"safetyFallback,omitempty"`
+	ProfanityScore     int             `json:"profanityScore"`
+	ExplicitScore      int             `json:"explicitScore"`
+	ViolenceScore      int             `json:"violenceScore"`
+	ProfanityInstances int             `json:"profanityInstances"`
+	ExplicitInstances  int             `json:"explicitInstances"`
+	// IssueBreakdown groups LanguageTool matches by editorial category; empty
+	// when LanguageTool was unavailable.
+	IssueBreakdown []LanguageToolCategory `json:"issueBreakdown"`
+	// Chapters scores each chapter on its own so copyediting can be targeted.
+	Chapters []ChapterLanguageScore `json:"chapters"`
+	// Dialect is the English variant LanguageTool checked against.
+	Dialect             string  `json:"dialect"`
+	SpellingIssuesPer1k float64 `json:"spellingIssuesPer1k"`
+	GrammarIssuesPer1k  float64 `json:"grammarIssuesPer1k"`
+	// DialogueGrammar is how grammar matches inside quoted dialogue were
+	// scored (include, downweight, exclude); DialogueMatches counts them.
+	DialogueGrammar string   `json:"dialogueGrammar"`
+	DialogueMatches int      `json:"dialogueMatches"`
+	Notes           []string `json:"notes"`
+}
+
+type ChapterLanguageScore struct {
+	Chapter          int    `json:"chapter"`
+	Title            string `json:"title"`
+	WordCount        int    `json:"wordCount"`
+	SpellingScore    int    `json:"spellingScore"`
+	GrammarScore     int    `json:"grammarScore"`
+	ReadabilityScore int    `json:"readabilityScore"`
+	SpellingIssues   int    `json:"spellingIssues"`
+	GrammarIssues    int    `json:"grammarIssues"`
+	NeedsAttention   bool   `json:"needsAttention"`
+}
+
+type LanguageToolCategory struct {
+	Category string             `json:"category"`
+	Label    string             `json:"label"`
+	Count    int                `json:"count"`
+	TopRules []LanguageToolRule `json:"topRules"`
+}
+
+type LanguageToolRule struct {
+	RuleID      string `json:"ruleId"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+}
+
+// SensitivityReport is advisory only: every flag is a passage for a human
+// sensitivity reader to look at, never a score input.
+type SensitivityReport struct {
+	Provider   string            `json:"provider"`
+	Disclaimer string            `json:"disclaimer"`
+	Flags      []SensitivityFlag `json:"flags"`
+	Notes      []string          `json:"notes"`
+	// Fallback is set when the model review failed.
+	Fallback *FallbackReason `json:"fallback,omitempty"`
+}
+
+type SensitivityFlag struct {
+	Chapter   int    `json:"chapter"`
+	Title     string `json:"title"`
+	Category  string `json:"category"`
+	Term      string `json:"term"`
+	Quote     string `json:"quote"`
+	Context   string `json:"context"`
+	Rationale string `json:"rationale"`
+	Provider  string `json:"provider"`
+	AnchorID  string `json:"anchorId,omitempty"`
 }
 
 type RunStats struct {
@@ -147,6 +432,24 @@ type RunStats struct {
 	TimelineCount      int    `json:"timelineCount"`
 	ContradictionCount int    `json:"contradictionCount"`
 	SlopFlagCount      int    `json:"slopFlagCount"`
+	// SourceArchivePath is the exact file this run analyzed, kept under the
+	// project's sources directory.
+	SourceArchivePath string `json:"sourceArchivePath,omitempty"`
+	// Resources is the CPU and memory profile sampled while the run worked.
+	Resources resources.Profile `json:"resources"`
+	// Concurrency records how each adaptive limiter behaved.
+	Concurrency []scheduler.Stats `json:"concurrency"`
+	// CachedStages lists the stages whose results were reused from an
+	// earlier run of the same text.
+	CachedStages []string `json:"cachedStages,omitempty"`
+	// Profile is the analysis profile the run used: quick, standard or deep.
+	Profile string `json:"profile"`
+	// Inference records, per Ollama model, whether requests ran on the GPU
+	// or the CPU and the tokens per second they achieved.
+	Inference []ModelInference `json:"inference,omitempty"`
+	// RescoredFrom is the run whose stored signals this run re-weighed
+	// instead of analyzing the manuscript again.
+	RescoredFrom string `json:"rescoredFrom,omitempty"`
 }
 
 type SystemDiagnostics struct {
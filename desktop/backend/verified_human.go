@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/workspace"
+)
+
+// VerifiedHuman lists the chapters (1-based) and verbatim passages the author
+// has marked as known human writing.
+type VerifiedHuman struct {
+	Chapters []int    `json:"chapters"`
+	Passages []string `json:"passages"`
+}
+
+// LoadVerifiedHuman returns the project's verified-human list, empty when no
+// project is loaded.
+func LoadVerifiedHuman(projectLocation string) (VerifiedHuman, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return VerifiedHuman{Chapters: []int{}, Passages: []string{}}, nil
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return VerifiedHuman{}, err
+	}
+	return verifiedHumanFromSettings(settings), nil
+}
+
+// SetVerifiedHuman replaces the project's verified-human list; it applies from
+// the next analysis run.
+func SetVerifiedHuman(projectLocation string, in VerifiedHuman) (VerifiedHuman, error) {
+	if strings.TrimSpace(projectLocation) == "" {
+		return VerifiedHuman{}, fmt.Errorf("no project loaded")
+	}
+	settings, err := workspace.LoadProjectSettings(projectLocation)
+	if err != nil {
+		return VerifiedHuman{}, err
+	}
+	settings.VerifiedHumanChapters = nil
+	seen := map[int]bool{}
+	for _, ch := range in.Chapters {
+		if ch < 1 {
+			return VerifiedHuman{}, fmt.Errorf("invalid chapter number %d", ch)
+		}
+		if !seen[ch] {
+			seen[ch] = true
+			settings.VerifiedHumanChapters = append(settings.VerifiedHumanChapters, ch)
+		}
+	}
+	sort.Ints(settings.VerifiedHumanChapters)
+	settings.VerifiedHumanPassages = nil
+	for _, passage := range in.Passages {
+		if passage = strings.TrimSpace(passage); passage != "" {
+			settings.VerifiedHumanPassages = append(settings.VerifiedHumanPassages, passage)
+		}
+	}
+	if err := workspace.SaveProjectSettings(projectLocation, settings); err != nil {
+		return VerifiedHuman{}, err
+	}
+	return verifiedHumanFromSettings(settings), nil
+}
+
+func verifiedHumanFromSettings(settings workspace.ProjectSettings) VerifiedHuman {
+	out := VerifiedHuman{Chapters: []int{}, Passages: []string{}}
+	out.Chapters = append(out.Chapters, settings.VerifiedHumanChapters...)
+	out.Passages = append(out.Passages, settings.VerifiedHumanPassages...)
+	return out
+}
+
+// humanExemptSpans converts the verified-human chapters and passages into
+// merged aidetect word ranges. It also returns the chapters and passages it
+// could not find in this draft, so the run can say so instead of quietly
+// counting them.
+func humanExemptSpans(text string, chapters []chapter, verified VerifiedHuman) ([]aidetect.EvidenceSpan, []string) {
+	spans := []aidetect.EvidenceSpan{}
+	missing := []string{}
+	if len(verified.Chapters) == 0 && len(verified.Passages) == 0 {
+		return spans, missing
+	}
+
+	chapterSpans := map[int]aidetect.EvidenceSpan{}
+	for _, p := range paragraphOffsets(text, chapters) {
+		span, ok := chapterSpans[p.chapter]
+		if !ok {
+			span.Start = p.start
+		}
+		span.End = p.end
+		chapterSpans[p.chapter] = span
+	}
+	for _, ch := range verified.Chapters {
+		span, ok := chapterSpans[ch]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("chapter %d", ch))
+			continue
+		}
+		spans = append(spans, span)
+	}
+
+	words := aidetect.Words(text)
+	for _, passage := range verified.Passages {
+		needle := aidetect.Words(passage)
+		found := false
+		for from := 0; len(needle) > 0; {
+			at := indexWords(words, from, needle)
+			if at < 0 {
+				break
+			}
+			spans = append(spans, aidetect.EvidenceSpan{Start: at, End: at + len(needle)})
+			found = true
+			from = at + len(needle)
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("passage %q", firstWords(passage, 8)))
+		}
+	}
+	return mergeSpans(spans), missing
+}
+
+func mergeSpans(spans []aidetect.EvidenceSpan) []aidetect.EvidenceSpan {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	out := make([]aidetect.EvidenceSpan, 0, len(spans))
+	for _, span := range spans {
+		if n := len(out); n > 0 && span.Start <= out[n-1].End {
+			out[n-1].End = max(out[n-1].End, span.End)
+			continue
+		}
+		out = append(out, span)
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+)
+
+func TestHumanExemptSpansCoverChaptersAndPassages(t *testing.T) {
+	text := "Chapter 1\nOne two three four.\nFive six seven.\n\nChapter 2\nEight nine ten.\nEleven twelve."
+	verified := VerifiedHuman{Chapters: []int{2, 7}, Passages: []string{"Three, four.", "never written"}}
+	spans, missing := humanExemptSpans(text, splitChapters(text), verified)
+	want := []aidetect.EvidenceSpan{{Start: 4, End: 6}, {Start: 11, End: 16}}
+	if !reflect.DeepEqual(spans, want) {
+		t.Fatalf("expected spans %v, got %v", want, spans)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("expected the unknown chapter and passage reported, got %v", missing)
+	}
+}
+
+func TestSetVerifiedHumanPersistsCleanList(t *testing.T) {
+	project := t.TempDir()
+	if _, err := SetVerifiedHuman(project, VerifiedHuman{Chapters: []int{0}}); err == nil {
+		t.Fatal("expected chapter 0 to be rejected")
+	}
+	saved, err := SetVerifiedHuman(project, VerifiedHuman{Chapters: []int{3, 1, 3}, Passages: []string{"  Published story.  ", " "}})
+	if err != nil {
+		t.Fatalf("set verified human: %v", err)
+	}
+	loaded, err := LoadVerifiedHuman(project)
+	if err != nil {
+		t.Fatalf("load verified human: %v", err)
+	}
+	want := VerifiedHuman{Chapters: []int{1, 3}, Passages: []string{"Published story."}}
+	if !reflect.DeepEqual(saved, want) || !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("expected %+v, got saved %+v loaded %+v", want, saved, loaded)
+	}
+}
@@ -0,0 +1,54 @@
+// Command mhd-analyze analyzes a DOCX or PDF manuscript and writes the
+// linear Markdown report, saving the project under ~/ManuscriptHealth as the
+// desktop app does.
+//
+//	go run ./cmd/mhd-analyze -profile quick manuscript.docx > report.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"book_dashboard/desktop/backend"
+	"book_dashboard/desktop/pkg/mhd"
+)
+
+func main() {
+	profile := flag.String("profile", backend.ProfileStandard, "analysis profile: "+strings.Join(backend.AnalysisProfiles, ", "))
+	out := flag.String("o", "", "write the report to this file instead of stdout")
+	quiet := flag.Bool("q", false, "do not print progress to stderr")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: mhd-analyze [-profile quick|standard|deep] [-o report.md] [-q] <manuscript.docx | manuscript.pdf>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !backend.IsAnalysisProfile(*profile) {
+		log.Fatalf("unknown analysis profile %q", *profile)
+	}
+
+	opts := mhd.Options{Profile: *profile}
+	if !*quiet {
+		opts.OnProgress = func(percent int, stage, detail string) {
+			fmt.Fprintf(os.Stderr, "%3d%% %s: %s\n", percent, stage, detail)
+		}
+	}
+	result, err := mhd.AnalyzeFile(flag.Arg(0), opts)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	report := mhd.Markdown(result)
+	if *out == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := os.WriteFile(*out, []byte(report), 0o644); err != nil {
+		log.Fatalf("write report: %v", err)
+	}
+}
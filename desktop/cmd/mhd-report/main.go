@@ -0,0 +1,79 @@
+// Command mhd-report renders a saved project's report.json as a linear,
+// screen-reader-friendly Markdown report, or with -ai-packet as an HTML AI
+// review packet. -profile author writes the author-facing report, without the
+// AI-likelihood internals and reviewer notes. -mask-profanity masks quoted
+// profanity (f--k), as the project setting mask_profanity does.
+//
+//	go run ./cmd/mhd-report ~/ManuscriptHealth/projects/<project_id> > report.md
+//	go run ./cmd/mhd-report -profile author -o author.md ~/ManuscriptHealth/projects/<project_id>
+//	go run ./cmd/mhd-report -ai-packet 10 -o packet.html ~/ManuscriptHealth/projects/<project_id>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"book_dashboard/desktop/backend"
+	"book_dashboard/internal/ingest"
+	"book_dashboard/internal/workspace"
+)
+
+func main() {
+	out := flag.String("o", "", "write the report to this file instead of stdout")
+	profile := flag.String("profile", backend.ExportProfileInternal, "export profile: "+strings.Join(backend.ExportProfiles, ", "))
+	packet := flag.Int("ai-packet", 0, "write an HTML AI review packet of this many passages instead of the report")
+	maskProfanity := flag.Bool("mask-profanity", false, "mask quoted profanity and explicit terms (f--k); counts and locations are kept")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: mhd-report [-o report.md] [-profile internal|author] [-ai-packet N] [-mask-profanity] <project dir | report.json>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	exportProfile, err := backend.ParseExportProfile(*profile)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *packet > 0 && exportProfile == backend.ExportProfileAuthor {
+		log.Fatalf("the AI review packet is internal; it has no author-facing profile")
+	}
+
+	data, err := backend.OpenProjectReport(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if data.SourceIntegrity != nil && data.SourceIntegrity.Warning != "" {
+		fmt.Fprintln(os.Stderr, "warning:", data.SourceIntegrity.Warning)
+	}
+	mask := *maskProfanity || backend.MaskProfanityEnabled(data.ProjectLocation)
+	if mask {
+		data = backend.MaskProfanity(data)
+	}
+	report := backend.PlainReportFor(data, exportProfile)
+	if *packet > 0 {
+		text := ""
+		if data.SourceIntegrity != nil && data.SourceIntegrity.Status == workspace.SourceUnchanged {
+			if parsed, err := ingest.ParseFile(data.SourceIntegrity.SourcePath); err == nil {
+				text = parsed.Text
+			} else {
+				fmt.Fprintln(os.Stderr, "warning: source unreadable, packet lists passages without text:", err)
+			}
+		}
+		if mask {
+			text = backend.MaskProfanityText(text)
+		}
+		report = backend.AIReviewPacket(data, text, *packet)
+	}
+	if *out == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := os.WriteFile(*out, []byte(report), 0o644); err != nil {
+		log.Fatalf("write report: %v", err)
+	}
+}
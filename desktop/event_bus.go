@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"book_dashboard/desktop/backend"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// wailsEventBus forwards bus events to the frontend as Wails events and shows
+// notifications as message dialogs.
+type wailsEventBus struct {
+	ctx context.Context
+}
+
+func newWailsEventBus(ctx context.Context) *wailsEventBus {
+	return &wailsEventBus{ctx: ctx}
+}
+
+func (b *wailsEventBus) Emit(name string, payload map[string]any) {
+	if name != backend.EventNotification {
+		runtime.EventsEmit(b.ctx, name, payload)
+		return
+	}
+	dialog := runtime.InfoDialog
+	if payload["level"] == backend.NotificationError {
+		dialog = runtime.ErrorDialog
+	}
+	_, _ = runtime.MessageDialog(b.ctx, runtime.MessageDialogOptions{
+		Type:    dialog,
+		Title:   fmt.Sprint(payload["title"]),
+		Message: fmt.Sprint(payload["message"]),
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"book_dashboard/desktop/backend"
+)
+
+type recordingBus struct {
+	names    []string
+	payloads []map[string]any
+}
+
+func (r *recordingBus) Emit(name string, payload map[string]any) {
+	r.names = append(r.names, name)
+	r.payloads = append(r.payloads, payload)
+}
+
+func TestAppRoutesProgressAndTracesThroughBus(t *testing.T) {
+	t.Setenv("PATH", "")
+	t.Setenv("MHD_DISABLE_SYSTEM_BIN_FALLBACK", "1")
+	t.Setenv("LANGUAGETOOL_JAR", "")
+	t.Setenv("OLLAMA_URL", "http://127.0.0.1:9")
+	t.Setenv("LANGUAGETOOL_URL", "http://127.0.0.1:9")
+
+	bus := &recordingBus{}
+	app := NewApp()
+	app.events = bus
+	app.emitProgress(12, "INGEST", "parsed")
+	app.services.EnsureReady(app.events)
+
+	if len(bus.names) == 0 || bus.names[0] != backend.EventAnalysisProgress || bus.payloads[0]["percent"] != 12 {
+		t.Fatalf("expected the progress event first, got %v %v", bus.names, bus.payloads)
+	}
+	if !strings.Contains(strings.Join(bus.names, " "), backend.EventServiceTrace) {
+		t.Fatalf("expected service traces on the bus, got %v", bus.names)
+	}
+}
@@ -1,16 +1,17 @@
 import { FormEvent, useEffect, useMemo, useRef, useState } from "react";
 import "vis-timeline/styles/vis-timeline-graph2d.css";
-import { AnalyzeExcerpt, AnalyzeFile, GetDashboard, InstallMissingDependencies, PickAndAnalyzeFile } from "../wailsjs/go/main/App";
-import { EventsOn } from "../wailsjs/runtime/runtime";
+import { AnalyzeExcerpt, AnalyzeFileWithProfile, EnqueueFiles, GetDashboard, GetQueueStatus, InstallMissingDependencies, PickAndAnalyzeFileWithProfile } from "../wailsjs/go/main/App";
+import { EventsOn, OnFileDrop, OnFileDropOff } from "../wailsjs/runtime/runtime";
 import { AnalysisForms } from "./components/AnalysisForms";
 import { HeaderMetrics } from "./components/HeaderMetrics";
 import { LiveConsole } from "./components/LiveConsole";
+import { QueuePanel } from "./components/QueuePanel";
 import { AITab } from "./tabs/AITab";
 import { LanguageTab } from "./tabs/LanguageTab";
 import { MarketTab } from "./tabs/MarketTab";
 import { StructureTab } from "./tabs/StructureTab";
 import { DictionaryTab } from "./tabs/DictionaryTab";
-import { DashboardData, emptyData, LogFilter, LogLine, TabName } from "./types";
+import { AnalysisProfile, DashboardData, emptyData, LogFilter, LogLine, ProgressEvent, QueueItem, StageProgress, TabName } from "./types";
 import "./App.css";
 
 const STARTUP_STAGE = "SETUP";
@@ -117,13 +118,16 @@ function App() {
   ]);
   const [excerpt, setExcerpt] = useState("");
   const [filePath, setFilePath] = useState("");
+  const [profile, setProfile] = useState<AnalysisProfile>("standard");
+  const [queueItems, setQueueItems] = useState<QueueItem[]>([]);
   const [loading, setLoading] = useState(false);
   const [logFilter, setLogFilter] = useState<LogFilter>("ALL");
   const [logQuery, setLogQuery] = useState("");
   const [autoScroll, setAutoScroll] = useState(true);
   const [liveProgressLogs, setLiveProgressLogs] = useState<LogLine[]>([]);
   const [chapterSubtasks, setChapterSubtasks] = useState<string[]>([]);
-  const [progress, setProgress] = useState({ percent: 0, stage: "IDLE", detail: "" });
+  const [progress, setProgress] = useState<{ percent: number; stage: string; detail: string; remainingMs?: number; chapter?: number; chapterCount?: number }>({ percent: 0, stage: "IDLE", detail: "" });
+  const [pipeline, setPipeline] = useState<StageProgress[]>([]);
   const [phaseElapsedSeconds, setPhaseElapsedSeconds] = useState(0);
   const [overallElapsedSeconds, setOverallElapsedSeconds] = useState(0);
   const [installingDeps, setInstallingDeps] = useState(false);
@@ -177,7 +181,7 @@ function App() {
   }, []);
 
   useEffect(() => {
-    const off = EventsOn("analysis_progress", (payload: { percent: number; stage: string; detail: string }) => {
+    const off = EventsOn("analysis_progress", (payload: ProgressEvent) => {
       if (!payload) return;
       const stage = (payload.stage ?? "RUNNING").toUpperCase();
       const detail = payload.detail ?? "";
@@ -185,7 +189,13 @@ function App() {
         percent: payload.percent ?? 0,
         stage,
         detail,
+        remainingMs: payload.remainingMs,
+        chapter: payload.chapter,
+        chapterCount: payload.chapterCount,
       });
+      if (payload.stages) {
+        setPipeline(payload.stages);
+      }
 
       if (stage === STARTUP_STAGE && detail.trim() !== "") {
         (window as BootWindow).__mhdBootUpdate?.("initializing", detail, detail);
@@ -224,6 +234,32 @@ function App() {
     };
   }, []);
 
+  useEffect(() => {
+    GetQueueStatus()
+      .then((status) => setQueueItems((status?.items ?? []) as unknown as QueueItem[]))
+      .catch(() => undefined);
+    const off = EventsOn("queue_progress", (item: QueueItem) => {
+      if (!item) return;
+      setQueueItems((prev) => {
+        const i = prev.findIndex((p) => p.id === item.id);
+        if (i < 0) return [...prev, item];
+        const next = [...prev];
+        next[i] = item;
+        return next;
+      });
+    });
+    OnFileDrop((_x: number, _y: number, paths: string[]) => {
+      const manuscripts = paths.filter((p) => /\.(docx|pdf)$/i.test(p));
+      if (manuscripts.length > 0) {
+        void EnqueueFiles(manuscripts);
+      }
+    }, false);
+    return () => {
+      off();
+      OnFileDropOff();
+    };
+  }, []);
+
   useEffect(() => {
     const off = EventsOn("service_trace", (payload: { time: string; level: string; message: string; detail: string }) => {
       if (!payload) return;
@@ -286,6 +322,7 @@ function App() {
     setLiveProgressLogs([]);
     setChapterSubtasks([]);
     setProgress({ percent: 0, stage: "ANALYSIS", detail: "Starting excerpt analysis..." });
+    setPipeline([]);
     setLoading(true);
     try {
       const next = await AnalyzeExcerpt(excerpt);
@@ -305,9 +342,10 @@ function App() {
     setLiveProgressLogs([]);
     setChapterSubtasks([]);
     setProgress({ percent: 0, stage: "ANALYSIS", detail: "Starting file analysis..." });
+    setPipeline([]);
     setLoading(true);
     try {
-      const next = await AnalyzeFile(filePath);
+      const next = await AnalyzeFileWithProfile(filePath, profile);
       setData(next as unknown as DashboardData);
     } finally {
       setLoading(false);
@@ -323,9 +361,10 @@ function App() {
     setLiveProgressLogs([]);
     setChapterSubtasks([]);
     setProgress({ percent: 0, stage: "ANALYSIS", detail: "Opening file picker..." });
+    setPipeline([]);
     setLoading(true);
     try {
-      const next = await PickAndAnalyzeFile();
+      const next = await PickAndAnalyzeFileWithProfile(profile);
       setData(next as unknown as DashboardData);
     } finally {
       setLoading(false);
@@ -452,21 +491,41 @@ function App() {
             setExcerpt={setExcerpt}
             filePath={filePath}
             setFilePath={setFilePath}
+            profile={profile}
+            setProfile={setProfile}
             loading={loading}
             onAnalyzeExcerpt={onAnalyzeExcerpt}
             onAnalyzeFile={onAnalyzeFile}
             onPickAndAnalyze={onPickAndAnalyze}
           />
 
+          <QueuePanel items={queueItems} />
+
           {loading ? (
             <section className="progress-wrap">
               <div className="progress-head">
-                <strong>{progress.stage}</strong>
-                <span>{progress.percent}%</span>
+                <strong>
+                  {progress.stage}
+                  {progress.chapter && progress.chapterCount ? ` · Chapter ${progress.chapter}/${progress.chapterCount}` : ""}
+                </strong>
+                <span>
+                  {progress.percent}%
+                  {progress.remainingMs !== undefined && progress.remainingMs >= 0 ? ` · about ${formatClock(Math.round(progress.remainingMs / 1000))} left` : ""}
+                </span>
               </div>
               <div className="progress-bar">
                 <div className="progress-fill" style={{ width: `${progress.percent}%` }} />
               </div>
+              {pipeline.length > 0 ? (
+                <ol className="progress-pipeline">
+                  {pipeline.map((s) => (
+                    <li key={s.stage} className={`pipeline-${s.status}`} title={s.estimatedMs >= 0 ? `estimated ${formatClock(Math.round(s.estimatedMs / 1000))}` : "no estimate yet"}>
+                      {s.stage}
+                      {s.status === "done" || s.status === "running" ? ` ${formatClock(Math.round(s.elapsedMs / 1000))}` : ""}
+                    </li>
+                  ))}
+                </ol>
+              ) : null}
               <p className="muted">{progress.detail}</p>
             </section>
           ) : null}
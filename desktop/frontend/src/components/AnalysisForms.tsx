@@ -1,16 +1,25 @@
 import { FormEvent } from "react";
+import { AnalysisProfile } from "../types";
 
 type Props = {
   excerpt: string;
   setExcerpt: (v: string) => void;
   filePath: string;
   setFilePath: (v: string) => void;
+  profile: AnalysisProfile;
+  setProfile: (v: AnalysisProfile) => void;
   loading: boolean;
   onAnalyzeExcerpt: (e: FormEvent) => void;
   onAnalyzeFile: (e: FormEvent) => void;
   onPickAndAnalyze: () => void;
 };
 
+const profiles: Array<{ value: AnalysisProfile; label: string; title: string }> = [
+  { value: "quick", label: "Quick Scan", title: "Offline heuristics only; about a minute" },
+  { value: "standard", label: "Standard", title: "Follows the project settings" },
+  { value: "deep", label: "Deep Dive", title: "All model passes, embeddings and per-sentence AI attribution" },
+];
+
 export function AnalysisForms(props: Props) {
   return (
     <>
@@ -21,6 +30,11 @@ export function AnalysisForms(props: Props) {
 
       <form className="analyze-form file-form" onSubmit={props.onAnalyzeFile}>
         <input value={props.filePath} onChange={(e) => props.setFilePath(e.target.value)} placeholder="Absolute path to .docx/.pdf, then click Analyze File" />
+        <select value={props.profile} onChange={(e) => props.setProfile(e.target.value as AnalysisProfile)} disabled={props.loading} aria-label="Analysis profile">
+          {profiles.map((p) => (
+            <option key={p.value} value={p.value} title={p.title}>{p.label}</option>
+          ))}
+        </select>
         <button type="button" onClick={props.onPickAndAnalyze} disabled={props.loading} className="ghost">{props.loading ? "Analyzing..." : "Pick File..."}</button>
         <button type="submit" disabled={props.loading || props.filePath.trim() === ""}>{props.loading ? "Analyzing..." : "Analyze File"}</button>
       </form>
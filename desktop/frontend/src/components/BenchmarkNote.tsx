@@ -0,0 +1,17 @@
+import { DashboardData } from "../types";
+
+type Props = { data: DashboardData; metric: string };
+
+// BenchmarkNote places a score among the other books analyzed in the
+// workspace, when the library holds enough of them.
+export function BenchmarkNote({ data, metric }: Props) {
+  const b = (data.benchmarks ?? []).find((x) => x.metric === metric);
+  if (!b) {
+    return null;
+  }
+  return (
+    <span className="benchmark-note muted" title={b.summary}>
+      {" "}higher than {Math.round(b.percentile)}% of your {b.books} other books
+    </span>
+  );
+}
@@ -0,0 +1,50 @@
+import { useState } from "react";
+import { PullModel, StartServices } from "../../wailsjs/go/main/App";
+import { FallbackReason } from "../types";
+
+type Props = { reason?: FallbackReason | null; section: string };
+
+const serviceNames: Record<string, string> = { ollama: "Ollama", languagetool: "LanguageTool" };
+
+// FallbackNotice explains why a section fell back to the heuristics and, when
+// the app can fix it, offers the fix inline. A re-run picks up the fix.
+export function FallbackNotice({ reason, section }: Props) {
+  const [busy, setBusy] = useState(false);
+  const [done, setDone] = useState("");
+  if (!reason) {
+    return null;
+  }
+  const service = serviceNames[reason.service] ?? reason.service;
+
+  const run = async (action: () => Promise<unknown>, message: string) => {
+    setBusy(true);
+    try {
+      await action();
+      setDone(message);
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  let action = null;
+  if (reason.code === "service_down") {
+    action = (
+      <button type="button" disabled={busy} onClick={() => run(StartServices, `${service} start requested; re-run the analysis.`)}>
+        {busy ? "Starting..." : `Start ${service}`}
+      </button>
+    );
+  } else if (reason.code === "model_missing" && reason.model) {
+    const model = reason.model;
+    action = (
+      <button type="button" disabled={busy} onClick={() => run(() => PullModel(model), `${model} pulled; re-run the analysis.`)}>
+        {busy ? "Pulling..." : `Pull ${model}`}
+      </button>
+    );
+  }
+
+  return (
+    <p className="text-risk" title={reason.detail}>
+      <strong>{section} fell back to heuristics ({reason.code}):</strong> {done || reason.fix} {done ? null : action}
+    </p>
+  );
+}
@@ -1,8 +1,12 @@
 import { DashboardData } from "../types";
+import { BenchmarkNote } from "./BenchmarkNote";
 
 type Props = { data: DashboardData };
 
 export function HeaderMetrics({ data }: Props) {
+  const gates = data.qualityGates ?? [];
+  const failed = gates.filter((g) => !g.passed).length;
+  const breakdown = (data.scoreBreakdown?.components ?? []).filter((c) => c.points > 0);
   return (
     <>
       <header className="mhd-header">
@@ -12,13 +16,29 @@ export function HeaderMetrics({ data }: Props) {
           <p>{data.chapterCount} chapters detected</p>
           <p className="project-path">{data.projectLocation}</p>
         </div>
-        <div className={`score-pill ${data.mhdScore >= 70 ? "healthy" : "risk"}`}>MHD Score: {data.mhdScore}</div>
+        <div className={`score-pill ${data.mhdScore >= 70 ? "healthy" : "risk"}`}>MHD Score: {data.mhdScore}<BenchmarkNote data={data} metric="mhd_score" /></div>
       </header>
 
+      {breakdown.length > 0 ? (
+        <section className="panel">
+          <h2>Why is my score {data.mhdScore}?</h2>
+          <p className="muted">Starts at {data.scoreBreakdown?.base ?? 100}; each component takes points off.</p>
+          <ul className="list">
+            {breakdown.map((c) => (
+              <li key={c.component}>
+                <strong>{c.label}: -{c.points}</strong>
+                {c.detail ? <div className="log-detail">{c.detail}</div> : null}
+              </li>
+            ))}
+          </ul>
+        </section>
+      ) : null}
+
       <section className={`run-banner ${data.runStats.status === "DONE" ? "ok" : "pending"}`}>
         <span>{data.runStats.lastAction || "Ready"}</span>
         <span>{data.runStats.status || "IDLE"}</span>
         <span>{data.runStats.runId}</span>
+        {data.runStats.profile ? <span>Profile: {data.runStats.profile}</span> : null}
       </section>
 
       <section className={`run-banner ${data.system.overall === "READY" ? "ok" : "pending"}`}>
@@ -51,6 +71,23 @@ export function HeaderMetrics({ data }: Props) {
         <div className="metric"><label>Contradictions</label><strong>{data.runStats.contradictionCount}</strong></div>
         <div className="metric"><label>Slop Flags</label><strong>{data.runStats.slopFlagCount}</strong></div>
       </section>
+
+      {gates.length > 0 ? (
+        <section className="panel">
+          <h2>Submission Checklist</h2>
+          <p className={failed === 0 ? "text-good" : "text-risk"}>
+            {failed === 0 ? "Ready to submit: every gate passes." : `${failed} of ${gates.length} gates fail.`}
+          </p>
+          <ul className="list submission-checklist">
+            {gates.map((g) => (
+              <li key={g.id} className={g.skipped ? "gate-skipped" : g.passed ? "gate-pass" : "gate-fail"}>
+                <strong>{g.skipped ? "SKIP" : g.passed ? "PASS" : "FAIL"}</strong> {g.label}
+                <div className="log-detail">{g.explanation}</div>
+              </li>
+            ))}
+          </ul>
+        </section>
+      ) : null}
     </>
   );
 }
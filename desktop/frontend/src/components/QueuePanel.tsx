@@ -0,0 +1,32 @@
+import { QueueItem } from "../types";
+
+type Props = {
+  items: QueueItem[];
+};
+
+function fileName(path: string): string {
+  const parts = path.split(/[\\/]/);
+  return parts[parts.length - 1] || path;
+}
+
+export function QueuePanel(props: Props) {
+  if (props.items.length === 0) {
+    return null;
+  }
+  return (
+    <section className="queue-panel panel" aria-label="Background analysis queue">
+      <h2>Queue</h2>
+      <ul>
+        {props.items.map((item) => (
+          <li key={item.id} className={`queue-${item.status}`}>
+            <strong>{fileName(item.path)}</strong>
+            <span>{item.status}</span>
+            {item.status === "running" ? <span>{item.percent}% {item.stage}</span> : null}
+            {item.status === "done" ? <span>MHD {item.mhdScore}</span> : null}
+            {item.error ? <span className="log-detail">{item.error}</span> : null}
+          </li>
+        ))}
+      </ul>
+    </section>
+  );
+}
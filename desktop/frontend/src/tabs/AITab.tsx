@@ -1,4 +1,5 @@
 import { DashboardData } from "../types";
+import { BenchmarkNote } from "../components/BenchmarkNote";
 
 type Props = {
   data: DashboardData;
@@ -25,6 +26,10 @@ function countWithDupEvidence(data: DashboardData): number {
   return data.aiReport.windows.filter((w) => (w.signals?.duplication?.evidence?.length ?? 0) > 0).length;
 }
 
+function countWithRecaps(data: DashboardData): number {
+  return data.aiReport.windows.filter((w) => (w.signals?.duplication?.recaps?.length ?? 0) > 0).length;
+}
+
 function countLongDuplicateSpans(data: DashboardData): number {
   return data.aiReport.windows.filter((w) =>
     (w.top_evidence ?? []).some((e) => e.type === "duplication" && e.summary.toLowerCase().includes("long duplicate span")),
@@ -44,6 +49,10 @@ export function AITab({ data }: Props) {
     ((pDoc ?? 0) >= 0.75 && (coverage ?? 0) >= 0.20);
   const dupWindows = countWithDupEvidence(data);
   const longDupWindows = countLongDuplicateSpans(data);
+  const recapWindows = countWithRecaps(data);
+  const chapters = (ai.p_ai_per_chapter ?? [])
+    .filter((c) => c.p_ai !== null)
+    .sort((a, b) => (b.p_ai ?? 0) - (a.p_ai ?? 0));
   const groupedErrors = ai.errors.reduce<ErrorRow[]>((acc, err) => {
     const key = `${err.stage}|${err.type}|${err.message}`;
     const found = acc.find((x) => `${x.stage}|${x.type}|${x.message}` === key);
@@ -61,11 +70,12 @@ export function AITab({ data }: Props) {
         <h2>AI Likelihood</h2>
         <ul className="list">
           <li><strong>Likely AI Generated:</strong> <span className={likelyAI ? "text-risk" : "text-good"}>{likelyAI ? "Yes" : "No"}</span></li>
-          <li><strong>Document AI Probability:</strong> <span className={metricClass(pDoc, 0.5)}>{pct(pDoc)}</span></li>
+          <li><strong>Document AI Probability:</strong> <span className={metricClass(pDoc, 0.5)}>{pct(pDoc)}</span><BenchmarkNote data={data} metric="p_ai_doc" /></li>
           <li><strong>AI Coverage Estimate:</strong> <span className={metricClass(coverage, 0.35)}>{pct(coverage)}</span></li>
           <li><strong>Max Window AI Probability:</strong> <span className={metricClass(pMax, 0.85)}>{pct(pMax)}</span></li>
           <li><strong>Document Confidence:</strong> <span className={metricClass(confidence, 0.5)}>{pct(confidence)}</span></li>
           <li><strong>Windows With Duplication Evidence:</strong> <span className={dupWindows > 0 ? "text-risk" : "text-good"}>{dupWindows}</span></li>
+          <li><strong>Windows Repeating Earlier Text As Recap (not counted):</strong> <span className="text-good">{recapWindows}</span></li>
           <li><strong>Windows With Long Duplicate Span:</strong> <span className={longDupWindows > 0 ? "text-risk" : "text-good"}>{longDupWindows}</span></li>
           <li><strong>Windows Analyzed:</strong> <span className="text-good">{ai.windows.length}</span></li>
           <li><strong>Detector Errors:</strong> <span className={groupedErrors.length > 0 ? "text-risk" : "text-good"}>{groupedErrors.length}</span></li>
@@ -94,6 +104,61 @@ export function AITab({ data }: Props) {
           </>
         )}
       </article>
+
+      {chapters.length > 0 && (
+        <article className="panel">
+          <h2>Chapters Most Likely AI</h2>
+          <ul className="list">
+            {chapters.slice(0, 10).map((c) => (
+              <li key={c.chapter}>
+                <strong>{`${c.title || `Chapter ${c.chapter}`}:`}</strong>{" "}
+                <span className={metricClass(c.p_ai, 0.5)}>{pct(c.p_ai)}</span>
+                {` (max ${pct(c.p_ai_max)}, ${c.windows} windows)`}
+              </li>
+            ))}
+          </ul>
+        </article>
+      )}
+
+      {data.drafts && (
+        <article className="panel">
+          <h2>Draft History</h2>
+          {data.drafts.flags.length > 0 ? (
+            <ul className="list">
+              {data.drafts.flags.map((flag, i) => (
+                <li key={`${flag}-${i}`} className="text-risk">{flag}</li>
+              ))}
+            </ul>
+          ) : (
+            <p className="text-good">{`No chapter replaced with an AI-risk spike across ${data.drafts.drafts.length} drafts.`}</p>
+          )}
+          <ul className="list">
+            {data.drafts.chapters.map((c) => (
+              <li key={c.chapter}>
+                <strong>{`${c.title || `Chapter ${c.chapter}`}:`}</strong>{" "}
+                {c.timeline
+                  .map((p) => `draft ${p.draft}${p.similarity === null ? "" : ` (${(p.similarity * 100).toFixed(0)}% kept)`}, AI ${pct(p.pAi)}`)
+                  .join(" → ")}
+              </li>
+            ))}
+          </ul>
+        </article>
+      )}
+
+      {(ai.sentences?.length ?? 0) > 0 && (
+        <article className="panel">
+          <h2>Sentences Carrying the Signal</h2>
+          <ul className="list">
+            {(ai.sentences ?? []).slice(0, 10).map((s) => (
+              <li key={`${s.start_word}-${s.end_word}`}>
+                <strong>{`${s.location?.label ?? `Words ${s.start_word}-${s.end_word}`}:`}</strong>{" "}
+                <span className={metricClass(s.p_ai, 0.5)}>{pct(s.p_ai)}</span>
+                {` "${s.text}" (${s.signals.join(", ")})`}
+              </li>
+            ))}
+          </ul>
+        </article>
+      )}
     </section>
   );
 }
@@ -13,13 +13,20 @@ export function DictionaryTab({ data }: Props) {
           <ul className="list chapter-grid">
             {data.characterDictionary.map((c) => (
               <li key={c.name}>
-                <strong>{c.name}</strong> <span className="muted">(mentions: {c.totalMentions})</span><br />
+                <strong>{c.name}</strong> <span className="muted">(mentions: {c.totalMentions}{c.pronounMentions ? `, ~${c.pronounMentions} by pronoun` : ""})</span><br />
+                {c.aliases && c.aliases.length > 0 && (
+                  <>
+                    <span className="muted">Also: {c.aliases.join(", ")}</span><br />
+                  </>
+                )}
                 <span className="muted">{c.description}</span><br />
                 <span className="muted">First seen: Ch {c.firstSeenChapter} | Last seen: Ch {c.lastSeenChapter}</span>
                 <ul className="list">
                   {c.chapters.slice(0, 3).map((ch) => (
                     <li key={`${c.name}-${ch.chapter}`}>
-                      <strong>Ch {ch.chapter}:</strong> {ch.title}<br />
+                      <strong>Ch {ch.chapter}:</strong> {ch.title}
+                      {ch.mentions !== undefined && <span className="muted"> ({ch.mentions} by name, ~{ch.pronounMentions ?? 0} by pronoun)</span>}
+                      <br />
                       <span className="muted">{ch.summary}</span><br />
                       <span className="muted">Actions: {ch.actions.join(" | ")}</span>
                     </li>
@@ -37,7 +44,9 @@ export function DictionaryTab({ data }: Props) {
           {data.chapterSummaries.map((c) => (
             <li key={`summary-${c.chapter}`}>
               <strong>Ch {c.chapter}:</strong> {c.title}<br />
-              <span className="muted">{c.summary}</span><br />
+              <span className="muted">{c.summary}</span>
+              {c.fallback && <span className="muted"> (opening prose; derived summary was {c.fallback.replace("_", " ")})</span>}
+              <br />
               <span className="muted">Events: {c.events.join(" | ")}</span>
             </li>
           ))}
@@ -19,8 +19,8 @@ export function HealthTab({ data, selectedIssue, setSelectedIssue }: Props) {
             {issues.map((c, i) => (
               <li key={`${c.id}-${i}`} className={selectedIssue === i ? "selected" : ""} onClick={() => setSelectedIssue(i)}>
                 <strong className={c.severity === "HIGH" ? "text-risk" : "text-warn"}>{c.severity}</strong> {c.description}
-                <div className="log-detail">Ch {c.chapterA}: {c.contextA || "No context extracted."}</div>
-                <div className="log-detail">Ch {c.chapterB}: {c.contextB || "No context extracted."}</div>
+                <div className="log-detail">Ch {c.chapterA}{c.pageA ? `, p. ${c.pageA}` : ""}: {c.contextA || "No context extracted."}</div>
+                <div className="log-detail">Ch {c.chapterB}{c.pageB ? `, p. ${c.pageB}` : ""}: {c.contextB || "No context extracted."}</div>
               </li>
             ))}
           </ul>
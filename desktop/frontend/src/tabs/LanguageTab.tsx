@@ -1,26 +1,61 @@
-import { DashboardData } from "../types";
+import { useState } from "react";
+import { MarkFinding } from "../../wailsjs/go/main/App";
+import { backend } from "../../wailsjs/go/models";
+import { CrutchTerm, DashboardData, SignalPrecision, SlopFlag } from "../types";
+import { BenchmarkNote } from "../components/BenchmarkNote";
+import { FallbackNotice } from "../components/FallbackNotice";
 
 type Props = { data: DashboardData };
 
+type SeverityFilter = "ALL" | "HIGH" | "MED" | "LOW";
+
+const severityRank: Record<string, number> = { HIGH: 0, MED: 1, LOW: 2 };
+
+function sortSlopFlags(flags: SlopFlag[]): SlopFlag[] {
+  return [...flags].sort((a, b) => (severityRank[a.severity] ?? 3) - (severityRank[b.severity] ?? 3) || b.confidence - a.confidence);
+}
+
 export function LanguageTab({ data }: Props) {
   const spellingProvider = data.language.spellingProvider || "heuristic";
   const safetyProvider = data.language.safetyProvider || "heuristic";
+  const [severity, setSeverity] = useState<SeverityFilter>("ALL");
+  const [hidden, setHidden] = useState<string[]>([]);
+  const [marked, setMarked] = useState<Record<string, boolean>>({});
+  const [precision, setPrecision] = useState<SignalPrecision[]>([]);
+  const proseFlags = sortSlopFlags(data.slopReport.Flags).filter((f) => (severity === "ALL" || f.severity === severity) && !hidden.includes(f.code || f.text));
+
+  // Anchor IDs survive re-analysis; older reports fall back to the flag's
+  // position in report order, as the backend numbers them.
+  const findingId = (flag: SlopFlag) => flag.anchorId || `slop-${data.slopReport.Flags.indexOf(flag) + 1}`;
+
+  const markFlag = async (flag: SlopFlag, correct: boolean) => {
+    const id = findingId(flag);
+    const report = await MarkFinding(backend.FindingFeedback.createFrom({ findingId: id, correct, note: "" }));
+    setMarked((m) => ({ ...m, [id]: correct }));
+    setPrecision((report.signals ?? []).filter((s) => s.signal.startsWith("slop:")));
+  };
 
   return (
     <section className="panel-grid">
       <article className="panel">
         <h2>Language Quality</h2>
         {data.language.heuristicFallback ? <p className="text-risk"><strong>Fallback Warning:</strong> Heuristic mode is active for part of language analysis.</p> : null}
+        <FallbackNotice reason={data.language.spellingFallback} section="Spelling & grammar" />
         <ul className="list">
-          <li><strong>Spelling Score:</strong> {data.language.spellingScore}/100</li>
-          <li><strong>Grammar Score:</strong> {data.language.grammarScore}/100</li>
-          <li><strong>Readability Score:</strong> {data.language.readabilityScore}/100</li>
+          <li><strong>Spelling Score:</strong> {data.language.spellingScore}/100<BenchmarkNote data={data} metric="spelling_score" /></li>
+          <li><strong>Grammar Score:</strong> {data.language.grammarScore}/100<BenchmarkNote data={data} metric="grammar_score" /></li>
+          <li><strong>Readability Score:</strong> {data.language.readabilityScore}/100<BenchmarkNote data={data} metric="readability_score" /></li>
+          {data.language.readingLevel && data.language.readingLevel.words > 0 ? (
+            <li><strong>Reading Level:</strong> {`grade ${data.language.readingLevel.fleschKincaidGrade.toFixed(1)} (Flesch-Kincaid), Gunning Fog ${data.language.readingLevel.gunningFog.toFixed(1)}, SMOG ${data.language.readingLevel.smog.toFixed(1)}`}</li>
+          ) : null}
+          <li><strong>Repetition Coverage:</strong> {(data.slopReport.VerbatimDuplicationCoverage * 100).toFixed(1)}%<BenchmarkNote data={data} metric="verbatim_duplication_coverage" /></li>
           <li><strong>Spelling & Grammar Provider:</strong> {spellingProvider}</li>
           <li><strong>Age Category:</strong> {data.language.ageCategory}</li>
         </ul>
       </article>
       <article className="panel">
         <h2>Content Safety</h2>
+        <FallbackNotice reason={data.language.safetyFallback} section="Content safety" />
         <ul className="list">
           <li><strong>Safety Provider:</strong> {safetyProvider}</li>
           <li><strong>Profanity Score:</strong> {data.language.profanityScore}/100 ({data.language.profanityInstances} instances)</li>
@@ -28,6 +63,74 @@ export function LanguageTab({ data }: Props) {
           <li><strong>Violence Score:</strong> {data.language.violenceScore}/100</li>
         </ul>
       </article>
+      <article className="panel panel-wide">
+        <h2>Prose Flags</h2>
+        <div className="prose-flag-controls">
+          <select value={severity} onChange={(e) => setSeverity(e.target.value as SeverityFilter)} aria-label="Flag severity">
+            <option value="ALL">All severities</option>
+            <option value="HIGH">High</option>
+            <option value="MED">Medium</option>
+            <option value="LOW">Low</option>
+          </select>
+          {hidden.length > 0 && (
+            <button type="button" className="ghost mini" onClick={() => setHidden([])}>Show {hidden.length} hidden</button>
+          )}
+        </div>
+        {proseFlags.length === 0 ? (
+          <p className="text-good">No prose flags{severity === "ALL" ? "" : " at this severity"}.</p>
+        ) : (
+          <ul className="list">
+            {proseFlags.map((f, i) => (
+              <li key={`${f.code}-${i}`}>
+                <strong className={f.severity === "HIGH" ? "text-risk" : f.severity === "MED" ? "text-warn" : undefined}>{f.severity || "?"}</strong> {f.text}
+                {f.confidence > 0 && <span className="muted"> (confidence {Math.round(f.confidence * 100)}%)</span>}
+                {f.evidence.length > 0 && (
+                  <>
+                    <br />
+                    <span className="muted">{f.evidence.map((e) => `${e.metric} ${e.value.toFixed(3)} vs ${e.threshold}`).join(" | ")}</span>
+                  </>
+                )}
+                <button type="button" className="ghost mini" onClick={() => setHidden((h) => [...h, f.code || f.text])}>Hide</button>
+                {marked[findingId(f)] === undefined ? (
+                  <>
+                    <button type="button" className="ghost mini" onClick={() => void markFlag(f, true)}>Correct</button>
+                    <button type="button" className="ghost mini" onClick={() => void markFlag(f, false)}>False positive</button>
+                  </>
+                ) : (
+                  <span className="muted"> marked {marked[findingId(f)] ? "correct" : "false positive"}</span>
+                )}
+              </li>
+            ))}
+          </ul>
+        )}
+        {precision.length > 0 && (
+          <ul className="list">
+            {precision.map((s) => (
+              <li key={s.signal} className="muted">
+                {s.signal.replace("slop:", "")}: {Math.round(s.precision * 100)}% agreed ({s.correct}/{s.labels})
+                {s.threshold !== undefined && s.defaultThreshold !== undefined && ` — ${s.metric} threshold ${s.defaultThreshold} → ${s.threshold} next run`}
+              </li>
+            ))}
+          </ul>
+        )}
+      </article>
+      {((data.crutchWords?.words.length ?? 0) > 0 || (data.crutchWords?.phrases.length ?? 0) > 0) && (
+        <article className="panel panel-wide">
+          <h2>Crutch Words</h2>
+          <p className="muted">{`Compared with ${data.crutchWords?.baseline}; rates per 10,000 words.`}</p>
+          <ul className="list">
+            {(data.crutchWords?.words ?? []).map((t) => crutchTermItem(t))}
+          </ul>
+          {(data.crutchWords?.phrases.length ?? 0) > 0 && (
+            <>
+              <h3>Repeated Phrases</h3>
+              <ul className="list">
+                {(data.crutchWords?.phrases ?? []).map((t) => crutchTermItem(t))}
+              </ul>
+            </>
+          )}
+        </article>
+      )}
       <article className="panel panel-wide">
         <h2>Additional Diagnostics</h2>
         <ul className="list">
@@ -39,3 +142,15 @@ export function LanguageTab({ data }: Props) {
     </section>
   );
 }
+
+function crutchTermItem(t: CrutchTerm) {
+  return (
+    <li key={t.term}>
+      <strong>{t.term}</strong> ×{t.count}{" "}
+      <span className="muted">
+        {`(${t.perTenThousand.toFixed(1)}${t.ratio ? `, ${t.ratio.toFixed(1)}× the baseline` : ""}) — `}
+        {t.chapters.map((c) => `ch ${c.chapter} ×${c.count}`).join(", ")}
+      </span>
+    </li>
+  );
+}
@@ -1,5 +1,6 @@
 import { Radar, RadarChart, PolarGrid, PolarAngleAxis, ResponsiveContainer } from "recharts";
 import { DashboardData } from "../types";
+import { FallbackNotice } from "../components/FallbackNotice";
 
 type Props = { data: DashboardData };
 
@@ -10,6 +11,7 @@ export function MarketTab({ data }: Props) {
     <section className="panel-grid">
       <article className="panel">
         <h2>Genre Radar</h2>
+        <FallbackNotice reason={data.genreFallback} section="Genre" />
         <div className="chart-wrap">
           <ResponsiveContainer width="100%" height={280}>
             <RadarChart data={radarData}>
@@ -22,6 +24,7 @@ export function MarketTab({ data }: Props) {
       </article>
       <article className="panel">
         <h2>Comp Titles</h2>
+        <FallbackNotice reason={data.compTitlesFallback} section="Comp titles" />
         <ul className="list">
           {data.compTitles.map((t) => (
             <li key={t.title}>
@@ -1,6 +1,21 @@
 import { useEffect, useRef } from "react";
 import { Timeline } from "vis-timeline/standalone";
-import { DashboardData } from "../types";
+import { Bar, BarChart, ResponsiveContainer, Tooltip, XAxis, YAxis } from "recharts";
+import { DashboardData, DistributionSummary } from "../types";
+
+// sentenceHistogram buckets sentence lengths into 5-word bins, with everything
+// from 60 words up in the last.
+function sentenceHistogram(lengths: number[]) {
+  const bins = Array.from({ length: 13 }, (_, i) => ({ bin: i === 12 ? "60+" : `${i * 5}-${i * 5 + 4}`, sentences: 0 }));
+  for (const n of lengths) {
+    bins[Math.min(Math.floor(n / 5), 12)].sentences++;
+  }
+  return bins;
+}
+
+function describe(d: DistributionSummary) {
+  return d.count === 0 ? "none" : `mean ${d.mean.toFixed(1)}, median ${d.median}, sd ${d.stdDev.toFixed(1)}, range ${d.min}-${d.max}`;
+}
 
 type Props = { data: DashboardData };
 
@@ -27,6 +42,9 @@ export function StructureTab({ data }: Props) {
     return () => tl.destroy();
   }, [data.timeline]);
 
+  const statsChapters = data.stats?.chapters ?? [];
+  const histogram = sentenceHistogram(statsChapters.flatMap((c) => c.sentenceLengths));
+
   return (
     <section className="panel-grid">
       <article className="panel">
@@ -46,6 +64,48 @@ export function StructureTab({ data }: Props) {
         <h2>Chapter Coverage</h2>
         <p>{data.chapterCount} chapters scanned with per-chapter logs in the console.</p>
       </article>
+      {(data.pacing?.chapters.length ?? 0) > 0 && (
+        <article className="panel">
+          <h2>Pacing</h2>
+          {(data.pacing?.flags ?? []).map((flag, i) => (
+            <p key={`${flag}-${i}`} className="text-risk">{flag}</p>
+          ))}
+          <ul className="list">
+            {(data.pacing?.chapters ?? []).map((c) => (
+              <li key={c.chapter}>
+                <strong>{`${c.title || `Chapter ${c.chapter}`}:`}</strong>{" "}
+                <span className={c.tension <= -0.5 ? "text-risk" : ""}>{`tension ${c.tension >= 0 ? "+" : ""}${c.tension.toFixed(1)}`}</span>
+                <span className="muted">{` (dialogue ${(c.dialogueShare * 100).toFixed(0)}%, sentence variation ${c.sentenceVariation.toFixed(2)}, ${c.actionVerbsPer100.toFixed(1)} action verbs/100 words)`}</span>
+              </li>
+            ))}
+          </ul>
+        </article>
+      )}
+      {statsChapters.length > 0 && data.stats && (
+        <article className="panel panel-wide">
+          <h2>Statistics</h2>
+          <p className="muted">{`Sentence words: ${describe(data.stats.sentences)}. Paragraph words: ${describe(data.stats.paragraphs)}. Chapter words: ${describe(data.stats.chapterWords)}.`}</p>
+          <div className="chart-wrap">
+            <ResponsiveContainer width="100%" height={220}>
+              <BarChart data={histogram}>
+                <XAxis dataKey="bin" tick={{ fill: "#d4d4d8", fontSize: 11 }} />
+                <YAxis tick={{ fill: "#d4d4d8", fontSize: 11 }} allowDecimals={false} />
+                <Tooltip />
+                <Bar dataKey="sentences" fill="#10b981" />
+              </BarChart>
+            </ResponsiveContainer>
+          </div>
+          <ul className="list">
+            {statsChapters.map((c) => (
+              <li key={c.chapter}>
+                <strong>{`${c.title || `Chapter ${c.chapter}`}:`}</strong>{" "}
+                <span className="muted">{`${c.words} words, dialogue ${(c.dialogueRatio * 100).toFixed(0)}%; per 100 words ${c.adverbsPer100.toFixed(1)} -ly adverbs, ${c.pronounsPer100.toFixed(1)} pronouns, ${c.ingWordsPer100.toFixed(1)} -ing words, ${c.functionWordsPer100.toFixed(1)} function words`}</span>
+              </li>
+            ))}
+          </ul>
+          <p className="muted">Export the raw values with File → Export Statistics (CSV).</p>
+        </article>
+      )}
     </section>
   );
 }
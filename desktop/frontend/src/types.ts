@@ -1,6 +1,17 @@
 export type LogLine = { time: string; level: string; stage: string; message: string; detail: string };
 export type GenreScore = { genre: string; score: number };
 
+export type ReadingLevel = {
+  sentences: number;
+  words: number;
+  syllables: number;
+  polysyllables: number;
+  fleschReadingEase: number;
+  fleschKincaidGrade: number;
+  gunningFog: number;
+  smog: number;
+};
+
 export type ChapterMetric = {
   index: number;
   title: string;
@@ -9,6 +20,7 @@ export type ChapterMetric = {
   topGenre: string;
   topGenreScore: number;
   genreBreakdown: GenreScore[];
+  readingLevel?: ReadingLevel;
 };
 
 export type Contradiction = {
@@ -24,6 +36,7 @@ export type Contradiction = {
 
 export type HealthIssue = {
   id: string;
+  anchorId?: string;
   entity: string;
   severity: string;
   description: string;
@@ -32,6 +45,8 @@ export type HealthIssue = {
   contextA: string;
   contextB: string;
   dictionaryRef: string;
+  pageA?: number;
+  pageB?: number;
 };
 
 export type ChapterSummary = {
@@ -39,25 +54,158 @@ export type ChapterSummary = {
   title: string;
   summary: string;
   events: string[];
+  fallback?: string;
 };
 
 export type CharacterChapterRecord = {
   chapter: number;
   title: string;
+  mentions?: number;
+  pronounMentions?: number;
   summary: string;
   actions: string[];
   events: string[];
 };
 
+export type SlopFlagCode =
+  | "monotone"
+  | "red_flag_vocabulary"
+  | "low_originality"
+  | "verbatim_repetition"
+  | "repeated_phrases"
+  | "dramatic_saturation"
+  | "expansion_markers"
+  | "ai_generation_risk";
+
+export type SlopFlag = {
+  code: SlopFlagCode | "";
+  severity: "HIGH" | "MED" | "LOW" | "";
+  confidence: number;
+  evidence: Array<{ metric: string; value: number; threshold: number }>;
+  text: string;
+  anchorId?: string;
+};
+
+export type QualityGateResult = {
+  id: string;
+  label: string;
+  metric: string;
+  op: string;
+  threshold: number;
+  value: number | null;
+  skipped: boolean;
+  passed: boolean;
+  explanation: string;
+};
+
+export type Benchmark = {
+  metric: string;
+  label: string;
+  value: number;
+  percentile: number;
+  books: number;
+  summary: string;
+};
+
+export type ChapterDraftPoint = {
+  draft: number;
+  words: number;
+  similarity: number | null;
+  pAi: number | null;
+  replaced: boolean;
+  aiSpike: boolean;
+};
+
+// FallbackReason says why a section fell back to the heuristics; code is
+// service_down, timeout, bad_json or model_missing.
+export type FallbackReason = {
+  code: string;
+  service: string;
+  model?: string;
+  fix: string;
+  detail: string;
+};
+
+export type PacingReport = {
+  chapters: Array<{
+    chapter: number;
+    title: string;
+    words: number;
+    dialogueShare: number;
+    sentenceVariation: number;
+    actionVerbsPer100: number;
+    tension: number;
+  }>;
+  stretches: Array<{ kind: "sag" | "unrelenting"; startChapter: number; endChapter: number; tension: number }>;
+  flags: string[];
+};
+
+export type DistributionSummary = { count: number; min: number; max: number; mean: number; median: number; stdDev: number };
+
+export type ManuscriptStats = {
+  chapters: Array<{
+    chapter: number;
+    title: string;
+    words: number;
+    sentenceLengths: number[];
+    paragraphLengths: number[];
+    dialogueRatio: number;
+    adverbsPer100: number;
+    pronounsPer100: number;
+    ingWordsPer100: number;
+    functionWordsPer100: number;
+  }>;
+  sentences: DistributionSummary;
+  paragraphs: DistributionSummary;
+  chapterWords: DistributionSummary;
+};
+
+export type CrutchTerm = {
+  term: string;
+  count: number;
+  perTenThousand: number;
+  baselinePerTenThousand?: number;
+  ratio?: number;
+  chapters: Array<{ chapter: number; title: string; count: number }>;
+};
+
+export type CrutchWordReport = { baseline: string; words: CrutchTerm[]; phrases: CrutchTerm[] };
+
+export type DraftProvenance = {
+  drafts: Array<{ draft: number; projectId: string; sourceName: string; analyzedAt: string }>;
+  chapters: Array<{ chapter: number; title: string; timeline: ChapterDraftPoint[] }>;
+  flags: string[];
+};
+
+export type SignalPrecision = {
+  signal: string;
+  labels: number;
+  correct: number;
+  precision: number;
+  metric?: string;
+  defaultThreshold?: number;
+  threshold?: number;
+};
+
 export type CharacterEntry = {
   name: string;
+  aliases?: string[];
   description: string;
   firstSeenChapter: number;
   lastSeenChapter: number;
   totalMentions: number;
+  pronounMentions?: number;
   chapters: CharacterChapterRecord[];
 };
 
+export type PairDiff = {
+  window_id: string;
+  other: { start: number; end: number };
+  identical_words: number;
+  paraphrased_words: number;
+  ops: Array<{ op: "identical" | "paraphrased" | "removed" | "added"; words?: string[]; other_words?: string[] }>;
+};
+
 export type AIDetectionReport = {
   document_id: string;
   p_ai_doc: number | null;
@@ -74,21 +222,64 @@ export type AIDetectionReport = {
     p_ai: number;
     confidence: number;
     signals: {
-      duplication: { score: number | null; evidence: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }> }> };
+      duplication: {
+        score: number | null;
+        evidence: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }>; diff?: PairDiff }>;
+        recaps?: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }>; diff?: PairDiff }>;
+      };
       lm_smoothness: { score: number | null };
       style_uniformity: { score: number | null };
       polish_cliche: { score: number | null };
       language_tool: { score: number | null };
+      semantic_duplication?: { score: number | null; evidence: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }> }> | null };
     };
-    top_evidence: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }> }>;
+    top_evidence: Array<{ type: string; summary: string; spans: Array<{ start: number; end: number }>; diff?: PairDiff }>;
+    location?: { start_chapter: number; start_paragraph: number; end_chapter: number; end_paragraph: number; start_page?: number; end_page?: number; label: string };
+  }>;
+  p_ai_per_chapter?: Array<{
+    chapter: number;
+    title: string;
+    start_word: number;
+    end_word: number;
+    p_ai: number | null;
+    ai_coverage_est: number | null;
+    p_ai_max: number | null;
+    windows: number;
+    exempt_windows: number;
+  }>;
+  sentences?: Array<{
+    window_id: string;
+    start_word: number;
+    end_word: number;
+    text: string;
+    p_ai: number;
+    signals: string[];
+    location?: { start_chapter: number; start_paragraph: number; end_chapter: number; end_paragraph: number; start_page?: number; end_page?: number; label: string };
   }>;
   word_count: number;
 };
 
+export type ScorePenalty = {
+  component: "health_issues" | "slop_flags" | "grammar" | "spelling" | "ai_likelihood" | "draft_markers";
+  label: string;
+  input: number;
+  weight?: number;
+  points: number;
+  detail: string;
+};
+
+export type ScoreBreakdown = {
+  base: number;
+  score: number;
+  deducted: number;
+  components: ScorePenalty[];
+};
+
 export type DashboardData = {
   bookTitle: string;
   wordCount: number;
   mhdScore: number;
+  scoreBreakdown?: ScoreBreakdown;
   logs: LogLine[];
   contradictions: Contradiction[];
   healthIssues: HealthIssue[];
@@ -109,7 +300,7 @@ export type DashboardData = {
     OptimizationMarkerCount: number;
     AISuspicionScore: number;
     LikelyAIGenerated: boolean;
-    Flags: string[];
+    Flags: SlopFlag[];
   };
   timeline: Array<{ time_marker: string; event: string }>;
   beats: Array<{ name: string; startChapter: number; endChapter: number; isBeat: boolean; reasoning: string }>;
@@ -119,14 +310,19 @@ export type DashboardData = {
   characterDictionary: CharacterEntry[];
   chapterCount: number;
   compTitles: Array<{ title: string; tier: string }>;
+  compTitlesFallback?: FallbackReason | null;
+  genreFallback?: FallbackReason | null;
   language: {
     spellingScore: number;
     grammarScore: number;
     readabilityScore: number;
+    readingLevel?: ReadingLevel;
     ageCategory: string;
     spellingProvider: string;
     safetyProvider: string;
     heuristicFallback: boolean;
+    spellingFallback?: FallbackReason;
+    safetyFallback?: FallbackReason;
     profanityScore: number;
     explicitScore: number;
     violenceScore: number;
@@ -135,6 +331,12 @@ export type DashboardData = {
     notes: string[];
   };
   projectLocation: string;
+  qualityGates?: QualityGateResult[];
+  benchmarks?: Benchmark[];
+  drafts?: DraftProvenance | null;
+  pacing?: PacingReport;
+  stats?: ManuscriptStats;
+  crutchWords?: CrutchWordReport;
   system: {
     overall: string;
     initializing: boolean;
@@ -154,9 +356,11 @@ export type DashboardData = {
     timelineCount: number;
     contradictionCount: number;
     slopFlagCount: number;
+    profile?: string;
   };
 };
 
+export type AnalysisProfile = "quick" | "standard" | "deep";
 export type TabName = "ai" | "structure" | "market" | "language" | "dictionary";
 export type LogFilter = "ALL" | "INFO" | "ANALYSIS" | "RISK";
 
@@ -164,6 +368,7 @@ export const emptyData: DashboardData = {
   bookTitle: "Untitled",
   wordCount: 0,
   mhdScore: 0,
+  scoreBreakdown: { base: 100, score: 0, deducted: 0, components: [] },
   logs: [],
   contradictions: [],
   healthIssues: [],
@@ -242,3 +447,45 @@ export const emptyData: DashboardData = {
     slopFlagCount: 0,
   },
 };
+
+export type ProgressStage =
+  | "SETUP" | "BOOT" | "WORKSPACE" | "PROJECT" | "INGEST" | "CHAPTER" | "DICTIONARY" | "SLOP" | "AI"
+  | "FORENSICS" | "TIMELINE" | "STRUCTURE" | "LANGUAGE" | "SENSITIVITY" | "SCORING" | "DONE";
+
+export type StageProgress = {
+  stage: ProgressStage;
+  status: "pending" | "running" | "done" | "skipped";
+  elapsedMs: number;
+  estimatedMs: number;
+};
+
+export type ProgressEvent = {
+  percent: number;
+  stage: ProgressStage;
+  detail: string;
+  elapsedMs?: number;
+  stageElapsedMs?: number;
+  remainingMs?: number;
+  chapter?: number;
+  chapterCount?: number;
+  words?: number;
+  stages?: StageProgress[];
+};
+
+export type QueueStatusName = "queued" | "running" | "done" | "failed" | "cancelled";
+
+export type QueueItem = {
+  id: number;
+  path: string;
+  status: QueueStatusName;
+  percent: number;
+  stage: string;
+  detail: string;
+  projectId?: string;
+  runId?: string;
+  mhdScore: number;
+  error?: string;
+  queuedAt: string;
+  startedAt?: string;
+  completedAt?: string;
+};
@@ -1,23 +1,40 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {backend} from '../models';
+import {main} from '../models';
 
 export function AnalyzeExcerpt(arg1:string):Promise<backend.DashboardData>;
 
 export function AnalyzeFile(arg1:string):Promise<backend.DashboardData>;
 
+export function AnalyzeFileWithProfile(arg1:string,arg2:string):Promise<backend.DashboardData>;
+
+export function EnqueueFiles(arg1:Array<string>):Promise<Array<main.QueueItem>>;
+
 export function ExportLogPackageDialog():Promise<void>;
 
 export function ExtractTimelineMarkers(arg1:string):Promise<Array<string>>;
 
 export function GetDashboard():Promise<backend.DashboardData>;
 
+export function GetFeedbackReport():Promise<backend.FeedbackReport>;
+
+export function GetQueueStatus():Promise<main.QueueStatus>;
+
 export function GetServiceDiagnostics():Promise<backend.SystemDiagnostics>;
 
 export function InstallMissingDependencies():Promise<backend.SystemDiagnostics>;
 
+export function MarkFinding(arg1:backend.FindingFeedback):Promise<backend.FeedbackReport>;
+
 export function PickAndAnalyzeFile():Promise<backend.DashboardData>;
 
+export function PickAndAnalyzeFileWithProfile(arg1:string):Promise<backend.DashboardData>;
+
+export function PullModel(arg1:string):Promise<backend.SystemDiagnostics>;
+
 export function Quit():Promise<void>;
 
 export function ReportClientError(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function StartServices():Promise<backend.SystemDiagnostics>;
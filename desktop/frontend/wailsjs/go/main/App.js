@@ -10,6 +10,14 @@ export function AnalyzeFile(arg1) {
   return window['go']['main']['App']['AnalyzeFile'](arg1);
 }
 
+export function AnalyzeFileWithProfile(arg1, arg2) {
+  return window['go']['main']['App']['AnalyzeFileWithProfile'](arg1, arg2);
+}
+
+export function EnqueueFiles(arg1) {
+  return window['go']['main']['App']['EnqueueFiles'](arg1);
+}
+
 export function ExportLogPackageDialog() {
   return window['go']['main']['App']['ExportLogPackageDialog']();
 }
@@ -22,6 +30,14 @@ export function GetDashboard() {
   return window['go']['main']['App']['GetDashboard']();
 }
 
+export function GetFeedbackReport() {
+  return window['go']['main']['App']['GetFeedbackReport']();
+}
+
+export function GetQueueStatus() {
+  return window['go']['main']['App']['GetQueueStatus']();
+}
+
 export function GetServiceDiagnostics() {
   return window['go']['main']['App']['GetServiceDiagnostics']();
 }
@@ -30,10 +46,22 @@ export function InstallMissingDependencies() {
   return window['go']['main']['App']['InstallMissingDependencies']();
 }
 
+export function MarkFinding(arg1) {
+  return window['go']['main']['App']['MarkFinding'](arg1);
+}
+
 export function PickAndAnalyzeFile() {
   return window['go']['main']['App']['PickAndAnalyzeFile']();
 }
 
+export function PickAndAnalyzeFileWithProfile(arg1) {
+  return window['go']['main']['App']['PickAndAnalyzeFileWithProfile'](arg1);
+}
+
+export function PullModel(arg1) {
+  return window['go']['main']['App']['PullModel'](arg1);
+}
+
 export function Quit() {
   return window['go']['main']['App']['Quit']();
 }
@@ -41,3 +69,7 @@ export function Quit() {
 export function ReportClientError(arg1, arg2, arg3) {
   return window['go']['main']['App']['ReportClientError'](arg1, arg2, arg3);
 }
+
+export function StartServices() {
+  return window['go']['main']['App']['StartServices']();
+}
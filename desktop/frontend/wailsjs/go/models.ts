@@ -284,6 +284,78 @@ export namespace backend {
 	        this.reasoning = source["reasoning"];
 	    }
 	}
+	export class FindingFeedback {
+	    findingId: string;
+	    correct: boolean;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FindingFeedback(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.findingId = source["findingId"];
+	        this.correct = source["correct"];
+	        this.note = source["note"];
+	    }
+	}
+	export class SignalPrecision {
+	    signal: string;
+	    labels: number;
+	    correct: number;
+	    precision: number;
+	    metric?: string;
+	    defaultThreshold?: number;
+	    threshold?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SignalPrecision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.signal = source["signal"];
+	        this.labels = source["labels"];
+	        this.correct = source["correct"];
+	        this.precision = source["precision"];
+	        this.metric = source["metric"];
+	        this.defaultThreshold = source["defaultThreshold"];
+	        this.threshold = source["threshold"];
+	    }
+	}
+	export class FeedbackReport {
+	    signals: SignalPrecision[];
+	    thresholds: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedbackReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.signals = this.convertValues(source["signals"], SignalPrecision);
+	        this.thresholds = source["thresholds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class GenreScore {
 	    genre: string;
 	    score: number;
@@ -801,8 +873,145 @@ export namespace forensics {
 
 }
 
+export namespace main {
+	
+	export class QueueItem {
+	    id: number;
+	    path: string;
+	    status: string;
+	    percent: number;
+	    stage: string;
+	    detail: string;
+	    projectId?: string;
+	    runId?: string;
+	    mhdScore: number;
+	    error?: string;
+	    queuedAt: string;
+	    startedAt?: string;
+	    completedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueueItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.percent = source["percent"];
+	        this.stage = source["stage"];
+	        this.detail = source["detail"];
+	        this.projectId = source["projectId"];
+	        this.runId = source["runId"];
+	        this.mhdScore = source["mhdScore"];
+	        this.error = source["error"];
+	        this.queuedAt = source["queuedAt"];
+	        this.startedAt = source["startedAt"];
+	        this.completedAt = source["completedAt"];
+	    }
+	}
+	export class QueueStatus {
+	    workers: number;
+	    items: QueueItem[];
+	    queued: number;
+	    running: number;
+	    done: number;
+	    failed: number;
+	    cancelled: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueueStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workers = source["workers"];
+	        this.items = this.convertValues(source["items"], QueueItem);
+	        this.queued = source["queued"];
+	        this.running = source["running"];
+	        this.done = source["done"];
+	        this.failed = source["failed"];
+	        this.cancelled = source["cancelled"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace slop {
 	
+	export class EvidenceRef {
+	    metric: string;
+	    value: number;
+	    threshold: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.metric = source["metric"];
+	        this.value = source["value"];
+	        this.threshold = source["threshold"];
+	    }
+	}
+	export class Flag {
+	    code: string;
+	    severity: string;
+	    confidence: number;
+	    evidence: EvidenceRef[];
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Flag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.severity = source["severity"];
+	        this.confidence = source["confidence"];
+	        this.evidence = this.convertValues(source["evidence"], EvidenceRef);
+	        this.text = source["text"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Report {
 	    Monotone: boolean;
 	    MeanSentenceLength: number;
@@ -819,7 +1028,7 @@ export namespace slop {
 	    OptimizationMarkerCount: number;
 	    AISuspicionScore: number;
 	    LikelyAIGenerated: boolean;
-	    Flags: string[];
+	    Flags: Flag[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Report(source);
@@ -842,8 +1051,26 @@ export namespace slop {
 	        this.OptimizationMarkerCount = source["OptimizationMarkerCount"];
 	        this.AISuspicionScore = source["AISuspicionScore"];
 	        this.LikelyAIGenerated = source["LikelyAIGenerated"];
-	        this.Flags = source["Flags"];
+	        this.Flags = this.convertValues(source["Flags"], Flag);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -2,29 +2,75 @@ package main
 
 import (
 	"archive/zip"
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"book_dashboard/desktop/backend"
+	"book_dashboard/internal/resources"
 	"book_dashboard/internal/workspace"
 )
 
+// logFlushInterval bounds how long a session log line can sit in memory.
+const logFlushInterval = time.Second
+
+// logArchive keeps the session log open behind a buffered writer; a run emits
+// hundreds of lines and reopening the file per line stalls on slow disks.
 type logArchive struct {
 	mu          sync.Mutex
 	rootDir     string
 	runsDir     string
 	sessionFile string
+	file        *os.File
+	writer      *bufio.Writer
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// snapMu serializes run snapshots, which are named to the second. It is
+	// not mu: gzipping a large run must not stall session logging.
+	snapMu sync.Mutex
 }
 
+// Run snapshots are gzip-compressed JSON. The bulkiest sections (the run's
+// log lines and the AI report's windows) go to side files in a directory
+// named after the snapshot, so listing snapshots stays cheap. Plain .json
+// snapshots written by earlier versions still list and load.
+const (
+	snapshotExt       = ".json.gz"
+	legacySnapshotExt = ".json"
+
+	snapshotSectionLogs      = "logs"
+	snapshotSectionAIWindows = "ai_windows"
+)
+
 type runSnapshot struct {
 	CapturedAt string                `json:"captured_at"`
 	Trigger    string                `json:"trigger"`
 	Dashboard  backend.DashboardData `json:"dashboard"`
+	// SideFiles maps split-out sections to their files, relative to
+	// logs/runs.
+	SideFiles map[string]string `json:"side_files,omitempty"`
+}
+
+// RunSnapshotInfo describes one persisted snapshot for the snapshot picker.
+type RunSnapshotInfo struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	CapturedAt string `json:"capturedAt"`
+	Trigger    string `json:"trigger"`
+	RunID      string `json:"runId"`
+	BookTitle  string `json:"bookTitle"`
+	Status     string `json:"status"`
+	SizeBytes  int64  `json:"sizeBytes"`
 }
 
 func newLogArchive() (*logArchive, error) {
@@ -38,15 +84,68 @@ func newLogArchive() (*logArchive, error) {
 		return nil, fmt.Errorf("create runs dir: %w", err)
 	}
 	sessionFile := filepath.Join(rootDir, "session-"+time.Now().Format("20060102-150405")+".log")
+	f, err := os.OpenFile(sessionFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open session log: %w", err)
+	}
 	a := &logArchive{
 		rootDir:     rootDir,
 		runsDir:     runsDir,
 		sessionFile: sessionFile,
+		file:        f,
+		writer:      bufio.NewWriterSize(f, 64*1024),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
 	}
+	go a.flushLoop(logFlushInterval)
 	a.appendLine("INFO", "BOOT", "log archive initialized", rootDir)
 	return a, nil
 }
 
+func (a *logArchive) flushLoop(interval time.Duration) {
+	defer close(a.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// flush writes buffered session lines to disk.
+func (a *logArchive) flush() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.writer != nil {
+		_ = a.writer.Flush()
+	}
+}
+
+// close stops the flush loop, flushes pending lines and closes the session
+// file. Lines appended afterwards are dropped.
+func (a *logArchive) close() {
+	if a == nil {
+		return
+	}
+	a.closeOnce.Do(func() {
+		close(a.stop)
+		<-a.done
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		_ = a.writer.Flush()
+		_ = a.file.Close()
+		a.writer = nil
+		a.file = nil
+	})
+}
+
 func (a *logArchive) RootDir() string {
 	if a == nil {
 		return ""
@@ -65,12 +164,10 @@ func (a *logArchive) appendLine(level, stage, message, detail string) {
 		line += " | " + detail
 	}
 	line += "\n"
-	f, err := os.OpenFile(a.sessionFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
+	if a.writer == nil {
 		return
 	}
-	defer f.Close()
-	_, _ = f.WriteString(line)
+	_, _ = a.writer.WriteString(line)
 }
 
 func (a *logArchive) appendProgress(percent int, stage, detail string) {
@@ -87,12 +184,53 @@ func (a *logArchive) appendDashboardLogs(lines []backend.LogLine) {
 	}
 }
 
-func (a *logArchive) persistRunSnapshot(trigger string, data backend.DashboardData) (string, error) {
+// resourceProfileLine is one run's entry in logs/resources.jsonl.
+type resourceProfileLine struct {
+	RunID       string            `json:"run_id"`
+	SourceName  string            `json:"source_name"`
+	Status      string            `json:"status"`
+	CompletedAt string            `json:"completed_at"`
+	Profile     resources.Profile `json:"profile"`
+}
+
+// appendResourceProfile adds a run's resource profile to logs/resources.jsonl,
+// which the log package export carries alongside the session logs.
+func (a *logArchive) appendResourceProfile(stats backend.RunStats) error {
 	if a == nil {
-		return "", fmt.Errorf("log archive unavailable")
+		return fmt.Errorf("log archive unavailable")
+	}
+	if stats.Resources.Samples == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(resourceProfileLine{
+		RunID:       stats.RunID,
+		SourceName:  stats.SourceName,
+		Status:      stats.Status,
+		CompletedAt: stats.CompletedAt,
+		Profile:     stats.Resources,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal resource profile: %w", err)
 	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(a.rootDir, "resources.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open resource log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("write resource profile: %w", err)
+	}
+	return nil
+}
+
+func (a *logArchive) persistRunSnapshot(trigger string, data backend.DashboardData) (string, error) {
+	if a == nil {
+		return "", fmt.Errorf("log archive unavailable")
+	}
+	a.snapMu.Lock()
+	defer a.snapMu.Unlock()
 	name := time.Now().Format("20060102-150405")
 	runID := sanitizeForFilename(strings.TrimSpace(data.RunStats.RunID))
 	if runID != "" {
@@ -102,22 +240,350 @@ func (a *logArchive) persistRunSnapshot(trigger string, data backend.DashboardDa
 	if trigger != "" {
 		name += "-" + trigger
 	}
-	path := filepath.Join(a.runsDir, name+".json")
+	path := filepath.Join(a.runsDir, name+snapshotExt)
 	snap := runSnapshot{
 		CapturedAt: time.Now().Format(time.RFC3339),
 		Trigger:    trigger,
 		Dashboard:  data,
+		SideFiles: map[string]string{
+			snapshotSectionLogs:      filepath.Join(name, snapshotSectionLogs+snapshotExt),
+			snapshotSectionAIWindows: filepath.Join(name, snapshotSectionAIWindows+snapshotExt),
+		},
 	}
-	raw, err := json.MarshalIndent(snap, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("marshal run snapshot: %w", err)
+	snap.Dashboard.Logs = nil
+	snap.Dashboard.AIReport.Windows = nil
+	if err := os.MkdirAll(filepath.Join(a.runsDir, name), 0o755); err != nil {
+		return "", fmt.Errorf("create snapshot dir: %w", err)
 	}
-	if err := os.WriteFile(path, raw, 0o644); err != nil {
+	sections := map[string]any{
+		snapshotSectionLogs:      data.Logs,
+		snapshotSectionAIWindows: data.AIReport.Windows,
+	}
+	for section, value := range sections {
+		if err := writeGzipJSON(filepath.Join(a.runsDir, snap.SideFiles[section]), value); err != nil {
+			return "", fmt.Errorf("write snapshot %s: %w", section, err)
+		}
+	}
+	if err := writeGzipJSON(path, snap); err != nil {
 		return "", fmt.Errorf("write run snapshot: %w", err)
 	}
 	return path, nil
 }
 
+// listRunSnapshots returns the snapshots in logs/runs, newest first. Files
+// that fail to parse are skipped so one corrupt snapshot does not hide the rest.
+func (a *logArchive) listRunSnapshots() ([]RunSnapshotInfo, error) {
+	if a == nil {
+		return nil, fmt.Errorf("log archive unavailable")
+	}
+	entries, err := os.ReadDir(a.runsDir)
+	if err != nil {
+		return nil, fmt.Errorf("read runs dir: %w", err)
+	}
+	out := []RunSnapshotInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isSnapshotFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(a.runsDir, entry.Name())
+		snap, err := decodeRunSnapshot(path)
+		if err != nil {
+			continue
+		}
+		info := RunSnapshotInfo{
+			Path:       path,
+			Name:       entry.Name(),
+			CapturedAt: snap.CapturedAt,
+			Trigger:    snap.Trigger,
+			RunID:      snap.Dashboard.RunStats.RunID,
+			BookTitle:  snap.Dashboard.BookTitle,
+			Status:     snap.Dashboard.RunStats.Status,
+		}
+		if fi, err := entry.Info(); err == nil {
+			info.SizeBytes = fi.Size()
+		}
+		for _, side := range snap.SideFiles {
+			if fi, err := os.Stat(filepath.Join(a.runsDir, side)); err == nil {
+				info.SizeBytes += fi.Size()
+			}
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name > out[j].Name })
+	return out, nil
+}
+
+// loadRunSnapshot reads a snapshot by absolute path, or by file name relative
+// to logs/runs.
+func (a *logArchive) loadRunSnapshot(path string) (runSnapshot, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return runSnapshot{}, fmt.Errorf("snapshot path is empty")
+	}
+	if !filepath.IsAbs(path) {
+		if a == nil {
+			return runSnapshot{}, fmt.Errorf("log archive unavailable")
+		}
+		path = filepath.Join(a.runsDir, filepath.Base(path))
+	}
+	return readRunSnapshot(path)
+}
+
+func isSnapshotFile(name string) bool {
+	return strings.HasSuffix(name, snapshotExt) || strings.HasSuffix(name, legacySnapshotExt)
+}
+
+// readRunSnapshot reads a snapshot and its side files.
+func readRunSnapshot(path string) (runSnapshot, error) {
+	snap, err := decodeRunSnapshot(path)
+	if err != nil {
+		return runSnapshot{}, err
+	}
+	targets := map[string]any{
+		snapshotSectionLogs:      &snap.Dashboard.Logs,
+		snapshotSectionAIWindows: &snap.Dashboard.AIReport.Windows,
+	}
+	for section, side := range snap.SideFiles {
+		target, ok := targets[section]
+		if !ok {
+			continue
+		}
+		// Side files live beside the snapshot; a snapshot copied from
+		// elsewhere must not point outside its directory.
+		if !filepath.IsLocal(side) {
+			return runSnapshot{}, fmt.Errorf("snapshot %s path %q is not local", section, side)
+		}
+		if err := readGzipJSON(filepath.Join(filepath.Dir(path), side), target); err != nil {
+			return runSnapshot{}, fmt.Errorf("read snapshot %s: %w", section, err)
+		}
+	}
+	return snap, nil
+}
+
+// decodeRunSnapshot reads the snapshot file alone, without side files.
+func decodeRunSnapshot(path string) (runSnapshot, error) {
+	var snap runSnapshot
+	if strings.HasSuffix(path, snapshotExt) {
+		if err := readGzipJSON(path, &snap); err != nil {
+			return runSnapshot{}, fmt.Errorf("read run snapshot: %w", err)
+		}
+		return snap, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return runSnapshot{}, fmt.Errorf("read run snapshot: %w", err)
+	}
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return runSnapshot{}, fmt.Errorf("decode run snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+func writeGzipJSON(path string, value any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if err := json.NewEncoder(zw).Encode(value); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readGzipJSON(path string, out any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return json.NewDecoder(zr).Decode(out)
+}
+
+// Kinds of log data removed by purgeProject.
+const (
+	purgedSnapshot        = "run_snapshot"
+	purgedResourceProfile = "resource_profile"
+	purgedSessionLog      = "session_log"
+)
+
+// purgeProject deletes log data derived from a project's manuscript: its run
+// snapshots (with side files), its lines in resources.jsonl and every session
+// log that mentions one of its runs. Session logs interleave every run of a
+// session, so other manuscripts' lines in those files go too; the live session
+// log is replaced by a fresh one.
+func (a *logArchive) purgeProject(projectLocation string) ([]workspace.PurgedFile, error) {
+	if a == nil {
+		return nil, fmt.Errorf("log archive unavailable")
+	}
+	purged := []workspace.PurgedFile{}
+	runIDs := map[string]bool{}
+	entries, err := os.ReadDir(a.runsDir)
+	if err != nil {
+		return nil, fmt.Errorf("read runs dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isSnapshotFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(a.runsDir, entry.Name())
+		snap, err := decodeRunSnapshot(path)
+		if err != nil || snap.Dashboard.ProjectLocation != projectLocation {
+			continue
+		}
+		if id := snap.Dashboard.RunStats.RunID; id != "" {
+			runIDs[id] = true
+		}
+		files := []string{path}
+		for _, side := range snap.SideFiles {
+			if filepath.IsLocal(side) {
+				files = append(files, filepath.Join(a.runsDir, side))
+			}
+		}
+		for _, file := range files {
+			removed, err := removeFile(file, purgedSnapshot)
+			if err != nil {
+				return purged, err
+			}
+			if removed != nil {
+				purged = append(purged, *removed)
+			}
+		}
+		sideDir := filepath.Join(a.runsDir, strings.TrimSuffix(strings.TrimSuffix(entry.Name(), snapshotExt), legacySnapshotExt))
+		_ = os.Remove(sideDir)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	profiles, err := a.pruneResourceProfiles(runIDs)
+	if err != nil {
+		return purged, err
+	}
+	if profiles != nil {
+		purged = append(purged, *profiles)
+	}
+	sessions, err := a.purgeSessionLogs(projectLocation, runIDs)
+	purged = append(purged, sessions...)
+	return purged, err
+}
+
+func removeFile(path, kind string) (*workspace.PurgedFile, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("remove %s: %w", filepath.Base(path), err)
+	}
+	return &workspace.PurgedFile{Path: path, Kind: kind, Bytes: info.Size()}, nil
+}
+
+// pruneResourceProfiles drops the runs' lines from resources.jsonl. Callers
+// hold a.mu.
+func (a *logArchive) pruneResourceProfiles(runIDs map[string]bool) (*workspace.PurgedFile, error) {
+	path := filepath.Join(a.rootDir, "resources.jsonl")
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) || len(runIDs) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read resource log: %w", err)
+	}
+	kept := []string{}
+	removed := 0
+	for _, line := range strings.Split(strings.TrimRight(string(raw), "\n"), "\n") {
+		var entry resourceProfileLine
+		if json.Unmarshal([]byte(line), &entry) == nil && runIDs[entry.RunID] {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if removed == 0 {
+		return nil, nil
+	}
+	out := strings.Join(kept, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		return nil, fmt.Errorf("write resource log: %w", err)
+	}
+	return &workspace.PurgedFile{Path: path, Kind: purgedResourceProfile, Bytes: int64(len(raw) - len(out)), Entries: removed}, nil
+}
+
+// purgeSessionLogs removes session logs that mention the project or its runs.
+// Callers hold a.mu.
+func (a *logArchive) purgeSessionLogs(projectLocation string, runIDs map[string]bool) ([]workspace.PurgedFile, error) {
+	if a.writer != nil {
+		_ = a.writer.Flush()
+	}
+	paths, err := filepath.Glob(filepath.Join(a.rootDir, "session-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	purged := []workspace.PurgedFile{}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return purged, fmt.Errorf("read session log: %w", err)
+		}
+		if !mentionsProject(string(raw), projectLocation, runIDs) {
+			continue
+		}
+		live := path == a.sessionFile && a.file != nil
+		if live {
+			_ = a.file.Close()
+			a.file, a.writer = nil, nil
+		}
+		removed, err := removeFile(path, purgedSessionLog)
+		if removed != nil {
+			purged = append(purged, *removed)
+		}
+		if live {
+			// Continue in a new file so the removed one stays removed.
+			next := filepath.Join(a.rootDir, "session-"+time.Now().Format("20060102-150405")+".log")
+			for i := 2; next == path; i++ {
+				next = filepath.Join(a.rootDir, fmt.Sprintf("session-%s-%d.log", time.Now().Format("20060102-150405"), i))
+			}
+			if f, openErr := os.OpenFile(next, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); openErr == nil {
+				a.sessionFile = next
+				a.file = f
+				a.writer = bufio.NewWriterSize(f, 64*1024)
+			}
+		}
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+func mentionsProject(text, projectLocation string, runIDs map[string]bool) bool {
+	if projectLocation != "" && strings.Contains(text, projectLocation) {
+		return true
+	}
+	for id := range runIDs {
+		if strings.Contains(text, id) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *logArchive) exportZip(dest string) error {
 	if a == nil {
 		return fmt.Errorf("log archive unavailable")
@@ -125,6 +591,7 @@ func (a *logArchive) exportZip(dest string) error {
 	if strings.TrimSpace(dest) == "" {
 		return fmt.Errorf("destination path is empty")
 	}
+	a.flush()
 	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
 		return fmt.Errorf("create destination dir: %w", err)
 	}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"book_dashboard/desktop/backend"
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/resources"
+)
+
+func TestRunSnapshotsListAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive, err := newLogArchive()
+	if err != nil {
+		t.Fatalf("new log archive: %v", err)
+	}
+	t.Cleanup(archive.close)
+	first := backend.DashboardData{BookTitle: "Older Book", RunStats: backend.RunStats{RunID: "run-1", Status: "FAILED"}}
+	if _, err := archive.persistRunSnapshot("analyze_file", first); err != nil {
+		t.Fatalf("persist first snapshot: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(archive.runsDir, "zzz-corrupt.json"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write corrupt snapshot: %v", err)
+	}
+
+	items, err := archive.listRunSnapshots()
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(items) != 1 || items[0].BookTitle != "Older Book" || items[0].Status != "FAILED" {
+		t.Fatalf("expected one parsed snapshot, got %+v", items)
+	}
+
+	app := NewApp()
+	app.logs = archive
+	data := app.LoadRunSnapshot(items[0].Name)
+	if data.BookTitle != "Older Book" || data.RunStats.RunID != "run-1" {
+		t.Fatalf("expected dashboard rehydrated from snapshot, got %q %q", data.BookTitle, data.RunStats.RunID)
+	}
+	if last := data.Logs[len(data.Logs)-1]; last.Message != "Run snapshot loaded" {
+		t.Fatalf("expected load to be logged, got %+v", last)
+	}
+	if app.state.sourceText() != "" {
+		t.Fatal("expected no source text after loading a snapshot")
+	}
+}
+
+func TestLogArchiveBuffersUntilFlush(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive, err := newLogArchive()
+	if err != nil {
+		t.Fatalf("new log archive: %v", err)
+	}
+	for i := 0; i < 500; i++ {
+		archive.appendLine("ANALYSIS", "CHAPTER", "Read chapter", "buffered line")
+	}
+	archive.close()
+	archive.appendLine("INFO", "LOGS", "after close", "")
+
+	raw, err := os.ReadFile(archive.sessionFile)
+	if err != nil {
+		t.Fatalf("read session log: %v", err)
+	}
+	if got := strings.Count(string(raw), "buffered line"); got != 500 {
+		t.Fatalf("expected all buffered lines flushed on close, got %d", got)
+	}
+	if strings.Contains(string(raw), "after close") {
+		t.Fatal("expected lines after close to be dropped")
+	}
+	archive.close()
+}
+
+func TestResourceProfilesAppendToJSONL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive, err := newLogArchive()
+	if err != nil {
+		t.Fatalf("new log archive: %v", err)
+	}
+	t.Cleanup(archive.close)
+	if err := archive.appendResourceProfile(backend.RunStats{RunID: "run-empty"}); err != nil {
+		t.Fatalf("append empty profile: %v", err)
+	}
+	for _, id := range []string{"run-1", "run-2"} {
+		stats := backend.RunStats{RunID: id, Status: "DONE", Resources: resources.Profile{Samples: 3, PeakProcessRSSMB: 210}}
+		if err := archive.appendResourceProfile(stats); err != nil {
+			t.Fatalf("append profile: %v", err)
+		}
+	}
+	raw, err := os.ReadFile(filepath.Join(archive.RootDir(), "resources.jsonl"))
+	if err != nil {
+		t.Fatalf("read resource log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"run_id":"run-2"`) || !strings.Contains(lines[0], `"peakProcessRssMb":210`) {
+		t.Fatalf("expected two profile lines without the empty run, got %q", raw)
+	}
+}
+
+func TestRunSnapshotsCompressAndSplitLargeSections(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive, err := newLogArchive()
+	if err != nil {
+		t.Fatalf("new log archive: %v", err)
+	}
+	t.Cleanup(archive.close)
+	data := backend.DashboardData{
+		BookTitle: "Big Book",
+		RunStats:  backend.RunStats{RunID: "run-9", Status: "DONE"},
+		Logs:      []backend.LogLine{{Stage: "CHAPTER", Message: strings.Repeat("Read chapter ", 50)}},
+		AIReport:  aidetect.Report{Windows: []aidetect.WindowReport{{WindowID: "w-1"}, {WindowID: "w-2"}}},
+	}
+	path, err := archive.persistRunSnapshot("analyze_file", data)
+	if err != nil {
+		t.Fatalf("persist snapshot: %v", err)
+	}
+	if !strings.HasSuffix(path, ".json.gz") {
+		t.Fatalf("expected a gzip snapshot, got %s", path)
+	}
+	main, err := decodeRunSnapshot(path)
+	if err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if len(main.Dashboard.Logs) != 0 || len(main.Dashboard.AIReport.Windows) != 0 || len(main.SideFiles) != 2 {
+		t.Fatalf("expected logs and windows split out, got %+v", main)
+	}
+
+	legacy := runSnapshot{CapturedAt: "2026-01-01T00:00:00Z", Dashboard: backend.DashboardData{BookTitle: "Old Book", Logs: []backend.LogLine{{Message: "inline"}}}}
+	raw, _ := json.Marshal(legacy)
+	if err := os.WriteFile(filepath.Join(archive.runsDir, "20250101-000000-run-1.json"), raw, 0o644); err != nil {
+		t.Fatalf("write legacy snapshot: %v", err)
+	}
+	items, err := archive.listRunSnapshots()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("expected compressed and legacy snapshots listed, got %+v %v", items, err)
+	}
+
+	snap, err := archive.loadRunSnapshot(filepath.Base(path))
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if len(snap.Dashboard.Logs) != 1 || len(snap.Dashboard.AIReport.Windows) != 2 || snap.Dashboard.AIReport.Windows[1].WindowID != "w-2" {
+		t.Fatalf("expected side files rehydrated, got %+v", snap.Dashboard)
+	}
+	old, err := archive.loadRunSnapshot("20250101-000000-run-1.json")
+	if err != nil || old.Dashboard.Logs[0].Message != "inline" {
+		t.Fatalf("expected legacy snapshot loaded, got %+v %v", old, err)
+	}
+
+	main.SideFiles[snapshotSectionLogs] = "../../escape.json.gz"
+	if err := writeGzipJSON(path, main); err != nil {
+		t.Fatalf("rewrite snapshot: %v", err)
+	}
+	if _, err := readRunSnapshot(path); err == nil {
+		t.Fatal("expected a side file outside the runs directory to be rejected")
+	}
+}
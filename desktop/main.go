@@ -4,6 +4,8 @@ import (
 	"embed"
 	"runtime"
 
+	"book_dashboard/desktop/backend"
+
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/menu"
 	"github.com/wailsapp/wails/v2/pkg/menu/keys"
@@ -29,6 +31,18 @@ func main() {
 			app.PickAndAnalyzeFile()
 		})
 		fileMenu.AddSeparator()
+		fileMenu.AddText("Export Plain Report...", keys.CmdOrCtrl("e"), func(_ *menu.CallbackData) {
+			app.ExportPlainReportDialog()
+		})
+		fileMenu.AddText("Export Author Report...", nil, func(_ *menu.CallbackData) {
+			app.ExportReportDialog(backend.ExportProfileAuthor)
+		})
+		fileMenu.AddText("Export AI Review Packet...", nil, func(_ *menu.CallbackData) {
+			app.ExportAIReviewPacketDialog()
+		})
+		fileMenu.AddText("Export Statistics (CSV)...", nil, func(_ *menu.CallbackData) {
+			app.ExportStatsDialog()
+		})
 		fileMenu.AddText("Export Log Package...", keys.CmdOrCtrl("l"), func(_ *menu.CallbackData) {
 			app.ExportLogPackageDialog()
 		})
@@ -41,6 +55,19 @@ func main() {
 	if runtime.GOOS == "darwin" {
 		appMenu.Append(menu.WindowMenu())
 	}
+	exportMenu := appMenu.AddSubmenu("Export")
+	exportMenu.AddText("Export Plain Report...", keys.CmdOrCtrl("e"), func(_ *menu.CallbackData) {
+		app.ExportPlainReportDialog()
+	})
+	exportMenu.AddText("Export Author Report...", nil, func(_ *menu.CallbackData) {
+		app.ExportReportDialog(backend.ExportProfileAuthor)
+	})
+	exportMenu.AddText("Export AI Review Packet...", nil, func(_ *menu.CallbackData) {
+		app.ExportAIReviewPacketDialog()
+	})
+	exportMenu.AddText("Export Statistics (CSV)...", nil, func(_ *menu.CallbackData) {
+		app.ExportStatsDialog()
+	})
 	diagnosticsMenu := appMenu.AddSubmenu("Diagnostics")
 	diagnosticsMenu.AddText("Export Log Package...", keys.CmdOrCtrl("l"), func(_ *menu.CallbackData) {
 		app.ExportLogPackageDialog()
@@ -60,6 +87,8 @@ func main() {
 		OnStartup:        app.startup,
 		OnShutdown:       app.shutdown,
 		Menu:             appMenu,
+		// Dropping several manuscripts on the window queues them all.
+		DragAndDrop: &options.DragAndDrop{EnableFileDrop: true},
 		Mac: &mac.Options{
 			About: &mac.AboutInfo{
 				Title:   "Manuscript Health Dashboard",
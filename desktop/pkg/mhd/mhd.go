@@ -0,0 +1,143 @@
+// Package mhd runs the manuscript analyzer without the desktop UI, so other Go
+// programs (a submission portal, a batch job) can ingest a manuscript, analyze
+// it and render the report.
+//
+//	result, err := mhd.AnalyzeFile("manuscript.docx", mhd.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(result.MHDScore)
+//	os.WriteFile("report.md", []byte(mhd.Markdown(result)), 0o644)
+//
+// Analysis writes its project and report.json under ~/ManuscriptHealth exactly
+// as the desktop app does, and uses the same Ollama, LanguageTool and
+// configuration files when they are available.
+package mhd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"book_dashboard/desktop/backend"
+	"book_dashboard/internal/ingest"
+)
+
+// Result is the full analysis of one manuscript, as shown in the dashboard
+// and saved to report.json.
+type Result = backend.DashboardData
+
+// ProgressFunc receives the run's percent complete, stage name and a short
+// detail line.
+type ProgressFunc = backend.ProgressFn
+
+// EventBus receives progress events; NewJSONLEventBus writes them as JSON
+// lines.
+type EventBus = backend.EventBus
+
+// NewJSONLEventBus returns a bus writing one JSON object per event to w.
+func NewJSONLEventBus(w io.Writer) EventBus {
+	return backend.NewJSONLEventBus(w)
+}
+
+type Options struct {
+	// Title overrides the book title. AnalyzeFile defaults to the title the
+	// parser gives the document (its file name without the extension), and
+	// AnalyzeText to "Untitled Manuscript".
+	Title string
+	// OnProgress, when set, is called as the run moves through its stages.
+	OnProgress ProgressFunc
+	// Events receives analysis_progress events when OnProgress is not set.
+	// They carry the structured ProgressEvent fields: stage timings, the
+	// current chapter and an estimate of the time remaining.
+	Events EventBus
+	// AISensitivity selects the AI-detection preset ("conservative",
+	// "balanced", "aggressive"); empty uses the project setting.
+	AISensitivity string
+	// Series analyzes the manuscript as the next installment of the named
+	// serialized work: it is checked against the earlier installments
+	// recorded under that name and then recorded itself. Empty analyzes it
+	// standalone.
+	Series string
+	// Anthology analyzes the manuscript as a short-story collection: each
+	// story is analyzed on its own and compared in Result.Anthology. False
+	// uses the project setting.
+	Anthology bool
+	// ManuscriptType is "fiction", "nonfiction" or "memoir"; nonfiction
+	// types skip plot beats and character contradictions and report
+	// Result.Nonfiction instead. Empty uses the project setting.
+	ManuscriptType string
+	// Profile selects the analysis profile: "quick" (offline heuristics
+	// only), "standard" or "deep" (every model pass, embeddings and
+	// per-sentence AI attribution). Empty runs Standard.
+	Profile string
+	// ForceRefresh recomputes the stages normally reused from the workspace
+	// cache when the text is unchanged (genre, chapter summaries, AI
+	// windows) and overwrites the cached results.
+	ForceRefresh bool
+}
+
+func (o Options) context() context.Context {
+	ctx := backend.WithSeries(backend.WithAISensitivity(context.Background(), o.AISensitivity), o.Series)
+	ctx = backend.WithManuscriptType(ctx, o.ManuscriptType)
+	ctx = backend.WithAnalysisProfile(ctx, o.Profile)
+	if o.Anthology {
+		ctx = backend.WithAnthology(ctx, true)
+	}
+	if o.ForceRefresh {
+		ctx = backend.WithForceRefresh(ctx)
+	}
+	if o.OnProgress == nil && o.Events != nil {
+		ctx = backend.WithProgressListener(ctx, backend.ProgressEventListener(o.Events))
+	}
+	return ctx
+}
+
+// AnalyzeFile parses a DOCX or PDF manuscript and analyzes it.
+func AnalyzeFile(path string, opts Options) (Result, error) {
+	parsed, err := ingest.ParseFile(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("parse manuscript: %w", err)
+	}
+	title := parsed.Title
+	if opts.Title != "" {
+		title = opts.Title
+	}
+	return backend.BuildDashboardContext(backend.WithPages(backend.WithHighlights(opts.context(), parsed.Highlights), parsed.Pages), title, filepath.Base(parsed.SourcePath), parsed.SourceBytes, parsed.Text, opts.OnProgress), nil
+}
+
+// AnalyzeText analyzes plain manuscript text, with chapters marked by
+// "Chapter N" headings.
+func AnalyzeText(text string, opts Options) (Result, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Result{}, fmt.Errorf("manuscript text is empty")
+	}
+	title := opts.Title
+	if title == "" {
+		title = "Untitled Manuscript"
+	}
+	return backend.BuildDashboardContext(opts.context(), title, "source.txt", []byte(text), text, opts.OnProgress), nil
+}
+
+// Markdown renders a result as the linear, screen-reader-friendly report
+// written by mhd-report.
+func Markdown(result Result) string {
+	return backend.PlainReport(result)
+}
+
+// AuthorMarkdown renders the author-facing report: Markdown without the
+// AI-likelihood internals, reviewer notes, benchmarks and quality gates.
+func AuthorMarkdown(result Result) string {
+	return backend.PlainReportFor(result, backend.ExportProfileAuthor)
+}
+
+// LoadReport reads a saved report.json, or the report.json inside a project
+// directory, without re-running analysis. Only the fields report.json
+// persists are filled in, plus SourceIntegrity, which warns when the
+// project's source file changed since the report was produced.
+func LoadReport(path string) (Result, error) {
+	return backend.OpenProjectReport(path)
+}
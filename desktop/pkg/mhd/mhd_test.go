@@ -0,0 +1,63 @@
+package mhd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeTextRejectsEmptyText(t *testing.T) {
+	if _, err := AnalyzeText("  \n", Options{}); err == nil {
+		t.Fatal("expected an error for empty text")
+	}
+}
+
+func TestAnalyzeFileReportsParseErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("Chapter 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AnalyzeFile(path, Options{}); err == nil || !strings.Contains(err.Error(), "unsupported file type") {
+		t.Fatalf("expected an unsupported file type error, got %v", err)
+	}
+}
+
+func TestLoadReportFromProjectDir(t *testing.T) {
+	dir := t.TempDir()
+	report := `{"book_title":"Harbor Lights","word_count":1200,"mhd_score":81,"analysis":{"chapter_count":3}}`
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := LoadReport(dir)
+	if err != nil {
+		t.Fatalf("load report: %v", err)
+	}
+	if result.BookTitle != "Harbor Lights" || result.MHDScore != 81 || result.ChapterCount != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if md := Markdown(result); !strings.Contains(md, "Harbor Lights") {
+		t.Fatalf("expected the title in the markdown report:\n%s", md)
+	}
+}
+
+func TestLoadReportWarnsWhenSourceChanged(t *testing.T) {
+	dir := t.TempDir()
+	report := `{"book_title":"Harbor Lights","source_name":"draft.docx","source_sha256":"0000","analysis":{}}`
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "draft.docx"), []byte("new draft"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := LoadReport(dir)
+	if err != nil {
+		t.Fatalf("load report: %v", err)
+	}
+	if result.SourceIntegrity == nil || result.SourceIntegrity.Status != "changed" {
+		t.Fatalf("expected a changed source, got %+v", result.SourceIntegrity)
+	}
+	if md := Markdown(result); !strings.Contains(md, "Warning: draft.docx changed") {
+		t.Fatalf("expected the stale-source warning in the markdown report:\n%s", md)
+	}
+}
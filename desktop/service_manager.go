@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"book_dashboard/desktop/backend"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type serviceManager struct {
@@ -44,7 +43,7 @@ func newServiceManager() *serviceManager {
 	}
 }
 
-func (s *serviceManager) Start(ctx context.Context) {
+func (s *serviceManager) Start(events backend.EventBus) {
 	s.mu.Lock()
 	if s.started {
 		s.mu.Unlock()
@@ -52,20 +51,33 @@ func (s *serviceManager) Start(ctx context.Context) {
 	}
 	s.started = true
 	s.mu.Unlock()
-	go s.ensureReadyInternal(ctx)
+	go s.ensureReadyInternal(events)
 }
 
-func (s *serviceManager) EnsureReady(ctx context.Context) {
+func (s *serviceManager) EnsureReady(events backend.EventBus) {
 	s.mu.Lock()
 	if s.ready || s.initializing {
 		s.mu.Unlock()
 		return
 	}
 	s.mu.Unlock()
-	s.ensureReadyInternal(ctx)
+	s.ensureReadyInternal(events)
 }
 
-func (s *serviceManager) ensureReadyInternal(ctx context.Context) {
+// Retry checks both services again and starts whichever is down, even after
+// an earlier startup found them ready.
+func (s *serviceManager) Retry(events backend.EventBus) {
+	s.mu.Lock()
+	if s.initializing {
+		s.mu.Unlock()
+		return
+	}
+	s.ready = false
+	s.mu.Unlock()
+	s.ensureReadyInternal(events)
+}
+
+func (s *serviceManager) ensureReadyInternal(events backend.EventBus) {
 	s.mu.Lock()
 	if s.ready || s.initializing {
 		s.mu.Unlock()
@@ -85,29 +97,29 @@ func (s *serviceManager) ensureReadyInternal(ctx context.Context) {
 	model := getenv("OLLAMA_LANGUAGE_MODEL", "llama3.1:8b")
 	genreModel := getenv("OLLAMA_GENRE_MODEL", model)
 
-	s.trace(ctx, "INFO", "Service lifecycle start", "initializing dependencies")
+	s.trace(events, "INFO", "Service lifecycle start", "initializing dependencies")
 
 	// LanguageTool
 	if isHTTPAlive(ltURL, 2*time.Second) {
 		s.updateLanguageTool(true, true, "using existing endpoint", "")
-		s.trace(ctx, "INFO", "LanguageTool ready", ltURL)
+		s.trace(events, "INFO", "LanguageTool ready", ltURL)
 	} else {
 		cmd, err := startLanguageTool()
 		if err != nil {
 			s.updateLanguageTool(false, false, "startup failed", err.Error())
-			s.trace(ctx, "RISK", "LanguageTool start failed", err.Error())
+			s.trace(events, "RISK", "LanguageTool start failed", err.Error())
 		} else {
 			s.mu.Lock()
 			s.languageToolProc = cmd
 			s.mu.Unlock()
-			s.trace(ctx, "ANALYSIS", "LanguageTool process started", "waiting for health endpoint")
+			s.trace(events, "ANALYSIS", "LanguageTool process started", "waiting for health endpoint")
 			waitForHTTP(ltURL, 35*time.Second)
 			if isHTTPAlive(ltURL, 2*time.Second) {
 				s.updateLanguageTool(true, true, "started by app", "")
-				s.trace(ctx, "INFO", "LanguageTool ready", ltURL)
+				s.trace(events, "INFO", "LanguageTool ready", ltURL)
 			} else {
 				s.updateLanguageTool(true, false, "process started but endpoint unreachable", "timeout")
-				s.trace(ctx, "RISK", "LanguageTool endpoint unreachable", ltURL)
+				s.trace(events, "RISK", "LanguageTool endpoint unreachable", ltURL)
 			}
 		}
 	}
@@ -116,24 +128,24 @@ func (s *serviceManager) ensureReadyInternal(ctx context.Context) {
 	tagsURL := strings.TrimSuffix(ollamaURL, "/") + "/api/tags"
 	if isHTTPAlive(tagsURL, 2*time.Second) {
 		s.updateOllama(true, true, "using existing endpoint", "")
-		s.trace(ctx, "INFO", "Ollama ready", tagsURL)
+		s.trace(events, "INFO", "Ollama ready", tagsURL)
 	} else {
 		cmd, err := startOllamaServe()
 		if err != nil {
 			s.updateOllama(false, false, "startup failed", err.Error())
-			s.trace(ctx, "RISK", "Ollama start failed", err.Error())
+			s.trace(events, "RISK", "Ollama start failed", err.Error())
 		} else {
 			s.mu.Lock()
 			s.ollamaProc = cmd
 			s.mu.Unlock()
-			s.trace(ctx, "ANALYSIS", "Ollama process started", "waiting for tags endpoint")
+			s.trace(events, "ANALYSIS", "Ollama process started", "waiting for tags endpoint")
 			waitForHTTP(tagsURL, 30*time.Second)
 			if isHTTPAlive(tagsURL, 2*time.Second) {
 				s.updateOllama(true, true, "started by app", "")
-				s.trace(ctx, "INFO", "Ollama ready", tagsURL)
+				s.trace(events, "INFO", "Ollama ready", tagsURL)
 			} else {
 				s.updateOllama(true, false, "process started but endpoint unreachable", "timeout")
-				s.trace(ctx, "RISK", "Ollama endpoint unreachable", tagsURL)
+				s.trace(events, "RISK", "Ollama endpoint unreachable", tagsURL)
 			}
 		}
 	}
@@ -143,24 +155,24 @@ func (s *serviceManager) ensureReadyInternal(ctx context.Context) {
 	ollamaReady := s.ollamaStatus.Ready
 	s.mu.Unlock()
 	if ollamaReady {
-		s.trace(ctx, "ANALYSIS", "Ensuring Ollama language model", model)
+		s.trace(events, "ANALYSIS", "Ensuring Ollama language model", model)
 		if err := pullModel(model); err != nil {
-			s.trace(ctx, "RISK", "Ollama model pull failed", err.Error())
+			s.trace(events, "RISK", "Ollama model pull failed", err.Error())
 			s.mu.Lock()
 			s.ollamaStatus.LastError = err.Error()
 			s.mu.Unlock()
 		} else {
-			s.trace(ctx, "INFO", "Ollama model ready", model)
+			s.trace(events, "INFO", "Ollama model ready", model)
 		}
 		if genreModel != model {
-			s.trace(ctx, "ANALYSIS", "Ensuring Ollama genre model", genreModel)
+			s.trace(events, "ANALYSIS", "Ensuring Ollama genre model", genreModel)
 			if err := pullModel(genreModel); err != nil {
-				s.trace(ctx, "RISK", "Ollama genre model pull failed", err.Error())
+				s.trace(events, "RISK", "Ollama genre model pull failed", err.Error())
 				s.mu.Lock()
 				s.ollamaStatus.LastError = err.Error()
 				s.mu.Unlock()
 			} else {
-				s.trace(ctx, "INFO", "Ollama genre model ready", genreModel)
+				s.trace(events, "INFO", "Ollama genre model ready", genreModel)
 			}
 		}
 	}
@@ -171,7 +183,7 @@ func (s *serviceManager) ensureReadyInternal(ctx context.Context) {
 		overall = "READY"
 	}
 	s.mu.Unlock()
-	s.trace(ctx, "INFO", "Service lifecycle complete", overall)
+	s.trace(events, "INFO", "Service lifecycle complete", overall)
 }
 
 func (s *serviceManager) Stop() {
@@ -236,7 +248,7 @@ func (s *serviceManager) updateLanguageTool(running, ready bool, detail, errMsg
 	})
 }
 
-func (s *serviceManager) trace(ctx context.Context, level, message, detail string) {
+func (s *serviceManager) trace(events backend.EventBus, level, message, detail string) {
 	t := backend.ServiceTrace{Time: time.Now().Format("15:04:05.000"), Level: level, Message: message, Detail: detail}
 	s.mu.Lock()
 	s.traces = append(s.traces, t)
@@ -253,9 +265,9 @@ func (s *serviceManager) trace(ctx context.Context, level, message, detail strin
 		fmt.Printf("%s [SERVICE] [%s] %s: %s\n", t.Time, level, message, detail)
 	}
 
-	if ctx != nil {
-		runtime.EventsEmit(ctx, "analysis_progress", map[string]any{"percent": 1, "stage": "SETUP", "detail": message + ": " + detail})
-		runtime.EventsEmit(ctx, "service_trace", map[string]any{
+	if events != nil {
+		events.Emit(backend.EventAnalysisProgress, map[string]any{"percent": 1, "stage": "SETUP", "detail": message + ": " + detail})
+		events.Emit(backend.EventServiceTrace, map[string]any{
 			"time":    t.Time,
 			"level":   t.Level,
 			"message": t.Message,
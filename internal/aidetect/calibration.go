@@ -0,0 +1,46 @@
+package aidetect
+
+import "strings"
+
+// Calibration rescales the stylistic signals for a genre. Uniform rhythm and
+// polished phrasing are normal in some genres, so their weight is reduced
+// there rather than raising the global bias for every manuscript.
+type Calibration struct {
+	Profile      string  `json:"profile"`
+	StyleWeight  float64 `json:"style_weight"`
+	PolishWeight float64 `json:"polish_weight"`
+	BiasOffset   float64 `json:"bias_offset"`
+}
+
+func NeutralCalibration() Calibration {
+	return Calibration{Profile: "neutral", StyleWeight: 1, PolishWeight: 1}
+}
+
+// genreCalibrations are keyed by the genre labels the dashboard classifier
+// emits. Genres not listed use the neutral profile.
+var genreCalibrations = map[string]Calibration{
+	"romance":  {Profile: "romance", StyleWeight: 0.75, PolishWeight: 0.60, BiasOffset: -0.05},
+	"literary": {Profile: "literary", StyleWeight: 0.70, PolishWeight: 0.65, BiasOffset: -0.05},
+	"thriller": {Profile: "thriller", StyleWeight: 0.85, PolishWeight: 0.90},
+	"mystery":  {Profile: "mystery", StyleWeight: 0.90, PolishWeight: 0.90},
+	"fantasy":  {Profile: "fantasy", StyleWeight: 1.00, PolishWeight: 0.85},
+}
+
+func CalibrationForGenre(genre string) Calibration {
+	if cal, ok := genreCalibrations[strings.ToLower(strings.TrimSpace(genre))]; ok {
+		return cal
+	}
+	return NeutralCalibration()
+}
+
+// normalized treats an unset calibration (zero multipliers) as neutral so a
+// hand-built Config does not silently drop the style signals.
+func (c Calibration) normalized() Calibration {
+	if c.StyleWeight == 0 && c.PolishWeight == 0 && c.BiasOffset == 0 {
+		return NeutralCalibration()
+	}
+	if c.Profile == "" {
+		c.Profile = "custom"
+	}
+	return c
+}
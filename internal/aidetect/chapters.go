@@ -0,0 +1,64 @@
+package aidetect
+
+import "sort"
+
+// ChapterSpan is one chapter's range of word offsets, as counted by Words.
+type ChapterSpan struct {
+	Chapter   int    `json:"chapter"`
+	Title     string `json:"title"`
+	StartWord int    `json:"start_word"`
+	EndWord   int    `json:"end_word"`
+}
+
+// ChapterPAI rolls the windows over one chapter up into chapter scores. A
+// window counts in proportion to how many of its words fall in the chapter,
+// so a window straddling a chapter break is shared between both. The scores
+// are nil when no counted window covers the chapter.
+type ChapterPAI struct {
+	ChapterSpan
+	PAI           *float64 `json:"p_ai"`
+	AICoverageEst *float64 `json:"ai_coverage_est"`
+	PAIMax        *float64 `json:"p_ai_max"`
+	Windows       int      `json:"windows"`
+	ExemptWindows int      `json:"exempt_windows"`
+}
+
+// AggregateChapters sets PAIPerChapter from the report's windows. Exempt
+// windows are left out of the scores, as they are for the document.
+func AggregateChapters(report *Report, chapters []ChapterSpan) {
+	out := make([]ChapterPAI, 0, len(chapters))
+	for _, ch := range chapters {
+		agg := ChapterPAI{ChapterSpan: ch}
+		// Windows are ordered by StartWord; skip those ending before the
+		// chapter starts.
+		first := sort.Search(len(report.Windows), func(i int) bool { return report.Windows[i].EndWord > ch.StartWord })
+		sum, coverage, weight, maxP := 0.0, 0.0, 0.0, 0.0
+		for _, w := range report.Windows[first:] {
+			if w.StartWord >= ch.EndWord {
+				break
+			}
+			overlap := minInt(w.EndWord, ch.EndWord) - maxInt(w.StartWord, ch.StartWord)
+			if overlap <= 0 {
+				continue
+			}
+			if w.Exempt {
+				agg.ExemptWindows++
+				continue
+			}
+			agg.Windows++
+			sum += w.PAI * float64(overlap)
+			coverage += w.PAI * w.Confidence * float64(overlap)
+			weight += float64(overlap)
+			if w.PAI > maxP {
+				maxP = w.PAI
+			}
+		}
+		if weight > 0 {
+			agg.PAI = floatPtr(clamp01(sum / weight))
+			agg.AICoverageEst = floatPtr(clamp01(coverage / weight))
+			agg.PAIMax = floatPtr(maxP)
+		}
+		out = append(out, agg)
+	}
+	report.PAIPerChapter = out
+}
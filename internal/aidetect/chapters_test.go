@@ -0,0 +1,37 @@
+package aidetect
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggregateChaptersWeighsWindowsByOverlap(t *testing.T) {
+	report := Report{Windows: []WindowReport{
+		{StartWord: 0, EndWord: 100, PAI: 0.2, Confidence: 1},
+		{StartWord: 50, EndWord: 150, PAI: 0.8, Confidence: 0.5},
+		{StartWord: 100, EndWord: 200, PAI: 0.9, Confidence: 1, Exempt: true},
+	}}
+	AggregateChapters(&report, []ChapterSpan{
+		{Chapter: 1, Title: "One", StartWord: 0, EndWord: 100},
+		{Chapter: 2, Title: "Two", StartWord: 100, EndWord: 200},
+		{Chapter: 3, Title: "Three", StartWord: 200, EndWord: 300},
+	})
+	if len(report.PAIPerChapter) != 3 {
+		t.Fatalf("want 3 chapters, got %+v", report.PAIPerChapter)
+	}
+	one := report.PAIPerChapter[0]
+	// 100 words at 0.2 and 50 words at 0.8.
+	if one.Windows != 2 || one.PAI == nil || math.Abs(*one.PAI-0.4) > 1e-9 || *one.PAIMax != 0.8 {
+		t.Fatalf("unexpected chapter 1 %+v", one)
+	}
+	if math.Abs(*one.AICoverageEst-(0.2*100+0.4*50)/150) > 1e-9 {
+		t.Fatalf("unexpected chapter 1 coverage %v", *one.AICoverageEst)
+	}
+	two := report.PAIPerChapter[1]
+	if two.Windows != 1 || two.ExemptWindows != 1 || math.Abs(*two.PAI-0.8) > 1e-9 {
+		t.Fatalf("unexpected chapter 2 %+v", two)
+	}
+	if three := report.PAIPerChapter[2]; three.PAI != nil || three.Windows != 0 || three.Title != "Three" {
+		t.Fatalf("want no scores for an uncovered chapter, got %+v", three)
+	}
+}
@@ -11,13 +11,20 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"book_dashboard/internal/scheduler"
 )
 
 type Input struct {
 	DocumentID string `json:"document_id"`
 	Text       string `json:"text"`
 	Language   string `json:"language"`
+	// ExemptSpans are non-overlapping word ranges verified as human-written.
+	// Windows mostly inside them are still scored but left out of the
+	// document aggregate.
+	ExemptSpans []EvidenceSpan `json:"exempt_spans,omitempty"`
 }
 
 type ErrorEntry struct {
@@ -42,11 +49,23 @@ type Evidence struct {
 	Type    string         `json:"type"`
 	Summary string         `json:"summary"`
 	Spans   []EvidenceSpan `json:"spans"`
+	// Diff compares a near-duplicated window with the window it duplicates.
+	// It is nil for other evidence and for runs of the streaming Analyzer,
+	// which does not keep the words of earlier windows.
+	Diff *PairDiff `json:"diff,omitempty"`
 }
 
 type DuplicationSignal struct {
 	Score    *float64   `json:"score"`
 	Evidence []Evidence `json:"evidence"`
+	// LongestWords is the longest run of words the window shares with
+	// another, which can override the weighted score; see
+	// Config.DupOverrideMinWords.
+	LongestWords int `json:"longest_words,omitempty"`
+	// Recaps is near-duplication caused by recap passages, which retell
+	// earlier chapters on purpose; it is kept apart from Evidence and does
+	// not count toward Score.
+	Recaps []Evidence `json:"recaps,omitempty"`
 }
 
 type ScalarSignal struct {
@@ -54,11 +73,14 @@ type ScalarSignal struct {
 }
 
 type WindowSignals struct {
-	Duplication  DuplicationSignal `json:"duplication"`
-	LMSmoothness ScalarSignal      `json:"lm_smoothness"`
-	StyleUniform ScalarSignal      `json:"style_uniformity"`
-	PolishCliche ScalarSignal      `json:"polish_cliche"`
-	LanguageTool ScalarSignal      `json:"language_tool"`
+	Duplication DuplicationSignal `json:"duplication"`
+	// SemanticDuplication compares window embeddings, which catches
+	// paraphrased repetition; its score is nil unless embeddings are enabled.
+	SemanticDuplication DuplicationSignal `json:"semantic_duplication"`
+	LMSmoothness        ScalarSignal      `json:"lm_smoothness"`
+	StyleUniform        ScalarSignal      `json:"style_uniformity"`
+	PolishCliche        ScalarSignal      `json:"polish_cliche"`
+	LanguageTool        ScalarSignal      `json:"language_tool"`
 }
 
 type WindowReport struct {
@@ -69,6 +91,27 @@ type WindowReport struct {
 	Confidence  float64       `json:"confidence"`
 	Signals     WindowSignals `json:"signals"`
 	TopEvidence []Evidence    `json:"top_evidence"`
+	// Location is filled in by the caller, which knows the chapter layout;
+	// nil when the window could not be placed.
+	Location *WindowLocation `json:"location,omitempty"`
+	// Exempt marks a window inside verified-human text; see Input.ExemptSpans.
+	Exempt bool `json:"exempt,omitempty"`
+	// AnchorID is filled in by the caller from the window's opening words so
+	// the window can be matched across runs whose offsets differ.
+	AnchorID string `json:"anchor_id,omitempty"`
+}
+
+// WindowLocation places a window's word range in the manuscript's chapters
+// and paragraphs, both 1-based. For a PDF source it also gives the pages the
+// window spans; they are 0 otherwise.
+type WindowLocation struct {
+	StartChapter   int    `json:"start_chapter"`
+	StartParagraph int    `json:"start_paragraph"`
+	EndChapter     int    `json:"end_chapter"`
+	EndParagraph   int    `json:"end_paragraph"`
+	StartPage      int    `json:"start_page,omitempty"`
+	EndPage        int    `json:"end_page,omitempty"`
+	Label          string `json:"label"`
 }
 
 type Report struct {
@@ -82,16 +125,40 @@ type Report struct {
 	Errors        []ErrorEntry   `json:"errors"`
 	Traces        []SpanTrace    `json:"traces"`
 	WordCount     int            `json:"word_count"`
+	Calibration   Calibration    `json:"calibration"`
+	Sensitivity   string         `json:"sensitivity"`
+	// Weighting is the profile that combined the signals into PAI.
+	Weighting     WeightingProfile `json:"weighting"`
+	ExemptWindows int              `json:"exempt_windows"`
+	// PAIPerChapter is filled in by AggregateChapters when the caller knows
+	// the chapter layout.
+	PAIPerChapter []ChapterPAI `json:"p_ai_per_chapter,omitempty"`
+	// Sentences is filled in by AttributeSentences when the run asks for
+	// per-sentence attribution.
+	Sentences []SentenceAttribution `json:"sentences,omitempty"`
+	// Intensifiers is the custom intensifier lexicon the run used, if any,
+	// for AttributeSentences to score sentences with. It is not serialized:
+	// reports are shared, and the caller records the lexicon by fingerprint.
+	Intensifiers []string `json:"-"`
 }
 
 type Config struct {
-	WindowWords           int
-	StrideWords           int
-	DupNGramN             int
-	NearDupThreshold      float64
-	DupOverrideMinWords   int
-	CoverageTrigger       float64
-	Bias                  float64
+	// Sensitivity names the preset the thresholds came from; see
+	// ConfigForSensitivity.
+	Sensitivity         string
+	WindowWords         int
+	StrideWords         int
+	DupNGramN           int
+	NearDupThreshold    float64
+	DupOverrideMinWords int
+	CoverageTrigger     float64
+	Bias                float64
+	// StyleScale multiplies the style and polish signal weights.
+	StyleScale float64
+	// ChunkFlagPAI and WidespreadCoverage are the window probability and
+	// coverage at which ai_chunk_detected and widespread_ai_signal are raised.
+	ChunkFlagPAI          float64
+	WidespreadCoverage    float64
 	EnableLanguageTool    bool
 	EnableLMSmoothness    bool
 	LanguageToolTimeoutMs int
@@ -99,6 +166,28 @@ type Config struct {
 	LanguageToolMaxWindow int
 	LanguageToolMaxFails  int
 	LMSmoothnessTimeoutMs int
+	// EnableSemanticDup embeds every window with Embedder and scores
+	// paraphrased duplication between windows at least SemanticDupThreshold
+	// cosine-similar.
+	EnableSemanticDup    bool
+	SemanticDupThreshold float64
+	EmbeddingTimeoutMs   int
+	Calibration          Calibration
+	// Weighting sets how much each signal counts; see WeightingProfile. An
+	// unset profile is balanced.
+	Weighting WeightingProfile
+	// LanguageToolLimiter, when set, scores sampled windows concurrently
+	// under its adaptive limit; nil scores them one at a time.
+	LanguageToolLimiter *scheduler.Limiter `json:"-"`
+	// Embedder is the embedding scorer used when EnableSemanticDup is set.
+	Embedder EmbeddingScorer `json:"-"`
+	// Exclusions match text that is repeated by design, such as epigraphs or
+	// quoted lyrics; matched words are left out of duplication scoring like
+	// intentional repetition.
+	Exclusions []*regexp.Regexp `json:"-"`
+	// Intensifiers replaces the built-in intensifier lexicon of the
+	// polish signal when non-nil; see DefaultIntensifiers.
+	Intensifiers []string `json:"-"`
 }
 
 type LanguageToolScorer interface {
@@ -114,31 +203,28 @@ type Logger interface {
 }
 
 func DefaultConfig() Config {
-	return Config{
-		WindowWords:           getenvInt("AI_WINDOW_WORDS", 900),
-		StrideWords:           getenvInt("AI_STRIDE_WORDS", 450),
-		DupNGramN:             getenvInt("AI_DUP_NGRAM_N", 10),
-		NearDupThreshold:      getenvFloat("AI_NEAR_DUP_THRESHOLD", 0.18),
-		DupOverrideMinWords:   getenvInt("AI_DUP_OVERRIDE_MIN_WORDS", 250),
-		CoverageTrigger:       getenvFloat("AI_COVERAGE_TRIGGER", 0.03),
-		Bias:                  getenvFloat("AI_BIAS", -0.20),
-		EnableLanguageTool:    getenvBool("AI_ENABLE_LANGUAGE_TOOL", true),
-		EnableLMSmoothness:    getenvBool("AI_ENABLE_LM_SMOOTHNESS", false),
-		LanguageToolTimeoutMs: getenvInt("AI_LANGUAGETOOL_TIMEOUT_MS", 5000),
-		LanguageToolStride:    getenvInt("AI_LANGUAGETOOL_STRIDE", 3),
-		LanguageToolMaxWindow: getenvInt("AI_LANGUAGETOOL_MAX_WINDOWS", 24),
-		LanguageToolMaxFails:  getenvInt("AI_LANGUAGETOOL_MAX_FAILS", 3),
-		LMSmoothnessTimeoutMs: getenvInt("AI_LM_TIMEOUT_MS", 5000),
-	}
+	return ConfigForSensitivity(SensitivityBalanced)
 }
 
 func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer, logger Logger) Report {
+	return AnalyzeContext(context.Background(), in, cfg, lt, lm, logger)
+}
+
+// AnalyzeContext is Analyze for a run that can be cancelled: LanguageTool,
+// LM and embedding calls derive their timeouts from ctx, and no new call
+// starts once it is done. A cancelled run's report carries a run_cancelled
+// error, so it is never taken for a complete one.
+func AnalyzeContext(ctx context.Context, in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer, logger Logger) Report {
 	report := Report{
-		DocumentID: in.DocumentID,
-		Flags:      []string{},
-		Windows:    []WindowReport{},
-		Errors:     []ErrorEntry{},
-		Traces:     []SpanTrace{},
+		DocumentID:   in.DocumentID,
+		Flags:        []string{},
+		Windows:      []WindowReport{},
+		Errors:       []ErrorEntry{},
+		Traces:       []SpanTrace{},
+		Calibration:  cfg.Calibration.normalized(),
+		Sensitivity:  cfg.Sensitivity,
+		Weighting:    cfg.Weighting.normalized(),
+		Intensifiers: cfg.Intensifiers,
 	}
 	if strings.TrimSpace(in.Language) != "" && !strings.EqualFold(in.Language, "en") {
 		report.Errors = append(report.Errors, ErrorEntry{
@@ -178,38 +264,57 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 
 	paraDupMap := map[string][]paragraphLoc{}
 	shingleSets := make([]map[string]struct{}, len(windows))
+	// recapSets holds, for windows overlapping a recap, the shingles with
+	// the recap text left in.
+	recapSets := make([]map[string]struct{}, len(windows))
+	var intentional, recaps []intentionalSpan
+	var repeatMask []int
 	withSpan(&report, "duplication_scan", func() error {
 		paraDupMap = buildParagraphHashIndex(normalized, words)
+		// Refrains, repeated letters, epigraphs and excluded text are
+		// repeated on purpose; their words are kept out of the shingles so
+		// they cannot drive the near-duplicate score or the long-duplicate
+		// override. Recaps are kept out the same way.
+		intentional = mergeSpans(append(findIntentionalRepetition(in.Text), excludedSpans(in.Text, cfg.Exclusions, 0)...))
+		recaps = recapSpans(in.Text, 0)
+		repeatMask = repetitionMask(append(append([]intentionalSpan{}, intentional...), recaps...), len(words))
+		var intentionalMask []int
+		if len(recaps) > 0 {
+			intentionalMask = repetitionMask(intentional, len(words))
+		}
 		for i, w := range windows {
-			shingleSets[i] = shingleSet(words[w.Start:w.End], cfg.DupNGramN)
+			shingleSets[i] = shingleSet(unmaskedWords(words, w, repeatMask), cfg.DupNGramN)
+			if spanMaskedWords(recaps, w.Start, w.End) > 0 {
+				recapSets[i] = shingleSet(unmaskedWords(words, w, intentionalMask), cfg.DupNGramN)
+			}
 		}
 		return nil
 	})
 
 	ltUnavailable := false
 	lmUnavailable := false
-	dupSignals := make([]float64, len(windows))
-	styleSignals := make([]float64, len(windows))
-	polishSignals := make([]float64, len(windows))
-	ltSignals := make([]*float64, len(windows))
-	lmSignals := make([]*float64, len(windows))
-	windowEvidences := make([][]Evidence, len(windows))
-	overrideLongDup := make([]bool, len(windows))
-	overrideDupWords := make([]int, len(windows))
-
+	scores := make([]windowScores, len(windows))
+	masked := func(start, end int) int { return maskedWords(repeatMask, start, end) }
+	similarity := func(i, j int) float64 { return jaccard(shingleSets[i], shingleSets[j]) }
+	withRecap := func(i, j int) float64 {
+		a, b := recapSets[i], recapSets[j]
+		if a == nil {
+			a = shingleSets[i]
+		}
+		if b == nil {
+			b = shingleSets[j]
+		}
+		return jaccard(a, b)
+	}
+	pairDiff := func(i, j int) *PairDiff { return windowPairDiff(words, windows[i], j, windows[j]) }
+	intensifiers := intensifierSet(cfg.Intensifiers)
 	for i, w := range windows {
 		windowWords := words[w.Start:w.End]
 		windowText := strings.Join(windowWords, " ")
-		dupScore, dupEvidence, longestDupWords := windowDupSignal(i, w, windows, windowWords, paraDupMap, shingleSets, cfg.NearDupThreshold, cfg.WindowWords)
-		dupSignals[i] = dupScore
-		windowEvidences[i] = dupEvidence
-		overrideDupWords[i] = longestDupWords
-		if longestDupWords >= cfg.DupOverrideMinWords {
-			overrideLongDup[i] = true
-		}
-
-		styleSignals[i] = styleUniformityScore(windowText)
-		polishSignals[i] = polishClicheScore(windowWords, windowText)
+		scores[i].dup, scores[i].evidence, scores[i].longestDup = windowDupSignal(i, w, len(windows), paraDupMap, masked, similarity, pairDiff, cfg.NearDupThreshold, cfg.WindowWords)
+		scores[i].recapEvidence = recapDupEvidence(i, w, len(windows), recaps, withRecap, similarity, pairDiff, cfg.NearDupThreshold)
+		scores[i].style = styleUniformityScore(windowText)
+		scores[i].polish = polishClicheScore(windowWords, windowText, intensifiers)
 	}
 
 	withSpan(&report, "language_tool_run", func() error {
@@ -223,20 +328,38 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 			})
 			return nil
 		}
+		sampled := []int{}
+		for i := range windows {
+			if shouldRunLanguageTool(i, len(windows), cfg, len(sampled)) {
+				sampled = append(sampled, i)
+			}
+		}
+		limiter := cfg.LanguageToolLimiter
+		if limiter == nil {
+			limiter = scheduler.New("language_tool_windows", 1, nil)
+		}
+		runCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		var mu sync.Mutex
 		failCount := 0
 		failType := ""
 		failMessage := ""
-		successCount := 0
 		attemptedCount := 0
 		consecutiveFailures := 0
-		for i, w := range windows {
-			if !shouldRunLanguageTool(i, len(windows), cfg, attemptedCount) {
-				continue
-			}
-			attemptedCount++
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.LanguageToolTimeoutMs)*time.Millisecond)
-			score, err := lt.ScoreWindow(ctx, strings.Join(words[w.Start:w.End], " "))
+		sizes := make([]int, len(sampled))
+		for j, i := range sampled {
+			sizes[j] = windows[i].End - windows[i].Start
+		}
+		// Stopping after repeated failures only prevents new calls; calls
+		// already in flight finish under their own timeout.
+		scheduler.Each(runCtx, limiter, sizes, func(_ context.Context, j int) error {
+			w := windows[sampled[j]]
+			callCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.LanguageToolTimeoutMs)*time.Millisecond)
+			score, err := lt.ScoreWindow(callCtx, strings.Join(words[w.Start:w.End], " "))
 			cancel()
+			mu.Lock()
+			defer mu.Unlock()
+			attemptedCount++
 			if err != nil {
 				ltUnavailable = true
 				failCount++
@@ -248,15 +371,15 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 					failMessage = err.Error()
 				}
 				if consecutiveFailures >= maxInt(1, cfg.LanguageToolMaxFails) {
-					break
+					stop()
 				}
-				continue
+				return err
 			}
 			s := clamp01(score)
-			ltSignals[i] = &s
-			successCount++
+			scores[sampled[j]].lt = &s
 			consecutiveFailures = 0
-		}
+			return nil
+		})
 		if failCount > 0 {
 			msg := failMessage
 			if msg == "" {
@@ -287,8 +410,11 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 		failType := ""
 		failMessage := ""
 		for i, w := range windows {
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.LMSmoothnessTimeoutMs)*time.Millisecond)
-			score, err := lm.ScoreWindow(ctx, strings.Join(words[w.Start:w.End], " "))
+			if ctx.Err() != nil {
+				break
+			}
+			callCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.LMSmoothnessTimeoutMs)*time.Millisecond)
+			score, err := lm.ScoreWindow(callCtx, strings.Join(words[w.Start:w.End], " "))
 			cancel()
 			if err != nil {
 				lmUnavailable = true
@@ -305,7 +431,7 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 				continue
 			}
 			s := clamp01(score)
-			lmSignals[i] = &s
+			scores[i].lm = &s
 		}
 		if failCount > 0 {
 			msg := failMessage
@@ -322,207 +448,275 @@ func Analyze(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer,
 		return nil
 	})
 
-	withSpan(&report, "score_windows", func() error {
+	withSpan(&report, "embedding_run", func() error {
+		if !cfg.EnableSemanticDup {
+			return nil
+		}
+		embedder := newSemanticEmbedder(ctx, cfg)
+		vectors := make([][]float64, len(windows))
 		for i, w := range windows {
-			weights := signalWeights(!lmUnavailable && lmSignals[i] != nil)
-			signals := WindowSignals{
-				Duplication: DuplicationSignal{
-					Score:    floatPtr(dupSignals[i]),
-					Evidence: windowEvidences[i],
-				},
-				LMSmoothness: ScalarSignal{Score: lmSignals[i]},
-				StyleUniform: ScalarSignal{Score: floatPtr(styleSignals[i])},
-				PolishCliche: ScalarSignal{Score: floatPtr(polishSignals[i])},
-				LanguageTool: ScalarSignal{Score: ltSignals[i]},
-			}
-
-			sum := weights.Duplication*dupSignals[i] + weights.StyleUniform*styleSignals[i] + weights.PolishCliche*polishSignals[i]
-			if lmSignals[i] != nil {
-				sum += weights.LMSmoothness * *lmSignals[i]
-			}
-			if ltSignals[i] != nil {
-				sum += weights.LanguageTool * *ltSignals[i]
-			}
-			p := sigmoid(sum + cfg.Bias)
-
-			conf := 0.6
-			if dupSignals[i] > 0.0 || len(windowEvidences[i]) > 0 {
-				conf += 0.15
-			}
-			agree := 0
-			if dupSignals[i] > 0.6 {
-				agree++
-			}
-			if styleSignals[i] > 0.6 {
-				agree++
-			}
-			if polishSignals[i] > 0.6 {
-				agree++
-			}
-			if lmSignals[i] != nil && *lmSignals[i] > 0.6 {
-				agree++
-			}
-			if ltSignals[i] != nil && *ltSignals[i] > 0.6 {
-				agree++
-			}
-			if agree >= 3 {
-				conf += 0.10
-			}
-			if lmSignals[i] == nil {
-				conf -= 0.20
-			}
-			if w.End-w.Start < 600 {
-				conf -= 0.10
-			}
-			conf = clamp01(conf)
-
-			topEvidence := topEvidence(windowEvidences[i], 3)
-			if overrideLongDup[i] {
-				p = math.Max(p, 0.90)
-				conf = math.Max(conf, 0.80)
-				topEvidence = append(topEvidence, Evidence{
-					Type:    "duplication",
-					Summary: "long duplicate span",
-					Spans:   []EvidenceSpan{{Start: w.Start, End: minInt(w.End, w.Start+overrideDupWords[i])}},
-				})
-			}
-
-			report.Windows = append(report.Windows, WindowReport{
-				WindowID:    fmt.Sprintf("w-%03d", i),
-				StartWord:   w.Start,
-				EndWord:     w.End,
-				PAI:         clamp01(p),
-				Confidence:  conf,
-				Signals:     signals,
-				TopEvidence: topEvidence,
-			})
+			vectors[i] = embedder.embed(strings.Join(words[w.Start:w.End], " "))
 		}
+		report.Errors = append(report.Errors, embedder.errors(len(windows))...)
+		semanticDupSignals(scores, windows, vectors, cfg.SemanticDupThreshold, cfg.WindowWords)
 		return nil
 	})
 
-	withSpan(&report, "aggregate_document", func() error {
-		if len(report.Windows) == 0 {
-			report.Errors = append(report.Errors, ErrorEntry{
-				Stage:     "aggregate_document",
-				Message:   "no windows to aggregate",
-				Type:      "exception",
-				Retryable: false,
-			})
-			return nil
-		}
-		maxP := 0.0
-		covNum := 0.0
-		covDen := 0.0
-		type sc struct {
-			p  float64
-			c  float64
-			pw float64
-		}
-		top := make([]sc, 0, len(report.Windows))
-
-		for _, w := range report.Windows {
-			pw := clamp01(w.PAI * w.Confidence)
-			if w.PAI > maxP {
-				maxP = w.PAI
-			}
-			length := float64(maxInt(1, w.EndWord-w.StartWord))
-			covNum += w.PAI * w.Confidence * length
-			covDen += length
-			top = append(top, sc{p: w.PAI, c: w.Confidence, pw: pw})
-		}
-		coverage := 0.0
-		if covDen > 0 {
-			coverage = covNum / covDen
-		}
-		sort.Slice(top, func(i, j int) bool { return top[i].pw > top[j].pw })
-		limit := minInt(10, len(top))
-		topPWMean := 0.0
-		cn := 0.0
-		cd := 0.0
-		for i := 0; i < limit; i++ {
-			topPWMean += top[i].pw
-			cn += top[i].c
-			cd += 1.0
-		}
-		if limit > 0 {
-			topPWMean /= float64(limit)
-		}
-		confDoc := 0.0
-		if cd > 0 {
-			confDoc = cn / cd
-		}
-		coverageSignal := 0.0
-		if coverage > cfg.CoverageTrigger {
-			den := maxFloat(0.01, 0.35-cfg.CoverageTrigger)
-			coverageSignal = clamp01((coverage - cfg.CoverageTrigger) / den)
-		}
-		// Conservative doc aggregation to avoid saturating on long manuscripts with many medium windows.
-		pDoc := clamp01(0.50*topPWMean + 0.35*maxP + 0.15*coverageSignal)
+	if err := ctx.Err(); err != nil {
+		report.Errors = append(report.Errors, cancelledError(err))
+	}
 
-		if maxP >= 0.85 {
-			report.Flags = append(report.Flags, "ai_chunk_detected")
-		}
-		if coverage >= 0.35 {
-			report.Flags = append(report.Flags, "widespread_ai_signal")
-		}
-		if hasDupFlag(report.Windows) {
-			report.Flags = append(report.Flags, "possible_stitching")
-		}
-		if coverage >= cfg.CoverageTrigger {
-			report.Flags = append(report.Flags, "coverage_trigger_exceeded")
+	withSpan(&report, "score_windows", func() error {
+		for i, w := range windows {
+			report.Windows = append(report.Windows, scoreWindow(i, w, scores[i], cfg, report.Calibration, lmUnavailable, intentional, in.ExemptSpans))
 		}
-
-		report.PAIDoc = floatPtr(clamp01(pDoc))
-		report.AICoverageEst = floatPtr(clamp01(coverage))
-		report.PAIMax = floatPtr(clamp01(maxP))
-		report.ConfidenceDoc = floatPtr(clamp01(confDoc))
 		return nil
 	})
 
+	withSpan(&report, "aggregate_document", func() error {
+		return aggregateDocument(&report, cfg)
+	})
+
 	if logger != nil {
 		errCount := len(report.Errors)
-		logger.Log("ANALYSIS", "AI", "AI detection run completed", fmt.Sprintf("document_id=%s words=%d windows=%d errors=%d p_ai_doc=%.3f coverage=%.3f p_ai_max=%.3f duration_ms=%d lm_available=%t lt_available=%t",
-			in.DocumentID, report.WordCount, len(report.Windows), errCount, deref(report.PAIDoc), deref(report.AICoverageEst), deref(report.PAIMax),
+		logger.Log("ANALYSIS", "AI", "AI detection run completed", fmt.Sprintf("document_id=%s words=%d windows=%d errors=%d p_ai_doc=%.3f coverage=%.3f p_ai_max=%.3f exempt_windows=%d duration_ms=%d lm_available=%t lt_available=%t",
+			in.DocumentID, report.WordCount, len(report.Windows), errCount, deref(report.PAIDoc), deref(report.AICoverageEst), deref(report.PAIMax), report.ExemptWindows,
 			time.Since(startAll).Milliseconds(), !lmUnavailable, !ltUnavailable))
 	}
 	return report
 }
 
-type wordWindow struct {
-	Start int
-	End   int
+// windowScores holds one window's signals before they are weighted into a
+// probability.
+type windowScores struct {
+	dup        float64
+	evidence   []Evidence
+	longestDup int
+	style      float64
+	polish     float64
+	lt         *float64
+	lm         *float64
+	// semantic is nil when the window was not embedded.
+	semantic         *float64
+	semanticEvidence []Evidence
+	recapEvidence    []Evidence
 }
 
-type paragraphLoc struct {
-	Start int
-	End   int
+// scoreWindow weighs one window's signals into its probability and
+// confidence. lmUnavailable is true once the LM scorer has failed for the
+// run, which moves every window onto the weights without it.
+func scoreWindow(i int, w wordWindow, s windowScores, cfg Config, cal Calibration, lmUnavailable bool, intentional []intentionalSpan, exempt []EvidenceSpan) WindowReport {
+	signals := WindowSignals{
+		Duplication: DuplicationSignal{
+			Score:        floatPtr(s.dup),
+			Evidence:     s.evidence,
+			LongestWords: s.longestDup,
+			Recaps:       s.recapEvidence,
+		},
+		LMSmoothness: ScalarSignal{Score: s.lm},
+		StyleUniform: ScalarSignal{Score: floatPtr(s.style)},
+		PolishCliche: ScalarSignal{Score: floatPtr(s.polish)},
+		LanguageTool: ScalarSignal{Score: s.lt},
+		SemanticDuplication: DuplicationSignal{
+			Score:    s.semantic,
+			Evidence: s.semanticEvidence,
+		},
+	}
+
+	p, conf := windowProbability(s, w.End-w.Start, cfg, cal, lmUnavailable)
+
+	topEvidence := append(topEvidence(append(s.evidence[:len(s.evidence):len(s.evidence)], s.semanticEvidence...), 3), repetitionEvidence(w, intentional)...)
+	topEvidence = append(topEvidence, s.recapEvidence...)
+	if s.longestDup >= cfg.DupOverrideMinWords {
+		topEvidence = append(topEvidence, longDuplicateEvidence(w, s.longestDup))
+	}
+
+	return WindowReport{
+		WindowID:    windowID(i),
+		StartWord:   w.Start,
+		EndWord:     w.End,
+		PAI:         clamp01(p),
+		Confidence:  conf,
+		Signals:     signals,
+		TopEvidence: topEvidence,
+		Exempt:      mostlyExempt(w, exempt),
+	}
+}
+
+// windowProbability weighs a window's signals into its probability and
+// confidence. words is the window's length; a duplicate run of at least
+// Config.DupOverrideMinWords words overrides the weighted probability.
+func windowProbability(s windowScores, words int, cfg Config, cal Calibration, lmUnavailable bool) (float64, float64) {
+	styleScale, _, _ := cfg.flagThresholds()
+	weights := cfg.Weighting.normalized().weights(!lmUnavailable && s.lm != nil)
+	sum := weights.Duplication*s.dup + styleScale*(cal.StyleWeight*weights.StyleUniform*s.style+cal.PolishWeight*weights.PolishCliche*s.polish)
+	if s.lm != nil {
+		sum += weights.LMSmoothness * *s.lm
+	}
+	if s.lt != nil {
+		sum += weights.LanguageTool * *s.lt
+	}
+	if s.semantic != nil {
+		sum += weights.SemanticDuplication * *s.semantic
+	}
+	p := sigmoid(sum + cfg.Bias + cal.BiasOffset)
+
+	conf := 0.6
+	if s.dup > 0.0 || len(s.evidence) > 0 || len(s.semanticEvidence) > 0 {
+		conf += 0.15
+	}
+	agree := 0
+	if s.dup > 0.6 {
+		agree++
+	}
+	if s.style > 0.6 {
+		agree++
+	}
+	if s.polish > 0.6 {
+		agree++
+	}
+	if s.lm != nil && *s.lm > 0.6 {
+		agree++
+	}
+	if s.lt != nil && *s.lt > 0.6 {
+		agree++
+	}
+	if s.semantic != nil && *s.semantic > 0.6 {
+		agree++
+	}
+	if agree >= 3 {
+		conf += 0.10
+	}
+	if s.lm == nil {
+		conf -= 0.20
+	}
+	if words < 600 {
+		conf -= 0.10
+	}
+	conf = clamp01(conf)
+
+	if s.longestDup >= cfg.DupOverrideMinWords {
+		p = math.Max(p, 0.90)
+		conf = math.Max(conf, 0.80)
+	}
+	return clamp01(p), conf
 }
 
-type weights struct {
-	Duplication  float64
-	LMSmoothness float64
-	StyleUniform float64
-	PolishCliche float64
-	LanguageTool float64
+func longDuplicateEvidence(w wordWindow, longest int) Evidence {
+	return Evidence{
+		Type:    "duplication",
+		Summary: "long duplicate span",
+		Spans:   []EvidenceSpan{{Start: w.Start, End: minInt(w.End, w.Start+longest)}},
+	}
 }
 
-func signalWeights(lmAvailable bool) weights {
-	if lmAvailable {
-		return weights{
-			Duplication:  0.35,
-			LMSmoothness: 0.30,
-			StyleUniform: 0.20,
-			PolishCliche: 0.10,
-			LanguageTool: 0.05,
+// aggregateDocument rolls the scored windows up into the document
+// probability, coverage and flags. Exempt windows are counted but left out.
+func aggregateDocument(report *Report, cfg Config) error {
+	_, chunkFlagPAI, widespreadCoverage := cfg.flagThresholds()
+	if len(report.Windows) == 0 {
+		report.Errors = append(report.Errors, ErrorEntry{
+			Stage:     "aggregate_document",
+			Message:   "no windows to aggregate",
+			Type:      "exception",
+			Retryable: false,
+		})
+		return nil
+	}
+	counted := make([]WindowReport, 0, len(report.Windows))
+	for _, w := range report.Windows {
+		if w.Exempt {
+			report.ExemptWindows++
+			continue
 		}
+		counted = append(counted, w)
+	}
+	if len(counted) == 0 {
+		// Everything analyzed is verified human; there is nothing to
+		// attribute to a model.
+		report.PAIDoc = floatPtr(0)
+		report.AICoverageEst = floatPtr(0)
+		report.PAIMax = floatPtr(0)
+		report.ConfidenceDoc = floatPtr(0)
+		return nil
+	}
+	maxP := 0.0
+	covNum := 0.0
+	covDen := 0.0
+	type sc struct {
+		p  float64
+		c  float64
+		pw float64
+	}
+	top := make([]sc, 0, len(counted))
+
+	for _, w := range counted {
+		pw := clamp01(w.PAI * w.Confidence)
+		if w.PAI > maxP {
+			maxP = w.PAI
+		}
+		length := float64(maxInt(1, w.EndWord-w.StartWord))
+		covNum += w.PAI * w.Confidence * length
+		covDen += length
+		top = append(top, sc{p: w.PAI, c: w.Confidence, pw: pw})
+	}
+	coverage := 0.0
+	if covDen > 0 {
+		coverage = covNum / covDen
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].pw > top[j].pw })
+	limit := minInt(10, len(top))
+	topPWMean := 0.0
+	cn := 0.0
+	cd := 0.0
+	for i := 0; i < limit; i++ {
+		topPWMean += top[i].pw
+		cn += top[i].c
+		cd += 1.0
+	}
+	if limit > 0 {
+		topPWMean /= float64(limit)
+	}
+	confDoc := 0.0
+	if cd > 0 {
+		confDoc = cn / cd
 	}
-	return weights{
-		Duplication:  0.50,
-		LMSmoothness: 0.0,
-		StyleUniform: 0.30,
-		PolishCliche: 0.15,
-		LanguageTool: 0.05,
+	coverageSignal := 0.0
+	if coverage > cfg.CoverageTrigger {
+		den := maxFloat(0.01, widespreadCoverage-cfg.CoverageTrigger)
+		coverageSignal = clamp01((coverage - cfg.CoverageTrigger) / den)
 	}
+	// Conservative doc aggregation to avoid saturating on long manuscripts with many medium windows.
+	pDoc := clamp01(0.50*topPWMean + 0.35*maxP + 0.15*coverageSignal)
+
+	if maxP >= chunkFlagPAI {
+		report.Flags = append(report.Flags, "ai_chunk_detected")
+	}
+	if coverage >= widespreadCoverage {
+		report.Flags = append(report.Flags, "widespread_ai_signal")
+	}
+	if hasDupFlag(counted) {
+		report.Flags = append(report.Flags, "possible_stitching")
+	}
+	if coverage >= cfg.CoverageTrigger {
+		report.Flags = append(report.Flags, "coverage_trigger_exceeded")
+	}
+
+	report.PAIDoc = floatPtr(clamp01(pDoc))
+	report.AICoverageEst = floatPtr(clamp01(coverage))
+	report.PAIMax = floatPtr(clamp01(maxP))
+	report.ConfidenceDoc = floatPtr(clamp01(confDoc))
+	return nil
+}
+
+type wordWindow struct {
+	Start int
+	End   int
+}
+
+type paragraphLoc struct {
+	Start int
+	End   int
 }
 
 func withSpan(report *Report, name string, fn func() error) {
@@ -559,6 +753,27 @@ var multiNewLine = regexp.MustCompile(`\n{3,}`)
 var sentenceSplit = regexp.MustCompile(`[.!?]+`)
 var wordFinder = regexp.MustCompile(`[a-z0-9']+`)
 
+// rawWordFinder finds Words' words in text that has not been normalized:
+// normalization keeps only ASCII letters and digits inside words.
+var rawWordFinder = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// Words tokenizes text exactly as Analyze does, so callers can place
+// StartWord/EndWord offsets against their own structure.
+func Words(text string) []string {
+	return splitWords(normalizeText(text))
+}
+
+// WordOffsets returns the byte offset in text at which each of Words(text)
+// starts, so word ranges can be placed back on the original text.
+func WordOffsets(text string) []int {
+	locs := rawWordFinder.FindAllStringIndex(text, -1)
+	out := make([]int, len(locs))
+	for i, loc := range locs {
+		out[i] = loc[0]
+	}
+	return out
+}
+
 func splitWords(text string) []string {
 	return wordFinder.FindAllString(text, -1)
 }
@@ -646,7 +861,12 @@ func jaccard(a, b map[string]struct{}) float64 {
 	return float64(inter) / float64(union)
 }
 
-func windowDupSignal(i int, w wordWindow, windows []wordWindow, windowWords []string, paraDupMap map[string][]paragraphLoc, shingleSets []map[string]struct{}, nearDupThreshold float64, windowSize int) (float64, []Evidence, int) {
+// windowDupSignal scores window i of windowCount for exact paragraph
+// duplication and its closest non-adjacent window. masked counts the words of
+// a range that are intentional repetition; similarity estimates the shingle
+// Jaccard similarity of two windows; pairDiff, when set, diffs two windows
+// for the near-duplication evidence.
+func windowDupSignal(i int, w wordWindow, windowCount int, paraDupMap map[string][]paragraphLoc, masked func(start, end int) int, similarity func(i, j int) float64, pairDiff func(i, j int) *PairDiff, nearDupThreshold float64, windowSize int) (float64, []Evidence, int) {
 	evidence := []Evidence{}
 	dupParaCount := 0
 	longestDupWords := 0
@@ -657,6 +877,9 @@ func windowDupSignal(i int, w wordWindow, windows []wordWindow, windowWords []st
 			continue
 		}
 		for _, loc := range locs {
+			if masked(loc.Start, loc.End)*2 > loc.End-loc.Start {
+				continue
+			}
 			if rangesOverlap(w.Start, w.End, loc.Start, loc.End) {
 				dupParaCount++
 				spanStart := maxInt(w.Start, loc.Start)
@@ -677,11 +900,11 @@ func windowDupSignal(i int, w wordWindow, windows []wordWindow, windowWords []st
 
 	maxJac := 0.0
 	maxJacWindow := -1
-	for j := range windows {
+	for j := 0; j < windowCount; j++ {
 		if i == j || absInt(i-j) < 2 {
 			continue
 		}
-		jac := jaccard(shingleSets[i], shingleSets[j])
+		jac := similarity(i, j)
 		if jac > maxJac {
 			maxJac = jac
 			maxJacWindow = j
@@ -692,11 +915,15 @@ func windowDupSignal(i int, w wordWindow, windows []wordWindow, windowWords []st
 		if approxSpan > longestDupWords {
 			longestDupWords = approxSpan
 		}
-		evidence = append(evidence, Evidence{
+		ev := Evidence{
 			Type:    "duplication",
 			Summary: fmt.Sprintf("near-duplication with %s (jaccard=%.2f)", windowID(maxJacWindow), maxJac),
 			Spans:   []EvidenceSpan{{Start: w.Start, End: minInt(w.End, w.Start+maxInt(1, approxSpan))}},
-		})
+		}
+		if pairDiff != nil && maxJacWindow >= 0 {
+			ev.Diff = pairDiff(i, maxJacWindow)
+		}
+		evidence = append(evidence, ev)
 	}
 
 	dupScore := clamp01(0.25*math.Min(1.0, float64(dupParaCount)/3.0) + 0.75*clamp01(maxJac/0.35))
@@ -732,13 +959,13 @@ func styleUniformityScore(windowText string) float64 {
 	return clamp01(0.55*a + 0.20*b + 0.25*c)
 }
 
-func polishClicheScore(words []string, windowText string) float64 {
+func polishClicheScore(words []string, windowText string, lexicon map[string]struct{}) float64 {
 	if len(words) == 0 {
 		return 0
 	}
 	intensifiers := 0
 	for _, w := range words {
-		if _, ok := intensifierLexicon[w]; ok {
+		if _, ok := lexicon[w]; ok {
 			intensifiers++
 		}
 	}
@@ -806,6 +1033,16 @@ func topEvidence(in []Evidence, limit int) []Evidence {
 	return in[:limit]
 }
 
+// mostlyExempt reports whether more than half of the window's words fall in
+// exempt spans.
+func mostlyExempt(w wordWindow, spans []EvidenceSpan) bool {
+	covered := 0
+	for _, span := range spans {
+		covered += maxInt(0, minInt(w.End, span.End)-maxInt(w.Start, span.Start))
+	}
+	return covered*2 > w.End-w.Start
+}
+
 func hasDupFlag(windows []WindowReport) bool {
 	for _, w := range windows {
 		if len(w.Signals.Duplication.Evidence) > 0 {
@@ -960,6 +1197,29 @@ var intensifierLexicon = map[string]struct{}{
 	"terrifying": {}, "chilling": {}, "unmistakable": {}, "frantic": {}, "desperate": {}, "inevitable": {}, "unforgiving": {},
 }
 
+// DefaultIntensifiers returns the built-in intensifier lexicon, sorted.
+func DefaultIntensifiers() []string {
+	out := make([]string, 0, len(intensifierLexicon))
+	for w := range intensifierLexicon {
+		out = append(out, w)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// intensifierSet is the lexicon of custom, or the built-in one when custom
+// is nil.
+func intensifierSet(custom []string) map[string]struct{} {
+	if custom == nil {
+		return intensifierLexicon
+	}
+	set := make(map[string]struct{}, len(custom))
+	for _, w := range custom {
+		set[strings.ToLower(strings.TrimSpace(w))] = struct{}{}
+	}
+	return set
+}
+
 var stockFramePatterns = []*regexp.Regexp{
 	regexp.MustCompile(`\bthe unmistakable\b`),
 	regexp.MustCompile(`\bthe final\b`),
@@ -968,3 +1228,9 @@ var stockFramePatterns = []*regexp.Regexp{
 	regexp.MustCompile(`\ba data point\b`),
 	regexp.MustCompile(`\bthe protocol\b`),
 }
+
+// cancelledError marks a report whose run was cancelled before every model
+// call was made.
+func cancelledError(err error) ErrorEntry {
+	return ErrorEntry{Stage: "run_cancelled", Message: err.Error(), Type: "cancelled", Retryable: true}
+}
@@ -3,6 +3,8 @@ package aidetect
 import (
 	"context"
 	"errors"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -141,7 +143,7 @@ func TestAnalyzeLanguageToolFailureStillProducesOutput(t *testing.T) {
 }
 
 func TestAnalyzeLMFailureRedistributesWeights(t *testing.T) {
-	w := signalWeights(false)
+	w := WeightingForName(WeightingBalanced).weights(false)
 	if w.Duplication != 0.50 || w.StyleUniform != 0.30 || w.PolishCliche != 0.15 || w.LMSmoothness != 0.0 {
 		t.Fatalf("unexpected redistributed weights: %+v", w)
 	}
@@ -182,3 +184,332 @@ func TestDocumentProbabilityDoesNotSaturateOnModerateSignals(t *testing.T) {
 		t.Fatalf("unexpected doc saturation: p_ai_doc=%.3f p_ai_max=%.3f", *report.PAIDoc, *report.PAIMax)
 	}
 }
+
+func TestGenreCalibrationLowersPolishedProseRisk(t *testing.T) {
+	parts := make([]string, 0, 300)
+	for i := 0; i < 300; i++ {
+		parts = append(parts, "Her heart ached with tender longing as light number "+strconv.Itoa(i*7%389)+" settled across garden "+strconv.Itoa(i)+".")
+	}
+	text := strings.Join(parts, " ")
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	neutral := Analyze(Input{DocumentID: "neutral", Text: text, Language: "en"}, cfg, nil, nil, nil)
+
+	cfg.Calibration = CalibrationForGenre("Romance")
+	romance := Analyze(Input{DocumentID: "romance", Text: text, Language: "en"}, cfg, nil, nil, nil)
+
+	if romance.Calibration.Profile != "romance" || neutral.Calibration.Profile != "neutral" {
+		t.Fatalf("expected calibration profiles on reports, got %q and %q", neutral.Calibration.Profile, romance.Calibration.Profile)
+	}
+	if len(neutral.Windows) == 0 || len(romance.Windows) != len(neutral.Windows) {
+		t.Fatalf("expected matching windows, got %d and %d", len(neutral.Windows), len(romance.Windows))
+	}
+	if romance.Windows[0].PAI >= neutral.Windows[0].PAI {
+		t.Fatalf("expected romance calibration to lower window p_ai, got %.3f >= %.3f", romance.Windows[0].PAI, neutral.Windows[0].PAI)
+	}
+	if CalibrationForGenre("Sci-Fi").Profile != "neutral" {
+		t.Fatal("expected uncalibrated genre to fall back to neutral")
+	}
+}
+
+func TestSensitivityPresetsOrderWindowProbability(t *testing.T) {
+	parts := make([]string, 0, 300)
+	for i := 0; i < 300; i++ {
+		parts = append(parts, "The quiet harbor shimmered as lantern "+strconv.Itoa(i*7%389)+" swayed above dock "+strconv.Itoa(i)+".")
+	}
+	text := strings.Join(parts, " ")
+	scores := map[string]float64{}
+	for _, preset := range []string{SensitivityConservative, SensitivityBalanced, SensitivityAggressive} {
+		cfg := ConfigForSensitivity(preset)
+		cfg.EnableLanguageTool = false
+		report := Analyze(Input{DocumentID: preset, Text: text, Language: "en"}, cfg, nil, nil, nil)
+		if report.Sensitivity != preset || len(report.Windows) == 0 {
+			t.Fatalf("expected %s recorded with windows, got %q (%d windows)", preset, report.Sensitivity, len(report.Windows))
+		}
+		scores[preset] = report.Windows[0].PAI
+	}
+	if !(scores[SensitivityConservative] < scores[SensitivityBalanced] && scores[SensitivityBalanced] < scores[SensitivityAggressive]) {
+		t.Fatalf("expected p_ai to rise with sensitivity, got %v", scores)
+	}
+	if NormalizeSensitivity(" Aggressive ") != SensitivityAggressive || NormalizeSensitivity("paranoid") != SensitivityBalanced {
+		t.Fatal("expected preset names normalized with balanced as the fallback")
+	}
+	if DefaultConfig().Sensitivity != SensitivityBalanced {
+		t.Fatal("expected the default config to be balanced")
+	}
+}
+
+func TestWeightingProfilesRecordedAndApplied(t *testing.T) {
+	parts := make([]string, 0, 300)
+	for i := 0; i < 300; i++ {
+		parts = append(parts, "The quiet harbor shimmered as lantern "+strconv.Itoa(i*7%389)+" swayed above dock "+strconv.Itoa(i)+".")
+	}
+	text := strings.Join(parts, " ")
+	scores := map[string]float64{}
+	for _, name := range []string{WeightingDuplicationOnly, WeightingBalanced, WeightingStrict} {
+		cfg := DefaultConfig()
+		cfg.EnableLanguageTool = false
+		cfg.Weighting = WeightingForName(name)
+		report := Analyze(Input{DocumentID: name, Text: text, Language: "en"}, cfg, nil, nil, nil)
+		if report.Weighting.Name != name {
+			t.Fatalf("expected %s recorded, got %q", name, report.Weighting.Name)
+		}
+		scores[name] = report.Windows[0].PAI
+	}
+	if !(scores[WeightingDuplicationOnly] < scores[WeightingBalanced] && scores[WeightingBalanced] < scores[WeightingStrict]) {
+		t.Fatalf("expected p_ai to rise from duplication-only to strict, got %v", scores)
+	}
+
+	custom, err := ParseWeightingProfile([]byte(`{"name":"house","with_lm":{"duplication":0.5,"lm_smoothness":0.5},"without_lm":{"duplication":1}}`))
+	if err != nil || custom.Name != "house" || custom.WithoutLM.Duplication != 1 {
+		t.Fatalf("expected the custom profile parsed, got %+v (%v)", custom, err)
+	}
+	if _, err := ParseWeightingProfile([]byte(`{"name":"bad","with_lm":{"duplication":-1},"without_lm":{"duplication":1}}`)); err == nil {
+		t.Fatal("expected a negative weight rejected")
+	}
+	if got := Analyze(Input{DocumentID: "unset", Text: text, Language: "en"}, Config{}, nil, nil, nil).Weighting.Name; got != WeightingBalanced {
+		t.Fatalf("expected an unset profile to report balanced, got %q", got)
+	}
+}
+
+func TestExemptSpansLeaveDocumentAggregate(t *testing.T) {
+	para := strings.TrimSpace(strings.Repeat("Shadows gathered along the quay while the lamplighter counted his keys twice. ", 8))
+	text := strings.Repeat(para+"\n\n", 30)
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 200
+	cfg.StrideWords = 200
+	base := Analyze(Input{DocumentID: "base", Text: text, Language: "en"}, cfg, nil, nil, nil)
+	if base.ExemptWindows != 0 || base.PAIDoc == nil {
+		t.Fatalf("expected no exemptions by default, got %d", base.ExemptWindows)
+	}
+
+	exempt := Analyze(Input{DocumentID: "exempt", Text: text, Language: "en", ExemptSpans: []EvidenceSpan{{Start: 0, End: base.WordCount}}}, cfg, nil, nil, nil)
+	if exempt.ExemptWindows != len(exempt.Windows) || *exempt.PAIDoc != 0 || *exempt.AICoverageEst != 0 || len(exempt.Flags) != 0 {
+		t.Fatalf("expected a fully exempt document to aggregate to zero, got %+v", exempt)
+	}
+	if exempt.Windows[0].PAI != base.Windows[0].PAI || exempt.Windows[0].Signals.Duplication.Score == nil {
+		t.Fatal("expected exempt windows to keep their raw scores and signals")
+	}
+
+	half := Analyze(Input{DocumentID: "half", Text: text, Language: "en", ExemptSpans: []EvidenceSpan{{Start: 0, End: 200}}}, cfg, nil, nil, nil)
+	if half.ExemptWindows != 1 || !half.Windows[0].Exempt || half.Windows[1].Exempt {
+		t.Fatalf("expected only the first window exempt, got %d", half.ExemptWindows)
+	}
+}
+
+func TestIntentionalRepetitionSkipsDuplicationOverride(t *testing.T) {
+	prose := func(from, n int) string {
+		lines := make([]string, 0, n)
+		for i := from; i < from+n; i++ {
+			lines = append(lines, "Marker "+strconv.Itoa(i)+" passed bench "+strconv.Itoa(i*7)+" near gate "+strconv.Itoa(i*13)+".")
+		}
+		return strings.Join(lines, "\n")
+	}
+	letterLines := make([]string, 0, 30)
+	for i := 0; i < 30; i++ {
+		letterLines = append(letterLines, "I kept lamp "+strconv.Itoa(i)+" lit by window "+strconv.Itoa(i*3)+" because you asked and the winter has been long without you.")
+	}
+	body := strings.Join(letterLines, "\n")
+	build := func(letter string) string {
+		return strings.Join([]string{prose(0, 60), letter, prose(100, 60), letter, prose(200, 60)}, "\n")
+	}
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 300
+	cfg.StrideWords = 150
+
+	maxDup := func(report Report) (float64, bool) {
+		top, reasoned := 0.0, false
+		for _, w := range report.Windows {
+			top = maxFloat(top, deref(w.Signals.Duplication.Score))
+			for _, e := range w.TopEvidence {
+				if e.Type == "intentional_repetition" && strings.Contains(e.Summary, RepetitionLetter) {
+					reasoned = true
+				}
+			}
+		}
+		return top, reasoned
+	}
+	stitched := Analyze(Input{DocumentID: "stitched", Text: build(body), Language: "en"}, cfg, nil, nil, nil)
+	letters := Analyze(Input{DocumentID: "letters", Text: build("Dear Mara,\n" + body + "\nYours always,\nTomas"), Language: "en"}, cfg, nil, nil, nil)
+
+	stitchedDup, _ := maxDup(stitched)
+	letterDup, reasoned := maxDup(letters)
+	if !reasoned {
+		t.Fatal("expected evidence explaining the repeated letter")
+	}
+	if letterDup >= stitchedDup {
+		t.Fatalf("expected the repeated letter to score lower duplication, got %.2f >= %.2f", letterDup, stitchedDup)
+	}
+	if *stitched.PAIMax < 0.90 || *letters.PAIMax >= 0.90 {
+		t.Fatalf("expected the long-duplicate override only on the stitched copy, got p_ai_max %.2f and %.2f", *stitched.PAIMax, *letters.PAIMax)
+	}
+}
+
+func TestFindIntentionalRepetitionMergesChorusLines(t *testing.T) {
+	chorus := "Row the boat to morning\nRow it past the light"
+	text := "Verse one opens here\nand wanders off\n" + chorus + "\nA long paragraph of ordinary prose that is certainly longer than any verse line would be.\nVerse two begins\nand drifts away\n" + chorus
+	spans := findIntentionalRepetition(text)
+	if len(spans) != 2 || spans[0].Kind != RepetitionVerse || spans[0].End-spans[0].Start != 10 || spans[0].Occurrences != 2 {
+		t.Fatalf("expected both choruses as single verse spans, got %+v", spans)
+	}
+}
+
+func TestExclusionsLeaveMatchedTextOutOfDuplication(t *testing.T) {
+	prose := func(from, n int) string {
+		lines := make([]string, 0, n)
+		for i := from; i < from+n; i++ {
+			lines = append(lines, "Marker "+strconv.Itoa(i)+" passed bench "+strconv.Itoa(i*7)+" near gate "+strconv.Itoa(i*13)+".")
+		}
+		return strings.Join(lines, "\n")
+	}
+	lyricLines := make([]string, 0, 30)
+	for i := 0; i < 30; i++ {
+		lyricLines = append(lyricLines, "We sang of harbor "+strconv.Itoa(i)+" and the long road "+strconv.Itoa(i*3)+" that carried every sailor home before the winter came.")
+	}
+	lyric := strings.Join(lyricLines, "\n")
+	text := strings.Join([]string{prose(0, 60), lyric, prose(100, 60), lyric, prose(200, 60)}, "\n")
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 300
+	cfg.StrideWords = 150
+
+	plain := Analyze(Input{DocumentID: "plain", Text: text, Language: "en"}, cfg, nil, nil, nil)
+	cfg.Exclusions = []*regexp.Regexp{regexp.MustCompile(`(?m)^We sang of harbor \d+ .*winter came\.$`)}
+	excluded := Analyze(Input{DocumentID: "excluded", Text: text, Language: "en"}, cfg, nil, nil, nil)
+	if *plain.PAIMax < 0.90 || *excluded.PAIMax >= 0.90 {
+		t.Fatalf("expected the ignore list to lift the long-duplicate override, got p_ai_max %.2f and %.2f", *plain.PAIMax, *excluded.PAIMax)
+	}
+	reasoned := false
+	for _, w := range excluded.Windows {
+		for _, e := range w.TopEvidence {
+			reasoned = reasoned || (e.Type == "intentional_repetition" && strings.Contains(e.Summary, "ignore list"))
+		}
+	}
+	if !reasoned {
+		t.Fatal("expected evidence naming the ignore list")
+	}
+
+	a := NewAnalyzer(Input{DocumentID: "stream", Language: "en"}, cfg, nil, nil, nil)
+	for _, chunk := range strings.Split(text, "\n") {
+		a.AddChunk(chunk)
+	}
+	if streamed := a.Finalize(); *streamed.PAIMax >= 0.90 {
+		t.Fatalf("expected the streamed run to honor the ignore list, got p_ai_max %.2f", *streamed.PAIMax)
+	}
+}
+
+func TestExcludedSpansUseNormalizedWordOffsets(t *testing.T) {
+	text := "It's late. \"Sing, O Muse,\" she said — and we sang.\nSing, O Muse!"
+	spans := excludedSpans(text, []*regexp.Regexp{regexp.MustCompile(`Sing, O Mu`)}, 5)
+	words := Words(text)
+	if len(spans) != 2 || spans[0].Occurrences != 2 || spans[0].Kind != RepetitionExcluded {
+		t.Fatalf("expected both matches as excluded spans, got %+v", spans)
+	}
+	for _, s := range spans {
+		if got := strings.Join(words[s.Start-5:s.End-5], " "); got != "sing o muse" {
+			t.Fatalf("expected span %d-%d to cover the whole match, got %q", s.Start, s.End, got)
+		}
+	}
+}
+
+func TestWordOffsetsMatchWords(t *testing.T) {
+	text := "It's late — “Café,” she said.\r\nRoom 101!"
+	words, offsets := Words(text), WordOffsets(text)
+	if len(offsets) != len(words) {
+		t.Fatalf("expected one offset per word, got %d offsets for %q", len(offsets), words)
+	}
+	for i, w := range words {
+		if got := strings.ToLower(text[offsets[i] : offsets[i]+len(w)]); got != w {
+			t.Fatalf("word %d: offset %d points at %q, want %q", i, offsets[i], got, w)
+		}
+	}
+}
+
+func TestRescoreReproducesAndReweighsWindows(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("the sterile corridor hummed with certainty and fear ", 48))
+	text := strings.Join([]string{paragraph, "chapter break", paragraph, strings.Repeat("Rain fell on a quiet harbor where boats rocked. ", 120)}, "\n\n")
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	report := Analyze(Input{DocumentID: "rescore", Text: text, Language: "en"}, cfg, nil, stubLM{score: 0.4}, nil)
+	AggregateChapters(&report, []ChapterSpan{{Chapter: 1, StartWord: 0, EndWord: report.WordCount}})
+	inputs := report.Inputs()
+
+	same := report
+	same.Windows = append([]WindowReport(nil), report.Windows...)
+	Rescore(&same, inputs, cfg)
+	if *same.PAIDoc != *report.PAIDoc || len(same.Flags) != len(report.Flags) {
+		t.Fatalf("expected the same config to reproduce p_ai_doc %.3f and flags %v, got %.3f %v", *report.PAIDoc, report.Flags, *same.PAIDoc, same.Flags)
+	}
+	for i := range same.Windows {
+		if same.Windows[i].PAI != report.Windows[i].PAI || same.Windows[i].Confidence != report.Windows[i].Confidence {
+			t.Fatalf("window %d rescored differently: %+v vs %+v", i, same.Windows[i], report.Windows[i])
+		}
+	}
+
+	lenient := cfg
+	lenient.Bias -= 2
+	lenient.DupOverrideMinWords = 100000
+	Rescore(&same, inputs, lenient)
+	if *same.PAIDoc >= *report.PAIDoc || *same.PAIPerChapter[0].PAI >= *report.PAIPerChapter[0].PAI {
+		t.Fatalf("expected a lower bias to lower document and chapter scores, got %.3f from %.3f", *same.PAIDoc, *report.PAIDoc)
+	}
+	for _, w := range same.Windows {
+		for _, e := range w.TopEvidence {
+			if e.Summary == "long duplicate span" {
+				t.Fatalf("expected the long duplicate override dropped with its threshold raised, got %+v", w.TopEvidence)
+			}
+		}
+	}
+}
+
+func TestPolishScoreUsesCustomIntensifiers(t *testing.T) {
+	text := "the gunmetal sky was very grey and the gunmetal sea was cold " + strings.Repeat("the boat went out past the harbor wall ", 10)
+	words := splitWords(text)
+	builtIn := polishClicheScore(words, text, intensifierSet(nil))
+	custom := polishClicheScore(words, text, intensifierSet([]string{"Gunmetal", "very"}))
+	none := polishClicheScore(words, text, intensifierSet([]string{}))
+	if !(custom > builtIn && builtIn > none) {
+		t.Fatalf("expected polish to follow the lexicon: custom=%.3f built-in=%.3f none=%.3f", custom, builtIn, none)
+	}
+	if !slices.Contains(DefaultIntensifiers(), "very") {
+		t.Fatalf("unexpected default intensifiers %v", DefaultIntensifiers())
+	}
+}
+
+type countingScorer struct{ calls *int }
+
+func (s countingScorer) ScoreWindow(ctx context.Context, _ string) (float64, error) {
+	*s.calls++
+	return 0.5, ctx.Err()
+}
+
+func TestCancelledRunMakesNoModelCalls(t *testing.T) {
+	text := strings.TrimSpace(strings.Repeat("The lantern swung above the quiet harbor as the boats came in. ", 400))
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = true
+	cfg.EnableLMSmoothness = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	scorer := countingScorer{calls: &calls}
+	report := AnalyzeContext(ctx, Input{DocumentID: "cancelled", Text: text, Language: "en"}, cfg, scorer, scorer, nil)
+	a := NewAnalyzerContext(ctx, Input{DocumentID: "cancelled-stream", Text: text, Language: "en"}, cfg, scorer, scorer, nil)
+	streamed := a.Finalize()
+	if calls != 0 {
+		t.Fatalf("expected no scorer calls after cancellation, got %d", calls)
+	}
+	for _, r := range []Report{report, streamed} {
+		if len(r.Windows) == 0 || !slices.ContainsFunc(r.Errors, func(e ErrorEntry) bool { return e.Stage == "run_cancelled" }) {
+			t.Fatalf("expected scored windows and a run_cancelled error, got %d windows and %+v", len(r.Windows), r.Errors)
+		}
+	}
+
+	calls = 0
+	Analyze(Input{DocumentID: "live", Text: text, Language: "en"}, cfg, scorer, scorer, nil)
+	if calls == 0 {
+		t.Fatal("expected a live run to call the scorers")
+	}
+}
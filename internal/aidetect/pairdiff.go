@@ -0,0 +1,130 @@
+package aidetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Diff operations: words both passages share, a short change in the same
+// place (a lightly paraphrased phrase), or words only one passage has.
+const (
+	DiffIdentical   = "identical"
+	DiffParaphrased = "paraphrased"
+	DiffRemoved     = "removed"
+	DiffAdded       = "added"
+)
+
+// maxParaphraseWords is the longest change, on either side, still counted as
+// a light paraphrase; longer changes are reported as removed and added text.
+const maxParaphraseWords = 4
+
+// DiffOp is one run of a word diff. Words are the first passage's words;
+// OtherWords are the second's, and are left empty for identical runs.
+type DiffOp struct {
+	Op         string   `json:"op"`
+	Words      []string `json:"words,omitempty"`
+	OtherWords []string `json:"other_words,omitempty"`
+}
+
+// PairDiff compares a near-duplicated window word by word with the window it
+// duplicates, so a reviewer can tell stitched-in copies (long identical runs
+// with the odd word swapped) from a recap that retells the same events in
+// new sentences.
+type PairDiff struct {
+	WindowID string       `json:"window_id"`
+	Other    EvidenceSpan `json:"other"`
+	// IdenticalWords and ParaphrasedWords count this window's words in
+	// identical and paraphrased runs.
+	IdenticalWords   int      `json:"identical_words"`
+	ParaphrasedWords int      `json:"paraphrased_words"`
+	Ops              []DiffOp `json:"ops"`
+}
+
+// windowPairDiff diffs window w against window j over the normalized words.
+func windowPairDiff(words []string, w wordWindow, j int, other wordWindow) *PairDiff {
+	d := &PairDiff{
+		WindowID: windowID(j),
+		Other:    EvidenceSpan{Start: other.Start, End: other.End},
+		Ops:      DiffWords(words[w.Start:w.End], words[other.Start:other.End]),
+	}
+	for _, op := range d.Ops {
+		switch op.Op {
+		case DiffIdentical:
+			d.IdenticalWords += len(op.Words)
+		case DiffParaphrased:
+			d.ParaphrasedWords += len(op.Words)
+		}
+	}
+	return d
+}
+
+// DiffWords aligns a and b on their longest common subsequence of words,
+// ignoring case and surrounding punctuation. A change of up to
+// maxParaphraseWords words on each side between two shared runs is reported
+// as paraphrased; other unshared words are removed (only in a) or added
+// (only in b). Identical runs carry a's spelling.
+func DiffWords(a, b []string) []DiffOp {
+	key := func(w string) string {
+		return strings.ToLower(strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+	}
+	ka := make([]string, len(a))
+	for i, w := range a {
+		ka[i] = key(w)
+	}
+	kb := make([]string, len(b))
+	for j, w := range b {
+		kb[j] = key(w)
+	}
+	// lcs[i*cols+j] is the common subsequence length of a[i:] and b[j:].
+	cols := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*cols)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if ka[i] == kb[j] {
+				lcs[i*cols+j] = lcs[(i+1)*cols+j+1] + 1
+			} else {
+				lcs[i*cols+j] = max(lcs[(i+1)*cols+j], lcs[i*cols+j+1])
+			}
+		}
+	}
+
+	out := []DiffOp{}
+	var removed, added []string
+	flush := func() {
+		switch {
+		case len(removed) == 0 && len(added) == 0:
+		case len(removed) > 0 && len(added) > 0 && len(removed) <= maxParaphraseWords && len(added) <= maxParaphraseWords:
+			out = append(out, DiffOp{Op: DiffParaphrased, Words: removed, OtherWords: added})
+		default:
+			if len(removed) > 0 {
+				out = append(out, DiffOp{Op: DiffRemoved, Words: removed})
+			}
+			if len(added) > 0 {
+				out = append(out, DiffOp{Op: DiffAdded, OtherWords: added})
+			}
+		}
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && ka[i] == kb[j]:
+			flush()
+			if n := len(out); n > 0 && out[n-1].Op == DiffIdentical {
+				out[n-1].Words = append(out[n-1].Words, a[i])
+			} else {
+				out = append(out, DiffOp{Op: DiffIdentical, Words: []string{a[i]}})
+			}
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[(i+1)*cols+j] >= lcs[i*cols+j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+	return out
+}
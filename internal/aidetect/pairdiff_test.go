@@ -0,0 +1,72 @@
+package aidetect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffWordsMarksParaphrasedAndChangedWords(t *testing.T) {
+	ops := DiffWords(
+		strings.Fields("The storm broke, loud and sudden. She ran for the boats while the harbour bells rang out over the water"),
+		strings.Fields("the storm broke quiet and sudden she ran for the boats"),
+	)
+	var got []string
+	for _, op := range ops {
+		got = append(got, op.Op+":"+strings.Join(op.Words, " ")+"/"+strings.Join(op.OtherWords, " "))
+	}
+	want := "identical:The storm broke,/|paraphrased:loud/quiet|identical:and sudden. She ran for the boats/|removed:while the harbour bells rang out over the water/"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("expected %s, got %s", want, strings.Join(got, "|"))
+	}
+}
+
+func TestNearDuplicationEvidenceCarriesPairDiff(t *testing.T) {
+	// Letters-only tokens, so normalization leaves every word distinct.
+	word := func(n int) string {
+		return "w" + string(rune('a'+n/26%26)) + string(rune('a'+n%26))
+	}
+	passage := func(from, n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = word(from + i)
+		}
+		return out
+	}
+	original := passage(0, 40)
+	copied := append([]string{}, original...)
+	copied[10], copied[30] = "suddenly", "quietly"
+	text := strings.Join(original, " ") + "\n\n" + strings.Join(passage(100, 120), " ") + "\n\n" + strings.Join(copied, " ")
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 40
+	cfg.StrideWords = 40
+	report := Analyze(Input{DocumentID: "pair-doc", Text: text, Language: "en"}, cfg, nil, nil, nil)
+
+	var diff *PairDiff
+	for _, e := range report.Windows[0].Signals.Duplication.Evidence {
+		if strings.HasPrefix(e.Summary, "near-duplication") {
+			diff = e.Diff
+		}
+	}
+	if diff == nil {
+		t.Fatalf("expected near-duplication evidence with a diff, got %+v", report.Windows[0].Signals.Duplication.Evidence)
+	}
+	if diff.WindowID != "w-004" || diff.Other != (EvidenceSpan{Start: 160, End: 200}) {
+		t.Fatalf("expected the diff against the copied window, got %s %+v", diff.WindowID, diff.Other)
+	}
+	if diff.IdenticalWords != 38 || diff.ParaphrasedWords != 2 {
+		t.Fatalf("expected 38 identical and 2 paraphrased words, got %d and %d", diff.IdenticalWords, diff.ParaphrasedWords)
+	}
+	if diff.Ops[1].Op != DiffParaphrased || diff.Ops[1].OtherWords[0] != "suddenly" {
+		t.Fatalf("expected the swapped word shown as a paraphrase, got %+v", diff.Ops[1])
+	}
+
+	stream := NewAnalyzer(Input{DocumentID: "pair-doc", Text: text, Language: "en"}, cfg, nil, nil, nil).Finalize()
+	for _, w := range stream.Windows {
+		for _, e := range w.Signals.Duplication.Evidence {
+			if e.Diff != nil {
+				t.Fatalf("expected no diffs from the streaming analyzer, got %+v", e)
+			}
+		}
+	}
+}
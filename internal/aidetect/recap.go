@@ -0,0 +1,116 @@
+package aidetect
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RepetitionRecap marks a passage that retells earlier chapters, as serial
+// and multi-POV novels do at the start of an installment or a returning
+// viewpoint. Its overlap with the passages it retells is a recap, not
+// stitching: it is left out of duplication scoring and reported as
+// recap_duplication evidence in DuplicationSignal.Recaps.
+const RepetitionRecap = "recap"
+
+const (
+	// minRecapWords drops framed lines too short to duplicate a window.
+	minRecapWords = 20
+	// maxRecapWords bounds how far a recap runs on past its framing line.
+	maxRecapWords = 600
+	// recapPastPerfect is the share of sentences using the past perfect
+	// ("had gone") that marks a line as summary of earlier events.
+	recapPastPerfect = 0.5
+)
+
+var (
+	// recapOpenPattern is framing that opens a recap on its own.
+	recapOpenPattern = regexp.MustCompile(`(?i)^\W*(previously|last time|the story so far|so far|to recap|when (we|you) last (saw|left)|in (the|our) (last|previous) (chapter|installment|episode|book))\b`)
+	// recapPhrasePattern is framing that marks a recap only in a line that
+	// also summarizes in the past perfect.
+	recapPhrasePattern = regexp.MustCompile(`(?i)\b(as (we|you) (saw|learned|know)|as (mentioned|described) (earlier|before)|(earlier|back) in (the|this) (story|book)|in an earlier chapter|since the events of|after everything that had happened|to summari[sz]e)\b`)
+	sentenceEndPattern = regexp.MustCompile(`[.!?]+`)
+)
+
+// recapSpans returns the recap passages of text, in word offsets starting at
+// offset. A recap starts at a line opening with recap framing ("Previously,"
+// "Last time") or at a line with recap phrasing that summarizes in the past
+// perfect, and runs on through the past-perfect summary lines after it.
+func recapSpans(text string, offset int) []intentionalSpan {
+	lines := textLines(text, offset)
+	out := []intentionalSpan{}
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if !recapOpenPattern.MatchString(l.raw) && !(recapPhrasePattern.MatchString(l.raw) && pastPerfectShare(l) >= recapPastPerfect) {
+			continue
+		}
+		end := l.end
+		for i+1 < len(lines) && lines[i+1].end-l.start <= maxRecapWords && pastPerfectShare(lines[i+1]) >= recapPastPerfect {
+			i++
+			end = lines[i].end
+		}
+		if end-l.start >= minRecapWords {
+			out = append(out, intentionalSpan{Start: l.start, End: end, Kind: RepetitionRecap, Occurrences: 1})
+		}
+	}
+	return out
+}
+
+// pastPerfectShare is the share of a line's sentences with "had" before
+// another verb, a rough mark of narration summarizing what already happened.
+func pastPerfectShare(l textLine) float64 {
+	sentences, hits := 0, 0
+	for _, s := range sentenceEndPattern.Split(l.raw, -1) {
+		words := splitWords(normalizeText(s))
+		if len(words) == 0 {
+			continue
+		}
+		sentences++
+		for k := 0; k+1 < len(words); k++ {
+			if words[k] == "had" && words[k+1] != "to" {
+				hits++
+				break
+			}
+		}
+	}
+	if sentences == 0 {
+		return 0
+	}
+	return float64(hits) / float64(sentences)
+}
+
+// recapDupEvidence classifies the near-duplication a window's recap text
+// causes. withRecap compares windows with recap text left in and similarity
+// with it left out; when only the first reaches threshold the overlap comes
+// from the recap, and the evidence names the window it retells.
+func recapDupEvidence(i int, w wordWindow, windowCount int, recaps []intentionalSpan, withRecap, similarity func(i, j int) float64, pairDiff func(i, j int) *PairDiff, threshold float64) []Evidence {
+	spans := []EvidenceSpan{}
+	for _, r := range recaps {
+		if rangesOverlap(w.Start, w.End, r.Start, r.End) {
+			spans = append(spans, EvidenceSpan{Start: maxInt(w.Start, r.Start), End: minInt(w.End, r.End)})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	best, bestWindow := 0.0, -1
+	for j := 0; j < windowCount; j++ {
+		if i == j || absInt(i-j) < 2 {
+			continue
+		}
+		if sim := withRecap(i, j); sim > best {
+			best, bestWindow = sim, j
+		}
+	}
+	if bestWindow < 0 || best < threshold || similarity(i, bestWindow) >= threshold {
+		return nil
+	}
+	ev := Evidence{
+		Type:    "recap_duplication",
+		Summary: fmt.Sprintf("recap of %s (jaccard=%.2f); not counted as duplication", windowID(bestWindow), best),
+		Spans:   spans,
+	}
+	if pairDiff != nil {
+		ev.Diff = pairDiff(i, bestWindow)
+	}
+	return []Evidence{ev}
+}
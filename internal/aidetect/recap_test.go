@@ -0,0 +1,76 @@
+package aidetect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecapSpansFindFramedAndPastPerfectSummaries(t *testing.T) {
+	lines := []string{
+		"Chapter Nine",
+		"Previously, the crew of the Meridian had crossed the ice shelf, lost two sledges to the crevasse field and found the buried station.",
+		"Mara had opened the hatch. Joss had found the logbook. The radio had been dead for a decade.",
+		"The wind rose as she stepped outside. She pulled her hood tight and walked toward the dogs.",
+		"She had previously worked in a bakery and liked the smell of bread in the morning before the shop opened.",
+		"As we saw in the last storm, Joss had kept the maps dry. Mara had rationed the fuel. They had reached the coast alive.",
+	}
+	offsets := []int{100}
+	for _, l := range lines {
+		offsets = append(offsets, offsets[len(offsets)-1]+len(splitWords(normalizeText(l))))
+	}
+	spans := recapSpans(strings.Join(lines, "\n\n"), 100)
+	if len(spans) != 2 {
+		t.Fatalf("expected the framed recap and the past-perfect summary, got %+v", spans)
+	}
+	// The framed recap runs on through the past-perfect line after it and
+	// stops at present narration.
+	if spans[0].Start != offsets[1] || spans[0].End != offsets[3] || spans[0].Kind != RepetitionRecap {
+		t.Fatalf("unexpected recap span %+v, want %d-%d", spans[0], offsets[1], offsets[3])
+	}
+	if spans[1].Start != offsets[5] || spans[1].End != offsets[6] {
+		t.Fatalf("expected only the last line as the second recap, got %+v", spans[1])
+	}
+}
+
+func TestRecapDuplicationIsClassifiedApartFromStitching(t *testing.T) {
+	word := func(n int) string {
+		return "w" + string(rune('a'+n/26%26)) + string(rune('a'+n%26))
+	}
+	passage := func(from, n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = word(from + i)
+		}
+		return out
+	}
+	original := passage(0, 40)
+	build := func(framing string) string {
+		return strings.Join(original, " ") + "\n\n" + strings.Join(passage(100, 120), " ") + "\n\n" + framing + " " + strings.Join(original[:39], " ")
+	}
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 40
+	cfg.StrideWords = 40
+
+	stitched := Analyze(Input{DocumentID: "recap-doc", Text: build("meanwhile"), Language: "en"}, cfg, nil, nil, nil)
+	if len(stitched.Windows[4].Signals.Duplication.Evidence) == 0 || len(stitched.Windows[4].Signals.Duplication.Recaps) != 0 {
+		t.Fatalf("expected unframed repetition counted as duplication, got %+v", stitched.Windows[4].Signals.Duplication)
+	}
+
+	recap := Analyze(Input{DocumentID: "recap-doc", Text: build("Previously,"), Language: "en"}, cfg, nil, nil, nil)
+	dup := recap.Windows[4].Signals.Duplication
+	if len(dup.Evidence) != 0 || *dup.Score >= *stitched.Windows[4].Signals.Duplication.Score {
+		t.Fatalf("expected the recap left out of duplication scoring, got %+v", dup)
+	}
+	if len(dup.Recaps) != 1 || !strings.HasPrefix(dup.Recaps[0].Summary, "recap of w-000") || dup.Recaps[0].Diff == nil {
+		t.Fatalf("expected recap evidence naming the retold window, got %+v", dup.Recaps)
+	}
+	if len(recap.Windows[0].Signals.Duplication.Evidence) != 0 {
+		t.Fatalf("expected the retold passage not flagged either, got %+v", recap.Windows[0].Signals.Duplication.Evidence)
+	}
+
+	stream := NewAnalyzer(Input{DocumentID: "recap-doc", Text: build("Previously,"), Language: "en"}, cfg, nil, nil, nil).Finalize()
+	if got := stream.Windows[4].Signals.Duplication; len(got.Evidence) != 0 || len(got.Recaps) != 1 {
+		t.Fatalf("expected the streaming analyzer to classify the recap too, got %+v", got)
+	}
+}
@@ -0,0 +1,340 @@
+package aidetect
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Layouts that make a verbatim repeat deliberate rather than stitched.
+const (
+	RepetitionVerse    = "verse"
+	RepetitionLetter   = "letter"
+	RepetitionEpigraph = "epigraph"
+	// RepetitionExcluded marks text matched by Config.Exclusions, such as a
+	// quoted song or a known source the author cites.
+	RepetitionExcluded = "excluded"
+)
+
+const (
+	// verseLineWords is the longest line still read as verse or lyric layout.
+	verseLineWords = 10
+	// maxLetterLines bounds how far a salutation looks for its sign-off.
+	maxLetterLines = 40
+	// maxEpigraphWords bounds the quotation above an attribution line.
+	maxEpigraphWords = 80
+	// minRefrainWords drops repeated verse shorter than a duplication
+	// shingle, such as a book title under every chapter heading.
+	minRefrainWords = 8
+)
+
+var (
+	salutationPattern  = regexp.MustCompile(`(?i)^(my )?(dear(est)?|to whom it may concern)\b.*[,:]$`)
+	signOffPattern     = regexp.MustCompile(`(?i)^(yours|sincerely|love|all my love|with love|regards|best|faithfully|ever yours)\b[^.!?]{0,30}[,.]?$`)
+	attributionPattern = regexp.MustCompile(`^\s*[—–~-]+\s*\S`)
+)
+
+// intentionalSpan is one occurrence of a block repeated on purpose, in word
+// offsets of the normalized text.
+type intentionalSpan struct {
+	Start       int
+	End         int
+	Kind        string
+	Occurrences int
+}
+
+type textLine struct {
+	raw   string
+	start int
+	end   int
+	words []string
+}
+
+// repetitionBlock is a verse line, letter or epigraph that may recur; key
+// holds its kind and words.
+type repetitionBlock struct {
+	kind       string
+	start, end int
+	key        string
+}
+
+// findIntentionalRepetition returns every occurrence of a verse line, letter
+// or epigraph that appears verbatim more than once. Blocks are read
+// from the raw line layout, which normalization discards.
+func findIntentionalRepetition(text string) []intentionalSpan {
+	return intentionalSpans(repetitionBlocks(text, 0))
+}
+
+// repetitionBlocks lists the candidate blocks of text, with word offsets
+// starting at offset.
+func repetitionBlocks(text string, offset int) []repetitionBlock {
+	lines := textLines(text, offset)
+	blocks := []repetitionBlock{}
+	add := func(kind string, from, to int) {
+		words := []string{}
+		for _, l := range lines[from:to] {
+			words = append(words, l.words...)
+		}
+		blocks = append(blocks, repetitionBlock{kind: kind, start: lines[from].start, end: lines[to-1].end, key: kind + "|" + strings.Join(words, " ")})
+	}
+
+	for i := 0; i < len(lines); {
+		if to := letterEnd(lines, i); to > i {
+			add(RepetitionLetter, i, to)
+			i = to
+			continue
+		}
+		if to := epigraphEnd(lines, i); to > i {
+			add(RepetitionEpigraph, i, to)
+			i = to
+			continue
+		}
+		to := i
+		for to < len(lines) && len(lines[to].words) <= verseLineWords {
+			if to > i && (letterEnd(lines, to) > to || epigraphEnd(lines, to) > to) {
+				break
+			}
+			to++
+		}
+		if to-i >= 2 {
+			// Verse repeats line by line: a chorus recurs between
+			// different verses, so whole stanzas rarely match.
+			for k := i; k < to; k++ {
+				add(RepetitionVerse, k, k+1)
+			}
+			i = to
+			continue
+		}
+		i++
+	}
+	return blocks
+}
+
+// textLines splits text into its non-blank lines with their normalized words
+// and word offsets starting at offset.
+func textLines(text string, offset int) []textLine {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := []textLine{}
+	cursor := offset
+	for _, raw := range strings.Split(text, "\n") {
+		words := splitWords(normalizeText(raw))
+		if len(words) == 0 {
+			continue
+		}
+		lines = append(lines, textLine{raw: strings.TrimSpace(raw), start: cursor, end: cursor + len(words), words: words})
+		cursor += len(words)
+	}
+	return lines
+}
+
+// intentionalSpans keeps the blocks that occur more than once and merges
+// consecutive verse lines into refrains.
+func intentionalSpans(blocks []repetitionBlock) []intentionalSpan {
+	counts := map[string]int{}
+	for _, b := range blocks {
+		counts[b.key]++
+	}
+	out := []intentionalSpan{}
+	for _, b := range blocks {
+		if n := counts[b.key]; n >= 2 {
+			out = append(out, intentionalSpan{Start: b.start, End: b.end, Kind: b.kind, Occurrences: n})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	// Consecutive repeated verse lines read as one refrain.
+	merged := make([]intentionalSpan, 0, len(out))
+	for _, s := range out {
+		if n := len(merged); n > 0 && merged[n-1].Kind == s.Kind && merged[n-1].End == s.Start {
+			merged[n-1].End = s.End
+			merged[n-1].Occurrences = maxInt(merged[n-1].Occurrences, s.Occurrences)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	out = merged[:0]
+	for _, s := range merged {
+		if s.Kind != RepetitionVerse || s.End-s.Start >= minRefrainWords {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// excludedSpans returns the word ranges of text, starting at offset, matched
+// by patterns. A match that starts or ends inside a word takes in the whole
+// word.
+func excludedSpans(text string, patterns []*regexp.Regexp, offset int) []intentionalSpan {
+	type match struct{ start, end, pattern int }
+	matches := []match{}
+	for p, re := range patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				matches = append(matches, match{start: loc[0], end: loc[1], pattern: p})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	counts := map[int]int{}
+	bounds := []int{}
+	for k, m := range matches {
+		counts[m.pattern]++
+		for m.start > 0 && m.start < len(text) && isWordByte(text[m.start-1]) && isWordByte(text[m.start]) {
+			m.start--
+		}
+		for m.end < len(text) && isWordByte(text[m.end-1]) && isWordByte(text[m.end]) {
+			m.end++
+		}
+		matches[k] = m
+		bounds = append(bounds, m.start, m.end)
+	}
+	// No boundary falls inside a word, so the words between boundaries
+	// add up to the offsets normalization gives the whole text.
+	sort.Ints(bounds)
+	wordsBefore := map[int]int{}
+	cursor, words := 0, 0
+	for _, b := range bounds {
+		if _, ok := wordsBefore[b]; ok {
+			continue
+		}
+		words += len(splitWords(normalizeText(text[cursor:b])))
+		wordsBefore[b], cursor = words, b
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	out := make([]intentionalSpan, 0, len(matches))
+	for _, m := range matches {
+		if start, end := wordsBefore[m.start], wordsBefore[m.end]; end > start {
+			out = append(out, intentionalSpan{Start: offset + start, End: offset + end, Kind: RepetitionExcluded, Occurrences: counts[m.pattern]})
+		}
+	}
+	return out
+}
+
+// isWordByte reports whether b survives normalization as part of a word.
+func isWordByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// mergeSpans sorts spans and folds overlapping ones into the earlier span,
+// so the result never overlaps.
+func mergeSpans(spans []intentionalSpan) []intentionalSpan {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	out := make([]intentionalSpan, 0, len(spans))
+	for _, s := range spans {
+		if n := len(out); n > 0 && s.Start < out[n-1].End {
+			out[n-1].End = maxInt(out[n-1].End, s.End)
+			out[n-1].Occurrences = maxInt(out[n-1].Occurrences, s.Occurrences)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// letterEnd returns the line after the sign-off of a letter opening at i, or
+// i when line i is not a salutation with a sign-off in reach.
+func letterEnd(lines []textLine, i int) int {
+	if !salutationPattern.MatchString(lines[i].raw) {
+		return i
+	}
+	for j := i + 1; j < len(lines) && j <= i+maxLetterLines; j++ {
+		if signOffPattern.MatchString(lines[j].raw) {
+			// The signature usually follows on its own short line.
+			if j+1 < len(lines) && len(lines[j+1].words) <= 4 {
+				return j + 2
+			}
+			return j + 1
+		}
+	}
+	return i
+}
+
+// epigraphEnd returns the line after an attribution that closes a short
+// quotation starting at i, or i when there is none.
+func epigraphEnd(lines []textLine, i int) int {
+	words := 0
+	for j := i; j < len(lines) && j <= i+4; j++ {
+		if j > i && attributionPattern.MatchString(lines[j].raw) {
+			return j + 1
+		}
+		words += len(lines[j].words)
+		if words > maxEpigraphWords {
+			return i
+		}
+	}
+	return i
+}
+
+// repetitionMask marks the words inside intentional spans and returns its
+// prefix sums, so any range can be tested in constant time.
+func repetitionMask(spans []intentionalSpan, wordCount int) []int {
+	marked := make([]bool, wordCount)
+	for _, s := range spans {
+		for k := maxInt(0, s.Start); k < minInt(s.End, wordCount); k++ {
+			marked[k] = true
+		}
+	}
+	prefix := make([]int, wordCount+1)
+	for k, m := range marked {
+		prefix[k+1] = prefix[k]
+		if m {
+			prefix[k+1]++
+		}
+	}
+	return prefix
+}
+
+// unmaskedWords returns the window's words with intentional repetition
+// removed.
+func unmaskedWords(words []string, w wordWindow, prefix []int) []string {
+	if maskedWords(prefix, w.Start, w.End) == 0 {
+		return words[w.Start:w.End]
+	}
+	out := make([]string, 0, w.End-w.Start)
+	for k := w.Start; k < w.End; k++ {
+		if prefix[k+1] == prefix[k] {
+			out = append(out, words[k])
+		}
+	}
+	return out
+}
+
+func maskedWords(prefix []int, start, end int) int {
+	start, end = maxInt(0, start), minInt(end, len(prefix)-1)
+	if end <= start {
+		return 0
+	}
+	return prefix[end] - prefix[start]
+}
+
+// repetitionEvidence explains, per layout, why repeated text in a window was
+// left out of duplication scoring and the long-duplicate override.
+func repetitionEvidence(w wordWindow, spans []intentionalSpan) []Evidence {
+	out := []Evidence{}
+	byKind := map[string]int{}
+	for _, s := range spans {
+		if !rangesOverlap(w.Start, w.End, s.Start, s.End) {
+			continue
+		}
+		span := EvidenceSpan{Start: maxInt(w.Start, s.Start), End: minInt(w.End, s.End)}
+		if k, ok := byKind[s.Kind]; ok {
+			out[k].Spans = append(out[k].Spans, span)
+			continue
+		}
+		byKind[s.Kind] = len(out)
+		out = append(out, Evidence{Type: "intentional_repetition", Spans: []EvidenceSpan{span}})
+	}
+	for kind, k := range byKind {
+		words := 0
+		for _, span := range out[k].Spans {
+			words += span.End - span.Start
+		}
+		out[k].Summary = fmt.Sprintf("repeated %s layout (%d words); not counted as duplication", kind, words)
+		if kind == RepetitionExcluded {
+			out[k].Summary = fmt.Sprintf("matches the duplication ignore list (%d words); not counted as duplication", words)
+		}
+	}
+	return out
+}
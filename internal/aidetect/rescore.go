@@ -0,0 +1,135 @@
+package aidetect
+
+// WindowInputs are the raw signals of one window: everything Rescore needs to
+// weigh it again under another Config without re-reading the text or calling
+// LanguageTool, the LM or the embedder.
+type WindowInputs struct {
+	WindowID  string `json:"window_id"`
+	StartWord int    `json:"start_word"`
+	EndWord   int    `json:"end_word"`
+	// HasEvidence is set when duplication or semantic duplication found a
+	// matching passage, which raises the window's confidence.
+	HasEvidence         bool     `json:"has_evidence"`
+	Duplication         float64  `json:"duplication"`
+	LongestDuplicate    int      `json:"longest_duplicate"`
+	SemanticDuplication *float64 `json:"semantic_duplication"`
+	LMSmoothness        *float64 `json:"lm_smoothness"`
+	StyleUniformity     float64  `json:"style_uniformity"`
+	PolishCliche        float64  `json:"polish_cliche"`
+	LanguageTool        *float64 `json:"language_tool"`
+	Exempt              bool     `json:"exempt"`
+}
+
+// Inputs returns the raw signals of the report's windows.
+func (r Report) Inputs() []WindowInputs {
+	out := make([]WindowInputs, 0, len(r.Windows))
+	for _, w := range r.Windows {
+		sig := w.Signals
+		out = append(out, WindowInputs{
+			WindowID:            w.WindowID,
+			StartWord:           w.StartWord,
+			EndWord:             w.EndWord,
+			HasEvidence:         len(sig.Duplication.Evidence) > 0 || len(sig.SemanticDuplication.Evidence) > 0,
+			Duplication:         deref(sig.Duplication.Score),
+			LongestDuplicate:    sig.Duplication.LongestWords,
+			SemanticDuplication: sig.SemanticDuplication.Score,
+			LMSmoothness:        sig.LMSmoothness.Score,
+			StyleUniformity:     deref(sig.StyleUniform.Score),
+			PolishCliche:        deref(sig.PolishCliche.Score),
+			LanguageTool:        sig.LanguageTool.Score,
+			Exempt:              w.Exempt,
+		})
+	}
+	return out
+}
+
+// Rescore weighs the windows' recorded signals again under cfg and rebuilds
+// the document and chapter scores and flags from them. Windows keep their
+// evidence and locations from report; inputs without a matching window are
+// added without either. Sentence attributions follow their window's new
+// score, but windows that only now reach the attribution threshold gain none
+// until the next full run.
+func Rescore(report *Report, inputs []WindowInputs, cfg Config) {
+	cal := cfg.Calibration.normalized()
+	previous := map[string]WindowReport{}
+	for _, w := range report.Windows {
+		previous[w.WindowID] = w
+	}
+	lmUnavailable := false
+	for _, in := range inputs {
+		if in.LMSmoothness == nil {
+			lmUnavailable = true
+		}
+	}
+
+	windows := make([]WindowReport, 0, len(inputs))
+	ratio := map[string]float64{}
+	for _, in := range inputs {
+		ww := wordWindow{Start: in.StartWord, End: in.EndWord}
+		s := windowScores{
+			dup:        in.Duplication,
+			longestDup: in.LongestDuplicate,
+			style:      in.StyleUniformity,
+			polish:     in.PolishCliche,
+			lt:         in.LanguageTool,
+			lm:         in.LMSmoothness,
+			semantic:   in.SemanticDuplication,
+		}
+		if in.HasEvidence {
+			s.evidence = []Evidence{{Type: "duplication"}}
+		}
+		p, conf := windowProbability(s, in.EndWord-in.StartWord, cfg, cal, lmUnavailable)
+
+		w, ok := previous[in.WindowID]
+		if !ok {
+			w = WindowReport{WindowID: in.WindowID}
+		}
+		if w.PAI > 0 {
+			ratio[in.WindowID] = p / w.PAI
+		}
+		evidence := make([]Evidence, 0, len(w.TopEvidence)+1)
+		for _, e := range w.TopEvidence {
+			if e.Type != "duplication" || e.Summary != "long duplicate span" {
+				evidence = append(evidence, e)
+			}
+		}
+		if in.LongestDuplicate >= cfg.DupOverrideMinWords {
+			evidence = append(evidence, longDuplicateEvidence(ww, in.LongestDuplicate))
+		}
+		w.StartWord, w.EndWord = in.StartWord, in.EndWord
+		w.PAI, w.Confidence = p, conf
+		w.Signals.Duplication.Score = floatPtr(in.Duplication)
+		w.Signals.Duplication.LongestWords = in.LongestDuplicate
+		w.Signals.SemanticDuplication.Score = in.SemanticDuplication
+		w.Signals.LMSmoothness.Score = in.LMSmoothness
+		w.Signals.StyleUniform.Score = floatPtr(in.StyleUniformity)
+		w.Signals.PolishCliche.Score = floatPtr(in.PolishCliche)
+		w.Signals.LanguageTool.Score = in.LanguageTool
+		w.TopEvidence = evidence
+		w.Exempt = in.Exempt
+		windows = append(windows, w)
+	}
+
+	report.Windows = windows
+	report.Calibration = cal
+	report.Sensitivity = cfg.Sensitivity
+	report.Weighting = cfg.Weighting.normalized()
+	report.Flags = []string{}
+	report.ExemptWindows = 0
+	report.PAIDoc, report.AICoverageEst, report.PAIMax, report.ConfidenceDoc = nil, nil, nil, nil
+	_ = aggregateDocument(report, cfg)
+
+	if len(report.PAIPerChapter) > 0 {
+		spans := make([]ChapterSpan, 0, len(report.PAIPerChapter))
+		for _, ch := range report.PAIPerChapter {
+			spans = append(spans, ch.ChapterSpan)
+		}
+		AggregateChapters(report, spans)
+	}
+	for i := range report.Sentences {
+		s := &report.Sentences[i]
+		if r, ok := ratio[s.WindowID]; ok {
+			s.PAI = clamp01(s.PAI * r)
+		}
+	}
+}
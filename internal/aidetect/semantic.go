@@ -0,0 +1,179 @@
+package aidetect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// semanticBaseline is the cosine similarity below which two windows count as
+// unrelated; passages of one book on different subjects score around here.
+// The signal rises linearly from it to 1 at Config.SemanticDupThreshold.
+const semanticBaseline = 0.70
+
+// EmbeddingScorer turns a window's text into a vector. Windows whose vectors
+// point the same way say the same thing, even in different words, which
+// shingle comparison cannot see.
+type EmbeddingScorer interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// OllamaEmbedder embeds text with an Ollama /api/embeddings endpoint.
+type OllamaEmbedder struct {
+	Endpoint string
+	Model    string
+	Client   *http.Client
+}
+
+func NewOllamaEmbedder(endpoint, model string) *OllamaEmbedder {
+	return &OllamaEmbedder{Endpoint: endpoint, Model: model, Client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	raw, err := json.Marshal(map[string]any{"model": e.Model, "prompt": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var out struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embedding) == 0 {
+		return nil, fmt.Errorf("empty embedding")
+	}
+	return out.Embedding, nil
+}
+
+// semanticEmbedder embeds windows one at a time and gives up after three
+// failures, like the LM scorer. The zero value is unused.
+type semanticEmbedder struct {
+	ctx         context.Context
+	scorer      EmbeddingScorer
+	timeout     time.Duration
+	fails       int
+	failType    string
+	failMessage string
+}
+
+func newSemanticEmbedder(ctx context.Context, cfg Config) *semanticEmbedder {
+	return &semanticEmbedder{ctx: ctx, scorer: cfg.Embedder, timeout: time.Duration(cfg.EmbeddingTimeoutMs) * time.Millisecond}
+}
+
+// embed returns the window's vector, or nil once the scorer has failed or
+// the run was cancelled.
+func (e *semanticEmbedder) embed(text string) []float64 {
+	if e.scorer == nil || e.fails >= 3 || e.ctx.Err() != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, e.timeout)
+	v, err := e.scorer.Embed(ctx, text)
+	cancel()
+	if err != nil {
+		e.fails++
+		if e.failType == "" {
+			e.failType = classifyToolErr(err)
+		}
+		if e.failMessage == "" {
+			e.failMessage = err.Error()
+		}
+		return nil
+	}
+	return v
+}
+
+// errors reports an enabled run that had no scorer or whose calls failed.
+func (e *semanticEmbedder) errors(windowCount int) []ErrorEntry {
+	if e.scorer == nil {
+		return []ErrorEntry{{
+			Stage:     "embedding_run",
+			Message:   "embedding scorer unavailable",
+			Type:      "tool_unavailable",
+			Retryable: true,
+		}}
+	}
+	if e.fails == 0 {
+		return nil
+	}
+	return []ErrorEntry{{
+		Stage:     "embedding_run",
+		Message:   fmt.Sprintf("%s (%d/%d windows failed)", defaultIfEmpty(e.failMessage, "embedding scorer failed"), e.fails, windowCount),
+		Type:      defaultIfEmpty(e.failType, "exception"),
+		Retryable: true,
+	}}
+}
+
+// semanticDupSignals scores every window in scores against its most similar
+// distant window. Windows closer than a window's length apart are skipped:
+// nearby passages of one scene share a subject without repeating each other.
+// A window without a vector gets no score.
+func semanticDupSignals(scores []windowScores, windows []wordWindow, vectors [][]float64, threshold float64, windowSize int) {
+	for i, w := range windows {
+		if vectors[i] == nil {
+			continue
+		}
+		best, bestWindow := 0.0, -1
+		for j, other := range windows {
+			if vectors[j] == nil || maxInt(other.Start-w.End, w.Start-other.End) < windowSize {
+				continue
+			}
+			if sim := cosineSimilarity(vectors[i], vectors[j]); sim > best {
+				best, bestWindow = sim, j
+			}
+		}
+		score := 0.0
+		if threshold > semanticBaseline {
+			score = clamp01((best - semanticBaseline) / (threshold - semanticBaseline))
+		}
+		scores[i].semantic = floatPtr(score)
+		scores[i].semanticEvidence = []Evidence{}
+		if bestWindow >= 0 && best >= threshold {
+			other := windows[bestWindow]
+			scores[i].semanticEvidence = append(scores[i].semanticEvidence, Evidence{
+				Type:    "semantic_duplication",
+				Summary: fmt.Sprintf("paraphrases %s (cosine=%.2f)", windowID(bestWindow), best),
+				Spans:   []EvidenceSpan{{Start: w.Start, End: w.End}, {Start: other.Start, End: other.End}},
+			})
+		}
+	}
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	dot, na, nb := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
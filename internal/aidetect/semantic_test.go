@@ -0,0 +1,143 @@
+package aidetect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stubEmbedder gives windows about the harbor the same direction and every
+// other window its own.
+type stubEmbedder struct {
+	err   error
+	calls int
+}
+
+func (s *stubEmbedder) Embed(_ context.Context, text string) ([]float64, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	v := make([]float64, 8)
+	if strings.Contains(text, "harbor") {
+		v[0] = 1
+		return v, nil
+	}
+	for k := 1; k < len(v); k++ {
+		if strings.Contains(text, "topic"+strconv.Itoa(k)+" ") {
+			v[k] = 1
+		}
+	}
+	return v, nil
+}
+
+func semanticFixture() []string {
+	section := func(lead string, n int) string {
+		words := make([]string, 0, 100)
+		for len(words) < 100 {
+			words = append(words, lead, "item"+strconv.Itoa(n*1000+len(words)))
+		}
+		return strings.Join(words[:100], " ")
+	}
+	return []string{section("harbor", 0), section("topic1", 1), section("topic2", 2), section("harbor", 3)}
+}
+
+func semanticConfig(e EmbeddingScorer) Config {
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 100
+	cfg.StrideWords = 100
+	cfg.EnableSemanticDup = true
+	cfg.Embedder = e
+	return cfg
+}
+
+func TestSemanticDuplicationFindsParaphrasedWindows(t *testing.T) {
+	text := strings.Join(semanticFixture(), "\n\n")
+	report := Analyze(Input{DocumentID: "semantic", Text: text, Language: "en"}, semanticConfig(&stubEmbedder{}), nil, nil, nil)
+	if len(report.Windows) != 4 {
+		t.Fatalf("want 4 windows, got %d", len(report.Windows))
+	}
+	for _, i := range []int{0, 3} {
+		sig := report.Windows[i].Signals.SemanticDuplication
+		if sig.Score == nil || *sig.Score != 1 || len(sig.Evidence) != 1 || sig.Evidence[0].Type != "semantic_duplication" {
+			t.Fatalf("window %d: want a paraphrase found, got %+v", i, sig)
+		}
+		if len(report.Windows[i].Signals.Duplication.Evidence) > 0 {
+			t.Fatalf("window %d: shingles should not see the paraphrase, got %+v", i, report.Windows[i].Signals.Duplication.Evidence)
+		}
+	}
+	if !strings.Contains(report.Windows[0].Signals.SemanticDuplication.Evidence[0].Summary, "w-003") {
+		t.Fatalf("unexpected evidence %+v", report.Windows[0].Signals.SemanticDuplication.Evidence)
+	}
+	if sig := report.Windows[1].Signals.SemanticDuplication; sig.Score == nil || *sig.Score != 0 || len(sig.Evidence) != 0 {
+		t.Fatalf("want no semantic duplication for a distinct window, got %+v", sig)
+	}
+	if report.Windows[0].PAI <= report.Windows[1].PAI {
+		t.Fatalf("want the paraphrased window to score higher: %.3f vs %.3f", report.Windows[0].PAI, report.Windows[1].PAI)
+	}
+	for _, e := range report.Errors {
+		if e.Stage == "embedding_run" {
+			t.Fatalf("unexpected embedding error %+v", e)
+		}
+	}
+
+	a := NewAnalyzer(Input{DocumentID: "semantic-stream", Language: "en"}, semanticConfig(&stubEmbedder{}), nil, nil, nil)
+	for _, chunk := range semanticFixture() {
+		a.AddChunk(chunk)
+	}
+	streamed := a.Finalize()
+	for i, w := range streamed.Windows {
+		if got, want := len(w.Signals.SemanticDuplication.Evidence), len(report.Windows[i].Signals.SemanticDuplication.Evidence); got != want {
+			t.Fatalf("window %d: stream found %d paraphrases, Analyze %d", i, got, want)
+		}
+	}
+}
+
+func TestSemanticDuplicationOptionalAndFailureReported(t *testing.T) {
+	text := strings.Join(semanticFixture(), "\n\n")
+	cfg := semanticConfig(&stubEmbedder{})
+	cfg.EnableSemanticDup = false
+	report := Analyze(Input{DocumentID: "off", Text: text, Language: "en"}, cfg, nil, nil, nil)
+	for _, w := range report.Windows {
+		if w.Signals.SemanticDuplication.Score != nil {
+			t.Fatalf("want no semantic score when disabled, got %+v", w.Signals.SemanticDuplication)
+		}
+	}
+
+	failing := &stubEmbedder{err: errors.New("connection refused")}
+	report = Analyze(Input{DocumentID: "fail", Text: text, Language: "en"}, semanticConfig(failing), nil, nil, nil)
+	if failing.calls != 3 {
+		t.Fatalf("want the embedder dropped after 3 failures, got %d calls", failing.calls)
+	}
+	found := false
+	for _, e := range report.Errors {
+		if e.Stage == "embedding_run" && e.Type == "tool_unavailable" && strings.Contains(e.Message, "3/4 windows failed") {
+			found = true
+		}
+	}
+	if !found || report.PAIDoc == nil {
+		t.Fatalf("want an embedding_run error and a document score, got %+v", report.Errors)
+	}
+}
+
+func TestOllamaEmbedderPostsPrompt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Model, Prompt string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "nomic-embed-text" || req.Prompt != "some text" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"embedding":[0.5,0.25]}`))
+	}))
+	defer srv.Close()
+	v, err := NewOllamaEmbedder(srv.URL+"/api/embeddings", "nomic-embed-text").Embed(context.Background(), "some text")
+	if err != nil || len(v) != 2 || v[0] != 0.5 {
+		t.Fatalf("unexpected embedding %v (%v)", v, err)
+	}
+}
@@ -0,0 +1,96 @@
+package aidetect
+
+import "strings"
+
+// Sensitivity presets trade false positives against missed passages. Each
+// sets the scoring bias, the stylistic signal scale and the flag thresholds
+// together so a run is uniformly strict or lenient.
+const (
+	SensitivityConservative = "conservative"
+	SensitivityBalanced     = "balanced"
+	SensitivityAggressive   = "aggressive"
+)
+
+type sensitivityPreset struct {
+	Bias                float64
+	StyleScale          float64
+	NearDupThreshold    float64
+	DupOverrideMinWords int
+	CoverageTrigger     float64
+	ChunkFlagPAI        float64
+	WidespreadCoverage  float64
+}
+
+// Balanced keeps the thresholds the detector shipped with.
+var sensitivityPresets = map[string]sensitivityPreset{
+	SensitivityConservative: {Bias: -0.45, StyleScale: 0.85, NearDupThreshold: 0.25, DupOverrideMinWords: 400, CoverageTrigger: 0.06, ChunkFlagPAI: 0.92, WidespreadCoverage: 0.45},
+	SensitivityBalanced:     {Bias: -0.20, StyleScale: 1.00, NearDupThreshold: 0.18, DupOverrideMinWords: 250, CoverageTrigger: 0.03, ChunkFlagPAI: 0.85, WidespreadCoverage: 0.35},
+	SensitivityAggressive:   {Bias: 0.00, StyleScale: 1.15, NearDupThreshold: 0.12, DupOverrideMinWords: 150, CoverageTrigger: 0.02, ChunkFlagPAI: 0.75, WidespreadCoverage: 0.25},
+}
+
+// NormalizeSensitivity maps a preset name onto a known preset; anything else
+// is balanced.
+func NormalizeSensitivity(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := sensitivityPresets[key]; ok {
+		return key
+	}
+	return SensitivityBalanced
+}
+
+// IsSensitivity reports whether name is one of the presets.
+func IsSensitivity(name string) bool {
+	_, ok := sensitivityPresets[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// ConfigForSensitivity is DefaultConfig with the preset's thresholds. AI_*
+// environment overrides still take precedence over the preset, and
+// AI_WEIGHTING picks the weighting profile.
+func ConfigForSensitivity(name string) Config {
+	name = NormalizeSensitivity(name)
+	preset := sensitivityPresets[name]
+	weighting, _ := WeightingFromEnv()
+	return Config{
+		Sensitivity:           name,
+		WindowWords:           getenvInt("AI_WINDOW_WORDS", 900),
+		StrideWords:           getenvInt("AI_STRIDE_WORDS", 450),
+		DupNGramN:             getenvInt("AI_DUP_NGRAM_N", 10),
+		NearDupThreshold:      getenvFloat("AI_NEAR_DUP_THRESHOLD", preset.NearDupThreshold),
+		DupOverrideMinWords:   getenvInt("AI_DUP_OVERRIDE_MIN_WORDS", preset.DupOverrideMinWords),
+		CoverageTrigger:       getenvFloat("AI_COVERAGE_TRIGGER", preset.CoverageTrigger),
+		Bias:                  getenvFloat("AI_BIAS", preset.Bias),
+		StyleScale:            preset.StyleScale,
+		ChunkFlagPAI:          preset.ChunkFlagPAI,
+		WidespreadCoverage:    preset.WidespreadCoverage,
+		EnableLanguageTool:    getenvBool("AI_ENABLE_LANGUAGE_TOOL", true),
+		EnableLMSmoothness:    getenvBool("AI_ENABLE_LM_SMOOTHNESS", false),
+		LanguageToolTimeoutMs: getenvInt("AI_LANGUAGETOOL_TIMEOUT_MS", 5000),
+		LanguageToolStride:    getenvInt("AI_LANGUAGETOOL_STRIDE", 3),
+		LanguageToolMaxWindow: getenvInt("AI_LANGUAGETOOL_MAX_WINDOWS", 24),
+		LanguageToolMaxFails:  getenvInt("AI_LANGUAGETOOL_MAX_FAILS", 3),
+		LMSmoothnessTimeoutMs: getenvInt("AI_LM_TIMEOUT_MS", 5000),
+		EnableSemanticDup:     getenvBool("AI_ENABLE_SEMANTIC_DUP", false),
+		SemanticDupThreshold:  getenvFloat("AI_SEMANTIC_DUP_THRESHOLD", 0.92),
+		EmbeddingTimeoutMs:    getenvInt("AI_EMBEDDING_TIMEOUT_MS", 15000),
+		Calibration:           NeutralCalibration(),
+		Weighting:             weighting,
+	}
+}
+
+// flagThresholds fills in the balanced thresholds for a hand-built Config
+// that left them unset.
+func (c Config) flagThresholds() (styleScale, chunkPAI, widespread float64) {
+	balanced := sensitivityPresets[SensitivityBalanced]
+	styleScale, chunkPAI, widespread = c.StyleScale, c.ChunkFlagPAI, c.WidespreadCoverage
+	if styleScale <= 0 {
+		styleScale = balanced.StyleScale
+	}
+	if chunkPAI <= 0 {
+		chunkPAI = balanced.ChunkFlagPAI
+	}
+	if widespread <= 0 {
+		widespread = balanced.WidespreadCoverage
+	}
+	return styleScale, chunkPAI, widespread
+}
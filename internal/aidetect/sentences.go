@@ -0,0 +1,135 @@
+package aidetect
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minSentenceSignal is the sentence-level signal below which a sentence is
+// not credited with its window's score.
+const minSentenceSignal = 0.3
+
+// sentenceEnd splits source text into sentences while keeping the original
+// casing and punctuation for display.
+var sentenceEnd = regexp.MustCompile(`[.!?]+["'”’)]*\s+|\n+`)
+
+// SentenceAttribution credits part of a flagged window's score to one of its
+// sentences. PAI is the window's likelihood scaled by how strongly the
+// sentence itself carries the signals that can be read at sentence length.
+type SentenceAttribution struct {
+	WindowID  string   `json:"window_id"`
+	StartWord int      `json:"start_word"`
+	EndWord   int      `json:"end_word"`
+	Text      string   `json:"text"`
+	PAI       float64  `json:"p_ai"`
+	Signals   []string `json:"signals"`
+	// Location is filled in by the caller, as for windows.
+	Location *WindowLocation `json:"location,omitempty"`
+}
+
+type sentenceSpan struct {
+	start, end int
+	text       string
+}
+
+// AttributeSentences sets Sentences from the report's non-exempt windows at
+// or above minPAI, keeping the limit highest-scoring sentences. A sentence in
+// several windows keeps its highest attribution. It returns how many
+// sentences were attributed.
+func AttributeSentences(report *Report, text string, minPAI float64, limit int) int {
+	sentences := splitSentenceSpans(text)
+	intensifiers := intensifierSet(report.Intensifiers)
+	best := map[int]SentenceAttribution{}
+	for _, w := range report.Windows {
+		if w.Exempt || w.PAI < minPAI {
+			continue
+		}
+		dupSpans := []EvidenceSpan{}
+		for _, ev := range w.Signals.Duplication.Evidence {
+			dupSpans = append(dupSpans, ev.Spans...)
+		}
+		first := sort.Search(len(sentences), func(i int) bool { return sentences[i].end > w.StartWord })
+		for i := first; i < len(sentences) && sentences[i].start < w.EndWord; i++ {
+			s := sentences[i]
+			if s.start < w.StartWord || s.end > w.EndWord {
+				continue
+			}
+			signal, signals := sentenceSignal(s, dupSpans, intensifiers)
+			if signal < minSentenceSignal {
+				continue
+			}
+			pai := clamp01(w.PAI * signal)
+			if prev, ok := best[i]; ok && prev.PAI >= pai {
+				continue
+			}
+			best[i] = SentenceAttribution{
+				WindowID:  w.WindowID,
+				StartWord: s.start,
+				EndWord:   s.end,
+				Text:      s.text,
+				PAI:       math.Round(pai*1000) / 1000,
+				Signals:   signals,
+			}
+		}
+	}
+	out := make([]SentenceAttribution, 0, len(best))
+	for _, a := range best {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PAI != out[j].PAI {
+			return out[i].PAI > out[j].PAI
+		}
+		return out[i].StartWord < out[j].StartWord
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	report.Sentences = out
+	return len(out)
+}
+
+// sentenceSignal scores one sentence from its own polish and cliché density
+// and whether it lies in duplicated text.
+func sentenceSignal(s sentenceSpan, dupSpans []EvidenceSpan, intensifiers map[string]struct{}) (float64, []string) {
+	signals := []string{}
+	normalized := normalizeText(s.text)
+	polish := polishClicheScore(splitWords(normalized), normalized, intensifiers)
+	if polish >= minSentenceSignal {
+		signals = append(signals, "polish_cliche")
+	}
+	dup := 0.0
+	for _, span := range dupSpans {
+		if span.Start <= s.start && s.end <= span.End {
+			dup = 1
+			signals = append(signals, "duplication")
+			break
+		}
+	}
+	return math.Max(polish, dup), signals
+}
+
+// splitSentenceSpans places every sentence of text on the word axis Words
+// uses; sentences without words are dropped.
+func splitSentenceSpans(text string) []sentenceSpan {
+	out := []sentenceSpan{}
+	pos, word := 0, 0
+	add := func(end int) {
+		raw := strings.TrimSpace(text[pos:end])
+		n := len(Words(raw))
+		if n > 0 {
+			out = append(out, sentenceSpan{start: word, end: word + n, text: raw})
+		}
+		word += n
+		pos = end
+	}
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		add(loc[1])
+	}
+	if pos < len(text) {
+		add(len(text))
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package aidetect
+
+import "testing"
+
+func TestAttributeSentencesCreditsPolishedAndDuplicatedSentences(t *testing.T) {
+	text := "She walked to the store. It was the unmistakable, utterly terrifying, absolutely inevitable end.\nHe bought bread and milk. The rain kept falling on the roof."
+	sentences := splitSentenceSpans(text)
+	if len(sentences) != 4 {
+		t.Fatalf("want 4 sentences, got %+v", sentences)
+	}
+	if got := sentences[len(sentences)-1].end; got != len(Words(text)) {
+		t.Fatalf("sentences end at word %d, want %d", got, len(Words(text)))
+	}
+	last := sentences[3]
+	report := Report{Windows: []WindowReport{
+		{WindowID: "w-001", StartWord: 0, EndWord: last.end, PAI: 0.8, Signals: WindowSignals{
+			Duplication: DuplicationSignal{Evidence: []Evidence{{Type: "duplication", Spans: []EvidenceSpan{{Start: last.start, End: last.end}}}}},
+		}},
+		{WindowID: "w-002", StartWord: 0, EndWord: last.end, PAI: 0.9, Exempt: true},
+	}}
+	if n := AttributeSentences(&report, text, 0.5, 10); n != 2 {
+		t.Fatalf("want 2 attributed sentences, got %+v", report.Sentences)
+	}
+	byText := map[string]SentenceAttribution{}
+	for _, a := range report.Sentences {
+		byText[a.Text] = a
+	}
+	dup := byText["The rain kept falling on the roof."]
+	if dup.PAI != 0.8 || len(dup.Signals) != 1 || dup.Signals[0] != "duplication" || dup.WindowID != "w-001" {
+		t.Fatalf("unexpected duplicated sentence %+v", report.Sentences)
+	}
+	polished := byText["It was the unmistakable, utterly terrifying, absolutely inevitable end."]
+	if polished.PAI == 0 || polished.Signals[0] != "polish_cliche" {
+		t.Fatalf("unexpected polished sentence %+v", report.Sentences)
+	}
+
+	report.Windows[0].PAI = 0.4
+	if n := AttributeSentences(&report, text, 0.5, 10); n != 0 {
+		t.Fatalf("want no attribution below minPAI, got %+v", report.Sentences)
+	}
+}
@@ -0,0 +1,448 @@
+package aidetect
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// sketchSize is the number of MinHash values kept per window; at this size
+// the standard error of a similarity estimate is at most 0.045.
+const sketchSize = 128
+
+var sketchSeeds = func() []uint64 {
+	seeds := make([]uint64, sketchSize)
+	state := uint64(0x9e3779b97f4a7c15)
+	for k := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[k] = mix64(state)
+	}
+	return seeds
+}()
+
+// Analyzer scores a manuscript fed to it in chunks, for texts too large to
+// hold as one string with a shingle set per window. Each window is scored as
+// soon as its words have arrived and then keeps only a fixed-size MinHash
+// sketch for near-duplicate comparison, so memory grows with the window count
+// rather than the text. Near-duplication is therefore estimated, where
+// Analyze computes it exactly; the other signals are the same.
+//
+// Chunks are joined as separate paragraphs, so split the text at paragraph
+// breaks (chapters are a natural unit). Repetition that is only found to be
+// intentional in a later chunk is still counted against the earlier
+// occurrence's shingles. Embeddings, when enabled, are kept per window and
+// compared in Finalize. Language tool windows are scored one at a time;
+// Config.LanguageToolLimiter is not used. An Analyzer is not safe for
+// concurrent use.
+type Analyzer struct {
+	ctx        context.Context
+	in         Input
+	cfg        Config
+	lt         LanguageToolScorer
+	lm         LMSmoothnessScorer
+	logger     Logger
+	report     Report
+	started    time.Time
+	chunkTime  time.Duration
+	rejected   bool
+	finalized  bool
+	windowSize int
+	stride     int
+	// intensifiers is the polish signal's lexicon, from cfg.
+	intensifiers map[string]struct{}
+
+	// words holds the text from word offset on; words before the next
+	// window's start are dropped once every window using them is scored.
+	words       []string
+	offset      int
+	total       int
+	next        int
+	windows     []wordWindow
+	scores      []windowScores
+	sketches    [][]uint64
+	vectors     [][]float64
+	embedder    *semanticEmbedder
+	paragraphs  map[string][]paragraphLoc
+	blocks      []repetitionBlock
+	excluded    []intentionalSpan
+	intentional []intentionalSpan
+	recaps      []intentionalSpan
+	// unscored merges intentional and recaps: the words kept out of the
+	// shingle sketches.
+	unscored []intentionalSpan
+	// recapSketches holds, for windows overlapping a recap, the sketch
+	// with the recap text left in.
+	recapSketches [][]uint64
+
+	ltCalls, ltSampled, ltFails, ltConsecutive int
+	ltType, ltMessage                          string
+	ltUnavailable                              bool
+	lmFails                                    int
+	lmType, lmMessage                          string
+	lmUnavailable                              bool
+}
+
+// NewAnalyzer starts a streaming run. in.Text, when set, is taken as the
+// first chunk; in.ExemptSpans use word offsets into the whole text.
+func NewAnalyzer(in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer, logger Logger) *Analyzer {
+	return NewAnalyzerContext(context.Background(), in, cfg, lt, lm, logger)
+}
+
+// NewAnalyzerContext is NewAnalyzer for a run that can be cancelled; see
+// AnalyzeContext.
+func NewAnalyzerContext(ctx context.Context, in Input, cfg Config, lt LanguageToolScorer, lm LMSmoothnessScorer, logger Logger) *Analyzer {
+	a := &Analyzer{
+		ctx:    ctx,
+		in:     in,
+		cfg:    cfg,
+		lt:     lt,
+		lm:     lm,
+		logger: logger,
+		report: Report{
+			DocumentID:   in.DocumentID,
+			Flags:        []string{},
+			Windows:      []WindowReport{},
+			Errors:       []ErrorEntry{},
+			Traces:       []SpanTrace{},
+			Calibration:  cfg.Calibration.normalized(),
+			Sensitivity:  cfg.Sensitivity,
+			Weighting:    cfg.Weighting.normalized(),
+			Intensifiers: cfg.Intensifiers,
+		},
+		started:      time.Now(),
+		windowSize:   cfg.WindowWords,
+		stride:       cfg.StrideWords,
+		paragraphs:   map[string][]paragraphLoc{},
+		embedder:     newSemanticEmbedder(ctx, cfg),
+		intensifiers: intensifierSet(cfg.Intensifiers),
+	}
+	if a.windowSize <= 0 {
+		a.windowSize = 900
+	}
+	if a.stride <= 0 {
+		a.stride = a.windowSize / 2
+	}
+	if strings.TrimSpace(in.Language) != "" && !strings.EqualFold(in.Language, "en") {
+		a.rejected = true
+		a.report.Errors = append(a.report.Errors, ErrorEntry{
+			Stage:     "bad_input",
+			Message:   "language must be en",
+			Type:      "bad_input",
+			Retryable: false,
+		})
+		return a
+	}
+	if logger != nil {
+		logger.Log("ANALYSIS", "AI", "AI detection stream started", fmt.Sprintf("document_id=%s window_words=%d stride_words=%d", in.DocumentID, a.windowSize, a.stride))
+	}
+	a.in.Text = ""
+	if strings.TrimSpace(in.Text) != "" {
+		a.AddChunk(in.Text)
+	}
+	return a
+}
+
+// AddChunk adds the next part of the text and scores every window it
+// completes. Chunks added after Finalize are ignored.
+func (a *Analyzer) AddChunk(text string) {
+	if a.rejected || a.finalized {
+		return
+	}
+	start := time.Now()
+	defer func() { a.chunkTime += time.Since(start) }()
+
+	normalized := normalizeText(text)
+	words := splitWords(normalized)
+	for h, locs := range buildParagraphHashIndex(normalized, words) {
+		for _, loc := range locs {
+			a.paragraphs[h] = append(a.paragraphs[h], paragraphLoc{Start: loc.Start + a.total, End: loc.End + a.total})
+		}
+	}
+	a.blocks = append(a.blocks, repetitionBlocks(text, a.total)...)
+	a.excluded = append(a.excluded, excludedSpans(text, a.cfg.Exclusions, a.total)...)
+	a.intentional = mergeSpans(append(intentionalSpans(a.blocks), a.excluded...))
+	a.recaps = append(a.recaps, recapSpans(text, a.total)...)
+	a.unscored = mergeSpans(append(append([]intentionalSpan{}, a.intentional...), a.recaps...))
+	a.words = append(a.words, words...)
+	a.total += len(words)
+
+	// A window with words after it is never the last, so the last window is
+	// left for Finalize, which knows where the text ends.
+	for a.next+a.windowSize < a.total {
+		a.scoreWindow(wordWindow{Start: a.next, End: a.next + a.windowSize}, false)
+		a.next += a.stride
+	}
+	if drop := minInt(a.next-a.offset, len(a.words)); drop > 0 {
+		a.words = append([]string(nil), a.words[drop:]...)
+		a.offset += drop
+	}
+}
+
+// Finalize scores the last window, compares every window for duplication and
+// returns the report. Calling it again returns the same report.
+func (a *Analyzer) Finalize() Report {
+	if a.finalized || a.rejected {
+		a.finalized = true
+		return a.report
+	}
+	a.finalized = true
+	report := &a.report
+	report.Traces = append(report.Traces, SpanTrace{Name: "stream_chunks", DurationMs: a.chunkTime.Milliseconds(), Status: "ok"})
+	report.WordCount = a.total
+	start := minInt(a.next, a.total)
+	a.scoreWindow(wordWindow{Start: start, End: minInt(start+a.windowSize, a.total)}, true)
+
+	if !a.cfg.EnableLanguageTool || a.lt == nil {
+		a.ltUnavailable = true
+		report.Errors = append(report.Errors, ErrorEntry{
+			Stage:     "language_tool_run",
+			Message:   "language tool scorer unavailable",
+			Type:      "tool_unavailable",
+			Retryable: true,
+		})
+	} else if a.ltFails > 0 {
+		report.Errors = append(report.Errors, ErrorEntry{
+			Stage:     "language_tool_run",
+			Message:   fmt.Sprintf("%s (%d/%d sampled windows failed)", defaultIfEmpty(a.ltMessage, "language tool scorer failed"), a.ltFails, maxInt(1, a.ltCalls)),
+			Type:      defaultIfEmpty(a.ltType, "exception"),
+			Retryable: true,
+		})
+	}
+	if !a.cfg.EnableLMSmoothness || a.lm == nil {
+		a.lmUnavailable = true
+		report.Errors = append(report.Errors, ErrorEntry{
+			Stage:     "lm_scoring_run",
+			Message:   "lm smoothness scorer unavailable",
+			Type:      "tool_unavailable",
+			Retryable: true,
+		})
+	} else if a.lmFails > 0 {
+		report.Errors = append(report.Errors, ErrorEntry{
+			Stage:     "lm_scoring_run",
+			Message:   fmt.Sprintf("%s (%d/%d windows failed)", defaultIfEmpty(a.lmMessage, "lm scorer failed"), a.lmFails, len(a.windows)),
+			Type:      defaultIfEmpty(a.lmType, "exception"),
+			Retryable: true,
+		})
+	}
+
+	withSpan(report, "duplication_scan", func() error {
+		masked := func(start, end int) int { return spanMaskedWords(a.unscored, start, end) }
+		similarity := func(i, j int) float64 { return sketchSimilarity(a.sketches[i], a.sketches[j]) }
+		withRecap := func(i, j int) float64 {
+			x, y := a.recapSketches[i], a.recapSketches[j]
+			if x == nil {
+				x = a.sketches[i]
+			}
+			if y == nil {
+				y = a.sketches[j]
+			}
+			return sketchSimilarity(x, y)
+		}
+		for i, w := range a.windows {
+			a.scores[i].dup, a.scores[i].evidence, a.scores[i].longestDup = windowDupSignal(i, w, len(a.windows), a.paragraphs, masked, similarity, nil, a.cfg.NearDupThreshold, a.cfg.WindowWords)
+			a.scores[i].recapEvidence = recapDupEvidence(i, w, len(a.windows), a.recaps, withRecap, similarity, nil, a.cfg.NearDupThreshold)
+		}
+		return nil
+	})
+	if a.cfg.EnableSemanticDup {
+		report.Errors = append(report.Errors, a.embedder.errors(len(a.windows))...)
+		semanticDupSignals(a.scores, a.windows, a.vectors, a.cfg.SemanticDupThreshold, a.cfg.WindowWords)
+	}
+	if err := a.ctx.Err(); err != nil {
+		report.Errors = append(report.Errors, cancelledError(err))
+	}
+	withSpan(report, "score_windows", func() error {
+		for i, w := range a.windows {
+			report.Windows = append(report.Windows, scoreWindow(i, w, a.scores[i], a.cfg, report.Calibration, a.lmUnavailable, a.intentional, a.in.ExemptSpans))
+		}
+		return nil
+	})
+	withSpan(report, "aggregate_document", func() error {
+		return aggregateDocument(report, a.cfg)
+	})
+	a.words, a.sketches, a.recapSketches, a.vectors, a.paragraphs, a.blocks = nil, nil, nil, nil, nil, nil
+
+	if a.logger != nil {
+		a.logger.Log("ANALYSIS", "AI", "AI detection run completed", fmt.Sprintf("document_id=%s words=%d windows=%d errors=%d p_ai_doc=%.3f coverage=%.3f p_ai_max=%.3f exempt_windows=%d duration_ms=%d lm_available=%t lt_available=%t",
+			a.in.DocumentID, report.WordCount, len(report.Windows), len(report.Errors), deref(report.PAIDoc), deref(report.AICoverageEst), deref(report.PAIMax), report.ExemptWindows,
+			time.Since(a.started).Milliseconds(), !a.lmUnavailable, !a.ltUnavailable))
+	}
+	return a.report
+}
+
+// scoreWindow computes the signals of one window whose words are all
+// buffered. Duplication waits for Finalize, when every window is known.
+func (a *Analyzer) scoreWindow(w wordWindow, last bool) {
+	i := len(a.windows)
+	windowWords := a.words[w.Start-a.offset : w.End-a.offset]
+	windowText := strings.Join(windowWords, " ")
+	s := windowScores{
+		style:  styleUniformityScore(windowText),
+		polish: polishClicheScore(windowWords, windowText, a.intensifiers),
+	}
+
+	// shouldRunLanguageTool samples the first, every stride-th and the last
+	// window; the total only tells it whether this one is the last.
+	total := i + 2
+	if last {
+		total = i + 1
+	}
+	if a.cfg.EnableLanguageTool && a.lt != nil && a.ctx.Err() == nil && a.ltConsecutive < maxInt(1, a.cfg.LanguageToolMaxFails) && shouldRunLanguageTool(i, total, a.cfg, a.ltSampled) {
+		a.ltSampled++
+		a.ltCalls++
+		ctx, cancel := context.WithTimeout(a.ctx, time.Duration(a.cfg.LanguageToolTimeoutMs)*time.Millisecond)
+		score, err := a.lt.ScoreWindow(ctx, windowText)
+		cancel()
+		if err != nil {
+			a.ltUnavailable = true
+			a.ltFails++
+			a.ltConsecutive++
+			if a.ltType == "" {
+				a.ltType = classifyToolErr(err)
+			}
+			if a.ltMessage == "" {
+				a.ltMessage = err.Error()
+			}
+		} else {
+			v := clamp01(score)
+			s.lt = &v
+			a.ltConsecutive = 0
+		}
+	}
+	if a.cfg.EnableLMSmoothness && a.lm != nil && a.ctx.Err() == nil && a.lmFails < 3 {
+		ctx, cancel := context.WithTimeout(a.ctx, time.Duration(a.cfg.LMSmoothnessTimeoutMs)*time.Millisecond)
+		score, err := a.lm.ScoreWindow(ctx, windowText)
+		cancel()
+		if err != nil {
+			a.lmUnavailable = true
+			a.lmFails++
+			if a.lmType == "" {
+				a.lmType = classifyToolErr(err)
+			}
+			if a.lmMessage == "" {
+				a.lmMessage = err.Error()
+			}
+		} else {
+			v := clamp01(score)
+			s.lm = &v
+		}
+	}
+
+	var vector []float64
+	if a.cfg.EnableSemanticDup {
+		vector = a.embedder.embed(windowText)
+	}
+
+	a.windows = append(a.windows, w)
+	a.scores = append(a.scores, s)
+	a.vectors = append(a.vectors, vector)
+	a.sketches = append(a.sketches, minHashSketch(a.unmaskedWords(w, a.unscored), a.cfg.DupNGramN))
+	var recapSketch []uint64
+	if spanMaskedWords(a.recaps, w.Start, w.End) > 0 {
+		recapSketch = minHashSketch(a.unmaskedWords(w, a.intentional), a.cfg.DupNGramN)
+	}
+	a.recapSketches = append(a.recapSketches, recapSketch)
+}
+
+// unmaskedWords returns the buffered words of w outside spans, which do not
+// overlap.
+func (a *Analyzer) unmaskedWords(w wordWindow, spans []intentionalSpan) []string {
+	out := make([]string, 0, w.End-w.Start)
+	k := w.Start
+	for _, s := range spans {
+		if s.End <= w.Start {
+			continue
+		}
+		if s.Start >= w.End {
+			break
+		}
+		for ; k < s.Start; k++ {
+			out = append(out, a.words[k-a.offset])
+		}
+		k = maxInt(k, s.End)
+	}
+	for ; k < w.End; k++ {
+		out = append(out, a.words[k-a.offset])
+	}
+	return out
+}
+
+// spanMaskedWords counts the words of [start, end) inside spans, which do not
+// overlap.
+func spanMaskedWords(spans []intentionalSpan, start, end int) int {
+	n := 0
+	for _, s := range spans {
+		if s.Start >= end {
+			break
+		}
+		n += maxInt(0, minInt(end, s.End)-maxInt(start, s.Start))
+	}
+	return n
+}
+
+// minHashSketch keeps, for each of sketchSize hash functions, the smallest
+// hash over the word n-gram shingles; nil when there are none.
+func minHashSketch(words []string, n int) []uint64 {
+	if n <= 0 {
+		n = 10
+	}
+	if len(words) < n {
+		return nil
+	}
+	sketch := make([]uint64, sketchSize)
+	for k := range sketch {
+		sketch[k] = math.MaxUint64
+	}
+	for i := 0; i+n <= len(words); i++ {
+		h := shingleHash(words[i : i+n])
+		for k, seed := range sketchSeeds {
+			if v := mix64(h ^ seed); v < sketch[k] {
+				sketch[k] = v
+			}
+		}
+	}
+	return sketch
+}
+
+// sketchSimilarity estimates the Jaccard similarity of the shingle sets
+// behind two sketches.
+func sketchSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	same := 0
+	for k := range a {
+		if a[k] == b[k] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// shingleHash is FNV-1a over the space-joined words.
+func shingleHash(words []string) uint64 {
+	h := uint64(14695981039346656037)
+	for i, w := range words {
+		if i > 0 {
+			h ^= ' '
+			h *= 1099511628211
+		}
+		for j := 0; j < len(w); j++ {
+			h ^= uint64(w[j])
+			h *= 1099511628211
+		}
+	}
+	return h
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,72 @@
+package aidetect
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzerMatchesAnalyzeAcrossChunks(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("the sterile corridor hummed with certainty and fear ", 48))
+	chunks := []string{}
+	for c := 0; c < 6; c++ {
+		lines := make([]string, 0, 40)
+		for i := 0; i < 40; i++ {
+			n := c*40 + i
+			lines = append(lines, "Record "+strconv.Itoa(n)+" notes a distinct weather pattern near district "+strconv.Itoa(n*7)+".")
+		}
+		chunks = append(chunks, strings.Join(lines, " "))
+		if c == 1 || c == 4 {
+			chunks = append(chunks, paragraph)
+		}
+	}
+	cfg := DefaultConfig()
+	cfg.EnableLanguageTool = false
+	cfg.WindowWords = 300
+	cfg.StrideWords = 150
+
+	whole := Analyze(Input{DocumentID: "whole", Text: strings.Join(chunks, "\n\n"), Language: "en"}, cfg, nil, nil, nil)
+	a := NewAnalyzer(Input{DocumentID: "stream", Language: "en"}, cfg, nil, nil, nil)
+	for _, c := range chunks {
+		a.AddChunk(c)
+	}
+	streamed := a.Finalize()
+
+	if streamed.WordCount != whole.WordCount || len(streamed.Windows) != len(whole.Windows) {
+		t.Fatalf("want %d words in %d windows, got %d in %d", whole.WordCount, len(whole.Windows), streamed.WordCount, len(streamed.Windows))
+	}
+	for i, w := range streamed.Windows {
+		if w.StartWord != whole.Windows[i].StartWord || w.EndWord != whole.Windows[i].EndWord {
+			t.Fatalf("window %d spans %d-%d, want %d-%d", i, w.StartWord, w.EndWord, whole.Windows[i].StartWord, whole.Windows[i].EndWord)
+		}
+		if *w.Signals.StyleUniform.Score != *whole.Windows[i].Signals.StyleUniform.Score {
+			t.Fatalf("window %d style signal differs", i)
+		}
+		if math.Abs(*w.Signals.Duplication.Score-*whole.Windows[i].Signals.Duplication.Score) > 0.25 {
+			t.Fatalf("window %d duplication %.2f too far from %.2f", i, *w.Signals.Duplication.Score, *whole.Windows[i].Signals.Duplication.Score)
+		}
+	}
+	if *streamed.PAIMax < 0.90 || *whole.PAIMax < 0.90 {
+		t.Fatalf("expected the duplicated paragraph to trip the override, got %.2f and %.2f", *streamed.PAIMax, *whole.PAIMax)
+	}
+	if again := a.Finalize(); len(again.Windows) != len(streamed.Windows) {
+		t.Fatal("expected Finalize to return the same report again")
+	}
+}
+
+func TestSketchSimilarityEstimatesJaccard(t *testing.T) {
+	words := make([]string, 400)
+	for i := range words {
+		words[i] = "w" + strconv.Itoa(i)
+	}
+	a, b := words[:300], words[100:]
+	exact := jaccard(shingleSet(a, 10), shingleSet(b, 10))
+	estimate := sketchSimilarity(minHashSketch(a, 10), minHashSketch(b, 10))
+	if math.Abs(exact-estimate) > 0.15 {
+		t.Fatalf("estimate %.2f too far from exact %.2f", estimate, exact)
+	}
+	if sketchSimilarity(minHashSketch(a, 10), minHashSketch(a, 10)) != 1 || sketchSimilarity(nil, minHashSketch(a, 10)) != 0 {
+		t.Fatal("expected identical sketches to match and empty ones not to")
+	}
+}
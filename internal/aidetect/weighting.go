@@ -0,0 +1,148 @@
+package aidetect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Weighting profiles decide how much each signal contributes to a window's
+// probability. Balanced is the weighting the detector shipped with; strict
+// counts every signal more heavily; duplication-only scores copied and
+// stitched text alone, for reviewers who distrust the stylistic signals.
+const (
+	WeightingBalanced        = "balanced"
+	WeightingStrict          = "strict"
+	WeightingDuplicationOnly = "duplication-only"
+)
+
+// Weights are the per-signal multipliers summed before the bias and sigmoid.
+type Weights struct {
+	Duplication  float64 `json:"duplication"`
+	LMSmoothness float64 `json:"lm_smoothness"`
+	StyleUniform float64 `json:"style_uniformity"`
+	PolishCliche float64 `json:"polish_cliche"`
+	LanguageTool float64 `json:"language_tool"`
+	// SemanticDuplication only counts when Config.EnableSemanticDup is set.
+	SemanticDuplication float64 `json:"semantic_duplication"`
+}
+
+// WeightingProfile has one set of weights for windows with an LM smoothness
+// score and one for windows without, which spreads the LM share over the
+// other signals.
+type WeightingProfile struct {
+	Name      string  `json:"name"`
+	WithLM    Weights `json:"with_lm"`
+	WithoutLM Weights `json:"without_lm"`
+}
+
+var weightingProfiles = map[string]WeightingProfile{
+	WeightingBalanced: {
+		Name:      WeightingBalanced,
+		WithLM:    Weights{Duplication: 0.35, LMSmoothness: 0.30, StyleUniform: 0.20, PolishCliche: 0.10, LanguageTool: 0.05, SemanticDuplication: 0.25},
+		WithoutLM: Weights{Duplication: 0.50, StyleUniform: 0.30, PolishCliche: 0.15, LanguageTool: 0.05, SemanticDuplication: 0.35},
+	},
+	WeightingStrict: {
+		Name:      WeightingStrict,
+		WithLM:    Weights{Duplication: 0.45, LMSmoothness: 0.40, StyleUniform: 0.30, PolishCliche: 0.20, LanguageTool: 0.10, SemanticDuplication: 0.35},
+		WithoutLM: Weights{Duplication: 0.65, StyleUniform: 0.45, PolishCliche: 0.25, LanguageTool: 0.10, SemanticDuplication: 0.45},
+	},
+	WeightingDuplicationOnly: {
+		Name:      WeightingDuplicationOnly,
+		WithLM:    Weights{Duplication: 1, SemanticDuplication: 0.7},
+		WithoutLM: Weights{Duplication: 1, SemanticDuplication: 0.7},
+	},
+}
+
+// NormalizeWeighting maps a profile name onto a built-in profile; anything
+// else is balanced.
+func NormalizeWeighting(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := weightingProfiles[key]; ok {
+		return key
+	}
+	return WeightingBalanced
+}
+
+// IsWeighting reports whether name is one of the built-in profiles.
+func IsWeighting(name string) bool {
+	_, ok := weightingProfiles[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// WeightingForName returns the built-in profile, or balanced for an unknown
+// name.
+func WeightingForName(name string) WeightingProfile {
+	return weightingProfiles[NormalizeWeighting(name)]
+}
+
+// ParseWeightingProfile decodes a profile from JSON and checks it: it needs a
+// name, no negative weight, and some weight in each set.
+func ParseWeightingProfile(raw []byte) (WeightingProfile, error) {
+	var p WeightingProfile
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return WeightingProfile{}, fmt.Errorf("decode weighting profile: %w", err)
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return WeightingProfile{}, fmt.Errorf("weighting profile has no name")
+	}
+	for label, w := range map[string]Weights{"with_lm": p.WithLM, "without_lm": p.WithoutLM} {
+		values := []float64{w.Duplication, w.LMSmoothness, w.StyleUniform, w.PolishCliche, w.LanguageTool, w.SemanticDuplication}
+		total := 0.0
+		for _, v := range values {
+			if v < 0 {
+				return WeightingProfile{}, fmt.Errorf("weighting profile %q: %s has a negative weight", p.Name, label)
+			}
+			total += v
+		}
+		if total == 0 {
+			return WeightingProfile{}, fmt.Errorf("weighting profile %q: %s has no weights", p.Name, label)
+		}
+	}
+	return p, nil
+}
+
+// LoadWeightingProfile reads a JSON profile from path.
+func LoadWeightingProfile(path string) (WeightingProfile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return WeightingProfile{}, fmt.Errorf("read weighting profile: %w", err)
+	}
+	return ParseWeightingProfile(raw)
+}
+
+// WeightingFromEnv resolves AI_WEIGHTING, which names a built-in profile or a
+// JSON profile file. When the file cannot be used it returns balanced along
+// with the reason.
+func WeightingFromEnv() (WeightingProfile, error) {
+	raw := strings.TrimSpace(os.Getenv("AI_WEIGHTING"))
+	if raw == "" || IsWeighting(raw) {
+		return WeightingForName(raw), nil
+	}
+	p, err := LoadWeightingProfile(raw)
+	if err != nil {
+		return WeightingForName(WeightingBalanced), err
+	}
+	return p, nil
+}
+
+// normalized treats an unset profile as balanced so a hand-built Config keeps
+// the shipped weighting.
+func (p WeightingProfile) normalized() WeightingProfile {
+	if p.WithLM == (Weights{}) && p.WithoutLM == (Weights{}) {
+		return WeightingForName(WeightingBalanced)
+	}
+	if p.Name == "" {
+		p.Name = "custom"
+	}
+	return p
+}
+
+func (p WeightingProfile) weights(lmAvailable bool) Weights {
+	if lmAvailable {
+		return p.WithLM
+	}
+	return p.WithoutLM
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Annotation is a user note attached either to a byte range of the analyzed
+// manuscript text or to a finding ID (health issue, AI window, slop flag).
+type Annotation struct {
+	ID          int64
+	FindingID   string
+	StartOffset int
+	EndOffset   int
+	AnchorText  string
+	Label       string
+	Note        string
+	Orphaned    bool
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+func InsertAnnotation(dbPath string, a Annotation) (Annotation, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return Annotation{}, err
+	}
+	defer conn.Close()
+
+	now := time.Now().Format(time.RFC3339)
+	a.CreatedAt = now
+	a.UpdatedAt = now
+	res, err := conn.Exec(
+		`INSERT INTO annotations(finding_id, start_offset, end_offset, anchor_text, label, note, orphaned, created_at, updated_at) VALUES(?,?,?,?,?,?,?,?,?)`,
+		strings.TrimSpace(a.FindingID),
+		a.StartOffset,
+		a.EndOffset,
+		a.AnchorText,
+		strings.TrimSpace(a.Label),
+		strings.TrimSpace(a.Note),
+		boolInt(a.Orphaned),
+		a.CreatedAt,
+		a.UpdatedAt,
+	)
+	if err != nil {
+		return Annotation{}, fmt.Errorf("insert annotation: %w", err)
+	}
+	a.ID, err = res.LastInsertId()
+	if err != nil {
+		return Annotation{}, fmt.Errorf("annotation last insert id: %w", err)
+	}
+	return a, nil
+}
+
+func ListAnnotations(dbPath string) ([]Annotation, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return listAnnotationsConn(conn)
+}
+
+func DeleteAnnotation(dbPath string, id int64) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(`DELETE FROM annotations WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete annotation: %w", err)
+	}
+	return nil
+}
+
+// ReanchorAnnotations moves offset-based annotations onto the current text.
+// An annotation keeps its range when the anchor text still matches there;
+// otherwise it is moved to the nearest occurrence of its anchor text, or
+// marked orphaned when the anchor no longer appears at all.
+func ReanchorAnnotations(dbPath, text string) ([]Annotation, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	items, err := listAnnotationsConn(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Format(time.RFC3339)
+	for i, a := range items {
+		if a.AnchorText == "" {
+			continue
+		}
+		start, ok := relocateAnchor(text, a.AnchorText, a.StartOffset)
+		moved := a
+		if ok {
+			moved.StartOffset = start
+			moved.EndOffset = start + len(a.AnchorText)
+			moved.Orphaned = false
+		} else {
+			moved.Orphaned = true
+		}
+		if moved.StartOffset == a.StartOffset && moved.EndOffset == a.EndOffset && moved.Orphaned == a.Orphaned {
+			continue
+		}
+		moved.UpdatedAt = now
+		if _, err := tx.Exec(
+			`UPDATE annotations SET start_offset = ?, end_offset = ?, orphaned = ?, updated_at = ? WHERE id = ?`,
+			moved.StartOffset, moved.EndOffset, boolInt(moved.Orphaned), moved.UpdatedAt, moved.ID,
+		); err != nil {
+			return nil, fmt.Errorf("update annotation: %w", err)
+		}
+		items[i] = moved
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return items, nil
+}
+
+func relocateAnchor(text, anchor string, previousStart int) (int, bool) {
+	if previousStart >= 0 && previousStart+len(anchor) <= len(text) && text[previousStart:previousStart+len(anchor)] == anchor {
+		return previousStart, true
+	}
+	best := -1
+	bestDistance := 0
+	for from := 0; from <= len(text); {
+		idx := strings.Index(text[from:], anchor)
+		if idx < 0 {
+			break
+		}
+		pos := from + idx
+		distance := pos - previousStart
+		if distance < 0 {
+			distance = -distance
+		}
+		if best < 0 || distance < bestDistance {
+			best = pos
+			bestDistance = distance
+		}
+		from = pos + 1
+	}
+	return best, best >= 0
+}
+
+func listAnnotationsConn(conn *sql.DB) ([]Annotation, error) {
+	rows, err := conn.Query(`SELECT id, finding_id, start_offset, end_offset, anchor_text, label, note, orphaned, created_at, updated_at FROM annotations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Annotation{}
+	for rows.Next() {
+		var a Annotation
+		var orphaned int
+		if err := rows.Scan(&a.ID, &a.FindingID, &a.StartOffset, &a.EndOffset, &a.AnchorText, &a.Label, &a.Note, &orphaned, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan annotation: %w", err)
+		}
+		a.Orphaned = orphaned != 0
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate annotations: %w", err)
+	}
+	return out, nil
+}
+
+func boolInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReanchorAnnotations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "analysis.db")
+	original := "John left the station. He said the same line every morning."
+	anchor := "the same line every morning"
+	start := len("John left the station. He said ")
+	if original[start:start+len(anchor)] != anchor {
+		t.Fatalf("test fixture offsets are wrong")
+	}
+
+	kept, err := InsertAnnotation(dbPath, Annotation{StartOffset: start, EndOffset: start + len(anchor), AnchorText: anchor, Label: "intentional repetition"})
+	if err != nil {
+		t.Fatalf("insert annotation: %v", err)
+	}
+	lost, err := InsertAnnotation(dbPath, Annotation{StartOffset: 0, EndOffset: 4, AnchorText: "John", Note: "fixed in draft 4"})
+	if err != nil {
+		t.Fatalf("insert annotation: %v", err)
+	}
+	if _, err := InsertAnnotation(dbPath, Annotation{FindingID: "issue-001", Label: "false positive"}); err != nil {
+		t.Fatalf("insert finding annotation: %v", err)
+	}
+
+	revised := "Prologue added here. Mara left the station. She said the same line every morning."
+	items, err := ReanchorAnnotations(dbPath, revised)
+	if err != nil {
+		t.Fatalf("reanchor: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 annotations, got %d", len(items))
+	}
+	for _, a := range items {
+		switch a.ID {
+		case kept.ID:
+			if a.Orphaned || revised[a.StartOffset:a.EndOffset] != anchor {
+				t.Fatalf("expected anchor to move with text, got %+v", a)
+			}
+		case lost.ID:
+			if !a.Orphaned {
+				t.Fatalf("expected annotation to be orphaned, got %+v", a)
+			}
+		default:
+			if a.FindingID != "issue-001" || a.Orphaned {
+				t.Fatalf("expected finding annotation untouched, got %+v", a)
+			}
+		}
+	}
+
+	if err := DeleteAnnotation(dbPath, lost.ID); err != nil {
+		t.Fatalf("delete annotation: %v", err)
+	}
+	count, err := CountRows(dbPath, "annotations")
+	if err != nil {
+		t.Fatalf("count annotations: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 annotations after delete, got %d", count)
+	}
+}
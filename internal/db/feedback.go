@@ -0,0 +1,105 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FeedbackLabel is a reader's verdict on one finding: whether the signal
+// that raised it was right. Metric, Value and Threshold are set when the
+// signal fired by crossing a threshold, so the threshold can be re-fit.
+type FeedbackLabel struct {
+	ID        int64
+	ProjectID string
+	RunID     string
+	FindingID string
+	Signal    string
+	Metric    string
+	Value     float64
+	Threshold float64
+	Correct   bool
+	Note      string
+	CreatedAt string
+}
+
+// RecordFeedback stores a label, replacing any earlier verdict on the same
+// finding of the same run.
+func RecordFeedback(dbPath string, label FeedbackLabel) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM feedback_labels WHERE project_id = ? AND run_id = ? AND finding_id = ?`, label.ProjectID, label.RunID, label.FindingID); err != nil {
+		return fmt.Errorf("clear feedback label: %w", err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO feedback_labels(project_id, run_id, finding_id, signal, metric, value, threshold, correct, note, created_at) VALUES(?,?,?,?,?,?,?,?,?,?)`,
+		label.ProjectID, label.RunID, label.FindingID, label.Signal, label.Metric, label.Value, label.Threshold,
+		boolInt(label.Correct), strings.TrimSpace(label.Note), time.Now().Format(time.RFC3339),
+	); err != nil {
+		return fmt.Errorf("insert feedback label: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// ListFeedback returns every label, oldest first.
+func ListFeedback(dbPath string) ([]FeedbackLabel, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(`SELECT id, project_id, run_id, finding_id, signal, metric, value, threshold, correct, note, created_at FROM feedback_labels ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query feedback labels: %w", err)
+	}
+	defer rows.Close()
+
+	out := []FeedbackLabel{}
+	for rows.Next() {
+		var l FeedbackLabel
+		var correct int
+		if err := rows.Scan(&l.ID, &l.ProjectID, &l.RunID, &l.FindingID, &l.Signal, &l.Metric, &l.Value, &l.Threshold, &correct, &l.Note, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan feedback label: %w", err)
+		}
+		l.Correct = correct != 0
+		out = append(out, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feedback labels: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteProjectFeedback removes the labels given on projectID's findings
+// and returns how many there were.
+func DeleteProjectFeedback(dbPath, projectID string) (int, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	res, err := conn.Exec(`DELETE FROM feedback_labels WHERE project_id = ?`, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("delete feedback labels: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("count deleted feedback labels: %w", err)
+	}
+	return int(n), nil
+}
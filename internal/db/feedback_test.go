@@ -0,0 +1,34 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordFeedbackReplacesEarlierVerdict(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "library.db")
+	label := FeedbackLabel{ProjectID: "p1", RunID: "run-1", FindingID: "slop-1", Signal: "monotone", Metric: "SentenceLengthSD", Value: 3.1, Threshold: 4, Correct: true}
+	if err := RecordFeedback(dbPath, label); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	label.Correct, label.Note = false, "dialogue-heavy chapter"
+	if err := RecordFeedback(dbPath, label); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := RecordFeedback(dbPath, FeedbackLabel{ProjectID: "p2", RunID: "run-1", FindingID: "w-001", Signal: "ai_window", Correct: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, err := ListFeedback(dbPath)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(labels) != 2 || labels[0].Correct || labels[0].Note != "dialogue-heavy chapter" || labels[0].Value != 3.1 {
+		t.Fatalf("expected the second verdict to replace the first, got %+v", labels)
+	}
+
+	removed, err := DeleteProjectFeedback(dbPath, "p1")
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one label removed, got %d %v", removed, err)
+	}
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Benchmark places one of a manuscript's scores among the other books in the
+// library.
+type Benchmark struct {
+	Metric string
+	Value  float64
+	// Books is how many other projects recorded the metric.
+	Books int
+	// Below and Above count the books scoring lower and higher than Value.
+	Below int
+	Above int
+}
+
+// Percentile is the share of the other books scoring lower, from 0 to 100,
+// with ties counted as half.
+func (b Benchmark) Percentile() float64 {
+	if b.Books == 0 {
+		return 0
+	}
+	ties := b.Books - b.Below - b.Above
+	return (float64(b.Below) + float64(ties)/2) * 100 / float64(b.Books)
+}
+
+// RecordLibraryScores replaces the library's scores for projectID with the
+// scores of its latest run.
+func RecordLibraryScores(dbPath, projectID, runID string, scores map[string]float64) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM library_scores WHERE project_id = ?`, projectID); err != nil {
+		return fmt.Errorf("clear library scores: %w", err)
+	}
+	now := time.Now().Format(time.RFC3339)
+	for metric, value := range scores {
+		if _, err := tx.Exec(
+			`INSERT INTO library_scores(project_id, run_id, metric, value, recorded_at) VALUES(?,?,?,?,?)`,
+			projectID, runID, metric, value, now,
+		); err != nil {
+			return fmt.Errorf("insert library score: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// LibraryBenchmarks compares scores with the other projects in the library,
+// leaving out projectID itself. Metrics no other project recorded are absent;
+// the rest come back sorted by metric name.
+func LibraryBenchmarks(dbPath, projectID string, scores map[string]float64) ([]Benchmark, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	out := []Benchmark{}
+	for metric, value := range scores {
+		b := Benchmark{Metric: metric, Value: value}
+		if err := conn.QueryRow(
+			`SELECT COUNT(*), COALESCE(SUM(value < ?), 0), COALESCE(SUM(value > ?), 0) FROM library_scores WHERE metric = ? AND project_id != ?`,
+			value, value, metric, projectID,
+		).Scan(&b.Books, &b.Below, &b.Above); err != nil {
+			return nil, fmt.Errorf("query library scores: %w", err)
+		}
+		if b.Books > 0 {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Metric < out[j].Metric })
+	return out, nil
+}
+
+// DeleteLibraryScores removes projectID from the library and returns how
+// many score rows it had.
+func DeleteLibraryScores(dbPath, projectID string) (int, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	res, err := conn.Exec(`DELETE FROM library_scores WHERE project_id = ?`, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("delete library scores: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("count deleted library scores: %w", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLibraryBenchmarksRankAgainstOtherProjects(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "library.db")
+	for i, coverage := range []float64{0.01, 0.02, 0.02, 0.08} {
+		project := string(rune('a' + i))
+		if err := RecordLibraryScores(dbPath, project, "run-1", map[string]float64{"verbatim_duplication_coverage": coverage}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	// Re-recording replaces a project's scores rather than adding a book.
+	if err := RecordLibraryScores(dbPath, "e", "run-1", map[string]float64{"verbatim_duplication_coverage": 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordLibraryScores(dbPath, "e", "run-2", map[string]float64{"verbatim_duplication_coverage": 0.05, "mhd_score": 70}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LibraryBenchmarks(dbPath, "e", map[string]float64{"verbatim_duplication_coverage": 0.05, "mhd_score": 70, "grammar_score": 90})
+	if err != nil {
+		t.Fatalf("benchmarks: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected only the metric other projects recorded, got %+v", got)
+	}
+	if b := got[0]; b.Books != 4 || b.Below != 3 || b.Above != 1 || b.Percentile() != 75 {
+		t.Fatalf("expected 0.05 above three of four books, got %+v (%.1f%%)", b, b.Percentile())
+	}
+
+	tied, err := LibraryBenchmarks(dbPath, "e", map[string]float64{"verbatim_duplication_coverage": 0.02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := tied[0].Percentile(); p != 50 {
+		t.Fatalf("expected ties counted as half, got %.1f", p)
+	}
+}
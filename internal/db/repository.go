@@ -22,7 +22,8 @@ func PersistContradictions(dbPath string, contradictions []forensics.Contradicti
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.Exec(`DELETE FROM contradictions`); err != nil {
+	// Contradictions recorded with a run (RecordRun) are history and stay.
+	if _, err := tx.Exec(`DELETE FROM contradictions WHERE run_id IS NULL OR run_id = ''`); err != nil {
 		return fmt.Errorf("clear contradictions: %w", err)
 	}
 	if _, err := tx.Exec(`DELETE FROM entities`); err != nil {
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/timeline"
+)
+
+// RunRecord is the queryable part of one analysis run: its headline numbers
+// and the per-chapter, per-character, timeline, AI window, language and
+// contradiction rows behind them. report.json stays the full report; the
+// database keeps every run so findings can be compared across runs.
+type RunRecord struct {
+	RunID        string
+	Title        string
+	SourceName   string
+	Status       string
+	Profile      string
+	WordCount    int
+	ChapterCount int
+	MHDScore     int
+	PAIDoc       *float64
+	StartedAt    string
+	CompletedAt  string
+
+	Chapters       []ChapterRecord
+	Characters     []CharacterRecord
+	Timeline       []timeline.Event
+	AIWindows      []aidetect.WindowReport
+	Language       []LanguageScore
+	Contradictions []forensics.Contradiction
+}
+
+type ChapterRecord struct {
+	Index         int
+	Title         string
+	WordCount     int
+	TimelineMarks int
+	TopGenre      string
+	TopGenreScore float64
+}
+
+type CharacterRecord struct {
+	Name             string
+	FirstSeenChapter int
+	LastSeenChapter  int
+	TotalMentions    int
+}
+
+// LanguageScore is one row of language scores. Chapter 0 is the whole book;
+// its content ratings are left zero on chapter rows.
+type LanguageScore struct {
+	Chapter     int
+	Spelling    int
+	Grammar     int
+	Readability int
+	AgeCategory string
+	Profanity   int
+	Explicit    int
+	Violence    int
+}
+
+// RunContradiction is a contradiction with the run that found it.
+type RunContradiction struct {
+	RunID       string
+	CompletedAt string
+	forensics.Contradiction
+}
+
+// runTables hold a run's child rows; RecordRun clears them before writing.
+var runTables = []string{"chapters", "characters", "timeline_events", "ai_windows", "language_scores", "contradictions"}
+
+// RecordRun stores a run and its rows. Recording a run again replaces them.
+func RecordRun(dbPath string, run RunRecord) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM runs WHERE run_id = ?`, run.RunID); err != nil {
+		return fmt.Errorf("clear run: %w", err)
+	}
+	for _, table := range runTables {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE run_id = ?`, run.RunID); err != nil {
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO runs(run_id, title, source_name, status, profile, word_count, chapter_count, mhd_score, p_ai_doc, started_at, completed_at) VALUES(?,?,?,?,?,?,?,?,?,?,?)`,
+		run.RunID, run.Title, run.SourceName, run.Status, run.Profile, run.WordCount, run.ChapterCount, run.MHDScore, nullFloat(run.PAIDoc), run.StartedAt, run.CompletedAt,
+	); err != nil {
+		return fmt.Errorf("insert run: %w", err)
+	}
+	for _, c := range run.Chapters {
+		if _, err := tx.Exec(
+			`INSERT INTO chapters(run_id, chapter_index, title, word_count, timeline_marks, top_genre, top_genre_score) VALUES(?,?,?,?,?,?,?)`,
+			run.RunID, c.Index, c.Title, c.WordCount, c.TimelineMarks, c.TopGenre, c.TopGenreScore,
+		); err != nil {
+			return fmt.Errorf("insert chapter: %w", err)
+		}
+	}
+	for _, c := range run.Characters {
+		if _, err := tx.Exec(
+			`INSERT INTO characters(run_id, name, first_seen_chapter, last_seen_chapter, total_mentions) VALUES(?,?,?,?,?)`,
+			run.RunID, c.Name, c.FirstSeenChapter, c.LastSeenChapter, c.TotalMentions,
+		); err != nil {
+			return fmt.Errorf("insert character: %w", err)
+		}
+	}
+	for i, e := range run.Timeline {
+		if _, err := tx.Exec(
+			`INSERT INTO timeline_events(run_id, position, time_marker, event) VALUES(?,?,?,?)`,
+			run.RunID, i, e.TimeMarker, e.Event,
+		); err != nil {
+			return fmt.Errorf("insert timeline event: %w", err)
+		}
+	}
+	for _, w := range run.AIWindows {
+		if _, err := tx.Exec(
+			`INSERT INTO ai_windows(run_id, window_id, start_word, end_word, p_ai, confidence, exempt) VALUES(?,?,?,?,?,?,?)`,
+			run.RunID, w.WindowID, w.StartWord, w.EndWord, w.PAI, w.Confidence, boolInt(w.Exempt),
+		); err != nil {
+			return fmt.Errorf("insert ai window: %w", err)
+		}
+	}
+	for _, l := range run.Language {
+		if _, err := tx.Exec(
+			`INSERT INTO language_scores(run_id, chapter, spelling, grammar, readability, age_category, profanity, explicit, violence) VALUES(?,?,?,?,?,?,?,?,?)`,
+			run.RunID, l.Chapter, l.Spelling, l.Grammar, l.Readability, l.AgeCategory, l.Profanity, l.Explicit, l.Violence,
+		); err != nil {
+			return fmt.Errorf("insert language score: %w", err)
+		}
+	}
+	for _, c := range run.Contradictions {
+		if _, err := tx.Exec(
+			`INSERT INTO contradictions(run_id, entity_name, attribute, value_a, value_b, chapter_a, chapter_b, description, severity) VALUES(?,?,?,?,?,?,?,?,?)`,
+			run.RunID, c.EntityName, c.Attribute, c.ValueA, c.ValueB, c.ChapterA, c.ChapterB, c.Description, c.Severity,
+		); err != nil {
+			return fmt.Errorf("insert contradiction: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the most recently recorded runs, newest first, without
+// their child rows. A limit of 0 or less returns every run.
+func ListRuns(dbPath string, limit int) ([]RunRecord, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := conn.Query(
+		`SELECT run_id, title, source_name, status, profile, word_count, chapter_count, mhd_score, p_ai_doc, started_at, completed_at
+		FROM runs ORDER BY id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []RunRecord{}
+	for rows.Next() {
+		var r RunRecord
+		var pAI sql.NullFloat64
+		if err := rows.Scan(&r.RunID, &r.Title, &r.SourceName, &r.Status, &r.Profile, &r.WordCount, &r.ChapterCount, &r.MHDScore, &pAI, &r.StartedAt, &r.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scan run: %w", err)
+		}
+		r.PAIDoc = floatOrNil(pAI)
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate runs: %w", err)
+	}
+	return out, nil
+}
+
+// ListContradictions returns the contradictions found by the last lastRuns
+// recorded runs, newest run first. An empty severity matches every severity;
+// otherwise it is compared without regard to case. A lastRuns of 0 or less
+// covers every run.
+func ListContradictions(dbPath, severity string, lastRuns int) ([]RunContradiction, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if lastRuns <= 0 {
+		lastRuns = -1
+	}
+	rows, err := conn.Query(
+		`SELECT c.run_id, r.completed_at, c.entity_name, c.attribute, c.value_a, c.value_b, c.chapter_a, c.chapter_b, c.description, c.severity
+		FROM contradictions c JOIN (SELECT id, run_id, completed_at FROM runs ORDER BY id DESC LIMIT ?) r ON r.run_id = c.run_id
+		WHERE ? = '' OR UPPER(c.severity) = ?
+		ORDER BY r.id DESC, c.id`,
+		lastRuns, severity, strings.ToUpper(severity),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query contradictions: %w", err)
+	}
+	defer rows.Close()
+
+	out := []RunContradiction{}
+	for rows.Next() {
+		var c RunContradiction
+		if err := rows.Scan(&c.RunID, &c.CompletedAt, &c.EntityName, &c.Attribute, &c.ValueA, &c.ValueB, &c.ChapterA, &c.ChapterB, &c.Description, &c.Severity); err != nil {
+			return nil, fmt.Errorf("scan contradiction: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate contradictions: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+	"book_dashboard/internal/forensics"
+	"book_dashboard/internal/timeline"
+)
+
+func TestRecordRunKeepsHistoryQueryableAcrossRuns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "analysis.db")
+	for i := 1; i <= 6; i++ {
+		pAI := 0.1 * float64(i)
+		run := RunRecord{
+			RunID:        fmt.Sprintf("run-%d", i),
+			Title:        "Ice Shelf",
+			Status:       "DONE",
+			WordCount:    1000 * i,
+			ChapterCount: 2,
+			MHDScore:     70 + i,
+			PAIDoc:       &pAI,
+			Chapters:     []ChapterRecord{{Index: 1, Title: "One", WordCount: 500}, {Index: 2, Title: "Two", WordCount: 500}},
+			Characters:   []CharacterRecord{{Name: "Mara", FirstSeenChapter: 1, LastSeenChapter: 2, TotalMentions: 12}},
+			Timeline:     []timeline.Event{{TimeMarker: "next day", Event: "They reach the station."}},
+			AIWindows:    []aidetect.WindowReport{{WindowID: "w-000", EndWord: 900, PAI: 0.4, Confidence: 0.8}},
+			Language:     []LanguageScore{{Chapter: 0, Spelling: 90, Grammar: 85, Readability: 70}},
+			Contradictions: []forensics.Contradiction{
+				{EntityName: "Mara", Attribute: "eyes", ValueA: "blue", ValueB: "green", ChapterA: 1, ChapterB: 2, Severity: "HIGH"},
+				{EntityName: "Joss", Attribute: "age", ValueA: "30", ValueB: "31", ChapterA: 1, ChapterB: 2, Severity: "low"},
+			},
+		}
+		if err := RecordRun(dbPath, run); err != nil {
+			t.Fatalf("record %s: %v", run.RunID, err)
+		}
+	}
+	// Recording a run again replaces its rows instead of adding to them.
+	if err := RecordRun(dbPath, RunRecord{RunID: "run-6", Status: "DONE", Contradictions: []forensics.Contradiction{{EntityName: "Mara", Severity: "high"}}}); err != nil {
+		t.Fatalf("record again: %v", err)
+	}
+
+	got, err := ListContradictions(dbPath, "high", 5)
+	if err != nil {
+		t.Fatalf("list contradictions: %v", err)
+	}
+	if len(got) != 5 || got[0].RunID != "run-6" || got[4].RunID != "run-2" {
+		t.Fatalf("expected one HIGH contradiction from each of the last five runs, newest first, got %+v", got)
+	}
+	if got[1].Attribute != "eyes" || got[1].ValueB != "green" {
+		t.Fatalf("expected the contradiction fields back intact, got %+v", got[1])
+	}
+	if all, err := ListContradictions(dbPath, "", 0); err != nil || len(all) != 11 {
+		t.Fatalf("expected every contradiction of every run, got %d %v", len(all), err)
+	}
+
+	runs, err := ListRuns(dbPath, 2)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].RunID != "run-6" || runs[0].PAIDoc != nil || runs[1].PAIDoc == nil || runs[1].MHDScore != 75 {
+		t.Fatalf("expected the two newest runs, got %+v", runs)
+	}
+	for table, want := range map[string]int{"runs": 6, "chapters": 10, "characters": 5, "timeline_events": 5, "ai_windows": 5, "language_scores": 5} {
+		if n, err := CountRows(dbPath, table); err != nil || n != want {
+			t.Fatalf("expected %d rows in %s, got %d %v", want, table, n, err)
+		}
+	}
+
+	// The unscoped contradiction set is replaced without touching run history.
+	if err := PersistContradictions(dbPath, []forensics.Contradiction{{EntityName: "Mara", Severity: "HIGH"}}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if after, err := ListContradictions(dbPath, "HIGH", 5); err != nil || len(after) != 5 {
+		t.Fatalf("expected run contradictions kept, got %d %v", len(after), err)
+	}
+}
+
+func TestOpenAddsRunColumnsToOlderContradictionsTable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "analysis.db")
+	old, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := old.Exec(`CREATE TABLE contradictions (id INTEGER PRIMARY KEY, entity_id INTEGER, chapter_a INTEGER, chapter_b INTEGER, description TEXT, severity TEXT)`); err != nil {
+		t.Fatalf("create old table: %v", err)
+	}
+	if _, err := old.Exec(`INSERT INTO contradictions(entity_id, chapter_a, chapter_b, description, severity) VALUES(1, 1, 2, 'old', 'HIGH')`); err != nil {
+		t.Fatalf("insert old row: %v", err)
+	}
+	old.Close()
+
+	if err := RecordRun(dbPath, RunRecord{RunID: "run-1", Contradictions: []forensics.Contradiction{{EntityName: "Mara", Severity: "HIGH"}}}); err != nil {
+		t.Fatalf("record on an older database: %v", err)
+	}
+	if n, err := CountRows(dbPath, "contradictions"); err != nil || n != 2 {
+		t.Fatalf("expected the old row kept beside the new one, got %d %v", n, err)
+	}
+	if got, err := ListContradictions(dbPath, "HIGH", 1); err != nil || len(got) != 1 || got[0].EntityName != "Mara" {
+		t.Fatalf("expected only the run's contradiction, got %+v %v", got, err)
+	}
+}
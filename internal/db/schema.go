@@ -21,10 +21,155 @@ CREATE TABLE IF NOT EXISTS contradictions (
     chapter_a INTEGER,
     chapter_b INTEGER,
     description TEXT,
-    severity TEXT
+    severity TEXT,
+    run_id TEXT,
+    entity_name TEXT,
+    attribute TEXT,
+    value_a TEXT,
+    value_b TEXT
+);
+
+CREATE TABLE IF NOT EXISTS annotations (
+    id INTEGER PRIMARY KEY,
+    finding_id TEXT,
+    start_offset INTEGER,
+    end_offset INTEGER,
+    anchor_text TEXT,
+    label TEXT,
+    note TEXT,
+    orphaned INTEGER DEFAULT 0,
+    created_at TEXT,
+    updated_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS stage_timings (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    stage TEXT,
+    duration_ms INTEGER,
+    word_count INTEGER,
+    recorded_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS window_signals (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    window_id TEXT,
+    start_word INTEGER,
+    end_word INTEGER,
+    has_evidence INTEGER DEFAULT 0,
+    duplication REAL,
+    longest_duplicate INTEGER,
+    semantic_duplication REAL,
+    lm_smoothness REAL,
+    style_uniformity REAL,
+    polish_cliche REAL,
+    language_tool REAL,
+    exempt INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS runs (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT UNIQUE,
+    title TEXT,
+    source_name TEXT,
+    status TEXT,
+    profile TEXT,
+    word_count INTEGER,
+    chapter_count INTEGER,
+    mhd_score INTEGER,
+    p_ai_doc REAL,
+    started_at TEXT,
+    completed_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS chapters (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    chapter_index INTEGER,
+    title TEXT,
+    word_count INTEGER,
+    timeline_marks INTEGER,
+    top_genre TEXT,
+    top_genre_score REAL
+);
+
+CREATE TABLE IF NOT EXISTS characters (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    name TEXT,
+    first_seen_chapter INTEGER,
+    last_seen_chapter INTEGER,
+    total_mentions INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS timeline_events (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    position INTEGER,
+    time_marker TEXT,
+    event TEXT
+);
+
+CREATE TABLE IF NOT EXISTS ai_windows (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    window_id TEXT,
+    start_word INTEGER,
+    end_word INTEGER,
+    p_ai REAL,
+    confidence REAL,
+    exempt INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS language_scores (
+    id INTEGER PRIMARY KEY,
+    run_id TEXT,
+    chapter INTEGER,
+    spelling INTEGER,
+    grammar INTEGER,
+    readability INTEGER,
+    age_category TEXT,
+    profanity INTEGER,
+    explicit INTEGER,
+    violence INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS feedback_labels (
+    id INTEGER PRIMARY KEY,
+    project_id TEXT,
+    run_id TEXT,
+    finding_id TEXT,
+    signal TEXT,
+    metric TEXT,
+    value REAL,
+    threshold REAL,
+    correct INTEGER,
+    note TEXT,
+    created_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS library_scores (
+    id INTEGER PRIMARY KEY,
+    project_id TEXT,
+    run_id TEXT,
+    metric TEXT,
+    value REAL,
+    recorded_at TEXT
 );
 `
 
+// schemaColumns are columns added to tables after their first release.
+// CREATE TABLE IF NOT EXISTS leaves an existing table alone, so Open adds
+// any that are missing.
+var schemaColumns = []struct{ table, column, decl string }{
+	{"contradictions", "run_id", "TEXT"},
+	{"contradictions", "entity_name", "TEXT"},
+	{"contradictions", "attribute", "TEXT"},
+	{"contradictions", "value_a", "TEXT"},
+	{"contradictions", "value_b", "TEXT"},
+}
+
 func Open(path string) (*sql.DB, error) {
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
@@ -34,5 +179,49 @@ func Open(path string) (*sql.DB, error) {
 		_ = db.Close()
 		return nil, fmt.Errorf("apply schema: %w", err)
 	}
+	if err := addMissingColumns(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 	return db, nil
 }
+
+func addMissingColumns(db *sql.DB) error {
+	existing := map[string]map[string]bool{}
+	for _, c := range schemaColumns {
+		if existing[c.table] == nil {
+			columns, err := tableColumns(db, c.table)
+			if err != nil {
+				return err
+			}
+			existing[c.table] = columns
+		}
+		if existing[c.table][c.column] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + c.table + ` ADD COLUMN ` + c.column + ` ` + c.decl); err != nil {
+			return fmt.Errorf("add column %s.%s: %w", c.table, c.column, err)
+		}
+		existing[c.table][c.column] = true
+	}
+	return nil
+}
+
+func tableColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(`PRAGMA table_info(` + table + `)`)
+	if err != nil {
+		return nil, fmt.Errorf("read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+	columns := map[string]bool{}
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, kind string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return nil, fmt.Errorf("scan columns of %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
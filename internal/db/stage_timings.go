@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// StageDuration is how long one analysis stage took in one run.
+type StageDuration struct {
+	Stage      string
+	DurationMs int64
+}
+
+// RecordStageTimings stores a run's stage durations with the manuscript word
+// count so later runs can weight their progress by how long stages really take.
+func RecordStageTimings(dbPath, runID string, wordCount int, timings []StageDuration) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Format(time.RFC3339)
+	for _, t := range timings {
+		if _, err := tx.Exec(
+			`INSERT INTO stage_timings(run_id, stage, duration_ms, word_count, recorded_at) VALUES(?,?,?,?,?)`,
+			runID, t.Stage, t.DurationMs, wordCount, now,
+		); err != nil {
+			return fmt.Errorf("insert stage timing: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// StageRates averages each stage's duration in milliseconds per 1,000 words
+// over the most recent runs. Stages never recorded are absent from the map.
+func StageRates(dbPath string, recentRuns int) (map[string]float64, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(
+		`SELECT stage, AVG(duration_ms * 1000.0 / MAX(word_count, 1)) FROM stage_timings
+		WHERE run_id IN (SELECT run_id FROM stage_timings GROUP BY run_id ORDER BY MAX(id) DESC LIMIT ?)
+		GROUP BY stage`,
+		recentRuns,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query stage timings: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[string]float64{}
+	for rows.Next() {
+		var stage string
+		var rate float64
+		if err := rows.Scan(&stage, &rate); err != nil {
+			return nil, fmt.Errorf("scan stage timing: %w", err)
+		}
+		out[stage] = rate
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stage timings: %w", err)
+	}
+	return out, nil
+}
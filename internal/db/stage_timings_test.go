@@ -0,0 +1,35 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestStageRatesAverageRecentRunsPerThousandWords(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "analysis.db")
+	// An old, slow run that falls outside the recent window.
+	if err := RecordStageTimings(dbPath, "run-0", 1000, []StageDuration{{Stage: "AI", DurationMs: 90000}}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		err := RecordStageTimings(dbPath, fmt.Sprintf("run-%d", i), 2000*i, []StageDuration{
+			{Stage: "AI", DurationMs: int64(4000 * i)},
+			{Stage: "LANGUAGE", DurationMs: int64(1000 * i)},
+		})
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	rates, err := StageRates(dbPath, 2)
+	if err != nil {
+		t.Fatalf("stage rates: %v", err)
+	}
+	if rates["AI"] != 2000 || rates["LANGUAGE"] != 500 {
+		t.Fatalf("expected AI=2000 LANGUAGE=500 ms per 1k words, got %+v", rates)
+	}
+	if _, ok := rates["SLOP"]; ok {
+		t.Fatalf("expected unrecorded stage to be absent, got %+v", rates)
+	}
+}
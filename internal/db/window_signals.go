@@ -0,0 +1,94 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"book_dashboard/internal/aidetect"
+)
+
+// RecordWindowSignals stores the raw AI-detection signals of a run's windows
+// so the run can be re-scored under new weights without the model calls that
+// produced them. Recording a run again replaces its rows.
+func RecordWindowSignals(dbPath, runID string, windows []aidetect.WindowInputs) error {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM window_signals WHERE run_id = ?`, runID); err != nil {
+		return fmt.Errorf("clear window signals: %w", err)
+	}
+	for _, w := range windows {
+		if _, err := tx.Exec(
+			`INSERT INTO window_signals(run_id, window_id, start_word, end_word, has_evidence, duplication, longest_duplicate, semantic_duplication, lm_smoothness, style_uniformity, polish_cliche, language_tool, exempt) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+			runID, w.WindowID, w.StartWord, w.EndWord, boolInt(w.HasEvidence), w.Duplication, w.LongestDuplicate,
+			nullFloat(w.SemanticDuplication), nullFloat(w.LMSmoothness), w.StyleUniformity, w.PolishCliche, nullFloat(w.LanguageTool), boolInt(w.Exempt),
+		); err != nil {
+			return fmt.Errorf("insert window signals: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// LoadWindowSignals returns the windows recorded for runID in window order,
+// or none when the run recorded no signals.
+func LoadWindowSignals(dbPath, runID string) ([]aidetect.WindowInputs, error) {
+	conn, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(
+		`SELECT window_id, start_word, end_word, has_evidence, duplication, longest_duplicate, semantic_duplication, lm_smoothness, style_uniformity, polish_cliche, language_tool, exempt
+		FROM window_signals WHERE run_id = ? ORDER BY id`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query window signals: %w", err)
+	}
+	defer rows.Close()
+
+	out := []aidetect.WindowInputs{}
+	for rows.Next() {
+		var w aidetect.WindowInputs
+		var hasEvidence, exempt int
+		var semantic, lm, lt sql.NullFloat64
+		if err := rows.Scan(&w.WindowID, &w.StartWord, &w.EndWord, &hasEvidence, &w.Duplication, &w.LongestDuplicate, &semantic, &lm, &w.StyleUniformity, &w.PolishCliche, &lt, &exempt); err != nil {
+			return nil, fmt.Errorf("scan window signals: %w", err)
+		}
+		w.HasEvidence, w.Exempt = hasEvidence != 0, exempt != 0
+		w.SemanticDuplication, w.LMSmoothness, w.LanguageTool = floatOrNil(semantic), floatOrNil(lm), floatOrNil(lt)
+		out = append(out, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate window signals: %w", err)
+	}
+	return out, nil
+}
+
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+func floatOrNil(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
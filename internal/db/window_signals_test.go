@@ -0,0 +1,36 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"book_dashboard/internal/aidetect"
+)
+
+func TestWindowSignalsRoundTripPerRun(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "analysis.db")
+	lm := 0.7
+	windows := []aidetect.WindowInputs{
+		{WindowID: "w_0001", StartWord: 0, EndWord: 900, HasEvidence: true, Duplication: 0.8, LongestDuplicate: 320, LMSmoothness: &lm, StyleUniformity: 0.4, PolishCliche: 0.2},
+		{WindowID: "w_0002", StartWord: 450, EndWord: 1350, StyleUniformity: 0.3, Exempt: true},
+	}
+	if err := RecordWindowSignals(dbPath, "run-1", windows); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := RecordWindowSignals(dbPath, "run-1", windows); err != nil {
+		t.Fatalf("record again: %v", err)
+	}
+	got, err := LoadWindowSignals(dbPath, "run-1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != 2 || got[0].LongestDuplicate != 320 || !got[0].HasEvidence || got[0].LMSmoothness == nil || *got[0].LMSmoothness != 0.7 {
+		t.Fatalf("expected the first window back intact, got %+v", got)
+	}
+	if got[1].LMSmoothness != nil || got[1].LanguageTool != nil || !got[1].Exempt {
+		t.Fatalf("expected missing signals to stay nil, got %+v", got[1])
+	}
+	if other, err := LoadWindowSignals(dbPath, "run-2"); err != nil || len(other) != 0 {
+		t.Fatalf("expected no signals for another run, got %+v %v", other, err)
+	}
+}
@@ -97,7 +97,7 @@ func severityFor(attribute string) string {
 	switch attribute {
 	case "dead", "alive":
 		return "HIGH"
-	case "age", "name", "eyes":
+	case "age", "name", "eyes", "residence", "birthplace":
 		return "MED"
 	default:
 		return "LOW"
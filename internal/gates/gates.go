@@ -0,0 +1,185 @@
+// Package gates evaluates a report's scores against submission rules ("no
+// HIGH contradictions", "p_ai_doc < 0.5", "grammar >= 70"), so the app's
+// submission checklist and the mhd command agree on what passes.
+package gates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Metrics a rule can test. Reports name them in their quality_metrics.
+const (
+	MetricMHDScore           = "mhd_score"
+	MetricPAIDoc             = "p_ai_doc"
+	MetricAISuspicion        = "ai_suspicion_score"
+	MetricGrammar            = "grammar_score"
+	MetricSpelling           = "spelling_score"
+	MetricReadability        = "readability_score"
+	MetricContradictions     = "contradictions"
+	MetricHighContradictions = "contradictions_high"
+	MetricMedContradictions  = "contradictions_med"
+	MetricLowContradictions  = "contradictions_low"
+)
+
+var metricNames = map[string]string{
+	MetricMHDScore:           "MHD score",
+	MetricPAIDoc:             "document AI probability",
+	MetricAISuspicion:        "AI suspicion score",
+	MetricGrammar:            "grammar score",
+	MetricSpelling:           "spelling score",
+	MetricReadability:        "readability score",
+	MetricContradictions:     "contradictions",
+	MetricHighContradictions: "HIGH contradictions",
+	MetricMedContradictions:  "MED contradictions",
+	MetricLowContradictions:  "LOW contradictions",
+}
+
+// Rule is one gate: the metric must compare to Value with Op, one of <, <=,
+// >, >= and ==.
+type Rule struct {
+	ID     string  `json:"id"`
+	Label  string  `json:"label,omitempty"`
+	Metric string  `json:"metric"`
+	Op     string  `json:"op"`
+	Value  float64 `json:"value"`
+}
+
+// Metrics are a report's measured values by metric name. A metric that was
+// not measured (AI detection turned off, say) is absent.
+type Metrics map[string]float64
+
+// Result is a rule's verdict on one report.
+type Result struct {
+	ID        string   `json:"id"`
+	Label     string   `json:"label"`
+	Metric    string   `json:"metric"`
+	Op        string   `json:"op"`
+	Threshold float64  `json:"threshold"`
+	Value     *float64 `json:"value"`
+	// Skipped is set when the report did not measure the metric; a skipped
+	// gate does not fail the report.
+	Skipped     bool   `json:"skipped"`
+	Passed      bool   `json:"passed"`
+	Explanation string `json:"explanation"`
+}
+
+// DefaultRules are the gates used when a project configures none.
+func DefaultRules() []Rule {
+	return []Rule{
+		{ID: "no-high-contradictions", Label: "No HIGH contradictions", Metric: MetricHighContradictions, Op: "==", Value: 0},
+		{ID: "ai-probability", Label: "Document AI probability below 0.5", Metric: MetricPAIDoc, Op: "<", Value: 0.5},
+		{ID: "grammar", Label: "Grammar score at least 70", Metric: MetricGrammar, Op: ">=", Value: 70},
+	}
+}
+
+// Validate reports the first rule that names an unknown metric or operator.
+func Validate(rules []Rule) error {
+	for i, r := range rules {
+		if _, ok := metricNames[r.Metric]; !ok {
+			return fmt.Errorf("gate %d (%s): unknown metric %q", i+1, r.ID, r.Metric)
+		}
+		if _, ok := compare(r.Op, 0, 0); !ok {
+			return fmt.Errorf("gate %d (%s): unknown operator %q", i+1, r.ID, r.Op)
+		}
+	}
+	return nil
+}
+
+// LoadRules reads a JSON array of rules from path.
+func LoadRules(path string) ([]Rule, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read gates: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("decode gates: %w", err)
+	}
+	if err := Validate(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Evaluate checks metrics against each rule in order.
+func Evaluate(metrics Metrics, rules []Rule) []Result {
+	out := make([]Result, 0, len(rules))
+	for _, r := range rules {
+		res := Result{ID: r.ID, Label: r.Label, Metric: r.Metric, Op: r.Op, Threshold: r.Value}
+		if res.Label == "" {
+			res.Label = fmt.Sprintf("%s %s %s", metricName(r.Metric), r.Op, FormatValue(r.Metric, r.Value))
+		}
+		value, measured := metrics[r.Metric]
+		passed, known := compare(r.Op, value, r.Value)
+		switch {
+		case !known:
+			res.Explanation = fmt.Sprintf("Unknown operator %q; the gate fails until it is fixed.", r.Op)
+		case !measured:
+			res.Skipped, res.Passed = true, true
+			res.Explanation = fmt.Sprintf("Not measured: this run has no %s.", metricName(r.Metric))
+		default:
+			res.Value = &value
+			res.Passed = passed
+			verdict := "meets"
+			if !passed {
+				verdict = "fails"
+			}
+			res.Explanation = fmt.Sprintf("%s is %s, which %s %s %s.", capitalize(metricName(r.Metric)), FormatValue(r.Metric, value), verdict, r.Op, FormatValue(r.Metric, r.Value))
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// Passed reports whether every gate passed.
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func compare(op string, value, limit float64) (bool, bool) {
+	switch op {
+	case "<":
+		return value < limit, true
+	case "<=":
+		return value <= limit, true
+	case ">":
+		return value > limit, true
+	case ">=":
+		return value >= limit, true
+	case "==":
+		return value == limit, true
+	}
+	return false, false
+}
+
+func metricName(metric string) string {
+	if name, ok := metricNames[metric]; ok {
+		return name
+	}
+	return metric
+}
+
+// FormatValue prints probabilities with two decimals and scores and counts
+// as whole numbers.
+func FormatValue(metric string, v float64) string {
+	if metric == MetricPAIDoc {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func capitalize(s string) string {
+	if s == "" || strings.ToUpper(s[:1]) == s[:1] {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
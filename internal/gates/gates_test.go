@@ -0,0 +1,51 @@
+package gates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExplainsEachDefaultGate(t *testing.T) {
+	results := Evaluate(Metrics{MetricHighContradictions: 2, MetricGrammar: 82}, DefaultRules())
+	if len(results) != 3 {
+		t.Fatalf("expected one result per default gate, got %+v", results)
+	}
+	contradictions, ai, grammar := results[0], results[1], results[2]
+	if contradictions.Passed || contradictions.Explanation != "HIGH contradictions is 2, which fails == 0." {
+		t.Fatalf("expected the HIGH contradiction gate to fail with its value, got %+v", contradictions)
+	}
+	if !ai.Passed || !ai.Skipped || !strings.Contains(ai.Explanation, "Not measured") {
+		t.Fatalf("expected an unmeasured AI probability skipped, got %+v", ai)
+	}
+	if !grammar.Passed || grammar.Value == nil || *grammar.Value != 82 {
+		t.Fatalf("expected grammar 82 to pass >= 70, got %+v", grammar)
+	}
+	if Passed(results) {
+		t.Fatal("expected a failed gate to fail the report")
+	}
+}
+
+func TestLoadRulesRejectsUnknownMetrics(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	if err := os.WriteFile(good, []byte(`[{"id":"mhd","metric":"mhd_score","op":">=","value":60}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(good)
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("load: %v %+v", err, rules)
+	}
+	if got := Evaluate(Metrics{MetricMHDScore: 55}, rules)[0]; got.Passed || got.Label != "MHD score >= 60" {
+		t.Fatalf("expected a generated label and a failure, got %+v", got)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`[{"id":"x","metric":"charm","op":">","value":1}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRules(bad); err == nil || !strings.Contains(err.Error(), "charm") {
+		t.Fatalf("expected the unknown metric named, got %v", err)
+	}
+}
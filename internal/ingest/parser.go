@@ -18,6 +18,12 @@ type Parsed struct {
 	SourcePath  string
 	SourceBytes []byte
 	Text        string
+	// Pages maps a PDF's pages to byte ranges of Text, for showing where
+	// evidence sits; it is empty for other formats.
+	Pages []PageSpan
+	// Highlights is the text of each highlighted DOCX passage, in document
+	// order; authors often highlight placeholders they mean to fill in.
+	Highlights []string
 }
 
 func ParseFile(path string) (*Parsed, error) {
@@ -28,17 +34,23 @@ func ParseFile(path string) (*Parsed, error) {
 
 	ext := strings.ToLower(filepath.Ext(path))
 	var text string
+	var highlights []string
+	var pages []PageSpan
 	switch ext {
 	case ".docx":
-		text, err = parseDOCX(raw)
+		text, highlights, err = parseDOCX(raw)
 		if err != nil {
 			return nil, err
 		}
+		text = normalizeWhitespace(text)
 	case ".pdf":
-		text, err = parsePDF(path)
-		if err != nil {
-			return nil, err
+		pageTexts, pdfErr := parsePDF(path)
+		if pdfErr != nil {
+			return nil, pdfErr
 		}
+		// Reconstructed text is already normalized; normalizing it again
+		// would be a no-op, and the page offsets depend on it staying as is.
+		text, pages = reconstructParagraphs(pageTexts)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
@@ -48,14 +60,19 @@ func ParseFile(path string) (*Parsed, error) {
 		Title:       title,
 		SourcePath:  path,
 		SourceBytes: raw,
-		Text:        normalizeWhitespace(text),
+		Text:        text,
+		Pages:       pages,
+		Highlights:  highlights,
 	}, nil
 }
 
-func parseDOCX(raw []byte) (string, error) {
+// parseDOCX returns the document text and its highlighted passages. A run
+// counts as highlighted when its properties set a highlight colour or a
+// shading fill; adjacent highlighted runs in a paragraph form one passage.
+func parseDOCX(raw []byte) (string, []string, error) {
 	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
 	if err != nil {
-		return "", fmt.Errorf("open docx zip: %w", err)
+		return "", nil, fmt.Errorf("open docx zip: %w", err)
 	}
 
 	var xmlData []byte
@@ -63,41 +80,55 @@ func parseDOCX(raw []byte) (string, error) {
 		if f.Name == "word/document.xml" {
 			rc, openErr := f.Open()
 			if openErr != nil {
-				return "", fmt.Errorf("open document.xml: %w", openErr)
+				return "", nil, fmt.Errorf("open document.xml: %w", openErr)
 			}
 			defer rc.Close()
 			xmlData, err = io.ReadAll(rc)
 			if err != nil {
-				return "", fmt.Errorf("read document.xml: %w", err)
+				return "", nil, fmt.Errorf("read document.xml: %w", err)
 			}
 			break
 		}
 	}
 	if len(xmlData) == 0 {
-		return "", fmt.Errorf("word/document.xml not found")
+		return "", nil, fmt.Errorf("word/document.xml not found")
 	}
 
 	decoder := xml.NewDecoder(bytes.NewReader(xmlData))
 	var b strings.Builder
 	inText := false
+	highlighted := false
+	var passage strings.Builder
+	highlights := []string{}
+	flush := func() {
+		if h := strings.Join(strings.Fields(passage.String()), " "); h != "" {
+			highlights = append(highlights, h)
+		}
+		passage.Reset()
+	}
 	for {
 		tok, tokenErr := decoder.Token()
 		if tokenErr == io.EOF {
 			break
 		}
 		if tokenErr != nil {
-			return "", fmt.Errorf("decode document.xml: %w", tokenErr)
+			return "", nil, fmt.Errorf("decode document.xml: %w", tokenErr)
 		}
 
 		switch t := tok.(type) {
 		case xml.StartElement:
-			if t.Name.Local == "t" {
+			switch t.Name.Local {
+			case "t":
 				inText = true
-			}
-			if t.Name.Local == "p" {
+			case "p":
+				flush()
 				if b.Len() > 0 {
 					b.WriteString("\n")
 				}
+			case "r":
+				highlighted = false
+			case "highlight", "shd":
+				highlighted = highlighted || runHighlighted(t)
 			}
 		case xml.EndElement:
 			if t.Name.Local == "t" {
@@ -106,37 +137,60 @@ func parseDOCX(raw []byte) (string, error) {
 		case xml.CharData:
 			if inText {
 				b.WriteString(string(t))
+				if highlighted {
+					passage.WriteString(string(t))
+				} else {
+					flush()
+				}
 			}
 		}
 	}
-	return b.String(), nil
+	flush()
+	return b.String(), highlights, nil
 }
 
-func parsePDF(path string) (string, error) {
+// runHighlighted reads a w:highlight or w:shd run property; "none", "auto"
+// and white fills are not highlighting.
+func runHighlighted(el xml.StartElement) bool {
+	for _, attr := range el.Attr {
+		if (el.Name.Local == "highlight" && attr.Name.Local == "val") || (el.Name.Local == "shd" && attr.Name.Local == "fill") {
+			switch strings.ToLower(attr.Value) {
+			case "", "none", "auto", "ffffff", "white":
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// parsePDF returns the plain text of each page, in page order. Pages
+// without extractable text are kept empty so page numbers stay right.
+func parsePDF(path string) ([]string, error) {
 	f, r, err := pdf.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("open pdf: %w", err)
+		return nil, fmt.Errorf("open pdf: %w", err)
 	}
 	defer f.Close()
 
-	var b strings.Builder
 	total := r.NumPage()
+	pages := make([]string, 0, total)
+	found := false
 	for i := 1; i <= total; i++ {
 		p := r.Page(i)
-		if p.V.IsNull() {
-			continue
-		}
-		content, pageErr := p.GetPlainText(nil)
-		if pageErr != nil {
-			continue
+		content := ""
+		if !p.V.IsNull() {
+			if text, pageErr := p.GetPlainText(nil); pageErr == nil {
+				content = text
+			}
 		}
-		b.WriteString(content)
-		b.WriteString("\n")
+		found = found || strings.TrimSpace(content) != ""
+		pages = append(pages, content)
 	}
-	if b.Len() == 0 {
-		return "", fmt.Errorf("no extractable text found in pdf")
+	if !found {
+		return nil, fmt.Errorf("no extractable text found in pdf")
 	}
-	return b.String(), nil
+	return pages, nil
 }
 
 func normalizeWhitespace(text string) string {
@@ -10,7 +10,7 @@ import (
 
 func TestParseDOCX(t *testing.T) {
 	raw := buildDOCX(t, `<w:document><w:body><w:p><w:r><w:t>Chapter 1</w:t></w:r></w:p><w:p><w:r><w:t>Hello world.</w:t></w:r></w:p></w:body></w:document>`)
-	got, err := parseDOCX(raw)
+	got, _, err := parseDOCX(raw)
 	if err != nil {
 		t.Fatalf("parseDOCX failed: %v", err)
 	}
@@ -19,6 +19,20 @@ func TestParseDOCX(t *testing.T) {
 	}
 }
 
+func TestParseDOCXCollectsHighlightedPassages(t *testing.T) {
+	raw := buildDOCX(t, `<w:document><w:body>`+
+		`<w:p><w:r><w:t xml:space="preserve">She met </w:t></w:r><w:r><w:rPr><w:highlight w:val="yellow"/></w:rPr><w:t>[insert </w:t></w:r><w:r><w:rPr><w:highlight w:val="yellow"/></w:rPr><w:t>name]</w:t></w:r><w:r><w:t xml:space="preserve"> at the dock.</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:rPr><w:shd w:fill="auto"/></w:rPr><w:t>Plain shading.</w:t></w:r><w:r><w:rPr><w:shd w:fill="FFFF00"/></w:rPr><w:t>Check the date</w:t></w:r></w:p>`+
+		`</w:body></w:document>`)
+	_, highlights, err := parseDOCX(raw)
+	if err != nil {
+		t.Fatalf("parseDOCX failed: %v", err)
+	}
+	if len(highlights) != 2 || highlights[0] != "[insert name]" || highlights[1] != "Check the date" {
+		t.Fatalf("unexpected highlights: %q", highlights)
+	}
+}
+
 func TestParseFileUnsupported(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "sample.txt")
 	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
@@ -0,0 +1,240 @@
+package ingest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// A line shorter than shortLineShare of the document's typical line
+	// length ends its paragraph (or is a heading).
+	shortLineShare = 0.75
+	// typicalLinePercentile picks the typical full line among the line
+	// lengths, above the short last lines of paragraphs.
+	typicalLinePercentile = 0.75
+	// Extracted lines longer than paragraphLineRunes are whole paragraphs
+	// already, so each one is kept as its own paragraph.
+	paragraphLineRunes = 150
+	// minIndentSpaces of leading whitespace mark a first-line indent.
+	minIndentSpaces = 2
+)
+
+var (
+	pageNumberLine = regexp.MustCompile(`(?i)^(?:page\s+)?[-–—]?\s*\d{1,4}\s*[-–—]?$|^(?:page\s+)?\d{1,4}\s+of\s+\d{1,4}$`)
+	headingLine    = regexp.MustCompile(`(?i)^(?:chapter|part|book|prologue|epilogue|interlude)\b`)
+)
+
+// PageSpan is the byte range of Parsed.Text that came from one PDF page.
+// Pages are numbered from 1 in document order; a paragraph running over a
+// page break belongs to both.
+type PageSpan struct {
+	Page  int
+	Start int
+	End   int
+}
+
+// PageAt returns the page holding the byte offset, or 0 when the text did
+// not come from a PDF or the offset is outside every page.
+func PageAt(pages []PageSpan, offset int) int {
+	i := sort.Search(len(pages), func(i int) bool { return pages[i].End > offset })
+	if i < len(pages) && pages[i].Start <= offset {
+		return pages[i].Page
+	}
+	return 0
+}
+
+// reconstructParagraphs joins the wrapped lines of extracted PDF pages back
+// into paragraphs, one per output line. A paragraph ends at a blank line, a
+// line much shorter than the document's full lines, or before an indented
+// line, a heading or a new line of dialogue; page numbers alone on the first
+// or last line of a page are dropped, and a paragraph may continue across a
+// page break. Pages extracted one text run per line are glued back first. Words hyphenated at a line end are rejoined. The returned text
+// is already whitespace-normalized, and pages holds each page's byte range
+// in it.
+func reconstructParagraphs(pageTexts []string) (string, []PageSpan) {
+	pages := make([][]string, len(pageTexts))
+	lengths := []int{}
+	indented := 0
+	for i, text := range pageTexts {
+		pages[i] = pageLines(text)
+		for _, line := range pages[i] {
+			if n := utf8.RuneCountInString(strings.TrimSpace(line)); n > 0 {
+				lengths = append(lengths, n)
+				if leadingSpaces(line) >= minIndentSpaces {
+					indented++
+				}
+			}
+		}
+	}
+	typical := percentile(lengths, typicalLinePercentile)
+	// When most lines are indented, indentation is layout, not a paragraph
+	// mark.
+	indentMarksParagraph := indented*2 < len(lengths)
+
+	out := []byte{}
+	spans := make([]PageSpan, 0, len(pages))
+	prev := ""
+	for i, lines := range pages {
+		span := PageSpan{Page: i + 1, Start: -1}
+		for j, raw := range lines {
+			line := strings.Join(strings.Fields(raw), " ")
+			if line == "" {
+				prev = ""
+				continue
+			}
+			switch {
+			case len(out) == 0:
+			case j == 0 && runsOverPage(prev, line):
+				out = append(out, ' ')
+			case startsParagraph(prev, raw, line, typical, indentMarksParagraph):
+				out = append(out, '\n')
+			case rejoinsHyphen(prev, line):
+				out = out[:len(out)-1]
+			default:
+				out = append(out, ' ')
+			}
+			if span.Start < 0 {
+				span.Start = len(out)
+				// A hyphen dropped at the page break shortens the last page.
+				if n := len(spans); n > 0 && spans[n-1].End > len(out) {
+					spans[n-1].End = len(out)
+				}
+			}
+			out = append(out, line...)
+			prev = line
+		}
+		if span.Start >= 0 {
+			span.End = len(out)
+			spans = append(spans, span)
+		}
+	}
+	return string(out), spans
+}
+
+// startsParagraph reports whether line opens a new paragraph after prev.
+// An empty prev is a blank line.
+func startsParagraph(prev, raw, line string, typical int, indentMarksParagraph bool) bool {
+	if prev == "" || typical > paragraphLineRunes {
+		return true
+	}
+	if utf8.RuneCountInString(prev) < int(shortLineShare*float64(typical)) {
+		return true
+	}
+	if indentMarksParagraph && leadingSpaces(raw) >= minIndentSpaces {
+		return true
+	}
+	if headingLine.MatchString(line) {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	endsSentence := strings.ContainsRune(`.!?"”’`, last)
+	opensDialogue := strings.HasPrefix(line, `"`) || strings.HasPrefix(line, "“") || strings.HasPrefix(line, "—")
+	return endsSentence && opensDialogue
+}
+
+// runsOverPage reports a sentence cut by a page break: prev stops mid-word
+// or after a comma and line carries on in lower case.
+func runsOverPage(prev, line string) bool {
+	if prev == "" || rejoinsHyphen(prev, line) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(line)
+	return (unicode.IsLetter(last) || last == ',' || last == ';') && unicode.IsLower(first)
+}
+
+// rejoinsHyphen reports a word split at the end of prev ("impor-" + "tant").
+func rejoinsHyphen(prev, line string) bool {
+	if len(prev) < 2 || !strings.HasSuffix(prev, "-") || strings.HasSuffix(prev, "--") {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(prev[:len(prev)-1])
+	first, _ := utf8.DecodeRuneInString(line)
+	return unicode.IsLetter(before) && unicode.IsLower(first)
+}
+
+// pageLines splits a page into lines without the blank lines around them or
+// a page number standing alone as the first or last line, so a paragraph can
+// run on over the page break.
+func pageLines(text string) []string {
+	lines := trimBlankLines(strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"))
+	if runPerLine(lines) {
+		lines = joinRuns(lines)
+	}
+	if n := len(lines); n > 0 && pageNumberLine.MatchString(strings.TrimSpace(lines[n-1])) {
+		lines = lines[:n-1]
+	}
+	if len(lines) > 0 && pageNumberLine.MatchString(strings.TrimSpace(lines[0])) {
+		lines = lines[1:]
+	}
+	return trimBlankLines(lines)
+}
+
+// runPerLine reports a page extracted one text run per line, with the spaces
+// between words as runs of their own; such a page has lost its line breaks.
+func runPerLine(lines []string) bool {
+	spaces, words := 0, 0
+	for _, line := range lines {
+		switch {
+		case line == "":
+		case strings.TrimSpace(line) == "":
+			spaces++
+		default:
+			words++
+		}
+	}
+	return words > 1 && spaces*2 >= words
+}
+
+// joinRuns glues the runs of a run-per-line page back into text, keeping
+// only the empty lines as line breaks.
+func joinRuns(lines []string) []string {
+	out := []string{}
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			out = append(out, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteString(line)
+	}
+	return append(out, b.String())
+}
+
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func leadingSpaces(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func percentile(values []int, p float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+	return sorted[int(p*float64(len(sorted)-1))]
+}
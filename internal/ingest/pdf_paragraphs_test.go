@@ -0,0 +1,71 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconstructParagraphsRestoresBreaks(t *testing.T) {
+	pages := []string{
+		"Chapter 1\n" +
+			"The harbor was quiet when Mara walked down to the\n" +
+			"water. The boats knocked against the pier and the\n" +
+			"gulls had gone inland for the night.\n" +
+			"  Tomas was waiting for her by the old crane, his\n" +
+			"collar turned up against the wind, and he did not\n" +
+			"look up when she came.\n" +
+			"\"You're late,\" he said. The words came out impor-\n" +
+			"\n" +
+			"1\n",
+		"2\n" +
+			"tant and sharp, as if he had rehearsed them all day\n" +
+			"on the way down from the house on the hill.\n",
+	}
+	text, spans := reconstructParagraphs(pages)
+	want := []string{
+		"Chapter 1",
+		"The harbor was quiet when Mara walked down to the water. The boats knocked against the pier and the gulls had gone inland for the night.",
+		"Tomas was waiting for her by the old crane, his collar turned up against the wind, and he did not look up when she came.",
+		"\"You're late,\" he said. The words came out important and sharp, as if he had rehearsed them all day on the way down from the house on the hill.",
+	}
+	if got := strings.Split(text, "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected paragraphs:\n%s", text)
+	}
+	if text != normalizeWhitespace(text) {
+		t.Fatal("expected reconstructed text to be normalized already")
+	}
+
+	if len(spans) != 2 || spans[0].Page != 1 || spans[0].Start != 0 || spans[1].Page != 2 || spans[1].End != len(text) {
+		t.Fatalf("unexpected page spans %+v", spans)
+	}
+	second := strings.Index(text, "tant and sharp")
+	if PageAt(spans, second) != 2 || PageAt(spans, strings.Index(text, "Tomas")) != 1 {
+		t.Fatalf("expected offsets mapped to their pages, got %+v", spans)
+	}
+	if PageAt(spans, len(text)+10) != 0 || PageAt(nil, 0) != 0 {
+		t.Fatal("expected offsets outside the pages unmapped")
+	}
+}
+
+func TestReconstructParagraphsKeepsWholeParagraphLines(t *testing.T) {
+	long := strings.Repeat("The rain kept on falling over the town all night long. ", 4)
+	text, _ := reconstructParagraphs([]string{long + "\n" + long + "\n"})
+	if strings.Count(text, "\n") != 1 {
+		t.Fatalf("expected extracted paragraph lines kept apart, got %q", text)
+	}
+}
+
+func TestReconstructParagraphsJoinsRunPerLinePages(t *testing.T) {
+	pages := []string{
+		"\nChapter\n \n1\n ",
+		"\nAdrian\n \nquickly\n \nfollowed,\n ",
+		"\nand\n \nbarely\n \nmanaged\n \nto\n \nhear.\n ",
+	}
+	text, spans := reconstructParagraphs(pages)
+	if text != "Chapter 1\nAdrian quickly followed, and barely managed to hear." {
+		t.Fatalf("expected runs glued into words and the sentence kept across the page break, got %q", text)
+	}
+	if len(spans) != 3 || PageAt(spans, strings.Index(text, "barely")) != 3 {
+		t.Fatalf("unexpected page spans %+v", spans)
+	}
+}
@@ -0,0 +1,60 @@
+package novelty
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// baselineWordsTSV is a small reference frequency table of common fiction
+// words, used when no reference model is installed.
+//
+//go:embed baseline_words.tsv
+var baselineWordsTSV string
+
+var (
+	baselineOnce  sync.Once
+	baselineRates map[string]float64
+)
+
+// BaselineWordRates returns the embedded reference frequencies of common
+// fiction words, in uses per million words. The map is shared; callers must
+// not modify it.
+func BaselineWordRates() map[string]float64 {
+	baselineOnce.Do(func() {
+		baselineRates = map[string]float64{}
+		for _, line := range strings.Split(baselineWordsTSV, "\n") {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			word, rate, ok := strings.Cut(strings.TrimSpace(line), "\t")
+			if !ok {
+				continue
+			}
+			if v, err := strconv.ParseFloat(rate, 64); err == nil {
+				baselineRates[word] = v
+			}
+		}
+	})
+	return baselineRates
+}
+
+// Rate returns how often the model's reference text uses ngram, a word,
+// bigram or trigram in lowercase tokens joined by single spaces, in uses per
+// million tokens. N-grams pruned from or absent in the model rate 0.
+func (m *Model) Rate(ngram string) float64 {
+	if m.Tokens == 0 {
+		return 0
+	}
+	var count int64
+	switch strings.Count(ngram, " ") {
+	case 0:
+		count = m.unigrams[ngram]
+	case 1:
+		count = m.bigrams[ngram]
+	case 2:
+		count = m.trigrams[ngram]
+	}
+	return float64(count) * 1e6 / float64(m.Tokens)
+}
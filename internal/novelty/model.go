@@ -0,0 +1,264 @@
+// Package novelty scores how surprising a text is to a reference trigram
+// model built from other fiction. Prose that the model predicts well is
+// conventional; prose it finds surprising is novel.
+package novelty
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var wordPattern = regexp.MustCompile(`[A-Za-z']+`)
+
+const (
+	// Interpolation weights for the trigram, bigram and unigram estimates.
+	lambdaTrigram = 0.6
+	lambdaBigram  = 0.3
+	lambdaUnigram = 0.1
+
+	// Build holds out every heldOutEvery-th segment of segmentWords tokens to
+	// measure the model's surprisal on fiction it has not seen.
+	segmentWords = 1000
+	heldOutEvery = 10
+
+	// minBaselineSD keeps a very uniform reference corpus from turning small
+	// differences into extreme z-scores.
+	minBaselineSD = 0.25
+)
+
+// Model is a trigram frequency model plus the surprisal it assigns to held-out
+// reference text, which anchors novelty scores.
+type Model struct {
+	Name string
+	// Tokens is the number of training tokens.
+	Tokens int64
+	// BaselineSurprisal and BaselineSD are the mean and spread, in bits per
+	// token, over held-out reference segments.
+	BaselineSurprisal float64
+	BaselineSD        float64
+
+	unigrams map[string]int64
+	bigrams  map[string]int64
+	trigrams map[string]int64
+}
+
+func newModel(name string) *Model {
+	return &Model{
+		Name:     name,
+		unigrams: map[string]int64{},
+		bigrams:  map[string]int64{},
+		trigrams: map[string]int64{},
+	}
+}
+
+// Tokenize lowercases text into the word tokens the model counts.
+func Tokenize(text string) []string {
+	return wordPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// Build trains a model on texts, dropping bigrams and trigrams seen fewer than
+// minCount times to keep the file small. The baseline comes from held-out
+// segments, so at least ten segments (about 10,000 words) are required.
+func Build(name string, texts []string, minCount int64) (*Model, error) {
+	var train, heldOut [][]string
+	segment := 0
+	for _, text := range texts {
+		tokens := Tokenize(text)
+		for start := 0; start < len(tokens); start += segmentWords {
+			seg := tokens[start:min(start+segmentWords, len(tokens))]
+			segment++
+			if segment%heldOutEvery == 0 {
+				heldOut = append(heldOut, seg)
+			} else {
+				train = append(train, seg)
+			}
+		}
+	}
+	if len(heldOut) == 0 {
+		return nil, fmt.Errorf("build novelty model: need at least %d words of reference text", segmentWords*heldOutEvery)
+	}
+	m := newModel(name)
+	for _, seg := range train {
+		m.count(seg)
+	}
+	m.prune(minCount)
+
+	scores := make([]float64, 0, len(heldOut))
+	for _, seg := range heldOut {
+		if s, n := m.surprisal(seg); n > 0 {
+			scores = append(scores, s)
+		}
+	}
+	m.BaselineSurprisal, m.BaselineSD = meanSD(scores)
+	return m, nil
+}
+
+func (m *Model) count(tokens []string) {
+	for i, w := range tokens {
+		m.unigrams[w]++
+		m.Tokens++
+		if i >= 1 {
+			m.bigrams[tokens[i-1]+" "+w]++
+		}
+		if i >= 2 {
+			m.trigrams[tokens[i-2]+" "+tokens[i-1]+" "+w]++
+		}
+	}
+}
+
+func (m *Model) prune(minCount int64) {
+	if minCount <= 1 {
+		return
+	}
+	for _, table := range []map[string]int64{m.bigrams, m.trigrams} {
+		for k, c := range table {
+			if c < minCount {
+				delete(table, k)
+			}
+		}
+	}
+}
+
+// surprisal returns the mean bits per token of tokens under the model and the
+// number of tokens scored. The first two tokens only have unigram and bigram
+// context, like the start of any segment.
+func (m *Model) surprisal(tokens []string) (float64, int) {
+	if len(tokens) == 0 || m.Tokens == 0 {
+		return 0, 0
+	}
+	vocab := float64(len(m.unigrams) + 1)
+	total := 0.0
+	for i, w := range tokens {
+		p := lambdaUnigram * (float64(m.unigrams[w]) + 1) / (float64(m.Tokens) + vocab)
+		if i >= 1 {
+			if ctx := m.unigrams[tokens[i-1]]; ctx > 0 {
+				p += lambdaBigram * float64(m.bigrams[tokens[i-1]+" "+w]) / float64(ctx)
+			}
+		}
+		if i >= 2 {
+			if ctx := m.bigrams[tokens[i-2]+" "+tokens[i-1]]; ctx > 0 {
+				p += lambdaTrigram * float64(m.trigrams[tokens[i-2]+" "+tokens[i-1]+" "+w]) / float64(ctx)
+			}
+		}
+		total += -math.Log2(p)
+	}
+	return total / float64(len(tokens)), len(tokens)
+}
+
+// Save writes the model as gzip-compressed TSV: "#key\tvalue" header lines,
+// then one "ngram\tcount" line per unigram, bigram and trigram.
+func (m *Model) Save(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	bw := bufio.NewWriter(gz)
+	fmt.Fprintf(bw, "#name\t%s\n#tokens\t%d\n#baseline\t%.6f\n#baseline_sd\t%.6f\n", m.Name, m.Tokens, m.BaselineSurprisal, m.BaselineSD)
+	for _, table := range []map[string]int64{m.unigrams, m.bigrams, m.trigrams} {
+		keys := make([]string, 0, len(table))
+		for k := range table {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(bw, "%s\t%d\n", k, table[k])
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write novelty model: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write novelty model: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a model written by Save.
+func Read(r io.Reader) (*Model, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read novelty model: %w", err)
+	}
+	defer gz.Close()
+	m := newModel("")
+	scanner := bufio.NewScanner(gz)
+	line := 0
+	for scanner.Scan() {
+		line++
+		key, value, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			return nil, fmt.Errorf("read novelty model: line %d has no tab", line)
+		}
+		if strings.HasPrefix(key, "#") {
+			if err := m.setHeader(strings.TrimPrefix(key, "#"), value); err != nil {
+				return nil, fmt.Errorf("read novelty model: line %d: %w", line, err)
+			}
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("read novelty model: line %d: %w", line, err)
+		}
+		switch strings.Count(key, " ") {
+		case 0:
+			m.unigrams[key] = count
+		case 1:
+			m.bigrams[key] = count
+		case 2:
+			m.trigrams[key] = count
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read novelty model: %w", err)
+	}
+	if m.Tokens == 0 || len(m.unigrams) == 0 {
+		return nil, fmt.Errorf("read novelty model: no n-gram counts")
+	}
+	return m, nil
+}
+
+func (m *Model) setHeader(key, value string) error {
+	var err error
+	switch key {
+	case "name":
+		m.Name = value
+	case "tokens":
+		m.Tokens, err = strconv.ParseInt(value, 10, 64)
+	case "baseline":
+		m.BaselineSurprisal, err = strconv.ParseFloat(value, 64)
+	case "baseline_sd":
+		m.BaselineSD, err = strconv.ParseFloat(value, 64)
+	}
+	return err
+}
+
+// Load reads a model file from disk.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open novelty model: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func meanSD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	mean := total / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
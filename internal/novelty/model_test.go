@@ -0,0 +1,125 @@
+package novelty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// referenceCorpus produces enough varied but formulaic prose to train a model.
+func referenceCorpus() []string {
+	subjects := []string{"she", "he", "the captain", "the old man", "her sister"}
+	verbs := []string{"walked to", "looked at", "ran toward", "waited by", "thought about"}
+	objects := []string{"the door", "the window", "the river", "the house", "the station", "the garden"}
+	var texts []string
+	for book := 0; book < 4; book++ {
+		var sb strings.Builder
+		for i := 0; i < 900; i++ {
+			fmt.Fprintf(&sb, "%s %s %s and then %s %s %s. ",
+				subjects[(i+book)%len(subjects)], verbs[i%len(verbs)], objects[(i*7+book)%len(objects)],
+				subjects[(i*3)%len(subjects)], verbs[(i+2)%len(verbs)], objects[i%len(objects)])
+		}
+		texts = append(texts, sb.String())
+	}
+	return texts
+}
+
+func buildTestModel(t *testing.T) *Model {
+	t.Helper()
+	m, err := Build("test-fiction", referenceCorpus(), 1)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return m
+}
+
+func TestBuildRequiresHeldOutText(t *testing.T) {
+	if _, err := Build("tiny", []string{"too short to hold anything out"}, 1); err == nil {
+		t.Fatal("expected an error for a corpus without held-out segments")
+	}
+}
+
+func TestScoreSeparatesConventionalAndNovelProse(t *testing.T) {
+	m := buildTestModel(t)
+	conventional := m.Score(strings.Repeat("she walked to the door and then he looked at the window. ", 30))
+	novel := m.Score(strings.Repeat("quantum marmalade erupted beneath seventeen velvet glaciers whispering arithmetic. ", 30))
+	if conventional.Novelty >= novel.Novelty || conventional.Surprisal >= novel.Surprisal {
+		t.Fatalf("expected novel prose to score higher: conventional=%+v novel=%+v", conventional, novel)
+	}
+	if novel.UnseenTrigramRate < 0.9 || conventional.UnseenTrigramRate > 0.2 {
+		t.Fatalf("unexpected unseen trigram rates: conventional=%.3f novel=%.3f", conventional.UnseenTrigramRate, novel.UnseenTrigramRate)
+	}
+	if empty := m.Score(""); empty.Tokens != 0 || empty.Novelty != 0 {
+		t.Fatalf("expected empty profile, got %+v", empty)
+	}
+}
+
+func TestSaveReadRoundTrip(t *testing.T) {
+	m := buildTestModel(t)
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if loaded.Name != m.Name || loaded.Tokens != m.Tokens || len(loaded.trigrams) != len(m.trigrams) {
+		t.Fatalf("round trip mismatch: %+v vs %+v", loaded, m)
+	}
+	text := "the old man waited by the river and then she thought about the garden."
+	if a, b := m.Score(text), loaded.Score(text); a != b {
+		t.Fatalf("scores differ after round trip: %+v vs %+v", a, b)
+	}
+}
+
+func TestDownloadKeepsExistingModelOnBadPayload(t *testing.T) {
+	var model bytes.Buffer
+	if err := buildTestModel(t).Save(&model); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			_, _ = w.Write([]byte("not a model"))
+			return
+		}
+		_, _ = w.Write(model.Bytes())
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "ngram", "reference.ngram.gz")
+	got, err := Download(context.Background(), server.URL+"/good", dest)
+	if err != nil || got.Name != "test-fiction" {
+		t.Fatalf("download: %v %+v", err, got)
+	}
+	if _, err := Download(context.Background(), server.URL+"/bad", dest); err == nil {
+		t.Fatal("expected a bad payload to fail")
+	}
+	if _, err := Load(dest); err != nil {
+		t.Fatalf("expected the installed model to survive a bad download: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dest))
+	if len(entries) != 1 {
+		t.Fatalf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
+
+func TestRateAndBaselineWordRates(t *testing.T) {
+	m := buildTestModel(t)
+	if m.Rate("the") <= m.Rate("walked to the") || m.Rate("walked to the") == 0 {
+		t.Fatalf("unexpected rates the=%.1f trigram=%.1f", m.Rate("the"), m.Rate("walked to the"))
+	}
+	if m.Rate("dragon") != 0 || m.Rate("a b c d") != 0 {
+		t.Fatal("expected unseen and over-long n-grams to rate 0")
+	}
+	rates := BaselineWordRates()
+	if rates["said"] <= rates["suddenly"] || rates["suddenly"] == 0 || rates["dragon"] != 0 {
+		t.Fatalf("unexpected baseline rates said=%.0f suddenly=%.0f", rates["said"], rates["suddenly"])
+	}
+}
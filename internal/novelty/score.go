@@ -0,0 +1,94 @@
+package novelty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Profile is the novelty of one text against a model.
+type Profile struct {
+	Tokens int `json:"tokens"`
+	// Surprisal is the mean bits per token under the model.
+	Surprisal float64 `json:"surprisal"`
+	// ZScore places Surprisal against the model's held-out baseline.
+	ZScore float64 `json:"zScore"`
+	// Novelty maps ZScore to 0-100: 50 is as surprising as the reference
+	// fiction, each baseline standard deviation moves it 15 points.
+	Novelty int `json:"novelty"`
+	// UnseenTrigramRate is the share of trigrams absent from the model.
+	UnseenTrigramRate float64 `json:"unseenTrigramRate"`
+}
+
+// Score profiles text against the model.
+func (m *Model) Score(text string) Profile {
+	tokens := Tokenize(text)
+	s, n := m.surprisal(tokens)
+	if n == 0 {
+		return Profile{}
+	}
+	sd := math.Max(m.BaselineSD, minBaselineSD)
+	z := (s - m.BaselineSurprisal) / sd
+	unseen, trigrams := 0, 0
+	for i := 2; i < len(tokens); i++ {
+		trigrams++
+		if m.trigrams[tokens[i-2]+" "+tokens[i-1]+" "+tokens[i]] == 0 {
+			unseen++
+		}
+	}
+	rate := 0.0
+	if trigrams > 0 {
+		rate = float64(unseen) / float64(trigrams)
+	}
+	return Profile{
+		Tokens:            n,
+		Surprisal:         math.Round(s*100) / 100,
+		ZScore:            math.Round(z*100) / 100,
+		Novelty:           int(math.Round(math.Max(0, math.Min(100, 50+15*z)))),
+		UnseenTrigramRate: math.Round(rate*1000) / 1000,
+	}
+}
+
+// Download fetches a model from url and installs it at dest once it decodes,
+// so a failed or partial download never replaces a working model.
+func Download(ctx context.Context, url, dest string) (*Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("download novelty model: status %d", resp.StatusCode)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".novelty-*")
+	if err != nil {
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("download novelty model: %w", err)
+	}
+	m, err := Load(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return nil, fmt.Errorf("install novelty model: %w", err)
+	}
+	return m, nil
+}
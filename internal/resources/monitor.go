@@ -0,0 +1,282 @@
+// Package resources samples process and system CPU and memory, plus the
+// memory held by local Ollama processes, while an analysis runs.
+package resources
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often a run is sampled.
+	DefaultInterval = 2 * time.Second
+	// maxTimelinePoints bounds the profile's timeline; longer runs are
+	// downsampled.
+	maxTimelinePoints = 60
+
+	PressureLow      = "low"
+	PressureModerate = "moderate"
+	PressureHigh     = "high"
+
+	// Pressure thresholds on system CPU and memory use, in percent.
+	moderateCPUPercent = 85
+	highCPUPercent     = 95
+	moderateMemPercent = 80
+	highMemPercent     = 92
+)
+
+// Sample is one reading. CPU percentages are shares of the whole machine
+// (all cores), so 100 means every core is busy.
+type Sample struct {
+	At                   time.Time `json:"at"`
+	ProcessCPUPercent    float64   `json:"processCpuPercent"`
+	SystemCPUPercent     float64   `json:"systemCpuPercent"`
+	ProcessRSSBytes      uint64    `json:"processRssBytes"`
+	HeapBytes            uint64    `json:"heapBytes"`
+	OllamaRSSBytes       uint64    `json:"ollamaRssBytes"`
+	SystemMemTotalBytes  uint64    `json:"systemMemTotalBytes"`
+	SystemMemUsedPercent float64   `json:"systemMemUsedPercent"`
+}
+
+// Profile summarizes a run's samples. Known is false on platforms where
+// system readings are unavailable; process heap figures are always filled.
+type Profile struct {
+	Known               bool           `json:"known"`
+	Samples             int            `json:"samples"`
+	IntervalMs          int64          `json:"intervalMs"`
+	CPUCores            int            `json:"cpuCores"`
+	PeakProcessRSSMB    float64        `json:"peakProcessRssMb"`
+	PeakHeapMB          float64        `json:"peakHeapMb"`
+	PeakOllamaRSSMB     float64        `json:"peakOllamaRssMb"`
+	MeanProcessCPU      float64        `json:"meanProcessCpu"`
+	MaxProcessCPU       float64        `json:"maxProcessCpu"`
+	MeanSystemCPU       float64        `json:"meanSystemCpu"`
+	MaxSystemCPU        float64        `json:"maxSystemCpu"`
+	SystemMemTotalMB    float64        `json:"systemMemTotalMb"`
+	MaxSystemMemPercent float64        `json:"maxSystemMemPercent"`
+	Pressure            string         `json:"pressure"`
+	Timeline            []ProfilePoint `json:"timeline"`
+}
+
+// ProfilePoint is a downsampled sample for charts and the log package.
+type ProfilePoint struct {
+	OffsetMs         int64   `json:"offsetMs"`
+	ProcessCPU       float64 `json:"processCpu"`
+	SystemCPU        float64 `json:"systemCpu"`
+	ProcessRSSMB     float64 `json:"processRssMb"`
+	OllamaRSSMB      float64 `json:"ollamaRssMb"`
+	SystemMemPercent float64 `json:"systemMemPercent"`
+}
+
+// reading is what a platform reports at one instant. CPU figures are either
+// cumulative counters (counters is true) that the monitor differences, or
+// instantaneous percentages.
+type reading struct {
+	known      bool
+	counters   bool
+	cpuBusy    uint64
+	cpuTotal   uint64
+	procCPU    time.Duration
+	systemCPU  float64
+	processCPU float64
+	memTotal   uint64
+	memUsed    uint64
+	processRSS uint64
+	ollamaRSS  uint64
+}
+
+// Monitor samples in the background from Start until Stop.
+type Monitor struct {
+	interval time.Duration
+	started  time.Time
+	read     func() reading
+
+	mu      sync.Mutex
+	samples []Sample
+	known   bool
+	prev    reading
+	prevAt  time.Time
+
+	done     chan struct{}
+	finished chan struct{}
+	stopOnce sync.Once
+	profile  Profile
+}
+
+// Start begins sampling every interval (DefaultInterval when interval <= 0),
+// taking the first sample immediately.
+func Start(interval time.Duration) *Monitor {
+	return start(interval, readPlatform)
+}
+
+func start(interval time.Duration, read func() reading) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	m := &Monitor{
+		interval: interval,
+		started:  time.Now(),
+		read:     read,
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	m.sample(m.started)
+	go func() {
+		defer close(m.finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.done:
+				return
+			case now := <-ticker.C:
+				m.sample(now)
+			}
+		}
+	}()
+	return m
+}
+
+func (m *Monitor) sample(now time.Time) {
+	r := m.read()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := Sample{
+		At:                  now,
+		ProcessRSSBytes:     r.processRSS,
+		HeapBytes:           mem.HeapAlloc,
+		OllamaRSSBytes:      r.ollamaRSS,
+		SystemMemTotalBytes: r.memTotal,
+		SystemCPUPercent:    r.systemCPU,
+		ProcessCPUPercent:   r.processCPU,
+	}
+	if r.memTotal > 0 {
+		s.SystemMemUsedPercent = 100 * float64(r.memUsed) / float64(r.memTotal)
+	}
+	if r.counters {
+		if !m.prev.counters {
+			// The first counter reading has nothing to difference against;
+			// -1 keeps it out of the CPU averages.
+			s.SystemCPUPercent, s.ProcessCPUPercent = -1, -1
+		} else {
+			if r.cpuTotal > m.prev.cpuTotal {
+				s.SystemCPUPercent = 100 * float64(r.cpuBusy-m.prev.cpuBusy) / float64(r.cpuTotal-m.prev.cpuTotal)
+			}
+			if wall := now.Sub(m.prevAt); wall > 0 {
+				s.ProcessCPUPercent = 100 * float64(r.procCPU-m.prev.procCPU) / float64(wall) / float64(runtime.NumCPU())
+			}
+		}
+		m.prev, m.prevAt = r, now
+	}
+	m.known = m.known || r.known
+	m.samples = append(m.samples, s)
+}
+
+// Latest returns the most recent sample, or false before any were taken.
+func (m *Monitor) Latest() (Sample, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.samples) == 0 {
+		return Sample{}, false
+	}
+	return m.samples[len(m.samples)-1], true
+}
+
+// Pressure is the load verdict for the latest sample, for callers that size
+// worker pools while the run is in progress.
+func (m *Monitor) Pressure() string {
+	latest, ok := m.Latest()
+	if !ok || latest.SystemCPUPercent < 0 {
+		return PressureLow
+	}
+	return pressureOf(latest.SystemCPUPercent, latest.SystemMemUsedPercent)
+}
+
+// Stop ends sampling, takes a final sample and returns the profile. Further
+// calls return the same profile.
+func (m *Monitor) Stop() Profile {
+	m.stopOnce.Do(func() {
+		close(m.done)
+		<-m.finished
+		m.sample(time.Now())
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.profile = summarize(m.samples, m.started, m.interval, m.known)
+	})
+	return m.profile
+}
+
+func summarize(samples []Sample, started time.Time, interval time.Duration, known bool) Profile {
+	p := Profile{
+		Known:      known,
+		Samples:    len(samples),
+		IntervalMs: interval.Milliseconds(),
+		CPUCores:   runtime.NumCPU(),
+		Pressure:   PressureLow,
+		Timeline:   []ProfilePoint{},
+	}
+	cpuSamples := 0
+	for _, s := range samples {
+		p.PeakProcessRSSMB = math.Max(p.PeakProcessRSSMB, mb(s.ProcessRSSBytes))
+		p.PeakHeapMB = math.Max(p.PeakHeapMB, mb(s.HeapBytes))
+		p.PeakOllamaRSSMB = math.Max(p.PeakOllamaRSSMB, mb(s.OllamaRSSBytes))
+		p.SystemMemTotalMB = math.Max(p.SystemMemTotalMB, mb(s.SystemMemTotalBytes))
+		p.MaxSystemMemPercent = math.Max(p.MaxSystemMemPercent, s.SystemMemUsedPercent)
+		if s.SystemCPUPercent < 0 {
+			continue
+		}
+		cpuSamples++
+		p.MeanProcessCPU += s.ProcessCPUPercent
+		p.MeanSystemCPU += s.SystemCPUPercent
+		p.MaxProcessCPU = math.Max(p.MaxProcessCPU, s.ProcessCPUPercent)
+		p.MaxSystemCPU = math.Max(p.MaxSystemCPU, s.SystemCPUPercent)
+	}
+	if cpuSamples > 0 {
+		p.MeanProcessCPU /= float64(cpuSamples)
+		p.MeanSystemCPU /= float64(cpuSamples)
+	}
+	if known {
+		// Sustained load decides pressure; a single spike does not.
+		p.Pressure = pressureOf(p.MeanSystemCPU, p.MaxSystemMemPercent)
+	}
+
+	step := max(1, (len(samples)+maxTimelinePoints-1)/maxTimelinePoints)
+	for i := 0; i < len(samples); i += step {
+		s := samples[i]
+		p.Timeline = append(p.Timeline, ProfilePoint{
+			OffsetMs:         s.At.Sub(started).Milliseconds(),
+			ProcessCPU:       round1(math.Max(0, s.ProcessCPUPercent)),
+			SystemCPU:        round1(math.Max(0, s.SystemCPUPercent)),
+			ProcessRSSMB:     round1(mb(s.ProcessRSSBytes)),
+			OllamaRSSMB:      round1(mb(s.OllamaRSSBytes)),
+			SystemMemPercent: round1(s.SystemMemUsedPercent),
+		})
+	}
+	for _, v := range []*float64{&p.PeakProcessRSSMB, &p.PeakHeapMB, &p.PeakOllamaRSSMB, &p.MeanProcessCPU, &p.MaxProcessCPU, &p.MeanSystemCPU, &p.MaxSystemCPU, &p.SystemMemTotalMB, &p.MaxSystemMemPercent} {
+		*v = round1(*v)
+	}
+	return p
+}
+
+func pressureOf(cpuPercent, memPercent float64) string {
+	switch {
+	case cpuPercent >= highCPUPercent || memPercent >= highMemPercent:
+		return PressureHigh
+	case cpuPercent >= moderateCPUPercent || memPercent >= moderateMemPercent:
+		return PressureModerate
+	}
+	return PressureLow
+}
+
+func mb(b uint64) float64 {
+	return float64(b) / (1 << 20)
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
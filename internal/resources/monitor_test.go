@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeReadings struct {
+	mu       sync.Mutex
+	readings []reading
+	i        int
+}
+
+func (f *fakeReadings) read() reading {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.readings[min(f.i, len(f.readings)-1)]
+	f.i++
+	return r
+}
+
+func TestMonitorSummarizesCounterReadings(t *testing.T) {
+	gb := uint64(1 << 30)
+	fake := &fakeReadings{readings: []reading{
+		{known: true, counters: true, cpuBusy: 0, cpuTotal: 100, memTotal: 16 * gb, memUsed: 8 * gb, processRSS: 200 << 20, ollamaRSS: 4 * gb},
+		{known: true, counters: true, cpuBusy: 98, cpuTotal: 200, memTotal: 16 * gb, memUsed: 15 * gb, processRSS: 300 << 20, ollamaRSS: 5 * gb},
+	}}
+	m := start(time.Hour, fake.read)
+	profile := m.Stop()
+
+	if !profile.Known || profile.Samples != 2 {
+		t.Fatalf("expected two known samples, got %+v", profile)
+	}
+	if profile.MaxSystemCPU != 98 || profile.MeanSystemCPU != 98 {
+		t.Fatalf("first counter sample must not count toward CPU, got mean %.1f max %.1f", profile.MeanSystemCPU, profile.MaxSystemCPU)
+	}
+	if profile.PeakProcessRSSMB != 300 || profile.PeakOllamaRSSMB != 5120 {
+		t.Fatalf("unexpected peaks: %+v", profile)
+	}
+	if profile.Pressure != PressureHigh {
+		t.Fatalf("expected high pressure at 98%% CPU and 94%% memory, got %q", profile.Pressure)
+	}
+	if len(profile.Timeline) != 2 {
+		t.Fatalf("expected timeline of 2 points, got %d", len(profile.Timeline))
+	}
+	if again := m.Stop(); again.Samples != profile.Samples {
+		t.Fatal("Stop must be idempotent")
+	}
+}
+
+func TestPressureReflectsLatestSample(t *testing.T) {
+	cases := []struct {
+		cpu, mem float64
+		want     string
+	}{
+		{cpu: 20, mem: 40, want: PressureLow},
+		{cpu: 90, mem: 40, want: PressureModerate},
+		{cpu: 20, mem: 95, want: PressureHigh},
+	}
+	for _, tc := range cases {
+		fake := &fakeReadings{readings: []reading{{known: true, systemCPU: tc.cpu, memTotal: 100, memUsed: uint64(tc.mem)}}}
+		m := start(time.Hour, fake.read)
+		if got := m.Pressure(); got != tc.want {
+			t.Errorf("cpu %.0f mem %.0f: expected %s pressure, got %s", tc.cpu, tc.mem, tc.want, got)
+		}
+		m.Stop()
+	}
+}
+
+func TestSummarizeDownsamplesTimeline(t *testing.T) {
+	started := time.Now()
+	samples := make([]Sample, 150)
+	for i := range samples {
+		samples[i] = Sample{At: started.Add(time.Duration(i) * time.Second)}
+	}
+	profile := summarize(samples, started, time.Second, false)
+	if len(profile.Timeline) > maxTimelinePoints {
+		t.Fatalf("timeline has %d points, want at most %d", len(profile.Timeline), maxTimelinePoints)
+	}
+	if profile.Pressure != PressureLow {
+		t.Fatalf("unknown platforms must report low pressure, got %q", profile.Pressure)
+	}
+}
+
+func TestStartSamplesRealPlatform(t *testing.T) {
+	profile := Start(10 * time.Millisecond).Stop()
+	if profile.Samples < 2 || profile.PeakHeapMB <= 0 {
+		t.Fatalf("expected heap samples from the live process, got %+v", profile)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build linux
+
+package resources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// readPlatform reads system CPU counters and memory from procfs, this
+// process's RSS from /proc/self/status and Ollama's RSS from every process
+// whose command name starts with "ollama".
+func readPlatform() reading {
+	r := reading{counters: true}
+	if busy, total, ok := readCPUCounters(); ok {
+		r.cpuBusy, r.cpuTotal = busy, total
+		r.known = true
+	}
+	if total, avail, ok := readMemInfo(); ok {
+		r.memTotal = total
+		if avail < total {
+			r.memUsed = total - avail
+		}
+		r.known = true
+	}
+	r.processRSS = readStatusRSS("/proc/self/status")
+	r.ollamaRSS = readOllamaRSS()
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err == nil {
+		r.procCPU = time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+	}
+	return r
+}
+
+// readCPUCounters returns busy and total jiffies from the aggregate cpu line
+// of /proc/stat. Idle and iowait count as not busy.
+func readCPUCounters() (busy, total uint64, ok bool) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var idle uint64
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, false
+			}
+			total += v
+			if i == 3 || i == 4 {
+				idle += v
+			}
+		}
+		return total - idle, total, true
+	}
+	return 0, 0, false
+}
+
+// readMemInfo returns MemTotal and MemAvailable in bytes.
+func readMemInfo() (total, avail uint64, ok bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			avail = kb * 1024
+		}
+	}
+	return total, avail, total > 0
+}
+
+// readStatusRSS returns the VmRSS line of a /proc/<pid>/status file in bytes,
+// or 0 when it cannot be read.
+func readStatusRSS(path string) uint64 {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, _ := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+func readOllamaRSS() uint64 {
+	dirs, _ := filepath.Glob("/proc/[0-9]*")
+	var total uint64
+	for _, dir := range dirs {
+		comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+		if err != nil || !strings.HasPrefix(strings.TrimSpace(string(comm)), "ollama") {
+			continue
+		}
+		total += readStatusRSS(filepath.Join(dir, "status"))
+	}
+	return total
+}
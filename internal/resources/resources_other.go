@@ -0,0 +1,62 @@
+//go:build !linux
+
+package resources
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// readPlatform reads per-process RSS and CPU from ps, which reports
+// instantaneous percentages of one core. System memory totals come from
+// sysctl on macOS; elsewhere only process figures are known.
+func readPlatform() reading {
+	r := reading{}
+	out, err := exec.Command("ps", "-A", "-o", "pid=,rss=,%cpu=,comm=").Output()
+	if err != nil {
+		return r
+	}
+	self := os.Getpid()
+	cores := float64(runtime.NumCPU())
+	var used uint64
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		rssKB, err2 := strconv.ParseUint(fields[1], 10, 64)
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		rss := rssKB * 1024
+		used += rss
+		r.systemCPU += cpu / cores
+		name := fields[len(fields)-1]
+		if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+			name = name[i+1:]
+		}
+		switch {
+		case pid == self:
+			r.processRSS = rss
+			r.processCPU = cpu / cores
+		case strings.HasPrefix(strings.ToLower(name), "ollama"):
+			r.ollamaRSS += rss
+		}
+	}
+	r.systemCPU = min(r.systemCPU, 100)
+	if runtime.GOOS == "darwin" {
+		if raw, err := exec.Command("sysctl", "-n", "hw.memsize").Output(); err == nil {
+			if total, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64); err == nil && total > 0 {
+				r.memTotal = total
+				r.memUsed = min(used, total)
+				r.known = true
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,214 @@
+// Package scheduler sizes worker pools for calls to local services (Ollama,
+// LanguageTool) from what the run observes, rather than from fixed settings.
+//
+// A Limiter starts with one call in flight and adds one more after a full
+// round of healthy calls: no error, per-unit latency within 1.5x of the best
+// seen, and no more than low system pressure. An error, a call slower than
+// twice the best, or high system pressure halves the limit. Moderate pressure
+// holds it where it is. A laptop that is already busy therefore stays at one
+// call at a time, and a fast workstation grows to the limiter's ceiling.
+package scheduler
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pressure levels reported by a PressureFunc; they match the verdicts of
+// resources.Profile.
+const (
+	PressureLow      = "low"
+	PressureModerate = "moderate"
+	PressureHigh     = "high"
+)
+
+// PressureFunc reports current system load as a Pressure level.
+type PressureFunc func() string
+
+const (
+	healthyLatencyRatio = 1.5
+	slowLatencyRatio    = 2.0
+	// baselineDrift is how quickly the best-seen latency forgets an
+	// unusually fast call, as a fraction of the gap per call.
+	baselineDrift = 0.05
+)
+
+// Stats summarizes how a limiter behaved over a run.
+type Stats struct {
+	Name          string  `json:"name"`
+	MaxWorkers    int     `json:"maxWorkers"`
+	FinalWorkers  int     `json:"finalWorkers"`
+	PeakWorkers   int     `json:"peakWorkers"`
+	Calls         int     `json:"calls"`
+	Errors        int     `json:"errors"`
+	Backoffs      int     `json:"backoffs"`
+	MeanLatencyMs float64 `json:"meanLatencyMs"`
+}
+
+// Limiter bounds concurrent calls to one service. It is safe for concurrent
+// use.
+type Limiter struct {
+	name     string
+	maxLimit int
+	pressure PressureFunc
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	inFlight  int
+	healthy   int
+	baseline  float64
+	peak      int
+	calls     int
+	errors    int
+	backoffs  int
+	latencyMs float64
+}
+
+// New returns a limiter allowing at most maxWorkers calls at once, further
+// capped by MHD_MAX_WORKERS when set. pressure may be nil.
+func New(name string, maxWorkers int, pressure PressureFunc) *Limiter {
+	maxWorkers = min(max(1, maxWorkers), workerCeiling())
+	l := &Limiter{name: name, maxLimit: maxWorkers, pressure: pressure, limit: 1}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// workerCeiling is MHD_MAX_WORKERS, a hard cap for debugging or shared
+// machines, or NumCPU.
+func workerCeiling() int {
+	if raw := strings.TrimSpace(os.Getenv("MHD_MAX_WORKERS")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return n
+		}
+	}
+	return max(1, runtime.NumCPU())
+}
+
+// Acquire waits for a free slot. It returns ctx's error if ctx ends first.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= l.limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.inFlight++
+	l.peak = max(l.peak, l.inFlight)
+	return nil
+}
+
+// Release frees a slot and adjusts the limit. perUnit is the call's latency
+// divided by its size, so large and small jobs compare fairly.
+func (l *Limiter) Release(perUnit time.Duration, err error) {
+	pressure := PressureLow
+	if l.pressure != nil {
+		pressure = l.pressure()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.calls++
+	ms := float64(perUnit) / float64(time.Millisecond)
+	l.latencyMs += ms
+	if err != nil {
+		l.errors++
+	}
+
+	slow := false
+	if err == nil && ms > 0 {
+		switch {
+		case l.baseline == 0 || ms < l.baseline:
+			l.baseline = ms
+		default:
+			slow = ms > l.baseline*slowLatencyRatio
+			l.baseline += (ms - l.baseline) * baselineDrift
+		}
+	}
+
+	switch {
+	case err != nil || slow || pressure == PressureHigh:
+		if l.limit > 1 {
+			l.limit = max(1, l.limit/2)
+			l.backoffs++
+		}
+		l.healthy = 0
+	case pressure == PressureModerate || ms > l.baseline*healthyLatencyRatio:
+		l.healthy = 0
+	default:
+		l.healthy++
+		if l.healthy >= l.limit && l.limit < l.maxLimit {
+			l.limit++
+			l.healthy = 0
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// Limit is the number of calls currently allowed at once.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := Stats{
+		Name:         l.name,
+		MaxWorkers:   l.maxLimit,
+		FinalWorkers: l.limit,
+		PeakWorkers:  l.peak,
+		Calls:        l.calls,
+		Errors:       l.errors,
+		Backoffs:     l.backoffs,
+	}
+	if l.calls > 0 {
+		s.MeanLatencyMs = float64(int(l.latencyMs/float64(l.calls)*10)) / 10
+	}
+	return s
+}
+
+// Each runs fn for jobs 0..len(sizes)-1 under the limiter and returns one
+// error per job. sizes[i] is job i's size in any unit (words, say); zero
+// counts as one. Jobs not started before ctx ends get ctx's error. fn runs on
+// its own goroutine, so shared state it touches must be guarded.
+func Each(ctx context.Context, l *Limiter, sizes []int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, len(sizes))
+	var wg sync.WaitGroup
+	for i := range sizes {
+		if err := l.Acquire(ctx); err != nil {
+			for j := i; j < len(sizes); j++ {
+				errs[j] = err
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started := time.Now()
+			err := fn(ctx, i)
+			errs[i] = err
+			l.Release(time.Since(started)/time.Duration(max(1, sizes[i])), err)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
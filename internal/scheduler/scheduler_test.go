@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterGrowsWhileHealthy(t *testing.T) {
+	t.Setenv("MHD_MAX_WORKERS", "4")
+	l := New("test", 8, nil)
+	for range 20 {
+		if err := l.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		l.Release(10*time.Millisecond, nil)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("expected limit to reach the MHD_MAX_WORKERS cap of 4, got %d", got)
+	}
+}
+
+func TestLimiterBacksOffOnSlowCallsErrorsAndPressure(t *testing.T) {
+	t.Setenv("MHD_MAX_WORKERS", "8")
+	pressure := PressureLow
+	l := New("test", 8, func() string { return pressure })
+	grow := func() {
+		for l.Limit() < 8 {
+			_ = l.Acquire(context.Background())
+			l.Release(10*time.Millisecond, nil)
+		}
+	}
+
+	grow()
+	_ = l.Acquire(context.Background())
+	l.Release(50*time.Millisecond, nil)
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("slow call: expected limit halved to 4, got %d", got)
+	}
+
+	grow()
+	_ = l.Acquire(context.Background())
+	l.Release(10*time.Millisecond, errors.New("status 503"))
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("error: expected limit halved to 4, got %d", got)
+	}
+
+	pressure = PressureModerate
+	for range 10 {
+		_ = l.Acquire(context.Background())
+		l.Release(10*time.Millisecond, nil)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("moderate pressure must hold the limit, got %d", got)
+	}
+
+	pressure = PressureHigh
+	for range 3 {
+		_ = l.Acquire(context.Background())
+		l.Release(10*time.Millisecond, nil)
+	}
+	if got := l.Limit(); got != 1 {
+		t.Fatalf("high pressure: expected limit 1, got %d", got)
+	}
+	if stats := l.Stats(); stats.Backoffs != 4 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestEachRespectsLimitAndReportsErrors(t *testing.T) {
+	t.Setenv("MHD_MAX_WORKERS", "3")
+	l := New("test", 3, nil)
+	var inFlight, peak atomic.Int32
+	var mu sync.Mutex
+	done := map[int]bool{}
+	errs := Each(context.Background(), l, make([]int, 30), func(_ context.Context, i int) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		done[i] = true
+		mu.Unlock()
+		if i == 29 {
+			return errors.New("last job failed")
+		}
+		return nil
+	})
+	if len(done) != 30 {
+		t.Fatalf("expected all 30 jobs to run, ran %d", len(done))
+	}
+	if peak.Load() > 3 {
+		t.Fatalf("expected at most 3 jobs in flight, saw %d", peak.Load())
+	}
+	if errs[29] == nil || errs[0] != nil {
+		t.Fatalf("expected only job 29 to fail, got %v", errs)
+	}
+}
+
+func TestEachSkipsJobsAfterCancel(t *testing.T) {
+	l := New("test", 1, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := 0
+	errs := Each(ctx, l, make([]int, 5), func(_ context.Context, i int) error {
+		ran++
+		if i == 1 {
+			cancel()
+		}
+		return nil
+	})
+	if ran != 2 {
+		t.Fatalf("expected two jobs before cancel, ran %d", ran)
+	}
+	if !errors.Is(errs[4], context.Canceled) {
+		t.Fatalf("expected skipped jobs to report cancellation, got %v", errs[4])
+	}
+}
@@ -3,9 +3,12 @@ package slop
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
 //go:embed bad_words.json
@@ -18,33 +21,29 @@ var chapterHeadingPattern = regexp.MustCompile(`(?im)^\s*(chapter|ch\.?)\s+([0-9
 var nonWordPattern = regexp.MustCompile(`[^a-z0-9\s]+`)
 var multiSpacePattern = regexp.MustCompile(`\s+`)
 
-// A compact subset of common trigrams used as a proxy for repetitive language.
-var commonTrigrams = map[string]struct{}{
-	"one of the":       {},
-	"as well as":       {},
-	"out of the":       {},
-	"it was a":         {},
-	"to be a":          {},
-	"in the same":      {},
-	"at the same":      {},
-	"was one of":       {},
-	"this is a":        {},
-	"there was a":      {},
-	"in order to":      {},
-	"the end of":       {},
-	"a lot of":         {},
-	"the rest of":      {},
-	"it is a":          {},
-	"for the first":    {},
-	"the beginning of": {},
-}
+// Low originality is flagged when at least this share of a manuscript's
+// trigrams come from the phrase bank; shorter texts are not judged.
+const (
+	lowOriginalityRate       = 0.006
+	minOriginalityTrigrams   = 300
+	maxReportedStockTrigrams = 5
+)
 
 type Report struct {
-	Monotone                    bool
-	MeanSentenceLength          float64
-	SentenceLengthSD            float64
-	BadWordDensity              float64
-	LowOriginality              bool
+	Monotone           bool
+	MeanSentenceLength float64
+	SentenceLengthSD   float64
+	BadWordDensity     float64
+	LowOriginality     bool
+	OriginalityGenre   string
+	// ThresholdGenre names the genre profile the thresholds and dramatic
+	// lexicon were adjusted for; empty when the defaults applied.
+	ThresholdGenre   string
+	StockTrigramRate float64
+	TopStockTrigrams []string
+	// ExcludedWords counts the words Options.Exclusions kept out of the
+	// repetition signals.
+	ExcludedWords               int
 	RepeatedBlockCount          int
 	MaxBlockRepeat              int
 	VerbatimDuplicationCoverage float64
@@ -55,49 +54,124 @@ type Report struct {
 	OptimizationMarkerCount     int
 	AISuspicionScore            int
 	LikelyAIGenerated           bool
-	Flags                       []string
+	Flags                       []Flag
+}
+
+// Options tunes Analyze. The zero value checks originality against the
+// general phrase bank only and scores all text for repetition.
+type Options struct {
+	PhraseBank PhraseBank
+	// Exclusions match text that is repeated by design, such as epigraphs or
+	// quoted lyrics; matched text is removed before the verbatim and
+	// repeated-phrase signals are computed.
+	Exclusions []*regexp.Regexp
+	// Thresholds override DefaultThresholds by metric name, for example
+	// with limits re-fit from reader feedback.
+	Thresholds map[string]float64
+	// BadWords replaces the embedded red-flag vocabulary when non-nil.
+	BadWords []string
+	// Genre is the manuscript's leading genre, if known. A genre with a
+	// GenreProfile adjusts the thresholds Thresholds does not set and the
+	// dramatic lexicon.
+	Genre string
+}
+
+// DefaultThresholds are the limits that raise each flag, by the metric name
+// its evidence reports. SentenceLengthSD and DramaticDensitySD are floors:
+// they count against a manuscript by falling below the limit.
+var DefaultThresholds = map[string]float64{
+	"SentenceLengthSD":            4.0,
+	"BadWordDensity":              0.015,
+	"StockTrigramRate":            lowOriginalityRate,
+	"VerbatimDuplicationCoverage": 0.12,
+	"MaxBlockRepeat":              3,
+	"RepeatedPhraseCoverage":      0.10,
+	"DramaticDensity":             0.055,
+	"DramaticDensitySD":           0.04,
+	"ExpansionMarkerCount":        1,
+	"AISuspicionScore":            45,
+}
+
+// IsFloorMetric reports whether metric flags by falling below its threshold.
+func IsFloorMetric(metric string) bool {
+	return metric == "SentenceLengthSD" || metric == "DramaticDensitySD"
+}
+
+func (o Options) threshold(metric string) float64 {
+	if t, ok := o.Thresholds[metric]; ok {
+		return t
+	}
+	if profile, ok := GenreProfileFor(o.Genre); ok {
+		if t, ok := profile.Thresholds[metric]; ok {
+			return t
+		}
+	}
+	return DefaultThresholds[metric]
 }
 
 func Analyze(text string) Report {
+	return AnalyzeWithOptions(text, Options{})
+}
+
+func AnalyzeWithOptions(text string, opts Options) Report {
+	bank := opts.PhraseBank
+	if bank.Size() == 0 {
+		bank = DefaultPhraseBank()
+	}
 	words := tokenize(text)
 	sentences := splitSentences(text)
 	sd, mean := sentenceLengthStats(text)
-	density := badWordDensity(words)
-	lowOriginality := trigramCommonness(words) >= 0.90
-	dupCoverage, repeatedBlockCount, maxRepeat := repeatedParagraphStats(text, len(words))
-	repeatedPhraseCoverage := repeatedShingleCoverage(words, 12)
-	dramaticDensity, dramaticDensitySD := dramaticProfile(sentences)
+	density := badWordDensity(words, opts.badWords())
+	stockRate, stockTrigrams, trigramCount := trigramCommonness(words, bank)
+	dupText, excludedWords := stripExclusions(text, opts.Exclusions)
+	dupCoverage, repeatedBlockCount, maxRepeat := repeatedParagraphStats(dupText, len(words))
+	repeatedPhraseCoverage := repeatedShingleCoverage(tokenize(dupText), 12)
+	profile, _ := GenreProfileFor(opts.Genre)
+	dramaticDensity, dramaticDensitySD := dramaticProfile(sentences, profile.dramaticWords())
 	expansionMarkerCount := expansionMarkerCount(text)
 	optimizationMarkerCount := optimizationMarkerCount(text)
 
-	flags := make([]string, 0, 7)
-	monotone := sd < 4.0
+	flags := make([]Flag, 0, 8)
+	sdFloor := opts.threshold("SentenceLengthSD")
+	monotone := sd < sdFloor
 	if monotone {
-		flags = append(flags, "Monotone: sentence-length variability is unusually low")
+		flags = append(flags, newFlag(FlagMonotone, "Monotone: sentence-length variability is unusually low", false, metric("SentenceLengthSD", sd, sdFloor)))
 	}
-	if density > 0.015 {
-		flags = append(flags, "High red-flag vocabulary density")
+	if limit := opts.threshold("BadWordDensity"); density > limit {
+		flags = append(flags, newFlag(FlagRedFlagVocabulary, "High red-flag vocabulary density", true, metric("BadWordDensity", density, limit)))
 	}
+	stockLimit := opts.threshold("StockTrigramRate")
+	lowOriginality := trigramCount >= minOriginalityTrigrams && stockRate >= stockLimit
 	if lowOriginality {
-		flags = append(flags, "Low Originality: trigram profile is overly common")
+		flags = append(flags, newFlag(FlagLowOriginality, fmt.Sprintf("Low Originality: %.1f%% of trigrams are stock %s phrasing", stockRate*100, bank.Genre), true, metric("StockTrigramRate", stockRate, stockLimit)))
 	}
-	if dupCoverage >= 0.12 || maxRepeat >= 3 {
-		flags = append(flags, "Verbatim repetition: large blocks are duplicated across the manuscript")
+	dupLimit, repeatLimit := opts.threshold("VerbatimDuplicationCoverage"), opts.threshold("MaxBlockRepeat")
+	if dupCoverage >= dupLimit || float64(maxRepeat) >= repeatLimit {
+		evidence := []EvidenceRef{}
+		if dupCoverage >= dupLimit {
+			evidence = append(evidence, metric("VerbatimDuplicationCoverage", dupCoverage, dupLimit))
+		}
+		if float64(maxRepeat) >= repeatLimit {
+			evidence = append(evidence, metric("MaxBlockRepeat", float64(maxRepeat), repeatLimit))
+		}
+		flags = append(flags, newFlag(FlagVerbatimRepetition, "Verbatim repetition: large blocks are duplicated across the manuscript", true, strongest(evidence)...))
 	}
-	if repeatedPhraseCoverage >= 0.10 {
-		flags = append(flags, "Repeated phrase lattice: long n-grams recur too frequently")
+	if limit := opts.threshold("RepeatedPhraseCoverage"); repeatedPhraseCoverage >= limit {
+		flags = append(flags, newFlag(FlagRepeatedPhrases, "Repeated phrase lattice: long n-grams recur too frequently", true, metric("RepeatedPhraseCoverage", repeatedPhraseCoverage, limit)))
 	}
-	if dramaticDensity >= 0.055 && dramaticDensitySD <= 0.04 {
-		flags = append(flags, "Uniform dramatic saturation: stylistic intensity is unusually constant")
+	if limit, floor := opts.threshold("DramaticDensity"), opts.threshold("DramaticDensitySD"); dramaticDensity >= limit && dramaticDensitySD <= floor {
+		flags = append(flags, newFlag(FlagDramaticSaturation, "Uniform dramatic saturation: stylistic intensity is unusually constant", true,
+			metric("DramaticDensity", dramaticDensity, limit), metric("DramaticDensitySD", dramaticDensitySD, floor)))
 	}
-	if expansionMarkerCount > 0 {
-		flags = append(flags, "Mechanical expansion markers detected (e.g., elaborated/duplicated chapter structure)")
+	if limit := opts.threshold("ExpansionMarkerCount"); float64(expansionMarkerCount) >= limit {
+		flags = append(flags, newFlag(FlagExpansionMarkers, "Mechanical expansion markers detected (e.g., elaborated/duplicated chapter structure)", true, metric("ExpansionMarkerCount", float64(expansionMarkerCount), limit)))
 	}
 
 	aiScore := aiSuspicionScore(dupCoverage, repeatedPhraseCoverage, repeatedBlockCount, maxRepeat, dramaticDensity, dramaticDensitySD, expansionMarkerCount, optimizationMarkerCount)
-	likelyAIGenerated := aiScore >= 45
+	aiLimit := opts.threshold("AISuspicionScore")
+	likelyAIGenerated := float64(aiScore) >= aiLimit
 	if likelyAIGenerated {
-		flags = append(flags, "AI-generation risk is high based on repetition and style-structure signals")
+		flags = append(flags, newFlag(FlagAIGenerationRisk, "AI-generation risk is high based on repetition and style-structure signals", true, metric("AISuspicionScore", float64(aiScore), aiLimit)))
 	}
 
 	return Report{
@@ -106,6 +180,11 @@ func Analyze(text string) Report {
 		SentenceLengthSD:            sd,
 		BadWordDensity:              density,
 		LowOriginality:              lowOriginality,
+		OriginalityGenre:            bank.Genre,
+		ThresholdGenre:              profile.Genre,
+		StockTrigramRate:            stockRate,
+		TopStockTrigrams:            stockTrigrams,
+		ExcludedWords:               excludedWords,
 		RepeatedBlockCount:          repeatedBlockCount,
 		MaxBlockRepeat:              maxRepeat,
 		VerbatimDuplicationCoverage: dupCoverage,
@@ -133,16 +212,42 @@ func splitSentences(text string) []string {
 	return out
 }
 
-func badWordDensity(words []string) float64 {
-	if len(words) == 0 {
-		return 0
-	}
+// badWordSet is bad_words.json, parsed once.
+var badWordSet = sync.OnceValue(func() map[string]struct{} {
 	var raw []string
 	_ = json.Unmarshal(badWordsJSON, &raw)
 	bad := make(map[string]struct{}, len(raw))
 	for _, w := range raw {
 		bad[strings.ToLower(strings.TrimSpace(w))] = struct{}{}
 	}
+	return bad
+})
+
+// DefaultBadWords returns the embedded red-flag vocabulary, sorted.
+func DefaultBadWords() []string {
+	out := make([]string, 0, len(badWordSet()))
+	for w := range badWordSet() {
+		out = append(out, w)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (o Options) badWords() map[string]struct{} {
+	if o.BadWords == nil {
+		return badWordSet()
+	}
+	bad := make(map[string]struct{}, len(o.BadWords))
+	for _, w := range o.BadWords {
+		bad[strings.ToLower(strings.TrimSpace(w))] = struct{}{}
+	}
+	return bad
+}
+
+func badWordDensity(words []string, bad map[string]struct{}) float64 {
+	if len(words) == 0 {
+		return 0
+	}
 	matches := 0
 	for _, w := range words {
 		if _, ok := bad[w]; ok {
@@ -183,23 +288,37 @@ func sentenceLengthStats(text string) (sd float64, mean float64) {
 	return math.Sqrt(variance), mean
 }
 
-func trigramCommonness(words []string) float64 {
+// trigramCommonness returns the share of trigrams found in the bank, the most
+// frequent of those trigrams and the number of trigrams checked.
+func trigramCommonness(words []string, bank PhraseBank) (float64, []string, int) {
 	if len(words) < 3 {
-		return 0
+		return 0, nil, 0
 	}
 	total := 0
 	common := 0
+	hits := map[string]int{}
 	for i := 0; i+2 < len(words); i++ {
 		total++
 		tri := words[i] + " " + words[i+1] + " " + words[i+2]
-		if _, ok := commonTrigrams[tri]; ok {
+		if bank.has(tri) {
 			common++
+			hits[tri]++
 		}
 	}
-	if total == 0 {
-		return 0
+	top := make([]string, 0, len(hits))
+	for tri := range hits {
+		top = append(top, tri)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if hits[top[i]] != hits[top[j]] {
+			return hits[top[i]] > hits[top[j]]
+		}
+		return top[i] < top[j]
+	})
+	if len(top) > maxReportedStockTrigrams {
+		top = top[:maxReportedStockTrigrams]
 	}
-	return float64(common) / float64(total)
+	return float64(common) / float64(total), top, total
 }
 
 func tokenize(text string) []string {
@@ -207,6 +326,20 @@ func tokenize(text string) []string {
 	return parts
 }
 
+// stripExclusions blanks every match of patterns out of text and returns the
+// rest with the number of words removed. A match blanks to a space, so the
+// paragraphs around it keep their breaks.
+func stripExclusions(text string, patterns []*regexp.Regexp) (string, int) {
+	removed := 0
+	for _, re := range patterns {
+		text = re.ReplaceAllStringFunc(text, func(m string) string {
+			removed += len(tokenize(m))
+			return " "
+		})
+	}
+	return text, removed
+}
+
 func repeatedParagraphStats(text string, totalWords int) (coverage float64, repeatedBlocks int, maxRepeat int) {
 	paras := paragraphSplit.Split(text, -1)
 	type paraStat struct {
@@ -275,7 +408,7 @@ func normalizeBlock(s string) string {
 	return s
 }
 
-func dramaticProfile(sentences []string) (mean float64, sd float64) {
+func dramaticProfile(sentences []string, lexicon map[string]struct{}) (mean float64, sd float64) {
 	if len(sentences) == 0 {
 		return 0, 0
 	}
@@ -287,7 +420,7 @@ func dramaticProfile(sentences []string) (mean float64, sd float64) {
 		}
 		hits := 0
 		for _, t := range tokens {
-			if _, ok := dramaticLexicon[t]; ok {
+			if _, ok := lexicon[t]; ok {
 				hits++
 			}
 		}
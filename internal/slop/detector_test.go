@@ -1,6 +1,7 @@
 package slop
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -41,7 +42,7 @@ He tried to remember the warmth of ordinary life, but the room answered with eff
 	if !report.LikelyAIGenerated {
 		t.Fatalf("expected likely ai generated to be true")
 	}
-	joinedFlags := strings.ToLower(strings.Join(report.Flags, " | "))
+	joinedFlags := strings.ToLower(strings.Join(FlagTexts(report.Flags), " | "))
 	if !strings.Contains(joinedFlags, "verbatim") {
 		t.Fatalf("expected verbatim repetition flag, got %+v", report.Flags)
 	}
@@ -63,3 +64,79 @@ func TestAnalyzeDoesNotOverFlagNormalDraft(t *testing.T) {
 		t.Fatalf("expected normal draft not to be marked as likely ai generated (score=%d flags=%v)", report.AISuspicionScore, report.Flags)
 	}
 }
+
+func TestAnalyzeWithExclusionsIgnoresRepeatedEpigraphs(t *testing.T) {
+	epigraph := strings.TrimSpace(`
+The sea remembers every name we give it, and it gives them back to us at night when the tide is high and the lamps are low.
+We were never lost, only waiting for the water to forget us, and it never did, not once in all the long years we sailed.
+`)
+	parts := []string{}
+	for i, body := range []string{
+		"He walked to the station, bought coffee, and missed his train by one minute.",
+		"Rain started around dinner and the streets filled with umbrellas.",
+		"By noon he had made up his mind to visit home.",
+		"He cooked soup, answered two emails, and read old notes before sleeping.",
+	} {
+		parts = append(parts, "Chapter "+string(rune('1'+i)), epigraph, body)
+	}
+	text := strings.Join(parts, "\n\n")
+
+	plain := Analyze(text)
+	if plain.MaxBlockRepeat < 3 {
+		t.Fatalf("expected the repeated epigraph to count without exclusions, got %d", plain.MaxBlockRepeat)
+	}
+	report := AnalyzeWithOptions(text, Options{Exclusions: []*regexp.Regexp{regexp.MustCompile(`(?s)The sea remembers.*?all the long years we sailed\.`)}})
+	if report.RepeatedBlockCount != 0 || report.VerbatimDuplicationCoverage != 0 || report.RepeatedPhraseCoverage != 0 {
+		t.Fatalf("expected excluded epigraphs not to count as repetition, got %+v", report)
+	}
+	if report.ExcludedWords != 4*len(tokenize(epigraph)) {
+		t.Fatalf("expected every epigraph word excluded, got %d", report.ExcludedWords)
+	}
+}
+
+func TestAnalyzeWithCustomBadWords(t *testing.T) {
+	text := "The vibrant tapestry of the city was a testament to its symphony of noise. Gunmetal skies hung over the gunmetal river."
+	if report := Analyze(text); report.BadWordDensity == 0 {
+		t.Fatal("expected the embedded vocabulary to match")
+	}
+	report := AnalyzeWithOptions(text, Options{BadWords: []string{"Gunmetal"}})
+	if want := 2.0 / float64(len(tokenize(text))); report.BadWordDensity != want {
+		t.Fatalf("expected only the custom words counted, got density %.4f want %.4f", report.BadWordDensity, want)
+	}
+	if empty := AnalyzeWithOptions(text, Options{BadWords: []string{}}); empty.BadWordDensity != 0 {
+		t.Fatalf("expected an empty lexicon to match nothing, got %.4f", empty.BadWordDensity)
+	}
+}
+
+func TestAnalyzeWithGenreAdjustsDramaticSaturation(t *testing.T) {
+	sentences := []string{
+		"The blood on the stairs was still warm when she came down.",
+		"A scream rose from the cellar and then stopped all at once.",
+		"Something dark moved behind the curtain of the nursery window.",
+		"The ghost of her mother stood at the end of the long hall.",
+		"Her fear kept her awake until the candle burned down to nothing.",
+		"They found the grave open and the coffin lid split in two.",
+	}
+	text := strings.Join(append(append([]string{}, sentences...), sentences...), " ")
+	hasSaturation := func(r Report) bool {
+		for _, f := range r.Flags {
+			if f.Code == FlagDramaticSaturation {
+				return true
+			}
+		}
+		return false
+	}
+	if report := Analyze(text); !hasSaturation(report) || report.ThresholdGenre != "" {
+		t.Fatalf("expected the defaults to flag uniform saturation, got density %.3f sd %.3f flags %v", report.DramaticDensity, report.DramaticDensitySD, FlagTexts(report.Flags))
+	}
+	horror := AnalyzeWithOptions(text, Options{Genre: "Horror"})
+	if hasSaturation(horror) || horror.ThresholdGenre != "Horror" {
+		t.Fatalf("expected horror conventions not to flag, got density %.3f flags %v", horror.DramaticDensity, FlagTexts(horror.Flags))
+	}
+	if opts := (Options{Genre: "horror", Thresholds: map[string]float64{"DramaticDensitySD": 0.5}}); opts.threshold("DramaticDensitySD") != 0.5 || opts.threshold("DramaticDensity") != 0.075 {
+		t.Fatal("expected explicit thresholds to win over the genre profile")
+	}
+	if opts := (Options{Genre: "Sci-Fi"}); opts.threshold("DramaticDensity") != 0.065 {
+		t.Fatal("expected Sci-Fi to match the scifi profile")
+	}
+}
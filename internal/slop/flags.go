@@ -0,0 +1,145 @@
+package slop
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Flag codes name what a flag measures. They stay stable across releases, so
+// the UI can filter and suppress flags by code and exporters can translate
+// them; Text is the English wording and may change.
+const (
+	FlagMonotone           = "monotone"
+	FlagRedFlagVocabulary  = "red_flag_vocabulary"
+	FlagLowOriginality     = "low_originality"
+	FlagVerbatimRepetition = "verbatim_repetition"
+	FlagRepeatedPhrases    = "repeated_phrases"
+	FlagDramaticSaturation = "dramatic_saturation"
+	FlagExpansionMarkers   = "expansion_markers"
+	FlagAIGenerationRisk   = "ai_generation_risk"
+)
+
+// Flag severities, in the HIGH/MED/LOW scale contradictions use.
+const (
+	SeverityHigh = "HIGH"
+	SeverityMed  = "MED"
+	SeverityLow  = "LOW"
+)
+
+// flagSeverity is how much each kind of flag matters to a manuscript:
+// duplicated blocks and a high AI-generation score are worth acting on,
+// while rhythm and vocabulary flags are matters of style.
+var flagSeverity = map[string]string{
+	FlagMonotone:           SeverityLow,
+	FlagRedFlagVocabulary:  SeverityLow,
+	FlagLowOriginality:     SeverityMed,
+	FlagVerbatimRepetition: SeverityHigh,
+	FlagRepeatedPhrases:    SeverityMed,
+	FlagDramaticSaturation: SeverityLow,
+	FlagExpansionMarkers:   SeverityMed,
+	FlagAIGenerationRisk:   SeverityHigh,
+}
+
+// flagLabels are the leading words of each flag's text, used to recognize
+// flags saved as bare strings by earlier versions.
+var flagLabels = []struct{ prefix, code string }{
+	{"Monotone", FlagMonotone},
+	{"High red-flag vocabulary density", FlagRedFlagVocabulary},
+	{"Low Originality", FlagLowOriginality},
+	{"Verbatim repetition", FlagVerbatimRepetition},
+	{"Repeated phrase lattice", FlagRepeatedPhrases},
+	{"Uniform dramatic saturation", FlagDramaticSaturation},
+	{"Mechanical expansion markers", FlagExpansionMarkers},
+	{"AI-generation risk", FlagAIGenerationRisk},
+}
+
+// Flag is one finding of the statistical scan.
+type Flag struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	// Confidence is how clearly the evidence passes its threshold, from 0.5
+	// at the threshold to 1 at double (or, for a floor, zero); 0 when unknown.
+	Confidence float64 `json:"confidence"`
+	// Evidence lists the report metrics that raised the flag.
+	Evidence []EvidenceRef `json:"evidence"`
+	Text     string        `json:"text"`
+	// AnchorID is set by the dashboard; it names the flag across runs.
+	AnchorID string `json:"anchorId,omitempty"`
+}
+
+// EvidenceRef points at a Report metric and the threshold it crossed.
+type EvidenceRef struct {
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+func (f Flag) String() string { return f.Text }
+
+// UnmarshalJSON also accepts a flag saved as a bare string, recovering its
+// code and severity from the wording.
+func (f *Flag) UnmarshalJSON(raw []byte) error {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		*f = Flag{Code: flagCodeForText(text), Text: text, Evidence: []EvidenceRef{}}
+		f.Severity = flagSeverity[f.Code]
+		return nil
+	}
+	type plain Flag
+	return json.Unmarshal(raw, (*plain)(f))
+}
+
+func flagCodeForText(text string) string {
+	for _, l := range flagLabels {
+		if strings.HasPrefix(text, l.prefix) {
+			return l.code
+		}
+	}
+	return ""
+}
+
+// newFlag builds a flag from the metrics that raised it. The first evidence
+// entry sets the confidence; above says whether the metric passes its
+// threshold by exceeding it or by falling below it.
+func newFlag(code, text string, above bool, evidence ...EvidenceRef) Flag {
+	f := Flag{Code: code, Severity: flagSeverity[code], Text: text, Evidence: evidence}
+	if len(evidence) > 0 {
+		f.Confidence = flagConfidence(evidence[0].Value, evidence[0].Threshold, above)
+	}
+	return f
+}
+
+func flagConfidence(value, threshold float64, above bool) float64 {
+	if threshold == 0 {
+		return 1
+	}
+	margin := (value - threshold) / threshold
+	if !above {
+		margin = -margin
+	}
+	return math.Round(math.Min(1, math.Max(0.5, 0.5+margin/2))*100) / 100
+}
+
+// FlagTexts returns the wording of flags, for plain-text listings.
+func FlagTexts(flags []Flag) []string {
+	out := make([]string, 0, len(flags))
+	for _, f := range flags {
+		out = append(out, f.Text)
+	}
+	return out
+}
+
+// strongest orders evidence of which any one entry raises the flag, so the
+// entry furthest past its threshold comes first and sets the confidence.
+func strongest(evidence []EvidenceRef) []EvidenceRef {
+	sort.SliceStable(evidence, func(i, j int) bool {
+		return flagConfidence(evidence[i].Value, evidence[i].Threshold, true) > flagConfidence(evidence[j].Value, evidence[j].Threshold, true)
+	})
+	return evidence
+}
+
+func metric(name string, value, threshold float64) EvidenceRef {
+	return EvidenceRef{Metric: name, Value: value, Threshold: threshold}
+}
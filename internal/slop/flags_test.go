@@ -0,0 +1,55 @@
+package slop
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFlagsCarryCodeSeverityConfidenceAndEvidence(t *testing.T) {
+	// Two twenty-word sentences, a paragraph long enough to count as a block.
+	block := "The hallway was quiet and the lamps burned low while the guards walked their slow rounds past the locked doors. " +
+		"The kitchen was warm and the ovens glowed red while the cooks kneaded their heavy dough beside the open windows."
+	report := Analyze(strings.Repeat(block+"\n\n", 4))
+
+	byCode := map[string]Flag{}
+	for _, f := range report.Flags {
+		byCode[f.Code] = f
+	}
+	verbatim, ok := byCode[FlagVerbatimRepetition]
+	if !ok || verbatim.Severity != SeverityHigh || verbatim.Text == "" {
+		t.Fatalf("expected a HIGH verbatim repetition flag, got %+v", report.Flags)
+	}
+	// Every paragraph repeats, so coverage is far past its 12% threshold.
+	if verbatim.Confidence != 1 || verbatim.Evidence[0].Metric != "VerbatimDuplicationCoverage" || verbatim.Evidence[0].Threshold != 0.12 {
+		t.Fatalf("expected full confidence led by duplication coverage, got %+v", verbatim)
+	}
+	if monotone, ok := byCode[FlagMonotone]; !ok || monotone.Severity != SeverityLow || monotone.Confidence != 1 {
+		t.Fatalf("expected identical sentences to be monotone with full confidence, got %+v", monotone)
+	}
+}
+
+func TestFlagDecodesLegacyStrings(t *testing.T) {
+	var report Report
+	if err := json.Unmarshal([]byte(`{"Flags":["Low Originality: 1.2% of trigrams are stock romance phrasing",{"code":"monotone","severity":"LOW","confidence":0.7,"evidence":[],"text":"Monotone"}]}`), &report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(report.Flags) != 2 || report.Flags[0].Code != FlagLowOriginality || report.Flags[0].Severity != SeverityMed || report.Flags[0].Confidence != 0 {
+		t.Fatalf("expected the bare string recognized as low originality, got %+v", report.Flags)
+	}
+	if report.Flags[1].Code != FlagMonotone || report.Flags[1].Confidence != 0.7 {
+		t.Fatalf("expected structured flags decoded as saved, got %+v", report.Flags[1])
+	}
+}
+
+func TestThresholdOverridesChangeWhichFlagsFire(t *testing.T) {
+	block := "The hallway was quiet and the lamps burned low while the guards walked their slow rounds past the locked doors. " +
+		"The kitchen was warm and the ovens glowed red while the cooks kneaded their heavy dough beside the open windows."
+	text := strings.Repeat(block+"\n\n", 4)
+	relaxed := AnalyzeWithOptions(text, Options{Thresholds: map[string]float64{"VerbatimDuplicationCoverage": 2, "MaxBlockRepeat": 10, "SentenceLengthSD": 0}})
+	for _, f := range relaxed.Flags {
+		if f.Code == FlagVerbatimRepetition || f.Code == FlagMonotone {
+			t.Fatalf("expected raised thresholds to silence %s, got %+v", f.Code, relaxed.Flags)
+		}
+	}
+}
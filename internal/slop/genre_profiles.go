@@ -0,0 +1,65 @@
+package slop
+
+// GenreProfile adjusts the checks for a genre whose conventions trip the
+// defaults. Horror is dark and dread-laden from the first page to the last,
+// so the dramatic vocabulary that marks uniform saturation in a literary
+// novel is ordinary there; a literary novel, in turn, is expected to vary
+// its sentences and its intensity more than a pulp thriller.
+type GenreProfile struct {
+	Genre string
+	// Thresholds override DefaultThresholds by metric name.
+	Thresholds map[string]float64
+	// NativeDramatic are dramatic-lexicon words the genre uses as plain
+	// vocabulary; they do not count toward dramatic density.
+	NativeDramatic []string
+}
+
+// genreProfiles are keyed by GenreKey. Genres not listed use the defaults.
+var genreProfiles = map[string]GenreProfile{
+	"horror": {
+		Genre:          "Horror",
+		Thresholds:     map[string]float64{"DramaticDensity": 0.075, "DramaticDensitySD": 0.025},
+		NativeDramatic: []string{"blood", "fear", "grave", "ghost", "tomb", "dark", "hollow", "doom", "claw", "clawed", "claws", "scream", "screamed", "haunting", "haunted"},
+	},
+	"thriller": {
+		Genre:          "Thriller",
+		Thresholds:     map[string]float64{"DramaticDensity": 0.07, "DramaticDensitySD": 0.03},
+		NativeDramatic: []string{"blood", "fear", "dark", "fatal"},
+	},
+	"fantasy": {
+		Genre:          "Fantasy",
+		Thresholds:     map[string]float64{"DramaticDensity": 0.065},
+		NativeDramatic: []string{"tomb", "ghost", "doom", "iron", "ruin", "eternal"},
+	},
+	"scifi": {
+		Genre:          "Sci-Fi",
+		Thresholds:     map[string]float64{"DramaticDensity": 0.065},
+		NativeDramatic: []string{"metallic", "sterile", "infinite", "compliance", "obedience"},
+	},
+	"literary": {
+		Genre:      "Literary",
+		Thresholds: map[string]float64{"SentenceLengthSD": 4.5, "DramaticDensity": 0.045},
+	},
+}
+
+// GenreProfileFor returns the profile for genre, matched as GenreKey matches
+// phrase banks, and whether the genre has one.
+func GenreProfileFor(genre string) (GenreProfile, bool) {
+	profile, ok := genreProfiles[GenreKey(genre)]
+	return profile, ok
+}
+
+// dramaticWords is the dramatic lexicon without the profile's native words.
+func (p GenreProfile) dramaticWords() map[string]struct{} {
+	if len(p.NativeDramatic) == 0 {
+		return dramaticLexicon
+	}
+	words := make(map[string]struct{}, len(dramaticLexicon))
+	for w := range dramaticLexicon {
+		words[w] = struct{}{}
+	}
+	for _, w := range p.NativeDramatic {
+		delete(words, w)
+	}
+	return words
+}
@@ -0,0 +1,65 @@
+package slop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minLintSentences is how many sentences an excerpt needs before its rhythm
+// is judged; a short paragraph is naturally uneven or even.
+const minLintSentences = 5
+
+// LintReport is the part of Report cheap enough to recompute as the author
+// types: sentence rhythm, dramatic density and red-flag vocabulary. The
+// whole-manuscript signals (originality, repetition, expansion markers) are
+// left to Analyze.
+type LintReport struct {
+	Monotone           bool
+	MeanSentenceLength float64
+	SentenceLengthSD   float64
+	BadWordDensity     float64
+	DramaticDensity    float64
+	// RedFlagWords are the red-flag vocabulary hits in text order.
+	RedFlagWords []WordHit
+	Flags        []Flag
+}
+
+// WordHit is one word of the text, with byte offsets.
+type WordHit struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// Lint checks an excerpt with the signals that need no other text.
+func Lint(text string) LintReport {
+	sentences := splitSentences(text)
+	sd, mean := sentenceLengthStats(text)
+	dramatic, _ := dramaticProfile(sentences, dramaticLexicon)
+	report := LintReport{
+		MeanSentenceLength: mean,
+		SentenceLengthSD:   sd,
+		DramaticDensity:    dramatic,
+		RedFlagWords:       []WordHit{},
+		Flags:              []Flag{},
+	}
+	bad := badWordSet()
+	lower := strings.ToLower(text)
+	locs := wordPattern.FindAllStringIndex(lower, -1)
+	for _, loc := range locs {
+		if _, ok := bad[lower[loc[0]:loc[1]]]; ok {
+			report.RedFlagWords = append(report.RedFlagWords, WordHit{Word: text[loc[0]:loc[1]], Start: loc[0], End: loc[1]})
+		}
+	}
+	if len(locs) > 0 {
+		report.BadWordDensity = float64(len(report.RedFlagWords)) / float64(len(locs))
+	}
+	if len(sentences) >= minLintSentences && sd < 4.0 {
+		report.Monotone = true
+		report.Flags = append(report.Flags, newFlag(FlagMonotone, "Monotone: sentence-length variability is unusually low", false, metric("SentenceLengthSD", sd, 4.0)))
+	}
+	if report.BadWordDensity > 0.015 {
+		report.Flags = append(report.Flags, newFlag(FlagRedFlagVocabulary, fmt.Sprintf("High red-flag vocabulary density (%d words)", len(report.RedFlagWords)), true, metric("BadWordDensity", report.BadWordDensity, 0.015)))
+	}
+	return report
+}
@@ -0,0 +1,20 @@
+package slop
+
+import "testing"
+
+func TestLintReportsRedFlagWordsWithOffsets(t *testing.T) {
+	text := "We delve into it. The tapestry held. She ran. He ran. They ran. It ran."
+	report := Lint(text)
+	if len(report.RedFlagWords) != 2 {
+		t.Fatalf("want 2 red-flag words, got %+v", report.RedFlagWords)
+	}
+	if hit := report.RedFlagWords[1]; hit.Word != "tapestry" || text[hit.Start:hit.End] != "tapestry" {
+		t.Fatalf("unexpected hit %+v", hit)
+	}
+	if !report.Monotone || len(report.Flags) != 2 {
+		t.Fatalf("want monotone rhythm and dense red-flag vocabulary flagged, got %+v", report)
+	}
+	if short := Lint("She ran. He ran."); short.Monotone {
+		t.Fatalf("want too few sentences left unjudged, got %+v", short)
+	}
+}
@@ -0,0 +1,109 @@
+package slop
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed phrase_banks/*.txt
+var phraseBankFS embed.FS
+
+// GeneralPhraseBank is always loaded; genre banks add to it.
+const GeneralPhraseBank = "general"
+
+// PhraseBank is a set of stock-phrase trigrams: phrasing so conventional for
+// fiction, or for one genre, that a high share of it reads as unoriginal.
+type PhraseBank struct {
+	Genre    string
+	Sources  []string
+	trigrams map[string]struct{}
+}
+
+// Size is the number of distinct trigrams in the bank.
+func (b PhraseBank) Size() int {
+	return len(b.trigrams)
+}
+
+// DefaultPhraseBank returns the embedded general bank.
+func DefaultPhraseBank() PhraseBank {
+	bank, _ := LoadPhraseBank("", "")
+	return bank
+}
+
+// LoadPhraseBank merges the embedded general bank, the embedded bank for genre
+// and, when packDir is set, the downloaded packs packDir/general.txt and
+// packDir/<genre>.txt. Genre names are matched case-insensitively with
+// punctuation dropped ("Sci-Fi" loads scifi.txt). A pack that cannot be read
+// is reported as an error alongside the bank built from everything else.
+func LoadPhraseBank(genre, packDir string) (PhraseBank, error) {
+	key := GenreKey(genre)
+	bank := PhraseBank{Genre: GeneralPhraseBank, trigrams: map[string]struct{}{}}
+	names := []string{GeneralPhraseBank}
+	if key != "" && key != GeneralPhraseBank {
+		names = append(names, key)
+	}
+	var errs []error
+	for _, name := range names {
+		if raw, err := phraseBankFS.ReadFile("phrase_banks/" + name + ".txt"); err == nil {
+			bank.add(string(raw))
+			bank.Sources = append(bank.Sources, "embedded:"+name)
+			if name != GeneralPhraseBank {
+				bank.Genre = name
+			}
+		}
+		if strings.TrimSpace(packDir) == "" {
+			continue
+		}
+		path := filepath.Join(packDir, name+".txt")
+		raw, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read phrase bank %s: %w", path, err))
+			continue
+		}
+		bank.add(string(raw))
+		bank.Sources = append(bank.Sources, path)
+		if name != GeneralPhraseBank {
+			bank.Genre = name
+		}
+	}
+	return bank, errors.Join(errs...)
+}
+
+func (b *PhraseBank) add(raw string) {
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words := tokenize(line)
+		for i := 0; i+2 < len(words); i++ {
+			b.trigrams[words[i]+" "+words[i+1]+" "+words[i+2]] = struct{}{}
+		}
+	}
+}
+
+func (b PhraseBank) has(trigram string) bool {
+	_, ok := b.trigrams[trigram]
+	return ok
+}
+
+// GenreKey is the file-name form of a genre: lowercase letters and digits
+// only, so "Sci-Fi" is scifi.
+func GenreKey(genre string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(genre) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package slop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPhraseBankMergesGenreAndPacks(t *testing.T) {
+	general := DefaultPhraseBank()
+	if general.Genre != GeneralPhraseBank || general.Size() == 0 {
+		t.Fatalf("unexpected default bank %+v", general.Sources)
+	}
+	romance, err := LoadPhraseBank("Romance", "")
+	if err != nil {
+		t.Fatalf("load romance: %v", err)
+	}
+	if romance.Genre != "romance" || romance.Size() <= general.Size() || !romance.has("heart skipped a") {
+		t.Fatalf("expected romance bank on top of general, got genre=%q size=%d", romance.Genre, romance.Size())
+	}
+	if scifi, _ := LoadPhraseBank("Sci-Fi", ""); scifi.Genre != "scifi" {
+		t.Fatalf("expected Sci-Fi to resolve to scifi, got %q", scifi.Genre)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "romance.txt"), []byte("# pack\nthe duke's smoldering gaze\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	packed, err := LoadPhraseBank("romance", dir)
+	if err != nil {
+		t.Fatalf("load pack: %v", err)
+	}
+	if !packed.has("duke's smoldering gaze") || len(packed.Sources) != 3 {
+		t.Fatalf("expected pack merged, got sources %v", packed.Sources)
+	}
+}
+
+func TestAnalyzeFlagsGenreStockPhrasing(t *testing.T) {
+	cliche := "Her heart skipped a beat when their eyes met across the room, and a blush crept up her cheeks. "
+	plain := "The train left at nine and she counted the fields as they passed the window in silence. "
+	text := strings.Repeat(plain+cliche, 20)
+
+	general := Analyze(text)
+	romance := AnalyzeWithOptions(text, Options{PhraseBank: mustPhraseBank(t, "romance")})
+	if romance.StockTrigramRate <= general.StockTrigramRate {
+		t.Fatalf("expected romance bank to find more stock phrasing: general=%.3f romance=%.3f", general.StockTrigramRate, romance.StockTrigramRate)
+	}
+	if !romance.LowOriginality || romance.OriginalityGenre != "romance" || len(romance.TopStockTrigrams) == 0 {
+		t.Fatalf("expected low originality against romance conventions, got %+v", romance)
+	}
+	if !strings.Contains(strings.Join(FlagTexts(romance.Flags), " | "), "stock romance phrasing") {
+		t.Fatalf("expected genre named in flag, got %v", romance.Flags)
+	}
+
+	short := Analyze(cliche)
+	if short.LowOriginality {
+		t.Fatalf("short texts should not be judged for originality")
+	}
+}
+
+func mustPhraseBank(t *testing.T, genre string) PhraseBank {
+	t.Helper()
+	bank, err := LoadPhraseBank(genre, "")
+	if err != nil {
+		t.Fatalf("load %s: %v", genre, err)
+	}
+	return bank
+}
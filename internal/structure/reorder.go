@@ -0,0 +1,262 @@
+package structure
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChapterFacts is what the reorder simulation knows about one chapter.
+type ChapterFacts struct {
+	// Index is the chapter's number in the manuscript as written.
+	Index int
+	Title string
+	// Year is the story year the chapter is set in, 0 when unknown.
+	Year int
+	// Flashback marks chapters narrated as the past of the main line; they are
+	// exempt from chronology but should not open the book or run back to back.
+	Flashback bool
+	// Beat names the beat window this chapter carries, "" for none.
+	Beat string
+}
+
+// Weights of the order score components, and the cost of moving a chapter
+// across the whole book, which keeps suggestions to the smallest useful edit.
+const (
+	beatFitWeight    = 0.5
+	chronologyWeight = 0.3
+	pacingWeight     = 0.2
+	disruptionWeight = 0.1
+
+	minReorderGain = 0.01
+)
+
+// OrderScore rates how well a chapter order fits the beat windows, story
+// chronology and flashback pacing. Components and Total are 0-1.
+type OrderScore struct {
+	Total      float64
+	BeatFit    float64
+	Chronology float64
+	Pacing     float64
+}
+
+// ChapterMove moves the chapter with the given index from one 1-based
+// position to another.
+type ChapterMove struct {
+	Chapter int
+	From    int
+	To      int
+}
+
+type ReorderSuggestion struct {
+	Order     []int
+	Move      ChapterMove
+	Score     OrderScore
+	Gain      float64
+	Rationale []string
+}
+
+type orderDiagnostics struct {
+	beatOffset     map[string]int
+	inversions     int
+	datedPairs     int
+	openingBacks   int
+	adjacentBacks  int
+	flashbackCount int
+}
+
+// ScoreOrder scores order, a permutation of chapter indexes from facts.
+func ScoreOrder(facts []ChapterFacts, order []int, windows []BeatWindow) OrderScore {
+	score, _ := scoreOrder(facts, order, windows)
+	return score
+}
+
+func scoreOrder(facts []ChapterFacts, order []int, windows []BeatWindow) (OrderScore, orderDiagnostics) {
+	byIndex := make(map[int]ChapterFacts, len(facts))
+	for _, f := range facts {
+		byIndex[f.Index] = f
+	}
+	n := len(order)
+	diag := orderDiagnostics{beatOffset: map[string]int{}}
+
+	beatFit, beats := 0.0, 0
+	for _, w := range windows {
+		start, end := ChaptersInWindow(n, w.StartRatio, w.EndRatio)
+		for pos, idx := range order {
+			if byIndex[idx].Beat != w.Name {
+				continue
+			}
+			offset := 0
+			if pos+1 < start {
+				offset = pos + 1 - start
+			} else if pos+1 > end {
+				offset = pos + 1 - end
+			}
+			diag.beatOffset[w.Name] = offset
+			tolerance := math.Max(1, float64(n)*0.1)
+			beatFit += 1 - math.Min(1, math.Abs(float64(offset))/tolerance)
+			beats++
+			break
+		}
+	}
+
+	dated := make([]int, 0, n)
+	for _, idx := range order {
+		if f := byIndex[idx]; f.Year > 0 && !f.Flashback {
+			dated = append(dated, f.Year)
+		}
+	}
+	for i := range dated {
+		for j := i + 1; j < len(dated); j++ {
+			diag.datedPairs++
+			if dated[i] > dated[j] {
+				diag.inversions++
+			}
+		}
+	}
+
+	// The opening runs up to the catalyst; the first chapter always counts.
+	catalyst, _ := ChaptersInWindow(n, SaveTheCatWindows[0].StartRatio, SaveTheCatWindows[0].EndRatio)
+	for pos, idx := range order {
+		if !byIndex[idx].Flashback {
+			continue
+		}
+		diag.flashbackCount++
+		if pos == 0 || pos+1 < catalyst {
+			diag.openingBacks++
+		}
+		if pos > 0 && byIndex[order[pos-1]].Flashback {
+			diag.adjacentBacks++
+		}
+	}
+
+	score := OrderScore{BeatFit: 1, Chronology: 1, Pacing: 1}
+	if beats > 0 {
+		score.BeatFit = beatFit / float64(beats)
+	}
+	if diag.datedPairs > 0 {
+		score.Chronology = 1 - float64(diag.inversions)/float64(diag.datedPairs)
+	}
+	if diag.flashbackCount > 0 {
+		score.Pacing = 1 - math.Min(1, float64(diag.openingBacks+diag.adjacentBacks)/float64(diag.flashbackCount))
+	}
+	score.Total = beatFitWeight*score.BeatFit + chronologyWeight*score.Chronology + pacingWeight*score.Pacing
+	return score, diag
+}
+
+// SimulateReorders tries moving each chapter to every other position and
+// returns the current order's score with up to limit moves that improve it
+// most, net of how far the chapter moves.
+func SimulateReorders(facts []ChapterFacts, windows []BeatWindow, limit int) (OrderScore, []ReorderSuggestion) {
+	current := make([]int, len(facts))
+	titles := make(map[int]string, len(facts))
+	for i, f := range facts {
+		current[i] = f.Index
+		titles[f.Index] = f.Title
+	}
+	base, baseDiag := scoreOrder(facts, current, windows)
+	n := len(current)
+	seen := map[string]bool{orderKey(current): true}
+	out := []ReorderSuggestion{}
+	for from := 0; from < n; from++ {
+		for to := 0; to < n; to++ {
+			if from == to {
+				continue
+			}
+			order := moveChapter(current, from, to)
+			key := orderKey(order)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			score, diag := scoreOrder(facts, order, windows)
+			distance := math.Abs(float64(to-from)) / float64(n)
+			gain := score.Total - base.Total - disruptionWeight*distance
+			if gain < minReorderGain {
+				continue
+			}
+			move := ChapterMove{Chapter: current[from], From: from + 1, To: to + 1}
+			out = append(out, ReorderSuggestion{
+				Order:     order,
+				Move:      move,
+				Score:     score,
+				Gain:      math.Round(gain*1000) / 1000,
+				Rationale: reorderRationale(move, titles[move.Chapter], baseDiag, diag),
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Gain > out[j].Gain })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return base, out
+}
+
+func moveChapter(order []int, from, to int) []int {
+	out := make([]int, 0, len(order))
+	moved := order[from]
+	for i, idx := range order {
+		if i != from {
+			out = append(out, idx)
+		}
+	}
+	out = append(out[:to], append([]int{moved}, out[to:]...)...)
+	return out
+}
+
+func orderKey(order []int) string {
+	parts := make([]string, len(order))
+	for i, idx := range order {
+		parts[i] = strconv.Itoa(idx)
+	}
+	return strings.Join(parts, ",")
+}
+
+func reorderRationale(move ChapterMove, title string, before, after orderDiagnostics) []string {
+	lines := []string{fmt.Sprintf("Move chapter %d (%s) from position %d to %d.", move.Chapter, title, move.From, move.To)}
+	names := make([]string, 0, len(after.beatOffset))
+	for name := range after.beatOffset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		was, now := before.beatOffset[name], after.beatOffset[name]
+		if abs(now) < abs(was) {
+			lines = append(lines, fmt.Sprintf("%s moves from %s to %s.", name, beatOffsetWords(was), beatOffsetWords(now)))
+		} else if abs(now) > abs(was) {
+			lines = append(lines, fmt.Sprintf("Trade-off: %s moves from %s to %s.", name, beatOffsetWords(was), beatOffsetWords(now)))
+		}
+	}
+	if after.inversions < before.inversions {
+		lines = append(lines, fmt.Sprintf("Out-of-order dated chapter pairs drop from %d to %d.", before.inversions, after.inversions))
+	} else if after.inversions > before.inversions {
+		lines = append(lines, fmt.Sprintf("Trade-off: out-of-order dated chapter pairs rise from %d to %d.", before.inversions, after.inversions))
+	}
+	if after.openingBacks < before.openingBacks {
+		lines = append(lines, "A flashback no longer delays the opening before the catalyst.")
+	}
+	if after.adjacentBacks < before.adjacentBacks {
+		lines = append(lines, "Back-to-back flashbacks are separated by present-line chapters.")
+	}
+	return lines
+}
+
+func beatOffsetWords(offset int) string {
+	switch {
+	case offset == 0:
+		return "inside its window"
+	case offset < 0:
+		return fmt.Sprintf("%d chapters early", -offset)
+	default:
+		return fmt.Sprintf("%d chapters late", offset)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,72 @@
+package structure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulateReordersMovesLateBeatIntoWindow(t *testing.T) {
+	facts := make([]ChapterFacts, 20)
+	for i := range facts {
+		facts[i] = ChapterFacts{Index: i + 1, Title: "Ch"}
+	}
+	// The midpoint chapter sits at position 16, well after its 10-12 window.
+	facts[15].Beat = "Midpoint"
+
+	current, suggestions := SimulateReorders(facts, SaveTheCatWindows, 3)
+	if current.BeatFit >= 1 || len(suggestions) == 0 {
+		t.Fatalf("expected a misplaced beat to produce suggestions, got %+v %+v", current, suggestions)
+	}
+	best := suggestions[0]
+	if best.Move.Chapter != 16 || best.Move.To < 10 || best.Move.To > 12 {
+		t.Fatalf("expected chapter 16 moved into the midpoint window, got %+v", best.Move)
+	}
+	if best.Score.BeatFit != 1 || best.Gain <= 0 {
+		t.Fatalf("expected full beat fit and a positive gain, got %+v", best)
+	}
+	if !strings.Contains(strings.Join(best.Rationale, " "), "Midpoint moves from 4 chapters late to inside its window") {
+		t.Fatalf("unexpected rationale %v", best.Rationale)
+	}
+}
+
+func TestSimulateReordersMovesOpeningFlashback(t *testing.T) {
+	facts := []ChapterFacts{
+		{Index: 1, Title: "Childhood", Flashback: true},
+		{Index: 2, Title: "Arrival", Year: 2001},
+		{Index: 3, Title: "Storm", Year: 2002},
+		{Index: 4, Title: "Harbor", Year: 2003},
+	}
+	current, suggestions := SimulateReorders(facts, SaveTheCatWindows, 3)
+	if current.Pacing != 0 || len(suggestions) == 0 {
+		t.Fatalf("expected an opening flashback to be penalized, got %+v", current)
+	}
+	best := suggestions[0]
+	if best.Move.Chapter != 1 || best.Move.To != 2 || best.Score.Pacing != 1 {
+		t.Fatalf("expected the smallest move of the flashback out of the opening, got %+v", best)
+	}
+}
+
+func TestSimulateReordersRestoresChronology(t *testing.T) {
+	facts := []ChapterFacts{
+		{Index: 1, Title: "Arrival", Year: 2001},
+		{Index: 2, Title: "Storm", Year: 2003},
+		{Index: 3, Title: "Letter", Year: 2002},
+		{Index: 4, Title: "Harbor", Year: 2004},
+	}
+	current, suggestions := SimulateReorders(facts, SaveTheCatWindows, 3)
+	if current.Chronology >= 1 || len(suggestions) == 0 {
+		t.Fatalf("expected an inversion penalty, got %+v", current)
+	}
+	best := suggestions[0]
+	if best.Score.Chronology != 1 || !strings.Contains(strings.Join(best.Rationale, " "), "drop from 1 to 0") {
+		t.Fatalf("expected the best move to restore chronology, got %+v", best)
+	}
+}
+
+func TestSimulateReordersLeavesGoodOrderAlone(t *testing.T) {
+	facts := []ChapterFacts{{Index: 1, Year: 1990}, {Index: 2, Year: 1991}, {Index: 3, Year: 1992}, {Index: 4, Year: 1993}}
+	current, suggestions := SimulateReorders(facts, SaveTheCatWindows, 3)
+	if current.Total != 1 || len(suggestions) != 0 {
+		t.Fatalf("expected no suggestions for an ordered book, got %+v %+v", current, suggestions)
+	}
+}
@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CanaryPath holds the answers a model gave to the fixed canary prompts, so a
+// later version of the same model can be checked against them. One file per
+// model name, shared by every project.
+func CanaryPath(workspaceRoot, model string) string {
+	return filepath.Join(workspaceRoot, "canary", SeriesSlug(model)+".json")
+}
+
+// LoadCanary decodes the recorded canary answers of model into v. It reports
+// false, leaving v untouched, when none were recorded.
+func LoadCanary(workspaceRoot, model string, v any) (bool, error) {
+	raw, err := os.ReadFile(CanaryPath(workspaceRoot, model))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read canary reference: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("decode canary reference: %w", err)
+	}
+	return true, nil
+}
+
+// SaveCanary records the canary answers of model, replacing earlier ones.
+func SaveCanary(workspaceRoot, model string, v any) error {
+	path := CanaryPath(workspaceRoot, model)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create canary dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal canary reference: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("write canary reference: %w", err)
+	}
+	return nil
+}
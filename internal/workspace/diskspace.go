@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInsufficientDisk is returned when the workspace volume is too full to
+// hold a project's source copy, caches and report.
+var ErrInsufficientDisk = errors.New("insufficient disk space")
+
+const (
+	// DefaultMinFreeDiskMB is the hard floor below which projects are refused;
+	// MHD_MIN_FREE_DISK_MB overrides it.
+	DefaultMinFreeDiskMB = 64
+	// LowDiskWarnMB is the free space below which runs proceed with a warning.
+	LowDiskWarnMB = 512
+
+	// projectSizeFactor approximates source copy + caches + report + db as a
+	// multiple of the source size.
+	projectSizeFactor = 4
+)
+
+// DiskStatus describes free space on the volume holding a path. Known is
+// false on platforms where free space cannot be queried.
+type DiskStatus struct {
+	AvailableBytes uint64
+	RequiredBytes  uint64
+	Known          bool
+}
+
+func (s DiskStatus) Low() bool {
+	return s.Known && s.AvailableBytes < LowDiskWarnMB<<20
+}
+
+func (s DiskStatus) Sufficient() bool {
+	return !s.Known || s.AvailableBytes >= s.RequiredBytes
+}
+
+// RequiredProjectBytes is the free space needed before writing a project for
+// a source of the given size.
+func RequiredProjectBytes(sourceSize int) uint64 {
+	return minFreeDiskBytes() + uint64(max(0, sourceSize))*projectSizeFactor
+}
+
+func CheckDiskSpace(path string, sourceSize int) DiskStatus {
+	available, known := availableBytes(path)
+	return DiskStatus{AvailableBytes: available, RequiredBytes: RequiredProjectBytes(sourceSize), Known: known}
+}
+
+// EnsureDiskSpace refuses with ErrInsufficientDisk when the volume cannot hold
+// the project, so a full disk fails up front instead of at report write time.
+func EnsureDiskSpace(path string, sourceSize int) error {
+	status := CheckDiskSpace(path, sourceSize)
+	if status.Sufficient() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s free at %s, %s required", ErrInsufficientDisk, formatMB(status.AvailableBytes), path, formatMB(status.RequiredBytes))
+}
+
+func minFreeDiskBytes() uint64 {
+	mb := DefaultMinFreeDiskMB
+	if raw := strings.TrimSpace(os.Getenv("MHD_MIN_FREE_DISK_MB")); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v >= 0 {
+			mb = v
+		}
+	}
+	return uint64(mb) << 20
+}
+
+func formatMB(b uint64) string {
+	return strconv.FormatUint(b>>20, 10) + " MB"
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package workspace
+
+func availableBytes(string) (uint64, bool) {
+	return 0, false
+}
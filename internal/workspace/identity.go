@@ -0,0 +1,181 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProjectIndexEntry records which manuscript content a project directory
+// belongs to, so identical content maps to one project whatever its title and
+// different content never shares a directory because the titles match.
+type ProjectIndexEntry struct {
+	ID             string `json:"id"`
+	ContentHash    string `json:"content_hash"`
+	Title          string `json:"title"`
+	SourceName     string `json:"source_name"`
+	CreatedAt      string `json:"created_at"`
+	LastAnalyzedAt string `json:"last_analyzed_at"`
+}
+
+type projectIndex struct {
+	Projects []ProjectIndexEntry `json:"projects"`
+}
+
+const projectIDLength = 12
+
+// indexMu serializes read-modify-write cycles of the project index, which
+// analyses running side by side would otherwise overwrite.
+var indexMu sync.Mutex
+
+func projectIndexPath(workspaceRoot string) string {
+	return filepath.Join(workspaceRoot, "projects", "index.json")
+}
+
+func contentHash(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
+
+func loadProjectIndex(workspaceRoot string) (projectIndex, error) {
+	raw, err := os.ReadFile(projectIndexPath(workspaceRoot))
+	if os.IsNotExist(err) {
+		return projectIndex{Projects: []ProjectIndexEntry{}}, nil
+	}
+	if err != nil {
+		return projectIndex{}, fmt.Errorf("read project index: %w", err)
+	}
+	var idx projectIndex
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return projectIndex{}, fmt.Errorf("decode project index: %w", err)
+	}
+	return idx, nil
+}
+
+func saveProjectIndex(workspaceRoot string, idx projectIndex) error {
+	if err := os.MkdirAll(filepath.Dir(projectIndexPath(workspaceRoot)), 0o755); err != nil {
+		return fmt.Errorf("create projects dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal project index: %w", err)
+	}
+	if err := writeFileAtomic(projectIndexPath(workspaceRoot), raw); err != nil {
+		return fmt.Errorf("write project index: %w", err)
+	}
+	return nil
+}
+
+// resolveProjectID returns the project id for the source content and the
+// index entry as it was before this run (nil for a new manuscript). Content
+// analyzed before under a title-hash directory is adopted so existing
+// annotations and settings carry over.
+func resolveProjectID(workspaceRoot, bookTitle, sourceFileName string, source []byte) (string, *ProjectIndexEntry, error) {
+	if len(source) == 0 {
+		return bookTitleHash(bookTitle), nil, nil
+	}
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	release, err := acquireLock(filepath.Dir(projectIndexPath(workspaceRoot)), lockWait)
+	if err != nil {
+		return "", nil, err
+	}
+	defer release()
+	idx, err := loadProjectIndex(workspaceRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	hash := contentHash(source)
+	now := time.Now().Format(time.RFC3339)
+	title := strings.TrimSpace(bookTitle)
+
+	for i, entry := range idx.Projects {
+		if entry.ContentHash != hash {
+			continue
+		}
+		prior := entry
+		idx.Projects[i].Title = title
+		idx.Projects[i].SourceName = sourceFileName
+		idx.Projects[i].LastAnalyzedAt = now
+		return entry.ID, &prior, saveProjectIndex(workspaceRoot, idx)
+	}
+
+	id := ""
+	legacyID := bookTitleHash(bookTitle)
+	if legacy, err := os.ReadFile(filepath.Join(workspaceRoot, "projects", legacyID, sourceFileName)); err == nil && contentHash(legacy) == hash && !idx.hasID(legacyID) {
+		id = legacyID
+	} else {
+		id = idx.uniqueID(hash)
+	}
+	idx.Projects = append(idx.Projects, ProjectIndexEntry{
+		ID:             id,
+		ContentHash:    hash,
+		Title:          title,
+		SourceName:     sourceFileName,
+		CreatedAt:      now,
+		LastAnalyzedAt: now,
+	})
+	return id, nil, saveProjectIndex(workspaceRoot, idx)
+}
+
+// EarlierDrafts returns the projects recorded before projectID under the same
+// title, oldest first. Identity follows content, so each revision of a
+// manuscript gets its own project and the title is what the drafts share.
+func EarlierDrafts(workspaceRoot, projectID string) ([]ProjectIndexEntry, error) {
+	idx, err := loadProjectIndex(workspaceRoot)
+	if err != nil {
+		return nil, err
+	}
+	title := ""
+	for _, entry := range idx.Projects {
+		if entry.ID == projectID {
+			title = strings.ToLower(strings.TrimSpace(entry.Title))
+			break
+		}
+	}
+	drafts := []ProjectIndexEntry{}
+	if title == "" {
+		return drafts, nil
+	}
+	// The index is appended to as projects are created, so its order is
+	// creation order.
+	for _, entry := range idx.Projects {
+		if entry.ID == projectID {
+			break
+		}
+		if strings.ToLower(strings.TrimSpace(entry.Title)) == title {
+			drafts = append(drafts, entry)
+		}
+	}
+	return drafts, nil
+}
+
+func (idx projectIndex) hasID(id string) bool {
+	for _, entry := range idx.Projects {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueID takes a prefix of the content hash, lengthening it (and finally
+// adding a counter) if a different manuscript already uses that prefix.
+func (idx projectIndex) uniqueID(hash string) string {
+	for n := projectIDLength; n <= len(hash); n += 4 {
+		if id := hash[:n]; !idx.hasID(id) {
+			return id
+		}
+	}
+	for i := 2; ; i++ {
+		if id := fmt.Sprintf("%s-%d", hash[:projectIDLength], i); !idx.hasID(id) {
+			return id
+		}
+	}
+}
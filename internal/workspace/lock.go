@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// LockFileName is the advisory lock a writer holds in a project directory
+// (or the projects and series directories) while writing there, so analysts
+// sharing a workspace on a network drive never interleave their writes.
+const LockFileName = ".lock"
+
+const (
+	// lockStaleAfter is how old a lock gets before it is taken as left
+	// behind by a writer that crashed; writes hold it for well under a
+	// second.
+	lockStaleAfter = 2 * time.Minute
+	// lockWait is how long a writer waits for another to finish.
+	lockWait  = 30 * time.Second
+	lockRetry = 50 * time.Millisecond
+)
+
+// ErrLocked is wrapped by write errors that gave up waiting for another
+// writer's lock.
+var ErrLocked = errors.New("locked by another writer")
+
+// LockHolder identifies the writer holding a lock.
+type LockHolder struct {
+	Host       string `json:"host"`
+	User       string `json:"user"`
+	PID        int    `json:"pid"`
+	AcquiredAt string `json:"acquired_at"`
+}
+
+// withLock runs write while holding dir's lock.
+func withLock(dir string, write func() error) error {
+	release, err := acquireLock(dir, lockWait)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return write()
+}
+
+// acquireLock creates dir's lock file, waiting up to wait for the current
+// holder to release it and breaking a stale one. The returned release
+// removes the lock unless another writer has since broken and retaken it.
+func acquireLock(dir string, wait time.Duration) (func(), error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, LockFileName)
+	holder := currentLockHolder()
+	raw, err := json.Marshal(holder)
+	if err != nil {
+		return nil, fmt.Errorf("marshal lock: %w", err)
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		f, createErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if createErr == nil {
+			_, writeErr := f.Write(raw)
+			if closeErr := f.Close(); writeErr == nil {
+				writeErr = closeErr
+			}
+			if writeErr != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("write lock: %w", writeErr)
+			}
+			return func() {
+				if current, readErr := os.ReadFile(path); readErr == nil && bytes.Equal(current, raw) {
+					_ = os.Remove(path)
+				}
+			}, nil
+		}
+		if !os.IsExist(createErr) {
+			return nil, fmt.Errorf("create lock: %w", createErr)
+		}
+		if breakStaleLock(path, holder) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			held, _ := readLockHolder(path)
+			return nil, fmt.Errorf("%s %w (%s@%s, pid %d, since %s)", dir, ErrLocked, held.User, held.Host, held.PID, held.AcquiredAt)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// breakStaleLock removes the lock at path when its writer is gone: the lock
+// has outlived lockStaleAfter, or its process no longer runs on this host.
+func breakStaleLock(path string, self LockHolder) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Released meanwhile; the caller retries at once.
+		return os.IsNotExist(err)
+	}
+	stale := time.Since(info.ModTime()) > lockStaleAfter
+	if held, readErr := readLockHolder(path); readErr == nil && held.Host == self.Host && held.PID != self.PID && held.PID > 0 {
+		stale = stale || !processAlive(held.PID)
+	}
+	if !stale {
+		return false
+	}
+	removeErr := os.Remove(path)
+	return removeErr == nil || os.IsNotExist(removeErr)
+}
+
+func readLockHolder(path string) (LockHolder, error) {
+	var held LockHolder
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return held, err
+	}
+	err = json.Unmarshal(raw, &held)
+	return held, err
+}
+
+func currentLockHolder() LockHolder {
+	host, _ := os.Hostname()
+	name := ""
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return LockHolder{Host: host, User: name, PID: os.Getpid(), AcquiredAt: time.Now().Format(time.RFC3339Nano)}
+}
+
+// writeFileAtomic replaces path through a temporary file in the same
+// directory, so a reader (or a writer on another machine) never sees half a
+// file.
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(raw)
+	if closeErr := tmp.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Chmod(tmp.Name(), 0o644)
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmp.Name(), path)
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return writeErr
+}
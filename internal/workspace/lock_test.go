@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockWaitsForHolderAndBreaksStaleLocks(t *testing.T) {
+	dir := t.TempDir()
+	release, err := acquireLock(dir, time.Second)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := acquireLock(dir, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a held lock to block a second writer, got %v", err)
+	}
+	release()
+	if _, err := os.Stat(filepath.Join(dir, LockFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected release to remove the lock file, got %v", err)
+	}
+
+	// A lock from another machine is only broken once it is stale.
+	path := filepath.Join(dir, LockFileName)
+	raw, _ := json.Marshal(LockHolder{Host: "other-host", User: "ana", PID: 4242})
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquireLock(dir, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a fresh lock from another host respected, got %v", err)
+	}
+	old := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	release, err = acquireLock(dir, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected a stale lock broken, got %v", err)
+	}
+	if held, _ := readLockHolder(path); held.PID != os.Getpid() {
+		t.Fatalf("expected the lock retaken by this writer, got %+v", held)
+	}
+	release()
+}
+
+func TestLockBreaksLockOfExitedLocalProcess(t *testing.T) {
+	const gone = 1 << 22
+	if processAlive(gone) {
+		t.Skip("cannot tell exited processes apart on this platform")
+	}
+	dir := t.TempDir()
+	raw, _ := json.Marshal(LockHolder{Host: currentLockHolder().Host, PID: gone})
+	if err := os.WriteFile(filepath.Join(dir, LockFileName), raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	release, err := acquireLock(dir, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected the lock of an exited process broken, got %v", err)
+	}
+	release()
+}
+
+func TestSaveReportKeepsConflictingRevision(t *testing.T) {
+	root := t.TempDir()
+	project, err := CreateProject(root, "Shared", []byte("shared manuscript"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	base := project.ReportRevision
+
+	first, err := SaveReport(project.ReportPath, Report{BookTitle: "Shared", MHDScore: 70, Revision: base})
+	if err != nil || first.Revision != base+1 || first.ConflictPath != "" {
+		t.Fatalf("expected a clean first save, got %+v (%v)", first, err)
+	}
+	// A second analyst's run started from the same revision and finishes later.
+	second, err := SaveReport(project.ReportPath, Report{BookTitle: "Shared", MHDScore: 55, Revision: base})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if second.Revision != base+2 || filepath.Base(second.ConflictPath) != "report.r1.json" {
+		t.Fatalf("expected the first analyst's report kept aside, got %+v", second)
+	}
+	var kept, latest Report
+	for path, out := range map[string]*Report{second.ConflictPath: &kept, project.ReportPath: &latest} {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if kept.MHDScore != 70 || latest.MHDScore != 55 || latest.Revision != base+2 {
+		t.Fatalf("unexpected reports: kept %+v latest %+v", kept, latest)
+	}
+	if reopened, err := CreateProject(root, "Shared", []byte("shared manuscript")); err != nil || reopened.ReportRevision != base+2 {
+		t.Fatalf("expected the reopened project based on the latest revision, got %+v (%v)", reopened, err)
+	}
+	if _, err := os.Stat(filepath.Join(project.Root, LockFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected no lock left behind, got %v", err)
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+
+	"book_dashboard/internal/gates"
 )
 
 type Report struct {
@@ -16,7 +20,43 @@ type Report struct {
 	MHDScore       int      `json:"mhd_score"`
 	Contradictions int      `json:"contradictions"`
 	SlopFlags      []string `json:"slop_flags"`
-	Analysis       any      `json:"analysis,omitempty"`
+	// SourceName and SourceSHA256 identify the manuscript file the findings
+	// were produced from, so reopening can tell when it changed on disk.
+	SourceName   string `json:"source_name,omitempty"`
+	SourceSHA256 string `json:"source_sha256,omitempty"`
+	// TextSHA256 keys the stage cache for the manuscript's extracted text.
+	TextSHA256 string      `json:"text_sha256,omitempty"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+	Analysis   any         `json:"analysis,omitempty"`
+	// Revision counts the saves of report.json. A writer passes the revision
+	// its report is based on; see SaveReport.
+	Revision int `json:"revision,omitempty"`
+}
+
+// ReportSave says how SaveReport stored a report.
+type ReportSave struct {
+	Revision int
+	// ConflictPath keeps the report another writer saved after the one
+	// this report was based on; it is empty without a conflict.
+	ConflictPath string
+}
+
+// Provenance records what produced a report so a run can be reproduced or
+// audited after the fact.
+type Provenance struct {
+	AppVersion   string            `json:"app_version"`
+	GitCommit    string            `json:"git_commit"`
+	GoVersion    string            `json:"go_version"`
+	GeneratedAt  string            `json:"generated_at"`
+	Config       map[string]any    `json:"config"`
+	EnvOverrides map[string]string `json:"env_overrides"`
+	Dependencies map[string]string `json:"dependencies"`
+	StageTimings []StageTiming     `json:"stage_timings"`
+}
+
+type StageTiming struct {
+	Stage      string `json:"stage"`
+	DurationMs int64  `json:"duration_ms"`
 }
 
 type ProjectInfo struct {
@@ -24,6 +64,15 @@ type ProjectInfo struct {
 	Root       string
 	SourcePath string
 	ReportPath string
+	DBPath     string
+	// SourceSHA256 is the checksum of the source written for this run.
+	SourceSHA256 string
+	// Prior is the index entry from the last time this content was analyzed,
+	// nil the first time.
+	Prior *ProjectIndexEntry
+	// ReportRevision is report.json's revision when the project was opened,
+	// the base for the run's SaveReport.
+	ReportRevision int
 }
 
 func CreateProject(workspaceRoot, bookTitle string, source []byte) (*ProjectInfo, error) {
@@ -31,59 +80,118 @@ func CreateProject(workspaceRoot, bookTitle string, source []byte) (*ProjectInfo
 }
 
 func CreateProjectWithSource(workspaceRoot, bookTitle, sourceFileName string, source []byte) (*ProjectInfo, error) {
-	id := bookTitleHash(bookTitle)
-	projectRoot := filepath.Join(workspaceRoot, "projects", id)
-	if err := os.MkdirAll(projectRoot, 0o755); err != nil {
-		return nil, fmt.Errorf("create project dir: %w", err)
+	if err := EnsureDiskSpace(workspaceRoot, len(source)); err != nil {
+		return nil, err
 	}
-
 	sourceFileName = sanitizeSourceName(sourceFileName)
+	id, prior, err := resolveProjectID(workspaceRoot, bookTitle, sourceFileName, source)
+	if err != nil {
+		return nil, err
+	}
+	projectRoot := filepath.Join(workspaceRoot, "projects", id)
 	sourcePath := filepath.Join(projectRoot, sourceFileName)
-	if len(source) > 0 {
-		if err := os.WriteFile(sourcePath, source, 0o644); err != nil {
-			return nil, fmt.Errorf("write source file: %w", err)
+	reportPath := filepath.Join(projectRoot, "report.json")
+	revision := 0
+	err = withLock(projectRoot, func() error {
+		if len(source) > 0 {
+			if err := writeFileAtomic(sourcePath, source); err != nil {
+				return fmt.Errorf("write source file: %w", err)
+			}
+		} else if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+			if err := os.WriteFile(sourcePath, nil, 0o644); err != nil {
+				return fmt.Errorf("create empty source file: %w", err)
+			}
 		}
-	} else if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		if err := os.WriteFile(sourcePath, nil, 0o644); err != nil {
-			return nil, fmt.Errorf("create empty source file: %w", err)
+
+		if _, err := os.Stat(reportPath); os.IsNotExist(err) {
+			report := Report{
+				BookTitle:      strings.TrimSpace(bookTitle),
+				WordCount:      0,
+				MHDScore:       0,
+				Contradictions: 0,
+				SlopFlags:      []string{},
+			}
+			raw, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal report: %w", err)
+			}
+			if err := writeFileAtomic(reportPath, raw); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
 		}
+		revision = reportRevision(reportPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	reportPath := filepath.Join(projectRoot, "report.json")
-	if _, err := os.Stat(reportPath); os.IsNotExist(err) {
-		report := Report{
-			BookTitle:      strings.TrimSpace(bookTitle),
-			WordCount:      0,
-			MHDScore:       0,
-			Contradictions: 0,
-			SlopFlags:      []string{},
+	return &ProjectInfo{
+		ID:             id,
+		Root:           projectRoot,
+		SourcePath:     sourcePath,
+		ReportPath:     reportPath,
+		DBPath:         ProjectDBPath(projectRoot),
+		SourceSHA256:   sourceChecksum(source),
+		Prior:          prior,
+		ReportRevision: revision,
+	}, nil
+}
+
+// SaveReport writes report.json as its next revision, under the project lock.
+// report.Revision is the revision the caller's report is based on. When the
+// file has moved past it (another analyst saved in between), the other
+// writer's report is kept beside it as report.r<revision>.json rather than
+// silently replaced.
+func SaveReport(path string, report Report) (ReportSave, error) {
+	var saved ReportSave
+	err := withLock(filepath.Dir(path), func() error {
+		current := reportRevision(path)
+		if current > report.Revision {
+			conflict := filepath.Join(filepath.Dir(path), fmt.Sprintf("report.r%d.json", current))
+			prev, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read report: %w", err)
+			}
+			if err := writeFileAtomic(conflict, prev); err != nil {
+				return fmt.Errorf("keep conflicting report: %w", err)
+			}
+			saved.ConflictPath = conflict
 		}
+		report.Revision = max(current, report.Revision) + 1
 		raw, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
-			return nil, fmt.Errorf("marshal report: %w", err)
+			return fmt.Errorf("marshal report: %w", err)
 		}
-		if err := os.WriteFile(reportPath, raw, 0o644); err != nil {
-			return nil, fmt.Errorf("write report: %w", err)
+		if err := writeFileAtomic(path, raw); err != nil {
+			return fmt.Errorf("write report: %w", err)
 		}
-	}
-
-	return &ProjectInfo{
-		ID:         id,
-		Root:       projectRoot,
-		SourcePath: sourcePath,
-		ReportPath: reportPath,
-	}, nil
+		saved.Revision = report.Revision
+		return nil
+	})
+	return saved, err
 }
 
-func SaveReport(path string, report Report) error {
-	raw, err := json.MarshalIndent(report, "", "  ")
+// reportRevision reads the revision of the report at path, 0 when it has
+// none or cannot be read.
+func reportRevision(path string) int {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("marshal report: %w", err)
+		return 0
+	}
+	var head struct {
+		Revision int `json:"revision"`
 	}
-	if err := os.WriteFile(path, raw, 0o644); err != nil {
-		return fmt.Errorf("write report: %w", err)
+	if json.Unmarshal(raw, &head) != nil {
+		return 0
 	}
-	return nil
+	return head.Revision
+}
+
+// ProjectDBPath returns the per-project SQLite database used for user state
+// such as annotations.
+func ProjectDBPath(projectRoot string) string {
+	return filepath.Join(projectRoot, "analysis.db")
 }
 
 func bookTitleHash(title string) string {
@@ -92,6 +200,15 @@ func bookTitleHash(title string) string {
 	return hex.EncodeToString(sum[:])[:12]
 }
 
+// sourceChecksum is empty when no source was supplied, since the file on disk
+// is then whatever an earlier run left there.
+func sourceChecksum(source []byte) string {
+	if len(source) == 0 {
+		return ""
+	}
+	return contentHash(source)
+}
+
 func sanitizeSourceName(name string) string {
 	base := filepath.Base(strings.TrimSpace(name))
 	if base == "" || base == "." || base == string(filepath.Separator) {
@@ -99,3 +216,153 @@ func sanitizeSourceName(name string) string {
 	}
 	return strings.ReplaceAll(base, "..", "")
 }
+
+// ProjectSettings holds per-project preferences stored next to report.json.
+type ProjectSettings struct {
+	DisabledSections []string `json:"disabled_sections"`
+	// EnabledSections lists opt-in sections, which are off unless named here.
+	EnabledSections []string `json:"enabled_sections,omitempty"`
+	// SourceRetentionCount caps archived source versions; see SourceRetention.
+	SourceRetentionCount int `json:"source_retention,omitempty"`
+	// Dialect is the LanguageTool English variant (en-US, en-GB, ...); empty
+	// or "auto" detects it from the manuscript.
+	Dialect string `json:"dialect,omitempty"`
+	// DialogueGrammar controls grammar matches inside quoted dialogue:
+	// "include" (default), "downweight" or "exclude".
+	DialogueGrammar string `json:"dialogue_grammar,omitempty"`
+	// AISensitivity is the AI-detection preset: "conservative", "balanced"
+	// (default) or "aggressive". A run may override it.
+	AISensitivity string `json:"ai_sensitivity,omitempty"`
+	// VerifiedHumanChapters and VerifiedHumanPassages mark text known to be
+	// the author's own (for example previously published work), which AI
+	// detection reports but leaves out of its document-level estimate.
+	VerifiedHumanChapters []int    `json:"verified_human_chapters,omitempty"`
+	VerifiedHumanPassages []string `json:"verified_human_passages,omitempty"`
+	// Anthology analyzes the manuscript as a collection of stories rather
+	// than one novel. A run may override it.
+	Anthology bool `json:"anthology,omitempty"`
+	// ManuscriptType is "fiction" (default), "nonfiction" or "memoir";
+	// nonfiction types skip plot beats and character contradictions and run
+	// the nonfiction checks instead. A run may override it.
+	ManuscriptType string `json:"manuscript_type,omitempty"`
+	// NumberStyle is the style guide for numbers, units and dates:
+	// "chicago" (default), "ap" or "oxford".
+	NumberStyle string `json:"number_style,omitempty"`
+	// QualityGates are the submission checklist rules; none means
+	// gates.DefaultRules.
+	QualityGates []gates.Rule `json:"quality_gates,omitempty"`
+	// FeedbackThresholds is "refit" (default) to apply slop thresholds
+	// re-fit from reader feedback, or "off" for the built-in ones.
+	FeedbackThresholds string `json:"feedback_thresholds,omitempty"`
+	// MaskProfanity dashes out profanity and explicit terms quoted in the
+	// exported reports (f--k); counts and locations are unchanged.
+	MaskProfanity bool `json:"mask_profanity,omitempty"`
+}
+
+func (s ProjectSettings) SectionEnabled(name string) bool {
+	for _, disabled := range s.DisabledSections {
+		if strings.EqualFold(strings.TrimSpace(disabled), name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s ProjectSettings) SectionOptedIn(name string) bool {
+	for _, enabled := range s.EnabledSections {
+		if strings.EqualFold(strings.TrimSpace(enabled), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GateRules returns the project's quality gates, or the defaults when it
+// configures none.
+func (s ProjectSettings) GateRules() []gates.Rule {
+	if len(s.QualityGates) == 0 {
+		return gates.DefaultRules()
+	}
+	return s.QualityGates
+}
+
+func ProjectSettingsPath(projectRoot string) string {
+	return filepath.Join(projectRoot, "settings.json")
+}
+
+// LoadProjectSettings returns defaults (everything enabled) when the project
+// has no settings file yet.
+func LoadProjectSettings(projectRoot string) (ProjectSettings, error) {
+	raw, err := os.ReadFile(ProjectSettingsPath(projectRoot))
+	if os.IsNotExist(err) {
+		return ProjectSettings{DisabledSections: []string{}}, nil
+	}
+	if err != nil {
+		return ProjectSettings{}, fmt.Errorf("read project settings: %w", err)
+	}
+	var settings ProjectSettings
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return ProjectSettings{}, fmt.Errorf("decode project settings: %w", err)
+	}
+	if settings.DisabledSections == nil {
+		settings.DisabledSections = []string{}
+	}
+	return settings, nil
+}
+
+func SaveProjectSettings(projectRoot string, settings ProjectSettings) error {
+	raw, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal project settings: %w", err)
+	}
+	return withLock(projectRoot, func() error {
+		if err := writeFileAtomic(ProjectSettingsPath(projectRoot), raw); err != nil {
+			return fmt.Errorf("write project settings: %w", err)
+		}
+		return nil
+	})
+}
+
+// DuplicationIgnorePath is the project's list of text that is repeated by
+// design and must not count as duplication.
+func DuplicationIgnorePath(projectRoot string) string {
+	return filepath.Join(projectRoot, "duplication_ignore.txt")
+}
+
+// LoadDuplicationIgnore reads the project's ignore list, one entry per line
+// with # comments. A line is literal text, matched case-insensitively with
+// any run of whitespace standing for its spaces; a line starting "re:" is a
+// regular expression. A missing file is an empty list. Lines that do not
+// compile are reported as an error alongside the patterns that do.
+func LoadDuplicationIgnore(projectRoot string) ([]*regexp.Regexp, error) {
+	raw, err := os.ReadFile(DuplicationIgnorePath(projectRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read duplication ignore list: %w", err)
+	}
+	patterns := []*regexp.Regexp{}
+	var errs []error
+	for n, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		expr, ok := strings.CutPrefix(line, "re:")
+		if !ok {
+			words := strings.Fields(line)
+			for i, w := range words {
+				words[i] = regexp.QuoteMeta(w)
+			}
+			expr = `(?i)` + strings.Join(words, `\s+`)
+		}
+		re, err := regexp.Compile(strings.TrimSpace(expr))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("duplication ignore list line %d: %w", n+1, err))
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, errors.Join(errs...)
+}
@@ -1,6 +1,8 @@
 package workspace
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -24,3 +26,318 @@ func TestCreateProject(t *testing.T) {
 		}
 	}
 }
+
+func TestProjectSettingsRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	settings, err := LoadProjectSettings(root)
+	if err != nil {
+		t.Fatalf("load default settings: %v", err)
+	}
+	if !settings.SectionEnabled("ai_detection") {
+		t.Fatal("expected sections enabled by default")
+	}
+
+	settings.DisabledSections = append(settings.DisabledSections, "ai_detection")
+	if err := SaveProjectSettings(root, settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	loaded, err := LoadProjectSettings(root)
+	if err != nil {
+		t.Fatalf("reload settings: %v", err)
+	}
+	if loaded.SectionEnabled("ai_detection") {
+		t.Fatal("expected ai_detection to stay disabled after reload")
+	}
+	if !loaded.SectionEnabled("safety") {
+		t.Fatal("expected safety to remain enabled")
+	}
+}
+
+func TestCreateProjectRefusesWhenDiskTooFull(t *testing.T) {
+	root := t.TempDir()
+	if status := CheckDiskSpace(root, 0); !status.Known {
+		t.Skip("free disk space not available on this platform")
+	}
+	t.Setenv("MHD_MIN_FREE_DISK_MB", "1000000000")
+	_, err := CreateProject(root, "Full Disk", []byte("data"))
+	if !errors.Is(err, ErrInsufficientDisk) {
+		t.Fatalf("expected ErrInsufficientDisk, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "projects")); !os.IsNotExist(statErr) {
+		t.Fatal("expected no project files written when refusing")
+	}
+}
+
+func TestCreateProjectUsesContentIdentity(t *testing.T) {
+	root, err := EnsureAt(filepath.Join(t.TempDir(), BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+
+	first, err := CreateProjectWithSource(root, "Final", "final.docx", []byte("draft one"))
+	if err != nil {
+		t.Fatalf("create first project: %v", err)
+	}
+	if first.Prior != nil {
+		t.Fatalf("expected new manuscript without prior analysis, got %+v", first.Prior)
+	}
+	revised, err := CreateProjectWithSource(root, "Final", "final_v2.docx", []byte("draft two"))
+	if err != nil {
+		t.Fatalf("create revised project: %v", err)
+	}
+	if revised.ID == first.ID {
+		t.Fatal("expected different content under the same title to get its own project")
+	}
+	renamed, err := CreateProjectWithSource(root, "Working Title", "final.docx", []byte("draft one"))
+	if err != nil {
+		t.Fatalf("create renamed project: %v", err)
+	}
+	if renamed.ID != first.ID {
+		t.Fatalf("expected identical content to reuse project %s, got %s", first.ID, renamed.ID)
+	}
+	if renamed.Prior == nil || renamed.Prior.Title != "Final" {
+		t.Fatalf("expected prior analysis recorded as Final, got %+v", renamed.Prior)
+	}
+	if _, err := CreateProjectWithSource(root, "Final", "final_v3.docx", []byte("draft three")); err != nil {
+		t.Fatalf("create third draft: %v", err)
+	}
+	third, _ := CreateProjectWithSource(root, "final", "final_v3.docx", []byte("draft three"))
+	drafts, err := EarlierDrafts(root, third.ID)
+	if err != nil || len(drafts) != 1 || drafts[0].ID != revised.ID {
+		t.Fatalf("expected only the revised draft still titled Final before the third, got %+v (%v)", drafts, err)
+	}
+}
+
+func TestCreateProjectAdoptsLegacyTitleDirectory(t *testing.T) {
+	root, err := EnsureAt(filepath.Join(t.TempDir(), BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	legacyRoot := filepath.Join(root, "projects", bookTitleHash("Old Book"))
+	if err := os.MkdirAll(legacyRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyRoot, "source.docx"), []byte("old content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := CreateProject(root, "Old Book", []byte("old content"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if project.Root != legacyRoot {
+		t.Fatalf("expected legacy project dir %s to be adopted, got %s", legacyRoot, project.Root)
+	}
+}
+
+func TestProjectIndexUniqueIDOnPrefixCollision(t *testing.T) {
+	hash := contentHash([]byte("x"))
+	idx := projectIndex{Projects: []ProjectIndexEntry{{ID: hash[:projectIDLength], ContentHash: "other"}}}
+	if got := idx.uniqueID(hash); got == hash[:projectIDLength] || got[:projectIDLength] != hash[:projectIDLength] {
+		t.Fatalf("expected lengthened id on collision, got %s", got)
+	}
+}
+
+func TestArchiveRunSourcePrunesOldestBeyondRetention(t *testing.T) {
+	projectRoot := t.TempDir()
+	runs := []string{"run-20260101-090000.000", "run-20260101-100000.000", "run-20260101-110000.000"}
+	for i, runID := range runs {
+		path, err := ArchiveRunSource(projectRoot, runID, "../draft.docx", []byte(fmt.Sprintf("draft %d", i)), 2)
+		if err != nil {
+			t.Fatalf("archive %s: %v", runID, err)
+		}
+		if filepath.Base(path) != runID+"-draft.docx" {
+			t.Fatalf("unexpected archive name %s", path)
+		}
+	}
+	entries, err := os.ReadDir(ProjectSourcesDir(projectRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != runs[1]+"-draft.docx" {
+		t.Fatalf("expected the two newest versions kept, got %v", entries)
+	}
+	raw, err := os.ReadFile(filepath.Join(ProjectSourcesDir(projectRoot), runs[2]+"-draft.docx"))
+	if err != nil || string(raw) != "draft 2" {
+		t.Fatalf("expected exact bytes of the latest run, got %q (%v)", raw, err)
+	}
+}
+
+func TestSourceRetentionDefaults(t *testing.T) {
+	if got := (ProjectSettings{}).SourceRetention(); got != DefaultSourceRetention {
+		t.Fatalf("expected default retention %d, got %d", DefaultSourceRetention, got)
+	}
+	if got := (ProjectSettings{SourceRetentionCount: -1}).SourceRetention(); got >= 0 {
+		t.Fatalf("expected negative retention to keep all, got %d", got)
+	}
+}
+
+func TestVerifyReportSourceDetectsChangedDraft(t *testing.T) {
+	root, err := EnsureAt(filepath.Join(t.TempDir(), BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	project, err := CreateProjectWithSource(root, "Harbor Lights", "draft.docx", []byte("first draft"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if check, err := VerifyReportSource(project.ReportPath); err != nil || check.Status != SourceUnrecorded {
+		t.Fatalf("expected the placeholder report to be unrecorded, got %+v (%v)", check, err)
+	}
+
+	report := Report{BookTitle: "Harbor Lights", SourceName: "draft.docx", SourceSHA256: project.SourceSHA256}
+	if _, err := SaveReport(project.ReportPath, report); err != nil {
+		t.Fatal(err)
+	}
+	if check, err := VerifyReportSource(project.ReportPath); err != nil || check.Status != SourceUnchanged || check.Stale() {
+		t.Fatalf("expected an unchanged source, got %+v (%v)", check, err)
+	}
+
+	if err := os.WriteFile(project.SourcePath, []byte("second draft"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	check, err := VerifyReportSource(project.ReportPath)
+	if err != nil || check.Status != SourceChanged || !check.Stale() || check.CurrentSHA256 == check.RecordedSHA256 {
+		t.Fatalf("expected a changed source, got %+v (%v)", check, err)
+	}
+
+	if err := os.Remove(project.SourcePath); err != nil {
+		t.Fatal(err)
+	}
+	if check, err := VerifyReportSource(project.ReportPath); err != nil || check.Status != SourceMissing {
+		t.Fatalf("expected a missing source, got %+v (%v)", check, err)
+	}
+}
+
+func TestSeriesRecordKeepsInstallmentOrderAcrossReruns(t *testing.T) {
+	root := t.TempDir()
+	s, err := LoadSeries(root, "The Long Road")
+	if err != nil {
+		t.Fatalf("load empty series: %v", err)
+	}
+	if n := s.Record(SeriesInstallment{SourceSHA256: "aaa"}); n != 1 {
+		t.Fatalf("first installment number = %d", n)
+	}
+	if n := s.Record(SeriesInstallment{SourceSHA256: "bbb"}); n != 2 {
+		t.Fatalf("second installment number = %d", n)
+	}
+	if err := SaveSeries(root, s); err != nil {
+		t.Fatalf("save series: %v", err)
+	}
+
+	reloaded, err := LoadSeries(root, "the-long-road")
+	if err != nil {
+		t.Fatalf("reload series: %v", err)
+	}
+	if got := reloaded.Before("bbb"); len(got) != 1 || got[0].SourceSHA256 != "aaa" {
+		t.Fatalf("a rerun of installment 2 should see only installment 1, got %+v", got)
+	}
+	if got := reloaded.Before("ccc"); len(got) != 2 {
+		t.Fatalf("a new installment should see both earlier ones, got %d", len(got))
+	}
+	if n := reloaded.Record(SeriesInstallment{SourceSHA256: "aaa", Title: "Part One"}); n != 1 || len(reloaded.Installments) != 2 {
+		t.Fatalf("rerun should replace installment 1 in place, got number %d of %d", n, len(reloaded.Installments))
+	}
+}
+
+func TestStageCacheMissesOnFingerprintChange(t *testing.T) {
+	root := t.TempDir()
+	key := TextSHA256("Chapter 1\nIt was a dark night.")
+	if err := SaveStage(root, key, "genre", "model-a", map[string]int{"ch1": 3}); err != nil {
+		t.Fatalf("save stage: %v", err)
+	}
+	var got map[string]int
+	if hit, err := LoadStage(root, key, "genre", "model-a", &got); err != nil || !hit || got["ch1"] != 3 {
+		t.Fatalf("expected a hit, got hit=%t err=%v value=%v", hit, err, got)
+	}
+	if hit, err := LoadStage(root, key, "genre", "model-b", &got); err != nil || hit {
+		t.Fatalf("expected a miss for another fingerprint, got hit=%t err=%v", hit, err)
+	}
+	if hit, err := LoadStage(root, TextSHA256("other text"), "genre", "model-a", &got); err != nil || hit {
+		t.Fatalf("expected a miss for other text, got hit=%t err=%v", hit, err)
+	}
+
+	purged, err := PurgeStageCache(root, key)
+	if err != nil || len(purged) != 1 || purged[0].Kind != PurgedStageCache {
+		t.Fatalf("expected the cached stage purged, got %+v err=%v", purged, err)
+	}
+	if _, err := os.Stat(StageCacheDir(root, key)); !os.IsNotExist(err) {
+		t.Fatalf("expected the stage cache dir removed, got %v", err)
+	}
+}
+
+func TestLoadDuplicationIgnore(t *testing.T) {
+	root := t.TempDir()
+	if patterns, err := LoadDuplicationIgnore(root); err != nil || len(patterns) != 0 {
+		t.Fatalf("expected no patterns without a file, got %v %v", patterns, err)
+	}
+	list := "# epigraphs\nThe sea   remembers (every) name\n\nre:^Chorus: .*$\nre:([unclosed\n"
+	if err := os.WriteFile(DuplicationIgnorePath(root), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := LoadDuplicationIgnore(root)
+	if err == nil || len(patterns) != 2 {
+		t.Fatalf("expected two patterns and an error for line 5, got %d %v", len(patterns), err)
+	}
+	if !patterns[0].MatchString("the sea\nremembers (every) name") || patterns[0].MatchString("The sea remembers every name") {
+		t.Fatalf("expected literal lines to match text and whitespace only, got %s", patterns[0])
+	}
+	if !patterns[1].MatchString("Chorus: row the boat") {
+		t.Fatalf("expected re: lines to be regular expressions, got %s", patterns[1])
+	}
+}
+
+func TestListProjectsSkipsUnfinishedRuns(t *testing.T) {
+	root := t.TempDir()
+	older, err := CreateProjectWithSource(root, "Older Book", "older.docx", []byte("older"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateProjectWithSource(root, "Cancelled Book", "cancelled.docx", []byte("cancelled")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveReport(older.ReportPath, Report{BookTitle: "Older Book", WordCount: 1200, MHDScore: 71, SourceName: "older.docx", Analysis: map[string]any{"chapter_count": 3}}); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := ListProjects(root)
+	if err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != older.ID || projects[0].WordCount != 1200 || projects[0].LastAnalyzedAt == "" {
+		t.Fatalf("expected only the finished project listed, got %+v", projects)
+	}
+
+	if got, err := ProjectRoot(root, older.ID); err != nil || got != older.Root {
+		t.Fatalf("expected %s, got %s %v", older.Root, got, err)
+	}
+	for _, id := range []string{"", "..", "../" + older.ID, "missing"} {
+		if _, err := ProjectRoot(root, id); err == nil {
+			t.Fatalf("expected %q to be rejected", id)
+		}
+	}
+}
+
+func TestSaveRunKeepsEveryRun(t *testing.T) {
+	root := t.TempDir()
+	for i, id := range []string{"run-20260102-090000.000", "run-20260101-090000.000"} {
+		if _, err := SaveRun(root, id, map[string]int{"mhdScore": 60 + i}); err != nil {
+			t.Fatalf("save run: %v", err)
+		}
+	}
+	ids, err := ListRuns(root)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "run-20260101-090000.000" {
+		t.Fatalf("expected both runs oldest first, got %v", ids)
+	}
+	var got struct{ MHDScore int }
+	if err := LoadRun(root, ids[0], &got); err != nil || got.MHDScore != 61 {
+		t.Fatalf("expected the older run's score, got %+v %v", got, err)
+	}
+	if err := LoadRun(root, "../report", &got); err == nil {
+		t.Fatal("expected a path to be rejected as a run id")
+	}
+	if err := LoadRun(root, "run-20990101-000000.000", &got); err == nil {
+		t.Fatal("expected a missing run to be reported")
+	}
+}
@@ -0,0 +1,118 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProjectSummary describes one analyzed project for a project browser, read
+// from its report.json and the project index.
+type ProjectSummary struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	SourceName     string `json:"source_name"`
+	WordCount      int    `json:"word_count"`
+	MHDScore       int    `json:"mhd_score"`
+	LastAnalyzedAt string `json:"last_analyzed_at"`
+	Root           string `json:"root"`
+	ReportPath     string `json:"report_path"`
+}
+
+// ListProjects lists the projects under workspaceRoot/projects with a
+// finished analysis, most recently analyzed first. Projects whose run never
+// wrote a report are left out. A report that cannot be read is reported as an
+// error alongside the projects that could.
+func ListProjects(workspaceRoot string) ([]ProjectSummary, error) {
+	entries, err := os.ReadDir(filepath.Join(workspaceRoot, "projects"))
+	if os.IsNotExist(err) {
+		return []ProjectSummary{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	idx, err := loadProjectIndex(workspaceRoot)
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[string]ProjectIndexEntry{}
+	for _, entry := range idx.Projects {
+		indexed[entry.ID] = entry
+	}
+
+	out := []ProjectSummary{}
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		root := filepath.Join(workspaceRoot, "projects", entry.Name())
+		reportPath := filepath.Join(root, "report.json")
+		raw, err := os.ReadFile(reportPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read report %s: %w", reportPath, err))
+			continue
+		}
+		var report Report
+		if err := json.Unmarshal(raw, &report); err != nil {
+			errs = append(errs, fmt.Errorf("decode report %s: %w", reportPath, err))
+			continue
+		}
+		if report.Analysis == nil {
+			continue
+		}
+		summary := ProjectSummary{
+			ID:         entry.Name(),
+			Title:      report.BookTitle,
+			SourceName: report.SourceName,
+			WordCount:  report.WordCount,
+			MHDScore:   report.MHDScore,
+			Root:       root,
+			ReportPath: reportPath,
+		}
+		if known, ok := indexed[summary.ID]; ok {
+			summary.LastAnalyzedAt = known.LastAnalyzedAt
+			if strings.TrimSpace(summary.Title) == "" {
+				summary.Title = known.Title
+			}
+			if summary.SourceName == "" {
+				summary.SourceName = known.SourceName
+			}
+		}
+		if summary.LastAnalyzedAt == "" {
+			if info, err := os.Stat(reportPath); err == nil {
+				summary.LastAnalyzedAt = info.ModTime().Format(time.RFC3339)
+			}
+		}
+		out = append(out, summary)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].LastAnalyzedAt != out[j].LastAnalyzedAt {
+			return out[i].LastAnalyzedAt > out[j].LastAnalyzedAt
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, errors.Join(errs...)
+}
+
+// ProjectRoot returns the directory of the project with id, which must name
+// a directory directly under workspaceRoot/projects.
+func ProjectRoot(workspaceRoot, id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || id == "." || !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("%q is not a project id", id)
+	}
+	root := filepath.Join(workspaceRoot, "projects", id)
+	if stat, err := os.Stat(root); err != nil || !stat.IsDir() {
+		return "", fmt.Errorf("project %s not found", id)
+	}
+	return root, nil
+}
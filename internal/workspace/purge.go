@@ -0,0 +1,114 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of stored data reported by PurgeProject and the callers that purge
+// derived data elsewhere.
+const (
+	PurgedSource     = "source"
+	PurgedRunSource  = "run_source"
+	PurgedRun        = "run"
+	PurgedReport     = "report"
+	PurgedDatabase   = "database"
+	PurgedSettings   = "settings"
+	PurgedIndexEntry = "index_entry"
+	PurgedOther      = "other"
+)
+
+// PurgedFile is one piece of stored data removed on an author's request.
+// Entries counts records removed from a shared file, such as a project index
+// entry, when the file itself is kept.
+type PurgedFile struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Bytes   int64  `json:"bytes"`
+	Entries int    `json:"entries,omitempty"`
+}
+
+// PurgeProject deletes a project directory (source copies, report, saved
+// runs, database, settings) and its project index entry, and lists what it
+// removed. The project must live under workspaceRoot/projects.
+func PurgeProject(workspaceRoot, projectRoot string) ([]PurgedFile, error) {
+	projectsDir := filepath.Join(workspaceRoot, "projects")
+	rel, err := filepath.Rel(projectsDir, projectRoot)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) || strings.ContainsRune(rel, filepath.Separator) {
+		return nil, fmt.Errorf("%s is not a project in %s", projectRoot, projectsDir)
+	}
+	id := rel
+
+	purged := []PurgedFile{}
+	err = filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		purged = append(purged, PurgedFile{Path: path, Kind: purgedKind(projectRoot, path), Bytes: info.Size()})
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("list project files: %w", err)
+	}
+	if err := os.RemoveAll(projectRoot); err != nil {
+		return purged, fmt.Errorf("remove project dir: %w", err)
+	}
+
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	release, err := acquireLock(filepath.Dir(projectIndexPath(workspaceRoot)), lockWait)
+	if err != nil {
+		return purged, err
+	}
+	defer release()
+	idx, err := loadProjectIndex(workspaceRoot)
+	if err != nil {
+		return purged, err
+	}
+	kept := idx.Projects[:0]
+	removed := 0
+	for _, entry := range idx.Projects {
+		if entry.ID == id {
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if removed > 0 {
+		idx.Projects = kept
+		if err := saveProjectIndex(workspaceRoot, idx); err != nil {
+			return purged, err
+		}
+		purged = append(purged, PurgedFile{Path: projectIndexPath(workspaceRoot), Kind: PurgedIndexEntry, Entries: removed})
+	}
+	return purged, nil
+}
+
+func purgedKind(projectRoot, path string) string {
+	switch {
+	case filepath.Dir(path) == ProjectSourcesDir(projectRoot):
+		return PurgedRunSource
+	case filepath.Dir(path) == ProjectRunsDir(projectRoot):
+		return PurgedRun
+	case filepath.Dir(path) != projectRoot:
+		return PurgedOther
+	case filepath.Base(path) == "report.json":
+		return PurgedReport
+	case strings.HasPrefix(filepath.Base(path), filepath.Base(ProjectDBPath(projectRoot))):
+		// Includes SQLite's -wal and -shm companions.
+		return PurgedDatabase
+	case path == ProjectSettingsPath(projectRoot):
+		return PurgedSettings
+	}
+	return PurgedSource
+}
@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPurgeProjectRemovesFilesAndIndexEntry(t *testing.T) {
+	root, err := EnsureAt(filepath.Join(t.TempDir(), BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	keep, err := CreateProjectWithSource(root, "Other Book", "other.docx", []byte("other manuscript"))
+	if err != nil {
+		t.Fatalf("create other project: %v", err)
+	}
+	project, err := CreateProjectWithSource(root, "Secret Book", "secret.docx", []byte("unpublished manuscript"))
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	if _, err := ArchiveRunSource(project.Root, "run-20260101-000000.000", "secret.docx", []byte("unpublished manuscript"), 0); err != nil {
+		t.Fatalf("archive run source: %v", err)
+	}
+	if _, err := SaveRun(project.Root, "run-20260101-000000.000", map[string]int{"mhdScore": 70}); err != nil {
+		t.Fatalf("save run: %v", err)
+	}
+	if err := SaveProjectSettings(project.Root, ProjectSettings{}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	if err := os.WriteFile(project.DBPath, []byte("db"), 0o644); err != nil {
+		t.Fatalf("write db: %v", err)
+	}
+
+	purged, err := PurgeProject(root, project.Root)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	kinds := map[string]int{}
+	for _, p := range purged {
+		kinds[p.Kind]++
+	}
+	for _, kind := range []string{PurgedSource, PurgedRunSource, PurgedRun, PurgedReport, PurgedDatabase, PurgedSettings, PurgedIndexEntry} {
+		if kinds[kind] != 1 {
+			t.Fatalf("expected one %s purged, got %+v", kind, purged)
+		}
+	}
+	if _, err := os.Stat(project.Root); !os.IsNotExist(err) {
+		t.Fatalf("expected project dir removed, got %v", err)
+	}
+	idx, err := loadProjectIndex(root)
+	if err != nil {
+		t.Fatalf("load index: %v", err)
+	}
+	if len(idx.Projects) != 1 || idx.Projects[0].ID != keep.ID {
+		t.Fatalf("expected only the other project indexed, got %+v", idx.Projects)
+	}
+	if _, err := os.Stat(keep.SourcePath); err != nil {
+		t.Fatalf("expected other project untouched: %v", err)
+	}
+}
+
+func TestPurgeProjectRejectsPathsOutsideProjects(t *testing.T) {
+	root, err := EnsureAt(filepath.Join(t.TempDir(), BaseDirName))
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	for _, path := range []string{root, filepath.Join(root, "projects"), filepath.Join(root, "configs"), filepath.Join(root, "projects", "a", "sources")} {
+		if _, err := PurgeProject(root, path); err == nil {
+			t.Fatalf("expected %s to be rejected", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "configs", "settings.json")); err != nil {
+		t.Fatalf("expected workspace untouched: %v", err)
+	}
+}
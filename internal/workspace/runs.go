@@ -0,0 +1,91 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProjectRunsDir holds the full result of every completed run of a project,
+// one <runID>.json each, so any two analyses can be compared later.
+func ProjectRunsDir(projectRoot string) string {
+	return filepath.Join(projectRoot, "runs")
+}
+
+// SaveRun writes the result of runID under the project's runs directory and
+// returns its path.
+func SaveRun(projectRoot, runID string, result any) (string, error) {
+	path, err := runPath(projectRoot, runID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(ProjectRunsDir(projectRoot), 0o755); err != nil {
+		return "", fmt.Errorf("create runs dir: %w", err)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("marshal run %s: %w", runID, err)
+	}
+	err = withLock(projectRoot, func() error {
+		if err := writeFileAtomic(path, raw); err != nil {
+			return fmt.Errorf("write run %s: %w", runID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// LoadRun decodes the saved result of runID into out.
+func LoadRun(projectRoot, runID string, out any) error {
+	path, err := runPath(projectRoot, runID)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("run %s not found", runID)
+	}
+	if err != nil {
+		return fmt.Errorf("read run %s: %w", runID, err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode run %s: %w", runID, err)
+	}
+	return nil
+}
+
+// ListRuns returns the ids of the project's saved runs, oldest first; run ids
+// start with a sortable timestamp, so name order is run order.
+func ListRuns(projectRoot string) ([]string, error) {
+	entries, err := os.ReadDir(ProjectRunsDir(projectRoot))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list runs: %w", err)
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func runPath(projectRoot, runID string) (string, error) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" || sanitizeSourceName(runID) != runID {
+		return "", fmt.Errorf("%q is not a run id", runID)
+	}
+	return filepath.Join(ProjectRunsDir(projectRoot), runID+".json"), nil
+}
@@ -0,0 +1,165 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Series accumulates what earlier installments of a serialized work
+// established, so a new installment can be checked against them without
+// re-analyzing the back catalog. It lives under the workspace rather than a
+// project because every installment is different content and so its own
+// project.
+type Series struct {
+	Name         string              `json:"name"`
+	Installments []SeriesInstallment `json:"installments"`
+}
+
+// SeriesInstallment is one analyzed installment and the continuity facts it
+// contributed.
+type SeriesInstallment struct {
+	Number       int               `json:"number"`
+	Title        string            `json:"title"`
+	SourceName   string            `json:"source_name"`
+	SourceSHA256 string            `json:"source_sha256"`
+	ProjectID    string            `json:"project_id"`
+	AnalyzedAt   string            `json:"analyzed_at"`
+	WordCount    int               `json:"word_count"`
+	ChapterCount int               `json:"chapter_count"`
+	Characters   []SeriesCharacter `json:"characters"`
+	Attributes   []SeriesAttribute `json:"attributes"`
+	Timeline     []SeriesEvent     `json:"timeline"`
+}
+
+type SeriesCharacter struct {
+	Name     string `json:"name"`
+	Mentions int    `json:"mentions"`
+}
+
+// SeriesAttribute is a character fact (eyes, age, dead) as stated in one
+// chapter of the installment.
+type SeriesAttribute struct {
+	Name      string `json:"name"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Chapter   int    `json:"chapter"`
+}
+
+type SeriesEvent struct {
+	Chapter    int    `json:"chapter"`
+	TimeMarker string `json:"time_marker"`
+	Event      string `json:"event"`
+}
+
+var seriesSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SeriesSlug is the directory name for a series; it ignores case and
+// punctuation so "The Long Road" and "the-long-road" are one series.
+func SeriesSlug(name string) string {
+	return strings.Trim(seriesSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func SeriesPath(workspaceRoot, name string) string {
+	return filepath.Join(workspaceRoot, "series", SeriesSlug(name), "series.json")
+}
+
+// LoadSeries returns an empty series when none has been recorded yet.
+func LoadSeries(workspaceRoot, name string) (Series, error) {
+	if SeriesSlug(name) == "" {
+		return Series{}, fmt.Errorf("series name %q has no letters or digits", name)
+	}
+	raw, err := os.ReadFile(SeriesPath(workspaceRoot, name))
+	if os.IsNotExist(err) {
+		return Series{Name: strings.TrimSpace(name), Installments: []SeriesInstallment{}}, nil
+	}
+	if err != nil {
+		return Series{}, fmt.Errorf("read series: %w", err)
+	}
+	var s Series
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return Series{}, fmt.Errorf("decode series: %w", err)
+	}
+	if s.Installments == nil {
+		s.Installments = []SeriesInstallment{}
+	}
+	return s, nil
+}
+
+// ListSeries returns the names of every recorded series, sorted.
+func ListSeries(workspaceRoot string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(workspaceRoot, "series"))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read series dir: %w", err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := LoadSeries(workspaceRoot, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(s.Installments) > 0 {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func SaveSeries(workspaceRoot string, s Series) error {
+	path := SeriesPath(workspaceRoot, s.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create series dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal series: %w", err)
+	}
+	return withLock(filepath.Dir(path), func() error {
+		if err := writeFileAtomic(path, raw); err != nil {
+			return fmt.Errorf("write series: %w", err)
+		}
+		return nil
+	})
+}
+
+// Before returns the installments recorded ahead of the one with the given
+// source checksum, or all of them for an installment not seen before. A
+// re-run of an installment is compared only with what preceded it.
+func (s Series) Before(sourceSHA256 string) []SeriesInstallment {
+	for i, inst := range s.Installments {
+		if sourceSHA256 != "" && inst.SourceSHA256 == sourceSHA256 {
+			return s.Installments[:i]
+		}
+	}
+	return s.Installments
+}
+
+// Record stores an installment and returns its number. An installment with
+// the same source checksum replaces the earlier record and keeps its place;
+// anything else is appended as the next installment.
+func (s *Series) Record(inst SeriesInstallment) int {
+	for i, prev := range s.Installments {
+		if inst.SourceSHA256 != "" && prev.SourceSHA256 == inst.SourceSHA256 {
+			inst.Number = prev.Number
+			s.Installments[i] = inst
+			return inst.Number
+		}
+	}
+	inst.Number = len(s.Installments) + 1
+	if n := len(s.Installments); n > 0 && s.Installments[n-1].Number >= inst.Number {
+		inst.Number = s.Installments[n-1].Number + 1
+	}
+	s.Installments = append(s.Installments, inst)
+	return inst.Number
+}
@@ -0,0 +1,137 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultSourceRetention is how many analyzed source versions a project keeps
+// when its settings do not say otherwise.
+const DefaultSourceRetention = 10
+
+// ProjectSourcesDir holds one copy of the source per run, named
+// <runID>-<source name>, so every report can be traced to the exact file.
+func ProjectSourcesDir(projectRoot string) string {
+	return filepath.Join(projectRoot, "sources")
+}
+
+// SourceRetention resolves the configured retention: 0 means the default and a
+// negative value keeps every version.
+func (s ProjectSettings) SourceRetention() int {
+	if s.SourceRetentionCount == 0 {
+		return DefaultSourceRetention
+	}
+	return s.SourceRetentionCount
+}
+
+// ArchiveRunSource stores the bytes analyzed by runID and prunes the oldest
+// versions beyond keep. It returns the archived file path.
+func ArchiveRunSource(projectRoot, runID, sourceFileName string, source []byte, keep int) (string, error) {
+	dir := ProjectSourcesDir(projectRoot)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create sources dir: %w", err)
+	}
+	name := sanitizeSourceName(runID) + "-" + sanitizeSourceName(sourceFileName)
+	path := filepath.Join(dir, name)
+	written := false
+	err := withLock(projectRoot, func() error {
+		if err := writeFileAtomic(path, source); err != nil {
+			return fmt.Errorf("write run source: %w", err)
+		}
+		written = true
+		if keep > 0 {
+			return pruneRunSources(dir, keep)
+		}
+		return nil
+	})
+	if !written {
+		return "", err
+	}
+	return path, err
+}
+
+// pruneRunSources relies on run ids starting with a sortable timestamp
+// (run-20060102-150405.000), so name order is run order.
+func pruneRunSources(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read sources dir: %w", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+	sort.Strings(names)
+	for _, name := range names[:len(names)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("prune run source: %w", err)
+		}
+	}
+	return nil
+}
+
+// Source check outcomes reported by VerifyReportSource.
+const (
+	SourceUnchanged  = "unchanged"
+	SourceChanged    = "changed"
+	SourceMissing    = "missing"
+	SourceUnrecorded = "unrecorded"
+)
+
+// SourceCheck compares the checksum report.json recorded for its source with
+// the project's source file as it is now.
+type SourceCheck struct {
+	Status         string
+	SourcePath     string
+	RecordedSHA256 string
+	CurrentSHA256  string
+}
+
+// Stale reports whether the findings may not describe the file on disk.
+func (c SourceCheck) Stale() bool {
+	return c.Status == SourceChanged || c.Status == SourceMissing
+}
+
+// VerifyReportSource hashes the source file named by the report at reportPath.
+// Reports written before checksums were recorded come back SourceUnrecorded.
+func VerifyReportSource(reportPath string) (SourceCheck, error) {
+	raw, err := os.ReadFile(reportPath)
+	if err != nil {
+		return SourceCheck{}, fmt.Errorf("read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return SourceCheck{}, fmt.Errorf("decode report: %w", err)
+	}
+	if report.SourceSHA256 == "" || report.SourceName == "" {
+		return SourceCheck{Status: SourceUnrecorded}, nil
+	}
+	check := SourceCheck{
+		SourcePath:     filepath.Join(filepath.Dir(reportPath), sanitizeSourceName(report.SourceName)),
+		RecordedSHA256: report.SourceSHA256,
+	}
+	source, err := os.ReadFile(check.SourcePath)
+	if os.IsNotExist(err) {
+		check.Status = SourceMissing
+		return check, nil
+	}
+	if err != nil {
+		return SourceCheck{}, fmt.Errorf("read source file: %w", err)
+	}
+	check.CurrentSHA256 = contentHash(source)
+	if strings.EqualFold(check.CurrentSHA256, check.RecordedSHA256) {
+		check.Status = SourceUnchanged
+	} else {
+		check.Status = SourceChanged
+	}
+	return check, nil
+}
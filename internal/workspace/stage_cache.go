@@ -0,0 +1,109 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PurgedStageCache is the PurgedFile kind for cached stage results of a
+// manuscript's text.
+const PurgedStageCache = "stage_cache"
+
+// stageEntry is one cached stage result. The fingerprint describes the
+// stage's inputs other than the text (model, thresholds, chapter split), so a
+// settings change misses the cache rather than reusing a stale result.
+type stageEntry struct {
+	Fingerprint string          `json:"fingerprint"`
+	SavedAt     string          `json:"saved_at"`
+	Value       json.RawMessage `json:"value"`
+}
+
+// TextSHA256 keys the stage cache by the extracted manuscript text, so a
+// re-saved file with the same words still hits it.
+func TextSHA256(text string) string {
+	return contentHash([]byte(text))
+}
+
+// StageCacheDir holds the cached stage results for one manuscript text.
+func StageCacheDir(workspaceRoot, textSHA256 string) string {
+	return filepath.Join(workspaceRoot, "cache", "stages", textSHA256)
+}
+
+// LoadStage decodes the cached result of stage into v. It reports false,
+// leaving v untouched, when nothing is cached or the entry was saved under a
+// different fingerprint.
+func LoadStage(workspaceRoot, textSHA256, stage, fingerprint string, v any) (bool, error) {
+	raw, err := os.ReadFile(filepath.Join(StageCacheDir(workspaceRoot, textSHA256), stage+".json"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read stage cache: %w", err)
+	}
+	var entry stageEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return false, fmt.Errorf("decode stage cache: %w", err)
+	}
+	if entry.Fingerprint != fingerprint {
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return false, fmt.Errorf("decode stage cache: %w", err)
+	}
+	return true, nil
+}
+
+// SaveStage stores the result of stage, replacing any earlier entry.
+func SaveStage(workspaceRoot, textSHA256, stage, fingerprint string, v any) error {
+	dir := StageCacheDir(workspaceRoot, textSHA256)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create stage cache dir: %w", err)
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal stage cache: %w", err)
+	}
+	raw, err := json.Marshal(stageEntry{Fingerprint: fingerprint, SavedAt: time.Now().Format(time.RFC3339), Value: value})
+	if err != nil {
+		return fmt.Errorf("marshal stage cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stage+".json"), raw, 0o644); err != nil {
+		return fmt.Errorf("write stage cache: %w", err)
+	}
+	return nil
+}
+
+// PurgeStageCache deletes every cached stage result for a manuscript text
+// and lists what it removed.
+func PurgeStageCache(workspaceRoot, textSHA256 string) ([]PurgedFile, error) {
+	dir := StageCacheDir(workspaceRoot, textSHA256)
+	purged := []PurgedFile{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		purged = append(purged, PurgedFile{Path: path, Kind: PurgedStageCache, Bytes: info.Size()})
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return purged, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list stage cache: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("remove stage cache: %w", err)
+	}
+	return purged, nil
+}
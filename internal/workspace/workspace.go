@@ -14,6 +14,18 @@ type Settings struct {
 	DefaultModel string `json:"default_model"`
 }
 
+// NoveltyModelPath is where the reference n-gram model used for novelty
+// scoring is installed.
+func NoveltyModelPath(base string) string {
+	return filepath.Join(base, "cache", "ngram", "reference.ngram.gz")
+}
+
+// LibraryDBPath is the workspace database that keeps each project's latest
+// scores, so a manuscript can be compared with the rest of the library.
+func LibraryDBPath(base string) string {
+	return filepath.Join(base, "library.db")
+}
+
 func EnsureDefault() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -26,6 +38,7 @@ func EnsureAt(base string) (string, error) {
 	paths := []string{
 		filepath.Join(base, "configs"),
 		filepath.Join(base, "cache", "embeddings"),
+		filepath.Join(base, "cache", "ngram"),
 		filepath.Join(base, "projects"),
 	}
 